@@ -0,0 +1,395 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anthropics/claude-code-go/internal/auth"
+	"github.com/anthropics/claude-code-go/internal/conversation"
+	"github.com/anthropics/claude-code-go/internal/hooks"
+	"github.com/anthropics/claude-code-go/internal/mcp"
+	"github.com/anthropics/claude-code-go/internal/tools"
+)
+
+// runHooks handles the `claude hooks` subcommand.
+func runHooks(args []string) {
+	if len(args) == 0 || args[0] != "test" {
+		fmt.Println("Usage: claude hooks test <event> [options]")
+		fmt.Println()
+		fmt.Println("Fires a made-up event at the configured hooks, or at --command, and shows")
+		fmt.Println("what each hook was given, what it printed, and what the event would do with")
+		fmt.Println("it. Only the hook commands run: no tool is called and nothing is sent.")
+		fmt.Println()
+		fmt.Println("Events: " + strings.Join(hooks.Events, ", "))
+		fmt.Println()
+		fmt.Println("Options:")
+		fmt.Println("  --command <cmd>    Test this command instead of the configured hooks")
+		fmt.Println("  --tool <name>      Tool name, for the tool events (default Bash)")
+		fmt.Println("  --input <json>     Tool input, for the tool events")
+		fmt.Println("  --output <text>    Tool output, for PostToolUse")
+		fmt.Println("  --prompt <text>    The prompt, for UserPromptSubmit and SubagentStart")
+		fmt.Println("  --message <text>   The message, for Notification")
+		fmt.Println("  --match <value>    The source, reason, trigger, or agent type, for the events matched on them")
+		fmt.Println("  --stop-active      Set stop_hook_active, for Stop")
+		if len(args) > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+	if len(args) < 2 || strings.HasPrefix(args[1], "-") {
+		fmt.Println("Usage: claude hooks test <event> [options]")
+		os.Exit(1)
+	}
+
+	event := ""
+	for _, e := range hooks.Events {
+		if strings.EqualFold(e, args[1]) {
+			event = e
+		}
+	}
+	if event == "" {
+		fmt.Fprintf(os.Stderr, "Error: unknown event %q (want one of %s)\n", args[1], strings.Join(hooks.Events, ", "))
+		os.Exit(1)
+	}
+
+	fs := flag.NewFlagSet("hooks test", flag.ExitOnError)
+	command := fs.String("command", "", "Test this command instead of the configured hooks")
+	tool := fs.String("tool", "Bash", "Tool name, for the tool events")
+	input := fs.String("input", `{"command":"echo hello"}`, "Tool input, for the tool events")
+	output := fs.String("output", "hello", "Tool output, for PostToolUse")
+	prompt := fs.String("prompt", "hello", "The prompt, for UserPromptSubmit and SubagentStart")
+	message := fs.String("message", "Claude needs your permission to use Bash", "The message, for Notification")
+	match := fs.String("match", "", "The source, reason, trigger, or agent type, for the events matched on them")
+	stopActive := fs.Bool("stop-active", false, "Set stop_hook_active, for Stop")
+	fs.Parse(args[2:])
+	if !json.Valid([]byte(*input)) {
+		fmt.Fprintf(os.Stderr, "Error: --input is not valid JSON: %s\n", *input)
+		os.Exit(1)
+	}
+
+	hookConfig, err := testHookConfig(event, *command)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	r := hooks.NewRunner(hookConfig)
+	r.SetSession("hooks-test", "")
+	servers := &hookMCPServers{}
+	defer servers.stop()
+	r.SetMCP(servers.call)
+	var mu sync.Mutex
+	var traces []hooks.Trace
+	r.SetTrace(func(t hooks.Trace) {
+		mu.Lock()
+		defer mu.Unlock()
+		traces = append(traces, t)
+	})
+
+	// Fire the event as the loop and the TUI would.
+	ctx := context.Background()
+	toolInput := json.RawMessage(*input)
+	var result []string
+	var out conversation.HookOutput
+	matchOr := func(def string) string {
+		if *match != "" {
+			return *match
+		}
+		return def
+	}
+	agent := conversation.SubagentEvent{AgentID: "agent-test", AgentType: matchOr("general-purpose"), Description: "hooks test", Prompt: *prompt}
+	switch event {
+	case hooks.EventPreToolUse:
+		out, err = r.RunPreToolUse(ctx, *tool, toolInput)
+	case hooks.EventPostToolUse:
+		out, err = r.RunPostToolUse(ctx, *tool, toolInput, *output, false)
+	case hooks.EventPermissionRequest:
+		out, err = r.RunPermissionRequest(ctx, *tool, toolInput)
+	case hooks.EventStop:
+		out, err = r.RunStop(ctx, *stopActive)
+	case hooks.EventUserPromptSubmit:
+		var submit conversation.HookSubmitResult
+		submit, err = r.RunUserPromptSubmit(ctx, *prompt)
+		if submit.Block && err == nil {
+			result = append(result, "Decision: block ("+submit.Reason+"); the prompt isn't sent")
+		}
+		if submit.Message != *prompt && !submit.Block {
+			result = append(result, "Prompt replaced with: "+submit.Message)
+		}
+		if submit.Context != "" {
+			result = append(result, "Context for the model: "+submit.Context)
+		}
+	case hooks.EventNotification:
+		err = r.RunNotification(ctx, *message)
+	case hooks.EventSessionStart:
+		err = r.RunSessionStart(ctx, matchOr("startup"))
+	case hooks.EventSessionEnd:
+		err = r.RunSessionEnd(ctx, matchOr("other"))
+	case hooks.EventPreCompact:
+		err = r.RunPreCompact(ctx, matchOr("manual"))
+	case hooks.EventSubagentStart:
+		err = r.RunSubagentStart(ctx, agent)
+	case hooks.EventSubagentStop:
+		agent.Status = "completed"
+		err = r.RunSubagentStop(ctx, agent)
+	}
+	// Async hooks are killed after their timeout, so this is bounded.
+	waited := r.Wait(10 * time.Minute)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(traces) == 0 {
+		fmt.Printf("No %s hooks match.\n", event)
+		return
+	}
+	fmt.Printf("%s input on stdin:\n%s\n", event, indentJSON(traces[0].Stdin, "  "))
+	failed := false
+	for i, t := range traces {
+		fmt.Println()
+		printTrace(i+1, t)
+		failed = failed || t.Result.Error != nil
+	}
+	if !waited {
+		fmt.Println("\nAsync hooks were still running.")
+	}
+
+	fmt.Println()
+	if event != hooks.EventUserPromptSubmit || err != nil {
+		result = append(result, describeHookOutput(event, out, err)...)
+	} else if len(result) == 0 {
+		result = append(result, "No decision: the prompt is sent as is.")
+	}
+	for _, line := range result {
+		fmt.Println(line)
+	}
+	if failed {
+		servers.stop()
+		os.Exit(1)
+	}
+}
+
+// testHookConfig returns the hooks to test: the configured ones, or a
+// hook running command for event.
+func testHookConfig(event, command string) (hooks.HookConfig, error) {
+	var hookConfig hooks.HookConfig
+	if command != "" {
+		if err := hooks.ValidateCommand(command); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+		data, _ := json.Marshal(map[string]hooks.HookList{event: {{Type: "command", Command: command}}})
+		err := json.Unmarshal(data, &hookConfig)
+		return hookConfig, err
+	}
+
+	cwd, _ := os.Getwd()
+	hookConfig, conflicts, err := hooks.Load(cwd)
+	if err != nil {
+		return hookConfig, fmt.Errorf("invalid hooks config: %w", err)
+	}
+	for _, c := range conflicts {
+		fmt.Fprintf(os.Stderr, "Note: %s\n", c)
+	}
+	if err := hookConfig.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: invalid hooks config: %v\n", err)
+	}
+	return hookConfig, nil
+}
+
+// hookMCPServers starts the MCP servers "mcp" hooks call, for `claude
+// hooks test`: each on the first call to it, rather than all of them up
+// front for hooks that may not use any.
+type hookMCPServers struct {
+	mu      sync.Mutex
+	config  *mcp.MCPConfig // nil until the first call
+	manager *mcp.Manager
+}
+
+// call is the Runner's MCPToolFunc.
+func (s *hookMCPServers) call(ctx context.Context, server, tool string, args json.RawMessage) (string, error) {
+	s.mu.Lock()
+	if s.manager == nil {
+		cwd, _ := os.Getwd()
+		cfg, err := mcp.LoadMCPConfig(cwd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: MCP config error: %v\n", err)
+		}
+		s.config = trustedMCPConfig(cwd, cfg, nil)
+		s.manager = mcp.NewManager(cwd)
+	}
+	if _, ok := s.manager.Client(server); !ok {
+		var cfg mcp.ServerConfig
+		ok := false
+		if s.config != nil {
+			cfg, ok = s.config.MCPServers[server]
+		}
+		if !ok {
+			s.mu.Unlock()
+			return "", fmt.Errorf("MCP server %q is not configured", server)
+		}
+		// Not ctx: the server outlives the hook's timeout.
+		s.manager.StartServers(context.Background(), map[string]mcp.ServerConfig{server: cfg}, tools.NewRegistry(nil))
+	}
+	m := s.manager
+	s.mu.Unlock()
+	return m.CallTool(ctx, server, tool, args)
+}
+
+// stop shuts down the servers that were started.
+func (s *hookMCPServers) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.manager != nil {
+		s.manager.Shutdown()
+	}
+}
+
+// printTrace prints what a hook did.
+func printTrace(n int, t hooks.Trace) {
+	name := t.Hook.Command
+	switch t.Hook.Type {
+	case "prompt":
+		name = "prompt: " + t.Hook.Prompt
+	case "mcp":
+		name = fmt.Sprintf("mcp: %s/%s", t.Hook.Server, t.Hook.Tool)
+	}
+	fmt.Printf("Hook %d: %s\n", n, name)
+	if t.Hook.Matcher != "" {
+		fmt.Printf("  Matcher: %s\n", t.Hook.Matcher)
+	}
+	res := t.Result
+	if res.Error != nil {
+		exit := ""
+		if t.Hook.Type == "command" {
+			exit = fmt.Sprintf(", exit %d", res.ExitCode)
+		}
+		fmt.Printf("  Failed after %s%s: %v\n", t.Duration.Round(time.Millisecond), exit, res.Error)
+	} else {
+		fmt.Printf("  Succeeded in %s\n", t.Duration.Round(time.Millisecond))
+	}
+	if strings.TrimSpace(res.Output) != "" {
+		fmt.Printf("  Stdout:\n    %s\n", strings.ReplaceAll(strings.TrimRight(res.Output, "\n"), "\n", "\n    "))
+	}
+	if p := res.Parsed; p != nil {
+		data, _ := json.Marshal(p)
+		fmt.Printf("  Read as JSON output: %s\n", data)
+	} else if res.Error == nil && strings.TrimSpace(res.Output) != "" {
+		fmt.Println("  Read as plain text")
+	}
+}
+
+// describeHookOutput says what an event does with what its hooks decided.
+func describeHookOutput(event string, out conversation.HookOutput, err error) []string {
+	if err != nil {
+		line := "A hook failed: " + err.Error()
+		switch event {
+		case hooks.EventPreToolUse, hooks.EventPermissionRequest, hooks.EventUserPromptSubmit, hooks.EventSubagentStart:
+			line += "\nThat blocks the action."
+		}
+		return []string{line}
+	}
+	var lines []string
+	switch out.Decision {
+	case conversation.HookBlock:
+		lines = append(lines, fmt.Sprintf("Decision: block (%s)", out.Reason))
+	case conversation.HookApprove:
+		lines = append(lines, "Decision: approve")
+	}
+	if out.UpdatedInput != nil {
+		lines = append(lines, "Tool input replaced with: "+string(out.UpdatedInput))
+	}
+	if out.AdditionalContext != "" {
+		lines = append(lines, "Context for the model: "+out.AdditionalContext)
+	}
+	if out.Message != "" {
+		lines = append(lines, "Shown to the user: "+out.Message)
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "No decision: the action goes ahead.")
+	}
+	return lines
+}
+
+// indentJSON pretty-prints JSON, or returns it as is if it isn't valid.
+func indentJSON(data []byte, prefix string) string {
+	var b bytes.Buffer
+	if json.Indent(&b, data, prefix, "  ") != nil {
+		return prefix + string(data)
+	}
+	return prefix + b.String()
+}
+
+// hookTraceBacklog is how many hook runs the TUI may fall behind on
+// before the scrollback starts dropping them; the debug file keeps all.
+const hookTraceBacklog = 256
+
+// hookTracer logs every hook run, with --verbose, so a hook that fails
+// quietly can be found: to debug/<session>.txt in the config directory
+// (~/.claude), and to the scrollback, which is stderr in print mode and
+// the TUI once it watches.
+type hookTracer struct {
+	mu     sync.Mutex
+	file   *os.File    // nil if the debug file couldn't be created
+	lines  chan string // runs for the TUI, held until it watches
+	stderr bool        // print mode: write runs to stderr instead
+}
+
+// newHookTracer opens the debug file for the session, pointing
+// debug/latest at it.
+func newHookTracer(sessionID string, printMode bool) *hookTracer {
+	t := &hookTracer{lines: make(chan string, hookTraceBacklog), stderr: printMode}
+	configDir, err := auth.ConfigDir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: no debug file for hooks: %v\n", err)
+		return t
+	}
+	dir := filepath.Join(configDir, "debug")
+	path := filepath.Join(dir, sessionID+".txt")
+	if err := os.MkdirAll(dir, 0o700); err == nil {
+		t.file, err = os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: no debug file for hooks: %v\n", err)
+		return t
+	}
+	latest := filepath.Join(dir, "latest")
+	os.Remove(latest)
+	os.Symlink(path, latest)
+	fmt.Fprintf(os.Stderr, "Logging hook runs to %s\n", path)
+	return t
+}
+
+// trace is the Runner's trace function.
+func (t *hookTracer) trace(tr hooks.Trace) {
+	line := tr.String()
+	t.mu.Lock()
+	if t.file != nil {
+		fmt.Fprintf(t.file, "%s %s\n", time.Now().Format(time.RFC3339Nano), line)
+	}
+	t.mu.Unlock()
+	if t.stderr {
+		fmt.Fprintln(os.Stderr, line)
+		return
+	}
+	// Hooks also run from the TUI's own goroutine, so this mustn't wait.
+	select {
+	case t.lines <- line:
+	default:
+	}
+}
+
+// watch passes hook runs to notify from now on, starting with the ones
+// that ran before the TUI was up.
+func (t *hookTracer) watch(notify func(line string)) {
+	go func() {
+		for line := range t.lines {
+			notify(line)
+		}
+	}()
+}
@@ -5,16 +5,23 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"os"
+	"os/exec"
 	"os/signal"
 	"os/user"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"golang.org/x/term"
 
+	"github.com/anthropics/claude-code-go/internal/agents"
 	"github.com/anthropics/claude-code-go/internal/api"
 	"github.com/anthropics/claude-code-go/internal/auth"
 	"github.com/anthropics/claude-code-go/internal/config"
@@ -31,6 +38,10 @@ var (
 	version = "dev"
 )
 
+// asyncHookGrace is how long to wait at exit for async hooks still
+// running, such as SessionEnd hooks, before leaving them behind.
+const asyncHookGrace = 5 * time.Second
+
 // subcommand defines a CLI subcommand (e.g. `claude login`).
 type subcommand struct {
 	Name string
@@ -50,7 +61,9 @@ func init() {
 	registerSubcommand(subcommand{Name: "status", Run: func(args []string) { runStatus(args) }})
 	registerSubcommand(subcommand{Name: "update", Run: func(args []string) { runUpdate(args) }})
 	registerSubcommand(subcommand{Name: "mcp", Run: func(args []string) { runMCP(args) }})
-	registerSubcommand(subcommand{Name: "agents", Run: func(args []string) { runAgents() }})
+	registerSubcommand(subcommand{Name: "agents", Run: func(args []string) { runAgents(args) }})
+	registerSubcommand(subcommand{Name: "sessions", Run: func(args []string) { runSessions(args) }})
+	registerSubcommand(subcommand{Name: "hooks", Run: func(args []string) { runHooks(args) }})
 }
 
 // dispatchSubcommand checks os.Args for a registered subcommand and runs it.
@@ -89,6 +102,8 @@ func main() {
 	printMode := flag.Bool("p", false, "Print mode: non-interactive, exit after response")
 	continueFlag := flag.Bool("c", false, "Continue most recent session")
 	resumeFlag := flag.String("r", "", "Resume specific session by ID")
+	allProjectsFlag := flag.Bool("all-projects", false, "With -c, continue the most recent session of any project")
+	forkSessionFlag := flag.Bool("fork-session", false, "With -c or -r, continue in a copy of the session under a new ID")
 	maxTokens := flag.Int("max-tokens", api.DefaultMaxTokens, "Maximum response tokens")
 	versionFlag := flag.Bool("version", false, "Print version and exit")
 	loginFlag := flag.Bool("login", false, "Log in with OAuth")
@@ -105,6 +120,15 @@ func main() {
 	maxThinkingTokens := flag.Int("max-thinking-tokens", 0, "Maximum thinking tokens")
 	betasFlag := flag.String("betas", "", "Additional beta headers (comma-separated)")
 
+	// Sampling flags.
+	temperatureFlag := flag.String("temperature", "", "Sampling temperature (0.0-1.0)")
+	topPFlag := flag.String("top-p", "", "Nucleus sampling top_p (0.0-1.0)")
+	var stopFlags []string
+	flag.Func("stop", "Stop sequence (repeatable)", func(v string) error {
+		stopFlags = append(stopFlags, v)
+		return nil
+	})
+
 	// System prompt override flags.
 	systemPromptFlag := flag.String("system-prompt", "", "Custom system prompt (replaces default)")
 	appendSystemPromptFlag := flag.String("append-system-prompt", "", "Append to default system prompt")
@@ -121,6 +145,7 @@ func main() {
 
 	// Other flags.
 	addDirFlag := flag.String("add-dir", "", "Additional directories (comma-separated)")
+	noHooksFlag := flag.Bool("no-hooks", false, "Don't run any hooks from settings")
 
 	flag.Parse()
 
@@ -174,6 +199,27 @@ func main() {
 		billingType = auth.SubscriptionDisplayName(tokens.SubscriptionType)
 	}
 
+	// Session notices go to stderr when the response is the output, so
+	// `claude -p -c` prints only the reply.
+	notices := os.Stdout
+	if *printMode || !term.IsTerminal(int(os.Stdin.Fd())) {
+		notices = os.Stderr
+	}
+
+	// A session from another project is resumed in that project's
+	// directory, so it gets the tools, settings, and CLAUDE.md it was
+	// started with.
+	if dir, err := resumeDir(*resumeFlag, *continueFlag && *allProjectsFlag); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	} else if dir != "" {
+		if err := os.Chdir(dir); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(notices, "Switching to %s\n", dir)
+	}
+
 	// Working directory.
 	cwd, err := os.Getwd()
 	if err != nil {
@@ -188,17 +234,110 @@ func main() {
 		settings = &config.Settings{}
 	}
 
-	// Phase 7: Parse hook config from settings.
+	// Phase 7: Merge the hooks of each settings file, unless --no-hooks.
 	var hookConfig hooks.HookConfig
-	if settings.Hooks != nil {
-		if err := json.Unmarshal(settings.Hooks, &hookConfig); err != nil {
+	if !*noHooksFlag {
+		var conflicts []hooks.Conflict
+		hookConfig, conflicts, err = hooks.Load(cwd)
+		if err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: invalid hooks config: %v\n", err)
 		}
+		if err := hookConfig.Validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: invalid hooks config: %v\n", err)
+		}
+		for _, c := range conflicts {
+			fmt.Fprintf(os.Stderr, "Note: %s\n", c)
+		}
 	}
 	hookRunner := hooks.NewRunner(hookConfig)
 
-	// Phase 7: Load skills.
-	loadedSkills := skills.LoadSkills(cwd)
+	// Whether there's a terminal to ask the user things on before the
+	// TUI starts: resume choices and new MCP servers.
+	interactive := !*printMode && term.IsTerminal(int(os.Stdin.Fd()))
+	stdin := bufio.NewReader(os.Stdin)
+
+	// Session management. Encryption comes first: without the key,
+	// encrypted sessions can't be loaded, and new ones would be written
+	// in the clear.
+	if err := setupSessionEncryption(settings.SessionEncryption); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	sessionStore, err := session.NewStore(cwd)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: session store unavailable: %v\n", err)
+	} else {
+		sessionStore.SetVersion(version)
+		if settings.SessionBackend == "sqlite" {
+			if err := sessionStore.UseSQLite(); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v; keeping sessions in JSON files\n", err)
+			}
+		}
+	}
+
+	// Check for session resume.
+	var history *conversation.History
+	var currentSession *session.Session
+
+	if *continueFlag && sessionStore != nil {
+		sess, err := sessionStore.MostRecent()
+		if err != nil && !interactive {
+			// A new conversation isn't what was asked for, and there is
+			// nobody to notice the warning before the reply.
+			fmt.Fprintf(os.Stderr, "Error: no previous session to continue: %v\n", err)
+			os.Exit(1)
+		} else if err != nil {
+			fmt.Fprintf(os.Stderr, "No previous session found: %v\n", err)
+		} else {
+			history = conversation.NewHistoryFrom(sess.Messages)
+			history.SetTurns(sess.Turns)
+			currentSession = sess
+			fmt.Fprintf(notices, "Resuming session %s (%s)\n", sess.ID, resumeSummary(sess))
+		}
+	}
+
+	if *resumeFlag != "" && sessionStore != nil {
+		id, err := sessionStore.Resolve(*resumeFlag)
+		var sess *session.Session
+		if err == nil {
+			sess, err = sessionStore.Load(id)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot load session %s: %v\n", *resumeFlag, err)
+			os.Exit(1)
+		}
+		history = conversation.NewHistoryFrom(sess.Messages)
+		history.SetTurns(sess.Turns)
+		currentSession = sess
+		fmt.Fprintf(notices, "Resuming session %s (%s)\n", sess.ID, resumeSummary(sess))
+	}
+
+	// --fork-session continues in a copy, leaving the resumed session as
+	// it was.
+	if *forkSessionFlag && currentSession != nil {
+		fork, err := sessionStore.Fork(currentSession.ID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot fork session %s: %v\n", currentSession.ID, err)
+			os.Exit(1)
+		}
+		currentSession = fork
+		fmt.Fprintf(notices, "Forked into new session %s\n", fork.ID)
+	}
+
+	// Phase 7: Load skills, and CLAUDE.md for the context message below.
+	// A resumed session may run with the ones pinned when it started.
+	runContext := &session.ContextSnapshot{
+		TakenAt:  time.Now(),
+		ClaudeMD: config.LoadClaudeMDEntries(cwd),
+		Skills:   skills.LoadSkills(cwd),
+	}
+	if currentSession != nil {
+		runContext = resumeContext(sessionStore, currentSession.ID, runContext, settings.ResumeContext, interactive, stdin)
+	}
+	if sessionStore != nil {
+		sessionStore.SetContext(runContext)
+	}
+	loadedSkills := runContext.Skills
 	skillContent := skills.ActiveSkillContent(loadedSkills)
 
 	// Resolve model: CLI flag > settings > default.
@@ -224,10 +363,16 @@ func main() {
 	)
 
 	// Collect context for system prompt and user message injection.
-	claudeMDEntries := config.LoadClaudeMDEntries(cwd)
-	claudeMDFormatted := config.FormatClaudeMDForContext(claudeMDEntries)
+	claudeMDFormatted := config.FormatClaudeMDForContext(runContext.ClaudeMD)
 	gitStatus := conversation.CollectGitStatus(cwd)
 
+	// Apply the project's system prompt preset, if any.
+	preset, err := conversation.LoadPromptPreset(cwd, settings)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: cannot load system prompt preset: %v\n", err)
+	}
+	conversation.ApplyPromptPreset(preset)
+
 	// Build system prompt with settings context, skill content, and git status.
 	// Git status is appended to the system prompt (matching JS owq() pattern).
 	system := conversation.BuildSystemPrompt(&conversation.PromptContext{
@@ -267,10 +412,12 @@ func main() {
 	}
 
 	// Apply --add-dir flag: additional directories to include.
+	var addDirs []string
 	if *addDirFlag != "" {
 		for _, dir := range strings.Split(*addDirFlag, ",") {
 			dir = strings.TrimSpace(dir)
 			if dir != "" {
+				addDirs = append(addDirs, dir)
 				// Load CLAUDE.md from additional directories.
 				extraContent := config.LoadClaudeMD(dir)
 				if extraContent != "" {
@@ -351,35 +498,106 @@ func main() {
 	)
 	// Set the initial permission mode.
 	ruleHandler.GetPermissionContext().SetMode(initialPermMode)
+	for _, dir := range addDirs {
+		if abs, err := filepath.Abs(dir); err == nil {
+			ruleHandler.GetPermissionContext().AddWorkingDirectory(abs, "cliArg")
+		}
+	}
 	permHandler = ruleHandler
 
 	// Background task store shared by Agent, TaskOutput, and TaskStop tools.
+	// Background commands and servers are stopped when the session ends.
 	bgStore := tools.NewBackgroundTaskStore()
+	defer bgStore.StopAll()
+	if session.Encrypted() {
+		bgStore.SetCodec(session.Seal, session.Open)
+	}
 
 	// Create tool registry with all tools.
 	registry := tools.NewRegistry(permHandler)
+	if len(settings.ToolLimits) > 0 {
+		registry.SetLimits(settings.ToolLimits)
+	}
+	if sr := settings.SecretRedaction; sr != nil && config.BoolVal(sr.Enabled, false) {
+		if redactor, err := tools.NewSecretRedactor(sr.Allow); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: secret redaction disabled: %v\n", err)
+		} else {
+			registry.Use(redactor)
+		}
+	}
+	var bashTool *tools.BashTool
 	if len(settings.Env) > 0 {
-		registry.Register(tools.NewBashToolWithEnv(cwd, settings.Env))
+		bashTool = tools.NewBashToolWithEnv(cwd, settings.Env)
 	} else {
-		registry.Register(tools.NewBashTool(cwd))
+		bashTool = tools.NewBashTool(cwd)
 	}
-	registry.Register(tools.NewFileReadTool())
-	registry.Register(tools.NewFileEditTool())
-	registry.Register(tools.NewFileWriteTool())
-	registry.Register(tools.NewGlobTool(cwd))
-	registry.Register(tools.NewGrepTool(cwd))
+	bashTool.SetPersistentShell(config.BoolVal(settings.PersistentShell, false))
+	defer bashTool.Close()
+	bashTool.SetBackgroundStore(bgStore)
+	registry.Register(bashTool)
+	registry.Register(tools.NewRunServerTool(bashTool, bgStore))
+	// File modifications are recorded for /undo, and refused if the file
+	// changed on disk since the model last read it.
+	undoStore := tools.NewUndoStore()
+	readTracker := tools.NewReadTracker()
+	fileReadTool := tools.NewFileReadTool()
+	fileReadTool.SetReadTracker(readTracker)
+	registry.Register(fileReadTool)
+	fileEditTool := tools.NewFileEditTool()
+	fileEditTool.SetUndoStore(undoStore)
+	fileEditTool.SetReadTracker(readTracker)
+	registry.Register(fileEditTool)
+	fileWriteTool := tools.NewFileWriteTool()
+	fileWriteTool.SetUndoStore(undoStore)
+	fileWriteTool.SetReadTracker(readTracker)
+	registry.Register(fileWriteTool)
+	respectGitignore := config.BoolVal(settings.RespectGitignore, true)
+	globTool := tools.NewGlobTool(cwd)
+	globTool.SetRespectGitignore(respectGitignore)
+	registry.Register(globTool)
+	grepTool := tools.NewGrepTool(cwd)
+	grepTool.SetRespectGitignore(respectGitignore)
+	registry.Register(grepTool)
+	registry.Register(tools.NewLSTool(cwd))
+	registry.Register(tools.NewGitTool(cwd))
+	registry.Register(tools.NewContainerTool(cwd))
+	registry.Register(tools.NewCodeRunTool())
 
 	// Phase 4 tools.
-	registry.Register(tools.NewTodoWriteTool())
-	registry.Register(tools.NewAskUserTool())
+	todoTool := tools.NewTodoWriteTool()
+	registry.Register(todoTool)
+	askUserTool := tools.NewAskUserTool()
+	registry.Register(askUserTool)
+	if skillTool := tools.NewSkillTool(loadedSkills, settings.ModelSlashCommands); skillTool.Len() > 0 {
+		registry.Register(skillTool)
+	}
+	if len(settings.ModelSlashCommands) > 0 {
+		if slashTool := tools.NewSlashCommandTool(loadedSkills, settings.ModelSlashCommands); slashTool.Len() > 0 {
+			registry.Register(slashTool)
+		}
+	}
 	registry.Register(tools.NewWebFetchTool(nil))
+	httpRequestTool := tools.NewHttpRequestTool(nil)
+	httpRequestTool.SetCredentials(settings.HttpCredentials)
+	registry.Register(httpRequestTool)
+	registry.Register(tools.NewDownloadFileTool(nil))
+	if len(settings.Databases) > 0 {
+		registry.Register(tools.NewSQLTool(cwd, settings.Databases))
+	}
 	registry.Register(tools.NewWebSearchTool())
-	registry.Register(tools.NewNotebookEditTool())
+	notebookReadTool := tools.NewNotebookReadTool()
+	notebookReadTool.SetReadTracker(readTracker)
+	registry.Register(notebookReadTool)
+	notebookEditTool := tools.NewNotebookEditTool()
+	notebookEditTool.SetUndoStore(undoStore)
+	notebookEditTool.SetReadTracker(readTracker)
+	registry.Register(notebookEditTool)
 	registry.Register(tools.NewConfigTool(cwd))
 	registry.Register(tools.NewWorktreeTool(cwd))
 	registry.Register(tools.NewExitPlanModeTool())
 	registry.Register(tools.NewTaskOutputTool(bgStore))
 	registry.Register(tools.NewTaskStopTool(bgStore))
+	registry.Register(tools.NewTaskSteerTool(bgStore))
 
 	// Phase 6: MCP server initialization.
 	// Load MCP config and start servers before AgentTool so MCP tools
@@ -388,12 +606,29 @@ func main() {
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: MCP config error: %v\n", err)
 	}
+	// Servers checked into the project only run once approved; ask about
+	// new ones when there's a terminal to ask on.
+	var trustPrompt mcp.TrustPrompt
+	if interactive {
+		trustPrompt = askMCPTrust(stdin)
+	}
+	mcpConfig = trustedMCPConfig(cwd, mcpConfig, trustPrompt)
 
 	var mcpManager *mcp.Manager
 	if mcpConfig != nil && len(mcpConfig.MCPServers) > 0 {
 		mcpManager = mcp.NewManager(cwd)
-		if err := mcpManager.StartServers(ctx, mcpConfig.MCPServers, registry); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: MCP startup error: %v\n", err)
+		mcpManager.SetSampler(mcpSampler(client, settings.MCPSampling, registry))
+		mcpManager.SetElicitor(mcp.AskUserElicitor(askUserTool.Ask))
+		// Offer --add-dir directories to servers as roots alongside cwd.
+		if err := mcpManager.AddRoots(ctx, addDirs...); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: MCP roots: %v\n", err)
+		}
+		// The TUI starts servers in the background once it's up (see
+		// appCfg.StartMCP); other sessions wait for them here.
+		if !interactive {
+			if err := mcpManager.StartServers(ctx, mcpConfig.MCPServers, registry); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: MCP startup error: %v\n", err)
+			}
 		}
 		defer mcpManager.Shutdown()
 
@@ -404,43 +639,43 @@ func main() {
 		registry.Register(mcp.NewUnsubscribeMcpResourceTool(mcpManager))
 		registry.Register(mcp.NewSubscribePollingTool(mcpManager))
 		registry.Register(mcp.NewUnsubscribePollingTool(mcpManager))
+		hookRunner.SetMCP(mcpManager.CallTool)
+	}
+	// os.Exit skips deferred calls, so paths that exit stop the servers and
+	// remove the shell's state directory first rather than leaving them behind.
+	cleanup := bashTool.Close
+	if mcpManager != nil {
+		cleanup = func() {
+			bashTool.Close()
+			mcpManager.Shutdown()
+		}
 	}
 
 	// Agent tool registered last — gets tool definitions that include everything above.
 	// Phase 7: Pass hookRunner so sub-agents inherit hooks.
-	agentTool := tools.NewAgentTool(client, system, registry.Definitions(), registry, bgStore, hookRunner)
-	registry.Register(agentTool)
-
-	// Session management.
-	sessionStore, err := session.NewStore(cwd)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: session store unavailable: %v\n", err)
+	// Oversized tool results are saved to files the model can read back,
+	// unless sessions are encrypted, when they are only cut short.
+	spillDir := filepath.Join(cwd, ".claude", "tool-output")
+	if session.Encrypted() {
+		spillDir = ""
 	}
+	spiller := conversation.NewResultSpiller(spillDir, settings.MaxToolResultBytes)
 
-	// Check for session resume.
-	var history *conversation.History
-	var currentSession *session.Session
-
-	if *continueFlag && sessionStore != nil {
-		sess, err := sessionStore.MostRecent()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "No previous session found: %v\n", err)
-		} else {
-			history = conversation.NewHistoryFrom(sess.Messages)
-			currentSession = sess
-			fmt.Printf("Resuming session %s (%d messages)\n", sess.ID, len(sess.Messages))
-		}
-	}
+	agentTool := tools.NewAgentTool(client, system, registry.Definitions(), registry, bgStore, hookRunner)
+	agentTool.SetResultSpiller(spiller)
+	agentTool.SetCustomAgents(agents.All(cwd))
+	agentTool.SetMaxDepth(settings.MaxAgentDepth)
+	agentTool.SetRepoState(func() string { return tools.GitRepoState(cwd) })
+	registry.Register(agentTool)
 
-	if *resumeFlag != "" && sessionStore != nil {
-		sess, err := sessionStore.Load(*resumeFlag)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Cannot load session %s: %v\n", *resumeFlag, err)
-			os.Exit(1)
-		}
-		history = conversation.NewHistoryFrom(sess.Messages)
-		currentSession = sess
-		fmt.Printf("Resuming session %s (%d messages)\n", sess.ID, len(sess.Messages))
+	// Title sessions for the resume picker and `claude sessions list`.
+	// Print mode exits before a title would arrive. Titles describe the
+	// conversation but are stored in the clear, so encrypted sessions
+	// aren't titled.
+	if sessionStore != nil && interactive && !session.Encrypted() {
+		sessionStore.SetTitler(func(ctx context.Context, msgs []api.Message) (string, error) {
+			return session.GenerateTitle(ctx, client, msgs)
+		})
 	}
 
 	// Create a new session if not resuming.
@@ -456,6 +691,61 @@ func main() {
 		}
 	}
 
+	// Claim the session so another claude process resuming it can't
+	// interleave its saves with this one's. If one already has it, print
+	// mode refuses and the TUI carries on without saving.
+	if sessionStore != nil {
+		var locked *session.LockedError
+		if err := sessionStore.Lock(currentSession.ID); errors.As(err, &locked) {
+			if !interactive {
+				fmt.Fprintf(os.Stderr, "Error: %v. Use --fork-session to continue in a copy.\n", err)
+				os.Exit(1)
+			}
+			sessionStore.SetReadOnly(currentSession.ID)
+			fmt.Fprintf(os.Stderr, "Warning: %v. Continuing read-only: nothing from this process will be saved. Use --fork-session to continue in a copy.\n", err)
+		} else if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	// Prune sessions past the retention limits, at most once a day. The
+	// session just claimed is kept.
+	if sessionStore != nil {
+		res, err := sessionStore.AutoPrune(retentionPolicy(settings.SessionRetention))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: pruning sessions: %v\n", err)
+		}
+		if res != nil && len(res.Pruned) > 0 {
+			fmt.Fprintf(notices, "Pruned %s past the sessionRetention limits, freeing %s\n", countSessions(len(res.Pruned)), session.FormatBytes(res.Freed()))
+		}
+	}
+	todoTool.SetTodos(currentSession.Todos)
+	agentTool.SetCosts(currentSession.AgentCosts)
+	transcriptPath := ""
+	if sessionStore != nil {
+		transcriptPath = sessionStore.TranscriptPath(currentSession.ID)
+	}
+	hookRunner.SetSession(currentSession.ID, transcriptPath)
+	var hookTrace *hookTracer
+	if config.BoolVal(settings.Verbose, false) {
+		hookTrace = newHookTracer(currentSession.ID, *printMode)
+		hookRunner.SetTrace(hookTrace.trace)
+	}
+	if sessionStore != nil {
+		if !sessionStore.IsReadOnly(currentSession.ID) {
+			if err := bgStore.Open(sessionStore.TasksDir(currentSession.ID)); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+		}
+		// Every file the tools modify goes in the session's change
+		// ledger, for /changes and `claude sessions diff`.
+		undoStore.OnSnapshot(func(e tools.UndoEntry) {
+			if err := sessionStore.RecordChange(currentSession.ID, e); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to record file change: %v\n", err)
+			}
+		})
+	}
+
 	// Create compactor for auto-compaction (unless disabled).
 	var compactor *conversation.Compactor
 	disableCompact := os.Getenv("DISABLE_COMPACT") != ""
@@ -471,10 +761,37 @@ func main() {
 		client.SetModel(model)
 	}
 
+	// Resolve sampling parameters: CLI flag > settings > API default.
+	temperature, err := samplingParam("temperature", *temperatureFlag, settings.Temperature)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	topP, err := samplingParam("top-p", *topPFlag, settings.TopP)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	stopSequences := settings.StopSequences
+	if len(stopFlags) > 0 {
+		stopSequences = stopFlags
+	}
+
 	// Create conversation loop with tools.
 	// In TUI mode, the handler and permission handler will be replaced by app.Run().
 	// In print mode, use the simple PrintStreamHandler.
 	handler := &conversation.ToolAwareStreamHandler{}
+	saveSession := func(h *conversation.History) {
+		if sessionStore != nil && currentSession != nil {
+			currentSession.Messages = h.Messages()
+			currentSession.Turns = h.Turns()
+			currentSession.Todos = todoTool.Todos()
+			currentSession.AgentCosts = agentTool.Costs()
+			if err := sessionStore.Save(currentSession); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to save session: %v\n", err)
+			}
+		}
+	}
 	loop := conversation.NewLoop(conversation.LoopConfig{
 		Client:         client,
 		System:         system,
@@ -485,18 +802,37 @@ func main() {
 		Compactor:      compactor,
 		Hooks:          hookRunner, // Phase 7: wire hooks into the loop
 		ContextMessage: contextMessage,
-		OnTurnComplete: func(h *conversation.History) {
-			// Save session after each turn.
+		Temperature:    temperature,
+		TopP:           topP,
+		StopSequences:  stopSequences,
+		Spiller:        spiller,
+		// Save the session after each turn, and as messages and tool
+		// results arrive mid-turn so a crash loses little.
+		OnTurnComplete: saveSession,
+		OnMessage:      saveSession,
+		OnToolResult: func(result api.ContentBlock) {
 			if sessionStore != nil && currentSession != nil {
-				currentSession.Messages = h.Messages()
-				if err := sessionStore.Save(currentSession); err != nil {
-					fmt.Fprintf(os.Stderr, "Warning: failed to save session: %v\n", err)
+				if err := sessionStore.AppendToolResult(currentSession.ID, result); err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: failed to save tool result: %v\n", err)
 				}
 			}
 		},
 	})
 	loop.SetFastMode(fastMode)
 
+	// MCP servers can add and remove tools mid-session; send the current
+	// set from the next request on.
+	if mcpManager != nil {
+		mcpManager.OnChange(func() {
+			defs := registry.Definitions()
+			loop.SetTools(defs)
+			agentTool.SetTools(defs)
+		})
+		// Changes seen by resource subscriptions reach the model with
+		// the next turn.
+		mcpManager.OnResourceUpdate(func(u mcp.ResourceUpdate) { loop.Remind(u.Reminder()) })
+	}
+
 	// Apply thinking/effort configuration from CLI flags.
 	thinkingMode := ""
 	if *thinkingFlag != "" {
@@ -556,7 +892,9 @@ func main() {
 			// Phase 7: Select handler based on --output-format.
 			switch *outputFormat {
 			case "json":
-				loop.SetHandler(conversation.NewJSONStreamHandler(os.Stdout))
+				h := conversation.NewJSONStreamHandler(os.Stdout)
+				h.SetSessionID(currentSession.ID)
+				loop.SetHandler(h)
 			case "stream-json":
 				loop.SetHandler(conversation.NewStreamJSONStreamHandler(os.Stdout))
 			default:
@@ -564,18 +902,29 @@ func main() {
 			}
 
 			// Fire SessionStart hook in print mode.
-			_ = hookRunner.RunSessionStart(ctx)
+			source := "startup"
+			if len(currentSession.Messages) > 0 {
+				source = "resume"
+			}
+			_ = hookRunner.RunSessionStart(ctx, source)
 
-			if err := loop.SendMessage(ctx, initialPrompt); err != nil {
+			err := loop.SendMessage(ctx, initialPrompt)
+			_ = hookRunner.RunSessionEnd(context.Background(), "other")
+			hookRunner.Wait(asyncHookGrace)
+			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				bgStore.StopAll()
+				cleanup()
 				os.Exit(1)
 			}
 		}
+		bgStore.StopAll()
+		cleanup()
 		os.Exit(0)
 	}
 
 	// Interactive mode: launch the TUI.
-	app := tui.New(tui.AppConfig{
+	appCfg := tui.AppConfig{
 		Loop:        loop,
 		Session:     currentSession,
 		SessStore:   sessionStore,
@@ -586,6 +935,7 @@ func main() {
 		MCPManager:  mcpManager,
 		Skills:      loadedSkills,  // Phase 7
 		Hooks:       hookRunner,    // Phase 7
+		NoHooks:     *noHooksFlag,
 		Settings:    settings,
 		RuleHandler: ruleHandler,
 		OnModelSwitch: func(newModel string) {
@@ -596,17 +946,56 @@ func main() {
 		LogoutFunc: func() error { return store.Delete() },
 		FastMode:   fastMode,
 		Client:     client,
-	})
+		ShellCwd:   bashTool.Cwd,
+		UndoStore:  undoStore,
+		TodoTool:   todoTool,
+		AskUser:    askUserTool,
+		AgentTools: agentToolNames(registry),
+		Tasks:      bgStore,
+		AgentTool:  agentTool,
+	}
+	if hookTrace != nil {
+		appCfg.WatchHooks = hookTrace.watch
+	}
+	if mcpManager != nil {
+		appCfg.MCPPrompts = mcpPromptCommands(mcpManager)
+		appCfg.GetMCPPrompt = mcpManager.GetPrompt
+		appCfg.WatchPrompts = func(update func([]tui.MCPPrompt)) {
+			mcpManager.OnChange(func() { update(mcpPromptCommands(mcpManager)) })
+		}
+		appCfg.OnAddDir = func(dir string) { mcpManager.AddRoots(ctx, dir) }
+		appCfg.ListResources = func(ctx context.Context) []tui.MCPResource { return mcpResources(ctx, mcpManager) }
+		appCfg.ReadResource = mcpManager.ReadResourceText
+		appCfg.WatchUpdates = func(notify func(string)) {
+			mcpManager.OnResourceUpdate(func(u mcp.ResourceUpdate) { notify(u.Target()) })
+		}
+		if interactive {
+			appCfg.StartMCP = func() { mcpManager.StartServersInBackground(ctx, mcpConfig.MCPServers, registry) }
+		}
+	}
+	app := tui.New(appCfg)
 
 	if initialPrompt != "" {
 		app.SetInitialPrompt(initialPrompt)
 	}
 
-	if err := app.Run(ctx); err != nil {
+	err = app.Run(ctx)
+	hookRunner.Wait(asyncHookGrace)
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		bgStore.StopAll()
+		cleanup()
 		os.Exit(1)
 	}
 
+	// Handle /resume of another project's session: run it in its own
+	// directory, where this process's tools and settings don't apply.
+	if app.ExitAction() == tui.ExitResume {
+		bgStore.StopAll()
+		cleanup()
+		os.Exit(resumeElsewhere(app.ResumeSession()))
+	}
+
 	// Handle /login: the TUI exited requesting a re-authentication flow.
 	if app.ExitAction() == tui.ExitLogin {
 		loginCtx, loginCancel := context.WithCancel(context.Background())
@@ -756,74 +1145,231 @@ func runUpdate(args []string) {
 	fmt.Println("  go install github.com/anthropics/claude-code-go/cmd/claude@latest")
 }
 
-// runMCP handles the `claude mcp` subcommand for MCP server management.
-func runMCP(args []string) {
-	if len(args) == 0 {
-		fmt.Println("Usage: claude mcp <command> [options]")
-		fmt.Println()
-		fmt.Println("Commands:")
-		fmt.Println("  list              List configured MCP servers")
-		fmt.Println("  add <name> <cmd>  Add an MCP server")
-		fmt.Println("  remove <name>     Remove an MCP server")
+// runAgents handles the `claude agents` subcommand for custom agent
+// management.
+func runAgents(args []string) {
+	cwd, _ := os.Getwd()
+	if len(args) == 0 || args[0] == "list" {
+		listAgents(cwd)
 		return
 	}
 
-	cwd, _ := os.Getwd()
-
 	switch args[0] {
-	case "list":
-		mcpCfg, err := mcp.LoadMCPConfig(cwd)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error loading MCP config: %v\n", err)
+	case "create":
+		fs := flag.NewFlagSet("agents create", flag.ExitOnError)
+		description := fs.String("description", "", "When the agent should be used")
+		toolList := fs.String("tools", "", "Comma-separated tools the agent may use (default: all)")
+		model := fs.String("model", "inherit", "Model: "+strings.Join(agents.Models, ", "))
+		maxTurns := fs.Int("max-turns", 0, "Maximum agentic turns (default: no limit)")
+		maxTokens := fs.Int("max-tokens", 0, "Maximum tokens per response (default: the client default)")
+		prompt := fs.String("prompt", "", "System prompt (default: a template to edit)")
+		user := fs.Bool("user", false, "Save in ~/.claude/agents instead of .claude/agents")
+		if len(args) < 2 || strings.HasPrefix(args[1], "-") {
+			fmt.Println("Usage: claude agents create <name> --description <text> [--tools a,b] [--model m] [--max-turns n] [--max-tokens n] [--prompt text] [--user]")
 			os.Exit(1)
 		}
-		if mcpCfg == nil || len(mcpCfg.MCPServers) == 0 {
-			fmt.Println("No MCP servers configured.")
-			return
+		fs.Parse(args[2:])
+		a := agents.Agent{Name: args[1], Description: *description, Model: *model, MaxTurns: *maxTurns, MaxTokens: *maxTokens, SystemPrompt: *prompt}
+		if *toolList != "" {
+			for _, t := range strings.Split(*toolList, ",") {
+				if t = strings.TrimSpace(t); t != "" {
+					a.Tools = append(a.Tools, agents.ToolName(t))
+				}
+			}
+		}
+		if existing, ok := agents.Find(cwd, a.Name); ok {
+			fmt.Fprintf(os.Stderr, "Error: agent %s already exists at %s\n", a.Name, existing.FilePath)
+			os.Exit(1)
 		}
-		fmt.Println("Configured MCP servers:")
-		for name, cfg := range mcpCfg.MCPServers {
-			fmt.Printf("  %s: %s %v\n", name, cfg.Command, cfg.Args)
+		dir, err := agents.Dir(cwd, *user)
+		if err == nil {
+			var path string
+			if path, err = agents.Create(dir, a, nil); err == nil {
+				fmt.Printf("Created agent %s at %s\n", a.Name, path)
+				return
+			}
 		}
+		fmt.Fprintf(os.Stderr, "Error creating agent: %v\n", err)
+		os.Exit(1)
 
-	case "add":
-		if len(args) < 3 {
-			fmt.Println("Usage: claude mcp add <name> <command> [args...]")
+	case "edit":
+		if len(args) < 2 {
+			fmt.Println("Usage: claude agents edit <name>")
 			os.Exit(1)
 		}
-		name := args[1]
-		command := args[2]
-		cmdArgs := args[3:]
-		if err := mcp.AddServerToConfig(cwd, name, command, cmdArgs); err != nil {
-			fmt.Fprintf(os.Stderr, "Error adding MCP server: %v\n", err)
+		a, ok := agents.Find(cwd, args[1])
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Error: no agent named %s\n", args[1])
 			os.Exit(1)
 		}
-		fmt.Printf("Added MCP server: %s\n", name)
+		editor := os.Getenv("VISUAL")
+		if editor == "" {
+			editor = os.Getenv("EDITOR")
+		}
+		if editor == "" {
+			editor = "vi"
+		}
+		parts := append(strings.Fields(editor), a.FilePath)
+		cmd := exec.Command(parts[0], parts[1:]...)
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err := cmd.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error running editor: %v\n", err)
+			os.Exit(1)
+		}
+		if _, err := agents.ValidateFile(a.FilePath, nil); err != nil {
+			fmt.Fprintf(os.Stderr, "Agent file %s has problems:\n  %s\n", a.FilePath, strings.ReplaceAll(err.Error(), "\n", "\n  "))
+			os.Exit(1)
+		}
+		fmt.Printf("Saved agent %s\n", a.Name)
 
-	case "remove":
+	case "delete":
 		if len(args) < 2 {
-			fmt.Println("Usage: claude mcp remove <name>")
+			fmt.Println("Usage: claude agents delete <name>")
+			os.Exit(1)
+		}
+		a, ok := agents.Find(cwd, args[1])
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Error: no agent named %s\n", args[1])
 			os.Exit(1)
 		}
-		name := args[1]
-		if err := mcp.RemoveServerFromConfig(cwd, name); err != nil {
-			fmt.Fprintf(os.Stderr, "Error removing MCP server: %v\n", err)
+		if err := os.Remove(a.FilePath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error deleting agent: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Printf("Removed MCP server: %s\n", name)
+		fmt.Printf("Deleted agent %s (%s)\n", a.Name, a.FilePath)
 
 	default:
-		fmt.Fprintf(os.Stderr, "Unknown mcp command: %s\n", args[0])
+		fmt.Fprintf(os.Stderr, "Unknown agents command: %s\n", args[0])
+		fmt.Println("Usage: claude agents [list | create <name> | edit <name> | delete <name>]")
 		os.Exit(1)
 	}
 }
 
-// runAgents handles the `claude agents` subcommand.
-func runAgents() {
+// agentToolNames returns the registered tool names a custom agent may be
+// given, excluding Agent itself.
+func agentToolNames(registry *tools.Registry) []string {
+	var names []string
+	for _, def := range registry.Definitions() {
+		if def.Name != "Agent" {
+			names = append(names, def.Name)
+		}
+	}
+	return names
+}
+
+// mcpPromptCommands converts the prompts discovered on MCP servers into
+// the TUI's slash command descriptions.
+func mcpPromptCommands(m *mcp.Manager) []tui.MCPPrompt {
+	var out []tui.MCPPrompt
+	for _, p := range m.Prompts() {
+		cmd := tui.MCPPrompt{
+			Command:     p.CommandName(),
+			Server:      p.Server,
+			Name:        p.Name,
+			Description: p.Description,
+		}
+		for _, a := range p.Arguments {
+			cmd.Args = append(cmd.Args, tui.MCPPromptArg{Name: a.Name, Description: a.Description, Required: a.Required})
+		}
+		out = append(out, cmd)
+	}
+	return out
+}
+
+// mcpResources lists the MCP servers' resources for @-mention completion.
+func mcpResources(ctx context.Context, m *mcp.Manager) []tui.MCPResource {
+	var out []tui.MCPResource
+	for _, r := range m.Resources(ctx) {
+		out = append(out, tui.MCPResource{Server: r.Server, URI: r.URI, Name: r.Name, Description: r.Description})
+	}
+	return out
+}
+
+// mcpSampler creates the sampler that answers MCP servers' sampling
+// requests with client. Under the "ask" policy, requests go through the
+// permission handler as the pseudo-tool "MCPSampling", so permission rules
+// and modes apply and the TUI prompts for the rest.
+func mcpSampler(client *api.Client, cfg *config.MCPSamplingConfig, registry *tools.Registry) *mcp.Sampler {
+	sc := mcp.SamplerConfig{
+		Client: client,
+		Approve: func(ctx context.Context, server string, params *mcp.CreateMessageParams) (bool, error) {
+			var prompt []string
+			for _, msg := range params.Messages {
+				if msg.Content.Type == "text" {
+					prompt = append(prompt, msg.Content.Text)
+				}
+			}
+			input, _ := json.Marshal(map[string]any{
+				"server":       server,
+				"prompt":       strings.Join(prompt, "\n"),
+				"systemPrompt": params.SystemPrompt,
+				"maxTokens":    params.MaxTokens,
+			})
+			return registry.RequestPermission(ctx, "MCPSampling", input)
+		},
+	}
+	if cfg != nil {
+		sc.Policy = cfg.Policy
+		sc.Servers = cfg.Servers
+		sc.MaxTokens = cfg.MaxTokens
+	}
+	return mcp.NewSampler(sc)
+}
+
+// listAgents prints the custom and built-in agents available in cwd.
+func listAgents(cwd string) {
+	defs := agents.All(cwd)
 	fmt.Println("Configured agents:")
-	fmt.Println("  (No custom agents configured)")
-	fmt.Println()
-	fmt.Println("Agents can be configured in .claude/settings.json")
+	for _, a := range defs {
+		fmt.Printf("  %s (%s)", a.Name, a.Source)
+		if a.Description != "" {
+			fmt.Printf(": %s", a.Description)
+		}
+		fmt.Println()
+		tools := "all"
+		if a.Tools != nil {
+			tools = strings.Join(a.Tools, ", ")
+		}
+		model := a.Model
+		if model == "" {
+			model = "inherit"
+		}
+		fmt.Printf("    tools: %s; model: %s", tools, model)
+		if a.MaxTurns > 0 {
+			fmt.Printf("; max turns: %d", a.MaxTurns)
+		}
+		if a.MaxTokens > 0 {
+			fmt.Printf("; max tokens: %d", a.MaxTokens)
+		}
+		fmt.Println()
+		if a.FilePath == "" {
+			continue
+		}
+		if _, err := agents.ValidateFile(a.FilePath, nil); err != nil {
+			fmt.Printf("    problems: %s\n", strings.ReplaceAll(err.Error(), "\n", "; "))
+		}
+	}
+	if !slices.ContainsFunc(defs, func(a agents.Agent) bool { return a.FilePath != "" }) {
+		fmt.Println()
+		fmt.Println("No custom agents configured. Create one with `claude agents create <name>` or /agents, or add markdown files to .claude/agents/ or ~/.claude/agents/")
+	}
+}
+
+// samplingParam resolves a sampling parameter from a CLI flag value (if
+// set) or a settings value, validating that it falls within [0, 1].
+func samplingParam(name, flagValue string, setting *float64) (*float64, error) {
+	v := setting
+	if flagValue != "" {
+		f, err := strconv.ParseFloat(flagValue, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --%s value %q", name, flagValue)
+		}
+		v = &f
+	}
+	if v != nil && (*v < 0 || *v > 1) {
+		return nil, fmt.Errorf("%s must be between 0 and 1, got %g", name, *v)
+	}
+	return v, nil
 }
 
 // showBypassPermissionsWarning displays a warning dialog for bypass permissions mode.
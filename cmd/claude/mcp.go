@@ -0,0 +1,703 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/anthropics/claude-code-go/internal/config"
+	"github.com/anthropics/claude-code-go/internal/mcp"
+	"github.com/anthropics/claude-code-go/internal/tools"
+)
+
+// mcpCheckTimeout bounds how long `claude mcp list --verbose` and
+// `claude mcp get` wait for each server to start and initialize.
+const mcpCheckTimeout = 15 * time.Second
+
+// runMCP handles the `claude mcp` subcommand for MCP server management.
+// The commands and options match the JS CLI.
+func runMCP(args []string) {
+	if len(args) == 0 {
+		fmt.Println("Usage: claude mcp <command> [options]")
+		fmt.Println()
+		fmt.Println("Commands:")
+		fmt.Println("  list [--verbose]                          List configured MCP servers")
+		fmt.Println("  get <name>                                Show details and status of an MCP server")
+		fmt.Println("  logs [--lines <n>] <name>                 Show what a stdio MCP server wrote to stderr")
+		fmt.Println("  add [options] <name> <cmd|url> [args...]  Add an MCP server")
+		fmt.Println("  add-json [--scope <s>] <name> <json>      Add an MCP server from a JSON definition")
+		fmt.Println("  remove [--scope <s>] <name>               Remove an MCP server")
+		fmt.Println("  enable <name>                             Start a disabled MCP server again")
+		fmt.Println("  disable <name>                            Stop starting an MCP server in this project")
+		fmt.Println("  import-from-claude-desktop [--scope <s>]  Import MCP servers from Claude Desktop")
+		fmt.Println("  reset-project-choices                     Forget approvals of this project's MCP servers")
+		fmt.Println("  serve                                     Serve the built-in tools as an MCP server over stdio")
+		fmt.Println()
+		fmt.Println("Options:")
+		fmt.Println("  -s, --scope <scope>          Config scope: local (default), project, or user")
+		fmt.Println("  -t, --transport <type>       Transport for add: stdio (default), sse, or http")
+		fmt.Println("  -e, --env <KEY=value>        Environment variable for a stdio server (repeatable)")
+		fmt.Println("  -H, --header <Name: value>   HTTP header for an sse or http server (repeatable)")
+		fmt.Println("  -v, --verbose                Show transport, scope, and connection status in list")
+		fmt.Println("  -n, --lines <n>              Lines of log to show (default 100, 0 for all)")
+		return
+	}
+
+	cwd, _ := os.Getwd()
+	opts, pos, err := parseMCPArgs(args[1:], args[0] == "add")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "list":
+		mcpList(cwd, opts.verbose)
+
+	case "get":
+		if len(pos) != 1 {
+			fmt.Println("Usage: claude mcp get <name>")
+			os.Exit(1)
+		}
+		mcpGet(cwd, pos[0])
+
+	case "logs":
+		if len(pos) != 1 {
+			fmt.Println("Usage: claude mcp logs [--lines <n>] <name>")
+			os.Exit(1)
+		}
+		mcpLogs(cwd, pos[0], opts.lines)
+
+	case "add":
+		if len(pos) < 2 {
+			fmt.Println("Usage: claude mcp add [-s scope] [-t transport] [-e KEY=value] [-H 'Name: value'] <name> <command|url> [args...]")
+			os.Exit(1)
+		}
+		cfg := mcp.ServerConfig{Type: opts.transport}
+		switch opts.transport {
+		case "", "stdio":
+			if len(opts.headers) > 0 {
+				fmt.Fprintln(os.Stderr, "Error: headers only apply to sse and http servers")
+				os.Exit(1)
+			}
+			cfg.Command, cfg.Args, cfg.Env = pos[1], pos[2:], opts.env
+		default:
+			if len(pos) > 2 || len(opts.env) > 0 {
+				fmt.Fprintf(os.Stderr, "Error: %s servers take a URL, not arguments or environment variables\n", opts.transport)
+				os.Exit(1)
+			}
+			cfg.URL, cfg.Headers = pos[1], opts.headers
+		}
+		mcpAdd(cwd, opts.scope, pos[0], cfg)
+
+	case "add-json":
+		if len(pos) != 2 {
+			fmt.Println("Usage: claude mcp add-json [-s scope] <name> <json>")
+			os.Exit(1)
+		}
+		cfg, err := mcp.ParseServerJSON(pos[1])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		mcpAdd(cwd, opts.scope, pos[0], cfg)
+
+	case "remove":
+		if len(pos) != 1 {
+			fmt.Println("Usage: claude mcp remove [-s scope] <name>")
+			os.Exit(1)
+		}
+		scope, err := mcp.RemoveServer(cwd, opts.scope, pos[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error removing MCP server: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Removed MCP server %s from %s config\n", pos[0], scope)
+
+	case "enable", "disable":
+		if len(pos) != 1 {
+			fmt.Printf("Usage: claude mcp %s <name>\n", args[0])
+			os.Exit(1)
+		}
+		if err := mcp.SetServerEnabled(cwd, pos[0], args[0] == "enable"); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("MCP server %s %sd for this project\n", pos[0], args[0])
+
+	case "import-from-claude-desktop", "add-from-claude-desktop":
+		mcpImportFromDesktop(cwd, opts.scope)
+
+	case "reset-project-choices":
+		store, err := loadMCPTrust()
+		if err == nil {
+			store.Reset(cwd)
+			err = store.Save()
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("All approvals and rejections of project MCP servers have been reset.")
+		fmt.Println("You will be asked about them again the next time you start Claude in this project.")
+
+	case "serve":
+		if len(pos) != 0 {
+			fmt.Println("Usage: claude mcp serve")
+			os.Exit(1)
+		}
+		if err := mcpServe(cwd); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown mcp command: %s\n", args[0])
+		os.Exit(1)
+	}
+}
+
+// mcpOptions are the options accepted by the mcp commands.
+type mcpOptions struct {
+	scope     string
+	transport string
+	env       map[string]string
+	headers   map[string]string
+	verbose   bool
+	lines     int
+}
+
+// parseMCPArgs separates options from positional arguments. Options may
+// appear anywhere before "--". For add, everything after the command is
+// passed to the server, so its own flags need no "--".
+func parseMCPArgs(args []string, add bool) (mcpOptions, []string, error) {
+	opts := mcpOptions{lines: 100}
+	var pos []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" || (add && len(pos) >= 2) {
+			if arg == "--" {
+				i++
+			}
+			pos = append(pos, args[i:]...)
+			break
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			pos = append(pos, arg)
+			continue
+		}
+
+		name, value, hasValue := strings.Cut(arg, "=")
+		if name == "-v" || name == "--verbose" {
+			opts.verbose = true
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return opts, nil, fmt.Errorf("option %s needs a value", name)
+			}
+			i++
+			value = args[i]
+		}
+		switch name {
+		case "-s", "--scope":
+			if _, err := mcp.ScopePath("", value); err != nil {
+				return opts, nil, err
+			}
+			opts.scope = value
+		case "-t", "--transport":
+			if value != "stdio" && value != "sse" && value != "http" {
+				return opts, nil, fmt.Errorf("invalid transport %q (must be stdio, sse, or http)", value)
+			}
+			opts.transport = value
+		case "-e", "--env":
+			k, v, ok := strings.Cut(value, "=")
+			if !ok || k == "" {
+				return opts, nil, fmt.Errorf("invalid environment variable %q (want KEY=value)", value)
+			}
+			if opts.env == nil {
+				opts.env = make(map[string]string)
+			}
+			opts.env[k] = v
+		case "-H", "--header":
+			k, v, ok := strings.Cut(value, ":")
+			k, v = strings.TrimSpace(k), strings.TrimSpace(v)
+			if !ok || k == "" || strings.ContainsAny(k, " \t") {
+				return opts, nil, fmt.Errorf("invalid header %q (want 'Name: value')", value)
+			}
+			if opts.headers == nil {
+				opts.headers = make(map[string]string)
+			}
+			opts.headers[k] = v
+		case "-n", "--lines":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return opts, nil, fmt.Errorf("invalid line count %q", value)
+			}
+			opts.lines = n
+		default:
+			return opts, nil, fmt.Errorf("unknown option %s", name)
+		}
+	}
+	return opts, pos, nil
+}
+
+// mcpAdd adds a server to the given scope, local by default.
+func mcpAdd(cwd, scope, name string, cfg mcp.ServerConfig) {
+	if scope == "" {
+		scope = mcp.ScopeLocal
+	}
+	if err := mcp.AddServer(cwd, scope, name, cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "Error adding MCP server: %v\n", err)
+		os.Exit(1)
+	}
+	approveMCPServers(cwd, scope, map[string]mcp.ServerConfig{name: cfg})
+	path, _ := mcp.ScopePath(cwd, scope)
+	fmt.Printf("Added %s MCP server %s to %s config\n", cfg.TransportType(), name, scope)
+	fmt.Printf("File modified: %s\n", path)
+}
+
+// mcpImportFromDesktop lists the servers configured in Claude Desktop,
+// asks which to import, and adds them to the given scope, local by
+// default.
+func mcpImportFromDesktop(cwd, scope string) {
+	if scope == "" {
+		scope = mcp.ScopeLocal
+	}
+	path, err := mcp.DesktopConfigPath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	servers, err := mcp.LoadDesktopServers(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(servers) == 0 {
+		fmt.Printf("No MCP servers found in %s\n", path)
+		return
+	}
+
+	names := make([]string, 0, len(servers))
+	for name := range servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Printf("Found %d MCP servers in Claude Desktop:\n", len(names))
+	for i, name := range names {
+		fmt.Printf("  %d. %s: %s\n", i+1, name, describeMCPServer(servers[name]))
+	}
+	fmt.Printf("Select servers to import into %s config (comma-separated numbers, \"all\", or empty to cancel): ", scope)
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return
+	}
+	selected, err := parseSelection(line, names)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(selected) == 0 {
+		fmt.Println("No servers imported.")
+		return
+	}
+
+	added, err := mcp.ImportServers(cwd, scope, selected, servers)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error importing MCP servers: %v\n", err)
+		os.Exit(1)
+	}
+	approved := make(map[string]mcp.ServerConfig)
+	for i, name := range added {
+		approved[name] = servers[selected[i]]
+	}
+	approveMCPServers(cwd, scope, approved)
+	for i, name := range added {
+		if name != selected[i] {
+			fmt.Printf("Imported %s as %s (name already in use)\n", selected[i], name)
+		} else {
+			fmt.Printf("Imported %s\n", name)
+		}
+	}
+	path, _ = mcp.ScopePath(cwd, scope)
+	fmt.Printf("File modified: %s\n", path)
+}
+
+// parseSelection turns a comma-separated list of 1-based numbers, or
+// "all", into the chosen names.
+func parseSelection(input string, names []string) ([]string, error) {
+	input = strings.TrimSpace(input)
+	if strings.EqualFold(input, "all") {
+		return names, nil
+	}
+	var selected []string
+	seen := make(map[int]bool)
+	for _, part := range strings.Split(input, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 1 || n > len(names) {
+			return nil, fmt.Errorf("invalid selection %q (want numbers from 1 to %d)", part, len(names))
+		}
+		if !seen[n] {
+			seen[n] = true
+			selected = append(selected, names[n-1])
+		}
+	}
+	return selected, nil
+}
+
+// loadMCPTrust loads the record of approved project servers.
+func loadMCPTrust() (*mcp.TrustStore, error) {
+	path, err := mcp.DefaultTrustPath()
+	if err != nil {
+		return nil, err
+	}
+	return mcp.LoadTrustStore(path)
+}
+
+// approveMCPServers records servers the user just added to a project
+// scope as approved, since adding them is consent to run them.
+func approveMCPServers(cwd, scope string, servers map[string]mcp.ServerConfig) {
+	if !mcp.NeedsTrust(mcp.ConfiguredServer{Scope: scope}) {
+		return
+	}
+	store, err := loadMCPTrust()
+	if err == nil {
+		for name, cfg := range servers {
+			store.Record(cwd, name, cfg, mcp.TrustApprove)
+		}
+		err = store.Save()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not record MCP server approval: %v\n", err)
+	}
+}
+
+// trustedMCPConfig drops the project servers the user hasn't approved
+// from cfg, asking about new ones with ask if it isn't nil.
+func trustedMCPConfig(cwd string, cfg *mcp.MCPConfig, ask mcp.TrustPrompt) *mcp.MCPConfig {
+	if cfg == nil {
+		return nil
+	}
+	store, err := loadMCPTrust()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: MCP server approvals: %v; project MCP servers will not start\n", err)
+		for _, s := range mcp.ListServers(cwd) {
+			if mcp.NeedsTrust(s) {
+				delete(cfg.MCPServers, s.Name)
+			}
+		}
+		return cfg
+	}
+	trusted, err := store.Filter(cwd, cfg, ask)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	if skipped := len(cfg.MCPServers) - len(trusted.MCPServers); skipped > 0 && ask == nil {
+		fmt.Fprintf(os.Stderr, "Note: %d project MCP servers are not approved and were not started; run claude interactively to review them\n", skipped)
+	}
+	return trusted
+}
+
+// askMCPTrust returns a TrustPrompt that asks on the terminal.
+func askMCPTrust(reader *bufio.Reader) mcp.TrustPrompt {
+	return func(s mcp.ConfiguredServer) (string, error) {
+		fmt.Println()
+		fmt.Printf("New MCP server found in .mcp.json: %s\n", s.Name)
+		fmt.Printf("  %s (%s)\n", describeMCPServer(s.Config), s.Config.TransportType())
+		fmt.Println()
+		fmt.Println("MCP servers may execute code or access system resources. Only approve")
+		fmt.Println("servers from sources you trust.")
+		fmt.Println()
+		fmt.Print("Use this server? [y]es, [n]o, or [a]ll servers in this project, now and later: ")
+
+		line, err := reader.ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("reading answer: %w", err)
+		}
+		switch strings.ToLower(strings.TrimSpace(line)) {
+		case "y", "yes":
+			return mcp.TrustApprove, nil
+		case "a", "all":
+			return mcp.TrustAll, nil
+		}
+		return mcp.TrustReject, nil
+	}
+}
+
+// mcpList prints the configured servers. With verbose, it shows each
+// server's transport and scope and checks that it connects.
+func mcpList(cwd string, verbose bool) {
+	servers := mcp.ListServers(cwd)
+	if len(servers) == 0 {
+		fmt.Println("No MCP servers configured. Use `claude mcp add` to add a server.")
+		return
+	}
+	if !verbose {
+		fmt.Println("Configured MCP servers:")
+		for _, s := range servers {
+			line := fmt.Sprintf("  %s: %s", s.Name, describeMCPServer(s.Config))
+			if s.Disabled {
+				line += " (disabled)"
+			}
+			fmt.Println(line)
+		}
+		return
+	}
+
+	fmt.Println("Checking MCP server health...")
+	fmt.Println()
+	statuses := checkMCPServers(cwd, servers)
+	for i, s := range servers {
+		fmt.Printf("%s: %s (%s, %s) - %s\n", s.Name, describeMCPServer(s.Config), s.Config.TransportType(), s.Scope, statuses[i])
+	}
+}
+
+// mcpGet prints the details and status of one server.
+func mcpGet(cwd, name string) {
+	s, err := mcp.FindServer(cwd, name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	status := checkMCPServers(cwd, []mcp.ConfiguredServer{*s})[0]
+
+	fmt.Printf("%s:\n", s.Name)
+	fmt.Printf("  Scope: %s\n", mcp.ScopeDescription(s.Scope))
+	fmt.Printf("  Status: %s\n", status)
+	fmt.Printf("  Type: %s\n", s.Config.TransportType())
+	if s.Config.URL != "" {
+		fmt.Printf("  URL: %s\n", s.Config.URL)
+	} else {
+		fmt.Printf("  Command: %s\n", s.Config.Command)
+		fmt.Printf("  Args: %s\n", strings.Join(s.Config.Args, " "))
+	}
+	if len(s.Config.Env) > 0 {
+		fmt.Println("  Environment:")
+		keys := make([]string, 0, len(s.Config.Env))
+		for k := range s.Config.Env {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Printf("    %s=%s\n", k, s.Config.Env[k])
+		}
+	}
+	if len(s.Config.Headers) > 0 {
+		fmt.Println("  Headers:")
+		keys := make([]string, 0, len(s.Config.Headers))
+		for k := range s.Config.Headers {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Printf("    %s: %s\n", k, s.Config.Headers[k])
+		}
+	}
+	if len(s.Config.IncludeTools) > 0 {
+		fmt.Printf("  Include tools: %s\n", strings.Join(s.Config.IncludeTools, ", "))
+	}
+	if len(s.Config.ExcludeTools) > 0 {
+		fmt.Printf("  Exclude tools: %s\n", strings.Join(s.Config.ExcludeTools, ", "))
+	}
+	if s.Config.MaxRestarts != 0 {
+		fmt.Printf("  Max restarts: %d\n", s.Config.MaxRestarts)
+	}
+	if l := s.Config.Limits; l != nil {
+		if l.CPUSeconds > 0 {
+			fmt.Printf("  CPU limit: %ds\n", l.CPUSeconds)
+		}
+		if l.MemoryMB > 0 {
+			fmt.Printf("  Memory limit: %d MB (address space)\n", l.MemoryMB)
+		}
+	}
+	fmt.Println()
+	fmt.Printf("To remove this server, run: claude mcp remove %q -s %s\n", s.Name, s.Scope)
+}
+
+// mcpLogs prints the end of a server's log file, or all of it if lines
+// is 0.
+func mcpLogs(cwd, name string, lines int) {
+	if _, err := mcp.FindServer(cwd, name); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	path, err := mcp.LogPath(cwd, name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		fmt.Printf("No logs for MCP server %s yet. Only stdio servers write logs, once started.\n", name)
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	text := strings.TrimRight(string(data), "\n")
+	if all := strings.Split(text, "\n"); lines > 0 && len(all) > lines {
+		text = strings.Join(all[len(all)-lines:], "\n")
+	}
+	fmt.Printf("==> %s <==\n", path)
+	fmt.Println(text)
+}
+
+// checkMCPServers connects to each enabled server in parallel and returns
+// a status line for each. Project servers the user hasn't approved are
+// not started.
+func checkMCPServers(cwd string, servers []mcp.ConfiguredServer) []string {
+	statuses := make([]string, len(servers))
+	approved := approvedMCPServers(cwd, servers)
+	m := mcp.NewManager(cwd)
+	defer m.Shutdown()
+	var wg sync.WaitGroup
+	for i, s := range servers {
+		if s.Disabled {
+			statuses[i] = "disabled"
+			continue
+		}
+		if !approved[s.Name] {
+			statuses[i] = "not approved (run claude in this project to review it)"
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), mcpCheckTimeout)
+			defer cancel()
+			info, err := m.CheckServer(ctx, s.Name, s.Config)
+			switch {
+			case err != nil:
+				statuses[i] = fmt.Sprintf("✗ Failed to connect: %v", err)
+			case info.Name != "":
+				statuses[i] = fmt.Sprintf("✓ Connected (%s %s)", info.Name, info.Version)
+			default:
+				statuses[i] = "✓ Connected"
+			}
+		}()
+	}
+	wg.Wait()
+	return statuses
+}
+
+// approvedMCPServers returns which of servers may be started: those that
+// don't need approval, and the project servers the user approved.
+func approvedMCPServers(cwd string, servers []mcp.ConfiguredServer) map[string]bool {
+	approved := make(map[string]bool)
+	cfg := &mcp.MCPConfig{MCPServers: make(map[string]mcp.ServerConfig)}
+	for _, s := range servers {
+		if mcp.NeedsTrust(s) {
+			cfg.MCPServers[s.Name] = s.Config
+		} else {
+			approved[s.Name] = true
+		}
+	}
+	if len(cfg.MCPServers) == 0 {
+		return approved
+	}
+	store, err := loadMCPTrust()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: MCP server approvals: %v\n", err)
+		return approved
+	}
+	trusted, _ := store.Filter(cwd, cfg, nil)
+	for name := range trusted.MCPServers {
+		approved[name] = true
+	}
+	return approved
+}
+
+// mcpServe serves the file, search, shell, and WebFetch tools over MCP on
+// stdin and stdout, so other MCP clients can use this machine's tools.
+// Tools that need a session, such as Agent and AskUserQuestion, aren't
+// offered, nor is WebSearch, which only the API can run.
+// The client is expected to ask its user before calling tools; deny rules
+// in settings still apply. Nothing but protocol messages may go to stdout.
+func mcpServe(cwd string) error {
+	settings, err := config.LoadSettings(cwd)
+	if err != nil {
+		return fmt.Errorf("load settings: %w", err)
+	}
+
+	registry := tools.NewRegistry(config.NewRuleBasedPermissionHandler(settings.Permissions, clientApproves{}))
+	if len(settings.ToolLimits) > 0 {
+		registry.SetLimits(settings.ToolLimits)
+	}
+	if sr := settings.SecretRedaction; sr != nil && config.BoolVal(sr.Enabled, false) {
+		if redactor, err := tools.NewSecretRedactor(sr.Allow); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: secret redaction disabled: %v\n", err)
+		} else {
+			registry.Use(redactor)
+		}
+	}
+
+	bashTool := tools.NewBashToolWithEnv(cwd, settings.Env)
+	bashTool.SetPersistentShell(config.BoolVal(settings.PersistentShell, false))
+	defer bashTool.Close()
+	registry.Register(bashTool)
+	undoStore := tools.NewUndoStore()
+	readTracker := tools.NewReadTracker()
+	fileReadTool := tools.NewFileReadTool()
+	fileReadTool.SetReadTracker(readTracker)
+	registry.Register(fileReadTool)
+	fileEditTool := tools.NewFileEditTool()
+	fileEditTool.SetUndoStore(undoStore)
+	fileEditTool.SetReadTracker(readTracker)
+	registry.Register(fileEditTool)
+	fileWriteTool := tools.NewFileWriteTool()
+	fileWriteTool.SetUndoStore(undoStore)
+	fileWriteTool.SetReadTracker(readTracker)
+	registry.Register(fileWriteTool)
+	respectGitignore := config.BoolVal(settings.RespectGitignore, true)
+	globTool := tools.NewGlobTool(cwd)
+	globTool.SetRespectGitignore(respectGitignore)
+	registry.Register(globTool)
+	grepTool := tools.NewGrepTool(cwd)
+	grepTool.SetRespectGitignore(respectGitignore)
+	registry.Register(grepTool)
+	registry.Register(tools.NewLSTool(cwd))
+	notebookReadTool := tools.NewNotebookReadTool()
+	notebookReadTool.SetReadTracker(readTracker)
+	registry.Register(notebookReadTool)
+	notebookEditTool := tools.NewNotebookEditTool()
+	notebookEditTool.SetUndoStore(undoStore)
+	notebookEditTool.SetReadTracker(readTracker)
+	registry.Register(notebookEditTool)
+	registry.Register(tools.NewWebFetchTool(nil))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return mcp.NewServer("claude", version, registry).Serve(ctx, os.Stdin, os.Stdout)
+}
+
+// clientApproves allows every tool call the rules leave undecided. When
+// serving, there is no terminal to ask on; the MCP client asks its user.
+type clientApproves struct{}
+
+func (clientApproves) RequestPermission(context.Context, string, json.RawMessage) (bool, error) {
+	return true, nil
+}
+
+// describeMCPServer returns a server's URL, or its command line.
+func describeMCPServer(cfg mcp.ServerConfig) string {
+	if cfg.URL != "" {
+		return cfg.URL
+	}
+	return strings.TrimSpace(cfg.Command + " " + strings.Join(cfg.Args, " "))
+}
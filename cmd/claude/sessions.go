@@ -0,0 +1,940 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+
+	"github.com/anthropics/claude-code-go/internal/config"
+	"github.com/anthropics/claude-code-go/internal/session"
+)
+
+// runSessions handles the `claude sessions` subcommand, which manages saved
+// sessions without starting the TUI.
+func runSessions(args []string) {
+	if len(args) == 0 {
+		fmt.Println("Usage: claude sessions <command> [options]")
+		fmt.Println()
+		fmt.Println("Commands:")
+		fmt.Println("  list                                                          List sessions, newest first")
+		fmt.Println("  show <id>                                                     Show a session's details and conversation")
+		fmt.Println("  search <text>                                                 Find sessions whose title or messages contain text")
+		fmt.Println("  diff [--stat] <id>                                            Show the cumulative diff of files a session changed")
+		fmt.Println("  rename <id> <name>                                            Name a session, to resume it with claude -r <name>")
+		fmt.Println("  pin [--unpin] <id>                                            Keep a session from being pruned")
+		fmt.Println("  prune [--dry-run]                                             Delete sessions past the sessionRetention limits")
+		fmt.Println("  delete [--yes] <id>...                                        Delete sessions")
+		fmt.Println("  export <id> [--format md|html|text|bundle] [--output <file>]  Export a session")
+		fmt.Println("  import <file>                                                 Import a session bundle to resume it here")
+		fmt.Println("  sync                                                          Push and pull sessions to the sessionSync remote")
+		fmt.Println()
+		fmt.Println("Options:")
+		fmt.Println("  --project <dir>   Use the sessions of another project directory")
+		fmt.Println("  --all             Use the sessions of every project")
+		fmt.Println("  --since <time>    Only sessions updated since a date (2006-01-02) or age (7d, 12h)")
+		fmt.Println("  --until <time>    Only sessions updated before a date or age")
+		return
+	}
+
+	cwd, _ := os.Getwd()
+	if settings, err := config.LoadSettings(cwd); err == nil {
+		if err := setupSessionEncryption(settings.SessionEncryption); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	switch args[0] {
+	case "list":
+		sessionsList(args[1:])
+	case "show":
+		sessionsShow(args[1:])
+	case "search":
+		sessionsSearch(args[1:])
+	case "diff":
+		sessionsDiff(args[1:])
+	case "rename":
+		sessionsRename(args[1:])
+	case "pin":
+		sessionsPin(args[1:])
+	case "prune":
+		sessionsPrune(args[1:])
+	case "delete":
+		sessionsDelete(args[1:])
+	case "export":
+		sessionsExport(args[1:])
+	case "import":
+		sessionsImport(args[1:])
+	case "sync":
+		sessionsSync(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown sessions command: %s\n", args[0])
+		os.Exit(1)
+	}
+}
+
+// setupSessionEncryption turns on encryption at rest for sessions when the
+// sessionEncryption setting asks for it. A passphrase comes from
+// CLAUDE_SESSION_PASSPHRASE, or is prompted for on a terminal.
+func setupSessionEncryption(mode string) error {
+	if mode == "" {
+		return nil
+	}
+	key, err := session.EncryptionKey(mode, func() (string, error) {
+		if pass := os.Getenv("CLAUDE_SESSION_PASSPHRASE"); pass != "" {
+			return pass, nil
+		}
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			return "", fmt.Errorf("sessions are encrypted with a passphrase; set CLAUDE_SESSION_PASSPHRASE")
+		}
+		fmt.Fprint(os.Stderr, "Session passphrase: ")
+		pass, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("reading passphrase: %w", err)
+		}
+		return string(pass), nil
+	})
+	if err != nil {
+		return fmt.Errorf("session encryption: %w", err)
+	}
+	return session.SetEncryptionKey(key)
+}
+
+// sessionScope selects the projects whose sessions a command sees: the
+// current directory by default, --project, or --all.
+type sessionScope struct {
+	project string
+	all     bool
+}
+
+func (sc *sessionScope) register(fs *flag.FlagSet) {
+	fs.StringVar(&sc.project, "project", "", "Project directory (default: current directory)")
+	fs.BoolVar(&sc.all, "all", false, "Sessions of every project")
+}
+
+// stores returns the session stores in scope.
+func (sc *sessionScope) stores() ([]*session.Store, error) {
+	if sc.all {
+		if sc.project != "" {
+			return nil, fmt.Errorf("--project and --all can't be used together")
+		}
+		return session.AllStores()
+	}
+	dir := sc.project
+	if dir == "" {
+		dir, _ = os.Getwd()
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	store, err := session.NewStore(dir)
+	if err != nil {
+		return nil, err
+	}
+	return []*session.Store{store}, nil
+}
+
+// search returns the sessions in scope that pass the filter, newest
+// first.
+func (sc *sessionScope) search(f session.Filter) ([]session.Match, error) {
+	stores, err := sc.stores()
+	if err != nil {
+		return nil, err
+	}
+	var all []session.Match
+	for _, store := range stores {
+		matches, err := store.Search(f)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, matches...)
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].Session.UpdatedAt.After(all[j].Session.UpdatedAt) })
+	return all, nil
+}
+
+// find loads a session by ID or name from the first store in scope that
+// has it. The store returned can also reach the session's transcript.
+func (sc *sessionScope) find(ref string) (*session.Session, *session.Store, error) {
+	if ref == "" || strings.ContainsAny(ref, `/\`) {
+		return nil, nil, fmt.Errorf("invalid session ID %q", ref)
+	}
+	stores, err := sc.stores()
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, store := range stores {
+		id, err := store.Resolve(ref)
+		if err != nil {
+			continue
+		}
+		sess, err := store.Load(id)
+		if err != nil {
+			return nil, nil, err
+		}
+		// Stores from AllStores don't know the transcript directory.
+		if sess.CWD != "" {
+			if own, err := session.NewStore(sess.CWD); err == nil && own.Dir() == store.Dir() {
+				store = own
+			}
+		}
+		return sess, store, nil
+	}
+	return nil, nil, fmt.Errorf("session %s not found", ref)
+}
+
+// sessionFilterFlags are the --since and --until options.
+type sessionFilterFlags struct {
+	since, until string
+}
+
+func (ff *sessionFilterFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&ff.since, "since", "", "Only sessions updated since a date (2006-01-02) or age (7d, 12h)")
+	fs.StringVar(&ff.until, "until", "", "Only sessions updated before a date or age")
+}
+
+// filter builds a session filter from the options and a search text.
+func (ff *sessionFilterFlags) filter(text string) (session.Filter, error) {
+	f := session.Filter{Text: text}
+	now := time.Now()
+	var err error
+	if ff.since != "" {
+		if f.Since, err = session.ParseFilterTime(ff.since, now, false); err != nil {
+			return f, err
+		}
+	}
+	if ff.until != "" {
+		if f.Until, err = session.ParseFilterTime(ff.until, now, true); err != nil {
+			return f, err
+		}
+	}
+	return f, nil
+}
+
+// parseSessionsArgs parses flags that may come before, after, or between
+// positional arguments, and returns the positional ones.
+func parseSessionsArgs(fs *flag.FlagSet, args []string) []string {
+	var pos []string
+	for {
+		fs.Parse(args)
+		if fs.NArg() == 0 {
+			return pos
+		}
+		pos = append(pos, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// sessionsFatal prints an error and exits.
+func sessionsFatal(err error) {
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	os.Exit(1)
+}
+
+// printSessionMatches prints one line per session, with its name if it
+// has one and whether it is pinned, and under it its cost, tokens, and models and the snippet that
+// matched a search. With several projects, each line names the session's
+// directory.
+func printSessionMatches(matches []session.Match, showProject bool) {
+	for _, m := range matches {
+		sess := m.Session
+		line := fmt.Sprintf("%s  %s  %4d msgs  ", sess.ID, sess.UpdatedAt.Local().Format("2006-01-02 15:04"), len(sess.Messages))
+		if showProject {
+			line += sess.CWD + "  "
+		}
+		if sess.Name != "" {
+			line += "[" + sess.Name + "]  "
+		}
+		if sess.Pinned {
+			line += "[pinned]  "
+		}
+		fmt.Println(line + sess.DisplayTitle())
+		if usage := sess.TotalUsage().String(); usage != "" {
+			fmt.Println("    " + usage)
+		}
+		if m.Snippet != "" {
+			fmt.Println("    " + m.Snippet)
+		}
+	}
+}
+
+// resumeContext returns the CLAUDE.md files and skills a resumed session
+// runs with. When current differs from the snapshot pinned to the
+// session, mode (the resumeContext setting) picks: "pinned", "current",
+// or by default asking on a terminal and keeping the snapshot otherwise.
+// Choosing current pins it in the snapshot's place.
+func resumeContext(store *session.Store, id string, current *session.ContextSnapshot, mode string, interactive bool, stdin *bufio.Reader) *session.ContextSnapshot {
+	pinned, err := store.PinnedContext(id)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v; using the current CLAUDE.md and skills\n", err)
+		return current
+	}
+	if pinned == nil {
+		return current
+	}
+	changes := session.ContextChanges(pinned, current)
+	if len(changes) == 0 {
+		return pinned
+	}
+
+	useCurrent := mode == "current"
+	if mode != "current" && mode != "pinned" {
+		if !interactive {
+			fmt.Fprintf(os.Stderr, "CLAUDE.md or skills changed since session %s started; using its pinned snapshot. Set resumeContext to \"current\" to use the files now.\n", id)
+			return pinned
+		}
+		fmt.Println()
+		fmt.Printf("CLAUDE.md or skills changed since session %s started (%s):\n", id, pinned.TakenAt.Local().Format("2006-01-02 15:04"))
+		for _, c := range changes {
+			fmt.Println("  " + c)
+		}
+		fmt.Print("Resume with the session's [p]inned snapshot or the [c]urrent files? [P/c]: ")
+		line, _ := stdin.ReadString('\n')
+		answer := strings.ToLower(strings.TrimSpace(line))
+		useCurrent = answer == "c" || answer == "current"
+	}
+	if !useCurrent {
+		return pinned
+	}
+	if err := store.PinContext(id, current); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	return current
+}
+
+// resumeSummary describes a session for the banner printed when -c or -r
+// resumes it: its size and what it has cost so far.
+func resumeSummary(sess *session.Session) string {
+	summary := fmt.Sprintf("%d messages", len(sess.Messages))
+	if usage := sess.TotalUsage().String(); usage != "" {
+		summary += "; " + usage
+	}
+	return summary
+}
+
+// sessionsList prints the sessions in scope, newest first, with their
+// titles.
+func sessionsList(args []string) {
+	fs := flag.NewFlagSet("sessions list", flag.ExitOnError)
+	var scope sessionScope
+	var ff sessionFilterFlags
+	scope.register(fs)
+	ff.register(fs)
+	fs.Parse(args)
+
+	filter, err := ff.filter("")
+	if err != nil {
+		sessionsFatal(err)
+	}
+	matches, err := scope.search(filter)
+	if err != nil {
+		sessionsFatal(err)
+	}
+	if len(matches) == 0 {
+		fmt.Println("No sessions found.")
+		return
+	}
+	printSessionMatches(matches, scope.all)
+}
+
+// sessionsSearch prints the sessions in scope whose title or messages
+// contain the text, with the matching part.
+func sessionsSearch(args []string) {
+	fs := flag.NewFlagSet("sessions search", flag.ExitOnError)
+	var scope sessionScope
+	var ff sessionFilterFlags
+	scope.register(fs)
+	ff.register(fs)
+	text := strings.Join(parseSessionsArgs(fs, args), " ")
+	if text == "" {
+		fmt.Fprintln(os.Stderr, "Usage: claude sessions search [--project <dir>|--all] [--since <time>] [--until <time>] <text>")
+		os.Exit(1)
+	}
+
+	filter, err := ff.filter(text)
+	if err != nil {
+		sessionsFatal(err)
+	}
+	matches, err := scope.search(filter)
+	if err != nil {
+		sessionsFatal(err)
+	}
+	if len(matches) == 0 {
+		fmt.Printf("No sessions match %q.\n", text)
+		return
+	}
+	printSessionMatches(matches, scope.all)
+}
+
+// sessionsShow prints a session's metadata followed by its conversation
+// as plain text.
+func sessionsShow(args []string) {
+	fs := flag.NewFlagSet("sessions show", flag.ExitOnError)
+	var scope sessionScope
+	scope.register(fs)
+	pos := parseSessionsArgs(fs, args)
+	if len(pos) != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: claude sessions show [--project <dir>|--all] <id>")
+		os.Exit(1)
+	}
+
+	sess, _, err := scope.find(pos[0])
+	if err != nil {
+		sessionsFatal(err)
+	}
+	fmt.Printf("Session:   %s\n", sess.ID)
+	if sess.Name != "" {
+		fmt.Printf("Name:      %s\n", sess.Name)
+	}
+	if sess.Pinned {
+		fmt.Println("Pinned:    yes")
+	}
+	if title := sess.DisplayTitle(); title != "" {
+		fmt.Printf("Title:     %s\n", title)
+	}
+	fmt.Printf("Directory: %s\n", sess.CWD)
+	fmt.Printf("Model:     %s\n", sess.Model)
+	if sess.ForkedFrom != "" {
+		fmt.Printf("Forked:    from %s\n", sess.ForkedFrom)
+	}
+	if !sess.CreatedAt.IsZero() {
+		fmt.Printf("Created:   %s\n", sess.CreatedAt.Local().Format("2006-01-02 15:04"))
+	}
+	fmt.Printf("Updated:   %s\n", sess.UpdatedAt.Local().Format("2006-01-02 15:04"))
+	fmt.Printf("Messages:  %d\n", len(sess.Messages))
+	if len(sess.Turns) > 0 {
+		fmt.Printf("Turns:     %d\n", len(sess.Turns))
+	}
+	if len(sess.Todos) > 0 {
+		done := 0
+		for _, t := range sess.Todos {
+			if t.Status == "completed" {
+				done++
+			}
+		}
+		fmt.Printf("Todos:     %d of %d done\n", done, len(sess.Todos))
+	}
+	fmt.Println()
+	fmt.Print(session.ExportText(sess, session.ExportOptions{}))
+}
+
+// sessionsDiff prints what a session did to the files it changed: each
+// file's content before the session first touched it against the file
+// now, as a unified diff, or with --stat one summary line per file.
+func sessionsDiff(args []string) {
+	fs := flag.NewFlagSet("sessions diff", flag.ExitOnError)
+	var scope sessionScope
+	scope.register(fs)
+	stat := fs.Bool("stat", false, "Only list the files changed")
+	pos := parseSessionsArgs(fs, args)
+	if len(pos) != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: claude sessions diff [--project <dir>|--all] [--stat] <id>")
+		os.Exit(1)
+	}
+
+	sess, store, err := scope.find(pos[0])
+	if err != nil {
+		sessionsFatal(err)
+	}
+	records, err := store.Changes(sess.ID)
+	if err != nil {
+		sessionsFatal(err)
+	}
+	if len(records) == 0 {
+		fmt.Println("No files changed in this session.")
+		return
+	}
+	for i, d := range session.CumulativeDiff(records, sess.CWD) {
+		if *stat {
+			fmt.Printf("%s (%s)\n", d.Path, d.Summary())
+			continue
+		}
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s (%s)\n", d.Path, d.Summary())
+		fmt.Print(d.Diff)
+	}
+}
+
+// sessionsRename names a session, or with --clear removes its name.
+func sessionsRename(args []string) {
+	fs := flag.NewFlagSet("sessions rename", flag.ExitOnError)
+	var scope sessionScope
+	scope.register(fs)
+	clearName := fs.Bool("clear", false, "Remove the session's name")
+	pos := parseSessionsArgs(fs, args)
+	if (*clearName && len(pos) != 1) || (!*clearName && len(pos) != 2) {
+		fmt.Fprintln(os.Stderr, "Usage: claude sessions rename [--project <dir>|--all] <id> <name>")
+		fmt.Fprintln(os.Stderr, "       claude sessions rename [--project <dir>|--all] --clear <id>")
+		os.Exit(1)
+	}
+
+	sess, store, err := scope.find(pos[0])
+	if err != nil {
+		sessionsFatal(err)
+	}
+	name := ""
+	if !*clearName {
+		name = pos[1]
+	}
+	if err := store.Rename(sess.ID, name); err != nil {
+		sessionsFatal(err)
+	}
+	if name == "" {
+		fmt.Printf("Removed the name of session %s\n", sess.ID)
+	} else {
+		fmt.Printf("Named session %s %q. Resume it with: claude -r %s\n", sess.ID, name, name)
+	}
+}
+
+// sessionsPin pins a session, so pruning never deletes it, or with
+// --unpin lets it be pruned again.
+func sessionsPin(args []string) {
+	fs := flag.NewFlagSet("sessions pin", flag.ExitOnError)
+	var scope sessionScope
+	scope.register(fs)
+	unpin := fs.Bool("unpin", false, "Let the session be pruned again")
+	pos := parseSessionsArgs(fs, args)
+	if len(pos) != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: claude sessions pin [--project <dir>|--all] [--unpin] <id>")
+		os.Exit(1)
+	}
+
+	sess, store, err := scope.find(pos[0])
+	if err != nil {
+		sessionsFatal(err)
+	}
+	if err := store.Pin(sess.ID, !*unpin); err != nil {
+		sessionsFatal(err)
+	}
+	if *unpin {
+		fmt.Printf("Unpinned session %s\n", sess.ID)
+	} else {
+		fmt.Printf("Pinned session %s; pruning will keep it\n", sess.ID)
+	}
+}
+
+// sessionsPrune deletes the project's sessions past the sessionRetention
+// limits, or with --dry-run lists the ones it would delete.
+func sessionsPrune(args []string) {
+	fs := flag.NewFlagSet("sessions prune", flag.ExitOnError)
+	var scope sessionScope
+	fs.StringVar(&scope.project, "project", "", "Project directory to prune (default: current directory)")
+	dryRun := fs.Bool("dry-run", false, "List the sessions that would be deleted without deleting them")
+	if pos := parseSessionsArgs(fs, args); len(pos) != 0 {
+		fmt.Fprintln(os.Stderr, "Usage: claude sessions prune [--project <dir>] [--dry-run]")
+		os.Exit(1)
+	}
+
+	dir, _ := os.Getwd()
+	if scope.project != "" {
+		dir, _ = filepath.Abs(scope.project)
+	}
+	settings, err := config.LoadSettings(dir)
+	if err != nil {
+		sessionsFatal(err)
+	}
+	policy := retentionPolicy(settings.SessionRetention)
+	if policy.IsZero() {
+		sessionsFatal(fmt.Errorf(`no retention limits; set "sessionRetention": {"maxAgeDays": 30, "maxSessions": 200, "maxSizeMB": 500} or some of them in settings`))
+	}
+	stores, err := scope.stores()
+	if err != nil {
+		sessionsFatal(err)
+	}
+
+	res, err := stores[0].Prune(policy, *dryRun)
+	if res != nil {
+		verb := "deleted"
+		if *dryRun {
+			verb = "would delete"
+		}
+		for _, p := range res.Pruned {
+			fmt.Printf("%-12s  %s  %s  %8s  %s (%s)\n", verb, p.Session.ID, p.Session.UpdatedAt.Local().Format("2006-01-02"), session.FormatBytes(p.Bytes), p.Session.DisplayTitle(), p.Reason)
+		}
+		for _, p := range res.Kept {
+			fmt.Printf("%-12s  %s  %s  %8s  %s (%s)\n", "kept", p.Session.ID, p.Session.UpdatedAt.Local().Format("2006-01-02"), session.FormatBytes(p.Bytes), p.Session.DisplayTitle(), p.Reason)
+		}
+		switch {
+		case len(res.Pruned) == 0 && err == nil:
+			fmt.Println("No sessions past the retention limits.")
+		case *dryRun:
+			fmt.Printf("Would delete %s, freeing %s. Run without --dry-run to delete them.\n", countSessions(len(res.Pruned)), session.FormatBytes(res.Freed()))
+		default:
+			fmt.Printf("Deleted %s, freeing %s.\n", countSessions(len(res.Pruned)), session.FormatBytes(res.Freed()))
+		}
+	}
+	if err != nil {
+		sessionsFatal(err)
+	}
+}
+
+// countSessions returns "1 session" or "N sessions".
+func countSessions(n int) string {
+	if n == 1 {
+		return "1 session"
+	}
+	return fmt.Sprintf("%d sessions", n)
+}
+
+// retentionPolicy converts the sessionRetention setting; nil sets no
+// limits.
+func retentionPolicy(cfg *config.SessionRetentionConfig) session.RetentionPolicy {
+	if cfg == nil {
+		return session.RetentionPolicy{}
+	}
+	return session.RetentionPolicy{
+		MaxAge:   time.Duration(cfg.MaxAgeDays) * 24 * time.Hour,
+		MaxCount: cfg.MaxSessions,
+		MaxBytes: int64(cfg.MaxSizeMB) << 20,
+	}
+}
+
+// sessionsDelete deletes sessions after asking, unless --yes is given.
+func sessionsDelete(args []string) {
+	fs := flag.NewFlagSet("sessions delete", flag.ExitOnError)
+	var scope sessionScope
+	scope.register(fs)
+	var yes bool
+	fs.BoolVar(&yes, "yes", false, "Don't ask for confirmation")
+	fs.BoolVar(&yes, "y", false, "Shorthand for --yes")
+	ids := parseSessionsArgs(fs, args)
+	if len(ids) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: claude sessions delete [--project <dir>|--all] [--yes] <id>...")
+		os.Exit(1)
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	failed := false
+	for _, id := range ids {
+		sess, store, err := scope.find(id)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			failed = true
+			continue
+		}
+		if !yes {
+			fmt.Printf("Delete session %s (%s)? [y/N]: ", id, sess.DisplayTitle())
+			line, _ := reader.ReadString('\n')
+			if answer := strings.ToLower(strings.TrimSpace(line)); answer != "y" && answer != "yes" {
+				fmt.Println("Skipped.")
+				continue
+			}
+		}
+		if err := store.Delete(id); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			failed = true
+			continue
+		}
+		fmt.Printf("Deleted session %s\n", id)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// sessionsExport renders a saved session as Markdown, HTML, or text,
+// writing to stdout unless --output is given. A bundle is an archive for
+// `claude sessions import`, written to a file.
+func sessionsExport(args []string) {
+	fs := flag.NewFlagSet("sessions export", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: claude sessions export <id> [--format md|html|text|bundle] [--output <file>]\n\nOptions:\n")
+		fs.PrintDefaults()
+	}
+	var scope sessionScope
+	scope.register(fs)
+	format := fs.String("format", session.FormatMarkdown, "Output format: md, html, text, or bundle")
+	output := fs.String("output", "", "Write to a file instead of stdout")
+	pos := parseSessionsArgs(fs, args)
+	if len(pos) != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	id := pos[0]
+
+	sess, store, err := scope.find(id)
+	if err != nil {
+		sessionsFatal(err)
+	}
+	if *format == "bundle" {
+		exportBundle(store, id, *output)
+		return
+	}
+	doc, err := session.Export(sess, *format, session.ExportOptions{CostSummary: sess.CostSummary()})
+	if err != nil {
+		sessionsFatal(err)
+	}
+
+	if *output == "" {
+		fmt.Print(doc)
+		return
+	}
+	if err := os.WriteFile(*output, []byte(doc), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", *output, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Exported session %s to %s\n", id, *output)
+}
+
+// exportBundle writes a session bundle to output, by default
+// claude-session-<id>.tar.gz in the current directory.
+func exportBundle(store *session.Store, id, output string) {
+	if output == "" {
+		output = "claude-session-" + id + ".tar.gz"
+	}
+	f, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		sessionsFatal(err)
+	}
+	if err := store.ExportBundle(id, f); err != nil {
+		f.Close()
+		os.Remove(output)
+		sessionsFatal(err)
+	}
+	if err := f.Close(); err != nil {
+		sessionsFatal(err)
+	}
+	fmt.Printf("Exported session %s to %s\n", id, output)
+}
+
+// sessionsImport imports a session bundle into the current project, or
+// --project's, and says how to resume it.
+func sessionsImport(args []string) {
+	fs := flag.NewFlagSet("sessions import", flag.ExitOnError)
+	var scope sessionScope
+	fs.StringVar(&scope.project, "project", "", "Project directory to import into (default: current directory)")
+	pos := parseSessionsArgs(fs, args)
+	if len(pos) != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: claude sessions import [--project <dir>] <file>")
+		os.Exit(1)
+	}
+
+	stores, err := scope.stores()
+	if err != nil {
+		sessionsFatal(err)
+	}
+	cwd, _ := os.Getwd()
+	dir := cwd
+	if scope.project != "" {
+		dir, _ = filepath.Abs(scope.project)
+	}
+
+	f, err := os.Open(pos[0])
+	if err != nil {
+		sessionsFatal(err)
+	}
+	defer f.Close()
+	imported, err := stores[0].ImportBundle(f, dir)
+	if err != nil {
+		sessionsFatal(err)
+	}
+
+	sess := imported.Session
+	fmt.Printf("Imported session %s (%s)\n", sess.ID, sess.DisplayTitle())
+	if m := imported.Manifest; m.CWD != "" && m.CWD != dir {
+		fmt.Printf("Paths under %s were rewritten to %s.\n", m.CWD, dir)
+	}
+	var edited []string
+	seen := make(map[string]bool)
+	for _, c := range imported.Changes {
+		if !c.Failed && !seen[c.Path] {
+			seen[c.Path] = true
+			edited = append(edited, c.Path)
+		}
+	}
+	if len(edited) > 0 {
+		fmt.Printf("The session edited %d file(s):\n", len(edited))
+		for _, p := range edited {
+			fmt.Println("  " + p)
+		}
+		fmt.Println("Make sure this checkout has those changes (e.g. push and pull them) before resuming.")
+	}
+	if dir == cwd {
+		fmt.Printf("Resume it with: claude -r %s\n", sess.ID)
+	} else {
+		fmt.Printf("Resume it from %s with: claude -r %s\n", dir, sess.ID)
+	}
+}
+
+// sessionsSync syncs the project's sessions with the sessionSync remote.
+func sessionsSync(args []string) {
+	fs := flag.NewFlagSet("sessions sync", flag.ExitOnError)
+	var scope sessionScope
+	fs.StringVar(&scope.project, "project", "", "Project directory to sync (default: current directory)")
+	if pos := parseSessionsArgs(fs, args); len(pos) != 0 {
+		fmt.Fprintln(os.Stderr, "Usage: claude sessions sync [--project <dir>]")
+		os.Exit(1)
+	}
+
+	dir, _ := os.Getwd()
+	if scope.project != "" {
+		dir, _ = filepath.Abs(scope.project)
+	}
+	settings, err := config.LoadSettings(dir)
+	if err != nil {
+		sessionsFatal(err)
+	}
+	if settings.SessionSync == nil || settings.SessionSync.URL == "" {
+		sessionsFatal(fmt.Errorf(`no sync remote; set "sessionSync": {"url": "s3://bucket/prefix"} or a WebDAV URL in settings`))
+	}
+	backend, err := syncBackend(settings.SessionSync)
+	if err != nil {
+		sessionsFatal(err)
+	}
+	stores, err := scope.stores()
+	if err != nil {
+		sessionsFatal(err)
+	}
+	project := syncProject(dir, settings.SessionSync.Project)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	res, err := stores[0].Sync(ctx, backend, project, dir)
+	if res != nil {
+		for _, id := range res.Pushed {
+			fmt.Printf("pushed    %s\n", id)
+		}
+		for _, id := range res.Pulled {
+			fmt.Printf("pulled    %s\n", id)
+		}
+		for _, id := range res.Conflicts {
+			fmt.Printf("conflict  %s changed on both sides; the remote version was kept as a fork\n", id)
+		}
+		for id, why := range res.Skipped {
+			fmt.Printf("skipped   %s: %s\n", id, why)
+		}
+		if len(res.Pushed)+len(res.Pulled)+len(res.Conflicts)+len(res.Skipped) == 0 && err == nil {
+			fmt.Printf("Sessions are up to date with %s (project %s).\n", settings.SessionSync.URL, project)
+		}
+	}
+	if err != nil {
+		sessionsFatal(err)
+	}
+}
+
+// syncBackend returns the backend for the sessionSync URL: s3://bucket/prefix
+// for S3, or an http(s) URL of a WebDAV collection.
+func syncBackend(cfg *config.SessionSyncConfig) (session.SyncBackend, error) {
+	if rest, ok := strings.CutPrefix(cfg.URL, "s3://"); ok {
+		bucket, prefix, _ := strings.Cut(rest, "/")
+		region := cfg.Region
+		if region == "" {
+			region = os.Getenv("AWS_REGION")
+		}
+		if region == "" {
+			region = os.Getenv("AWS_DEFAULT_REGION")
+		}
+		return session.NewS3Backend(session.S3Options{
+			Bucket:          bucket,
+			Prefix:          prefix,
+			Region:          region,
+			Endpoint:        cfg.Endpoint,
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		})
+	}
+	return session.NewWebDAVBackend(cfg.URL, os.Getenv("CLAUDE_SYNC_USERNAME"), os.Getenv("CLAUDE_SYNC_PASSWORD"))
+}
+
+// syncProject names the remote folder of the project in dir, so the same
+// repository checked out in different places on different machines
+// shares its sessions: the configured name, else the origin remote's
+// host and path, else the directory's name.
+func syncProject(dir, configured string) string {
+	name := configured
+	if name == "" {
+		if out, err := exec.Command("git", "-C", dir, "remote", "get-url", "origin").Output(); err == nil {
+			name = strings.TrimSpace(string(out))
+			if _, rest, ok := strings.Cut(name, "://"); ok {
+				name = rest
+			}
+			if _, rest, ok := strings.Cut(name, "@"); ok {
+				name = rest
+			}
+			name = strings.TrimSuffix(strings.ReplaceAll(name, ":", "/"), ".git")
+		}
+	}
+	if name == "" {
+		name = filepath.Base(dir)
+	}
+	return session.ProjectDirName(name)
+}
+
+// resumeDir returns the directory to start in when the session to resume
+// belongs to a project other than the current directory's: the session
+// with id, or with continueAll the most recent session of any project.
+// It returns "" to stay put, which includes an id found nowhere, so that
+// loading it reports the error as usual.
+func resumeDir(id string, continueAll bool) (string, error) {
+	if id == "" && !continueAll {
+		return "", nil
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", nil
+	}
+	var sess *session.Session
+	if id != "" {
+		if store, err := session.NewStore(cwd); err == nil {
+			_, err := store.Resolve(id)
+			found := err == nil
+			store.Close()
+			if found {
+				return "", nil
+			}
+		}
+		sess, _ = session.FindSession(id)
+	} else {
+		all, err := session.ListAll()
+		if err != nil {
+			return "", err
+		}
+		if len(all) > 0 {
+			sess = all[0]
+		}
+	}
+	if sess == nil || sess.CWD == "" || sess.CWD == cwd {
+		return "", nil
+	}
+	if info, err := os.Stat(sess.CWD); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("session %s was started in %s, which no longer exists", sess.ID, sess.CWD)
+	}
+	return sess.CWD, nil
+}
+
+// resumeElsewhere runs `claude -r <id>` in the session's directory, for a
+// session picked from another project in /resume, and returns its exit
+// code.
+func resumeElsewhere(sess *session.Session) int {
+	self, err := os.Executable()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	cmd := exec.Command(self, "-r", sess.ID)
+	cmd.Dir = sess.CWD
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return exitErr.ExitCode()
+		}
+		fmt.Fprintf(os.Stderr, "Error: resuming session %s in %s: %v\n", sess.ID, sess.CWD, err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,88 @@
+package agents
+
+import (
+	"path/filepath"
+	"slices"
+)
+
+// readOnlyTools are the tools given to the built-in agents: they can
+// search and read the codebase and the web but change nothing.
+var readOnlyTools = []string{"FileRead", "Glob", "Grep", "LS", "NotebookRead", "WebFetch", "WebSearch"}
+
+const exploreSystemPrompt = `You are Explore, a fast read-only agent for searching codebases.
+
+Your job is to find and report information, never to change anything. You have no tools that write files or run commands.
+
+How to search:
+- Start broad with Glob and Grep, then narrow down. Run independent searches in parallel.
+- Read only the parts of files you need; use offset and limit for large files.
+- Try alternative names, spellings, and locations before concluding something doesn't exist.
+- Stop once you can answer the question; don't read the whole codebase.
+
+Your final message is all the caller sees, so make it self-contained:
+- Answer the question directly first.
+- Cite absolute file paths with line numbers for every claim.
+- Include short code excerpts only where they matter.
+- Say what you looked for and didn't find, if relevant.`
+
+const planSystemPrompt = `You are Plan, a software architect agent. You investigate a codebase and produce an implementation plan; you never change anything.
+
+Investigate before planning: find the code the change touches, the conventions it follows, existing helpers to reuse, and the tests that cover it.
+
+Your final message is the plan, saved as a markdown file for the user and the caller. Use exactly these sections:
+
+## Goal
+One or two sentences on what the change achieves.
+
+## Context
+What exists today that matters, with file paths and line numbers.
+
+## Changes
+A numbered list of steps. For each, name the files to create or modify and describe the change concretely enough to implement without further research.
+
+## Risks
+Edge cases, compatibility concerns, and open questions, or "None".
+
+## Verification
+How to check the change works: tests to add or run, and manual checks.`
+
+// Builtins returns the agents built into the CLI:
+//   - Explore: read-only codebase search on a small, fast model. Its
+//     results are reused for repeated questions about an unchanged
+//     repository.
+//   - Plan: read-only investigation producing an implementation plan,
+//     which is saved under .claude/plans in cwd.
+func Builtins(cwd string) []Agent {
+	return []Agent{
+		{
+			Name:         "Explore",
+			Description:  "Fast read-only agent for exploring codebases: finding files, searching code, and answering questions about how things work. Specify the desired thoroughness (quick, medium, or very thorough).",
+			Tools:        slices.Clone(readOnlyTools),
+			Model:        "haiku",
+			SystemPrompt: exploreSystemPrompt,
+			CacheResults: true,
+			Source:       "built-in",
+		},
+		{
+			Name:         "Plan",
+			Description:  "Software architect agent for designing implementation plans. Returns step-by-step plans naming the files to change, risks, and how to verify the work; the plan is also saved as a file.",
+			Tools:        slices.Clone(readOnlyTools),
+			Model:        "inherit",
+			SystemPrompt: planSystemPrompt,
+			OutputDir:    filepath.Join(cwd, ".claude", "plans"),
+			Source:       "built-in",
+		},
+	}
+}
+
+// All returns the custom agents for cwd followed by the built-in agents
+// they don't override.
+func All(cwd string) []Agent {
+	defs := LoadAgents(cwd)
+	for _, b := range Builtins(cwd) {
+		if !slices.ContainsFunc(defs, func(a Agent) bool { return a.Name == b.Name }) {
+			defs = append(defs, b)
+		}
+	}
+	return defs
+}
@@ -0,0 +1,33 @@
+package agents
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAll(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cwd := t.TempDir()
+
+	defs := All(cwd)
+	if len(defs) != 2 || defs[0].Name != "Explore" || defs[1].Name != "Plan" {
+		t.Fatalf("All without custom agents = %+v", defs)
+	}
+	for _, a := range defs {
+		if err := Validate(Agent{Name: "x", Description: a.Description, Tools: a.Tools, Model: a.Model, SystemPrompt: a.SystemPrompt}, readOnlyTools); err != nil {
+			t.Errorf("%s: %v", a.Name, err)
+		}
+	}
+	if defs[1].OutputDir != filepath.Join(cwd, ".claude", "plans") {
+		t.Errorf("Plan OutputDir = %q", defs[1].OutputDir)
+	}
+
+	dir := filepath.Join(cwd, ".claude", "agents")
+	os.MkdirAll(dir, 0o755)
+	os.WriteFile(filepath.Join(dir, "explore.md"), []byte("---\nname: Explore\ndescription: Mine\n---\nMy prompt."), 0o644)
+	defs = All(cwd)
+	if len(defs) != 2 || defs[0].Source != "project" || defs[0].Description != "Mine" || defs[1].Name != "Plan" {
+		t.Errorf("a project Explore should replace the built-in: %+v", defs)
+	}
+}
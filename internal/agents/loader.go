@@ -0,0 +1,141 @@
+package agents
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// LoadAgents discovers agent definitions in the user-level
+// (~/.claude/agents/) and project-level (.claude/agents/) directories.
+// Project-level agents take precedence over user-level agents with the
+// same name.
+func LoadAgents(cwd string) []Agent {
+	var agents []Agent
+	seen := make(map[string]bool)
+	add := func(dir, source string) {
+		for _, a := range loadAgentsFromDir(dir, source) {
+			if !seen[a.Name] {
+				agents = append(agents, a)
+				seen[a.Name] = true
+			}
+		}
+	}
+
+	add(filepath.Join(cwd, ".claude", "agents"), "project")
+	if home, err := os.UserHomeDir(); err == nil {
+		add(filepath.Join(home, ".claude", "agents"), "user")
+	}
+	return agents
+}
+
+// loadAgentsFromDir reads all .md files in dir as agent definitions.
+func loadAgentsFromDir(dir, source string) []Agent {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var agents []Agent
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".md") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		a := parseAgent(string(data), path)
+		if a.Name == "" {
+			a.Name = strings.TrimSuffix(entry.Name(), ".md")
+		}
+		a.Source = source
+		agents = append(agents, a)
+	}
+	return agents
+}
+
+// parseAgent parses a markdown file with optional YAML frontmatter. The
+// tools key takes a comma-separated list, a flow list ("[Read, Grep]"), or
+// a block list of "- Name" lines.
+func parseAgent(content, filePath string) Agent {
+	a := Agent{FilePath: filePath}
+
+	if !strings.HasPrefix(content, "---") {
+		a.SystemPrompt = strings.TrimSpace(content)
+		return a
+	}
+	parts := strings.SplitN(content, "---", 3)
+	if len(parts) < 3 {
+		a.SystemPrompt = strings.TrimSpace(content)
+		return a
+	}
+
+	var listKey string // key whose block list is being read
+	for _, line := range strings.Split(parts[1], "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if item, ok := strings.CutPrefix(line, "- "); ok {
+			if listKey == "tools" {
+				a.Tools = append(a.Tools, unquote(item))
+			}
+			continue
+		}
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		listKey = ""
+
+		switch key {
+		case "name":
+			a.Name = unquote(value)
+		case "description":
+			a.Description = unquote(value)
+		case "model":
+			a.Model = unquote(value)
+		case "maxTurns", "max_turns":
+			a.MaxTurns = parseLimit(value)
+		case "maxTokens", "max_tokens":
+			a.MaxTokens = parseLimit(value)
+		case "tools":
+			if value == "" {
+				listKey = key
+				continue
+			}
+			value = strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+			for _, name := range strings.Split(value, ",") {
+				if name = unquote(strings.TrimSpace(name)); name != "" {
+					a.Tools = append(a.Tools, name)
+				}
+			}
+		}
+	}
+
+	a.SystemPrompt = strings.TrimSpace(parts[2])
+	return a
+}
+
+// parseLimit parses a positive integer limit, returning -1 if value is
+// not one so that Validate can report it.
+func parseLimit(value string) int {
+	n, err := strconv.Atoi(unquote(value))
+	if err != nil || n <= 0 {
+		return -1
+	}
+	return n
+}
+
+// unquote strips matching single or double quotes around a YAML scalar.
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,78 @@
+package agents
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseAgent(t *testing.T) {
+	content := `---
+name: code-reviewer
+description: "Reviews diffs for bugs and style"
+tools: Read, Grep, Glob
+model: sonnet
+---
+
+You are a careful code reviewer.`
+
+	a := parseAgent(content, "reviewer.md")
+	if a.Name != "code-reviewer" || a.Description != "Reviews diffs for bugs and style" || a.Model != "sonnet" {
+		t.Errorf("unexpected agent: %+v", a)
+	}
+	if want := []string{"Read", "Grep", "Glob"}; !reflect.DeepEqual(a.Tools, want) {
+		t.Errorf("Tools = %v, want %v", a.Tools, want)
+	}
+	if a.SystemPrompt != "You are a careful code reviewer." {
+		t.Errorf("SystemPrompt = %q", a.SystemPrompt)
+	}
+}
+
+func TestParseAgent_ToolLists(t *testing.T) {
+	flow := parseAgent("---\ntools: [Bash, \"Read\"]\n---\nbody", "a.md")
+	if want := []string{"Bash", "Read"}; !reflect.DeepEqual(flow.Tools, want) {
+		t.Errorf("flow list Tools = %v, want %v", flow.Tools, want)
+	}
+
+	block := parseAgent("---\ntools:\n  - Bash\n  - Read\nmodel: haiku\n---\nbody", "a.md")
+	if want := []string{"Bash", "Read"}; !reflect.DeepEqual(block.Tools, want) || block.Model != "haiku" {
+		t.Errorf("block list agent = %+v", block)
+	}
+
+	none := parseAgent("Just a prompt", "a.md")
+	if none.Tools != nil || none.SystemPrompt != "Just a prompt" {
+		t.Errorf("no frontmatter agent = %+v", none)
+	}
+}
+
+func TestLoadAgents_ProjectOverridesUser(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	cwd := t.TempDir()
+
+	write := func(dir, name, content string) {
+		t.Helper()
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(home, ".claude", "agents"), "reviewer.md", "user reviewer")
+	write(filepath.Join(home, ".claude", "agents"), "planner.md", "user planner")
+	write(filepath.Join(cwd, ".claude", "agents"), "reviewer.md", "project reviewer")
+	write(filepath.Join(cwd, ".claude", "agents"), "notes.txt", "ignored")
+
+	agents := LoadAgents(cwd)
+	if len(agents) != 2 {
+		t.Fatalf("got %d agents, want 2: %+v", len(agents), agents)
+	}
+	if agents[0].Name != "reviewer" || agents[0].SystemPrompt != "project reviewer" || agents[0].Source != "project" {
+		t.Errorf("reviewer = %+v, want project definition", agents[0])
+	}
+	if agents[1].Name != "planner" || agents[1].Source != "user" {
+		t.Errorf("planner = %+v, want user definition", agents[1])
+	}
+}
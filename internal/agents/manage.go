@@ -0,0 +1,177 @@
+package agents
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/anthropics/claude-code-go/internal/api"
+)
+
+// Models lists the model values offered when creating an agent.
+var Models = []string{"inherit", "sonnet", "opus", "haiku"}
+
+// toolAliases maps tool names used in agent definitions written for the
+// JavaScript CLI to this implementation's names.
+var toolAliases = map[string]string{"Read": "FileRead", "Write": "FileWrite", "Edit": "FileEdit"}
+
+// ToolName returns the registry name for a tool named in an agent
+// definition.
+func ToolName(name string) string {
+	if alias, ok := toolAliases[name]; ok {
+		return alias
+	}
+	return name
+}
+
+var validName = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// Dir returns the agents directory: .claude/agents under cwd, or
+// ~/.claude/agents if user is set.
+func Dir(cwd string, user bool) (string, error) {
+	if !user {
+		return filepath.Join(cwd, ".claude", "agents"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".claude", "agents"), nil
+}
+
+// Find returns the agent named name, preferring the project definition.
+func Find(cwd, name string) (Agent, bool) {
+	for _, a := range LoadAgents(cwd) {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return Agent{}, false
+}
+
+// Validate reports every problem with an agent definition. If knownTools
+// is non-nil, tools must be among them.
+func Validate(a Agent, knownTools []string) error {
+	var errs []error
+	if !validName.MatchString(a.Name) {
+		errs = append(errs, fmt.Errorf("name %q must be lowercase letters, digits, and hyphens", a.Name))
+	}
+	if strings.TrimSpace(a.Description) == "" {
+		errs = append(errs, errors.New("description is required; it tells the model when to use the agent"))
+	}
+	if a.Model != "" && !slices.Contains(Models, a.Model) && !strings.HasPrefix(a.Model, "claude-") {
+		errs = append(errs, fmt.Errorf("model %q must be one of %s, or a full model ID", a.Model, strings.Join(Models, ", ")))
+	}
+	if a.MaxTurns < 0 {
+		errs = append(errs, errors.New("maxTurns must be a positive integer"))
+	}
+	if a.MaxTokens < 0 {
+		errs = append(errs, errors.New("maxTokens must be a positive integer"))
+	}
+	if a.Tools != nil && len(a.Tools) == 0 {
+		errs = append(errs, errors.New("tools is empty; omit it to allow all tools"))
+	}
+	if knownTools != nil {
+		for _, name := range a.Tools {
+			if !slices.Contains(knownTools, ToolName(name)) {
+				errs = append(errs, fmt.Errorf("unknown tool %q", name))
+			}
+		}
+	}
+	if strings.TrimSpace(a.SystemPrompt) == "" {
+		errs = append(errs, errors.New("the system prompt (the markdown body) is empty"))
+	}
+	return errors.Join(errs...)
+}
+
+// ValidateFile parses and validates the agent file at path.
+func ValidateFile(path string, knownTools []string) (Agent, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Agent{}, err
+	}
+	content := string(data)
+	if !strings.HasPrefix(content, "---") || len(strings.SplitN(content, "---", 3)) < 3 {
+		return Agent{}, errors.New("missing YAML frontmatter between --- lines")
+	}
+	a := parseAgent(content, path)
+	if a.Name == "" {
+		a.Name = strings.TrimSuffix(filepath.Base(path), ".md")
+	}
+	return a, Validate(a, knownTools)
+}
+
+// Format renders an agent definition as a markdown file.
+func Format(a Agent) string {
+	var b strings.Builder
+	b.WriteString("---\n")
+	fmt.Fprintf(&b, "name: %s\n", a.Name)
+	fmt.Fprintf(&b, "description: %s\n", a.Description)
+	if a.Tools != nil {
+		fmt.Fprintf(&b, "tools: %s\n", strings.Join(a.Tools, ", "))
+	}
+	if a.Model != "" && a.Model != "inherit" {
+		fmt.Fprintf(&b, "model: %s\n", a.Model)
+	}
+	if a.MaxTurns > 0 {
+		fmt.Fprintf(&b, "maxTurns: %d\n", a.MaxTurns)
+	}
+	if a.MaxTokens > 0 {
+		fmt.Fprintf(&b, "maxTokens: %d\n", a.MaxTokens)
+	}
+	b.WriteString("---\n\n")
+	b.WriteString(strings.TrimSpace(a.SystemPrompt))
+	b.WriteString("\n")
+	return b.String()
+}
+
+// Scaffold returns a placeholder system prompt for a new agent.
+func Scaffold(a Agent) string {
+	return fmt.Sprintf(`You are %s, a specialized agent. %s
+
+When invoked:
+1. Understand the task you were given.
+2. Investigate using the tools available to you.
+3. Report your findings concisely, with file paths and line numbers.`, a.Name, strings.TrimSpace(a.Description))
+}
+
+// Create writes a new agent file to dir and returns its path. It fails if
+// the definition is invalid or the file already exists. An empty system
+// prompt is replaced by Scaffold.
+func Create(dir string, a Agent, knownTools []string) (string, error) {
+	if strings.TrimSpace(a.SystemPrompt) == "" {
+		a.SystemPrompt = Scaffold(a)
+	}
+	if err := Validate(a, knownTools); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, a.Name+".md")
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		if os.IsExist(err) {
+			return "", fmt.Errorf("agent %s already exists at %s", a.Name, path)
+		}
+		return "", err
+	}
+	if _, err := f.WriteString(Format(a)); err != nil {
+		f.Close()
+		return "", err
+	}
+	return path, f.Close()
+}
+
+// ResolveModel returns the API model ID for an agent's model, or "" to
+// use the parent's model.
+func ResolveModel(model string) string {
+	if model == "" || model == "inherit" {
+		return ""
+	}
+	return api.ResolveModelAlias(model)
+}
@@ -0,0 +1,97 @@
+package agents
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	ok := Agent{Name: "reviewer", Description: "Reviews code", Model: "sonnet", Tools: []string{"Read", "Grep"}, SystemPrompt: "Review."}
+	if err := Validate(ok, []string{"FileRead", "Grep"}); err != nil {
+		t.Errorf("valid agent: %v", err)
+	}
+
+	bad := Agent{Name: "Bad Name", Model: "gpt", Tools: []string{}}
+	err := Validate(bad, []string{"Bash"})
+	if err == nil {
+		t.Fatal("expected errors")
+	}
+	for _, want := range []string{"name", "description is required", `model "gpt"`, "tools is empty", "system prompt"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error missing %q: %v", want, err)
+		}
+	}
+
+	if err := Validate(Agent{Name: "a", Description: "d", SystemPrompt: "p", Tools: []string{"Nope"}}, []string{"Bash"}); err == nil || !strings.Contains(err.Error(), `unknown tool "Nope"`) {
+		t.Errorf("unknown tool: %v", err)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	a := parseAgent("---\nname: explorer\ndescription: d\nmaxTurns: 5\nmax_tokens: 2048\n---\nbody", "explorer.md")
+	if a.MaxTurns != 5 || a.MaxTokens != 2048 {
+		t.Errorf("limits = %d, %d", a.MaxTurns, a.MaxTokens)
+	}
+	if err := Validate(a, nil); err != nil {
+		t.Errorf("valid limits: %v", err)
+	}
+	if got := parseAgent(Format(a), "explorer.md"); got.MaxTurns != 5 || got.MaxTokens != 2048 {
+		t.Errorf("Format round trip = %+v", got)
+	}
+
+	bad := parseAgent("---\nname: explorer\ndescription: d\nmaxTurns: many\nmaxTokens: 0\n---\nbody", "explorer.md")
+	err := Validate(bad, nil)
+	if err == nil || !strings.Contains(err.Error(), "maxTurns must be") || !strings.Contains(err.Error(), "maxTokens must be") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestCreate(t *testing.T) {
+	dir := t.TempDir()
+	a := Agent{Name: "reviewer", Description: "Reviews code", Tools: []string{"FileRead", "Grep"}, Model: "haiku"}
+	path, err := Create(dir, a, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != filepath.Join(dir, "reviewer.md") {
+		t.Errorf("path = %q", path)
+	}
+
+	got, err := ValidateFile(path, nil)
+	if err != nil {
+		t.Fatalf("created file is invalid: %v", err)
+	}
+	if got.Name != a.Name || got.Description != a.Description || got.Model != "haiku" || !reflect.DeepEqual(got.Tools, a.Tools) {
+		t.Errorf("round trip = %+v", got)
+	}
+	if !strings.HasPrefix(got.SystemPrompt, "You are reviewer") {
+		t.Errorf("SystemPrompt should be scaffolded, got %q", got.SystemPrompt)
+	}
+
+	if _, err := Create(dir, a, nil); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("second Create: %v", err)
+	}
+	if _, err := Create(dir, Agent{Name: "x"}, nil); err == nil {
+		t.Error("Create should reject an agent without a description")
+	}
+}
+
+func TestValidateFileRequiresFrontmatter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plain.md")
+	os.WriteFile(path, []byte("Just a prompt."), 0o644)
+	if _, err := ValidateFile(path, nil); err == nil || !strings.Contains(err.Error(), "frontmatter") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestResolveModel(t *testing.T) {
+	if ResolveModel("inherit") != "" || ResolveModel("") != "" {
+		t.Error("inherit should resolve to the parent model")
+	}
+	if ResolveModel("sonnet") == "sonnet" {
+		t.Error("aliases should resolve to a model ID")
+	}
+}
@@ -0,0 +1,25 @@
+// Package agents loads custom sub-agent definitions for the Agent tool and
+// provides the built-in Explore and Plan agents.
+//
+// Agents are markdown files with YAML frontmatter located in:
+//   - ~/.claude/agents/ (user-level, all projects)
+//   - .claude/agents/  (project-level)
+//
+// The frontmatter sets the agent's name, description, tools, model, and
+// turn and token limits; the markdown body is its system prompt.
+package agents
+
+// Agent is a custom sub-agent definition.
+type Agent struct {
+	Name         string   // subagent_type value, from frontmatter or the file name
+	Description  string   // when to use the agent, shown to the model
+	Tools        []string // tools the agent may use; nil means all tools
+	Model        string   // model alias or ID; empty or "inherit" uses the parent's
+	MaxTurns     int      // agentic turns before the agent stops; 0 means no limit, -1 an invalid value
+	MaxTokens    int      // max_tokens per response; 0 means the client default, -1 an invalid value
+	SystemPrompt string   // markdown body
+	OutputDir    string   // if set, the agent's final report is also saved here as markdown
+	CacheResults bool     // reuse results for near-identical prompts while the repository is unchanged
+	FilePath     string   // source file path; empty for built-in agents
+	Source       string   // "project", "user", or "built-in"
+}
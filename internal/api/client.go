@@ -132,6 +132,18 @@ func (c *Client) SetModel(model string) {
 	c.model = model
 }
 
+// SetMaxTokens changes the default max_tokens for subsequent API calls.
+func (c *Client) SetMaxTokens(n int) {
+	c.maxTokens = n
+}
+
+// Clone returns a copy of the client whose model and max tokens can be
+// changed without affecting c.
+func (c *Client) Clone() *Client {
+	clone := *c
+	return &clone
+}
+
 // CreateMessageStream sends a streaming Messages API request and dispatches
 // events to the provided handler. It returns the final assembled response.
 func (c *Client) CreateMessageStream(
@@ -0,0 +1,40 @@
+package api
+
+// ModelPrice is the price of a model's tokens in USD per million.
+type ModelPrice struct{ Input, Output, CacheRead, CacheWrite float64 }
+
+// ModelPricing holds the published Anthropic pricing for supported models.
+var ModelPricing = map[string]ModelPrice{
+	ModelClaude46Opus:   {Input: 15.0, Output: 75.0, CacheRead: 1.5, CacheWrite: 18.75},
+	ModelClaude46Sonnet: {Input: 3.0, Output: 15.0, CacheRead: 0.3, CacheWrite: 3.75},
+	ModelClaude45Haiku:  {Input: 0.8, Output: 4.0, CacheRead: 0.08, CacheWrite: 1.0},
+}
+
+// Cost returns the USD cost of usage on model, or 0 if the model's pricing
+// is unknown.
+func (u Usage) Cost(model string) float64 {
+	p, ok := ModelPricing[model]
+	if !ok {
+		return 0
+	}
+	cost := float64(u.InputTokens)*p.Input + float64(u.OutputTokens)*p.Output
+	if u.CacheReadInputTokens != nil {
+		cost += float64(*u.CacheReadInputTokens) * p.CacheRead
+	}
+	if u.CacheCreationInputTokens != nil {
+		cost += float64(*u.CacheCreationInputTokens) * p.CacheWrite
+	}
+	return cost / 1_000_000
+}
+
+// TotalTokens returns all tokens in u, including cache reads and writes.
+func (u Usage) TotalTokens() int {
+	n := u.InputTokens + u.OutputTokens
+	if u.CacheReadInputTokens != nil {
+		n += *u.CacheReadInputTokens
+	}
+	if u.CacheCreationInputTokens != nil {
+		n += *u.CacheCreationInputTokens
+	}
+	return n
+}
@@ -0,0 +1,17 @@
+package api
+
+import "testing"
+
+func TestUsageCost(t *testing.T) {
+	read, write := 1_000_000, 1_000_000
+	u := Usage{InputTokens: 1_000_000, OutputTokens: 1_000_000, CacheReadInputTokens: &read, CacheCreationInputTokens: &write}
+	if got, want := u.Cost(ModelClaude46Sonnet), 3.0+15.0+0.3+3.75; got != want {
+		t.Errorf("Cost = %v, want %v", got, want)
+	}
+	if got := u.Cost("unknown-model"); got != 0 {
+		t.Errorf("Cost for an unknown model = %v, want 0", got)
+	}
+	if got := u.TotalTokens(); got != 4_000_000 {
+		t.Errorf("TotalTokens = %d", got)
+	}
+}
@@ -10,9 +10,9 @@ import (
 
 // ClaudeMDEntry represents a loaded CLAUDE.md file with its metadata.
 type ClaudeMDEntry struct {
-	Path    string // absolute path to the file
-	Type    string // "User", "Project", "Local", "Managed"
-	Content string // file content
+	Path    string `json:"path"`    // absolute path to the file
+	Type    string `json:"type"`    // "User", "Project", "Local", "Managed"
+	Content string `json:"content"` // file content
 }
 
 // LoadClaudeMD loads and merges CLAUDE.md content from multiple locations.
@@ -3,6 +3,9 @@ package config
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -209,6 +212,25 @@ func (c *ToolPermissionContext) AddRules(behavior string, destination string, ru
 	target[destination] = append(target[destination], ruleStrings...)
 }
 
+// AddWorkingDirectory records a directory added to the session, with
+// where it came from ("cliArg" or "session").
+func (c *ToolPermissionContext) AddWorkingDirectory(dir, source string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.AdditionalWorkingDirectories[dir] = source
+}
+
+// WorkingDirectories returns the directories added to the session.
+func (c *ToolPermissionContext) WorkingDirectories() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	dirs := make([]string, 0, len(c.AdditionalWorkingDirectories))
+	for dir := range c.AdditionalWorkingDirectories {
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
+
 // RemoveRules removes session-level rules.
 func (c *ToolPermissionContext) RemoveRules(behavior string, destination string, ruleStrings []string) {
 	c.mu.Lock()
@@ -319,6 +341,181 @@ func (h *RuleBasedPermissionHandler) RequestPermission(
 // CheckPermission evaluates permission rules and returns a rich result.
 // This is the main entry point for permission checking.
 func (h *RuleBasedPermissionHandler) CheckPermission(toolName string, input json.RawMessage) PermissionResult {
+	result := h.checkToolRules(toolName, input)
+	if result.Behavior == BehaviorDeny || (result.DecisionReason != nil && result.DecisionReason.Type == ReasonMode) {
+		return result
+	}
+	if path, write := fileTarget(toolName, input); path != "" {
+		result = h.checkFileTarget(path, write, result)
+	}
+	paths, pattern := gitReadTargets(toolName, input)
+	for _, path := range paths {
+		if result = h.checkFileTarget(path, false, result); result.Behavior == BehaviorDeny {
+			return result
+		}
+	}
+	if pattern && result.Behavior == BehaviorAllow {
+		return PermissionResult{
+			Behavior: BehaviorAsk,
+			Message:  "git pathspec patterns can match files the Read rules deny",
+			DecisionReason: &DecisionReason{
+				Type:   ReasonOther,
+				Reason: "Git pathspec patterns require approval",
+			},
+		}
+	}
+	return result
+}
+
+// fileTarget returns the host file that a tool other than the file tools
+// reads or writes, such as DownloadFile's destination or the host side of
+// a Container cp, so that it can be checked against the file tools' rules.
+func fileTarget(toolName string, input json.RawMessage) (path string, write bool) {
+	switch toolName {
+	case "DownloadFile":
+		return extractStringField(input, "path"), true
+	case "Container":
+		if extractStringField(input, "operation") != "cp" {
+			return "", false
+		}
+		if dst := extractStringField(input, "destination"); dst != "" && !strings.HasPrefix(dst, "container:") {
+			return dst, true
+		}
+		if src := extractStringField(input, "source"); !strings.HasPrefix(src, "container:") {
+			return src, false
+		}
+	}
+	return "", false
+}
+
+// gitReadTargets returns the files a read-only git command shows, so that
+// they can be checked against the Read rules: its pathspecs, and the path
+// in a revision such as HEAD:secrets/.env, :.env or :2:.env. pattern
+// reports whether a pathspec is a glob or uses pathspec magic, which
+// can't be checked file by file.
+func gitReadTargets(toolName string, input json.RawMessage) (paths []string, pattern bool) {
+	var args []string
+	switch toolName {
+	case "Git":
+		if !readOnlyGitOperations[gitOperation(input)] {
+			return nil, false
+		}
+		var in struct {
+			Ref   string   `json:"ref"`
+			Paths []string `json:"paths"`
+		}
+		json.Unmarshal(input, &in)
+		args = append([]string{in.Ref}, in.Paths...)
+	case "Bash":
+		parts := strings.Fields(extractCommandFromInput(input))
+		if len(parts) < 2 || filepath.Base(parts[0]) != "git" || !readOnlyGitSubcommands[parts[1]] {
+			return nil, false
+		}
+		args = parts[2:]
+	}
+	for _, arg := range args {
+		arg = strings.Trim(arg, `'"`)
+		if arg == "" || strings.HasPrefix(arg, "-") {
+			continue
+		}
+		if strings.ContainsAny(arg, "*?[") || strings.HasPrefix(arg, ":(") {
+			pattern = true
+		}
+		if i := strings.Index(arg, ":"); i >= 0 {
+			arg = arg[i+1:]
+			if len(arg) > 1 && arg[1] == ':' && arg[0] >= '0' && arg[0] <= '3' {
+				arg = arg[2:]
+			}
+		}
+		if arg != "" {
+			paths = append(paths, arg)
+		}
+	}
+	return paths, pattern
+}
+
+// checkFileTarget applies the deny and ask rules of the file editing
+// tools (or, if !write, the file reading tools) to a file another tool
+// uses: a deny rule denies the call, and an ask rule, or a path outside
+// the working directory and the directories added to the session, turns
+// an allow from the tool's own rules into a question.
+func (h *RuleBasedPermissionHandler) checkFileTarget(path string, write bool, result PermissionResult) PermissionResult {
+	fileInput, _ := json.Marshal(map[string]string{"file_path": path})
+	fileTools, verb := []string{"FileWrite", "Write", "FileEdit", "Edit"}, "writes"
+	if !write {
+		fileTools, verb = []string{"FileRead", "Read"}, "reads"
+	}
+	var ask *PermissionResult
+	for _, tool := range fileTools {
+		rule := ""
+		if h.permCtx != nil {
+			rule = matchSessionRules(h.permCtx.GetAllRules("deny"), tool, fileInput, true)
+		}
+		fileResult := h.matchSettingsRules(tool, fileInput)
+		if rule == "" && fileResult.Behavior == BehaviorDeny {
+			rule = fileResult.DecisionReason.Rule
+		}
+		if rule != "" {
+			return PermissionResult{
+				Behavior:       BehaviorDeny,
+				Message:        fmt.Sprintf("Permission denied by rule: %s (%s %s)", rule, verb, path),
+				DecisionReason: &DecisionReason{Type: ReasonRule, Rule: rule},
+			}
+		}
+		if fileResult.Behavior == BehaviorAsk && ask == nil {
+			ask = &fileResult
+		}
+	}
+	if result.Behavior != BehaviorAllow {
+		return result
+	}
+	if ask != nil {
+		return *ask
+	}
+	if !h.inWorkingDirectories(path) {
+		return PermissionResult{
+			Behavior: BehaviorAsk,
+			Message:  fmt.Sprintf("%s is outside the working directory", path),
+			DecisionReason: &DecisionReason{
+				Type:   ReasonOther,
+				Reason: "Files outside the working directory require approval",
+			},
+		}
+	}
+	return result
+}
+
+// inWorkingDirectories reports whether path, once symlinks are resolved,
+// is in the working directory or a directory added to the session.
+func (h *RuleBasedPermissionHandler) inWorkingDirectories(path string) bool {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return false
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(cwd, path)
+	}
+	resolved := resolveSymlinks(filepath.Clean(path), maxSymlinkHops)
+	dirs := []string{cwd}
+	if h.permCtx != nil {
+		dirs = append(dirs, h.permCtx.WorkingDirectories()...)
+	}
+	for _, dir := range dirs {
+		if pathWithin(resolved, resolveSymlinks(filepath.Clean(dir), maxSymlinkHops)) {
+			return true
+		}
+	}
+	return false
+}
+
+// pathWithin reports whether path is dir or inside it.
+func pathWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// checkToolRules evaluates the permission mode and the tool's own rules.
+func (h *RuleBasedPermissionHandler) checkToolRules(toolName string, input json.RawMessage) PermissionResult {
 	// 1. Check permission mode.
 	if h.permCtx != nil {
 		mode := h.permCtx.GetMode()
@@ -368,7 +565,7 @@ func (h *RuleBasedPermissionHandler) CheckPermission(toolName string, input json
 	// 2. Check session-level always-deny rules.
 	if h.permCtx != nil {
 		denyRules := h.permCtx.GetAllRules("deny")
-		if rule := matchSessionRules(denyRules, toolName, input); rule != "" {
+		if rule := matchSessionRules(denyRules, toolName, input, true); rule != "" {
 			return PermissionResult{
 				Behavior: BehaviorDeny,
 				Message:  "Permission denied by session rule: " + rule,
@@ -383,7 +580,7 @@ func (h *RuleBasedPermissionHandler) CheckPermission(toolName string, input json
 	// 3. Check session-level always-allow rules.
 	if h.permCtx != nil {
 		allowRules := h.permCtx.GetAllRules("allow")
-		if rule := matchSessionRules(allowRules, toolName, input); rule != "" {
+		if rule := matchSessionRules(allowRules, toolName, input, false); rule != "" {
 			return PermissionResult{
 				Behavior: BehaviorAllow,
 				DecisionReason: &DecisionReason{
@@ -415,10 +612,22 @@ func (h *RuleBasedPermissionHandler) CheckPermission(toolName string, input json
 		}
 	}
 
+	// Read-only Git operations; CheckPermission checks the paths they
+	// show against the Read rules.
+	if toolName == "Git" && readOnlyGitOperations[gitOperation(input)] {
+		return PermissionResult{
+			Behavior: BehaviorAllow,
+			DecisionReason: &DecisionReason{
+				Type:   ReasonOther,
+				Reason: "Read-only git operation is allowed",
+			},
+		}
+	}
+
 	// 6. Check session-level always-ask rules.
 	if h.permCtx != nil {
 		askRules := h.permCtx.GetAllRules("ask")
-		if rule := matchSessionRules(askRules, toolName, input); rule != "" {
+		if rule := matchSessionRules(askRules, toolName, input, true); rule != "" {
 			return PermissionResult{
 				Behavior: BehaviorAsk,
 				Message:  "Permission required by session rule",
@@ -477,10 +686,17 @@ func (h *RuleBasedPermissionHandler) matchSettingsRules(toolName string, input j
 	}
 
 	value := extractMatchValue(toolName, input, "")
+	// Deny and ask rules also match file paths as written, so a rule
+	// naming a symlink still applies when the symlink is used.
+	written := rawMatchValue(toolName, input)
+	matchesEither := func(rule PermissionRule) bool {
+		return ruleMatchesValue(rule, toolName, value, input, matchExact) ||
+			(written != value && ruleMatchesValue(rule, toolName, written, input, matchExact))
+	}
 
 	// Exact deny rules take highest priority.
 	for _, rule := range denyRules {
-		if ruleMatchesValue(rule, toolName, value, input, matchExact) {
+		if matchesEither(rule) {
 			return PermissionResult{
 				Behavior: BehaviorDeny,
 				Message:  "Permission denied by rule: " + FormatRuleString(rule),
@@ -494,7 +710,7 @@ func (h *RuleBasedPermissionHandler) matchSettingsRules(toolName string, input j
 
 	// Exact ask rules.
 	for _, rule := range askRules {
-		if ruleMatchesValue(rule, toolName, value, input, matchExact) {
+		if matchesEither(rule) {
 			return PermissionResult{
 				Behavior: BehaviorAsk,
 				Message:  "Permission required by rule: " + FormatRuleString(rule),
@@ -585,11 +801,9 @@ func ruleMatchesValue(rule PermissionRule, toolName string, value string, input
 		return true
 	}
 
-	// For domain: prefix rules (WebFetch), always try.
+	// For domain: prefix rules (WebFetch, HttpRequest, DownloadFile), always try.
 	if strings.HasPrefix(rule.Pattern, "domain:") {
-		domain := strings.TrimPrefix(rule.Pattern, "domain:")
-		url := extractStringField(input, "url")
-		return strings.Contains(url, domain)
+		return urlMatchesDomain(extractStringField(input, "url"), strings.TrimPrefix(rule.Pattern, "domain:"))
 	}
 
 	if value == "" {
@@ -632,6 +846,11 @@ func matchPatternExact(pattern, value, toolName string) bool {
 		return false
 	}
 
+	// Container and RunServer values contain command lines too.
+	if toolName == "Container" || toolName == "RunServer" {
+		return simpleWildcardMatch(pattern, value)
+	}
+
 	// For file-based tools, use path-based glob matching.
 	if matched, err := doublestar.Match(pattern, value); err == nil && matched {
 		return true
@@ -727,8 +946,10 @@ func matchPatternPrefix(pattern, value string) bool {
 }
 
 // matchSessionRules checks session-level rules (stored as formatted strings
-// like "Bash(npm:*)") against a tool call.
-func matchSessionRules(rules []string, toolName string, input json.RawMessage) string {
+// like "Bash(npm:*)") against a tool call. If asWritten is set, file rules
+// also match the path as written before symlinks are resolved; deny and ask
+// rules use this, allow rules must not.
+func matchSessionRules(rules []string, toolName string, input json.RawMessage, asWritten bool) string {
 	for _, ruleStr := range rules {
 		parsed := ParseRuleString(ruleStr)
 		if parsed.Tool != toolName {
@@ -745,6 +966,11 @@ func matchSessionRules(rules []string, toolName string, input json.RawMessage) s
 		if matchPatternExact(parsed.Pattern, value, toolName) {
 			return ruleStr
 		}
+		if asWritten {
+			if written := rawMatchValue(toolName, input); written != value && matchPatternExact(parsed.Pattern, written, toolName) {
+				return ruleStr
+			}
+		}
 		if toolName == "Bash" && matchPatternPrefix(parsed.Pattern, value) {
 			return ruleStr
 		}
@@ -753,10 +979,19 @@ func matchSessionRules(rules []string, toolName string, input json.RawMessage) s
 }
 
 // extractMatchValue gets the value from tool input that should be matched
-// against the pattern.
+// against the pattern. File paths are normalized with resolveMatchPath.
 func extractMatchValue(toolName string, input json.RawMessage, pattern string) string {
+	value := rawMatchValue(toolName, input)
+	if isFilePatternTool(toolName) {
+		return resolveMatchPath(value)
+	}
+	return value
+}
+
+// rawMatchValue gets the match value from tool input as written.
+func rawMatchValue(toolName string, input json.RawMessage) string {
 	switch toolName {
-	case "Bash":
+	case "Bash", "RunServer":
 		return extractStringField(input, "command")
 	case "FileRead", "Read":
 		return extractStringField(input, "file_path")
@@ -764,9 +999,9 @@ func extractMatchValue(toolName string, input json.RawMessage, pattern string) s
 		return extractStringField(input, "file_path")
 	case "FileWrite", "Write":
 		return extractStringField(input, "file_path")
-	case "NotebookEdit":
+	case "NotebookEdit", "NotebookRead":
 		return extractStringField(input, "notebook_path")
-	case "WebFetch":
+	case "WebFetch", "HttpRequest", "DownloadFile":
 		return extractStringField(input, "url")
 	case "WebSearch":
 		return extractStringField(input, "query")
@@ -774,11 +1009,84 @@ func extractMatchValue(toolName string, input json.RawMessage, pattern string) s
 		return extractStringField(input, "path")
 	case "Grep":
 		return extractStringField(input, "path")
+	case "LS":
+		return extractStringField(input, "path")
+	case "Git":
+		return gitOperation(input)
+	case "SQL":
+		return extractStringField(input, "database")
+	case "CodeRun":
+		return extractStringField(input, "language")
+	case "Container":
+		return containerOperation(input)
 	default:
 		return ""
 	}
 }
 
+// maxSymlinkHops bounds symlink resolution, as the kernel does.
+const maxSymlinkHops = 40
+
+// resolveMatchPath normalizes a file path before it is matched against
+// permission rules: ".." components are cleaned and symlinks resolved, so
+// a rule scoped to src/** cannot be escaped with src/../../etc/passwd or a
+// symlink in src that points elsewhere. Paths that end up inside the
+// working directory keep the form they were written in (relative or
+// absolute); relative paths that end up outside it become absolute.
+func resolveMatchPath(p string) string {
+	if p == "" {
+		return ""
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return filepath.Clean(p)
+	}
+	abs := p
+	if !filepath.IsAbs(abs) {
+		abs = filepath.Join(cwd, abs)
+	}
+	resolved := resolveSymlinks(filepath.Clean(abs), maxSymlinkHops)
+
+	root, err := filepath.EvalSymlinks(cwd)
+	if err != nil {
+		root = cwd
+	}
+	rel, err := filepath.Rel(root, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return resolved
+	}
+	if filepath.IsAbs(p) {
+		return filepath.Join(cwd, rel)
+	}
+	return rel
+}
+
+// resolveSymlinks resolves the symlinks in an absolute, clean path. Unlike
+// filepath.EvalSymlinks it handles paths that don't exist yet, such as a
+// file about to be written, by resolving the longest existing prefix, and
+// it follows dangling symlinks to where a write would land.
+func resolveSymlinks(p string, hops int) string {
+	var rest []string
+	for dir := p; ; dir = filepath.Dir(dir) {
+		if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+			return filepath.Join(append([]string{resolved}, rest...)...)
+		}
+		if fi, err := os.Lstat(dir); err == nil && fi.Mode()&os.ModeSymlink != 0 && hops > 0 {
+			if target, err := os.Readlink(dir); err == nil {
+				if !filepath.IsAbs(target) {
+					target = filepath.Join(filepath.Dir(dir), target)
+				}
+				return resolveSymlinks(filepath.Join(append([]string{filepath.Clean(target)}, rest...)...), hops-1)
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return p
+		}
+		rest = append([]string{filepath.Base(dir)}, rest...)
+	}
+}
+
 // extractStringField extracts a string field from JSON input.
 func extractStringField(input json.RawMessage, key string) string {
 	if input == nil {
@@ -799,6 +1107,40 @@ func extractStringField(input json.RawMessage, key string) string {
 	return s
 }
 
+// gitOperation returns the value Git permission rules match against: the
+// operation, followed by the action for branch and stash (e.g. "commit",
+// "branch create", "stash pop").
+func gitOperation(input json.RawMessage) string {
+	op := extractStringField(input, "operation")
+	if action := extractStringField(input, "action"); action != "" && op != "" {
+		return op + " " + action
+	}
+	return op
+}
+
+// containerOperation describes a Container tool call for rule matching as
+// "<operation> <container> <command>", e.g. "exec web npm test", so rules
+// like Container(exec web:*) allow any command in one container. For cp,
+// the source and destination take the command's place, e.g.
+// "cp web container:/app/log.txt log.txt".
+func containerOperation(input json.RawMessage) string {
+	parts := []string{extractStringField(input, "operation")}
+	target := extractStringField(input, "container")
+	if target == "" {
+		target = extractStringField(input, "service")
+	}
+	rest := []string{target, extractStringField(input, "command")}
+	if parts[0] == "cp" {
+		rest = []string{target, extractStringField(input, "source"), extractStringField(input, "destination")}
+	}
+	for _, s := range rest {
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
 // extractCommandFromInput is a convenience wrapper for extracting bash commands.
 func extractCommandFromInput(input json.RawMessage) string {
 	return extractStringField(input, "command")
@@ -808,7 +1150,7 @@ func extractCommandFromInput(input json.RawMessage) string {
 // modify the filesystem or make network requests.
 func isReadOnlyTool(name string) bool {
 	switch name {
-	case "FileRead", "Read", "Glob", "Grep", "TodoWrite",
+	case "FileRead", "Read", "Glob", "Grep", "LS", "NotebookRead", "TodoWrite",
 		"AskUserQuestion", "ExitPlanMode", "TaskOutput", "Config":
 		return true
 	default:
@@ -831,7 +1173,7 @@ func isEditTool(name string) bool {
 func isFilePatternTool(name string) bool {
 	switch name {
 	case "Read", "FileRead", "Write", "FileWrite", "Edit", "FileEdit",
-		"Glob", "NotebookEdit":
+		"Glob", "LS", "NotebookEdit", "NotebookRead":
 		return true
 	default:
 		return false
@@ -863,6 +1205,14 @@ var readOnlyGitSubcommands = map[string]bool{
 	"config": true,
 }
 
+// readOnlyGitOperations lists the Git tool operations, as returned by
+// gitOperation, that only read the repository.
+var readOnlyGitOperations = map[string]bool{
+	"status": true, "diff": true, "log": true,
+	"branch": true, "branch list": true,
+	"stash": true, "stash list": true, "stash show": true,
+}
+
 // isReadOnlyCommand checks if a bash command is read-only (won't modify state).
 func isReadOnlyCommand(cmd string) bool {
 	cmd = strings.TrimSpace(cmd)
@@ -957,7 +1307,68 @@ func generateSuggestions(toolName string, input json.RawMessage) []PermissionSug
 			})
 		}
 
-	case "WebFetch":
+	case "Git":
+		if op := gitOperation(input); op != "" {
+			suggestions = append(suggestions, PermissionSuggestion{
+				Type: "addRules",
+				Rules: []PermissionRule{
+					{Tool: "Git", Pattern: op},
+				},
+				Behavior:    "allow",
+				Destination: "localSettings",
+			})
+		}
+
+	case "Container":
+		// exec is suggested for the whole container; cp only for the
+		// same copy, since its host path is a file read or write.
+		var pattern string
+		switch extractStringField(input, "operation") {
+		case "exec":
+			target := extractStringField(input, "container")
+			if target == "" {
+				target = extractStringField(input, "service")
+			}
+			pattern = "exec " + target + ":*"
+		case "cp":
+			pattern = containerOperation(input)
+		}
+		if pattern != "" {
+			suggestions = append(suggestions, PermissionSuggestion{
+				Type: "addRules",
+				Rules: []PermissionRule{
+					{Tool: "Container", Pattern: pattern},
+				},
+				Behavior:    "allow",
+				Destination: "localSettings",
+			})
+		}
+
+	case "SQL":
+		if db := extractStringField(input, "database"); db != "" {
+			suggestions = append(suggestions, PermissionSuggestion{
+				Type: "addRules",
+				Rules: []PermissionRule{
+					{Tool: "SQL", Pattern: db},
+				},
+				Behavior:    "allow",
+				Destination: "localSettings",
+			})
+		}
+
+	case "CodeRun":
+		if lang := extractStringField(input, "language"); lang != "" {
+			suggestions = append(suggestions, PermissionSuggestion{
+				Type: "addRules",
+				Rules: []PermissionRule{
+					{Tool: "CodeRun", Pattern: lang},
+				},
+				Behavior:    "allow",
+				Destination: "localSettings",
+			})
+		}
+
+	case "WebFetch", "HttpRequest", "DownloadFile":
 		url := extractStringField(input, "url")
 		if url == "" {
 			return nil
@@ -968,7 +1379,7 @@ func generateSuggestions(toolName string, input json.RawMessage) []PermissionSug
 			suggestions = append(suggestions, PermissionSuggestion{
 				Type: "addRules",
 				Rules: []PermissionRule{
-					{Tool: "WebFetch", Pattern: "domain:" + domain},
+					{Tool: toolName, Pattern: "domain:" + domain},
 				},
 				Behavior:    "allow",
 				Destination: "localSettings",
@@ -995,6 +1406,21 @@ func extractDomain(url string) string {
 	return url
 }
 
+// urlMatchesDomain reports whether the host of rawURL is domain or one of
+// its subdomains. A leading "*." on domain is accepted and means the same.
+func urlMatchesDomain(rawURL, domain string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	domain = strings.ToLower(strings.TrimPrefix(domain, "*."))
+	if host == "" || domain == "" {
+		return false
+	}
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
 // ValidateRuleString validates that a rule string is well-formed.
 // Returns an error message if invalid, or empty string if valid.
 func ValidateRuleString(s string) string {
@@ -1025,9 +1451,9 @@ func ValidateRuleString(s string) string {
 			if strings.Contains(parsed.Pattern, "*") || strings.Contains(parsed.Pattern, "?") {
 				return "WebSearch does not support wildcards"
 			}
-		case parsed.Tool == "WebFetch":
+		case parsed.Tool == "WebFetch", parsed.Tool == "HttpRequest", parsed.Tool == "DownloadFile":
 			if strings.Contains(parsed.Pattern, "://") || strings.HasPrefix(parsed.Pattern, "http") {
-				return "WebFetch rules should use domain: prefix. Example: WebFetch(domain:example.com)"
+				return fmt.Sprintf("%s rules should use domain: prefix. Example: %s(domain:example.com)", parsed.Tool, parsed.Tool)
 			}
 		}
 	}
@@ -1201,10 +1627,9 @@ func ruleMatches(rule PermissionRule, toolName string, input json.RawMessage) bo
 //   - Domain patterns: "domain:example.com" (for WebFetch)
 //   - Path patterns: "./.env", "src/**/*.go"
 func matchPattern(pattern, value string) bool {
-	// Handle domain: prefix for WebFetch.
+	// Handle domain: prefix for WebFetch, HttpRequest, and DownloadFile.
 	if strings.HasPrefix(pattern, "domain:") {
-		domain := strings.TrimPrefix(pattern, "domain:")
-		return strings.Contains(value, domain)
+		return urlMatchesDomain(value, strings.TrimPrefix(pattern, "domain:"))
 	}
 
 	// Handle :* prefix matching.
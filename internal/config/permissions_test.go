@@ -3,6 +3,8 @@ package config
 import (
 	"context"
 	"encoding/json"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -520,6 +522,12 @@ func TestExtractMatchValue(t *testing.T) {
 		{"Glob", `{"path": "/src"}`, "/src"},
 		{"Grep", `{"path": "/src"}`, "/src"},
 		{"NotebookEdit", `{"notebook_path": "test.ipynb"}`, "test.ipynb"},
+		{"LS", `{"path": "/src"}`, "/src"},
+		{"Git", `{"operation": "stash", "action": "pop"}`, "stash pop"},
+		{"Container", `{"operation": "exec", "service": "web", "command": "npm test"}`, "exec web npm test"},
+		{"SQL", `{"database": "app", "query": "select 1"}`, "app"},
+		{"HttpRequest", `{"method": "POST", "url": "https://api.example.com"}`, "https://api.example.com"},
+		{"DownloadFile", `{"url": "https://example.com/a.tgz", "path": "/tmp/a.tgz"}`, "https://example.com/a.tgz"},
 		{"Unknown", `{"any": "value"}`, ""},
 	}
 	for _, tt := range tests {
@@ -532,6 +540,98 @@ func TestExtractMatchValue(t *testing.T) {
 	}
 }
 
+func TestCheckPermissionResolvesPaths(t *testing.T) {
+	dir := t.TempDir()
+	for _, d := range []string{"src", "outside"} {
+		if err := os.Mkdir(filepath.Join(dir, d), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	os.WriteFile(filepath.Join(dir, "src", "main.go"), nil, 0o644)
+	os.WriteFile(filepath.Join(dir, "outside", "secret"), nil, 0o644)
+	os.Symlink("../outside", filepath.Join(dir, "src", "link"))
+	os.Symlink("../outside/new.txt", filepath.Join(dir, "src", "dangling"))
+	os.Symlink("../outside/secret", filepath.Join(dir, "src", "config.env"))
+	t.Chdir(dir)
+
+	handler := NewRuleBasedPermissionHandler([]PermissionRule{
+		{Tool: "Read", Pattern: "src/**", Action: "allow"},
+		{Tool: "Write", Pattern: "src/**", Action: "allow"},
+		{Tool: "Read", Pattern: "*.env", Action: "deny"},
+	}, &mockFallbackHandler{allow: false})
+
+	tests := []struct {
+		tool, path string
+		want       PermissionBehavior
+	}{
+		{"Read", "src/main.go", BehaviorAllow},
+		{"Read", "./src/../src/main.go", BehaviorAllow},
+		{"Read", filepath.Join(dir, "src", "main.go"), BehaviorAsk},
+		{"Read", "src/../../etc/passwd", BehaviorAsk},
+		{"Read", "src/../outside/secret", BehaviorAsk},
+		{"Read", "src/link/secret", BehaviorAsk},
+		{"Write", "src/new.go", BehaviorAllow},
+		{"Write", "src/link/new.txt", BehaviorAsk},
+		{"Write", "src/dangling", BehaviorAsk},
+		{"Read", "src/config.env", BehaviorDeny},
+	}
+	for _, tt := range tests {
+		input, _ := json.Marshal(map[string]string{"file_path": tt.path})
+		if got := handler.CheckPermission(tt.tool, input).Behavior; got != tt.want {
+			t.Errorf("%s(%s) = %v, want %v", tt.tool, tt.path, got, tt.want)
+		}
+	}
+
+	for path, want := range map[string]string{
+		"src/link/secret":      "outside/secret",
+		"src/../../etc/passwd": filepath.Join(filepath.Dir(dir), "etc", "passwd"),
+	} {
+		input, _ := json.Marshal(map[string]string{"file_path": path})
+		if got := extractMatchValue("Read", input, ""); got != want {
+			t.Errorf("extractMatchValue(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestCheckPermissionDownloadDestination(t *testing.T) {
+	dir, added, outside := t.TempDir(), t.TempDir(), t.TempDir()
+	t.Chdir(dir)
+
+	handler := NewRuleBasedPermissionHandler([]PermissionRule{
+		{Tool: "DownloadFile", Pattern: "domain:example.com", Action: "allow"},
+		{Tool: "Edit", Pattern: "**/.git/**", Action: "deny"},
+		{Tool: "FileWrite", Pattern: "**/*.sh", Action: "ask"},
+	}, &mockFallbackHandler{allow: false})
+	permCtx := NewToolPermissionContext()
+	permCtx.AddWorkingDirectory(added, "cliArg")
+	handler.SetPermissionContext(permCtx)
+
+	tests := []struct {
+		path string
+		want PermissionBehavior
+	}{
+		{filepath.Join(dir, "a.tgz"), BehaviorAllow},
+		{"vendor/a.tgz", BehaviorAllow},
+		{filepath.Join(added, "a.tgz"), BehaviorAllow},
+		{filepath.Join(outside, "a.tgz"), BehaviorAsk},
+		{"../a.tgz", BehaviorAsk},
+		{filepath.Join(dir, "install.sh"), BehaviorAsk},
+		{filepath.Join(dir, ".git", "hooks", "pre-commit"), BehaviorDeny},
+	}
+	for _, tt := range tests {
+		input, _ := json.Marshal(map[string]string{"url": "https://example.com/a.tgz", "path": tt.path})
+		if got := handler.CheckPermission("DownloadFile", input).Behavior; got != tt.want {
+			t.Errorf("DownloadFile to %s = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+
+	permCtx.SetMode(ModeBypassPermissions)
+	input, _ := json.Marshal(map[string]string{"url": "https://example.com/a.tgz", "path": filepath.Join(outside, "a.tgz")})
+	if got := handler.CheckPermission("DownloadFile", input).Behavior; got != BehaviorAllow {
+		t.Errorf("DownloadFile in bypass mode = %v, want allow", got)
+	}
+}
+
 // ─── ToolPermissionContext tests ───
 
 func TestToolPermissionContextModes(t *testing.T) {
@@ -652,6 +752,10 @@ func TestMatchPatternGlob(t *testing.T) {
 		{"*.go", "main.go", true},
 		{"domain:example.com", "https://example.com/path", true},
 		{"domain:example.com", "https://other.com/path", false},
+		{"domain:example.com", "https://api.example.com/v1", true},
+		{"domain:example.com", "https://notexample.com/", false},
+		{"domain:example.com", "https://evil.com/?next=example.com", false},
+		{"domain:example.com", "https://example.com@evil.com/", false},
 	}
 
 	for _, tt := range tests {
@@ -671,20 +775,20 @@ func TestMatchSessionRules(t *testing.T) {
 
 	// Match npm command.
 	input := json.RawMessage(`{"command": "npm install"}`)
-	matched := matchSessionRules(rules, "Bash", input)
+	matched := matchSessionRules(rules, "Bash", input, false)
 	if matched == "" {
 		t.Error("Expected match for npm install against Bash(npm:*)")
 	}
 
 	// Match read in src.
 	readInput := json.RawMessage(`{"file_path": "src/main.go"}`)
-	matched2 := matchSessionRules(rules, "Read", readInput)
+	matched2 := matchSessionRules(rules, "Read", readInput, false)
 	if matched2 == "" {
 		t.Error("Expected match for src/main.go against Read(src/**)")
 	}
 
 	// No match for different tool.
-	matched3 := matchSessionRules(rules, "FileWrite", readInput)
+	matched3 := matchSessionRules(rules, "FileWrite", readInput, false)
 	if matched3 != "" {
 		t.Error("Expected no match for FileWrite against read rules")
 	}
@@ -693,7 +797,7 @@ func TestMatchSessionRules(t *testing.T) {
 func TestMatchSessionRulesNoPattern(t *testing.T) {
 	rules := []string{"Bash"}
 	input := json.RawMessage(`{"command": "anything"}`)
-	matched := matchSessionRules(rules, "Bash", input)
+	matched := matchSessionRules(rules, "Bash", input, false)
 	if matched == "" {
 		t.Error("Expected match for pattern-less rule")
 	}
@@ -839,6 +943,126 @@ func TestRuleBasedPermissionHandlerWebFetchDomain(t *testing.T) {
 	}
 }
 
+func TestRuleBasedPermissionHandlerGitOperations(t *testing.T) {
+	rules := []PermissionRule{
+		{Tool: "Git", Pattern: "commit", Action: "allow"},
+		{Tool: "Git", Pattern: "branch:*", Action: "allow"},
+		{Tool: "Git", Pattern: "branch delete", Action: "deny"},
+	}
+	handler := NewRuleBasedPermissionHandler(rules, &mockFallbackHandler{allow: false})
+
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{`{"operation": "commit", "message": "x"}`, true},
+		{`{"operation": "branch", "action": "create", "name": "x"}`, true},
+		{`{"operation": "branch", "action": "delete", "name": "x"}`, false},
+		{`{"operation": "stage", "all": true}`, false},
+	}
+	for _, tt := range tests {
+		allowed, err := handler.RequestPermission(context.Background(), "Git", json.RawMessage(tt.input))
+		if err != nil {
+			t.Fatalf("RequestPermission: %v", err)
+		}
+		if allowed != tt.want {
+			t.Errorf("Git %s: allowed = %v, want %v", tt.input, allowed, tt.want)
+		}
+	}
+}
+
+func TestRuleBasedPermissionHandlerGitReadDeny(t *testing.T) {
+	t.Chdir(t.TempDir())
+	handler := NewRuleBasedPermissionHandler([]PermissionRule{
+		{Tool: "Read", Pattern: "**/.env", Action: "deny"},
+		{Tool: "Read", Pattern: "**/*.pem", Action: "deny"},
+	}, &mockFallbackHandler{allow: false})
+
+	tests := []struct {
+		tool, input string
+		want        PermissionBehavior
+	}{
+		{"Git", `{"operation": "status"}`, BehaviorAllow},
+		{"Git", `{"operation": "diff", "paths": ["src/main.go"]}`, BehaviorAllow},
+		{"Git", `{"operation": "log", "ref": "main..feature"}`, BehaviorAllow},
+		{"Git", `{"operation": "diff", "paths": ["secrets/.env"]}`, BehaviorDeny},
+		{"Git", `{"operation": "log", "paths": ["key.pem"]}`, BehaviorDeny},
+		{"Git", `{"operation": "diff", "ref": "HEAD:secrets/.env"}`, BehaviorDeny},
+		{"Git", `{"operation": "diff", "paths": ["**/*.pem"]}`, BehaviorDeny},
+		{"Git", `{"operation": "diff", "paths": ["*"]}`, BehaviorAsk},
+		{"Git", `{"operation": "diff", "paths": ["/etc/passwd"]}`, BehaviorAsk},
+		{"Bash", `{"command": "git show HEAD:secrets/.env"}`, BehaviorDeny},
+		{"Bash", `{"command": "git show ':0:.env'"}`, BehaviorDeny},
+		{"Bash", `{"command": "git diff -- **/*.pem"}`, BehaviorDeny},
+		{"Bash", `{"command": "git log --oneline -- src"}`, BehaviorAllow},
+		{"Bash", `{"command": "git show :(top)secrets"}`, BehaviorAsk},
+	}
+	for _, tt := range tests {
+		if got := handler.CheckPermission(tt.tool, json.RawMessage(tt.input)).Behavior; got != tt.want {
+			t.Errorf("%s %s = %v, want %v", tt.tool, tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestRuleBasedPermissionHandlerContainerOperations(t *testing.T) {
+	rules := []PermissionRule{
+		{Tool: "Container", Pattern: "exec web:*", Action: "allow"},
+		{Tool: "Container", Pattern: "exec web rm *", Action: "deny"},
+	}
+	handler := NewRuleBasedPermissionHandler(rules, &mockFallbackHandler{allow: false})
+
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{`{"operation": "exec", "container": "web", "command": "npm test"}`, true},
+		{`{"operation": "exec", "service": "web", "command": "ls"}`, true},
+		{`{"operation": "exec", "container": "web", "command": "rm -rf /app"}`, false},
+		{`{"operation": "exec", "container": "db", "command": "ls"}`, false},
+		{`{"operation": "cp", "container": "web", "source": "a", "destination": "container:/b"}`, false},
+	}
+	for _, tt := range tests {
+		allowed, err := handler.RequestPermission(context.Background(), "Container", json.RawMessage(tt.input))
+		if err != nil {
+			t.Fatalf("RequestPermission: %v", err)
+		}
+		if allowed != tt.want {
+			t.Errorf("Container %s: allowed = %v, want %v", tt.input, allowed, tt.want)
+		}
+	}
+}
+
+func TestRuleBasedPermissionHandlerContainerCopy(t *testing.T) {
+	t.Chdir(t.TempDir())
+	handler := NewRuleBasedPermissionHandler([]PermissionRule{
+		{Tool: "Container", Pattern: "cp web container:/app/*", Action: "allow"},
+		{Tool: "FileWrite", Pattern: "**/.env", Action: "deny"},
+		{Tool: "Read", Pattern: "**/*.pem", Action: "deny"},
+	}, &mockFallbackHandler{allow: false})
+
+	tests := []struct {
+		input string
+		want  PermissionBehavior
+	}{
+		{`{"operation": "cp", "container": "web", "source": "container:/app/log.txt", "destination": "log.txt"}`, BehaviorAllow},
+		{`{"operation": "cp", "container": "web", "source": "container:/app/.env", "destination": ".env"}`, BehaviorDeny},
+		{`{"operation": "cp", "container": "web", "source": "container:/app/log.txt", "destination": "/etc/cron.d/x"}`, BehaviorAsk},
+		{`{"operation": "cp", "container": "web", "source": "key.pem", "destination": "container:/app/key.pem"}`, BehaviorDeny},
+		{`{"operation": "cp", "container": "web", "source": "notes.txt", "destination": "container:/tmp/notes.txt"}`, BehaviorAsk},
+	}
+	for _, tt := range tests {
+		if got := handler.CheckPermission("Container", json.RawMessage(tt.input)).Behavior; got != tt.want {
+			t.Errorf("Container %s = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+
+	input := json.RawMessage(`{"operation": "cp", "container": "web", "source": "container:/app/log.txt", "destination": "log.txt"}`)
+	suggestions := generateSuggestions("Container", input)
+	if len(suggestions) != 1 || suggestions[0].Rules[0].Pattern != "cp web container:/app/log.txt log.txt" {
+		t.Errorf("cp suggestions = %+v, want the exact copy", suggestions)
+	}
+}
+
 func TestRuleBasedPermissionHandlerFilePathGlob(t *testing.T) {
 	rules := []PermissionRule{
 		{Tool: "FileRead", Pattern: "*.env", Action: "deny"},
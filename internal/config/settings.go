@@ -23,6 +23,59 @@ type StatusLineConfig struct {
 	Padding int    `json:"padding,omitempty"` // extra horizontal padding (chars)
 }
 
+// DatabaseConfig defines a database the SQL tool can query. URL is a
+// sqlite://, postgres://, or mysql:// URL (a bare path means SQLite). In
+// user and managed settings, $VAR references are expanded from the
+// environment, so passwords can stay out of settings files; a project
+// could otherwise send any of the user's variables to a server it names.
+type DatabaseConfig struct {
+	URL       string `json:"url"`
+	ReadWrite bool   `json:"readWrite,omitempty"` // allow statements that modify data
+	ExpandEnv bool   `json:"-"`                   // set by LoadSettings for user and managed settings
+}
+
+// SecretRedactionConfig controls the redaction of credentials from Bash
+// and Grep output before it reaches the model.
+type SecretRedactionConfig struct {
+	Enabled *bool    `json:"enabled,omitempty"` // default false
+	Allow   []string `json:"allow,omitempty"`   // regular expressions matching values to keep
+}
+
+// MCPSamplingConfig controls how sampling requests from MCP servers,
+// which ask the client to run a prompt through the model, are handled.
+type MCPSamplingConfig struct {
+	Policy    string            `json:"policy,omitempty"`    // "ask" (default), "allow", or "deny"
+	Servers   map[string]string `json:"servers,omitempty"`   // policy overrides by server name
+	MaxTokens int               `json:"maxTokens,omitempty"` // per-request token cap; 0 uses the default
+}
+
+// SessionSyncConfig says where `claude sessions sync` keeps sessions.
+// Credentials come from the environment: CLAUDE_SYNC_USERNAME and
+// CLAUDE_SYNC_PASSWORD for WebDAV, the usual AWS_* variables for S3.
+type SessionSyncConfig struct {
+	URL      string `json:"url"`                // a WebDAV collection (https://...) or s3://bucket/prefix
+	Endpoint string `json:"endpoint,omitempty"` // S3-compatible service URL; empty uses AWS
+	Region   string `json:"region,omitempty"`   // S3 region; empty uses AWS_REGION or us-east-1
+	Project  string `json:"project,omitempty"`  // remote folder for this project; empty derives one from the git remote
+}
+
+// SessionRetentionConfig limits the sessions kept for a project. Older
+// sessions past a limit are deleted at startup, at most once a day, and by
+// `claude sessions prune`. Named and pinned sessions are never deleted.
+// Zero means no limit.
+type SessionRetentionConfig struct {
+	MaxAgeDays  int `json:"maxAgeDays,omitempty"`  // sessions not updated for this many days
+	MaxSessions int `json:"maxSessions,omitempty"` // sessions beyond the newest this many
+	MaxSizeMB   int `json:"maxSizeMB,omitempty"`   // sessions beyond the newest this many megabytes
+}
+
+// ToolLimit caps how a tool is used, to protect the machine and external
+// services from runaway agent loops. Zero means unlimited.
+type ToolLimit struct {
+	MaxConcurrent int `json:"maxConcurrent,omitempty"` // calls running at once
+	PerMinute     int `json:"perMinute,omitempty"`     // calls started in any 60-second window
+}
+
 // Settings holds merged configuration from all levels.
 type Settings struct {
 	Permissions []PermissionRule  `json:"permissions,omitempty"`
@@ -42,9 +95,74 @@ type Settings struct {
 	RespectGitignore    *bool  `json:"respectGitignore,omitempty"`
 	FastMode            *bool  `json:"fastMode,omitempty"`
 
+	// Sampling parameters sent with every API request.
+	Temperature   *float64 `json:"temperature,omitempty"`
+	TopP          *float64 `json:"topP,omitempty"`
+	StopSequences []string `json:"stopSequences,omitempty"`
+
+	// System prompt preset file (default .claude/system-prompt.md).
+	SystemPromptFile string `json:"systemPromptFile,omitempty"`
+
+	// PersistentShell keeps cwd, exported variables, and functions across
+	// Bash calls (default false).
+	PersistentShell *bool `json:"persistentShell,omitempty"`
+
 	// Custom status line.
 	StatusLine *StatusLineConfig `json:"statusLine,omitempty"`
 
+	// Databases available to the SQL tool, by name.
+	Databases map[string]DatabaseConfig `json:"databases,omitempty"`
+
+	// HttpCredentials names the environment variables HttpRequest may send
+	// as auth, by host ("api.github.com", or "*.example.com" for its
+	// subdomains). Only read from user and managed settings.
+	HttpCredentials map[string][]string `json:"httpCredentials,omitempty"`
+
+	// ToolLimits limits tool calls by tool name; the "*" entry applies to
+	// all tool calls together.
+	ToolLimits map[string]ToolLimit `json:"toolLimits,omitempty"`
+
+	// SecretRedaction controls redaction of credentials from tool output.
+	SecretRedaction *SecretRedactionConfig `json:"secretRedaction,omitempty"`
+
+	// MCPSampling controls whether MCP servers may sample the model.
+	MCPSampling *MCPSamplingConfig `json:"mcpSampling,omitempty"`
+
+	// ModelSlashCommands lists the user-defined slash commands the model
+	// may run itself with the SlashCommand and Skill tools; "*" allows all
+	// of them.
+	ModelSlashCommands []string `json:"modelSlashCommands,omitempty"`
+
+	// MaxToolResultBytes is the size above which tool results are saved to
+	// .claude/tool-output and replaced by a preview. 0 uses the default;
+	// a negative value disables spilling.
+	MaxToolResultBytes int `json:"maxToolResultBytes,omitempty"`
+
+	// MaxAgentDepth is how deeply sub-agents may nest; at 1, sub-agents
+	// can't start agents of their own. 0 uses the default of 2.
+	MaxAgentDepth int `json:"maxAgentDepth,omitempty"`
+
+	// SessionBackend is "sqlite" to keep the project's sessions in a SQLite
+	// database instead of a JSON file each. Empty or "json" uses files.
+	SessionBackend string `json:"sessionBackend,omitempty"`
+
+	// SessionEncryption encrypts session content at rest: "keychain" with
+	// a key kept in the OS keychain, or "passphrase" with a key derived
+	// from a passphrase. Empty leaves sessions unencrypted.
+	SessionEncryption string `json:"sessionEncryption,omitempty"`
+
+	// SessionSync configures the remote store for `claude sessions sync`.
+	SessionSync *SessionSyncConfig `json:"sessionSync,omitempty"`
+
+	// SessionRetention prunes old sessions; nil keeps them all.
+	SessionRetention *SessionRetentionConfig `json:"sessionRetention,omitempty"`
+
+	// ResumeContext chooses the CLAUDE.md files and skills a resumed
+	// session runs with when they changed since it started: "pinned" for
+	// its snapshot, "current" for the files now, or "ask" (the default)
+	// to ask on a terminal and keep the snapshot otherwise.
+	ResumeContext string `json:"resumeContext,omitempty"`
+
 	// Permission mode settings.
 	DefaultPermissionMode string `json:"defaultPermissionMode,omitempty"` // default, plan, acceptEdits, bypassPermissions, dontAsk
 
@@ -91,9 +209,51 @@ type rawSettings struct {
 	RespectGitignore   *bool  `json:"respectGitignore,omitempty"`
 	FastMode           *bool  `json:"fastMode,omitempty"`
 
+	// Sampling parameters.
+	Temperature   *float64 `json:"temperature,omitempty"`
+	TopP          *float64 `json:"topP,omitempty"`
+	StopSequences []string `json:"stopSequences,omitempty"`
+
+	// System prompt preset.
+	SystemPromptFile string `json:"systemPromptFile,omitempty"`
+
+	// Bash tool behavior.
+	PersistentShell *bool `json:"persistentShell,omitempty"`
+
 	// Custom status line.
 	StatusLine *StatusLineConfig `json:"statusLine,omitempty"`
 
+	// SQL tool databases.
+	Databases map[string]DatabaseConfig `json:"databases,omitempty"`
+
+	// Environment variables HttpRequest may send, by host.
+	HttpCredentials map[string][]string `json:"httpCredentials,omitempty"`
+
+	// Tool concurrency and rate limits.
+	ToolLimits map[string]ToolLimit `json:"toolLimits,omitempty"`
+
+	// Secret redaction in tool output.
+	SecretRedaction *SecretRedactionConfig `json:"secretRedaction,omitempty"`
+
+	// MCP server sampling policy.
+	MCPSampling *MCPSamplingConfig `json:"mcpSampling,omitempty"`
+
+	// Slash commands the model may invoke.
+	ModelSlashCommands []string `json:"modelSlashCommands,omitempty"`
+
+	// Tool result size before spilling to a file.
+	MaxToolResultBytes int `json:"maxToolResultBytes,omitempty"`
+
+	// Sub-agent nesting limit.
+	MaxAgentDepth int `json:"maxAgentDepth,omitempty"`
+
+	// Session storage backend.
+	SessionBackend    string                  `json:"sessionBackend,omitempty"`
+	SessionEncryption string                  `json:"sessionEncryption,omitempty"`
+	SessionSync       *SessionSyncConfig      `json:"sessionSync,omitempty"`
+	SessionRetention  *SessionRetentionConfig `json:"sessionRetention,omitempty"`
+	ResumeContext     string                  `json:"resumeContext,omitempty"`
+
 	// Permission mode settings.
 	DefaultPermissionMode    string `json:"defaultPermissionMode,omitempty"`
 	DisableBypassPermissions string `json:"disableBypassPermissions,omitempty"`
@@ -113,11 +273,21 @@ func LoadSettings(cwd string) (*Settings, error) {
 	// Load from lowest to highest priority, merging as we go.
 	// Higher priority settings override lower priority ones.
 	merged := &Settings{}
-	for _, path := range paths {
+	for i, path := range paths {
 		layer, err := loadSettingsFile(path)
 		if err != nil {
 			continue // file doesn't exist or is invalid — skip
 		}
+		if scope := settingsScopes[i]; scope == "user" || scope == "managed" {
+			for name, db := range layer.Databases {
+				db.ExpandEnv = true
+				layer.Databases[name] = db
+			}
+		} else {
+			// A project could otherwise let its own host receive any of
+			// the user's variables.
+			layer.HttpCredentials = nil
+		}
 		merged = mergeSettings(merged, layer)
 	}
 
@@ -138,6 +308,46 @@ func settingsPaths(home, cwd string) []string {
 	}
 }
 
+// settingsScopes names the files settingsPaths returns, in order.
+var settingsScopes = []string{"user", "project", "local", "managed"}
+
+// SettingsFile is one of the settings files LoadSettings merges.
+type SettingsFile struct {
+	Scope string // "user", "project", "local", or "managed"
+	Path  string
+}
+
+// SettingsFiles returns the settings files for cwd, from lowest to highest
+// priority. The managed file is for administrators, not for editing.
+func SettingsFiles(cwd string) ([]SettingsFile, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("cannot determine home directory: %w", err)
+	}
+	var files []SettingsFile
+	for i, path := range settingsPaths(home, cwd) {
+		files = append(files, SettingsFile{Scope: settingsScopes[i], Path: path})
+	}
+	return files, nil
+}
+
+// ReadSetting returns the raw value of key in the settings file at path,
+// or nil if the file or the key doesn't exist.
+func ReadSetting(path, key string) (json.RawMessage, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading settings: %w", err)
+	}
+	var settings map[string]json.RawMessage
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return settings[key], nil
+}
+
 // loadSettingsFile reads and parses a single settings JSON file.
 // It supports both the Go format (flat rule array) and the JS format
 // (permissions: {allow:[], deny:[], ask:[]}).
@@ -166,7 +376,25 @@ func loadSettingsFile(path string) (*Settings, error) {
 		Theme:                    raw.Theme,
 		RespectGitignore:         raw.RespectGitignore,
 		FastMode:                 raw.FastMode,
+		Temperature:              raw.Temperature,
+		TopP:                     raw.TopP,
+		StopSequences:            raw.StopSequences,
+		SystemPromptFile:         raw.SystemPromptFile,
+		PersistentShell:          raw.PersistentShell,
 		StatusLine:               raw.StatusLine,
+		Databases:                raw.Databases,
+		HttpCredentials:          raw.HttpCredentials,
+		ToolLimits:               raw.ToolLimits,
+		SecretRedaction:          raw.SecretRedaction,
+		MCPSampling:              raw.MCPSampling,
+		ModelSlashCommands:       raw.ModelSlashCommands,
+		MaxToolResultBytes:       raw.MaxToolResultBytes,
+		MaxAgentDepth:            raw.MaxAgentDepth,
+		SessionBackend:           raw.SessionBackend,
+		SessionEncryption:        raw.SessionEncryption,
+		SessionSync:              raw.SessionSync,
+		SessionRetention:         raw.SessionRetention,
+		ResumeContext:            raw.ResumeContext,
 		DefaultPermissionMode:    raw.DefaultPermissionMode,
 		DisableBypassPermissions: raw.DisableBypassPermissions,
 	}
@@ -402,7 +630,8 @@ func mergeSettings(base, overlay *Settings) *Settings {
 		result.Env[k] = v
 	}
 
-	// Hooks: overlay wins if set.
+	// Hooks: overlay wins if set. The hooks that run are merged from
+	// every file by hooks.Load.
 	result.Hooks = base.Hooks
 	if overlay.Hooks != nil {
 		result.Hooks = overlay.Hooks
@@ -452,10 +681,106 @@ func mergeSettings(base, overlay *Settings) *Settings {
 		result.FastMode = overlay.FastMode
 	}
 
+	result.Temperature = base.Temperature
+	if overlay.Temperature != nil {
+		result.Temperature = overlay.Temperature
+	}
+	result.TopP = base.TopP
+	if overlay.TopP != nil {
+		result.TopP = overlay.TopP
+	}
+	result.StopSequences = base.StopSequences
+	if overlay.StopSequences != nil {
+		result.StopSequences = overlay.StopSequences
+	}
+
+	// ModelSlashCommands: overlay wins if set.
+	result.ModelSlashCommands = base.ModelSlashCommands
+	if overlay.ModelSlashCommands != nil {
+		result.ModelSlashCommands = overlay.ModelSlashCommands
+	}
+	result.MaxToolResultBytes = base.MaxToolResultBytes
+	if overlay.MaxToolResultBytes != 0 {
+		result.MaxToolResultBytes = overlay.MaxToolResultBytes
+	}
+	result.MaxAgentDepth = base.MaxAgentDepth
+	if overlay.MaxAgentDepth != 0 {
+		result.MaxAgentDepth = overlay.MaxAgentDepth
+	}
+	result.SessionBackend = base.SessionBackend
+	if overlay.SessionBackend != "" {
+		result.SessionBackend = overlay.SessionBackend
+	}
+	result.SessionEncryption = base.SessionEncryption
+	if overlay.SessionEncryption != "" {
+		result.SessionEncryption = overlay.SessionEncryption
+	}
+	result.SessionSync = base.SessionSync
+	if overlay.SessionSync != nil {
+		result.SessionSync = overlay.SessionSync
+	}
+	result.SessionRetention = base.SessionRetention
+	if overlay.SessionRetention != nil {
+		result.SessionRetention = overlay.SessionRetention
+	}
+	result.ResumeContext = base.ResumeContext
+	if overlay.ResumeContext != "" {
+		result.ResumeContext = overlay.ResumeContext
+	}
+	result.SystemPromptFile = base.SystemPromptFile
+	if overlay.SystemPromptFile != "" {
+		result.SystemPromptFile = overlay.SystemPromptFile
+	}
+	result.PersistentShell = base.PersistentShell
+	if overlay.PersistentShell != nil {
+		result.PersistentShell = overlay.PersistentShell
+	}
+
 	result.StatusLine = base.StatusLine
 	if overlay.StatusLine != nil {
 		result.StatusLine = overlay.StatusLine
 	}
+	result.SecretRedaction = base.SecretRedaction
+	if overlay.SecretRedaction != nil {
+		result.SecretRedaction = overlay.SecretRedaction
+	}
+	result.MCPSampling = base.MCPSampling
+	if overlay.MCPSampling != nil {
+		result.MCPSampling = overlay.MCPSampling
+	}
+
+	// Databases: merged by name, overlay wins per name.
+	if len(base.Databases) > 0 || len(overlay.Databases) > 0 {
+		result.Databases = make(map[string]DatabaseConfig)
+		for k, v := range base.Databases {
+			result.Databases[k] = v
+		}
+		for k, v := range overlay.Databases {
+			result.Databases[k] = v
+		}
+	}
+
+	// HttpCredentials: merged by host, overlay wins per host.
+	if len(base.HttpCredentials) > 0 || len(overlay.HttpCredentials) > 0 {
+		result.HttpCredentials = make(map[string][]string)
+		for k, v := range base.HttpCredentials {
+			result.HttpCredentials[k] = v
+		}
+		for k, v := range overlay.HttpCredentials {
+			result.HttpCredentials[k] = v
+		}
+	}
+
+	// ToolLimits: merged by tool name, overlay wins per name.
+	if len(base.ToolLimits) > 0 || len(overlay.ToolLimits) > 0 {
+		result.ToolLimits = make(map[string]ToolLimit)
+		for k, v := range base.ToolLimits {
+			result.ToolLimits[k] = v
+		}
+		for k, v := range overlay.ToolLimits {
+			result.ToolLimits[k] = v
+		}
+	}
 
 	result.DefaultPermissionMode = base.DefaultPermissionMode
 	if overlay.DefaultPermissionMode != "" {
@@ -492,7 +817,12 @@ func SaveUserSetting(key string, value interface{}) error {
 	if err != nil {
 		return err
 	}
+	return SaveSetting(path, key, value)
+}
 
+// SaveSetting saves a single key/value pair to the settings file at path,
+// creating it if needed and keeping its other keys.
+func SaveSetting(path, key string, value interface{}) error {
 	// Read existing settings as raw map.
 	var settings map[string]interface{}
 	data, err := os.ReadFile(path)
@@ -6,9 +6,12 @@ import (
 	"github.com/anthropics/claude-code-go/internal/api"
 )
 
-// History manages conversation messages for the agentic loop.
+// History manages conversation messages for the agentic loop. It holds
+// every message since the last compaction in memory, since each request
+// sends them all; the session's message log only makes saving them cheap.
 type History struct {
 	messages []api.Message
+	turns    []TurnMetadata // per-turn metadata; survives compaction
 }
 
 // NewHistory creates an empty conversation history.
@@ -33,11 +36,31 @@ func (h *History) SetMessages(msgs []api.Message) {
 	h.messages = msgs
 }
 
+// Turns returns the recorded per-turn metadata.
+func (h *History) Turns() []TurnMetadata {
+	return h.turns
+}
+
+// SetTurns replaces the turn metadata (for session resume).
+func (h *History) SetTurns(turns []TurnMetadata) {
+	h.turns = turns
+}
+
+// AddTurn records metadata for a completed assistant turn.
+func (h *History) AddTurn(t TurnMetadata) {
+	h.turns = append(h.turns, t)
+}
+
 // AddUserMessage appends a user text message.
 func (h *History) AddUserMessage(text string) {
 	h.messages = append(h.messages, api.NewTextMessage(api.RoleUser, text))
 }
 
+// AddUserBlocks appends a user message made of content blocks.
+func (h *History) AddUserBlocks(blocks []api.ContentBlock) {
+	h.messages = append(h.messages, api.NewBlockMessage(api.RoleUser, blocks))
+}
+
 // AddAssistantResponse appends the assistant's response (with content blocks).
 func (h *History) AddAssistantResponse(blocks []api.ContentBlock) {
 	h.messages = append(h.messages, api.NewBlockMessage(api.RoleAssistant, blocks))
@@ -76,3 +99,20 @@ func MakeToolResult(toolUseID string, content string, isError bool) api.ContentB
 		IsError:   isError,
 	}
 }
+
+// MakeToolResultBlocks creates a tool_result content block whose content is
+// the text output followed by extra blocks such as images.
+func MakeToolResultBlocks(toolUseID string, text string, blocks []api.ContentBlock, isError bool) api.ContentBlock {
+	content := make([]api.ContentBlock, 0, len(blocks)+1)
+	if text != "" {
+		content = append(content, api.ContentBlock{Type: api.ContentTypeText, Text: text})
+	}
+	content = append(content, blocks...)
+	contentJSON, _ := json.Marshal(content)
+	return api.ContentBlock{
+		Type:      api.ContentTypeToolResult,
+		ToolUseID: toolUseID,
+		Content:   contentJSON,
+		IsError:   isError,
+	}
+}
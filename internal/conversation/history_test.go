@@ -1,6 +1,7 @@
 package conversation
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/anthropics/claude-code-go/internal/api"
@@ -126,3 +127,28 @@ func TestNewHistoryFromIndependence(t *testing.T) {
 		t.Error("NewHistoryFrom should copy messages, not reference them")
 	}
 }
+
+func TestMakeToolResultBlocks(t *testing.T) {
+	img := api.ContentBlock{
+		Type:   api.ContentTypeImage,
+		Source: &api.ImageSource{Type: "base64", MediaType: "image/png", Data: "AAAA"},
+	}
+	result := MakeToolResultBlocks("tool_1", "Image: /tmp/a.png", []api.ContentBlock{img}, false)
+	if result.Type != api.ContentTypeToolResult || result.ToolUseID != "tool_1" {
+		t.Fatalf("unexpected result block: %+v", result)
+	}
+
+	var content []api.ContentBlock
+	if err := json.Unmarshal(result.Content, &content); err != nil {
+		t.Fatalf("content is not a block array: %v", err)
+	}
+	if len(content) != 2 {
+		t.Fatalf("got %d content blocks, want 2", len(content))
+	}
+	if content[0].Type != api.ContentTypeText || content[0].Text != "Image: /tmp/a.png" {
+		t.Errorf("content[0] = %+v, want text summary", content[0])
+	}
+	if content[1].Type != api.ContentTypeImage || content[1].Source == nil || content[1].Source.Data != "AAAA" {
+		t.Errorf("content[1] = %+v, want image", content[1])
+	}
+}
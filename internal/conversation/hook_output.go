@@ -0,0 +1,49 @@
+package conversation
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Hook decisions, from the "decision" field of a hook's JSON output.
+const (
+	HookApprove = "approve"
+	HookBlock   = "block"
+)
+
+// HookOutput is what the hooks for an event decided, from the JSON they
+// printed. The zero value lets the action go ahead unchanged.
+type HookOutput struct {
+	Decision          string          // HookApprove, HookBlock, or "" to leave it to the usual checks
+	Reason            string          // why; a block's reason is passed to the model
+	UpdatedInput      json.RawMessage // replaces the tool input (PreToolUse)
+	AdditionalContext string          // added to what the model sees next
+	Message           string          // output to show the user, unless a hook suppressed it
+}
+
+// HookMessageHandler is implemented by stream handlers that can show the
+// user what hooks printed, such as a PreToolUse hook's reason for
+// blocking a call.
+type HookMessageHandler interface {
+	OnHookMessage(event, message string)
+}
+
+// PermissionHook decides a tool call before the user is asked to permit
+// it. HookApprove allows the call without asking, HookBlock denies it
+// with the output's Reason, and "" asks as usual.
+type PermissionHook func(ctx context.Context, toolName string, input json.RawMessage) HookOutput
+
+type permissionHookKey struct{}
+
+// WithPermissionHook returns a context carrying a hook for the tool
+// executor to consult before prompting for permission. The loop sets it
+// for each call, from the PreToolUse and PermissionRequest hooks.
+func WithPermissionHook(ctx context.Context, hook PermissionHook) context.Context {
+	return context.WithValue(ctx, permissionHookKey{}, hook)
+}
+
+// PermissionHookFrom returns the permission hook in ctx, or nil.
+func PermissionHookFrom(ctx context.Context) PermissionHook {
+	hook, _ := ctx.Value(permissionHookKey{}).(PermissionHook)
+	return hook
+}
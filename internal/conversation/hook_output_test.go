@@ -0,0 +1,111 @@
+package conversation
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/anthropics/claude-code-go/internal/api"
+)
+
+// fakeHooks answers the tool and permission events with fixed outputs.
+// The other events aren't used by executeTool.
+type fakeHooks struct {
+	HookRunner
+	pre, post, permission HookOutput
+	permissionAsked       bool
+}
+
+func (h *fakeHooks) RunPreToolUse(context.Context, string, json.RawMessage) (HookOutput, error) {
+	return h.pre, nil
+}
+
+func (h *fakeHooks) RunPostToolUse(context.Context, string, json.RawMessage, string, bool) (HookOutput, error) {
+	return h.post, nil
+}
+
+func (h *fakeHooks) RunPermissionRequest(context.Context, string, json.RawMessage) (HookOutput, error) {
+	h.permissionAsked = true
+	return h.permission, nil
+}
+
+// permissionExec is a ToolExecutor whose one tool needs permission, which
+// it asks the hook in ctx for, as the registry does.
+type permissionExec struct {
+	input    json.RawMessage
+	decision string
+}
+
+func (e *permissionExec) HasTool(string) bool { return true }
+
+func (e *permissionExec) Execute(ctx context.Context, name string, input []byte) (string, error) {
+	e.input = input
+	if hook := PermissionHookFrom(ctx); hook != nil {
+		e.decision = hook(ctx, name, input).Decision
+	}
+	return "ran", nil
+}
+
+func resultText(t *testing.T, b api.ContentBlock) string {
+	t.Helper()
+	var s string
+	if err := json.Unmarshal(b.Content, &s); err != nil {
+		t.Fatalf("tool result content %s: %v", b.Content, err)
+	}
+	return s
+}
+
+func TestExecuteToolHookOutput(t *testing.T) {
+	call := api.ContentBlock{ID: "t1", Name: "Bash", Input: json.RawMessage(`{"command":"rm -rf build"}`)}
+
+	t.Run("block", func(t *testing.T) {
+		exec := &permissionExec{}
+		loop := NewLoop(LoopConfig{ToolExec: exec, Hooks: &fakeHooks{pre: HookOutput{Decision: HookBlock, Reason: "no rm"}}})
+		res := loop.executeTool(context.Background(), call)
+		if !res.IsError || resultText(t, res) != "Hook blocked tool execution: no rm" || exec.input != nil {
+			t.Errorf("result %q (error %v), ran with %s", resultText(t, res), res.IsError, exec.input)
+		}
+	})
+
+	t.Run("approve and rewrite", func(t *testing.T) {
+		exec := &permissionExec{}
+		hooks := &fakeHooks{
+			pre:  HookOutput{Decision: HookApprove, UpdatedInput: json.RawMessage(`{"command":"rm -rf ./build"}`), AdditionalContext: "build is generated"},
+			post: HookOutput{Decision: HookBlock, Reason: "run make next"},
+		}
+		loop := NewLoop(LoopConfig{ToolExec: exec, Hooks: hooks})
+		res := loop.executeTool(context.Background(), call)
+		if string(exec.input) != `{"command":"rm -rf ./build"}` {
+			t.Errorf("ran with %s, want the updated input", exec.input)
+		}
+		if exec.decision != HookApprove || hooks.permissionAsked {
+			t.Errorf("permission decision %q (PermissionRequest asked: %v), want approved by PreToolUse", exec.decision, hooks.permissionAsked)
+		}
+		want := "ran\n\nbuild is generated\n\nPostToolUse hook feedback:\nrun make next"
+		if got := resultText(t, res); got != want {
+			t.Errorf("result = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("permission request", func(t *testing.T) {
+		exec := &permissionExec{}
+		hooks := &fakeHooks{permission: HookOutput{Decision: HookBlock, Reason: "not on CI"}}
+		loop := NewLoop(LoopConfig{ToolExec: exec, Hooks: hooks})
+		loop.executeTool(context.Background(), call)
+		if exec.decision != HookBlock || !hooks.permissionAsked {
+			t.Errorf("permission decision %q, want the PermissionRequest hook's block", exec.decision)
+		}
+	})
+
+	t.Run("no hooks", func(t *testing.T) {
+		// An approval from a parent loop's hooks doesn't reach here.
+		exec := &permissionExec{}
+		ctx := WithPermissionHook(context.Background(), func(context.Context, string, json.RawMessage) HookOutput {
+			return HookOutput{Decision: HookApprove}
+		})
+		NewLoop(LoopConfig{ToolExec: exec}).executeTool(ctx, call)
+		if exec.decision != "" {
+			t.Errorf("permission decision %q, want none", exec.decision)
+		}
+	})
+}
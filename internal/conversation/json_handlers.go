@@ -12,6 +12,7 @@ import (
 // when the message is complete. Used with --output-format json.
 type JSONStreamHandler struct {
 	writer     io.Writer
+	sessionID  string
 	content    []api.ContentBlock
 	usage      api.Usage
 	model      string
@@ -33,6 +34,12 @@ func NewJSONStreamHandler(w io.Writer) *JSONStreamHandler {
 	}
 }
 
+// SetSessionID sets the session ID included in each message, so a script
+// can continue the conversation with -r.
+func (h *JSONStreamHandler) SetSessionID(id string) {
+	h.sessionID = id
+}
+
 func (h *JSONStreamHandler) OnMessageStart(msg api.MessageResponse) {
 	h.model = msg.Model
 	h.usage.InputTokens = msg.Usage.InputTokens
@@ -104,6 +111,9 @@ func (h *JSONStreamHandler) OnMessageStop() {
 			"output_tokens": h.usage.OutputTokens,
 		},
 	}
+	if h.sessionID != "" {
+		msg["session_id"] = h.sessionID
+	}
 	data, _ := json.Marshal(msg)
 	fmt.Fprintln(h.writer, string(data))
 }
@@ -6,6 +6,9 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/anthropics/claude-code-go/internal/api"
 	"github.com/anthropics/claude-code-go/internal/config"
@@ -17,21 +20,74 @@ type ToolExecutor interface {
 	HasTool(name string) bool
 }
 
+// ParallelToolExecutor is implemented by executors that allow calls to
+// some tools to run concurrently when the model makes several in one turn.
+type ParallelToolExecutor interface {
+	// MaxParallel returns how many calls to the named tool may run at
+	// once; 1 or less means one at a time.
+	MaxParallel(name string) int
+}
+
 // HookRunner fires lifecycle hooks at various points in the agentic loop.
-// A nil HookRunner means no hooks are configured.
+// A nil HookRunner means no hooks are configured. The tool, Stop, and
+// PermissionRequest events return what the hooks decided; an error means
+// a hook failed, which blocks the action where it can be blocked.
 type HookRunner interface {
-	RunPreToolUse(ctx context.Context, toolName string, input json.RawMessage) error
-	RunPostToolUse(ctx context.Context, toolName string, input json.RawMessage, output string, isError bool) error
+	RunPreToolUse(ctx context.Context, toolName string, input json.RawMessage) (HookOutput, error)
+	RunPostToolUse(ctx context.Context, toolName string, input json.RawMessage, output string, isError bool) (HookOutput, error)
 	RunUserPromptSubmit(ctx context.Context, message string) (HookSubmitResult, error)
-	RunSessionStart(ctx context.Context) error
-	RunStop(ctx context.Context) error
-	RunPermissionRequest(ctx context.Context, toolName string, input json.RawMessage) error
+	RunSessionStart(ctx context.Context, source string) error
+	RunSessionEnd(ctx context.Context, reason string) error
+	RunStop(ctx context.Context, active bool) (HookOutput, error)
+	RunNotification(ctx context.Context, message string) error
+	RunPreCompact(ctx context.Context, trigger string) error
+	RunPermissionRequest(ctx context.Context, toolName string, input json.RawMessage) (HookOutput, error)
+	RunSubagentStart(ctx context.Context, ev SubagentEvent) error
+	RunSubagentStop(ctx context.Context, ev SubagentEvent) error
+}
+
+// SessionHookRunner is implemented by hook runners that tell hooks which
+// session they run in. It's called again when the user switches sessions.
+type SessionHookRunner interface {
+	// SetSession sets the session's ID and the path of its JSONL
+	// transcript, "" if it has none.
+	SetSession(id, transcriptPath string)
+}
+
+// SubagentEvent describes a sub-agent run for the SubagentStart and
+// SubagentStop hooks. The outcome fields are set for SubagentStop only.
+type SubagentEvent struct {
+	AgentID     string
+	AgentType   string // subagent_type
+	Description string
+	Prompt      string
+	Background  bool
+
+	Status      string // "completed", "error", or "interrupted"
+	ResultChars int
+	ToolUses    int
+	Tokens      int
+	CostUSD     float64
+	DurationMs  int64
 }
 
 // HookSubmitResult is the outcome of a UserPromptSubmit hook.
 type HookSubmitResult struct {
 	Block   bool   // true = reject the message
+	Reason  string // why it was rejected, for the user
 	Message string // possibly modified message
+	Context string // additional context for the model, sent with the message
+}
+
+// PromptBlockedError is returned by SendMessage when a UserPromptSubmit
+// hook rejects the message, which isn't added to the history.
+type PromptBlockedError struct {
+	Prompt string // the message as the user sent it
+	Reason string
+}
+
+func (e *PromptBlockedError) Error() string {
+	return "prompt blocked by hook: " + e.Reason
 }
 
 // Loop is the main agentic conversation loop.
@@ -39,16 +95,37 @@ type Loop struct {
 	client         *api.Client
 	history        *History
 	system         []api.SystemBlock
-	tools          []api.ToolDefinition
 	toolExec       ToolExecutor
 	handler        api.StreamHandler
 	compactor      *Compactor
 	onTurnComplete func(history *History)
+	onMessage      func(history *History)
+	onToolResult   func(result api.ContentBlock)
 	hooks          HookRunner // Phase 7: nil = no hooks
-	fastMode       bool       // when true, sends speed:"fast" on eligible models
-	contextMessage string     // <system-reminder> context prepended to messages
+	spiller        *ResultSpiller
+	fastMode       bool   // when true, sends speed:"fast" on eligible models
+	contextMessage string // <system-reminder> context prepended to messages
 	thinking       *api.ThinkingConfig
 	maxTurns       int // 0 = unlimited
+	temperature    *float64
+	topP           *float64
+	stopSequences  []string
+
+	// Tool definitions sent with each request. They can change while the
+	// loop runs, as MCP servers add and remove tools.
+	toolsMu sync.Mutex
+	tools   []api.ToolDefinition
+
+	// Messages sent with Steer while the loop runs, delivered at the next
+	// turn boundary.
+	steerMu sync.Mutex
+	running bool
+	steered []string
+
+	// System reminders queued with Remind, delivered with the next message
+	// to the model.
+	remindMu  sync.Mutex
+	reminders []string
 }
 
 // LoopConfig configures the agentic loop.
@@ -60,9 +137,15 @@ type LoopConfig struct {
 	Handler        api.StreamHandler
 	History        *History               // if non-nil, resume from this history
 	Compactor      *Compactor             // if non-nil, enables auto-compaction
-	OnTurnComplete func(history *History)  // called after each API round-trip
+	OnTurnComplete func(history *History) // called after each API round-trip
+	OnMessage      func(history *History) // called when a message is added mid-turn, before tools run
+	OnToolResult   func(api.ContentBlock) // called as each tool call finishes; may be called concurrently
 	Hooks          HookRunner             // Phase 7: nil = no hooks
 	ContextMessage string                 // <system-reminder> context prepended to messages
+	Temperature    *float64               // nil = API default
+	TopP           *float64               // nil = API default
+	StopSequences  []string               // custom stop sequences
+	Spiller        *ResultSpiller         // if non-nil, saves oversized tool results to files
 }
 
 // NewLoop creates a new agentic conversation loop.
@@ -80,8 +163,14 @@ func NewLoop(cfg LoopConfig) *Loop {
 		handler:        cfg.Handler,
 		compactor:      cfg.Compactor,
 		onTurnComplete: cfg.OnTurnComplete,
+		onMessage:      cfg.OnMessage,
+		onToolResult:   cfg.OnToolResult,
 		hooks:          cfg.Hooks,
 		contextMessage: cfg.ContextMessage,
+		temperature:    cfg.Temperature,
+		topP:           cfg.TopP,
+		stopSequences:  cfg.StopSequences,
+		spiller:        cfg.Spiller,
 	}
 }
 
@@ -90,6 +179,13 @@ func (l *Loop) History() *History {
 	return l.history
 }
 
+// SetTools replaces the tool definitions sent from the next request on.
+func (l *Loop) SetTools(defs []api.ToolDefinition) {
+	l.toolsMu.Lock()
+	defer l.toolsMu.Unlock()
+	l.tools = defs
+}
+
 // SetHandler replaces the stream handler. This allows the TUI to inject
 // its own handler after the loop is created.
 func (l *Loop) SetHandler(h api.StreamHandler) {
@@ -147,18 +243,33 @@ func (l *Loop) GetPermissionContext() *config.ToolPermissionContext {
 // SendMessage sends a user message and runs the agentic loop until the
 // assistant produces a final text response (stop_reason = "end_turn").
 func (l *Loop) SendMessage(ctx context.Context, userMessage string) error {
-	// Phase 7: UserPromptSubmit hook.
+	// Phase 7: UserPromptSubmit hook. A hook that fails or blocks
+	// rejects the message.
+	var hookContext string
 	if l.hooks != nil {
 		result, err := l.hooks.RunUserPromptSubmit(ctx, userMessage)
 		if err != nil {
-			return fmt.Errorf("UserPromptSubmit hook: %w", err)
+			return &PromptBlockedError{Prompt: userMessage, Reason: err.Error()}
 		}
 		if result.Block {
-			return nil // hook rejected the message
+			return &PromptBlockedError{Prompt: userMessage, Reason: result.Reason}
 		}
 		userMessage = result.Message // hook may modify the message
+		hookContext = result.Context
+	}
+	blocks := []api.ContentBlock{{Type: api.ContentTypeText, Text: userMessage}}
+	if hookContext != "" {
+		blocks = append(blocks, api.ContentBlock{Type: api.ContentTypeText, Text: "<user-prompt-submit-hook>\n" + hookContext + "\n</user-prompt-submit-hook>"})
+	}
+	if reminders := l.takeReminders(); reminders != "" {
+		blocks = append(blocks, api.ContentBlock{Type: api.ContentTypeText, Text: reminders})
 	}
-	l.history.AddUserMessage(userMessage)
+	if len(blocks) > 1 {
+		l.history.AddUserBlocks(blocks)
+	} else {
+		l.history.AddUserMessage(userMessage)
+	}
+	l.notifyMessage()
 	return l.run(ctx)
 }
 
@@ -167,12 +278,29 @@ func (l *Loop) Compact(ctx context.Context) error {
 	if l.compactor == nil {
 		return fmt.Errorf("compaction not configured")
 	}
-	return l.compactor.Compact(ctx, l.history)
+	return l.compact(ctx, "manual")
+}
+
+// compact compacts the history, firing the PreCompact hooks before and
+// the SessionStart hooks, with source "compact", after. trigger is
+// "manual" or "auto".
+func (l *Loop) compact(ctx context.Context, trigger string) error {
+	if l.hooks != nil {
+		_ = l.hooks.RunPreCompact(ctx, trigger)
+	}
+	if err := l.compactor.Compact(ctx, l.history); err != nil {
+		return err
+	}
+	if l.hooks != nil {
+		_ = l.hooks.RunSessionStart(ctx, "compact")
+	}
+	return nil
 }
 
 // Clear resets the conversation history to empty, starting a fresh conversation.
 func (l *Loop) Clear() {
 	l.history.SetMessages(nil)
+	l.history.SetTurns(nil)
 }
 
 // SetOnTurnComplete replaces the turn-complete callback. This is used by
@@ -181,8 +309,80 @@ func (l *Loop) SetOnTurnComplete(fn func(history *History)) {
 	l.onTurnComplete = fn
 }
 
+// SetOnMessage and SetOnToolResult replace the callbacks that persist a
+// turn as it happens, for the same reason as SetOnTurnComplete.
+func (l *Loop) SetOnMessage(fn func(history *History)) {
+	l.onMessage = fn
+}
+
+func (l *Loop) SetOnToolResult(fn func(result api.ContentBlock)) {
+	l.onToolResult = fn
+}
+
+// Steer queues a user message for the running loop, which sees it with
+// the results of its current tool calls or, if it was about to finish, as
+// a new message it must answer. It returns false if the loop isn't running.
+func (l *Loop) Steer(text string) bool {
+	l.steerMu.Lock()
+	defer l.steerMu.Unlock()
+	if !l.running {
+		return false
+	}
+	l.steered = append(l.steered, text)
+	return true
+}
+
+// takeSteered returns the queued steering messages. If there are none and
+// done is set, the loop is marked stopped under the same lock, so a
+// message can't arrive after the last check.
+func (l *Loop) takeSteered(done bool) []string {
+	l.steerMu.Lock()
+	defer l.steerMu.Unlock()
+	msgs := l.steered
+	l.steered = nil
+	if len(msgs) == 0 && done {
+		l.running = false
+	}
+	return msgs
+}
+
+// Remind queues a note for the model, such as an MCP resource changing,
+// wrapped in <system-reminder> tags. It goes with the next tool results or
+// user message, whichever comes first. It may be called from any
+// goroutine.
+func (l *Loop) Remind(text string) {
+	l.remindMu.Lock()
+	defer l.remindMu.Unlock()
+	l.reminders = append(l.reminders, "<system-reminder>\n"+text+"\n</system-reminder>")
+}
+
+// takeReminders returns the queued reminders as one text, or "" if there
+// are none.
+func (l *Loop) takeReminders() string {
+	l.remindMu.Lock()
+	defer l.remindMu.Unlock()
+	text := strings.Join(l.reminders, "\n")
+	l.reminders = nil
+	return text
+}
+
+// steerText formats steering messages for the model.
+func steerText(msgs []string) string {
+	return "The user sent a new message while you were working:\n\n" + strings.Join(msgs, "\n\n")
+}
+
 func (l *Loop) run(ctx context.Context) error {
+	l.steerMu.Lock()
+	l.running = true
+	l.steerMu.Unlock()
+	defer func() {
+		l.steerMu.Lock()
+		l.running, l.steered = false, nil
+		l.steerMu.Unlock()
+	}()
+
 	turnCount := 0
+	stopHookActive := false // a Stop hook has already kept the loop going
 	for {
 		msgs := l.history.Messages()
 
@@ -195,7 +395,9 @@ func (l *Loop) run(ctx context.Context) error {
 		}
 
 		system := l.system
+		l.toolsMu.Lock()
 		tools := l.tools
+		l.toolsMu.Unlock()
 
 		// Apply prompt caching if enabled for the current model.
 		// This adds cache_control breakpoints to system blocks, tool
@@ -211,6 +413,9 @@ func (l *Loop) run(ctx context.Context) error {
 			Messages: msgs,
 			System:   system,
 			Tools:    tools,
+			Temp:     l.temperature,
+			TopP:     l.topP,
+			StopSeqs: l.stopSequences,
 		}
 
 		// Apply fast mode: add speed:"fast" when enabled on an eligible model.
@@ -223,6 +428,7 @@ func (l *Loop) run(ctx context.Context) error {
 			req.Thinking = l.thinking
 		}
 
+		started := time.Now()
 		resp, err := l.client.CreateMessageStream(ctx, req, l.handler)
 		if err != nil {
 			return fmt.Errorf("API call: %w", err)
@@ -232,12 +438,23 @@ func (l *Loop) run(ctx context.Context) error {
 			return fmt.Errorf("no response received")
 		}
 
+		turn := TurnMetadata{
+			StartedAt:  started,
+			DurationMs: time.Since(started).Milliseconds(),
+			Model:      resp.Model,
+			StopReason: resp.StopReason,
+			Usage:      resp.Usage,
+		}
+		if turn.Model == "" {
+			turn.Model = l.client.Model()
+		}
+
 		// Add assistant response to history.
 		l.history.AddAssistantResponse(resp.Content)
 
 		// Check for auto-compaction after each API response.
 		if l.compactor != nil && l.compactor.ShouldCompact(resp.Usage) {
-			if err := l.compactor.Compact(ctx, l.history); err != nil {
+			if err := l.compact(ctx, "auto"); err != nil {
 				// Log but don't fail the loop.
 				log.Printf("Warning: compaction failed: %v", err)
 			}
@@ -245,66 +462,62 @@ func (l *Loop) run(ctx context.Context) error {
 
 		// Check if we need to execute tools.
 		if resp.StopReason != api.StopReasonToolUse {
-			// Phase 7: Stop hook.
+			if steered := l.takeSteered(true); len(steered) > 0 {
+				// The user redirected the loop as it finished; answer them.
+				l.history.AddTurn(turn)
+				l.notifyTurnComplete()
+				l.history.AddUserMessage(steerText(steered))
+				l.notifyMessage()
+				continue
+			}
+			// Phase 7: Stop hook. One that blocks keeps the model going,
+			// with its reason as the next message.
 			if l.hooks != nil {
-				_ = l.hooks.RunStop(ctx)
+				out, _ := l.hooks.RunStop(ctx, stopHookActive)
+				l.notifyHookMessage("Stop", out.Message)
+				if out.Decision == HookBlock {
+					stopHookActive = true
+					l.history.AddTurn(turn)
+					l.notifyTurnComplete()
+					l.history.AddUserMessage("Stop hook feedback:\n" + out.Reason)
+					l.notifyMessage()
+					continue
+				}
 			}
 			// No tool calls - conversation turn is done.
+			l.history.AddTurn(turn)
 			l.notifyTurnComplete()
 			return nil
 		}
 
 		// Execute tool calls and collect results.
-		var toolResults []api.ContentBlock
+		var calls []api.ContentBlock
 		for _, block := range resp.Content {
-			if block.Type != api.ContentTypeToolUse {
-				continue
-			}
-
-			if l.toolExec == nil || !l.toolExec.HasTool(block.Name) {
-				result := MakeToolResult(block.ID,
-					fmt.Sprintf("Tool %q is not available.", block.Name), true)
-				toolResults = append(toolResults, result)
-				continue
-			}
-
-			// Phase 7: PreToolUse hook.
-			if l.hooks != nil {
-				if err := l.hooks.RunPreToolUse(ctx, block.Name, block.Input); err != nil {
-					result := MakeToolResult(block.ID,
-						fmt.Sprintf("Hook blocked tool execution: %v", err), true)
-					toolResults = append(toolResults, result)
-					continue
-				}
-			}
-
-			output, execErr := l.toolExec.Execute(ctx, block.Name, block.Input)
-
-			// Phase 7: PostToolUse hook.
-			if l.hooks != nil {
-				_ = l.hooks.RunPostToolUse(ctx, block.Name, block.Input, output, execErr != nil)
-			}
-
-			if execErr != nil {
-				// If tool returned output along with an error, use the output.
-				msg := output
-				if msg == "" {
-					msg = fmt.Sprintf("Error executing tool: %v", execErr)
-				}
-				result := MakeToolResult(block.ID, msg, true)
-				toolResults = append(toolResults, result)
-			} else {
-				result := MakeToolResult(block.ID, output, false)
-				toolResults = append(toolResults, result)
+			if block.Type == api.ContentTypeToolUse {
+				calls = append(calls, block)
+				turn.Tools = append(turn.Tools, block.Name)
 			}
 		}
+		// Persist the tool calls before running them, which can take
+		// minutes; their results are reported one by one as they finish.
+		l.notifyMessage()
+		toolsStarted := time.Now()
+		toolResults := l.executeTools(ctx, calls)
 
 		if len(toolResults) == 0 {
 			// Stop reason was tool_use but no tool blocks found - shouldn't happen.
 			return fmt.Errorf("stop_reason was tool_use but no tool_use blocks found")
 		}
 
+		if steered := l.takeSteered(false); len(steered) > 0 {
+			toolResults = append(toolResults, api.ContentBlock{Type: api.ContentTypeText, Text: steerText(steered)})
+		}
+		if reminders := l.takeReminders(); reminders != "" {
+			toolResults = append(toolResults, api.ContentBlock{Type: api.ContentTypeText, Text: reminders})
+		}
 		l.history.AddToolResults(toolResults)
+		turn.ToolDurationMs = time.Since(toolsStarted).Milliseconds()
+		l.history.AddTurn(turn)
 		l.notifyTurnComplete()
 
 		// Enforce max turns limit.
@@ -316,12 +529,176 @@ func (l *Loop) run(ctx context.Context) error {
 	}
 }
 
+// executeTools runs one turn's tool calls and returns their results in
+// call order. Consecutive calls to a tool the executor allows to run in
+// parallel run concurrently, up to its limit; everything else runs one at
+// a time.
+func (l *Loop) executeTools(ctx context.Context, calls []api.ContentBlock) []api.ContentBlock {
+	results := make([]api.ContentBlock, len(calls))
+	for i := 0; i < len(calls); {
+		limit := 1
+		if pe, ok := l.toolExec.(ParallelToolExecutor); ok {
+			limit = pe.MaxParallel(calls[i].Name)
+		}
+		j := i + 1
+		for limit > 1 && j < len(calls) && calls[j].Name == calls[i].Name {
+			j++
+		}
+		if j-i == 1 {
+			results[i] = l.executeTool(ctx, calls[i])
+			l.notifyToolResult(results[i])
+			i = j
+			continue
+		}
+
+		var wg sync.WaitGroup
+		slots := make(chan struct{}, limit)
+		for k := i; k < j; k++ {
+			wg.Add(1)
+			slots <- struct{}{}
+			go func() {
+				defer func() { <-slots; wg.Done() }()
+				results[k] = l.executeTool(ctx, calls[k])
+				l.notifyToolResult(results[k])
+			}()
+		}
+		wg.Wait()
+		i = j
+	}
+	return results
+}
+
+// executeTool runs a single tool call, with its hooks, and returns the
+// tool_result block.
+func (l *Loop) executeTool(ctx context.Context, block api.ContentBlock) api.ContentBlock {
+	if l.toolExec == nil || !l.toolExec.HasTool(block.Name) {
+		return MakeToolResult(block.ID, fmt.Sprintf("Tool %q is not available.", block.Name), true)
+	}
+
+	// Phase 7: PreToolUse hook. It can block the call, rewrite its input,
+	// or approve it without a permission prompt.
+	input := block.Input
+	var notes []string // context from hooks, added to the result
+	// Replace any hook from a parent loop's Agent call, so its approval
+	// doesn't carry over to the sub-agent's calls.
+	toolCtx := WithPermissionHook(ctx, nil)
+	if l.hooks != nil {
+		out, err := l.hooks.RunPreToolUse(ctx, block.Name, input)
+		l.notifyHookMessage("PreToolUse", out.Message)
+		if err != nil {
+			return MakeToolResult(block.ID, fmt.Sprintf("Hook blocked tool execution: %v", err), true)
+		}
+		if out.Decision == HookBlock {
+			return MakeToolResult(block.ID, "Hook blocked tool execution: "+out.Reason, true)
+		}
+		if out.UpdatedInput != nil {
+			input = out.UpdatedInput
+		}
+		if out.AdditionalContext != "" {
+			notes = append(notes, out.AdditionalContext)
+		}
+		toolCtx = WithPermissionHook(ctx, l.permissionHook(out.Decision == HookApprove))
+	}
+
+	if h, ok := l.handler.(ToolOutputHandler); ok {
+		id, name := block.ID, block.Name
+		toolCtx = WithToolOutput(toolCtx, func(chunk string) {
+			h.OnToolOutput(id, name, chunk)
+		})
+	}
+	if h, ok := l.handler.(AgentProgressHandler); ok {
+		id := block.ID
+		toolCtx = WithAgentProgress(toolCtx, func(p AgentProgress) {
+			h.OnAgentProgress(id, p)
+		})
+	}
+	if h, ok := l.handler.(ToolProgressHandler); ok {
+		id, name := block.ID, block.Name
+		toolCtx = WithToolProgress(toolCtx, func(p ToolProgress) {
+			h.OnToolProgress(id, name, p)
+		})
+	}
+	toolCtx, attachments := WithToolAttachments(toolCtx)
+	output, execErr := l.toolExec.Execute(toolCtx, block.Name, input)
+
+	// Phase 7: PostToolUse hook. The tool has run, so a block only passes
+	// the hook's reason on to the model.
+	if l.hooks != nil {
+		out, _ := l.hooks.RunPostToolUse(ctx, block.Name, input, output, execErr != nil)
+		l.notifyHookMessage("PostToolUse", out.Message)
+		if out.Decision == HookBlock {
+			notes = append(notes, "PostToolUse hook feedback:\n"+out.Reason)
+		}
+		if out.AdditionalContext != "" {
+			notes = append(notes, out.AdditionalContext)
+		}
+	}
+	output = l.spiller.Spill(block.ID, output)
+
+	if execErr != nil {
+		// If tool returned output along with an error, use the output.
+		msg := output
+		if msg == "" {
+			msg = fmt.Sprintf("Error executing tool: %v", execErr)
+		}
+		return MakeToolResult(block.ID, withNotes(msg, notes), true)
+	}
+	output = withNotes(output, notes)
+	if blocks := attachments.Blocks(); len(blocks) > 0 {
+		return MakeToolResultBlocks(block.ID, output, blocks, false)
+	}
+	return MakeToolResult(block.ID, output, false)
+}
+
+// permissionHook returns the PermissionHook for a tool call. A call a
+// PreToolUse hook approved is allowed; otherwise the PermissionRequest
+// hooks decide, or leave it to the user. A failing hook denies the call.
+func (l *Loop) permissionHook(approved bool) PermissionHook {
+	return func(ctx context.Context, toolName string, input json.RawMessage) HookOutput {
+		if approved {
+			return HookOutput{Decision: HookApprove}
+		}
+		out, err := l.hooks.RunPermissionRequest(ctx, toolName, input)
+		l.notifyHookMessage("PermissionRequest", out.Message)
+		if err != nil {
+			return HookOutput{Decision: HookBlock, Reason: err.Error()}
+		}
+		return out
+	}
+}
+
+// withNotes appends the context hooks added to a tool result.
+func withNotes(output string, notes []string) string {
+	if len(notes) == 0 {
+		return output
+	}
+	return output + "\n\n" + strings.Join(notes, "\n\n")
+}
+
 func (l *Loop) notifyTurnComplete() {
 	if l.onTurnComplete != nil {
 		l.onTurnComplete(l.history)
 	}
 }
 
+func (l *Loop) notifyMessage() {
+	if l.onMessage != nil {
+		l.onMessage(l.history)
+	}
+}
+
+func (l *Loop) notifyHookMessage(event, message string) {
+	if h, ok := l.handler.(HookMessageHandler); ok && message != "" {
+		h.OnHookMessage(event, message)
+	}
+}
+
+func (l *Loop) notifyToolResult(result api.ContentBlock) {
+	if l.onToolResult != nil {
+		l.onToolResult(result)
+	}
+}
+
 // PrintStreamHandler is a basic StreamHandler that prints text to stdout.
 type PrintStreamHandler struct{}
 
@@ -460,6 +837,17 @@ func toolInputSummary(name string, input json.RawMessage) string {
 		if s := extractString("pattern"); s != "" {
 			return fmt.Sprintf("/%s/", s)
 		}
+	case "LS":
+		if s := extractString("path"); s != "" {
+			return s
+		}
+	case "Git":
+		if s := extractString("operation"); s != "" {
+			if a := extractString("action"); a != "" {
+				return s + " " + a
+			}
+			return s
+		}
 	case "Agent":
 		if s := extractString("description"); s != "" {
 			return s
@@ -468,7 +856,7 @@ func toolInputSummary(name string, input json.RawMessage) string {
 		return "updating task list"
 	case "AskUserQuestion":
 		return "asking user"
-	case "WebFetch":
+	case "WebFetch", "HttpRequest", "DownloadFile":
 		if s := extractString("url"); s != "" {
 			return s
 		}
@@ -476,7 +864,38 @@ func toolInputSummary(name string, input json.RawMessage) string {
 		if s := extractString("query"); s != "" {
 			return fmt.Sprintf("searching: %s", s)
 		}
-	case "NotebookEdit":
+	case "RunServer":
+		if s := extractString("command"); s != "" {
+			return s
+		}
+	case "Container":
+		if s := extractString("operation"); s != "" {
+			target := extractString("container")
+			if target == "" {
+				target = extractString("service")
+			}
+			if target != "" {
+				return s + " " + target
+			}
+			return s
+		}
+	case "SQL":
+		if s := extractString("database"); s != "" {
+			return s
+		}
+	case "CodeRun":
+		if s := extractString("language"); s != "" {
+			return s
+		}
+	case "SlashCommand":
+		if s := extractString("command"); s != "" {
+			return s
+		}
+	case "Skill":
+		if s := extractString("skill"); s != "" {
+			return s
+		}
+	case "NotebookEdit", "NotebookRead":
 		if s := extractString("notebook_path"); s != "" {
 			return s
 		}
@@ -494,6 +913,10 @@ func toolInputSummary(name string, input json.RawMessage) string {
 		}
 	case "TaskStop":
 		return "stopping task"
+	case "TaskSteer":
+		if s := extractString("task_id"); s != "" {
+			return fmt.Sprintf("messaging %s", s)
+		}
 	}
 	return ""
 }
@@ -0,0 +1,148 @@
+package conversation
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/anthropics/claude-code-go/internal/config"
+)
+
+// DefaultPromptPresetFile is the per-project system prompt preset, relative
+// to the project root.
+const DefaultPromptPresetFile = ".claude/system-prompt.md"
+
+// PromptPreset customizes the built-in system prompt for a project.
+//
+// The preset file is Markdown split on top-level "# " headings. A section
+// whose heading matches a built-in section (e.g. "# Tone and style")
+// replaces it; a matching heading with an empty body removes it. Sections
+// with other headings, and any text before the first heading, are added to
+// the project block.
+type PromptPreset struct {
+	Path      string
+	Overrides map[string]string // lowercased heading → replacement text ("" removes)
+	Extra     []string          // additional sections, in file order
+}
+
+// LoadPromptPreset reads the project's prompt preset. settings.systemPromptFile
+// takes precedence over .claude/system-prompt.md; relative paths are resolved
+// against cwd. It returns nil (and no error) when no preset exists.
+func LoadPromptPreset(cwd string, settings *config.Settings) (*PromptPreset, error) {
+	path := filepath.Join(cwd, DefaultPromptPresetFile)
+	explicit := settings != nil && settings.SystemPromptFile != ""
+	if explicit {
+		path = settings.SystemPromptFile
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(cwd, path)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) && !explicit {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	p := ParsePromptPreset(string(data))
+	p.Path = path
+	return p, nil
+}
+
+// ParsePromptPreset parses preset Markdown into overrides and extra sections.
+func ParsePromptPreset(text string) *PromptPreset {
+	p := &PromptPreset{Overrides: make(map[string]string)}
+	known := builtinSectionHeadings()
+
+	var heading string
+	var body []string
+	flush := func() {
+		content := strings.TrimSpace(strings.Join(body, "\n"))
+		switch {
+		case heading == "":
+			if content != "" {
+				p.Extra = append(p.Extra, content)
+			}
+		case known[strings.ToLower(heading)]:
+			if content == "" {
+				p.Overrides[strings.ToLower(heading)] = ""
+			} else {
+				p.Overrides[strings.ToLower(heading)] = "# " + heading + "\n" + content
+			}
+		default:
+			p.Extra = append(p.Extra, strings.TrimSpace("# "+heading+"\n"+content))
+		}
+		body = nil
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(line, "# ") {
+			flush()
+			heading = strings.TrimSpace(strings.TrimPrefix(line, "# "))
+			continue
+		}
+		body = append(body, line)
+	}
+	flush()
+	return p
+}
+
+// ApplyPromptPreset installs a preset into the section registries, so
+// subsequent BuildSystemPrompt calls reflect it.
+func ApplyPromptPreset(p *PromptPreset) {
+	if p == nil {
+		return
+	}
+	if len(p.Overrides) > 0 {
+		for i, s := range coreSections {
+			coreSections[i] = overrideSection(s, p.Overrides)
+		}
+		for i, s := range projectSections {
+			projectSections[i] = overrideSection(s, p.Overrides)
+		}
+	}
+	for _, text := range p.Extra {
+		RegisterProjectSection(func(_ *PromptContext) string { return text })
+	}
+}
+
+// overrideSection wraps a section so that its output is replaced when its
+// heading has an override.
+func overrideSection(s PromptSection, overrides map[string]string) PromptSection {
+	return func(ctx *PromptContext) string {
+		text := s(ctx)
+		if replacement, ok := overrides[sectionHeading(text)]; ok {
+			return replacement
+		}
+		return text
+	}
+}
+
+// sectionHeading returns the lowercased top-level heading a section's text
+// starts with, or "" if it has none.
+func sectionHeading(text string) string {
+	if !strings.HasPrefix(text, "# ") {
+		return ""
+	}
+	line, _, _ := strings.Cut(text[2:], "\n")
+	return strings.ToLower(strings.TrimSpace(line))
+}
+
+// builtinSectionHeadings returns the headings of all registered sections,
+// rendered with a representative context.
+func builtinSectionHeadings() map[string]bool {
+	ctx := &PromptContext{
+		CWD:          os.TempDir(),
+		Settings:     &config.Settings{Permissions: []config.PermissionRule{{Tool: "Bash", Action: "allow"}}},
+		SkillContent: "-",
+	}
+	headings := make(map[string]bool)
+	for _, s := range append(append([]PromptSection{}, coreSections...), projectSections...) {
+		if h := sectionHeading(s(ctx)); h != "" {
+			headings[h] = true
+		}
+	}
+	return headings
+}
@@ -0,0 +1,103 @@
+package conversation
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/anthropics/claude-code-go/internal/config"
+)
+
+// saveSections restores the section registries after a test.
+func saveSections(t *testing.T) {
+	t.Helper()
+	core := append([]PromptSection{}, coreSections...)
+	project := append([]PromptSection{}, projectSections...)
+	t.Cleanup(func() {
+		coreSections = core
+		projectSections = project
+	})
+}
+
+func TestParsePromptPreset(t *testing.T) {
+	p := ParsePromptPreset(`Always answer in British English.
+
+# Tone and style
+Be terse.
+
+# Executing actions with care
+
+# Team conventions
+Use table-driven tests.
+`)
+	if got := p.Overrides["tone and style"]; got != "# Tone and style\nBe terse." {
+		t.Errorf("tone override = %q", got)
+	}
+	if got, ok := p.Overrides["executing actions with care"]; !ok || got != "" {
+		t.Errorf("empty section should remove the built-in section, got %q, %v", got, ok)
+	}
+	if len(p.Extra) != 2 {
+		t.Fatalf("extra sections = %d, want 2", len(p.Extra))
+	}
+	if p.Extra[0] != "Always answer in British English." {
+		t.Errorf("extra[0] = %q", p.Extra[0])
+	}
+	if p.Extra[1] != "# Team conventions\nUse table-driven tests." {
+		t.Errorf("extra[1] = %q", p.Extra[1])
+	}
+}
+
+func TestApplyPromptPreset(t *testing.T) {
+	saveSections(t)
+
+	ApplyPromptPreset(ParsePromptPreset("# Tone and style\nBe terse.\n\n# Executing actions with care\n\n# Team conventions\nUse tabs.\n"))
+
+	blocks := BuildSystemPrompt(testCtx())
+	all := ""
+	for _, b := range blocks {
+		all += b.Text + "\n"
+	}
+	if !strings.Contains(all, "# Tone and style\nBe terse.") {
+		t.Error("tone section should be replaced")
+	}
+	if strings.Contains(all, "Only use emojis") {
+		t.Error("original tone section text should be gone")
+	}
+	if strings.Contains(all, "# Executing actions with care") {
+		t.Error("empty override should remove the section")
+	}
+	if !strings.Contains(all, "# Team conventions\nUse tabs.") {
+		t.Error("unknown heading should be added as a project section")
+	}
+	if !strings.Contains(all, "# Doing tasks") {
+		t.Error("sections without overrides should be untouched")
+	}
+}
+
+func TestLoadPromptPreset(t *testing.T) {
+	cwd := t.TempDir()
+
+	p, err := LoadPromptPreset(cwd, &config.Settings{})
+	if err != nil || p != nil {
+		t.Fatalf("missing default preset: got %v, %v; want nil, nil", p, err)
+	}
+
+	os.MkdirAll(filepath.Join(cwd, ".claude"), 0755)
+	os.WriteFile(filepath.Join(cwd, ".claude", "system-prompt.md"), []byte("Default preset."), 0644)
+	os.WriteFile(filepath.Join(cwd, "custom.md"), []byte("Custom preset."), 0644)
+
+	p, err = LoadPromptPreset(cwd, &config.Settings{})
+	if err != nil || p == nil || p.Extra[0] != "Default preset." {
+		t.Fatalf("default preset not loaded: %+v, %v", p, err)
+	}
+
+	p, err = LoadPromptPreset(cwd, &config.Settings{SystemPromptFile: "custom.md"})
+	if err != nil || p == nil || p.Extra[0] != "Custom preset." {
+		t.Fatalf("systemPromptFile not honored: %+v, %v", p, err)
+	}
+
+	if _, err := LoadPromptPreset(cwd, &config.Settings{SystemPromptFile: "missing.md"}); err == nil {
+		t.Error("explicit missing systemPromptFile should be an error")
+	}
+}
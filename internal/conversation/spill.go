@@ -0,0 +1,108 @@
+package conversation
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// DefaultToolResultLimit is the size in bytes above which a tool
+	// result is spilled to a file.
+	DefaultToolResultLimit = 100_000
+
+	// spillPreviewBytes is how much of a spilled result the model sees.
+	spillPreviewBytes = 10_000
+)
+
+// unsafeIDChars matches characters not allowed in spill file names.
+var unsafeIDChars = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// ResultSpiller keeps oversized tool results out of the context. Results
+// over the limit are written in full to <dir>/<tool-use-id>.txt and
+// replaced by a preview plus the file's path, which the model can page
+// through with FileRead or search with Grep.
+type ResultSpiller struct {
+	dir   string
+	limit int
+}
+
+// NewResultSpiller creates a spiller writing to dir. A limit of 0 uses
+// DefaultToolResultLimit; a negative limit disables spilling and
+// NewResultSpiller returns nil. With an empty dir, oversized results are
+// cut to the preview without being saved, for content that mustn't be
+// written to disk in the clear.
+func NewResultSpiller(dir string, limit int) *ResultSpiller {
+	if limit < 0 {
+		return nil
+	}
+	if limit == 0 {
+		limit = DefaultToolResultLimit
+	}
+	return &ResultSpiller{dir: dir, limit: limit}
+}
+
+// Dir returns the directory spill files are written to.
+func (s *ResultSpiller) Dir() string { return s.dir }
+
+// Spill returns output unchanged if it is within the limit, and otherwise
+// writes it to a file and returns a truncated preview naming the file.
+// A nil spiller never spills.
+func (s *ResultSpiller) Spill(toolUseID, output string) string {
+	if s == nil || len(output) <= s.limit {
+		return output
+	}
+	preview := previewPrefix(output, min(spillPreviewBytes, s.limit))
+	if s.dir == "" {
+		return fmt.Sprintf("%s\n\n... (output truncated: showing %d of %d bytes; the full output was not saved. Narrow the command or query to see the rest.)",
+			preview, len(preview), len(output))
+	}
+
+	path, err := s.write(toolUseID, output)
+	if err != nil {
+		return fmt.Sprintf("%s\n\n... (output truncated: showing %d of %d bytes; the full output could not be saved: %v)",
+			preview, len(preview), len(output), err)
+	}
+	return fmt.Sprintf("%s\n\n... (output truncated: showing %d of %d bytes. The full output was saved to %s; read it with FileRead using offset and limit, or search it with Grep.)",
+		preview, len(preview), len(output), path)
+}
+
+func (s *ResultSpiller) write(toolUseID, output string) (string, error) {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return "", err
+	}
+	// Keep spill files out of version control.
+	ignore := filepath.Join(s.dir, ".gitignore")
+	if _, err := os.Stat(ignore); os.IsNotExist(err) {
+		_ = os.WriteFile(ignore, []byte("*\n"), 0o644)
+	}
+	name := unsafeIDChars.ReplaceAllString(toolUseID, "_")
+	if name == "" {
+		name = "result"
+	}
+	path := filepath.Join(s.dir, name+".txt")
+	if err := os.WriteFile(path, []byte(output), 0o600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// previewPrefix returns at most n bytes from the start of s, cut at the
+// last line break if there is one in the second half, and never inside a
+// UTF-8 sequence.
+func previewPrefix(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	cut := n
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	if nl := strings.LastIndexByte(s[:cut], '\n'); nl >= n/2 {
+		cut = nl
+	}
+	return s[:cut]
+}
@@ -0,0 +1,62 @@
+package conversation
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResultSpiller(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "tool-output")
+	s := NewResultSpiller(dir, 100)
+
+	if got := s.Spill("toolu_1", "short"); got != "short" {
+		t.Errorf("small result changed: %q", got)
+	}
+
+	output := strings.Repeat("line of output\n", 20)
+	got := s.Spill("toolu_2/..", output)
+	path := filepath.Join(dir, "toolu_2___.txt")
+	if !strings.HasPrefix(got, "line of output\n") || !strings.Contains(got, "saved to "+path) {
+		t.Errorf("unexpected preview:\n%s", got)
+	}
+	if strings.Count(got, "line of output") > 7 {
+		t.Errorf("preview longer than limit:\n%s", got)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != output {
+		t.Errorf("spill file = %q, %v; want full output", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".gitignore")); err != nil {
+		t.Errorf("expected .gitignore in spill dir: %v", err)
+	}
+}
+
+func TestResultSpillerNoDir(t *testing.T) {
+	s := NewResultSpiller("", 100)
+	output := strings.Repeat("y", 500)
+	got := s.Spill("toolu_1", output)
+	if !strings.HasPrefix(got, strings.Repeat("y", 100)) || len(got) > 300 || !strings.Contains(got, "not saved") {
+		t.Errorf("Spill without a dir = %q, want a preview saying the output wasn't saved", got)
+	}
+}
+
+func TestResultSpillerDisabled(t *testing.T) {
+	s := NewResultSpiller(t.TempDir(), -1)
+	if s != nil {
+		t.Fatal("negative limit should disable spilling")
+	}
+	output := strings.Repeat("x", DefaultToolResultLimit+1)
+	if got := s.Spill("toolu_1", output); got != output {
+		t.Error("nil spiller should return output unchanged")
+	}
+}
+
+func TestPreviewPrefix(t *testing.T) {
+	if got := previewPrefix("héllo", 2); got != "h" {
+		t.Errorf("cut inside a rune: %q", got)
+	}
+	if got := previewPrefix("abc\ndefgh", 6); got != "abc" {
+		t.Errorf("expected cut at line break, got %q", got)
+	}
+}
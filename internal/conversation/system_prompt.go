@@ -180,6 +180,8 @@ func sectionUsingTools(_ *PromptContext) string {
 		"To edit files use Edit instead of sed or awk",
 		"To create files use Write instead of cat with heredoc or echo redirection",
 		"To search for files use Glob instead of find or ls",
+		"To list a directory use LS instead of ls",
+		"To check status, view diffs and logs, stage, and commit, use Git instead of running git in Bash",
 		"To search the content of files, use Grep instead of grep or rg",
 		"Reserve using the Bash exclusively for system commands and terminal operations that require shell execution. If you are unsure and there is a relevant dedicated tool, default to using the dedicated tool and only fallback on using the Bash tool for these if it is absolutely necessary.",
 	}
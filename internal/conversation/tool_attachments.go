@@ -0,0 +1,45 @@
+package conversation
+
+import (
+	"context"
+	"sync"
+
+	"github.com/anthropics/claude-code-go/internal/api"
+)
+
+// ToolAttachments collects content blocks a tool adds to its result.
+type ToolAttachments struct {
+	mu     sync.Mutex
+	blocks []api.ContentBlock
+}
+
+type toolAttachmentsKey struct{}
+
+// WithToolAttachments returns a context in which tools can attach content
+// blocks to their result with AttachToolResultBlock.
+func WithToolAttachments(ctx context.Context) (context.Context, *ToolAttachments) {
+	a := &ToolAttachments{}
+	return context.WithValue(ctx, toolAttachmentsKey{}, a), a
+}
+
+// AttachToolResultBlock adds a content block (e.g. an image) to the result
+// of the tool call running in ctx, after the tool's text output. It reports
+// false if the caller can't carry extra blocks, in which case the tool
+// should describe the content in text instead.
+func AttachToolResultBlock(ctx context.Context, block api.ContentBlock) bool {
+	a, ok := ctx.Value(toolAttachmentsKey{}).(*ToolAttachments)
+	if !ok {
+		return false
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.blocks = append(a.blocks, block)
+	return true
+}
+
+// Blocks returns the blocks attached so far.
+func (a *ToolAttachments) Blocks() []api.ContentBlock {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.blocks
+}
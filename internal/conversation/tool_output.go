@@ -0,0 +1,95 @@
+package conversation
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// ToolOutputHandler is implemented by stream handlers that can display tool
+// output while the tool is still running (e.g. the TUI's live region).
+// Chunks arrive in the order the tool produced them; a chunk may end
+// mid-line.
+type ToolOutputHandler interface {
+	OnToolOutput(toolUseID, toolName, chunk string)
+}
+
+type toolOutputKey struct{}
+
+// WithToolOutput returns a context carrying a sink for incremental tool
+// output. Tools that produce output over time (such as Bash) look it up
+// with ToolOutput.
+func WithToolOutput(ctx context.Context, sink func(chunk string)) context.Context {
+	return context.WithValue(ctx, toolOutputKey{}, sink)
+}
+
+// ToolOutput returns the incremental output sink in ctx, or nil if the
+// caller isn't interested in partial output.
+func ToolOutput(ctx context.Context) func(chunk string) {
+	sink, _ := ctx.Value(toolOutputKey{}).(func(string))
+	return sink
+}
+
+// AgentProgress is a snapshot of what a running sub-agent is doing.
+type AgentProgress struct {
+	AgentID     string
+	Description string          // the Agent call's short task description
+	Turns       int             // API round-trips started so far
+	Tool        string          // tool the sub-agent is calling, or ""
+	ToolInput   json.RawMessage // the tool's input, once complete
+	Text        string          // tail of the sub-agent's latest text
+	Done        bool            // the sub-agent has finished
+
+	// Set on the Done report: what the run consumed.
+	ToolUses   int
+	Tokens     int
+	CostUSD    float64
+	DurationMs int64
+}
+
+// AgentProgressHandler is implemented by stream handlers that can show
+// sub-agent activity under the running Agent tool call.
+type AgentProgressHandler interface {
+	OnAgentProgress(toolUseID string, p AgentProgress)
+}
+
+type agentProgressKey struct{}
+
+// WithAgentProgress returns a context carrying a sink for sub-agent
+// progress. The Agent tool looks it up with AgentProgressSink.
+func WithAgentProgress(ctx context.Context, sink func(AgentProgress)) context.Context {
+	return context.WithValue(ctx, agentProgressKey{}, sink)
+}
+
+// AgentProgressSink returns the sub-agent progress sink in ctx, or nil.
+func AgentProgressSink(ctx context.Context) func(AgentProgress) {
+	sink, _ := ctx.Value(agentProgressKey{}).(func(AgentProgress))
+	return sink
+}
+
+// ToolProgress is a progress report from a long-running tool, such as an
+// MCP server's notifications/progress.
+type ToolProgress struct {
+	Progress float64
+	Total    float64 // 0 if unknown
+	Message  string
+}
+
+// ToolProgressHandler is implemented by stream handlers that can show the
+// progress of a running tool (e.g. next to the TUI's spinner).
+type ToolProgressHandler interface {
+	OnToolProgress(toolUseID, toolName string, p ToolProgress)
+}
+
+type toolProgressKey struct{}
+
+// WithToolProgress returns a context carrying a sink for tool progress.
+// Tools that report progress look it up with ToolProgressSink.
+func WithToolProgress(ctx context.Context, sink func(ToolProgress)) context.Context {
+	return context.WithValue(ctx, toolProgressKey{}, sink)
+}
+
+// ToolProgressSink returns the tool progress sink in ctx, or nil.
+func ToolProgressSink(ctx context.Context) func(ToolProgress) {
+	sink, _ := ctx.Value(toolProgressKey{}).(func(ToolProgress))
+	return sink
+}
@@ -0,0 +1,68 @@
+package conversation
+
+import (
+	"time"
+
+	"github.com/anthropics/claude-code-go/internal/api"
+)
+
+// TurnMetadata describes a single assistant turn (one API round-trip plus
+// the tool calls it requested).
+type TurnMetadata struct {
+	StartedAt      time.Time `json:"started_at"`
+	DurationMs     int64     `json:"duration_ms"`                // API request, including streaming
+	ToolDurationMs int64     `json:"tool_duration_ms,omitempty"` // time spent executing tools
+	Model          string    `json:"model"`
+	StopReason     string    `json:"stop_reason"`
+	Usage          api.Usage `json:"usage"`
+	Tools          []string  `json:"tools,omitempty"` // tool names, in call order
+}
+
+// TurnStats aggregates a list of turns.
+type TurnStats struct {
+	Turns         int
+	TotalDuration time.Duration
+	ToolDuration  time.Duration
+	InputTokens   int
+	OutputTokens  int
+	CacheRead     int
+	CacheWrite    int
+	ToolCalls     int
+	ToolCounts    map[string]int
+	ModelCounts   map[string]int
+	StopReasons   map[string]int
+}
+
+// SummarizeTurns aggregates turn metadata for reporting (/stats, resume
+// summaries).
+func SummarizeTurns(turns []TurnMetadata) TurnStats {
+	st := TurnStats{
+		ToolCounts:  make(map[string]int),
+		ModelCounts: make(map[string]int),
+		StopReasons: make(map[string]int),
+	}
+	for _, t := range turns {
+		st.Turns++
+		st.TotalDuration += time.Duration(t.DurationMs+t.ToolDurationMs) * time.Millisecond
+		st.ToolDuration += time.Duration(t.ToolDurationMs) * time.Millisecond
+		st.InputTokens += t.Usage.InputTokens
+		st.OutputTokens += t.Usage.OutputTokens
+		if t.Usage.CacheReadInputTokens != nil {
+			st.CacheRead += *t.Usage.CacheReadInputTokens
+		}
+		if t.Usage.CacheCreationInputTokens != nil {
+			st.CacheWrite += *t.Usage.CacheCreationInputTokens
+		}
+		for _, name := range t.Tools {
+			st.ToolCalls++
+			st.ToolCounts[name]++
+		}
+		if t.Model != "" {
+			st.ModelCounts[t.Model]++
+		}
+		if t.StopReason != "" {
+			st.StopReasons[t.StopReason]++
+		}
+	}
+	return st
+}
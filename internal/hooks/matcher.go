@@ -0,0 +1,278 @@
+package hooks
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"sort"
+)
+
+// HookList is the hooks for one event. In settings it is a list of
+// hooks, or, in the official CLI's format, a list of matcher groups:
+//
+//	[{"matcher": "Edit|Write", "hooks": [{"type": "command", "command": "gofmt -w ."}]}]
+//
+// Groups are flattened, with the group's matcher and input conditions
+// copied to each of its hooks. Both forms can be mixed in one list.
+type HookList []HookDef
+
+// hookGroup is a matcher group in the official format.
+type hookGroup struct {
+	Matcher string            `json:"matcher,omitempty"`
+	Input   map[string]string `json:"input,omitempty"`
+	Hooks   []HookDef         `json:"hooks"`
+}
+
+// UnmarshalJSON reads either form of hook list.
+func (l *HookList) UnmarshalJSON(data []byte) error {
+	var entries []json.RawMessage
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+	list := HookList{}
+	for _, entry := range entries {
+		var probe struct {
+			Hooks json.RawMessage `json:"hooks"`
+		}
+		if err := json.Unmarshal(entry, &probe); err != nil {
+			return err
+		}
+		if probe.Hooks == nil {
+			var def HookDef
+			if err := json.Unmarshal(entry, &def); err != nil {
+				return err
+			}
+			list = append(list, def)
+			continue
+		}
+		var group hookGroup
+		if err := json.Unmarshal(entry, &group); err != nil {
+			return err
+		}
+		for _, def := range group.Hooks {
+			if def.Matcher == "" {
+				def.Matcher = group.Matcher
+			}
+			if def.Input == nil {
+				def.Input = group.Input
+			}
+			list = append(list, def)
+		}
+	}
+	*l = list
+	return nil
+}
+
+// MarshalJSON writes the list in the official format: hooks with a
+// matcher or input conditions go in matcher groups, one for each run of
+// hooks that share them, and the others stay plain.
+func (l HookList) MarshalJSON() ([]byte, error) {
+	entries := []any{}
+	var group *hookGroup
+	for _, def := range l {
+		if def.Matcher == "" && len(def.Input) == 0 {
+			entries = append(entries, def)
+			group = nil
+			continue
+		}
+		if group == nil || group.Matcher != def.Matcher || !maps.Equal(group.Input, def.Input) {
+			group = &hookGroup{Matcher: def.Matcher, Input: def.Input}
+			entries = append(entries, group)
+		}
+		def.Matcher, def.Input = "", nil
+		group.Hooks = append(group.Hooks, def)
+	}
+	return json.Marshal(entries)
+}
+
+// matchAll reports whether a matcher matches every target.
+func matchAll(matcher string) bool {
+	return matcher == "" || matcher == "*"
+}
+
+// anchored returns the regular expression for a hook's matcher, which
+// must match the whole target, so "Edit" doesn't run on NotebookEdit; use
+// "Edit|MultiEdit" or ".*Edit" for more.
+func anchored(matcher string) string {
+	return "^(?:" + matcher + ")$"
+}
+
+// ValidateMatcher checks that a hook matcher is a valid regular
+// expression.
+func ValidateMatcher(matcher string) error {
+	if matchAll(matcher) {
+		return nil
+	}
+	_, err := regexp.Compile(anchored(matcher))
+	return err
+}
+
+// Validate checks the hooks' matchers and input conditions, which would
+// otherwise keep a hook from ever running without saying why.
+func (c HookConfig) Validate() error {
+	var errs []error
+	for event, list := range c.byEvent() {
+		for _, def := range list {
+			if def.Type == "mcp" && (def.Server == "" || def.Tool == "") {
+				errs = append(errs, fmt.Errorf("%s mcp hook needs a server and a tool", event))
+			}
+			if err := ValidateMatcher(def.Matcher); err != nil {
+				errs = append(errs, fmt.Errorf("%s hook matcher %q: %w", event, def.Matcher, err))
+			}
+			for _, field := range sortedKeys(def.Input) {
+				if _, err := regexp.Compile(def.Input[field]); err != nil {
+					errs = append(errs, fmt.Errorf("%s hook input pattern for %s: %w", event, field, err))
+				}
+			}
+		}
+	}
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+	return errors.Join(errs...)
+}
+
+// enabled returns the config without its disabled hooks.
+func (c HookConfig) enabled() HookConfig {
+	for _, list := range []*HookList{
+		&c.PreToolUse, &c.PostToolUse, &c.UserPromptSubmit, &c.Notification,
+		&c.SessionStart, &c.SessionEnd, &c.PermissionRequest, &c.Stop,
+		&c.SubagentStart, &c.SubagentStop, &c.PreCompact,
+	} {
+		*list = slices.DeleteFunc(slices.Clone(*list), func(def HookDef) bool { return def.Disabled })
+	}
+	return c
+}
+
+// list returns the event's hooks, or nil for an unknown event.
+func (c *HookConfig) list(event string) *HookList {
+	switch event {
+	case EventPreToolUse:
+		return &c.PreToolUse
+	case EventPostToolUse:
+		return &c.PostToolUse
+	case EventUserPromptSubmit:
+		return &c.UserPromptSubmit
+	case EventNotification:
+		return &c.Notification
+	case EventSessionStart:
+		return &c.SessionStart
+	case EventSessionEnd:
+		return &c.SessionEnd
+	case EventPermissionRequest:
+		return &c.PermissionRequest
+	case EventStop:
+		return &c.Stop
+	case EventSubagentStart:
+		return &c.SubagentStart
+	case EventSubagentStop:
+		return &c.SubagentStop
+	case EventPreCompact:
+		return &c.PreCompact
+	}
+	return nil
+}
+
+// byEvent returns the hooks for each event that has any, by event name.
+func (c HookConfig) byEvent() map[string]HookList {
+	all := map[string]HookList{
+		EventPreToolUse:        c.PreToolUse,
+		EventPostToolUse:       c.PostToolUse,
+		EventUserPromptSubmit:  c.UserPromptSubmit,
+		EventNotification:      c.Notification,
+		EventSessionStart:      c.SessionStart,
+		EventSessionEnd:        c.SessionEnd,
+		EventPermissionRequest: c.PermissionRequest,
+		EventStop:              c.Stop,
+		EventSubagentStart:     c.SubagentStart,
+		EventSubagentStop:      c.SubagentStop,
+		EventPreCompact:        c.PreCompact,
+	}
+	for event, list := range all {
+		if len(list) == 0 {
+			delete(all, event)
+		}
+	}
+	return all
+}
+
+// matching returns the hooks in list that apply: those whose matcher
+// matches target, the value the event is matched on, and whose input
+// conditions all match fields of input, the tool input, or nil for events
+// without one. A hook with an invalid pattern never applies; Validate
+// reports it.
+func (r *Runner) matching(list HookList, target string, input json.RawMessage) []HookDef {
+	var fields map[string]any
+	var matched []HookDef
+	for _, def := range list {
+		if !matchAll(def.Matcher) {
+			re := r.pattern(anchored(def.Matcher))
+			if re == nil || !re.MatchString(target) {
+				continue
+			}
+		}
+		if len(def.Input) > 0 && input != nil {
+			if fields == nil {
+				fields = map[string]any{}
+				json.Unmarshal(input, &fields)
+			}
+			if !r.inputMatches(def.Input, fields) {
+				continue
+			}
+		}
+		matched = append(matched, def)
+	}
+	return matched
+}
+
+// inputMatches reports whether every pattern matches somewhere in its
+// field of the tool input. A field that isn't a string is matched as
+// JSON; a missing field doesn't match.
+func (r *Runner) inputMatches(patterns map[string]string, fields map[string]any) bool {
+	for field, pattern := range patterns {
+		v, ok := fields[field]
+		if !ok {
+			return false
+		}
+		s, ok := v.(string)
+		if !ok {
+			data, _ := json.Marshal(v)
+			s = string(data)
+		}
+		re := r.pattern(pattern)
+		if re == nil || !re.MatchString(s) {
+			return false
+		}
+	}
+	return true
+}
+
+// pattern returns a compiled regular expression, caching it, or nil if
+// it doesn't compile.
+func (r *Runner) pattern(expr string) *regexp.Regexp {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if re, ok := r.patterns[expr]; ok {
+		return re
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		re = nil
+	}
+	if r.patterns == nil {
+		r.patterns = make(map[string]*regexp.Regexp)
+	}
+	r.patterns[expr] = re
+	return re
+}
+
+// sortedKeys returns a map's keys in order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,168 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHookListFormats(t *testing.T) {
+	var config HookConfig
+	err := json.Unmarshal([]byte(`{
+		"PreToolUse": [
+			{"type": "command", "command": "audit"},
+			{"matcher": "Edit|Write", "input": {"file_path": "\\.go$"}, "hooks": [
+				{"type": "command", "command": "gofmt -w"},
+				{"type": "command", "command": "lint", "matcher": "Write"}
+			]}
+		]
+	}`), &config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := config.PreToolUse
+	if len(got) != 3 {
+		t.Fatalf("PreToolUse = %+v, want 3 hooks", got)
+	}
+	if got[0].Command != "audit" || got[0].Matcher != "" {
+		t.Errorf("plain hook = %+v", got[0])
+	}
+	if got[1].Command != "gofmt -w" || got[1].Matcher != "Edit|Write" || got[1].Input["file_path"] != `\.go$` {
+		t.Errorf("grouped hook = %+v, want the group's matcher and input", got[1])
+	}
+	if got[2].Matcher != "Write" {
+		t.Errorf("grouped hook with its own matcher = %+v", got[2])
+	}
+}
+
+func TestMatching(t *testing.T) {
+	r := NewRunner(HookConfig{})
+	list := HookList{
+		{Command: "all"},
+		{Command: "star", Matcher: "*"},
+		{Command: "edits", Matcher: "Edit|Write"},
+		{Command: "go edits", Matcher: "Edit|Write", Input: map[string]string{"file_path": `\.go$`}},
+		{Command: "force push", Matcher: "Bash", Input: map[string]string{"command": `git push.*--force`}},
+		{Command: "long timeout", Input: map[string]string{"timeout": `^[0-9]{6,}$`}},
+		{Command: "broken", Matcher: "Edit("},
+	}
+	names := func(defs []HookDef) string {
+		var s []string
+		for _, d := range defs {
+			s = append(s, d.Command)
+		}
+		return strings.Join(s, ", ")
+	}
+	for _, tt := range []struct {
+		target string
+		input  string
+		want   string
+	}{
+		{"Edit", `{"file_path":"/p/main.go"}`, "all, star, edits, go edits"},
+		{"Write", `{"file_path":"/p/README.md"}`, "all, star, edits"},
+		{"NotebookEdit", `{"file_path":"/p/a.go"}`, "all, star"},
+		{"Bash", `{"command":"git push origin main --force"}`, "all, star, force push"},
+		{"Bash", `{"command":"ls","timeout":600000}`, "all, star, long timeout"},
+	} {
+		if got := names(r.matching(list, tt.target, json.RawMessage(tt.input))); got != tt.want {
+			t.Errorf("%s %s: matched %q, want %q", tt.target, tt.input, got, tt.want)
+		}
+	}
+
+	// Events without a tool input ignore input conditions.
+	if got := names(r.matching(list[5:6], "startup", nil)); got != "long timeout" {
+		t.Errorf("matched %q without input", got)
+	}
+}
+
+func TestMatcherSkipsHooks(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "ran")
+	r := NewRunner(HookConfig{
+		PostToolUse:  HookList{{Type: "command", Command: "echo $TOOL_NAME >> " + out, Matcher: "Edit|Write"}},
+		SessionStart: HookList{{Type: "command", Command: "echo $HOOK_SOURCE >> " + out, Matcher: "resume"}},
+	})
+	ctx := context.Background()
+	for _, tool := range []string{"Bash", "Edit", "Read", "Write"} {
+		if _, err := r.RunPostToolUse(ctx, tool, json.RawMessage(`{}`), "", false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, source := range []string{"startup", "resume"} {
+		if err := r.RunSessionStart(ctx, source); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, _ := os.ReadFile(out); string(got) != "Edit\nWrite\nresume\n" {
+		t.Errorf("hooks ran for %q", got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	config := HookConfig{
+		PreToolUse: HookList{{Matcher: "Edit|Write"}, {Matcher: "Edit("}},
+		Stop:       HookList{{Input: map[string]string{"command": "[a-"}}},
+	}
+	err := config.Validate()
+	if err == nil {
+		t.Fatal("Validate = nil, want errors")
+	}
+	for _, want := range []string{`PreToolUse hook matcher "Edit("`, "Stop hook input pattern for command"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate = %v, want it to mention %s", err, want)
+		}
+	}
+	if err := (HookConfig{PreToolUse: HookList{{Matcher: "*"}, {Matcher: "mcp__.*"}}}).Validate(); err != nil {
+		t.Errorf("Validate of good matchers = %v", err)
+	}
+}
+
+func TestHookListMarshal(t *testing.T) {
+	list := HookList{
+		{Type: "command", Command: "audit"},
+		{Type: "command", Command: "gofmt -w", Matcher: "Edit|Write"},
+		{Type: "command", Command: "lint", Matcher: "Edit|Write", Disabled: true},
+		{Type: "command", Command: "check", Matcher: "Bash", Input: map[string]string{"command": "rm"}},
+	}
+	data, err := json.Marshal(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"type":"command","command":"audit"},` +
+		`{"matcher":"Edit|Write","hooks":[{"type":"command","command":"gofmt -w"},{"type":"command","command":"lint","disabled":true}]},` +
+		`{"matcher":"Bash","input":{"command":"rm"},"hooks":[{"type":"command","command":"check"}]}]`
+	if string(data) != want {
+		t.Errorf("Marshal = %s\nwant %s", data, want)
+	}
+
+	var back HookList
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(back, list) {
+		t.Errorf("round trip = %+v\nwant %+v", back, list)
+	}
+}
+
+func TestDisabledHooks(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "ran")
+	r := NewRunner(HookConfig{Stop: HookList{
+		{Type: "command", Command: "echo on >> " + out},
+		{Type: "command", Command: "echo off >> " + out, Disabled: true},
+	}})
+	ctx := context.Background()
+	r.RunStop(ctx, false)
+
+	// New settings apply to the next event.
+	r.SetConfig(HookConfig{Stop: HookList{
+		{Type: "command", Command: "echo on >> " + out, Disabled: true},
+		{Type: "command", Command: "echo off >> " + out},
+	}})
+	r.RunStop(ctx, false)
+	if got, _ := os.ReadFile(out); string(got) != "on\noff\n" {
+		t.Errorf("hooks ran %q", got)
+	}
+}
@@ -0,0 +1,139 @@
+package hooks
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/anthropics/claude-code-go/internal/config"
+)
+
+// Source is the hooks set in one settings file.
+type Source struct {
+	Scope  string // "user", "project", "local", or "managed"
+	Config HookConfig
+}
+
+// Conflict is a hook set differently in two settings files: the one in
+// Scope applies, over the one in Overridden.
+type Conflict struct {
+	Event      string
+	Hook       HookDef // as Scope sets it
+	Scope      string
+	Overridden string
+}
+
+func (c Conflict) String() string {
+	verb := "overridden"
+	if c.Hook.Disabled {
+		verb = "disabled"
+	}
+	return fmt.Sprintf("%s hook %s in %s settings is %s by %s settings", c.Event, c.Hook.label(), c.Overridden, verb, c.Scope)
+}
+
+// Load returns the hooks of cwd's settings files merged (see Merge), and
+// the conflicts between them. A file whose hooks can't be read is left
+// out, and reported in the error.
+func Load(cwd string) (HookConfig, []Conflict, error) {
+	sources, err := LoadSources(cwd)
+	merged, conflicts := Merge(sources)
+	return merged, conflicts, err
+}
+
+// LoadSources reads the hooks of each of cwd's settings files, from lowest
+// to highest priority, leaving out files that don't set any.
+func LoadSources(cwd string) ([]Source, error) {
+	files, err := config.SettingsFiles(cwd)
+	if err != nil {
+		return nil, err
+	}
+	var sources []Source
+	var errs []error
+	for _, f := range files {
+		raw, err := config.ReadSetting(f.Path, "hooks")
+		if err == nil && raw != nil {
+			var c HookConfig
+			if err = json.Unmarshal(raw, &c); err == nil {
+				sources = append(sources, Source{Scope: f.Scope, Config: c})
+			}
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", f.Path, err))
+		}
+	}
+	return sources, errors.Join(errs...)
+}
+
+// Merge combines the hooks of settings files, given from lowest to highest
+// priority: user, project, local, then managed. Every file's hooks run,
+// in that order. A hook set in more than one file (see SameHook) runs
+// once, in its first place, as the highest-priority file sets it: so a
+// project or local file can turn off a user hook by repeating it with
+// "disabled": true, or change its timeout, and nothing can change a
+// managed hook. Each override that changes the hook is a Conflict.
+func Merge(sources []Source) (HookConfig, []Conflict) {
+	var merged HookConfig
+	var conflicts []Conflict
+	for _, event := range Events {
+		var list HookList
+		var scopes []string // the scope list[i] came from
+		for _, src := range sources {
+			for _, def := range src.Config.byEvent()[event] {
+				i := slices.IndexFunc(list, func(d HookDef) bool { return SameHook(d, def) })
+				if i >= 0 && scopes[i] == src.Scope {
+					i = -1 // a file may run the same hook twice
+				}
+				if i < 0 {
+					list = append(list, def)
+					scopes = append(scopes, src.Scope)
+					continue
+				}
+				if !reflect.DeepEqual(list[i], def) {
+					conflicts = append(conflicts, Conflict{Event: event, Hook: def, Scope: src.Scope, Overridden: scopes[i]})
+				}
+				list[i], scopes[i] = def, src.Scope
+			}
+		}
+		*merged.list(event) = list
+	}
+	return merged, conflicts
+}
+
+// SameHook reports whether a and b are the same hook of an event, perhaps
+// set differently: the same type, command, prompt, or MCP tool, matcher,
+// and input conditions. Other settings, such as the timeout or disabled,
+// may differ.
+func SameHook(a, b HookDef) bool {
+	sameMatcher := a.Matcher == b.Matcher || matchAll(a.Matcher) && matchAll(b.Matcher)
+	return a.Type == b.Type && a.Command == b.Command && a.Prompt == b.Prompt &&
+		a.Server == b.Server && a.Tool == b.Tool && sameMatcher &&
+		maps.Equal(a.Input, b.Input)
+}
+
+// label names the hook in messages.
+func (d HookDef) label() string {
+	var s string
+	switch d.Type {
+	case "prompt":
+		s = fmt.Sprintf("prompt %q", d.Prompt)
+	case "mcp":
+		s = fmt.Sprintf("mcp %s/%s", d.Server, d.Tool)
+	default:
+		s = fmt.Sprintf("%q", d.Command)
+	}
+	if d.Matcher != "" {
+		s += fmt.Sprintf(" (matcher %s)", d.Matcher)
+	}
+	if len(d.Input) > 0 {
+		var conds []string
+		for _, field := range sortedKeys(d.Input) {
+			conds = append(conds, field+" ~ "+d.Input[field])
+		}
+		s += " (" + strings.Join(conds, ", ") + ")"
+	}
+	return s
+}
@@ -0,0 +1,87 @@
+package hooks
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	sources := []Source{
+		{Scope: "user", Config: HookConfig{
+			PreToolUse: HookList{
+				{Type: "command", Command: "audit"},
+				{Type: "command", Command: "lint", Matcher: "Edit"},
+			},
+			Stop: HookList{{Type: "command", Command: "notify"}},
+		}},
+		{Scope: "project", Config: HookConfig{
+			PreToolUse: HookList{
+				{Type: "command", Command: "audit", Timeout: 5},
+				{Type: "command", Command: "lint", Matcher: "Write"}, // a different hook
+				{Type: "command", Command: "check"},
+			},
+			Stop: HookList{{Type: "command", Command: "notify"}}, // the same, so no conflict
+		}},
+		{Scope: "local", Config: HookConfig{
+			PreToolUse: HookList{{Type: "command", Command: "audit", Timeout: 5, Disabled: true}},
+		}},
+	}
+	merged, conflicts := Merge(sources)
+
+	var got []string
+	for _, def := range merged.PreToolUse {
+		s := def.Matcher + ":" + def.Command
+		if def.Disabled {
+			s += " (disabled)"
+		}
+		got = append(got, s)
+	}
+	if want := ":audit (disabled), Edit:lint, Write:lint, :check"; strings.Join(got, ", ") != want {
+		t.Errorf("merged PreToolUse = %s, want %s", strings.Join(got, ", "), want)
+	}
+	if len(merged.Stop) != 1 {
+		t.Errorf("merged Stop = %+v, want the hook once", merged.Stop)
+	}
+	if n := len(NewRunner(merged).hookConfig().PreToolUse); n != 3 {
+		t.Errorf("runner has %d PreToolUse hooks, want 3 without the disabled one", n)
+	}
+
+	var msgs []string
+	for _, c := range conflicts {
+		msgs = append(msgs, c.String())
+	}
+	want := []string{
+		`PreToolUse hook "audit" in user settings is overridden by project settings`,
+		`PreToolUse hook "audit" in project settings is disabled by local settings`,
+	}
+	if strings.Join(msgs, "\n") != strings.Join(want, "\n") {
+		t.Errorf("conflicts:\n%s\nwant:\n%s", strings.Join(msgs, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestLoad(t *testing.T) {
+	home, cwd := t.TempDir(), t.TempDir()
+	t.Setenv("HOME", home)
+	write := func(path, data string) {
+		os.MkdirAll(filepath.Dir(path), 0o755)
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(home, ".claude", "settings.json"), `{"hooks": {"Stop": [{"type": "command", "command": "user-stop"}]}}`)
+	write(filepath.Join(cwd, ".claude", "settings.json"), `{"hooks": {"Stop": [{"type": "command", "command": "project-stop"}]}}`)
+	write(filepath.Join(cwd, ".claude", "settings.local.json"), `{"hooks": {"Stop": "not a list"}}`)
+
+	merged, conflicts, err := Load(cwd)
+	if err == nil || !strings.Contains(err.Error(), "settings.local.json") {
+		t.Errorf("Load error = %v, want the local file's", err)
+	}
+	if len(merged.Stop) != 2 || merged.Stop[0].Command != "user-stop" || merged.Stop[1].Command != "project-stop" {
+		t.Errorf("merged Stop = %+v, want the user's hook, then the project's", merged.Stop)
+	}
+	if len(conflicts) != 0 {
+		t.Errorf("conflicts = %v", conflicts)
+	}
+}
@@ -0,0 +1,125 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/anthropics/claude-code-go/internal/conversation"
+)
+
+// Output is the JSON a command hook may print on stdout instead of plain
+// text, in the official CLI's format. Hooks written for it that answer in
+// hookSpecificOutput, as PreToolUse hooks there do, work too.
+type Output struct {
+	Decision          string          `json:"decision,omitempty"` // "approve" or "block"
+	Reason            string          `json:"reason,omitempty"`
+	UpdatedInput      json.RawMessage `json:"updatedInput,omitempty"`
+	AdditionalContext string          `json:"additionalContext,omitempty"`
+	SuppressOutput    bool            `json:"suppressOutput,omitempty"` // don't show the reason to the user
+
+	HookSpecificOutput *struct {
+		PermissionDecision       string          `json:"permissionDecision,omitempty"` // "allow", "deny", or "ask"
+		PermissionDecisionReason string          `json:"permissionDecisionReason,omitempty"`
+		UpdatedInput             json.RawMessage `json:"updatedInput,omitempty"`
+		AdditionalContext        string          `json:"additionalContext,omitempty"`
+	} `json:"hookSpecificOutput,omitempty"`
+}
+
+// parseOutput reads a hook's stdout as JSON output. It returns nil for
+// plain text, and an error for JSON output that makes no sense, such as
+// an unknown decision, so a broken policy hook doesn't silently allow
+// everything.
+func parseOutput(stdout string) (*Output, error) {
+	trimmed := strings.TrimSpace(stdout)
+	if !strings.HasPrefix(trimmed, "{") {
+		return nil, nil
+	}
+	var out Output
+	if json.Unmarshal([]byte(trimmed), &out) != nil {
+		return nil, nil // text that happens to start with a brace
+	}
+
+	if spec := out.HookSpecificOutput; spec != nil {
+		switch spec.PermissionDecision {
+		case "allow":
+			out.Decision = conversation.HookApprove
+		case "deny":
+			out.Decision = conversation.HookBlock
+		case "ask", "":
+		default:
+			return nil, fmt.Errorf("hook output: unknown permissionDecision %q", spec.PermissionDecision)
+		}
+		if spec.PermissionDecisionReason != "" {
+			out.Reason = spec.PermissionDecisionReason
+		}
+		if spec.UpdatedInput != nil {
+			out.UpdatedInput = spec.UpdatedInput
+		}
+		if spec.AdditionalContext != "" {
+			out.AdditionalContext = spec.AdditionalContext
+		}
+		out.HookSpecificOutput = nil
+	}
+
+	switch out.Decision {
+	case conversation.HookApprove, conversation.HookBlock, "":
+	default:
+		return nil, fmt.Errorf("hook output: unknown decision %q (want %q or %q)", out.Decision, conversation.HookApprove, conversation.HookBlock)
+	}
+	if out.UpdatedInput != nil && !bytes.HasPrefix(bytes.TrimSpace(out.UpdatedInput), []byte("{")) {
+		return nil, fmt.Errorf("hook output: updatedInput must be a JSON object")
+	}
+	return &out, nil
+}
+
+// decide runs hooks and merges what they printed, in the hooks' order.
+// The first hook that blocks wins, and the ones after it are ignored;
+// otherwise an approval stands, the last updatedInput wins, and the
+// additional contexts are joined. Plain stdout, and the reasons hooks
+// gave, become the message for the user. An error means a hook failed,
+// and the ones after it are ignored too.
+func (r *Runner) decide(ctx context.Context, event string, defs []HookDef, env []string, stdin []byte) (conversation.HookOutput, error) {
+	var out conversation.HookOutput
+	var contexts, messages []string
+	for _, result := range r.runHooks(ctx, event, defs, env, stdin) {
+		if result.Error != nil {
+			return out, result.Error
+		}
+		if result.PromptInject != "" {
+			r.addInjection(result.PromptInject)
+			continue
+		}
+		p := result.Parsed
+		if p == nil {
+			if msg := strings.TrimSpace(result.Output); msg != "" {
+				messages = append(messages, msg)
+			}
+			continue
+		}
+		if p.Reason != "" && !p.SuppressOutput {
+			messages = append(messages, p.Reason)
+		}
+		if p.AdditionalContext != "" {
+			contexts = append(contexts, p.AdditionalContext)
+		}
+		if p.UpdatedInput != nil {
+			out.UpdatedInput = p.UpdatedInput
+		}
+		if p.Decision == conversation.HookBlock {
+			out.Decision, out.Reason = conversation.HookBlock, p.Reason
+			if out.Reason == "" {
+				out.Reason = "no reason given"
+			}
+			break
+		}
+		if p.Decision == conversation.HookApprove {
+			out.Decision, out.Reason = conversation.HookApprove, p.Reason
+		}
+	}
+	out.AdditionalContext = strings.Join(contexts, "\n")
+	out.Message = strings.Join(messages, "\n")
+	return out, nil
+}
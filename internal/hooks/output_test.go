@@ -0,0 +1,114 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/anthropics/claude-code-go/internal/conversation"
+)
+
+func TestParseOutput(t *testing.T) {
+	for _, tt := range []struct {
+		stdout   string
+		decision string
+		reason   string
+		wantErr  bool
+		plain    bool
+	}{
+		{stdout: "formatted 2 files\n", plain: true},
+		{stdout: "{not json", plain: true},
+		{stdout: `{"decision":"block","reason":"no rm"}`, decision: "block", reason: "no rm"},
+		{stdout: `{"decision":"approve"}`, decision: "approve"},
+		{stdout: `{"suppressOutput":true}`},
+		{stdout: `{"hookSpecificOutput":{"permissionDecision":"deny","permissionDecisionReason":"prod"}}`, decision: "block", reason: "prod"},
+		{stdout: `{"hookSpecificOutput":{"permissionDecision":"allow"}}`, decision: "approve"},
+		{stdout: `{"hookSpecificOutput":{"permissionDecision":"ask"}}`},
+		{stdout: `{"decision":"maybe"}`, wantErr: true},
+		{stdout: `{"hookSpecificOutput":{"permissionDecision":"yes"}}`, wantErr: true},
+		{stdout: `{"updatedInput":"rm"}`, wantErr: true},
+	} {
+		out, err := parseOutput(tt.stdout)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseOutput(%s) error = %v", tt.stdout, err)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if (out == nil) != tt.plain {
+			t.Errorf("parseOutput(%s) = %+v, plain text: %v", tt.stdout, out, tt.plain)
+			continue
+		}
+		if out != nil && (out.Decision != tt.decision || out.Reason != tt.reason) {
+			t.Errorf("parseOutput(%s) = decision %q, reason %q", tt.stdout, out.Decision, out.Reason)
+		}
+	}
+}
+
+func TestRunPreToolUseJSON(t *testing.T) {
+	echo := func(out string) HookDef {
+		return HookDef{Type: "command", Command: "echo '" + out + "'"}
+	}
+	ctx := context.Background()
+	input := json.RawMessage(`{"command":"ls"}`)
+
+	r := NewRunner(HookConfig{PreToolUse: []HookDef{
+		echo(`{"decision":"approve","reason":"read-only","additionalContext":"cwd is /p"}`),
+		echo(`{"hookSpecificOutput":{"updatedInput":{"command":"ls -a"},"additionalContext":"hidden files matter"}}`),
+		echo("checked"),
+	}})
+	out, err := r.RunPreToolUse(ctx, "Bash", input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := conversation.HookOutput{
+		Decision:          conversation.HookApprove,
+		Reason:            "read-only",
+		UpdatedInput:      json.RawMessage(`{"command":"ls -a"}`),
+		AdditionalContext: "cwd is /p\nhidden files matter",
+		Message:           "read-only\nchecked",
+	}
+	if out.Decision != want.Decision || out.Reason != want.Reason || string(out.UpdatedInput) != string(want.UpdatedInput) ||
+		out.AdditionalContext != want.AdditionalContext || out.Message != want.Message {
+		t.Errorf("output = %+v\nwant %+v", out, want)
+	}
+
+	// The first block wins, and later hooks don't run.
+	r = NewRunner(HookConfig{PreToolUse: []HookDef{
+		echo(`{"decision":"block","reason":"no network","suppressOutput":true}`),
+		{Type: "command", Command: "exit 1"},
+	}})
+	out, err = r.RunPreToolUse(ctx, "Bash", input)
+	if err != nil || out.Decision != conversation.HookBlock || out.Reason != "no network" || out.Message != "" {
+		t.Errorf("output = %+v, %v; want a suppressed block", out, err)
+	}
+
+	// Output that doesn't make sense fails the hook, which blocks.
+	r = NewRunner(HookConfig{PreToolUse: []HookDef{echo(`{"decision":"allow-ish"}`)}})
+	if _, err := r.RunPreToolUse(ctx, "Bash", input); err == nil || !strings.Contains(err.Error(), "unknown decision") {
+		t.Errorf("err = %v, want an unknown decision error", err)
+	}
+}
+
+func TestRunStopJSON(t *testing.T) {
+	// The hook keeps the model going only the first time.
+	r := NewRunner(HookConfig{Stop: []HookDef{{Type: "command", Command: `grep -q '"stop_hook_active":true' || echo '{"decision":"block","reason":"run the tests"}'`}}})
+	out, err := r.RunStop(context.Background(), false)
+	if err != nil || out.Decision != conversation.HookBlock || out.Reason != "run the tests" {
+		t.Errorf("first Stop = %+v, %v; want a block", out, err)
+	}
+	out, err = r.RunStop(context.Background(), true)
+	if err != nil || out.Decision != "" {
+		t.Errorf("second Stop = %+v, %v; want no decision", out, err)
+	}
+}
+
+func TestUserPromptSubmitIgnoresJSON(t *testing.T) {
+	r := NewRunner(HookConfig{UserPromptSubmit: []HookDef{{Type: "command", Command: `echo '{"suppressOutput":true}'`}}})
+	result, err := r.RunUserPromptSubmit(context.Background(), "hello")
+	if err != nil || result.Message != "hello" {
+		t.Errorf("result = %+v, %v; want the message unchanged", result, err)
+	}
+}
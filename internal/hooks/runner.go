@@ -5,53 +5,146 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 	"os"
 	"os/exec"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/anthropics/claude-code-go/internal/conversation"
 )
 
+// defaultTimeout is how long a hook command may run when its definition
+// doesn't set a timeout.
+const defaultTimeout = 60 * time.Second
+
 // Runner executes hooks based on a HookConfig.
 // It implements conversation.HookRunner.
 type Runner struct {
-	config             HookConfig
-	pendingInjections  []string // prompt hook content awaiting injection
+	projectDir string // the directory claude was started in
+
+	mu                sync.Mutex                // events fire from the loop and the TUI
+	config            HookConfig                // enabled hooks only
+	pendingInjections []string                  // prompt hook content awaiting injection
+	patterns          map[string]*regexp.Regexp // compiled matchers; nil for invalid ones
+	sessionID         string
+	transcriptPath    string // "" without a transcript
+	trace             func(Trace)
+	lastRun           map[string]time.Time // when oncePerSession and debounced hooks last ran, by hookKey
+	mcp               MCPToolFunc          // nil without MCP servers
+
+	async sync.WaitGroup // async hooks still running
 }
 
 // NewRunner creates a new hook runner from the given config.
 func NewRunner(config HookConfig) *Runner {
-	return &Runner{config: config}
+	dir, _ := os.Getwd()
+	return &Runner{config: config.enabled(), projectDir: dir}
+}
+
+// SetConfig replaces the hooks, for settings changed while running. Events
+// already firing finish with the old ones.
+func (r *Runner) SetConfig(config HookConfig) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.config = config.enabled()
+}
+
+// SetMCP sets the function "mcp" hooks call their tools with. Without
+// one, they fail.
+func (r *Runner) SetMCP(call MCPToolFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.mcp = call
+}
+
+// SetTrace sets a function called after each hook runs, with what it was
+// given and what it did, for debugging hooks. Hooks of an event run
+// concurrently, so it must be safe to call from several goroutines.
+func (r *Runner) SetTrace(trace func(Trace)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.trace = trace
+}
+
+// hookConfig returns the current hooks.
+func (r *Runner) hookConfig() HookConfig {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.config
+}
+
+// SetSession sets the session hooks are told they run in, as
+// session_id and transcript_path, and in the environment.
+func (r *Runner) SetSession(id, transcriptPath string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if id != r.sessionID {
+		r.lastRun = nil // a new session runs oncePerSession hooks again
+	}
+	r.sessionID, r.transcriptPath = id, transcriptPath
+}
+
+// session returns the current session's ID and transcript path.
+func (r *Runner) session() (id, transcriptPath string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.sessionID, r.transcriptPath
+}
+
+// baseEnv returns the environment variables every hook gets, under the
+// names the official CLI's hooks use.
+func (r *Runner) baseEnv() []string {
+	id, transcript := r.session()
+	return []string{
+		"CLAUDE_PROJECT_DIR=" + r.projectDir,
+		"CLAUDE_SESSION_ID=" + id,
+		"CLAUDE_TRANSCRIPT_PATH=" + transcript,
+	}
 }
 
 // RunPreToolUse fires all PreToolUse hooks. Returns an error if any hook
-// blocks the tool execution (non-zero exit code).
-func (r *Runner) RunPreToolUse(ctx context.Context, toolName string, input json.RawMessage) error {
-	if len(r.config.PreToolUse) == 0 {
-		return nil
+// blocks the tool execution (non-zero exit code). Hooks that print JSON
+// can also block the call, approve it without a permission prompt, or
+// replace its input.
+func (r *Runner) RunPreToolUse(ctx context.Context, toolName string, input json.RawMessage) (conversation.HookOutput, error) {
+	hooks := r.matching(r.hookConfig().PreToolUse, toolName, input)
+	if len(hooks) == 0 {
+		return conversation.HookOutput{}, nil
 	}
 
 	env := []string{
 		"HOOK_EVENT=PreToolUse",
 		"TOOL_NAME=" + toolName,
+		"CLAUDE_TOOL_NAME=" + toolName,
 		"TOOL_INPUT=" + string(input),
 	}
+	stdin := r.payload(EventPreToolUse, map[string]any{
+		"tool_name":  toolName,
+		"tool_input": input,
+	})
 
-	for _, hook := range r.config.PreToolUse {
-		result := r.executeHook(ctx, hook, env)
-		if result.Error != nil {
-			return fmt.Errorf("PreToolUse hook blocked: %w", result.Error)
-		}
-		// Collect prompt injections for the conversation.
-		if result.PromptInject != "" {
-			r.pendingInjections = append(r.pendingInjections, result.PromptInject)
-		}
+	out, err := r.decide(ctx, EventPreToolUse, hooks, env, stdin)
+	if err != nil {
+		return out, fmt.Errorf("PreToolUse hook blocked: %w", err)
 	}
-	return nil
+	return out, nil
+}
+
+// addInjection queues prompt hook content for PendingInjections.
+func (r *Runner) addInjection(content string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pendingInjections = append(r.pendingInjections, content)
 }
 
 // PendingInjections returns and clears any prompt content from prompt hooks.
 func (r *Runner) PendingInjections() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	if len(r.pendingInjections) == 0 {
 		return nil
 	}
@@ -61,10 +154,12 @@ func (r *Runner) PendingInjections() []string {
 }
 
 // RunPostToolUse fires all PostToolUse hooks. Errors are logged but do not
-// block execution.
-func (r *Runner) RunPostToolUse(ctx context.Context, toolName string, input json.RawMessage, output string, isError bool) error {
-	if len(r.config.PostToolUse) == 0 {
-		return nil
+// block execution. A hook that prints a "block" decision has its reason
+// passed on to the model.
+func (r *Runner) RunPostToolUse(ctx context.Context, toolName string, input json.RawMessage, output string, isError bool) (conversation.HookOutput, error) {
+	hooks := r.matching(r.hookConfig().PostToolUse, toolName, input)
+	if len(hooks) == 0 {
+		return conversation.HookOutput{}, nil
 	}
 
 	isErrStr := "false"
@@ -81,24 +176,31 @@ func (r *Runner) RunPostToolUse(ctx context.Context, toolName string, input json
 	env := []string{
 		"HOOK_EVENT=PostToolUse",
 		"TOOL_NAME=" + toolName,
+		"CLAUDE_TOOL_NAME=" + toolName,
 		"TOOL_INPUT=" + string(input),
 		"TOOL_OUTPUT=" + truncatedOutput,
 		"TOOL_IS_ERROR=" + isErrStr,
 	}
+	// Tools here return text, so tool_response carries it whole rather
+	// than the per-tool fields the official CLI sends.
+	stdin := r.payload(EventPostToolUse, map[string]any{
+		"tool_name":  toolName,
+		"tool_input": input,
+		"tool_response": map[string]any{
+			"output":   output,
+			"is_error": isError,
+		},
+	})
 
-	for _, hook := range r.config.PostToolUse {
-		result := r.executeHook(ctx, hook, env)
-		if result.Error != nil {
-			return result.Error
-		}
-	}
-	return nil
+	return r.decide(ctx, EventPostToolUse, hooks, env, stdin)
 }
 
 // RunUserPromptSubmit fires all UserPromptSubmit hooks. A hook can modify
-// or reject the user's message.
+// or reject the user's message. Hooks that print JSON can block it with a
+// reason for the user or add context for the model, as prompt hooks do.
 func (r *Runner) RunUserPromptSubmit(ctx context.Context, message string) (conversation.HookSubmitResult, error) {
-	if len(r.config.UserPromptSubmit) == 0 {
+	hooks := r.hookConfig().UserPromptSubmit
+	if len(hooks) == 0 {
 		return conversation.HookSubmitResult{Message: message}, nil
 	}
 
@@ -106,16 +208,30 @@ func (r *Runner) RunUserPromptSubmit(ctx context.Context, message string) (conve
 		"HOOK_EVENT=UserPromptSubmit",
 		"USER_MESSAGE=" + message,
 	}
+	stdin := r.payload(EventUserPromptSubmit, map[string]any{"prompt": message})
 
 	currentMsg := message
-	for _, hook := range r.config.UserPromptSubmit {
-		result := r.executeHook(ctx, hook, env)
+	var contexts []string
+	for _, result := range r.runHooks(ctx, EventUserPromptSubmit, hooks, env, stdin) {
 		if result.Error != nil {
-			return conversation.HookSubmitResult{Block: true, Message: currentMsg}, result.Error
+			return conversation.HookSubmitResult{Block: true, Reason: result.Error.Error(), Message: currentMsg}, result.Error
 		}
-		// Prompt hooks inject content.
+		// Prompt hooks add their text as context.
 		if result.PromptInject != "" {
-			r.pendingInjections = append(r.pendingInjections, result.PromptInject)
+			contexts = append(contexts, result.PromptInject)
+			continue
+		}
+		if p := result.Parsed; p != nil {
+			if p.Decision == conversation.HookBlock {
+				reason := p.Reason
+				if reason == "" {
+					reason = "no reason given"
+				}
+				return conversation.HookSubmitResult{Block: true, Reason: reason, Message: currentMsg}, nil
+			}
+			if p.AdditionalContext != "" {
+				contexts = append(contexts, p.AdditionalContext)
+			}
 			continue
 		}
 		// If the hook produced stdout, use it as the (possibly modified) message.
@@ -123,61 +239,215 @@ func (r *Runner) RunUserPromptSubmit(ctx context.Context, message string) (conve
 			currentMsg = trimmed
 		}
 	}
-	return conversation.HookSubmitResult{Message: currentMsg}, nil
+	return conversation.HookSubmitResult{Message: currentMsg, Context: strings.Join(contexts, "\n")}, nil
 }
 
-// RunSessionStart fires all SessionStart hooks.
-func (r *Runner) RunSessionStart(ctx context.Context) error {
-	if len(r.config.SessionStart) == 0 {
+// RunSessionStart fires all SessionStart hooks. source says how the
+// session began: "startup", "resume", "clear", or "compact".
+func (r *Runner) RunSessionStart(ctx context.Context, source string) error {
+	hooks := r.matching(r.hookConfig().SessionStart, source, nil)
+	if len(hooks) == 0 {
 		return nil
 	}
 
 	env := []string{
 		"HOOK_EVENT=SessionStart",
+		"HOOK_SOURCE=" + source,
 	}
+	stdin := r.payload(EventSessionStart, map[string]any{"source": source})
+	return r.runAll(ctx, EventSessionStart, hooks, env, stdin)
+}
 
-	for _, hook := range r.config.SessionStart {
-		result := r.executeHook(ctx, hook, env)
-		if result.Error != nil {
-			return result.Error
-		}
+// RunSessionEnd fires all SessionEnd hooks. reason says why the session
+// ended: "clear", "logout", "prompt_input_exit", or "other".
+func (r *Runner) RunSessionEnd(ctx context.Context, reason string) error {
+	hooks := r.matching(r.hookConfig().SessionEnd, reason, nil)
+	if len(hooks) == 0 {
+		return nil
 	}
-	return nil
+
+	env := []string{
+		"HOOK_EVENT=SessionEnd",
+		"HOOK_REASON=" + reason,
+	}
+	stdin := r.payload(EventSessionEnd, map[string]any{"reason": reason})
+	return r.runAll(ctx, EventSessionEnd, hooks, env, stdin)
 }
 
-// RunStop fires all Stop hooks.
-func (r *Runner) RunStop(ctx context.Context) error {
-	if len(r.config.Stop) == 0 {
-		return nil
+// RunStop fires all Stop hooks. A hook that prints a "block" decision
+// keeps the model going, with the reason as its next message. active is
+// true when a Stop hook has already done so in this loop, so hooks can
+// let it stop.
+func (r *Runner) RunStop(ctx context.Context, active bool) (conversation.HookOutput, error) {
+	hooks := r.hookConfig().Stop
+	if len(hooks) == 0 {
+		return conversation.HookOutput{}, nil
 	}
 
 	env := []string{
 		"HOOK_EVENT=Stop",
 	}
+	stdin := r.payload(EventStop, map[string]any{"stop_hook_active": active})
+	return r.decide(ctx, EventStop, hooks, env, stdin)
+}
 
-	for _, hook := range r.config.Stop {
-		result := r.executeHook(ctx, hook, env)
-		if result.Error != nil {
-			return result.Error
-		}
+// RunNotification fires all Notification hooks, when the user is needed:
+// a tool is waiting for permission, or the prompt has been idle.
+func (r *Runner) RunNotification(ctx context.Context, message string) error {
+	hooks := r.hookConfig().Notification
+	if len(hooks) == 0 {
+		return nil
 	}
-	return nil
+
+	env := []string{
+		"HOOK_EVENT=Notification",
+		"HOOK_MESSAGE=" + message,
+	}
+	stdin := r.payload(EventNotification, map[string]any{"message": message})
+	return r.runAll(ctx, EventNotification, hooks, env, stdin)
 }
 
-// RunPermissionRequest fires all PermissionRequest hooks.
-func (r *Runner) RunPermissionRequest(ctx context.Context, toolName string, input json.RawMessage) error {
-	if len(r.config.PermissionRequest) == 0 {
+// RunPreCompact fires all PreCompact hooks before the conversation is
+// compacted. trigger is "manual" for /compact or "auto".
+func (r *Runner) RunPreCompact(ctx context.Context, trigger string) error {
+	hooks := r.matching(r.hookConfig().PreCompact, trigger, nil)
+	if len(hooks) == 0 {
 		return nil
 	}
 
+	env := []string{
+		"HOOK_EVENT=PreCompact",
+		"HOOK_TRIGGER=" + trigger,
+	}
+	stdin := r.payload(EventPreCompact, map[string]any{
+		"trigger":             trigger,
+		"custom_instructions": "",
+	})
+	return r.runAll(ctx, EventPreCompact, hooks, env, stdin)
+}
+
+// RunPermissionRequest fires all PermissionRequest hooks, before the user
+// is asked to permit a tool call. A hook that prints a decision answers
+// for the user.
+func (r *Runner) RunPermissionRequest(ctx context.Context, toolName string, input json.RawMessage) (conversation.HookOutput, error) {
+	hooks := r.matching(r.hookConfig().PermissionRequest, toolName, input)
+	if len(hooks) == 0 {
+		return conversation.HookOutput{}, nil
+	}
+
 	env := []string{
 		"HOOK_EVENT=PermissionRequest",
 		"TOOL_NAME=" + toolName,
+		"CLAUDE_TOOL_NAME=" + toolName,
 		"TOOL_INPUT=" + string(input),
 	}
+	stdin := r.payload(EventPermissionRequest, map[string]any{
+		"tool_name":  toolName,
+		"tool_input": input,
+	})
+	return r.decide(ctx, EventPermissionRequest, hooks, env, stdin)
+}
+
+// RunSubagentStart fires all SubagentStart hooks before a sub-agent runs.
+// Returns an error if any hook blocks the sub-agent (non-zero exit code).
+func (r *Runner) RunSubagentStart(ctx context.Context, ev conversation.SubagentEvent) error {
+	hooks := r.matching(r.hookConfig().SubagentStart, ev.AgentType, nil)
+	if len(hooks) == 0 {
+		return nil
+	}
 
-	for _, hook := range r.config.PermissionRequest {
-		result := r.executeHook(ctx, hook, env)
+	// Pass a summary of the prompt; the full text can be very long.
+	prompt := ev.Prompt
+	if len(prompt) > 1000 {
+		prompt = prompt[:1000] + "...(truncated)"
+	}
+
+	env := append(subagentEnv(EventSubagentStart, ev),
+		"AGENT_PROMPT="+prompt,
+		"AGENT_BACKGROUND="+strconv.FormatBool(ev.Background),
+	)
+	fields := subagentFields(ev)
+	fields["prompt"] = ev.Prompt
+	fields["background"] = ev.Background
+	stdin := r.payload(EventSubagentStart, fields)
+
+	for _, result := range r.runHooks(ctx, EventSubagentStart, hooks, env, stdin) {
+		if result.Error != nil {
+			return fmt.Errorf("SubagentStart hook blocked: %w", result.Error)
+		}
+		if p := result.Parsed; p != nil && p.Decision == conversation.HookBlock {
+			return fmt.Errorf("SubagentStart hook blocked: %s", p.Reason)
+		}
+	}
+	return nil
+}
+
+// RunSubagentStop fires all SubagentStop hooks after a sub-agent run ends.
+func (r *Runner) RunSubagentStop(ctx context.Context, ev conversation.SubagentEvent) error {
+	hooks := r.matching(r.hookConfig().SubagentStop, ev.AgentType, nil)
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	env := append(subagentEnv(EventSubagentStop, ev),
+		"AGENT_STATUS="+ev.Status,
+		"AGENT_RESULT_CHARS="+strconv.Itoa(ev.ResultChars),
+		"AGENT_TOOL_USES="+strconv.Itoa(ev.ToolUses),
+		"AGENT_TOKENS="+strconv.Itoa(ev.Tokens),
+		"AGENT_COST_USD="+strconv.FormatFloat(ev.CostUSD, 'f', 4, 64),
+		"AGENT_DURATION_MS="+strconv.FormatInt(ev.DurationMs, 10),
+	)
+	fields := subagentFields(ev)
+	fields["stop_hook_active"] = false
+	fields["status"] = ev.Status
+	fields["result_chars"] = ev.ResultChars
+	fields["tool_uses"] = ev.ToolUses
+	fields["tokens"] = ev.Tokens
+	fields["cost_usd"] = ev.CostUSD
+	fields["duration_ms"] = ev.DurationMs
+	stdin := r.payload(EventSubagentStop, fields)
+
+	return r.runAll(ctx, EventSubagentStop, hooks, env, stdin)
+}
+
+// subagentEnv returns the environment shared by the sub-agent events.
+func subagentEnv(event string, ev conversation.SubagentEvent) []string {
+	return []string{
+		"HOOK_EVENT=" + event,
+		"AGENT_ID=" + ev.AgentID,
+		"AGENT_TYPE=" + ev.AgentType,
+		"AGENT_DESCRIPTION=" + ev.Description,
+	}
+}
+
+// subagentFields returns the stdin fields shared by the sub-agent events.
+func subagentFields(ev conversation.SubagentEvent) map[string]any {
+	return map[string]any{
+		"agent_id":    ev.AgentID,
+		"agent_type":  ev.AgentType,
+		"description": ev.Description,
+	}
+}
+
+// payload builds the JSON a hook command reads on stdin: the event's
+// fields, its name, as hook_event_name, and the fields every event has.
+func (r *Runner) payload(event string, fields map[string]any) []byte {
+	fields["hook_event_name"] = event
+	fields["session_id"], fields["transcript_path"] = r.session()
+	fields["cwd"], _ = os.Getwd()
+	data, err := json.Marshal(fields)
+	if err != nil {
+		// Only a tool input that isn't valid JSON gets here.
+		delete(fields, "tool_input")
+		data, _ = json.Marshal(fields)
+	}
+	return data
+}
+
+// runAll runs the hooks and returns the error of the first, in order,
+// that failed.
+func (r *Runner) runAll(ctx context.Context, event string, defs []HookDef, env []string, stdin []byte) error {
+	for _, result := range r.runHooks(ctx, event, defs, env, stdin) {
 		if result.Error != nil {
 			return result.Error
 		}
@@ -185,46 +455,222 @@ func (r *Runner) RunPermissionRequest(ctx context.Context, toolName string, inpu
 	return nil
 }
 
+// runHooks runs the hooks concurrently, so an event takes as long as its
+// slowest hook rather than all of them, and returns their results in the
+// hooks' order, so what the event makes of them doesn't depend on which
+// finished first. Async hooks are started in the background and have an
+// empty result.
+func (r *Runner) runHooks(ctx context.Context, event string, defs []HookDef, env []string, stdin []byte) []HookResult {
+	defs = r.due(event, defs)
+	results := make([]HookResult, len(defs))
+	if len(defs) == 1 && !defs[0].Async {
+		results[0] = r.traced(ctx, event, defs[0], env, stdin)
+		return results
+	}
+	var wg sync.WaitGroup
+	for i, hook := range defs {
+		if hook.Async {
+			r.startAsync(ctx, event, hook, env, stdin)
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = r.traced(ctx, event, hook, env, stdin)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// due returns the hooks in defs that may run now, recording the time for
+// the ones limited by OncePerSession or Debounce: those that haven't run
+// in this session, or not within their debounce window. A hook counts as
+// run when it starts, so an event that fires again while it's running
+// doesn't start it twice.
+func (r *Runner) due(event string, defs []HookDef) []HookDef {
+	now := time.Now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var due []HookDef
+	for _, def := range defs {
+		if !def.OncePerSession && def.Debounce <= 0 {
+			due = append(due, def)
+			continue
+		}
+		key := hookKey(event, def)
+		last, ran := r.lastRun[key]
+		if ran && (def.OncePerSession || now.Sub(last) < time.Duration(def.Debounce)*time.Second) {
+			continue
+		}
+		if r.lastRun == nil {
+			r.lastRun = map[string]time.Time{}
+		}
+		r.lastRun[key] = now
+		due = append(due, def)
+	}
+	return due
+}
+
+// hookKey identifies a hook of an event, for due: by everything in its
+// definition, so a hook that's changed counts as a new one.
+func hookKey(event string, def HookDef) string {
+	data, _ := json.Marshal(def)
+	return event + " " + string(data)
+}
+
+// startAsync runs an async hook in the background. It outlives the
+// event's context, which may end as soon as the event returns, so only
+// its own timeout and Wait limit it. A failure is logged, since there's
+// no one left to tell.
+func (r *Runner) startAsync(ctx context.Context, event string, hook HookDef, env []string, stdin []byte) {
+	r.async.Add(1)
+	go func() {
+		defer r.async.Done()
+		if result := r.traced(context.WithoutCancel(ctx), event, hook, env, stdin); result.Error != nil {
+			log.Printf("Warning: async hook %q failed: %v", hook.Command, result.Error)
+		}
+	}()
+}
+
+// Wait waits up to timeout for async hooks still running, such as
+// SessionEnd hooks at exit, and reports whether they all finished.
+func (r *Runner) Wait(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		r.async.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// traced runs a hook and passes the run to the trace function, if any.
+func (r *Runner) traced(ctx context.Context, event string, hook HookDef, env []string, stdin []byte) HookResult {
+	start := time.Now()
+	result := r.executeHook(ctx, hook, env, stdin)
+	r.mu.Lock()
+	trace := r.trace
+	r.mu.Unlock()
+	if trace != nil {
+		trace(Trace{Event: event, Hook: hook, Stdin: stdin, Result: result, Duration: time.Since(start)})
+	}
+	return result
+}
+
 // executeHook runs a single hook definition and returns the result.
-func (r *Runner) executeHook(ctx context.Context, hook HookDef, extraEnv []string) HookResult {
+func (r *Runner) executeHook(ctx context.Context, hook HookDef, extraEnv []string, stdin []byte) HookResult {
 	switch hook.Type {
 	case "command":
-		return r.runCommand(ctx, hook.Command, extraEnv)
+		return r.runCommand(ctx, hook, extraEnv, stdin)
 	case "prompt":
 		// Prompt hooks inject additional context into the conversation.
 		return HookResult{Output: hook.Prompt, PromptInject: hook.Prompt}
 	case "agent":
 		// Agent hooks spawn a sub-process. For now, treat as a command.
-		return r.runCommand(ctx, hook.Command, extraEnv)
+		return r.runCommand(ctx, hook, extraEnv, stdin)
+	case "mcp":
+		return r.callMCP(ctx, hook, stdin)
 	default:
 		return HookResult{Error: fmt.Errorf("unknown hook type: %s", hook.Type)}
 	}
 }
 
-// runCommand executes a shell command with the given extra environment variables.
-func (r *Runner) runCommand(ctx context.Context, command string, extraEnv []string) HookResult {
-	if command == "" {
+// hookTimeout returns how long the hook may run.
+func hookTimeout(hook HookDef) time.Duration {
+	if hook.Timeout > 0 {
+		return time.Duration(hook.Timeout) * time.Second
+	}
+	return defaultTimeout
+}
+
+// callMCP calls an "mcp" hook's tool with the event's JSON as the
+// arguments. The tool's text is read as a command's stdout would be; a
+// tool error, or a server that isn't connected, fails the hook, which
+// blocks the action for the events that can, rather than letting a
+// policy server that's down allow everything.
+func (r *Runner) callMCP(ctx context.Context, hook HookDef, stdin []byte) HookResult {
+	r.mu.Lock()
+	call := r.mcp
+	r.mu.Unlock()
+	if hook.Server == "" || hook.Tool == "" {
+		return HookResult{Error: fmt.Errorf("mcp hook needs a server and a tool")}
+	}
+	if call == nil {
+		return HookResult{Error: fmt.Errorf("mcp hook %s/%s: MCP server %q is not configured", hook.Server, hook.Tool, hook.Server)}
+	}
+
+	timeout := hookTimeout(hook)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	text, err := call(ctx, hook.Server, hook.Tool, stdin)
+	if ctx.Err() == context.DeadlineExceeded {
+		return HookResult{Output: text, Error: fmt.Errorf("hook timed out after %s: mcp %s/%s", timeout, hook.Server, hook.Tool)}
+	}
+	if err != nil {
+		return HookResult{Output: text, Error: fmt.Errorf("mcp hook %s/%s: %w", hook.Server, hook.Tool, err)}
+	}
+	parsed, err := parseOutput(text)
+	if err != nil {
+		return HookResult{Output: text, Error: err}
+	}
+	return HookResult{Output: text, Parsed: parsed}
+}
+
+// runCommand executes a hook's shell command with the given extra
+// environment variables and the event's JSON on stdin. A command still
+// running after the hook's timeout is killed, and fails.
+func (r *Runner) runCommand(ctx context.Context, hook HookDef, extraEnv []string, stdin []byte) HookResult {
+	if hook.Command == "" {
 		return HookResult{}
 	}
 
-	cmd := exec.CommandContext(ctx, "sh", "-c", command)
-	cmd.Env = append(os.Environ(), extraEnv...)
+	timeout := hookTimeout(hook)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", hook.Command)
+	cmd.Env = append(append(os.Environ(), r.baseEnv()...), extraEnv...)
+	cmd.Stdin = bytes.NewReader(stdin)
+	// Don't wait for background processes the command left holding its
+	// output once it has been killed.
+	cmd.WaitDelay = time.Second
 
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
 	err := cmd.Run()
+	exitCode := -1
+	if cmd.ProcessState != nil {
+		exitCode = cmd.ProcessState.ExitCode()
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return HookResult{
+			Output:   stdout.String(),
+			ExitCode: exitCode,
+			Error:    fmt.Errorf("hook timed out after %s: %s", timeout, hook.Command),
+		}
+	}
 	if err != nil {
 		errMsg := stderr.String()
 		if errMsg == "" {
 			errMsg = err.Error()
 		}
 		return HookResult{
-			Output: stdout.String(),
-			Error:  fmt.Errorf("%s", strings.TrimSpace(errMsg)),
+			Output:   stdout.String(),
+			ExitCode: exitCode,
+			Error:    fmt.Errorf("%s", strings.TrimSpace(errMsg)),
 		}
 	}
 
-	return HookResult{Output: stdout.String()}
+	parsed, err := parseOutput(stdout.String())
+	if err != nil {
+		return HookResult{Output: stdout.String(), ExitCode: exitCode, Error: err}
+	}
+	return HookResult{Output: stdout.String(), ExitCode: exitCode, Parsed: parsed}
 }
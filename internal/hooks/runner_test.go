@@ -3,12 +3,23 @@ package hooks
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/anthropics/claude-code-go/internal/conversation"
 )
 
 func TestRunPreToolUse_NoHooks(t *testing.T) {
 	r := NewRunner(HookConfig{})
-	err := r.RunPreToolUse(context.Background(), "Bash", json.RawMessage(`{"command":"ls"}`))
+	_, err := r.RunPreToolUse(context.Background(), "Bash", json.RawMessage(`{"command":"ls"}`))
 	if err != nil {
 		t.Fatalf("expected nil error, got %v", err)
 	}
@@ -20,7 +31,7 @@ func TestRunPreToolUse_SuccessfulCommand(t *testing.T) {
 			{Type: "command", Command: "true"},
 		},
 	})
-	err := r.RunPreToolUse(context.Background(), "Bash", json.RawMessage(`{"command":"ls"}`))
+	_, err := r.RunPreToolUse(context.Background(), "Bash", json.RawMessage(`{"command":"ls"}`))
 	if err != nil {
 		t.Fatalf("expected nil error, got %v", err)
 	}
@@ -32,7 +43,7 @@ func TestRunPreToolUse_BlockingCommand(t *testing.T) {
 			{Type: "command", Command: "false"},
 		},
 	})
-	err := r.RunPreToolUse(context.Background(), "Bash", json.RawMessage(`{"command":"rm -rf /"}`))
+	_, err := r.RunPreToolUse(context.Background(), "Bash", json.RawMessage(`{"command":"rm -rf /"}`))
 	if err == nil {
 		t.Fatal("expected error from blocking hook, got nil")
 	}
@@ -40,7 +51,7 @@ func TestRunPreToolUse_BlockingCommand(t *testing.T) {
 
 func TestRunPostToolUse_NoHooks(t *testing.T) {
 	r := NewRunner(HookConfig{})
-	err := r.RunPostToolUse(context.Background(), "Bash", json.RawMessage(`{}`), "output", false)
+	_, err := r.RunPostToolUse(context.Background(), "Bash", json.RawMessage(`{}`), "output", false)
 	if err != nil {
 		t.Fatalf("expected nil error, got %v", err)
 	}
@@ -52,7 +63,7 @@ func TestRunPostToolUse_WithCommand(t *testing.T) {
 			{Type: "command", Command: "true"},
 		},
 	})
-	err := r.RunPostToolUse(context.Background(), "Bash", json.RawMessage(`{}`), "output", false)
+	_, err := r.RunPostToolUse(context.Background(), "Bash", json.RawMessage(`{}`), "output", false)
 	if err != nil {
 		t.Fatalf("expected nil error, got %v", err)
 	}
@@ -107,7 +118,7 @@ func TestRunUserPromptSubmit_BlocksOnFailure(t *testing.T) {
 
 func TestRunSessionStart_NoHooks(t *testing.T) {
 	r := NewRunner(HookConfig{})
-	err := r.RunSessionStart(context.Background())
+	err := r.RunSessionStart(context.Background(), "startup")
 	if err != nil {
 		t.Fatalf("expected nil error, got %v", err)
 	}
@@ -119,7 +130,7 @@ func TestRunSessionStart_WithCommand(t *testing.T) {
 			{Type: "command", Command: "true"},
 		},
 	})
-	err := r.RunSessionStart(context.Background())
+	err := r.RunSessionStart(context.Background(), "startup")
 	if err != nil {
 		t.Fatalf("expected nil error, got %v", err)
 	}
@@ -127,7 +138,7 @@ func TestRunSessionStart_WithCommand(t *testing.T) {
 
 func TestRunStop_NoHooks(t *testing.T) {
 	r := NewRunner(HookConfig{})
-	err := r.RunStop(context.Background())
+	_, err := r.RunStop(context.Background(), false)
 	if err != nil {
 		t.Fatalf("expected nil error, got %v", err)
 	}
@@ -139,7 +150,7 @@ func TestRunStop_WithCommand(t *testing.T) {
 			{Type: "command", Command: "true"},
 		},
 	})
-	err := r.RunStop(context.Background())
+	_, err := r.RunStop(context.Background(), false)
 	if err != nil {
 		t.Fatalf("expected nil error, got %v", err)
 	}
@@ -147,7 +158,7 @@ func TestRunStop_WithCommand(t *testing.T) {
 
 func TestRunPermissionRequest_NoHooks(t *testing.T) {
 	r := NewRunner(HookConfig{})
-	err := r.RunPermissionRequest(context.Background(), "Bash", json.RawMessage(`{}`))
+	_, err := r.RunPermissionRequest(context.Background(), "Bash", json.RawMessage(`{}`))
 	if err != nil {
 		t.Fatalf("expected nil error, got %v", err)
 	}
@@ -159,7 +170,7 @@ func TestRunPermissionRequest_WithCommand(t *testing.T) {
 			{Type: "command", Command: "true"},
 		},
 	})
-	err := r.RunPermissionRequest(context.Background(), "Bash", json.RawMessage(`{}`))
+	_, err := r.RunPermissionRequest(context.Background(), "Bash", json.RawMessage(`{}`))
 	if err != nil {
 		t.Fatalf("expected nil error, got %v", err)
 	}
@@ -171,7 +182,7 @@ func TestPromptHook(t *testing.T) {
 			{Type: "prompt", Prompt: "Check for sensitive data"},
 		},
 	})
-	err := r.RunPreToolUse(context.Background(), "Bash", json.RawMessage(`{}`))
+	_, err := r.RunPreToolUse(context.Background(), "Bash", json.RawMessage(`{}`))
 	if err != nil {
 		t.Fatalf("expected nil error, got %v", err)
 	}
@@ -183,7 +194,7 @@ func TestUnknownHookType(t *testing.T) {
 			{Type: "unknown"},
 		},
 	})
-	err := r.RunPreToolUse(context.Background(), "Bash", json.RawMessage(`{}`))
+	_, err := r.RunPreToolUse(context.Background(), "Bash", json.RawMessage(`{}`))
 	if err == nil {
 		t.Fatal("expected error for unknown hook type, got nil")
 	}
@@ -195,7 +206,7 @@ func TestEnvironmentVariablesPassed(t *testing.T) {
 			{Type: "command", Command: "test \"$TOOL_NAME\" = \"Bash\" && test \"$HOOK_EVENT\" = \"PreToolUse\""},
 		},
 	})
-	err := r.RunPreToolUse(context.Background(), "Bash", json.RawMessage(`{"command":"ls"}`))
+	_, err := r.RunPreToolUse(context.Background(), "Bash", json.RawMessage(`{"command":"ls"}`))
 	if err != nil {
 		t.Fatalf("environment variables not set correctly: %v", err)
 	}
@@ -208,7 +219,7 @@ func TestMultipleHooks(t *testing.T) {
 			{Type: "command", Command: "true"},
 		},
 	})
-	err := r.RunPreToolUse(context.Background(), "Bash", json.RawMessage(`{}`))
+	_, err := r.RunPreToolUse(context.Background(), "Bash", json.RawMessage(`{}`))
 	if err != nil {
 		t.Fatalf("expected nil error, got %v", err)
 	}
@@ -221,8 +232,347 @@ func TestMultipleHooks_SecondFails(t *testing.T) {
 			{Type: "command", Command: "false"},
 		},
 	})
-	err := r.RunPreToolUse(context.Background(), "Bash", json.RawMessage(`{}`))
+	_, err := r.RunPreToolUse(context.Background(), "Bash", json.RawMessage(`{}`))
 	if err == nil {
 		t.Fatal("expected error from second hook, got nil")
 	}
 }
+
+func TestRunSubagentStart_Blocks(t *testing.T) {
+	r := NewRunner(HookConfig{
+		SubagentStart: []HookDef{
+			{Type: "command", Command: `test "$AGENT_TYPE" != Explore || { echo "no exploring" >&2; exit 1; }`},
+		},
+	})
+	if err := r.RunSubagentStart(context.Background(), conversation.SubagentEvent{AgentType: "Plan"}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	err := r.RunSubagentStart(context.Background(), conversation.SubagentEvent{AgentType: "Explore"})
+	if err == nil || !strings.Contains(err.Error(), "no exploring") {
+		t.Fatalf("expected the hook to block, got %v", err)
+	}
+}
+
+func TestRunSubagentStop_Env(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "env")
+	r := NewRunner(HookConfig{
+		SubagentStop: []HookDef{
+			{Type: "command", Command: `echo "$HOOK_EVENT $AGENT_ID $AGENT_TYPE $AGENT_STATUS $AGENT_RESULT_CHARS $AGENT_TOOL_USES $AGENT_TOKENS $AGENT_COST_USD" > ` + out},
+		},
+	})
+	err := r.RunSubagentStop(context.Background(), conversation.SubagentEvent{
+		AgentID: "agent-1", AgentType: "Plan", Status: "completed",
+		ResultChars: 120, ToolUses: 3, Tokens: 4500, CostUSD: 0.0123,
+	})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	got, _ := os.ReadFile(out)
+	if want := "SubagentStop agent-1 Plan completed 120 3 4500 0.0123\n"; string(got) != want {
+		t.Errorf("hook saw %q", got)
+	}
+}
+
+func TestStdinPayload(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "stdin")
+	hook := []HookDef{{Type: "command", Command: "cat > " + out}}
+	r := NewRunner(HookConfig{
+		PreToolUse: hook, PostToolUse: hook, UserPromptSubmit: hook,
+		Notification: hook, Stop: hook, SubagentStop: hook,
+		PreCompact: hook, SessionStart: hook, SessionEnd: hook,
+	})
+	r.SetSession("s1", "/t/s1.jsonl")
+	cwd, _ := os.Getwd()
+	ctx := context.Background()
+	input := json.RawMessage(`{"file_path":"/p/main.go"}`)
+	tests := []struct {
+		fire func() error
+		want map[string]any
+	}{
+		{func() error { _, err := r.RunPreToolUse(ctx, "Edit", input); return err },
+			map[string]any{"hook_event_name": "PreToolUse", "tool_name": "Edit", "tool_input": map[string]any{"file_path": "/p/main.go"}}},
+		{func() error { _, err := r.RunPostToolUse(ctx, "Edit", input, "ok", false); return err },
+			map[string]any{"hook_event_name": "PostToolUse", "tool_name": "Edit", "tool_input": map[string]any{"file_path": "/p/main.go"},
+				"tool_response": map[string]any{"output": "ok", "is_error": false}}},
+		{func() error { _, err := r.RunUserPromptSubmit(ctx, "fix it"); return err },
+			map[string]any{"hook_event_name": "UserPromptSubmit", "prompt": "fix it"}},
+		{func() error { return r.RunNotification(ctx, "Claude is waiting for your input") },
+			map[string]any{"hook_event_name": "Notification", "message": "Claude is waiting for your input"}},
+		{func() error { _, err := r.RunStop(ctx, false); return err },
+			map[string]any{"hook_event_name": "Stop", "stop_hook_active": false}},
+		{func() error { return r.RunPreCompact(ctx, "auto") },
+			map[string]any{"hook_event_name": "PreCompact", "trigger": "auto", "custom_instructions": ""}},
+		{func() error { return r.RunSessionStart(ctx, "resume") },
+			map[string]any{"hook_event_name": "SessionStart", "source": "resume"}},
+		{func() error { return r.RunSessionEnd(ctx, "clear") },
+			map[string]any{"hook_event_name": "SessionEnd", "reason": "clear"}},
+	}
+	for _, tt := range tests {
+		if err := tt.fire(); err != nil {
+			t.Fatalf("%v: %v", tt.want["hook_event_name"], err)
+		}
+		data, _ := os.ReadFile(out)
+		var got map[string]any
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("%v: stdin %q isn't JSON: %v", tt.want["hook_event_name"], data, err)
+		}
+		// Every event has these.
+		if got["session_id"] != "s1" || got["transcript_path"] != "/t/s1.jsonl" || got["cwd"] != cwd {
+			t.Errorf("%v: session fields = %v, %v, %v", tt.want["hook_event_name"], got["session_id"], got["transcript_path"], got["cwd"])
+		}
+		delete(got, "session_id")
+		delete(got, "transcript_path")
+		delete(got, "cwd")
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("stdin = %v, want %v", got, tt.want)
+		}
+	}
+
+	if err := r.RunSubagentStop(ctx, conversation.SubagentEvent{AgentID: "agent-1", Status: "completed"}); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(out)
+	var got map[string]any
+	json.Unmarshal(data, &got)
+	if got["hook_event_name"] != "SubagentStop" || got["stop_hook_active"] != false || got["agent_id"] != "agent-1" || got["status"] != "completed" {
+		t.Errorf("SubagentStop stdin = %v", got)
+	}
+}
+
+func TestHookIgnoringStdin(t *testing.T) {
+	r := NewRunner(HookConfig{
+		PostToolUse: []HookDef{{Type: "command", Command: "exit 0"}},
+	})
+	big := strings.Repeat("x", 1<<20) // more than a pipe holds
+	if _, err := r.RunPostToolUse(context.Background(), "Bash", json.RawMessage(`{}`), big, false); err != nil {
+		t.Fatalf("a hook that doesn't read stdin failed: %v", err)
+	}
+}
+
+func TestEventEnvironment(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "env")
+	r := NewRunner(HookConfig{
+		PreCompact: []HookDef{{Type: "command", Command: `echo "$HOOK_EVENT $HOOK_TRIGGER" > ` + out}},
+	})
+	if err := r.RunPreCompact(context.Background(), "manual"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(out); string(got) != "PreCompact manual\n" {
+		t.Errorf("hook saw %q", got)
+	}
+}
+
+func TestSessionEnvironment(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "env")
+	r := NewRunner(HookConfig{
+		PostToolUse: []HookDef{{Type: "command", Command: `echo "$CLAUDE_PROJECT_DIR $CLAUDE_SESSION_ID $CLAUDE_TRANSCRIPT_PATH $CLAUDE_TOOL_NAME" > ` + out}},
+	})
+	r.SetSession("s1", "/t/s1.jsonl")
+	if _, err := r.RunPostToolUse(context.Background(), "Write", json.RawMessage(`{}`), "", false); err != nil {
+		t.Fatal(err)
+	}
+	cwd, _ := os.Getwd()
+	if got, _ := os.ReadFile(out); string(got) != cwd+" s1 /t/s1.jsonl Write\n" {
+		t.Errorf("hook saw %q", got)
+	}
+
+	// A switch to another session, as /clear makes, reaches the next hook.
+	r.SetSession("s2", "")
+	r.RunPostToolUse(context.Background(), "Edit", json.RawMessage(`{}`), "", false)
+	if got, _ := os.ReadFile(out); string(got) != cwd+" s2  Edit\n" {
+		t.Errorf("hook saw %q", got)
+	}
+}
+
+func TestHooksRunInParallel(t *testing.T) {
+	r := NewRunner(HookConfig{PreToolUse: HookList{
+		{Type: "command", Command: `sleep 1; echo '{"decision":"block","reason":"first"}'`},
+		{Type: "command", Command: `echo '{"decision":"block","reason":"second"}'`},
+		{Type: "command", Command: "sleep 1"},
+	}})
+	start := time.Now()
+	out, err := r.RunPreToolUse(context.Background(), "Bash", json.RawMessage(`{}`))
+	if elapsed := time.Since(start); elapsed > 1900*time.Millisecond {
+		t.Errorf("hooks took %v, want them to run at once", elapsed)
+	}
+	// The results are taken in order, not as they finish.
+	if err != nil || out.Reason != "first" {
+		t.Errorf("output = %+v, %v; want the first hook's block", out, err)
+	}
+}
+
+func TestHookTimeout(t *testing.T) {
+	r := NewRunner(HookConfig{PostToolUse: HookList{
+		{Type: "command", Command: "sleep 30 & sleep 30", Timeout: 1},
+	}})
+	start := time.Now()
+	_, err := r.RunPostToolUse(context.Background(), "Bash", json.RawMessage(`{}`), "", false)
+	if err == nil || !strings.Contains(err.Error(), "timed out after 1s") {
+		t.Errorf("err = %v, want a timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("timed-out hook took %v", elapsed)
+	}
+}
+
+func TestAsyncHook(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "notified")
+	r := NewRunner(HookConfig{Notification: HookList{
+		{Type: "command", Command: "sleep 1; cat > " + out + "; exit 1", Async: true},
+	}})
+	ctx, cancel := context.WithCancel(context.Background())
+	start := time.Now()
+	if err := r.RunNotification(ctx, "waiting"); err != nil {
+		t.Errorf("RunNotification = %v, want the async hook's failure ignored", err)
+	}
+	cancel() // the hook outlives the event
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("RunNotification waited %v for an async hook", elapsed)
+	}
+	if !r.Wait(10 * time.Second) {
+		t.Fatal("Wait timed out")
+	}
+	if got, _ := os.ReadFile(out); !strings.Contains(string(got), `"message":"waiting"`) {
+		t.Errorf("async hook read %q", got)
+	}
+
+	r = NewRunner(HookConfig{SessionEnd: HookList{{Type: "command", Command: "sleep 2", Async: true}}})
+	r.RunSessionEnd(context.Background(), "other")
+	if r.Wait(100 * time.Millisecond) {
+		t.Error("Wait = true with a hook still running")
+	}
+}
+
+func TestSetTrace(t *testing.T) {
+	r := NewRunner(HookConfig{PreToolUse: HookList{
+		{Type: "command", Command: `echo '{"decision":"approve"}'`},
+		{Type: "command", Command: "echo nope >&2; exit 1"},
+	}})
+	var mu sync.Mutex
+	traces := map[string]Trace{}
+	r.SetTrace(func(tr Trace) {
+		mu.Lock()
+		defer mu.Unlock()
+		traces[tr.Hook.Command] = tr
+	})
+	r.RunPreToolUse(context.Background(), "Bash", json.RawMessage(`{"command":"ls"}`))
+
+	if len(traces) != 2 {
+		t.Fatalf("traced %d hooks, want 2", len(traces))
+	}
+	ok := traces[`echo '{"decision":"approve"}'`]
+	if ok.Event != EventPreToolUse || ok.Result.Parsed == nil || ok.Result.Parsed.Decision != "approve" {
+		t.Errorf("trace = %+v", ok)
+	}
+	if !strings.Contains(string(ok.Stdin), `"tool_name":"Bash"`) {
+		t.Errorf("traced stdin = %s", ok.Stdin)
+	}
+	failed := traces["echo nope >&2; exit 1"]
+	if failed.Result.Error == nil || failed.Result.Error.Error() != "nope" || failed.Result.ExitCode != 1 {
+		t.Errorf("failed hook's trace = %+v", failed)
+	}
+	for _, want := range []string{`PreToolUse hook "echo nope >&2; exit 1": exit 1 in `, `, failed: "nope"`} {
+		if !strings.Contains(failed.String(), want) {
+			t.Errorf("String = %q, want it to contain %q", failed.String(), want)
+		}
+	}
+	long := Trace{Event: EventStop, Hook: HookDef{Type: "command", Command: "cat big"}, Result: HookResult{Output: strings.Repeat("x\n", 500)}}
+	if s := long.String(); !strings.Contains(s, `output: "x\nx\n`) || !strings.HasSuffix(s, `…"`) || strings.Count(s, `x\n`) != 100 {
+		t.Errorf("String of long output = %q", s)
+	}
+}
+
+func TestMCPHook(t *testing.T) {
+	r := NewRunner(HookConfig{PreToolUse: HookList{{Type: "mcp", Server: "policy", Tool: "check", Matcher: "Bash"}}})
+	ctx := context.Background()
+	input := json.RawMessage(`{"command":"rm -rf /"}`)
+
+	// Without MCP servers, the hook fails, which blocks the tool.
+	if _, err := r.RunPreToolUse(ctx, "Bash", input); err == nil || !strings.Contains(err.Error(), "not configured") {
+		t.Errorf("without MCP: err = %v", err)
+	}
+
+	var gotArgs json.RawMessage
+	r.SetMCP(func(ctx context.Context, server, tool string, args json.RawMessage) (string, error) {
+		gotArgs = args
+		if server != "policy" || tool != "check" {
+			return "", fmt.Errorf("called %s/%s", server, tool)
+		}
+		if strings.Contains(string(args), "rm -rf") {
+			return `{"decision":"block","reason":"destructive command"}`, nil
+		}
+		return "looks fine", nil
+	})
+	out, err := r.RunPreToolUse(ctx, "Bash", input)
+	if err != nil || out.Decision != "block" || out.Reason != "destructive command" {
+		t.Errorf("blocked call: out = %+v, err = %v", out, err)
+	}
+	if !strings.Contains(string(gotArgs), `"hook_event_name":"PreToolUse"`) || !strings.Contains(string(gotArgs), `"tool_input":{"command":"rm -rf /"}`) {
+		t.Errorf("tool arguments = %s, want the event's JSON", gotArgs)
+	}
+	out, err = r.RunPreToolUse(ctx, "Bash", json.RawMessage(`{"command":"ls"}`))
+	if err != nil || out.Decision != "" || out.Message != "looks fine" {
+		t.Errorf("allowed call: out = %+v, err = %v", out, err)
+	}
+
+	r.SetMCP(func(context.Context, string, string, json.RawMessage) (string, error) {
+		return "", errors.New("MCP tool error: policy unavailable")
+	})
+	if _, err := r.RunPreToolUse(ctx, "Bash", input); err == nil || !strings.Contains(err.Error(), "mcp hook policy/check: MCP tool error: policy unavailable") {
+		t.Errorf("tool error: err = %v", err)
+	}
+
+	if err := (HookConfig{Stop: HookList{{Type: "mcp", Server: "policy"}}}).Validate(); err == nil {
+		t.Error("Validate of an mcp hook without a tool = nil")
+	}
+}
+
+func TestOncePerSessionAndDebounce(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "ran")
+	r := NewRunner(HookConfig{PostToolUse: HookList{
+		{Type: "command", Command: "echo audit >> " + out, OncePerSession: true},
+		{Type: "command", Command: "echo index >> " + out, Debounce: 30},
+		{Type: "command", Command: "echo every >> " + out, Matcher: "Edit"},
+	}})
+	r.SetSession("one", "")
+	ctx := context.Background()
+	edit := func() {
+		if _, err := r.RunPostToolUse(ctx, "Edit", json.RawMessage(`{}`), "", false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ran := func() string {
+		data, _ := os.ReadFile(out)
+		os.Remove(out)
+		names := strings.Fields(string(data)) // the hooks run in parallel
+		sort.Strings(names)
+		return strings.Join(names, " ")
+	}
+
+	edit()
+	if got := ran(); got != "audit every index" {
+		t.Errorf("first event ran %q", got)
+	}
+	edit()
+	if got := ran(); got != "every" {
+		t.Errorf("second event ran %q, want only the hook without limits", got)
+	}
+
+	// Once the debounce window has passed, the debounced hook runs again.
+	r.mu.Lock()
+	for key, last := range r.lastRun {
+		r.lastRun[key] = last.Add(-time.Minute)
+	}
+	r.mu.Unlock()
+	edit()
+	if got := ran(); got != "every index" {
+		t.Errorf("after the debounce window ran %q", got)
+	}
+
+	// A new session runs oncePerSession hooks again.
+	r.SetSession("two", "")
+	edit()
+	if got := ran(); got != "audit every index" {
+		t.Errorf("in a new session ran %q", got)
+	}
+}
@@ -0,0 +1,49 @@
+package hooks
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// traceTextLimit is how many characters of a hook's command, output, or
+// error a Trace's String keeps.
+const traceTextLimit = 200
+
+// String describes the run on one line, for debug logs: the event and
+// matcher, the hook, its exit code and duration, and the start of its
+// error and output.
+func (t Trace) String() string {
+	var b strings.Builder
+	b.WriteString(t.Event + " hook")
+	if t.Hook.Matcher != "" {
+		fmt.Fprintf(&b, " [%s]", t.Hook.Matcher)
+	}
+	switch t.Hook.Type {
+	case "prompt":
+		fmt.Fprintf(&b, " prompt %q", truncateTrace(t.Hook.Prompt))
+	case "mcp":
+		fmt.Fprintf(&b, " mcp %s/%s", t.Hook.Server, t.Hook.Tool)
+	default:
+		fmt.Fprintf(&b, " %q: exit %d", truncateTrace(t.Hook.Command), t.Result.ExitCode)
+	}
+	fmt.Fprintf(&b, " in %s", t.Duration.Round(time.Millisecond))
+	if t.Hook.Async {
+		b.WriteString(" (async)")
+	}
+	if err := t.Result.Error; err != nil {
+		fmt.Fprintf(&b, ", failed: %q", truncateTrace(err.Error()))
+	}
+	if out := strings.TrimSpace(t.Result.Output); out != "" && t.Hook.Type != "prompt" {
+		fmt.Fprintf(&b, ", output: %q", truncateTrace(out))
+	}
+	return b.String()
+}
+
+// truncateTrace shortens s to traceTextLimit characters.
+func truncateTrace(s string) string {
+	if r := []rune(s); len(r) > traceTextLimit {
+		return string(r[:traceTextLimit]) + "…"
+	}
+	return s
+}
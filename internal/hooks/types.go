@@ -1,41 +1,141 @@
 // Package hooks implements lifecycle event hooks for the Claude Code CLI.
 //
 // Hooks fire at specific points in the agentic loop (PreToolUse, PostToolUse,
-// UserPromptSubmit, SessionStart, Stop, PermissionRequest) and can run shell
-// commands, inject prompts, or spawn sub-agents.
+// UserPromptSubmit, Notification, SessionStart, SessionEnd, Stop,
+// PermissionRequest, SubagentStart, SubagentStop, PreCompact) and can run
+// shell commands, inject prompts, or spawn sub-agents. Commands get the
+// event as JSON on stdin, in the official CLI's format, and in environment
+// variables. They answer with an exit code, or with JSON on stdout that
+// can approve or block the action, rewrite a tool's input, or add context
+// for the model.
 package hooks
 
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
 // Event constants for hook lifecycle events.
 const (
 	EventPreToolUse        = "PreToolUse"
 	EventPostToolUse       = "PostToolUse"
 	EventUserPromptSubmit  = "UserPromptSubmit"
+	EventNotification      = "Notification"
 	EventSessionStart      = "SessionStart"
+	EventSessionEnd        = "SessionEnd"
 	EventPermissionRequest = "PermissionRequest"
 	EventStop              = "Stop"
+	EventSubagentStart     = "SubagentStart"
+	EventSubagentStop      = "SubagentStop"
+	EventPreCompact        = "PreCompact"
 )
 
+// Events lists the hook events in the order they're presented to users.
+var Events = []string{
+	EventPreToolUse, EventPostToolUse, EventPermissionRequest,
+	EventUserPromptSubmit, EventNotification, EventStop,
+	EventSubagentStart, EventSubagentStop, EventPreCompact,
+	EventSessionStart, EventSessionEnd,
+}
+
+// MatcherTarget returns what an event's hook matchers match, such as
+// "tool name", or "" for events that ignore matchers.
+func MatcherTarget(event string) string {
+	switch event {
+	case EventPreToolUse, EventPostToolUse, EventPermissionRequest:
+		return "tool name"
+	case EventSubagentStart, EventSubagentStop:
+		return "agent type"
+	case EventSessionStart:
+		return "source"
+	case EventSessionEnd:
+		return "reason"
+	case EventPreCompact:
+		return "trigger"
+	}
+	return ""
+}
+
 // HookConfig holds all hook definitions keyed by event type.
 // Parsed from the "hooks" field in settings.json.
 type HookConfig struct {
-	PreToolUse        []HookDef `json:"PreToolUse,omitempty"`
-	PostToolUse       []HookDef `json:"PostToolUse,omitempty"`
-	UserPromptSubmit  []HookDef `json:"UserPromptSubmit,omitempty"`
-	SessionStart      []HookDef `json:"SessionStart,omitempty"`
-	PermissionRequest []HookDef `json:"PermissionRequest,omitempty"`
-	Stop              []HookDef `json:"Stop,omitempty"`
+	PreToolUse        HookList `json:"PreToolUse,omitempty"`
+	PostToolUse       HookList `json:"PostToolUse,omitempty"`
+	UserPromptSubmit  HookList `json:"UserPromptSubmit,omitempty"`
+	Notification      HookList `json:"Notification,omitempty"`
+	SessionStart      HookList `json:"SessionStart,omitempty"`
+	SessionEnd        HookList `json:"SessionEnd,omitempty"`
+	PermissionRequest HookList `json:"PermissionRequest,omitempty"`
+	Stop              HookList `json:"Stop,omitempty"`
+	SubagentStart     HookList `json:"SubagentStart,omitempty"`
+	SubagentStop      HookList `json:"SubagentStop,omitempty"`
+	PreCompact        HookList `json:"PreCompact,omitempty"`
 }
 
 // HookDef defines a single hook action.
 type HookDef struct {
-	Type    string `json:"type"`              // "command", "prompt", "agent"
+	Type    string `json:"type"`              // "command", "prompt", "agent", "mcp"
 	Command string `json:"command,omitempty"` // shell command (type=command)
 	Prompt  string `json:"prompt,omitempty"`  // prompt text (type=prompt)
+	Timeout int    `json:"timeout,omitempty"` // seconds before the command or MCP call is stopped; 0 = 60
+
+	// Server and Tool name the MCP tool an "mcp" hook calls instead of
+	// running a command, with the event's JSON as the arguments. Its text
+	// result is read like a command's stdout, and a tool error fails the
+	// hook, so a policy server can gate tool use for a whole team.
+	Server string `json:"server,omitempty"`
+	Tool   string `json:"tool,omitempty"`
+
+	// Async runs the hook in the background: the event doesn't wait for
+	// it, and its output and failures are ignored, apart from a logged
+	// warning. For notifications and other hooks with nothing to say.
+	Async bool `json:"async,omitempty"`
+
+	// Disabled keeps the hook in settings without running it, as /hooks
+	// does to turn one off.
+	Disabled bool `json:"disabled,omitempty"`
+
+	// OncePerSession runs the hook the first time its event matches in a
+	// session, and skips it after that, for setup such as a dependency
+	// audit. Debounce skips the hook when it ran less than that many
+	// seconds ago, for work such as refreshing an index after every edit.
+	// A skipped hook counts as if it had run and said nothing, so a gate
+	// that is skipped doesn't block.
+	OncePerSession bool `json:"oncePerSession,omitempty"`
+	Debounce       int  `json:"debounce,omitempty"`
+
+	// Matcher limits the hook to targets the regular expression matches
+	// in full: the tool name for the tool events, the agent type for the
+	// sub-agent events, the source, reason, or trigger for SessionStart,
+	// SessionEnd, and PreCompact. "" or "*" matches everything. Other
+	// events ignore it.
+	Matcher string `json:"matcher,omitempty"`
+
+	// Input limits a tool event's hook to calls whose input fields match:
+	// each pattern must match somewhere in its field's value. Other events
+	// ignore it.
+	Input map[string]string `json:"input,omitempty"`
+}
+
+// MCPToolFunc calls a tool on a connected MCP server and returns its text
+// result. A result the server marks as an error is returned as an error.
+type MCPToolFunc func(ctx context.Context, server, tool string, args json.RawMessage) (string, error)
+
+// Trace is a hook run, as passed to the function set with SetTrace.
+type Trace struct {
+	Event    string
+	Hook     HookDef
+	Stdin    []byte // the event's JSON
+	Result   HookResult
+	Duration time.Duration
 }
 
 // HookResult is the outcome of a hook execution.
 type HookResult struct {
-	Output       string // stdout from the hook command
-	Error        error  // non-nil if the hook failed or blocked
-	PromptInject string // content to inject into conversation (from prompt hooks)
+	Output       string  // stdout from the hook command
+	ExitCode     int     // the command's exit status; -1 if it was killed or didn't start
+	Parsed       *Output // Output as JSON hook output, or nil for plain text
+	Error        error   // non-nil if the hook failed or blocked
+	PromptInject string  // content to inject into conversation (from prompt hooks)
 }
@@ -0,0 +1,45 @@
+package hooks
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// shellBuiltins are words a command can start with that aren't programs
+// on the PATH.
+var shellBuiltins = map[string]bool{
+	"!": true, ".": true, ":": true, "[": true, "{": true, "alias": true,
+	"case": true, "cd": true, "eval": true, "exec": true, "exit": true,
+	"export": true, "for": true, "if": true, "read": true, "return": true,
+	"set": true, "shift": true, "source": true, "test": true, "trap": true,
+	"umask": true, "unset": true, "until": true, "wait": true, "while": true,
+}
+
+// ValidateCommand checks a command hook before it's saved: that sh can
+// parse it, and that the program it starts with is on the PATH. Scripts
+// named by a path aren't checked, since they're often written after the
+// hook that runs them, nor are commands that start with an expansion.
+func ValidateCommand(command string) error {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return errors.New("command is empty")
+	}
+	if out, err := exec.Command("sh", "-n", "-c", command).CombinedOutput(); err != nil {
+		msg := strings.TrimSpace(string(out))
+		if msg == "" {
+			msg = err.Error()
+		}
+		return fmt.Errorf("sh can't parse the command: %s", msg)
+	}
+
+	program := fields[0]
+	if shellBuiltins[program] || strings.ContainsAny(program, "/$`'\"=(){}<>|;&*?[]~") {
+		return nil
+	}
+	if _, err := exec.LookPath(program); err != nil {
+		return fmt.Errorf("%s: command not found", program)
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package hooks
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateCommand(t *testing.T) {
+	for _, tt := range []struct {
+		command string
+		wantErr string
+	}{
+		{command: "echo hi | cat"},
+		{command: "if true; then exit 0; fi"},
+		{command: "$CLAUDE_PROJECT_DIR/.claude/hooks/check.sh"},
+		{command: "./scripts/not-written-yet.sh"},
+		{command: "FOO=1 env"},
+		{command: "  ", wantErr: "empty"},
+		{command: "echo 'unterminated", wantErr: "can't parse"},
+		{command: "no-such-program-here --fix", wantErr: "no-such-program-here: command not found"},
+	} {
+		err := ValidateCommand(tt.command)
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("ValidateCommand(%q) = %v", tt.command, err)
+		}
+		if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("ValidateCommand(%q) = %v, want %q", tt.command, err, tt.wantErr)
+		}
+	}
+}
@@ -3,9 +3,11 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 // Transport is the interface for sending JSON-RPC messages to an MCP server.
@@ -20,16 +22,35 @@ type Transport interface {
 	Close() error
 }
 
-// MCPClient communicates with a single MCP server over a Transport.
+// MCPClient communicates with a single MCP server over a Transport. The
+// transport can be replaced when the connection is re-established, so tool
+// wrappers holding the client keep working across reconnects.
 type MCPClient struct {
-	transport  Transport
 	serverName string
 	nextID     atomic.Int64
-	mu         sync.Mutex
 
-	// Capabilities negotiated during initialization.
+	// mu guards the transport and the state negotiated over it.
+	mu           sync.Mutex
+	transport    Transport
 	capabilities ServerCapabilities
 	serverInfo   ServerInfo
+
+	// failures receives transport errors from calls, waking the manager's
+	// health monitor. It holds at most one pending error.
+	failures chan error
+
+	// Limits on how long a call waits for its response; zero means no
+	// limit. toolTimeout applies to tools/call, requestTimeout to the rest.
+	requestTimeout time.Duration
+	toolTimeout    time.Duration
+
+	// Progress callbacks for calls in flight, by progress token.
+	progressMu sync.Mutex
+	progress   map[string]func(ProgressParams)
+
+	// Handlers for requests and notifications from the server, by method.
+	handlersMu sync.Mutex
+	handlers   map[string]RequestHandler
 }
 
 // NewMCPClient creates a new MCP client for the named server.
@@ -37,11 +58,55 @@ func NewMCPClient(serverName string, transport Transport) *MCPClient {
 	c := &MCPClient{
 		transport:  transport,
 		serverName: serverName,
+		failures:   make(chan error, 1),
+		progress:   make(map[string]func(ProgressParams)),
+	}
+	c.handlers = map[string]RequestHandler{
+		"ping":                   func(context.Context, json.RawMessage) (any, error) { return nil, nil },
+		"notifications/progress": c.handleProgress,
 	}
 	c.nextID.Store(1)
+	if t, ok := transport.(incomingTransport); ok {
+		t.SetIncomingHandler(c.dispatch)
+	}
 	return c
 }
 
+// Handle registers fn for server requests or notifications with the given
+// method. Register handlers before Initialize so that the matching client
+// capabilities are advertised.
+func (c *MCPClient) Handle(method string, fn RequestHandler) {
+	c.handlersMu.Lock()
+	defer c.handlersMu.Unlock()
+	c.handlers[method] = fn
+}
+
+// handles reports whether a handler is registered for method.
+func (c *MCPClient) handles(method string) bool {
+	c.handlersMu.Lock()
+	defer c.handlersMu.Unlock()
+	return c.handlers[method] != nil
+}
+
+// dispatch routes a server request or notification to its handler.
+func (c *MCPClient) dispatch(ctx context.Context, method string, params json.RawMessage) (any, error) {
+	c.handlersMu.Lock()
+	fn := c.handlers[method]
+	c.handlersMu.Unlock()
+	if fn == nil {
+		return nil, &JSONRPCError{Code: codeMethodNotFound, Message: "method not found: " + method}
+	}
+	return fn(ctx, params)
+}
+
+// SetTimeouts sets how long calls wait for a response: tool calls up to
+// tool, other requests up to request. Zero means no limit.
+func (c *MCPClient) SetTimeouts(request, tool time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requestTimeout, c.toolTimeout = request, tool
+}
+
 // ServerName returns the configured name of this server.
 func (c *MCPClient) ServerName() string {
 	return c.serverName
@@ -49,19 +114,54 @@ func (c *MCPClient) ServerName() string {
 
 // ServerInfo returns the server's self-reported info after initialization.
 func (c *MCPClient) ServerInfoResult() ServerInfo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	return c.serverInfo
 }
 
 // Capabilities returns the negotiated server capabilities.
 func (c *MCPClient) Capabilities() ServerCapabilities {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	return c.capabilities
 }
 
+// conn returns the current transport.
+func (c *MCPClient) conn() Transport {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.transport
+}
+
+// swapTransport replaces the transport, routing the new one's incoming
+// messages to the client's handlers, and returns the old one. The caller
+// closes the old transport and re-runs Initialize over a live one.
+func (c *MCPClient) swapTransport(t Transport) Transport {
+	if it, ok := t.(incomingTransport); ok {
+		it.SetIncomingHandler(c.dispatch)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	old := c.transport
+	c.transport = t
+	return old
+}
+
 // Initialize performs the MCP initialization handshake.
 func (c *MCPClient) Initialize(ctx context.Context) error {
+	var caps ClientCapabilities
+	if c.handles("roots/list") {
+		caps.Roots = &RootsCapability{ListChanged: true}
+	}
+	if c.handles("sampling/createMessage") {
+		caps.Sampling = &SamplingCapability{}
+	}
+	if c.handles("elicitation/create") {
+		caps.Elicitation = &ElicitationCapability{}
+	}
 	params := InitializeParams{
 		ProtocolVersion: ProtocolVersion,
-		Capabilities:    ClientCapabilities{},
+		Capabilities:    caps,
 		ClientInfo: ClientInfo{
 			Name:    "claude-code",
 			Version: "1.0.0",
@@ -83,21 +183,35 @@ func (c *MCPClient) Initialize(ctx context.Context) error {
 		return fmt.Errorf("unmarshal initialize result: %w", err)
 	}
 
+	c.mu.Lock()
 	c.capabilities = result.Capabilities
 	c.serverInfo = result.ServerInfo
+	c.mu.Unlock()
 
 	// Send initialized notification.
-	notif := &JSONRPCRequest{
-		JSONRPC: "2.0",
-		Method:  "notifications/initialized",
-	}
-	if err := c.transport.Notify(ctx, notif); err != nil {
+	if err := c.notify(ctx, "notifications/initialized", nil); err != nil {
 		return fmt.Errorf("send initialized notification: %w", err)
 	}
 
 	return nil
 }
 
+// notify sends a notification to the server. Params may be nil.
+func (c *MCPClient) notify(ctx context.Context, method string, params any) error {
+	req := &JSONRPCRequest{
+		JSONRPC: "2.0",
+		Method:  method,
+	}
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return fmt.Errorf("marshal %s params: %w", method, err)
+		}
+		req.Params = data
+	}
+	return c.conn().Notify(ctx, req)
+}
+
 // ListTools discovers tools from the server.
 func (c *MCPClient) ListTools(ctx context.Context) ([]MCPToolDef, error) {
 	paramsJSON, _ := json.Marshal(struct{}{})
@@ -117,17 +231,30 @@ func (c *MCPClient) ListTools(ctx context.Context) ([]MCPToolDef, error) {
 
 // CallTool executes a tool on the server.
 func (c *MCPClient) CallTool(ctx context.Context, name string, args json.RawMessage) (json.RawMessage, error) {
+	return c.CallToolWithProgress(ctx, name, args, nil)
+}
+
+// CallToolWithProgress executes a tool on the server, passing the
+// server's progress notifications for the call to onProgress if it isn't
+// nil.
+func (c *MCPClient) CallToolWithProgress(ctx context.Context, name string, args json.RawMessage, onProgress func(ProgressParams)) (json.RawMessage, error) {
+	id := c.newID()
 	params := ToolCallParams{
 		Name:      name,
 		Arguments: args,
 	}
+	if onProgress != nil {
+		token := json.RawMessage(fmt.Sprint(id))
+		params.Meta = &RequestMeta{ProgressToken: token}
+		defer c.watchProgress(token, onProgress)()
+	}
 
 	paramsJSON, err := json.Marshal(params)
 	if err != nil {
 		return nil, fmt.Errorf("marshal tool call params: %w", err)
 	}
 
-	resp, err := c.call(ctx, "tools/call", paramsJSON)
+	resp, err := c.callID(ctx, id, "tools/call", paramsJSON)
 	if err != nil {
 		return nil, fmt.Errorf("tools/call %s: %w", name, err)
 	}
@@ -208,17 +335,77 @@ func (c *MCPClient) UnsubscribeResource(ctx context.Context, uri string) error {
 	return nil
 }
 
+// ListPrompts lists the prompt templates the server provides.
+func (c *MCPClient) ListPrompts(ctx context.Context) ([]MCPPrompt, error) {
+	paramsJSON, _ := json.Marshal(struct{}{})
+
+	resp, err := c.call(ctx, "prompts/list", paramsJSON)
+	if err != nil {
+		return nil, fmt.Errorf("prompts/list: %w", err)
+	}
+
+	var result PromptsListResult
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, fmt.Errorf("unmarshal prompts/list result: %w", err)
+	}
+
+	return result.Prompts, nil
+}
+
+// GetPrompt resolves a prompt template with the given arguments.
+func (c *MCPClient) GetPrompt(ctx context.Context, name string, args map[string]string) (*PromptGetResult, error) {
+	params := PromptGetParams{Name: name, Arguments: args}
+
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("marshal prompt get params: %w", err)
+	}
+
+	resp, err := c.call(ctx, "prompts/get", paramsJSON)
+	if err != nil {
+		return nil, fmt.Errorf("prompts/get %s: %w", name, err)
+	}
+
+	var result PromptGetResult
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, fmt.Errorf("unmarshal prompts/get result: %w", err)
+	}
+
+	return &result, nil
+}
+
+// Ping checks that the server is responsive. A server that answers with
+// an error, such as one that doesn't implement ping, is still alive.
+func (c *MCPClient) Ping(ctx context.Context) error {
+	_, err := c.call(ctx, "ping", nil)
+	var rpcErr *JSONRPCError
+	if errors.As(err, &rpcErr) {
+		return nil
+	}
+	return err
+}
+
 // Close shuts down the transport.
 func (c *MCPClient) Close() error {
-	return c.transport.Close()
+	return c.conn().Close()
 }
 
 // call sends a JSON-RPC request and returns the result payload.
 func (c *MCPClient) call(ctx context.Context, method string, params json.RawMessage) (json.RawMessage, error) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	return c.callID(ctx, c.newID(), method, params)
+}
 
-	id := c.nextID.Add(1) - 1
+// newID returns the next request ID.
+func (c *MCPClient) newID() int64 {
+	return c.nextID.Add(1) - 1
+}
+
+// callID sends a JSON-RPC request with the given ID and returns the result
+// payload. The call gives up after the client's timeout for the method;
+// when it gives up or ctx is canceled, the server is told to stop with
+// notifications/cancelled. Transport failures, as opposed to error
+// responses, cancellation and timeouts, are reported on c.failures.
+func (c *MCPClient) callID(ctx context.Context, id int64, method string, params json.RawMessage) (json.RawMessage, error) {
 	req := &JSONRPCRequest{
 		JSONRPC: "2.0",
 		ID:      &id,
@@ -226,8 +413,31 @@ func (c *MCPClient) call(ctx context.Context, method string, params json.RawMess
 		Params:  params,
 	}
 
-	resp, err := c.transport.Send(ctx, req)
+	timeout := c.timeoutFor(method)
+	callCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		callCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	resp, err := c.conn().Send(callCtx, req)
 	if err != nil {
+		if callCtx.Err() == nil {
+			select {
+			case c.failures <- err:
+			default:
+			}
+			return nil, err
+		}
+		reason := "cancelled by the client"
+		if ctx.Err() == nil {
+			err = fmt.Errorf("no response within %s", timeout)
+			reason = "timed out after " + timeout.String()
+		}
+		if method != "initialize" {
+			go c.cancelRequest(id, reason)
+		}
 		return nil, err
 	}
 
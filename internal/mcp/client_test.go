@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"sync"
 	"testing"
 )
@@ -309,3 +310,44 @@ func TestMCPClient_IDIncrement(t *testing.T) {
 		t.Errorf("IDs should be incrementing: %d, %d", id1, id2)
 	}
 }
+
+func TestMCPClient_AdvertisesCapabilities(t *testing.T) {
+	transport := newMockTransport()
+	initResult, _ := json.Marshal(InitializeResult{ProtocolVersion: ProtocolVersion})
+	transport.enqueue(&JSONRPCResponse{JSONRPC: "2.0", Result: initResult})
+
+	client := NewMCPClient("test", transport)
+	client.Handle("sampling/createMessage", func(context.Context, json.RawMessage) (any, error) { return nil, nil })
+	client.Handle("roots/list", func(context.Context, json.RawMessage) (any, error) { return nil, nil })
+	client.Handle("elicitation/create", func(context.Context, json.RawMessage) (any, error) { return nil, nil })
+	if err := client.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize error: %v", err)
+	}
+
+	params, _ := json.Marshal(transport.requests[0].Params)
+	if !strings.Contains(string(params), `"capabilities":{"roots":{"listChanged":true},"sampling":{},"elicitation":{}}`) {
+		t.Errorf("initialize params = %s, want the roots, sampling, and elicitation capabilities", params)
+	}
+}
+
+func TestMCPClient_Dispatch(t *testing.T) {
+	client := NewMCPClient("test", newMockTransport())
+
+	resp := handleIncoming(context.Background(), client.dispatch, &serverMessage{ID: json.RawMessage(`"p1"`), Method: "ping"})
+	if string(resp) != `{"jsonrpc":"2.0","id":"p1","result":{}}` {
+		t.Errorf("ping response = %s", resp)
+	}
+
+	resp = handleIncoming(context.Background(), client.dispatch, &serverMessage{ID: json.RawMessage(`7`), Method: "roots/list"})
+	var msg serverMessage
+	if err := json.Unmarshal(resp, &msg); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+	if msg.Error == nil || msg.Error.Code != codeMethodNotFound {
+		t.Errorf("error = %+v, want method not found", msg.Error)
+	}
+
+	if resp := handleIncoming(context.Background(), client.dispatch, &serverMessage{Method: "notifications/message"}); resp != nil {
+		t.Errorf("notification got response %s", resp)
+	}
+}
@@ -5,32 +5,75 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/anthropics/claude-code-go/internal/session"
 )
 
-// LoadMCPConfig loads and merges .mcp.json from the user home and project dirs.
-// User-level config (~/.mcp.json) is loaded first; project-level (.mcp.json in
-// cwd) overrides per server name.
-func LoadMCPConfig(cwd string) (*MCPConfig, error) {
-	merged := &MCPConfig{
-		MCPServers: make(map[string]ServerConfig),
+// Config scopes, from lowest to highest precedence. A server defined in
+// several scopes uses the highest one.
+const (
+	ScopeUser    = "user"    // ~/.mcp.json, available in all projects
+	ScopeProject = "project" // <cwd>/.mcp.json, shared with the project
+	ScopeLocal   = "local"   // ~/.claude/projects/<project>/mcp.local.json, private to you in this project
+)
+
+// Scopes lists the config scopes from lowest to highest precedence.
+var Scopes = []string{ScopeUser, ScopeProject, ScopeLocal}
+
+// ScopeDescription describes a scope for CLI output.
+func ScopeDescription(scope string) string {
+	switch scope {
+	case ScopeUser:
+		return "User config (available in all your projects)"
+	case ScopeProject:
+		return "Project config (shared via .mcp.json)"
+	case ScopeLocal:
+		return "Local config (private to you in this project)"
 	}
+	return scope
+}
 
-	// 1. User-level: ~/.mcp.json
-	home, err := os.UserHomeDir()
-	if err == nil {
-		userConfig := filepath.Join(home, ".mcp.json")
-		if cfg, err := loadMCPFile(userConfig); err == nil {
-			for name, sc := range cfg.MCPServers {
-				merged.MCPServers[name] = sc
-			}
+// ScopePath returns the config file for a scope. The local scope's file
+// is kept in the home directory, keyed by project like the session logs,
+// so it can't be committed with the project.
+func ScopePath(cwd, scope string) (string, error) {
+	switch scope {
+	case ScopeUser, ScopeLocal:
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
 		}
+		if scope == ScopeLocal {
+			return filepath.Join(home, ".claude", "projects", session.ProjectDirName(cwd), "mcp.local.json"), nil
+		}
+		return filepath.Join(home, ".mcp.json"), nil
+	case ScopeProject:
+		return filepath.Join(cwd, ".mcp.json"), nil
 	}
+	return "", fmt.Errorf("invalid scope %q (must be %s)", scope, strings.Join(Scopes, ", "))
+}
 
-	// 2. Project-level: <cwd>/.mcp.json (overrides user-level per server name)
-	projectConfig := filepath.Join(cwd, ".mcp.json")
-	if cfg, err := loadMCPFile(projectConfig); err == nil {
-		for name, sc := range cfg.MCPServers {
-			merged.MCPServers[name] = sc
+// ConfiguredServer is a server as resolved across scopes.
+type ConfiguredServer struct {
+	Name     string
+	Scope    string // the scope whose definition is used
+	Config   ServerConfig
+	Disabled bool // turned off with `claude mcp disable`
+}
+
+// LoadMCPConfig loads and merges the enabled servers from every scope.
+// User-level config (~/.mcp.json) is loaded first; project-level (.mcp.json
+// in cwd) and then local config override it per server name.
+func LoadMCPConfig(cwd string) (*MCPConfig, error) {
+	merged := &MCPConfig{
+		MCPServers: make(map[string]ServerConfig),
+	}
+	for _, s := range ListServers(cwd) {
+		if !s.Disabled {
+			merged.MCPServers[s.Name] = s.Config
 		}
 	}
 
@@ -41,6 +84,47 @@ func LoadMCPConfig(cwd string) (*MCPConfig, error) {
 	return merged, nil
 }
 
+// ListServers returns every configured server, enabled or not, sorted by
+// name. Missing or unreadable config files are skipped.
+func ListServers(cwd string) []ConfiguredServer {
+	byName := make(map[string]*ConfiguredServer)
+	var disabled []string
+	for _, scope := range Scopes {
+		path, err := ScopePath(cwd, scope)
+		if err != nil {
+			continue
+		}
+		cfg, err := loadMCPFile(path)
+		if err != nil {
+			continue // file not found or invalid
+		}
+		for name, sc := range cfg.MCPServers {
+			byName[name] = &ConfiguredServer{Name: name, Scope: scope, Config: sc}
+		}
+		if scope == ScopeLocal {
+			disabled = cfg.DisabledMCPServers
+		}
+	}
+
+	servers := make([]ConfiguredServer, 0, len(byName))
+	for _, s := range byName {
+		s.Disabled = slices.Contains(disabled, s.Name)
+		servers = append(servers, *s)
+	}
+	sort.Slice(servers, func(i, j int) bool { return servers[i].Name < servers[j].Name })
+	return servers
+}
+
+// FindServer returns the named server as resolved across scopes.
+func FindServer(cwd, name string) (*ConfiguredServer, error) {
+	for _, s := range ListServers(cwd) {
+		if s.Name == name {
+			return &s, nil
+		}
+	}
+	return nil, fmt.Errorf("no MCP server named %q", name)
+}
+
 // loadMCPFile reads and parses a single .mcp.json file.
 func loadMCPFile(path string) (*MCPConfig, error) {
 	data, err := os.ReadFile(path)
@@ -56,46 +140,161 @@ func loadMCPFile(path string) (*MCPConfig, error) {
 	return &cfg, nil
 }
 
-// AddServerToConfig adds an MCP server to the project-level .mcp.json file.
-func AddServerToConfig(cwd, name, command string, args []string) error {
-	path := filepath.Join(cwd, ".mcp.json")
-	cfg, err := loadMCPFile(path)
+// updateScope loads a scope's config file, or an empty config if it
+// doesn't exist, applies fn, and writes it back.
+func updateScope(cwd, scope string, fn func(cfg *MCPConfig) error) error {
+	path, err := ScopePath(cwd, scope)
 	if err != nil {
-		cfg = &MCPConfig{MCPServers: make(map[string]ServerConfig)}
+		return err
+	}
+	cfg, err := loadMCPFile(path)
+	if os.IsNotExist(err) {
+		cfg = &MCPConfig{}
+	} else if err != nil {
+		return err
 	}
 	if cfg.MCPServers == nil {
 		cfg.MCPServers = make(map[string]ServerConfig)
 	}
-
-	cfg.MCPServers[name] = ServerConfig{
-		Command: command,
-		Args:    args,
+	if err := fn(cfg); err != nil {
+		return err
 	}
 
 	data, err := json.MarshalIndent(cfg, "", "  ")
 	if err != nil {
 		return fmt.Errorf("marshaling config: %w", err)
 	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
 	return os.WriteFile(path, append(data, '\n'), 0644)
 }
 
-// RemoveServerFromConfig removes an MCP server from the project-level .mcp.json file.
-func RemoveServerFromConfig(cwd, name string) error {
-	path := filepath.Join(cwd, ".mcp.json")
-	cfg, err := loadMCPFile(path)
-	if err != nil {
-		return fmt.Errorf("no .mcp.json found")
+// AddServer adds an MCP server to a scope's config file, replacing any
+// server of the same name there.
+func AddServer(cwd, scope, name string, sc ServerConfig) error {
+	if err := sc.Validate(); err != nil {
+		return err
 	}
+	return updateScope(cwd, scope, func(cfg *MCPConfig) error {
+		cfg.MCPServers[name] = sc
+		return nil
+	})
+}
 
-	if _, ok := cfg.MCPServers[name]; !ok {
-		return fmt.Errorf("server %q not found in config", name)
+// RemoveServer removes an MCP server from a scope's config file. With an
+// empty scope the server is removed from the one scope that defines it.
+// It returns the scope removed from.
+func RemoveServer(cwd, scope, name string) (string, error) {
+	if scope == "" {
+		var found []string
+		for _, s := range Scopes {
+			path, err := ScopePath(cwd, s)
+			if err != nil {
+				continue
+			}
+			if cfg, err := loadMCPFile(path); err == nil {
+				if _, ok := cfg.MCPServers[name]; ok {
+					found = append(found, s)
+				}
+			}
+		}
+		switch len(found) {
+		case 0:
+			return "", fmt.Errorf("no MCP server named %q", name)
+		case 1:
+			scope = found[0]
+		default:
+			return "", fmt.Errorf("MCP server %q exists in multiple scopes (%s); specify one with --scope", name, strings.Join(found, ", "))
+		}
 	}
 
-	delete(cfg.MCPServers, name)
+	err := updateScope(cwd, scope, func(cfg *MCPConfig) error {
+		if _, ok := cfg.MCPServers[name]; !ok {
+			return fmt.Errorf("no MCP server named %q in %s config", name, scope)
+		}
+		delete(cfg.MCPServers, name)
+		return nil
+	})
+	return scope, err
+}
 
-	data, err := json.MarshalIndent(cfg, "", "  ")
+// SetServerEnabled enables or disables a configured server for this
+// project. Disabled servers stay configured but aren't started; the list
+// is kept in the local scope.
+func SetServerEnabled(cwd, name string, enabled bool) error {
+	if _, err := FindServer(cwd, name); err != nil {
+		return err
+	}
+	return updateScope(cwd, ScopeLocal, func(cfg *MCPConfig) error {
+		cfg.DisabledMCPServers = slices.DeleteFunc(cfg.DisabledMCPServers, func(s string) bool { return s == name })
+		if !enabled {
+			cfg.DisabledMCPServers = append(cfg.DisabledMCPServers, name)
+		}
+		return nil
+	})
+}
+
+// ParseServerJSON parses a server definition as given to
+// `claude mcp add-json`.
+func ParseServerJSON(data string) (ServerConfig, error) {
+	var sc ServerConfig
+	if err := json.Unmarshal([]byte(data), &sc); err != nil {
+		return ServerConfig{}, fmt.Errorf("invalid server JSON: %w", err)
+	}
+	return sc, sc.Validate()
+}
+
+// DesktopConfigPath returns the path of the Claude Desktop app's config
+// file, claude_desktop_config.json in the platform's user config
+// directory.
+func DesktopConfigPath() (string, error) {
+	dir, err := os.UserConfigDir()
 	if err != nil {
-		return fmt.Errorf("marshaling config: %w", err)
+		return "", err
 	}
-	return os.WriteFile(path, append(data, '\n'), 0644)
+	return filepath.Join(dir, "Claude", "claude_desktop_config.json"), nil
+}
+
+// LoadDesktopServers reads the MCP servers from a Claude Desktop config
+// file, which uses the same mcpServers format as .mcp.json.
+func LoadDesktopServers(path string) (map[string]ServerConfig, error) {
+	cfg, err := loadMCPFile(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no Claude Desktop config found at %s", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return cfg.MCPServers, nil
+}
+
+// ImportServers adds servers to a scope's config file. A server whose name
+// is already taken in that scope is added as name_1, name_2, and so on.
+// It returns the names the servers were added under, in the order given.
+func ImportServers(cwd, scope string, names []string, servers map[string]ServerConfig) ([]string, error) {
+	for _, name := range names {
+		if err := servers[name].Validate(); err != nil {
+			return nil, fmt.Errorf("server %q: %w", name, err)
+		}
+	}
+	var added []string
+	err := updateScope(cwd, scope, func(cfg *MCPConfig) error {
+		for _, name := range names {
+			target := name
+			for i := 1; ; i++ {
+				if _, taken := cfg.MCPServers[target]; !taken {
+					break
+				}
+				target = fmt.Sprintf("%s_%d", name, i)
+			}
+			cfg.MCPServers[target] = servers[name]
+			added = append(added, target)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return added, nil
 }
@@ -3,6 +3,7 @@ package mcp
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -109,6 +110,38 @@ func TestLoadMCPConfig_SSEServer(t *testing.T) {
 	}
 }
 
+func TestLoadMCPConfig_HTTPServer(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	mcpJSON := `{
+		"mcpServers": {
+			"hosted": {
+				"type": "http",
+				"url": "https://mcp.example.com/mcp"
+			}
+		}
+	}`
+
+	if err := os.WriteFile(filepath.Join(tmpDir, ".mcp.json"), []byte(mcpJSON), 0644); err != nil {
+		t.Fatalf("write .mcp.json: %v", err)
+	}
+
+	cfg, err := LoadMCPConfig(tmpDir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	server := cfg.MCPServers["hosted"]
+	if server.TransportType() != "http" {
+		t.Errorf("TransportType() = %q, want %q", server.TransportType(), "http")
+	}
+	if server.URL != "https://mcp.example.com/mcp" {
+		t.Errorf("URL = %q, want %q", server.URL, "https://mcp.example.com/mcp")
+	}
+	if got := (ServerConfig{URL: "https://mcp.example.com/sse"}).TransportType(); got != "sse" {
+		t.Errorf("TransportType() without type = %q, want %q", got, "sse")
+	}
+}
+
 func TestLoadMCPConfig_WithEnv(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -183,3 +216,145 @@ func TestLoadMCPConfig_EmptyServers(t *testing.T) {
 		t.Error("expected nil config for empty mcpServers")
 	}
 }
+
+func TestServerScopes(t *testing.T) {
+	cwd, home := t.TempDir(), t.TempDir()
+	t.Setenv("HOME", home)
+
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	must(AddServer(cwd, ScopeUser, "shared", ServerConfig{Command: "user-cmd"}))
+	must(AddServer(cwd, ScopeProject, "shared", ServerConfig{Command: "project-cmd"}))
+	must(AddServer(cwd, ScopeLocal, "shared", ServerConfig{Command: "local-cmd"}))
+	must(AddServer(cwd, ScopeUser, "remote", ServerConfig{Type: "http", URL: "https://mcp.example.com"}))
+
+	s, err := FindServer(cwd, "shared")
+	if err != nil {
+		t.Fatalf("FindServer error: %v", err)
+	}
+	if s.Scope != ScopeLocal || s.Config.Command != "local-cmd" {
+		t.Errorf("shared = %s %q, want the local definition", s.Scope, s.Config.Command)
+	}
+
+	if _, err := RemoveServer(cwd, "", "shared"); err == nil || !strings.Contains(err.Error(), "multiple scopes") {
+		t.Errorf("RemoveServer without scope error = %v, want ambiguity", err)
+	}
+	if _, err := RemoveServer(cwd, ScopeLocal, "shared"); err != nil {
+		t.Fatalf("RemoveServer local error: %v", err)
+	}
+	if s, _ := FindServer(cwd, "shared"); s.Scope != ScopeProject {
+		t.Errorf("after removing local, shared scope = %s, want project", s.Scope)
+	}
+	if scope, err := RemoveServer(cwd, "", "remote"); err != nil || scope != ScopeUser {
+		t.Errorf("RemoveServer(remote) = %q, %v, want user scope", scope, err)
+	}
+
+	if err := AddServer(cwd, ScopeLocal, "broken", ServerConfig{Type: "sse"}); err == nil {
+		t.Error("AddServer accepted an SSE server without a URL")
+	}
+	// Local servers are kept out of the project tree.
+	if path, _ := ScopePath(cwd, ScopeLocal); !strings.HasPrefix(path, filepath.Join(home, ".claude", "projects")+string(filepath.Separator)) {
+		t.Errorf("local scope path = %s, want it under ~/.claude/projects", path)
+	}
+	if _, err := os.Stat(filepath.Join(cwd, ".claude")); !os.IsNotExist(err) {
+		t.Errorf("local scope wrote to the project's .claude directory (stat error %v)", err)
+	}
+	if _, err := ScopePath(cwd, "global"); err == nil {
+		t.Error("ScopePath accepted an unknown scope")
+	}
+}
+
+func TestSetServerEnabled(t *testing.T) {
+	cwd := t.TempDir()
+	t.Setenv("HOME", t.TempDir())
+
+	if err := AddServer(cwd, ScopeProject, "a", ServerConfig{Command: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddServer(cwd, ScopeProject, "b", ServerConfig{Command: "b"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetServerEnabled(cwd, "a", false); err != nil {
+		t.Fatalf("disable error: %v", err)
+	}
+
+	cfg, err := LoadMCPConfig(cwd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cfg.MCPServers["a"]; ok || len(cfg.MCPServers) != 1 {
+		t.Errorf("servers = %v, want only b", cfg.MCPServers)
+	}
+	if s, _ := FindServer(cwd, "a"); !s.Disabled {
+		t.Error("disabled server not reported as disabled")
+	}
+
+	if err := SetServerEnabled(cwd, "a", true); err != nil {
+		t.Fatalf("enable error: %v", err)
+	}
+	if cfg, _ := LoadMCPConfig(cwd); len(cfg.MCPServers) != 2 {
+		t.Errorf("servers after enabling = %v, want a and b", cfg.MCPServers)
+	}
+	if err := SetServerEnabled(cwd, "missing", false); err == nil {
+		t.Error("disabling an unknown server should fail")
+	}
+}
+
+func TestParseServerJSON(t *testing.T) {
+	sc, err := ParseServerJSON(`{"type":"stdio","command":"npx","args":["-y","server"],"env":{"KEY":"v"}}`)
+	if err != nil {
+		t.Fatalf("ParseServerJSON error: %v", err)
+	}
+	if sc.Command != "npx" || len(sc.Args) != 2 || sc.Env["KEY"] != "v" {
+		t.Errorf("parsed = %+v", sc)
+	}
+	for _, bad := range []string{`{`, `{"type":"http"}`, `{"type":"ws","url":"x"}`} {
+		if _, err := ParseServerJSON(bad); err == nil {
+			t.Errorf("ParseServerJSON(%s) accepted invalid config", bad)
+		}
+	}
+}
+
+func TestImportDesktopServers(t *testing.T) {
+	cwd := t.TempDir()
+	t.Setenv("HOME", t.TempDir())
+
+	desktop := filepath.Join(t.TempDir(), "claude_desktop_config.json")
+	data := `{"globalShortcut": "", "mcpServers": {
+		"filesystem": {"command": "npx", "args": ["-y", "@modelcontextprotocol/server-filesystem", "/tmp"]},
+		"memory": {"command": "npx", "args": ["-y", "@modelcontextprotocol/server-memory"]}
+	}}`
+	if err := os.WriteFile(desktop, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	servers, err := LoadDesktopServers(desktop)
+	if err != nil {
+		t.Fatalf("LoadDesktopServers error: %v", err)
+	}
+	if len(servers) != 2 {
+		t.Fatalf("servers = %v, want 2", servers)
+	}
+
+	if err := AddServer(cwd, ScopeProject, "memory", ServerConfig{Command: "existing"}); err != nil {
+		t.Fatal(err)
+	}
+	added, err := ImportServers(cwd, ScopeProject, []string{"filesystem", "memory"}, servers)
+	if err != nil {
+		t.Fatalf("ImportServers error: %v", err)
+	}
+	if strings.Join(added, ",") != "filesystem,memory_1" {
+		t.Errorf("added = %v, want the clashing name suffixed", added)
+	}
+	cfg, _ := LoadMCPConfig(cwd)
+	if cfg.MCPServers["memory"].Command != "existing" || len(cfg.MCPServers["memory_1"].Args) != 2 {
+		t.Errorf("servers = %+v, want the existing server kept", cfg.MCPServers)
+	}
+
+	if _, err := LoadDesktopServers(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("LoadDesktopServers should fail without a config file")
+	}
+}
@@ -0,0 +1,195 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/anthropics/claude-code-go/internal/tools"
+)
+
+// ElicitParams are sent by a server in an "elicitation/create" request
+// asking the user for information.
+type ElicitParams struct {
+	Message         string       `json:"message"`
+	RequestedSchema ElicitSchema `json:"requestedSchema"`
+}
+
+// ElicitSchema is the flat object schema of the requested information.
+type ElicitSchema struct {
+	Type       string                    `json:"type"`
+	Properties map[string]ElicitProperty `json:"properties"`
+	Required   []string                  `json:"required,omitempty"`
+}
+
+// ElicitProperty describes one requested field. Fields are strings
+// (optionally enums), numbers, integers, or booleans.
+type ElicitProperty struct {
+	Type        string   `json:"type"`
+	Title       string   `json:"title,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Enum        []string `json:"enum,omitempty"`
+	EnumNames   []string `json:"enumNames,omitempty"`
+	Format      string   `json:"format,omitempty"`
+	Default     any      `json:"default,omitempty"`
+}
+
+// ElicitResult is the client's response to "elicitation/create".
+type ElicitResult struct {
+	Action  string         `json:"action"` // "accept", "decline", or "cancel"
+	Content map[string]any `json:"content,omitempty"`
+}
+
+// Elicitor asks the user for the information a server requests.
+type Elicitor func(ctx context.Context, server string, params *ElicitParams) (*ElicitResult, error)
+
+// AskFunc asks the user questions, returning answers keyed by question
+// text; tools.AskUserTool.Ask is one.
+type AskFunc func(ctx context.Context, questions []tools.AskUserQuestionItem) (map[string]string, error)
+
+// AskUserElicitor returns an Elicitor that prompts with the AskUserQuestion
+// machinery. The server's message is asked first, with the choice to
+// respond or decline; then each field is one question. A field whose
+// answer is missing or doesn't fit its type cancels the request if the
+// field is required and is left out otherwise.
+func AskUserElicitor(ask AskFunc) Elicitor {
+	return func(ctx context.Context, server string, params *ElicitParams) (*ElicitResult, error) {
+		intro := tools.AskUserQuestionItem{
+			Question: params.Message,
+			Header:   server,
+			Options: []tools.AskUserOption{
+				{Label: "Respond", Description: "Provide the requested information"},
+				{Label: "Decline", Description: "Don't share anything"},
+			},
+		}
+		answers, err := ask(ctx, []tools.AskUserQuestionItem{intro})
+		if err != nil {
+			return nil, err
+		}
+		switch answers[intro.Question] {
+		case "Respond":
+		case "Decline":
+			return &ElicitResult{Action: "decline"}, nil
+		default:
+			return &ElicitResult{Action: "cancel"}, nil
+		}
+
+		fields := params.fields()
+		if len(fields) == 0 {
+			return &ElicitResult{Action: "accept", Content: map[string]any{}}, nil
+		}
+		questions := make([]tools.AskUserQuestionItem, len(fields))
+		for i, f := range fields {
+			questions[i] = f.question()
+		}
+		if answers, err = ask(ctx, questions); err != nil {
+			return nil, err
+		}
+
+		content := make(map[string]any)
+		for i, f := range fields {
+			v, ok := f.value(answers[questions[i].Question])
+			if ok {
+				content[f.name] = v
+			} else if f.required {
+				return &ElicitResult{Action: "cancel"}, nil
+			}
+		}
+		return &ElicitResult{Action: "accept", Content: content}, nil
+	}
+}
+
+// elicitField is a requested field with its name.
+type elicitField struct {
+	name     string
+	required bool
+	ElicitProperty
+}
+
+// fields returns the requested fields, required ones first, each group
+// sorted by name.
+func (p *ElicitParams) fields() []elicitField {
+	var fields []elicitField
+	for name, prop := range p.RequestedSchema.Properties {
+		fields = append(fields, elicitField{name, slices.Contains(p.RequestedSchema.Required, name), prop})
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		if fields[i].required != fields[j].required {
+			return fields[i].required
+		}
+		return fields[i].name < fields[j].name
+	})
+	return fields
+}
+
+// question returns the AskUserQuestion prompt for the field. Enums and
+// booleans become options; other fields take free text, offering the
+// default as an option when there is one.
+func (f elicitField) question() tools.AskUserQuestionItem {
+	label := f.Title
+	if label == "" {
+		label = f.name
+	}
+	if f.Description != "" {
+		label += ": " + f.Description
+	}
+	if !f.required {
+		label += " (optional)"
+	}
+	q := tools.AskUserQuestionItem{Question: label, Header: f.name}
+
+	switch {
+	case len(f.Enum) > 0:
+		for i, v := range f.Enum {
+			opt := tools.AskUserOption{Label: v}
+			if i < len(f.EnumNames) {
+				opt.Description = f.EnumNames[i]
+			}
+			q.Options = append(q.Options, opt)
+		}
+	case f.Type == "boolean":
+		q.Options = []tools.AskUserOption{{Label: "true", Description: "Yes"}, {Label: "false", Description: "No"}}
+	case f.Default != nil:
+		q.Options = []tools.AskUserOption{{Label: fmt.Sprint(f.Default), Description: "(default)"}}
+	}
+	return q
+}
+
+// value converts an answer to the field's type, reporting false if it is
+// empty or invalid.
+func (f elicitField) value(answer string) (any, bool) {
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		return nil, false
+	}
+	if len(f.Enum) > 0 && !slices.Contains(f.Enum, answer) {
+		return nil, false
+	}
+	switch f.Type {
+	case "boolean":
+		b, err := strconv.ParseBool(answer)
+		return b, err == nil
+	case "integer":
+		n, err := strconv.ParseInt(answer, 10, 64)
+		return n, err == nil
+	case "number":
+		n, err := strconv.ParseFloat(answer, 64)
+		return n, err == nil
+	}
+	return answer, true
+}
+
+// elicitHandler returns the elicitation/create handler for the named server.
+func elicitHandler(server string, e Elicitor) RequestHandler {
+	return func(ctx context.Context, raw json.RawMessage) (any, error) {
+		var params ElicitParams
+		if err := json.Unmarshal(raw, &params); err != nil {
+			return nil, &JSONRPCError{Code: codeInvalidParams, Message: "invalid elicitation/create params: " + err.Error()}
+		}
+		return e(ctx, server, &params)
+	}
+}
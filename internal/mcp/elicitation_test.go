@@ -0,0 +1,104 @@
+package mcp
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/anthropics/claude-code-go/internal/tools"
+)
+
+// scriptedAsk answers each round of questions with the next answer set,
+// keyed by header, and records the questions asked.
+type scriptedAsk struct {
+	rounds []map[string]string
+	asked  [][]tools.AskUserQuestionItem
+}
+
+func (s *scriptedAsk) ask(_ context.Context, questions []tools.AskUserQuestionItem) (map[string]string, error) {
+	s.asked = append(s.asked, questions)
+	answers := make(map[string]string)
+	if len(s.rounds) > 0 {
+		for _, q := range questions {
+			if a, ok := s.rounds[0][q.Header]; ok {
+				answers[q.Question] = a
+			}
+		}
+		s.rounds = s.rounds[1:]
+	}
+	return answers, nil
+}
+
+func elicitParams() *ElicitParams {
+	return &ElicitParams{
+		Message: "Set up the deployment",
+		RequestedSchema: ElicitSchema{
+			Type: "object",
+			Properties: map[string]ElicitProperty{
+				"region":   {Type: "string", Enum: []string{"us", "eu"}, EnumNames: []string{"United States", "Europe"}},
+				"replicas": {Type: "integer", Title: "Replicas", Default: 2},
+				"public":   {Type: "boolean"},
+				"note":     {Type: "string", Description: "Anything else"},
+			},
+			Required: []string{"region", "replicas"},
+		},
+	}
+}
+
+func TestAskUserElicitor_Accept(t *testing.T) {
+	s := &scriptedAsk{rounds: []map[string]string{
+		{"deployer": "Respond"},
+		{"region": "eu", "replicas": "3", "public": "true", "note": ""},
+	}}
+	result, err := AskUserElicitor(s.ask)(context.Background(), "deployer", elicitParams())
+	if err != nil {
+		t.Fatalf("elicit error: %v", err)
+	}
+	want := &ElicitResult{Action: "accept", Content: map[string]any{"region": "eu", "replicas": int64(3), "public": true}}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("result = %+v, want %+v", result, want)
+	}
+
+	if len(s.asked) != 2 {
+		t.Fatalf("asked %d rounds, want 2", len(s.asked))
+	}
+	var headers []string
+	for _, q := range s.asked[1] {
+		headers = append(headers, q.Header)
+	}
+	if !reflect.DeepEqual(headers, []string{"region", "replicas", "note", "public"}) {
+		t.Errorf("field order = %v, want required fields first, then by name", headers)
+	}
+	if opts := s.asked[1][0].Options; len(opts) != 2 || opts[1].Description != "Europe" {
+		t.Errorf("region options = %+v, want the enum with its names", opts)
+	}
+	if opts := s.asked[1][1].Options; len(opts) != 1 || opts[0].Label != "2" {
+		t.Errorf("replicas options = %+v, want the default", opts)
+	}
+	if opts := s.asked[1][2].Options; len(opts) != 0 {
+		t.Errorf("note options = %+v, want free text", opts)
+	}
+}
+
+func TestAskUserElicitor_DeclineAndCancel(t *testing.T) {
+	tests := []struct {
+		name   string
+		rounds []map[string]string
+		want   string
+	}{
+		{"declined", []map[string]string{{"deployer": "Decline"}}, "decline"},
+		{"dismissed", []map[string]string{{}}, "cancel"},
+		{"invalid required field", []map[string]string{{"deployer": "Respond"}, {"region": "asia", "replicas": "3"}}, "cancel"},
+		{"missing required field", []map[string]string{{"deployer": "Respond"}, {"region": "us"}}, "cancel"},
+	}
+	for _, tt := range tests {
+		s := &scriptedAsk{rounds: tt.rounds}
+		result, err := AskUserElicitor(s.ask)(context.Background(), "deployer", elicitParams())
+		if err != nil {
+			t.Fatalf("%s: elicit error: %v", tt.name, err)
+		}
+		if result.Action != tt.want || result.Content != nil {
+			t.Errorf("%s: result = %+v, want action %q without content", tt.name, result, tt.want)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package mcp
+
+import (
+	"regexp"
+	"slices"
+)
+
+// envRefRe matches ${VAR} and ${VAR:-default} in server configs.
+var envRefRe = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// Expand returns the config with ${VAR} references in its command, args,
+// env values, URL, and headers replaced using lookup, normally
+// os.LookupEnv. ${VAR:-default} uses the default when VAR is unset or
+// empty. Variables that are unset with no default expand to "" and are
+// returned, sorted, in missing.
+func (c ServerConfig) Expand(lookup func(string) (string, bool)) (expanded ServerConfig, missing []string) {
+	seen := make(map[string]bool)
+	expand := func(s string) string {
+		return envRefRe.ReplaceAllStringFunc(s, func(ref string) string {
+			m := envRefRe.FindStringSubmatch(ref)
+			hasDefault := m[2] != ""
+			if v, ok := lookup(m[1]); ok && (v != "" || !hasDefault) {
+				return v
+			}
+			if hasDefault {
+				return m[3]
+			}
+			if !seen[m[1]] {
+				seen[m[1]] = true
+				missing = append(missing, m[1])
+			}
+			return ""
+		})
+	}
+	expandMap := func(in map[string]string) map[string]string {
+		if in == nil {
+			return nil
+		}
+		out := make(map[string]string, len(in))
+		for k, v := range in {
+			out[k] = expand(v)
+		}
+		return out
+	}
+
+	c.Command = expand(c.Command)
+	c.Args = slices.Clone(c.Args)
+	for i, a := range c.Args {
+		c.Args[i] = expand(a)
+	}
+	c.Env = expandMap(c.Env)
+	c.URL = expand(c.URL)
+	c.Headers = expandMap(c.Headers)
+	slices.Sort(missing)
+	return c, missing
+}
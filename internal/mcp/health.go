@@ -0,0 +1,228 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// serverHealth describes a server that lost its connection and is being
+// reconnected. Entries are replaced, not modified, so readers can hold one
+// without locking.
+type serverHealth struct {
+	err      error  // why the server is unavailable
+	attempts int    // failed reconnect attempts so far
+	stopped  string // why the server was given up on; "" while reconnecting
+}
+
+// status formats the /mcp status line of a degraded server.
+func (h *serverHealth) status(name string) string {
+	if h.stopped != "" {
+		return fmt.Sprintf("%s: failed — %s: %v", name, h.stopped, h.err)
+	}
+	if h.attempts == 0 {
+		return fmt.Sprintf("%s: degraded — reconnecting: %v", name, h.err)
+	}
+	return fmt.Sprintf("%s: degraded — reconnecting (%d failed attempts): %v", name, h.attempts, h.err)
+}
+
+// doneTransport is implemented by transports that know when their
+// connection ends: a stdio server exiting or an SSE stream closing.
+type doneTransport interface {
+	Done() <-chan struct{}
+}
+
+// unavailableTransport stands in for the transport of a server that is
+// being reconnected, so its tools fail fast with the reason instead of
+// hanging on a dead connection.
+type unavailableTransport struct {
+	server  string
+	err     error
+	stopped bool // given up on rather than reconnecting
+}
+
+func (t unavailableTransport) Send(context.Context, *JSONRPCRequest) (*JSONRPCResponse, error) {
+	return nil, t.error()
+}
+
+func (t unavailableTransport) Notify(context.Context, *JSONRPCRequest) error {
+	return t.error()
+}
+
+func (t unavailableTransport) Close() error { return nil }
+
+func (t unavailableTransport) error() error {
+	if t.stopped {
+		return fmt.Errorf("MCP server %q is unavailable (stopped after crashing): %w", t.server, t.err)
+	}
+	return fmt.Errorf("MCP server %q is unavailable (reconnecting): %w", t.server, t.err)
+}
+
+// monitor watches a started server until Shutdown, reconnecting it each
+// time it fails.
+func (m *Manager) monitor(name string, client *MCPClient) {
+	for {
+		err := m.waitForFailure(client)
+		if err == nil || !m.recover(name, client, err) {
+			return
+		}
+	}
+}
+
+// waitForFailure returns the error once the server is found dead: its
+// connection ended, a call failed in the transport, or a periodic ping
+// failed. A failed call or ended connection is confirmed with a ping
+// first. It returns nil when the manager shuts down.
+func (m *Manager) waitForFailure(client *MCPClient) error {
+	ticker := time.NewTicker(m.healthInterval)
+	defer ticker.Stop()
+
+	var done <-chan struct{}
+	if t, ok := client.conn().(doneTransport); ok {
+		done = t.Done()
+	}
+	for {
+		select {
+		case <-m.stop:
+			return nil
+		case <-done:
+			done = nil // a closed channel would fire forever
+		case <-client.failures:
+		case <-ticker.C:
+		}
+		if m.stopped() {
+			return nil
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), m.healthTimeout)
+		err := client.Ping(ctx)
+		cancel()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// recover marks the server degraded and reconnects it with exponential
+// backoff. Stdio servers are restarted at most MaxRestarts times within
+// restartWindow, then left stopped. It reports false if the manager shut
+// down first or the server was given up on.
+func (m *Manager) recover(name string, client *MCPClient, cause error) bool {
+	m.setHealth(name, &serverHealth{err: cause})
+	old := client.swapTransport(unavailableTransport{server: name, err: cause})
+	old.Close()
+
+	delay := m.backoff
+	for attempt := 1; ; attempt++ {
+		if reason, ok := m.mayRestart(name); !ok {
+			m.giveUp(name, client, cause, reason)
+			return false
+		}
+		err := m.reconnect(name, client)
+		if m.stopped() {
+			client.Close()
+			return false
+		}
+		if err == nil {
+			m.setHealth(name, nil)
+			select {
+			case <-client.failures: // reported while unavailable
+			default:
+			}
+			return true
+		}
+		m.setHealth(name, &serverHealth{err: err, attempts: attempt})
+		cause = err
+
+		select {
+		case <-m.stop:
+			return false
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, m.maxBackoff)
+	}
+}
+
+// mayRestart records a restart of a stdio server if it's within the
+// server's MaxRestarts. Otherwise it returns why not. Other servers may
+// always reconnect.
+func (m *Manager) mayRestart(name string) (reason string, ok bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cfg := m.configs[name]
+	if cfg.TransportType() != "stdio" {
+		return "", true
+	}
+	max := cfg.maxRestarts()
+	if max < 0 {
+		return "restarts disabled", false
+	}
+	now := time.Now()
+	recent := m.restarts[name][:0]
+	for _, t := range m.restarts[name] {
+		if now.Sub(t) < m.restartWindow {
+			recent = append(recent, t)
+		}
+	}
+	m.restarts[name] = recent
+	if len(recent) >= max {
+		return fmt.Sprintf("stopped after %d restarts in %v", len(recent), m.restartWindow), false
+	}
+	m.restarts[name] = append(recent, now)
+	return "", true
+}
+
+// giveUp leaves a server stopped: its tools fail fast with the last
+// error, and /mcp and its log say why.
+func (m *Manager) giveUp(name string, client *MCPClient, cause error, reason string) {
+	client.swapTransport(unavailableTransport{server: name, err: cause, stopped: true})
+	m.setHealth(name, &serverHealth{err: cause, stopped: reason})
+	m.serverLog(name).Printf("--- %s %s: %v", time.Now().Format(time.RFC3339), reason, cause)
+}
+
+// reconnect replaces the client's transport with a fresh connection,
+// re-runs initialization, and rediscovers tools and prompts.
+func (m *Manager) reconnect(name string, client *MCPClient) error {
+	m.mu.Lock()
+	cfg := m.configs[name]
+	m.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.connectTimeout)
+	defer cancel()
+
+	transport, err := m.connect(ctx, name, cfg)
+	if err != nil {
+		return err
+	}
+	unavailable := client.swapTransport(transport)
+	err = client.Initialize(ctx)
+	if err == nil {
+		_, err = m.discover(ctx, name, client)
+	}
+	if err != nil {
+		client.swapTransport(unavailable)
+		transport.Close()
+		return err
+	}
+	return nil
+}
+
+// setHealth records a server as degraded, or as healthy when h is nil.
+func (m *Manager) setHealth(name string, h *serverHealth) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if h == nil {
+		delete(m.health, name)
+	} else {
+		m.health[name] = h
+	}
+}
+
+// stopped reports whether Shutdown has been called.
+func (m *Manager) stopped() bool {
+	select {
+	case <-m.stop:
+		return true
+	default:
+		return false
+	}
+}
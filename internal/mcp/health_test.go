@@ -0,0 +1,243 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/anthropics/claude-code-go/internal/tools"
+)
+
+// restartingServer is a Streamable HTTP MCP server that can go down and
+// come back with its sessions forgotten, like a restarted deployment.
+type restartingServer struct {
+	mu          sync.Mutex
+	session     int  // current session number; earlier ones get 404
+	down        bool // every request fails with 503
+	initializes int
+}
+
+func (s *restartingServer) restart(down bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.session++
+	s.down = down
+}
+
+func (s *restartingServer) initCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.initializes
+}
+
+func (s *restartingServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.down {
+		http.Error(w, "down", http.StatusServiceUnavailable)
+		return
+	}
+	if r.Method == http.MethodDelete {
+		return
+	}
+	var req JSONRPCRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	session := fmt.Sprintf("session-%d", s.session)
+	if req.Method != "initialize" && r.Header.Get("Mcp-Session-Id") != session {
+		http.Error(w, "unknown session", http.StatusNotFound)
+		return
+	}
+
+	var result any
+	switch req.Method {
+	case "initialize":
+		s.initializes++
+		w.Header().Set("Mcp-Session-Id", session)
+		result = InitializeResult{
+			ProtocolVersion: ProtocolVersion,
+			Capabilities:    ServerCapabilities{Tools: &ToolsCapability{}},
+			ServerInfo:      ServerInfo{Name: "restarting", Version: "1.0.0"},
+		}
+	case "notifications/initialized":
+		w.WriteHeader(http.StatusAccepted)
+		return
+	case "ping":
+		result = struct{}{}
+	case "tools/list":
+		// Each session offers a tool named after it.
+		result = ToolsListResult{Tools: []MCPToolDef{{Name: fmt.Sprintf("tool%d", s.session), InputSchema: json.RawMessage(`{}`)}}}
+	case "tools/call":
+		result = ToolCallResult{Content: []ToolResultContent{{Type: "text", Text: "ok"}}}
+	default:
+		http.Error(w, "unexpected method", http.StatusBadRequest)
+		return
+	}
+	data, _ := json.Marshal(result)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(JSONRPCResponse{JSONRPC: "2.0", ID: req.ID, Result: data})
+}
+
+// eventually polls cond until it holds or a few seconds pass.
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func fastHealthManager() *Manager {
+	m := NewManager("/tmp")
+	m.healthInterval = time.Hour // only failures trigger checks
+	m.backoff = 20 * time.Millisecond
+	m.maxBackoff = 50 * time.Millisecond
+	return m
+}
+
+func TestManager_ReconnectsDroppedSession(t *testing.T) {
+	srv := &restartingServer{}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	m := fastHealthManager()
+	defer m.Shutdown()
+	registry := tools.NewRegistry(nil)
+	ctx := context.Background()
+	if err := m.StartServers(ctx, map[string]ServerConfig{"remote": {Type: "http", URL: ts.URL}}, registry); err != nil {
+		t.Fatalf("StartServers error: %v", err)
+	}
+	client, _ := m.Client("remote")
+
+	srv.restart(false)
+	if _, err := client.CallTool(ctx, "tool0", json.RawMessage(`{}`)); err == nil {
+		t.Fatal("CallTool on an expired session should fail")
+	}
+
+	eventually(t, "reinitialization", func() bool {
+		return srv.initCount() == 2 && strings.HasPrefix(m.ServerStatus("remote"), "remote: connected")
+	})
+	if !registry.HasTool("mcp__remote__tool1") {
+		t.Error("tools were not rediscovered after reconnecting")
+	}
+	if _, err := client.CallTool(ctx, "tool1", json.RawMessage(`{}`)); err != nil {
+		t.Errorf("CallTool after reconnecting: %v", err)
+	}
+	if status := m.ServerStatus("remote"); !strings.Contains(status, "connected") {
+		t.Errorf("status = %q, want connected", status)
+	}
+}
+
+func TestManager_DegradedWhileReconnecting(t *testing.T) {
+	srv := &restartingServer{}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	m := fastHealthManager()
+	defer m.Shutdown()
+	ctx := context.Background()
+	if err := m.StartServers(ctx, map[string]ServerConfig{"remote": {Type: "http", URL: ts.URL}}, tools.NewRegistry(nil)); err != nil {
+		t.Fatalf("StartServers error: %v", err)
+	}
+	client, _ := m.Client("remote")
+
+	srv.restart(true)
+	client.CallTool(ctx, "tool0", json.RawMessage(`{}`))
+
+	eventually(t, "a failed reconnect attempt", func() bool {
+		return strings.Contains(m.ServerStatus("remote"), "failed attempts")
+	})
+	status := m.ServerStatus("remote")
+	if !strings.HasPrefix(status, "remote: degraded") || !strings.Contains(status, "503") {
+		t.Errorf("status = %q, want degraded with the last error", status)
+	}
+	_, err := client.CallTool(ctx, "tool0", json.RawMessage(`{}`))
+	if err == nil || !strings.Contains(err.Error(), "unavailable (reconnecting)") {
+		t.Errorf("CallTool error = %v, want the server reported unavailable", err)
+	}
+
+	srv.restart(false)
+	eventually(t, "recovery", func() bool { return strings.HasPrefix(m.ServerStatus("remote"), "remote: connected") })
+}
+
+func TestMCPClient_FailuresReported(t *testing.T) {
+	client := NewMCPClient("test", unavailableTransport{server: "test", err: fmt.Errorf("gone")})
+
+	if err := client.Ping(context.Background()); err == nil {
+		t.Fatal("Ping over an unavailable transport should fail")
+	}
+	select {
+	case err := <-client.failures:
+		if !strings.Contains(err.Error(), "gone") {
+			t.Errorf("reported failure = %v", err)
+		}
+	default:
+		t.Error("transport failure was not reported")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	client.Ping(ctx)
+	select {
+	case err := <-client.failures:
+		t.Errorf("failure reported for a canceled call: %v", err)
+	default:
+	}
+}
+
+func TestMCPClient_PingErrorResponseIsAlive(t *testing.T) {
+	mt := newMockTransport()
+	mt.enqueue(&JSONRPCResponse{JSONRPC: "2.0", Error: &JSONRPCError{Code: codeMethodNotFound, Message: "method not found"}})
+	client := NewMCPClient("test", mt)
+
+	if err := client.Ping(context.Background()); err != nil {
+		t.Errorf("Ping error = %v, want nil for an error response", err)
+	}
+}
+
+func TestManager_RestartCap(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // for the server's log
+	m := fastHealthManager()
+	defer m.Shutdown()
+
+	// A server that exits at once, so every restart fails.
+	m.configs["crashy"] = ServerConfig{Command: "true", MaxRestarts: 2}
+	cause := fmt.Errorf("subprocess exited")
+	client := NewMCPClient("crashy", unavailableTransport{server: "crashy", err: cause})
+	m.clients["crashy"] = client
+	if m.recover("crashy", client, cause) {
+		t.Fatal("recover reported success for a server that keeps crashing")
+	}
+	status := m.ServerStatus("crashy")
+	if !strings.HasPrefix(status, "crashy: failed — stopped after 2 restarts in 10m0s: ") {
+		t.Errorf("status = %q, want the server reported stopped", status)
+	}
+	_, err := client.CallTool(context.Background(), "tool", json.RawMessage(`{}`))
+	if err == nil || !strings.Contains(err.Error(), "stopped after crashing") {
+		t.Errorf("CallTool error = %v, want the server reported stopped", err)
+	}
+	if lines, _ := m.Logs("crashy", 1); len(lines) != 1 || !strings.Contains(lines[0], "stopped after 2 restarts") {
+		t.Errorf("log = %q, want a line saying why the server stopped", lines)
+	}
+
+	m.configs["never"] = ServerConfig{Command: "true", MaxRestarts: -1}
+	client = NewMCPClient("never", unavailableTransport{server: "never", err: cause})
+	m.clients["never"] = client
+	if m.recover("never", client, cause) {
+		t.Fatal("recover restarted a server with restarts disabled")
+	}
+	if status := m.ServerStatus("never"); !strings.Contains(status, "restarts disabled") {
+		t.Errorf("status = %q, want restarts disabled", status)
+	}
+}
@@ -0,0 +1,259 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sessionHeader carries the session ID a Streamable HTTP server assigns
+// in its initialize response.
+const sessionHeader = "Mcp-Session-Id"
+
+// HTTPTransport communicates with an MCP server over the Streamable HTTP
+// transport: each JSON-RPC message is POSTed to a single endpoint, and the
+// server answers with either a JSON body or an SSE stream that ends with
+// the response.
+type HTTPTransport struct {
+	url    string
+	client *http.Client
+
+	mu        sync.Mutex
+	sessionID string // assigned by the server on initialize; "" if stateless
+	handler   IncomingHandler
+	closed    bool
+}
+
+// NewHTTPTransport creates a Streamable HTTP transport for the MCP
+// endpoint at url. The headers, such as Authorization, are sent with every
+// request.
+func NewHTTPTransport(url string, headers map[string]string) *HTTPTransport {
+	return &HTTPTransport{url: url, client: newHeaderClient(headers)}
+}
+
+// headerTransport adds configured headers to each request.
+type headerTransport struct {
+	headers map[string]string
+	base    http.RoundTripper
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// newHeaderClient returns an HTTP client that sends headers with every
+// request.
+func newHeaderClient(headers map[string]string) *http.Client {
+	if len(headers) == 0 {
+		return &http.Client{}
+	}
+	return &http.Client{Transport: &headerTransport{headers: headers, base: http.DefaultTransport}}
+}
+
+// SessionID returns the session ID assigned by the server, if any.
+func (t *HTTPTransport) SessionID() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.sessionID
+}
+
+// SetIncomingHandler sets the handler for requests and notifications the
+// server sends on response streams.
+func (t *HTTPTransport) SetIncomingHandler(h IncomingHandler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.handler = h
+}
+
+// handleRequest runs the incoming handler for a server request or
+// notification and POSTs the response, if any.
+func (t *HTTPTransport) handleRequest(msg *serverMessage) {
+	t.mu.Lock()
+	h := t.handler
+	t.mu.Unlock()
+	data := handleIncoming(context.Background(), h, msg)
+	if data == nil {
+		return
+	}
+	if resp, err := t.postData(context.Background(), data); err == nil {
+		resp.Body.Close()
+	}
+}
+
+// Send posts a JSON-RPC request and returns the server's response.
+func (t *HTTPTransport) Send(ctx context.Context, req *JSONRPCRequest) (*JSONRPCResponse, error) {
+	httpResp, err := t.post(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	if strings.HasPrefix(httpResp.Header.Get("Content-Type"), "text/event-stream") {
+		return readHTTPEventStream(httpResp.Body, req.ID, func(msg *serverMessage) { go t.handleRequest(msg) })
+	}
+
+	body, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response body: %w", err)
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil, fmt.Errorf("empty response body (status %d)", httpResp.StatusCode)
+	}
+	var resp JSONRPCResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("unmarshal response: %w", err)
+	}
+	return &resp, nil
+}
+
+// Notify posts a JSON-RPC notification. Servers acknowledge it with 202
+// Accepted and no body.
+func (t *HTTPTransport) Notify(ctx context.Context, req *JSONRPCRequest) error {
+	httpResp, err := t.post(ctx, req)
+	if err != nil {
+		return err
+	}
+	httpResp.Body.Close()
+	return nil
+}
+
+// post sends one JSON-RPC message.
+func (t *HTTPTransport) post(ctx context.Context, msg *JSONRPCRequest) (*http.Response, error) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+	resp, err := t.postData(ctx, data)
+	if err != nil {
+		return nil, fmt.Errorf("POST %s: %w", msg.Method, err)
+	}
+	return resp, nil
+}
+
+// postData POSTs an encoded JSON-RPC message, records a session ID from
+// the response, and returns the response if its status is a success.
+func (t *HTTPTransport) postData(ctx context.Context, data []byte) (*http.Response, error) {
+	t.mu.Lock()
+	closed, sessionID := t.closed, t.sessionID
+	t.mu.Unlock()
+	if closed {
+		return nil, fmt.Errorf("HTTP transport closed")
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("create POST request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "application/json, text/event-stream")
+	if sessionID != "" {
+		httpReq.Header.Set(sessionHeader, sessionID)
+	}
+
+	httpResp, err := t.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	if httpResp.StatusCode < 200 || httpResp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(httpResp.Body, 4096))
+		httpResp.Body.Close()
+		if httpResp.StatusCode == http.StatusNotFound && sessionID != "" {
+			return nil, fmt.Errorf("MCP session %s expired (status 404)", sessionID)
+		}
+		return nil, fmt.Errorf("response status %d: %s", httpResp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	if id := httpResp.Header.Get(sessionHeader); id != "" {
+		t.mu.Lock()
+		t.sessionID = id
+		t.mu.Unlock()
+	}
+	return httpResp, nil
+}
+
+// readHTTPEventStream reads SSE events until the response to the request
+// with the given ID. Server requests and notifications sent on the stream
+// before it are passed to incoming.
+func readHTTPEventStream(body io.Reader, id *int64, incoming func(*serverMessage)) (*JSONRPCResponse, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+	var data strings.Builder
+
+	dispatch := func() *JSONRPCResponse {
+		defer data.Reset()
+		var msg serverMessage
+		if data.Len() == 0 || json.Unmarshal([]byte(data.String()), &msg) != nil {
+			return nil
+		}
+		if msg.Method != "" {
+			incoming(&msg)
+			return nil
+		}
+		resp := msg.response()
+		if resp == nil || (id != nil && *resp.ID != *id) {
+			return nil
+		}
+		return resp
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if resp := dispatch(); resp != nil {
+				return resp, nil
+			}
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read SSE response: %w", err)
+	}
+	if resp := dispatch(); resp != nil {
+		return resp, nil
+	}
+	return nil, fmt.Errorf("SSE stream ended without response")
+}
+
+// Close ends the session, telling the server with a DELETE request so it
+// can free its state.
+func (t *HTTPTransport) Close() error {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return nil
+	}
+	t.closed = true
+	sessionID := t.sessionID
+	t.mu.Unlock()
+
+	if sessionID == "" {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, t.url, nil)
+	if err != nil {
+		return nil
+	}
+	req.Header.Set(sessionHeader, sessionID)
+	if resp, err := t.client.Do(req); err == nil {
+		resp.Body.Close()
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// httpTestServer is a minimal Streamable HTTP MCP server. It assigns a
+// session on initialize, answers tools/list as an SSE stream, and records
+// the session header of every request.
+type httpTestServer struct {
+	mu       sync.Mutex
+	sessions []string // Mcp-Session-Id of each POST, in order
+	methods  []string
+	deleted  string
+}
+
+func (s *httpTestServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodDelete {
+		s.mu.Lock()
+		s.deleted = r.Header.Get("Mcp-Session-Id")
+		s.mu.Unlock()
+		return
+	}
+	var req JSONRPCRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	s.sessions = append(s.sessions, r.Header.Get("Mcp-Session-Id"))
+	s.methods = append(s.methods, req.Method)
+	s.mu.Unlock()
+
+	switch req.Method {
+	case "initialize":
+		result, _ := json.Marshal(InitializeResult{
+			ProtocolVersion: ProtocolVersion,
+			Capabilities:    ServerCapabilities{Tools: &ToolsCapability{}},
+			ServerInfo:      ServerInfo{Name: "http-server", Version: "1.0.0"},
+		})
+		w.Header().Set("Mcp-Session-Id", "session-1")
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(JSONRPCResponse{JSONRPC: "2.0", ID: req.ID, Result: result})
+	case "notifications/initialized":
+		w.WriteHeader(http.StatusAccepted)
+	case "tools/list":
+		if r.Header.Get("Mcp-Session-Id") != "session-1" {
+			http.Error(w, "unknown session", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/message\",\"params\":{}}\n\n")
+		fmt.Fprintf(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"id\":%d,\n", *req.ID)
+		fmt.Fprint(w, "data: \"result\":{\"tools\":[{\"name\":\"echo\",\"inputSchema\":{}}]}}\n\n")
+	default:
+		http.Error(w, "unexpected method", http.StatusBadRequest)
+	}
+}
+
+func TestHTTPTransport_Session(t *testing.T) {
+	srv := &httpTestServer{}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	transport := NewHTTPTransport(ts.URL, nil)
+	client := NewMCPClient("remote", transport)
+	ctx := context.Background()
+
+	if err := client.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize error: %v", err)
+	}
+	if transport.SessionID() != "session-1" {
+		t.Errorf("SessionID = %q, want %q", transport.SessionID(), "session-1")
+	}
+	if client.ServerInfoResult().Name != "http-server" {
+		t.Errorf("server name = %q, want %q", client.ServerInfoResult().Name, "http-server")
+	}
+
+	tools, err := client.ListTools(ctx)
+	if err != nil {
+		t.Fatalf("ListTools error: %v", err)
+	}
+	if len(tools) != 1 || tools[0].Name != "echo" {
+		t.Errorf("tools = %+v, want one tool named echo", tools)
+	}
+
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close error: %v", err)
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	wantMethods := []string{"initialize", "notifications/initialized", "tools/list"}
+	if fmt.Sprint(srv.methods) != fmt.Sprint(wantMethods) {
+		t.Errorf("methods = %v, want %v", srv.methods, wantMethods)
+	}
+	wantSessions := []string{"", "session-1", "session-1"}
+	if fmt.Sprint(srv.sessions) != fmt.Sprint(wantSessions) {
+		t.Errorf("sessions = %q, want %q", srv.sessions, wantSessions)
+	}
+	if srv.deleted != "session-1" {
+		t.Errorf("DELETE session = %q, want %q", srv.deleted, "session-1")
+	}
+}
+
+func TestHTTPTransport_SessionExpired(t *testing.T) {
+	ts := httptest.NewServer(&httpTestServer{})
+	defer ts.Close()
+
+	transport := NewHTTPTransport(ts.URL, nil)
+	transport.sessionID = "stale"
+	id := int64(1)
+	_, err := transport.Send(context.Background(), &JSONRPCRequest{JSONRPC: "2.0", ID: &id, Method: "tools/list"})
+	if err == nil {
+		t.Fatal("expected error for expired session")
+	}
+}
+
+func TestHTTPTransport_HeadersFromEnv(t *testing.T) {
+	var auth string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		var req JSONRPCRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(JSONRPCResponse{JSONRPC: "2.0", ID: req.ID, Result: json.RawMessage(`{}`)})
+	}))
+	defer ts.Close()
+
+	t.Setenv("MCP_TEST_TOKEN", "s3cret")
+	m := NewManager("/tmp")
+	cfg := ServerConfig{Type: "http", URL: ts.URL, Headers: map[string]string{"Authorization": "Bearer ${MCP_TEST_TOKEN}"}}
+	transport, err := m.transportForConfig("remote", cfg)
+	if err != nil {
+		t.Fatalf("transportForConfig error: %v", err)
+	}
+	defer transport.Close()
+	if err := NewMCPClient("remote", transport).Ping(context.Background()); err != nil {
+		t.Fatalf("Ping error: %v", err)
+	}
+	if auth != "Bearer s3cret" {
+		t.Errorf("Authorization = %q, want the expanded header", auth)
+	}
+
+	cfg.Headers["Authorization"] = "Bearer ${MCP_TEST_UNSET_TOKEN}"
+	if _, err := m.transportForConfig("remote", cfg); err == nil || err.Error() != "environment variables not set: MCP_TEST_UNSET_TOKEN" {
+		t.Errorf("error for an unset variable = %v", err)
+	}
+}
@@ -0,0 +1,126 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+)
+
+// JSON-RPC error codes used in responses to server requests.
+const (
+	codeMethodNotFound = -32601
+	codeInternalError  = -32603
+)
+
+// IncomingHandler handles a request or notification the server sends to
+// the client. The result of a notification is discarded.
+type IncomingHandler func(ctx context.Context, method string, params json.RawMessage) (any, error)
+
+// RequestHandler handles one method of server request or notification.
+type RequestHandler func(ctx context.Context, params json.RawMessage) (any, error)
+
+// incomingTransport is implemented by transports that deliver requests
+// and notifications from the server.
+type incomingTransport interface {
+	SetIncomingHandler(h IncomingHandler)
+}
+
+// serverMessage is a JSON-RPC message read from the server: a response to
+// one of the client's requests, or a request or notification of its own.
+type serverMessage struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *JSONRPCError   `json:"error,omitempty"`
+}
+
+// response returns the message as a response to a client request, or nil
+// if it is a request or notification, or its ID isn't one the client uses.
+func (m *serverMessage) response() *JSONRPCResponse {
+	if m.Method != "" {
+		return nil
+	}
+	var id int64
+	if json.Unmarshal(m.ID, &id) != nil {
+		return nil
+	}
+	return &JSONRPCResponse{JSONRPC: "2.0", ID: &id, Result: m.Result, Error: m.Error}
+}
+
+// incomingResponse is the client's response to a server request. The ID
+// is echoed verbatim since servers may use string IDs.
+type incomingResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *JSONRPCError   `json:"error,omitempty"`
+}
+
+// handleIncoming runs h for a server request or notification. For a
+// request it returns the encoded response to send back; for a
+// notification it returns nil.
+func handleIncoming(ctx context.Context, h IncomingHandler, msg *serverMessage) []byte {
+	var result any
+	err := error(&JSONRPCError{Code: codeMethodNotFound, Message: "method not found: " + msg.Method})
+	if h != nil {
+		result, err = h(ctx, msg.Method, msg.Params)
+	}
+	if len(msg.ID) == 0 {
+		return nil
+	}
+
+	resp := incomingResponse{JSONRPC: "2.0", ID: msg.ID}
+	if err != nil {
+		var rpcErr *JSONRPCError
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &JSONRPCError{Code: codeInternalError, Message: err.Error()}
+		}
+		resp.Error = rpcErr
+	} else {
+		if result == nil {
+			result = struct{}{}
+		}
+		resp.Result = result
+	}
+	data, _ := json.Marshal(resp)
+	return data
+}
+
+// pendingCalls routes responses read from a server's stream to the
+// requests waiting for them.
+type pendingCalls struct {
+	mu sync.Mutex
+	m  map[int64]chan *JSONRPCResponse
+}
+
+// add registers a request and returns the channel its response arrives on.
+func (p *pendingCalls) add(id int64) chan *JSONRPCResponse {
+	ch := make(chan *JSONRPCResponse, 1)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.m == nil {
+		p.m = make(map[int64]chan *JSONRPCResponse)
+	}
+	p.m[id] = ch
+	return ch
+}
+
+// remove forgets a request, for example after its context is cancelled.
+func (p *pendingCalls) remove(id int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.m, id)
+}
+
+// deliver hands resp to the request waiting for it, if any.
+func (p *pendingCalls) deliver(resp *JSONRPCResponse) {
+	p.mu.Lock()
+	ch := p.m[*resp.ID]
+	delete(p.m, *resp.ID)
+	p.mu.Unlock()
+	if ch != nil {
+		ch <- resp
+	}
+}
@@ -0,0 +1,188 @@
+package mcp
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/anthropics/claude-code-go/internal/session"
+)
+
+const (
+	// logLines is how many lines of a server's stderr are kept in memory.
+	logLines = 1000
+
+	// logMaxLine caps an unterminated line, so a server writing without
+	// newlines can't grow the buffer without bound.
+	logMaxLine = 64 * 1024
+
+	// logMaxSize is the size past which a log file is rotated to .1 when
+	// it's opened.
+	logMaxSize = 5 * 1024 * 1024
+)
+
+// ServerLog collects what a stdio server writes to stderr. The most recent
+// lines are kept in memory for /mcp and error messages, and everything is
+// appended to a log file if one is open. It is safe for concurrent use.
+type ServerLog struct {
+	mu      sync.Mutex
+	lines   []string
+	partial []byte // unterminated last line
+	file    *os.File
+	path    string
+}
+
+// newServerLog returns a log kept only in memory.
+func newServerLog() *ServerLog {
+	return &ServerLog{}
+}
+
+// OpenServerLog returns the log for a server in the project, appending to
+// its log file. If the file can't be opened, the log is kept in memory only.
+func OpenServerLog(cwd, name string) *ServerLog {
+	l := newServerLog()
+	path, err := LogPath(cwd, name)
+	if err != nil {
+		return l
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return l
+	}
+	if info, err := os.Stat(path); err == nil && info.Size() > logMaxSize {
+		os.Rename(path, path+".1")
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return l
+	}
+	l.file, l.path = f, path
+	return l
+}
+
+// LogPath returns the log file of a server in the project,
+// ~/.claude/projects/<project>/mcp-logs/<name>.log.
+func LogPath(cwd, name string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	file := strings.NewReplacer("/", "_", `\`, "_").Replace(name) + ".log"
+	return filepath.Join(home, ".claude", "projects", session.ProjectDirName(cwd), "mcp-logs", file), nil
+}
+
+// Write records stderr output.
+func (l *ServerLog) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file != nil {
+		l.file.Write(p)
+	}
+
+	data := p
+	for len(data) > 0 {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			l.partial = append(l.partial, data...)
+			if len(l.partial) > logMaxLine {
+				l.addLine(string(l.partial))
+				l.partial = l.partial[:0]
+			}
+			break
+		}
+		l.partial = append(l.partial, data[:i]...)
+		l.addLine(string(l.partial))
+		l.partial = l.partial[:0]
+		data = data[i+1:]
+	}
+	return len(p), nil
+}
+
+// addLine appends a line, dropping the oldest past logLines. The slice is
+// compacted once it holds twice that, so trimming stays cheap.
+func (l *ServerLog) addLine(line string) {
+	l.lines = append(l.lines, strings.TrimRight(line, "\r"))
+	if len(l.lines) > 2*logLines {
+		l.lines = append([]string(nil), l.lines[len(l.lines)-logLines:]...)
+	}
+}
+
+// Printf writes a line of our own to the log, such as a note that the
+// server is starting.
+func (l *ServerLog) Printf(format string, args ...any) {
+	fmt.Fprintf(l, format+"\n", args...)
+}
+
+// Tail returns up to the last n lines, including an unterminated one. A
+// non-positive n returns every line kept in memory.
+func (l *ServerLog) Tail(n int) []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	lines := l.lines
+	if len(lines) > logLines {
+		lines = lines[len(lines)-logLines:]
+	}
+	if len(l.partial) > 0 {
+		lines = append(append([]string(nil), lines...), string(l.partial))
+	}
+	if n > 0 && len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return append([]string(nil), lines...)
+}
+
+// String returns the last few lines, for error messages.
+func (l *ServerLog) String() string {
+	return strings.TrimSpace(strings.Join(l.Tail(20), "\n"))
+}
+
+// Path returns the log file's path, or "" if the log is only in memory.
+func (l *ServerLog) Path() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.path
+}
+
+// Close closes the log file. Later output is still kept in memory.
+func (l *ServerLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// serverLog returns the log for a server, opening it on first use.
+func (m *Manager) serverLog(name string) *ServerLog {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	log := m.logs[name]
+	if log == nil {
+		log = OpenServerLog(m.cwd, name)
+		m.logs[name] = log
+	}
+	return log
+}
+
+// Logs returns up to the last n lines a server wrote to stderr, and the
+// path of its full log, or "" if it has none. Only stdio servers have
+// logs.
+func (m *Manager) Logs(name string, n int) (lines []string, path string) {
+	m.mu.Lock()
+	log := m.logs[name]
+	m.mu.Unlock()
+	if log == nil {
+		return nil, ""
+	}
+	return log.Tail(n), log.Path()
+}
+
+// describeCommand returns a stdio server's command line.
+func describeCommand(cfg ServerConfig) string {
+	return strings.TrimSpace(cfg.Command + " " + strings.Join(cfg.Args, " "))
+}
@@ -0,0 +1,97 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServerLog_Lines(t *testing.T) {
+	l := newServerLog()
+	fmt.Fprint(l, "first\nsec")
+	fmt.Fprint(l, "ond\r\nthird")
+
+	got := l.Tail(0)
+	want := []string{"first", "second", "third"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Tail(0) = %q, want %q", got, want)
+	}
+	if got := l.Tail(2); strings.Join(got, "|") != "second|third" {
+		t.Errorf("Tail(2) = %q", got)
+	}
+}
+
+func TestServerLog_KeepsRecentLines(t *testing.T) {
+	l := newServerLog()
+	for i := range 3 * logLines {
+		fmt.Fprintf(l, "line %d\n", i)
+	}
+	got := l.Tail(0)
+	if len(got) != logLines {
+		t.Fatalf("kept %d lines, want %d", len(got), logLines)
+	}
+	if want := fmt.Sprintf("line %d", 3*logLines-1); got[len(got)-1] != want {
+		t.Errorf("last line = %q, want %q", got[len(got)-1], want)
+	}
+	if want := fmt.Sprintf("line %d", 2*logLines); got[0] != want {
+		t.Errorf("first line = %q, want %q", got[0], want)
+	}
+}
+
+func TestServerLog_File(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	l := OpenServerLog("/work/app", "git/hub")
+	l.Printf("hello %s", "log")
+	l.Close()
+	fmt.Fprintln(l, "after close")
+
+	path := l.Path()
+	if !strings.HasSuffix(path, "/mcp-logs/git_hub.log") {
+		t.Errorf("path = %q, want .../mcp-logs/git_hub.log", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "hello log\n" {
+		t.Errorf("file = %q, want only the line written before Close", data)
+	}
+	if got := l.Tail(0); len(got) != 2 {
+		t.Errorf("in memory = %q, want both lines", got)
+	}
+}
+
+func TestStdioTransport_CapturesStderr(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	m := NewManager(t.TempDir())
+	defer m.Shutdown()
+
+	cfg := ServerConfig{Command: "sh", Args: []string{"-c", "echo starting up >&2; echo bad token >&2; exit 1"}}
+	transport, err := m.transportForConfig("broken", cfg)
+	if err != nil {
+		t.Fatalf("transportForConfig error: %v", err)
+	}
+	defer transport.Close()
+	select {
+	case <-transport.(*StdioTransport).Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not exit")
+	}
+
+	_, err = transport.Send(context.Background(), &JSONRPCRequest{JSONRPC: "2.0", Method: "ping"})
+	if err == nil || !strings.Contains(err.Error(), "bad token") {
+		t.Errorf("Send error = %v, want the server's stderr", err)
+	}
+
+	lines, path := m.Logs("broken", 2)
+	if strings.Join(lines, "|") != "starting up|bad token" {
+		t.Errorf("Logs = %q", lines)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "starting: sh -c") || !strings.HasSuffix(string(data), "bad token\n") {
+		t.Errorf("log file = %q, want a start marker and the stderr output", data)
+	}
+}
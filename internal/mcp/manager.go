@@ -3,81 +3,239 @@ package mcp
 import (
 	"context"
 	"fmt"
+	"os"
 	"sort"
+	"strconv"
 	"sync"
+	"time"
 
 	"github.com/anthropics/claude-code-go/internal/tools"
 )
 
 // Manager coordinates MCP server lifecycles: starting servers,
-// discovering tools, registering them, and shutting down.
+// discovering tools, registering them, watching their health, and
+// shutting down.
 type Manager struct {
-	mu      sync.Mutex
-	clients map[string]*MCPClient // keyed by server name
-	cwd     string
+	mu       sync.Mutex
+	clients  map[string]*MCPClient    // keyed by server name
+	prompts  map[string][]MCPPrompt   // keyed by server name
+	tools    map[string][]string      // registered tool names, keyed by server name
+	logs     map[string]*ServerLog    // stdio servers' stderr, keyed by server name
+	configs  map[string]ServerConfig  // keyed by server name, for reconnecting
+	health   map[string]*serverHealth // degraded servers, keyed by server name
+	starting map[string]chan struct{} // servers still starting, closed when done
+	startErr map[string]error         // servers that failed to start
+	restarts map[string][]time.Time   // recent stdio restarts, keyed by server name
+	registry *tools.Registry
+	sampler  *Sampler
+	elicitor Elicitor
+	roots    []string // workspace directories offered to servers
+	cwd      string
+
+	listeners       []func()               // called when tools or prompts change
+	updateListeners []func(ResourceUpdate) // called when a subscription sees a change
+
+	stop     chan struct{} // closed by Shutdown to end health monitoring
+	stopOnce sync.Once
+
+	// Request timeouts.
+	requestTimeout time.Duration // for requests other than tool calls
+	toolTimeout    time.Duration // for tool calls, unless the server sets one; 0 for none
+
+	// Health check and reconnect timing.
+	healthInterval time.Duration // between pings
+	healthTimeout  time.Duration // for one ping
+	connectTimeout time.Duration // for starting a server, or one reconnect attempt
+	backoff        time.Duration // before the second reconnect attempt
+	maxBackoff     time.Duration // cap on the doubling backoff
+	restartWindow  time.Duration // how far back restarts count towards MaxRestarts
 }
 
-// NewManager creates a new MCP manager.
+// NewManager creates a new MCP manager. MCP_TIMEOUT sets how long a server
+// may take to start, and MCP_TOOL_TIMEOUT how long a tool call may take,
+// both in milliseconds.
 func NewManager(cwd string) *Manager {
 	return &Manager{
-		clients: make(map[string]*MCPClient),
-		cwd:     cwd,
+		clients:        make(map[string]*MCPClient),
+		prompts:        make(map[string][]MCPPrompt),
+		tools:          make(map[string][]string),
+		logs:           make(map[string]*ServerLog),
+		configs:        make(map[string]ServerConfig),
+		health:         make(map[string]*serverHealth),
+		starting:       make(map[string]chan struct{}),
+		startErr:       make(map[string]error),
+		restarts:       make(map[string][]time.Time),
+		roots:          []string{cwd},
+		cwd:            cwd,
+		stop:           make(chan struct{}),
+		requestTimeout: time.Minute,
+		toolTimeout:    envTimeout("MCP_TOOL_TIMEOUT", 0),
+		healthInterval: 30 * time.Second,
+		healthTimeout:  10 * time.Second,
+		connectTimeout: envTimeout("MCP_TIMEOUT", 30*time.Second),
+		backoff:        time.Second,
+		maxBackoff:     time.Minute,
+		restartWindow:  10 * time.Minute,
 	}
 }
 
+// SetSampler sets the sampler that answers sampling requests from
+// servers started afterwards. Without one, servers aren't offered sampling.
+func (m *Manager) SetSampler(s *Sampler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sampler = s
+}
+
+// SetElicitor sets the function that answers elicitation requests from
+// servers started afterwards. Without one, servers aren't offered
+// elicitation.
+func (m *Manager) SetElicitor(e Elicitor) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.elicitor = e
+}
+
 // StartServers connects to all configured MCP servers, discovers their tools,
-// and registers them in the provided tool registry.
+// and registers them in the provided tool registry. Servers start in
+// parallel; StartServers waits for all of them and reports each in name
+// order. Each started server is then monitored and reconnected if it dies
+// or drops its session.
 func (m *Manager) StartServers(ctx context.Context, configs map[string]ServerConfig, registry *tools.Registry) error {
-	var firstErr error
+	done := m.launch(ctx, configs, registry)
 
-	for name, cfg := range configs {
-		client, err := m.startServer(ctx, name, cfg)
-		if err != nil {
-			fmt.Printf("Warning: MCP server %q failed to start: %v\n", name, err)
+	names := make([]string, 0, len(done))
+	for name := range done {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var firstErr error
+	for _, name := range names {
+		res := <-done[name]
+		switch {
+		case res.connectErr != nil:
+			fmt.Printf("Warning: MCP server %q failed to start: %v\n", name, res.connectErr)
 			if firstErr == nil {
-				firstErr = err
+				firstErr = res.connectErr
 			}
-			continue
+		case res.discoverErr != nil:
+			fmt.Printf("Warning: MCP server %q %v\n", name, res.discoverErr)
+		default:
+			fmt.Printf("MCP server %q: %d tools registered\n", name, res.tools)
 		}
+	}
+	return firstErr
+}
 
-		m.mu.Lock()
-		m.clients[name] = client
-		m.mu.Unlock()
+// StartServersInBackground starts the configured servers like StartServers
+// but returns at once, so an interactive session needn't wait for them.
+// Until a server is up, ServerStatus reports it as starting, and requests
+// that name it wait for it; failures are reported by ServerStatus rather
+// than printed. Its tools and prompts arrive through OnChange.
+func (m *Manager) StartServersInBackground(ctx context.Context, configs map[string]ServerConfig, registry *tools.Registry) {
+	m.launch(ctx, configs, registry)
+}
 
-		// Discover and register tools from this server.
-		mcpTools, err := client.ListTools(ctx)
-		if err != nil {
-			fmt.Printf("Warning: MCP server %q tool discovery failed: %v\n", name, err)
-			continue
-		}
+// startResult is the outcome of starting one server.
+type startResult struct {
+	tools       int
+	connectErr  error // the server couldn't be started
+	discoverErr error // it started, but listing its tools or prompts failed
+}
 
-		for _, tool := range mcpTools {
-			wrapper := NewMCPToolWrapper(name, tool, client)
-			registry.Register(wrapper)
-		}
+// launch starts each server in its own goroutine, marking it as starting
+// until it's done, and returns a channel per server for its result.
+func (m *Manager) launch(ctx context.Context, configs map[string]ServerConfig, registry *tools.Registry) map[string]chan startResult {
+	m.mu.Lock()
+	m.registry = registry
+	done := make(map[string]chan startResult, len(configs))
+	for name := range configs {
+		m.starting[name] = make(chan struct{})
+		delete(m.startErr, name)
+		done[name] = make(chan startResult, 1)
+	}
+	m.mu.Unlock()
+
+	for name, cfg := range configs {
+		go func() {
+			res := m.start(ctx, name, cfg)
+			m.mu.Lock()
+			if res.connectErr != nil {
+				m.startErr[name] = res.connectErr
+			}
+			close(m.starting[name])
+			delete(m.starting, name)
+			m.mu.Unlock()
+			done[name] <- res
+		}()
+	}
+	return done
+}
 
-		fmt.Printf("MCP server %q: %d tools registered\n", name, len(mcpTools))
+// start connects to one server, begins monitoring it, and discovers its
+// tools and prompts.
+func (m *Manager) start(ctx context.Context, name string, cfg ServerConfig) startResult {
+	ctx, cancel := context.WithTimeout(ctx, m.connectTimeout)
+	defer cancel()
+	client, err := m.startServer(ctx, name, cfg)
+	if err != nil {
+		return startResult{connectErr: err}
 	}
 
-	return firstErr
+	m.mu.Lock()
+	if m.stopped() {
+		// Shut down while starting; don't leave the server running.
+		m.mu.Unlock()
+		client.Close()
+		return startResult{connectErr: fmt.Errorf("shut down while starting")}
+	}
+	m.clients[name] = client
+	m.configs[name] = cfg
+	m.mu.Unlock()
+	go m.monitor(name, client)
+
+	n, err := m.discover(ctx, name, client)
+	return startResult{tools: n, discoverErr: err}
 }
 
-// startServer creates a transport, connects, and initializes a single MCP server.
-func (m *Manager) startServer(ctx context.Context, name string, cfg ServerConfig) (*MCPClient, error) {
-	transport, err := m.transportForConfig(cfg)
+// discover lists the server's tools and registers them, replacing any
+// registered earlier, and lists its prompts, which the TUI offers as
+// slash commands. It returns the number of tools.
+func (m *Manager) discover(ctx context.Context, name string, client *MCPClient) (int, error) {
+	defer m.changed()
+	n, err := m.refreshTools(ctx, name, client)
 	if err != nil {
-		return nil, fmt.Errorf("create transport: %w", err)
+		return 0, err
 	}
+	return n, m.refreshPrompts(ctx, name, client)
+}
 
-	// For SSE transports, establish the connection first.
-	if sseT, ok := transport.(*SSETransport); ok {
-		if err := sseT.Connect(ctx); err != nil {
-			transport.Close()
-			return nil, fmt.Errorf("SSE connect: %w", err)
-		}
+// startServer creates a transport, connects, and initializes a single MCP server.
+func (m *Manager) startServer(ctx context.Context, name string, cfg ServerConfig) (*MCPClient, error) {
+	transport, err := m.connect(ctx, name, cfg)
+	if err != nil {
+		return nil, err
 	}
 
 	client := NewMCPClient(name, transport)
+	client.SetTimeouts(m.requestTimeout, m.toolTimeout)
+	if cfg.Timeout > 0 {
+		client.SetTimeouts(m.requestTimeout, time.Duration(cfg.Timeout)*time.Millisecond)
+	}
+	client.Handle("roots/list", m.listRoots)
+	client.Handle("notifications/tools/list_changed", m.toolsChanged(name, client))
+	client.Handle("notifications/prompts/list_changed", m.promptsChanged(name, client))
+	client.Handle("notifications/resources/updated", m.resourcesUpdated(name))
+	m.mu.Lock()
+	sampler, elicitor := m.sampler, m.elicitor
+	m.mu.Unlock()
+	if sampler != nil {
+		client.Handle("sampling/createMessage", sampler.Handler(name))
+	}
+	if elicitor != nil {
+		client.Handle("elicitation/create", elicitHandler(name, elicitor))
+	}
 
 	if err := client.Initialize(ctx); err != nil {
 		transport.Close()
@@ -87,39 +245,109 @@ func (m *Manager) startServer(ctx context.Context, name string, cfg ServerConfig
 	return client, nil
 }
 
+// CheckServer connects to a server and initializes it to check that it
+// works, then disconnects. It returns the server's self-reported info.
+func (m *Manager) CheckServer(ctx context.Context, name string, cfg ServerConfig) (ServerInfo, error) {
+	client, err := m.startServer(ctx, name, cfg)
+	if err != nil {
+		return ServerInfo{}, err
+	}
+	defer client.Close()
+	return client.ServerInfoResult(), nil
+}
+
+// connect creates the transport for a server and, for SSE, establishes
+// the event stream.
+func (m *Manager) connect(ctx context.Context, name string, cfg ServerConfig) (Transport, error) {
+	transport, err := m.transportForConfig(name, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("create transport: %w", err)
+	}
+
+	// For SSE transports, establish the connection first.
+	if sseT, ok := transport.(*SSETransport); ok {
+		if err := sseT.Connect(ctx); err != nil {
+			transport.Close()
+			return nil, fmt.Errorf("SSE connect: %w", err)
+		}
+	}
+	return transport, nil
+}
+
 // transportForConfig creates the appropriate transport based on the config.
-func (m *Manager) transportForConfig(cfg ServerConfig) (Transport, error) {
-	if cfg.URL != "" {
-		return NewSSETransport(cfg.URL), nil
+// ${VAR} references are expanded from the environment first.
+func (m *Manager) transportForConfig(name string, cfg ServerConfig) (Transport, error) {
+	cfg, missing := cfg.Expand(os.LookupEnv)
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("environment variables not set: %s", joinStrings(missing, ", "))
 	}
-	if cfg.Command == "" {
-		return nil, fmt.Errorf("server config must have either 'url' or 'command'")
+	switch typ := cfg.TransportType(); typ {
+	case "http", "sse":
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("%s server config must have a 'url'", typ)
+		}
+		if typ == "http" {
+			return NewHTTPTransport(cfg.URL, cfg.Headers), nil
+		}
+		return NewSSETransport(cfg.URL, cfg.Headers), nil
+	case "stdio":
+		if cfg.Command == "" {
+			return nil, fmt.Errorf("server config must have either 'url' or 'command'")
+		}
+		log := m.serverLog(name)
+		log.Printf("--- %s starting: %s", time.Now().Format(time.RFC3339), describeCommand(cfg))
+		command, args := limitCommand(cfg.Command, cfg.Args, cfg.Limits)
+		return NewStdioTransport(command, args, cfg.Env, m.cwd, log)
+	default:
+		return nil, fmt.Errorf("unknown transport type %q", typ)
 	}
-	return NewStdioTransport(cfg.Command, cfg.Args, cfg.Env, m.cwd)
 }
 
-// Shutdown gracefully closes all server connections.
+// Shutdown stops health monitoring and gracefully closes all server
+// connections at once. Stdio servers that don't exit within a few seconds
+// are killed, along with any processes they started.
 func (m *Manager) Shutdown() {
+	m.stopOnce.Do(func() { close(m.stop) })
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	var wg sync.WaitGroup
 	for name, client := range m.clients {
-		if err := client.Close(); err != nil {
-			fmt.Printf("Warning: error closing MCP server %q: %v\n", name, err)
-		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := client.Close(); err != nil {
+				fmt.Printf("Warning: error closing MCP server %q: %v\n", name, err)
+			}
+		}()
 	}
+	wg.Wait()
 	m.clients = make(map[string]*MCPClient)
+	m.prompts = make(map[string][]MCPPrompt)
+	m.tools = make(map[string][]string)
+	for _, log := range m.logs {
+		log.Close()
+	}
+	m.logs = make(map[string]*ServerLog)
+	m.health = make(map[string]*serverHealth)
 }
 
-// Servers returns the sorted list of connected server names.
+// Servers returns the sorted list of configured server names: those
+// connected, still starting, or that failed to start.
 func (m *Manager) Servers() []string {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	names := make([]string, 0, len(m.clients))
+	names := make([]string, 0, len(m.clients)+len(m.starting)+len(m.startErr))
 	for name := range m.clients {
 		names = append(names, name)
 	}
+	for name := range m.starting {
+		names = append(names, name)
+	}
+	for name := range m.startErr {
+		names = append(names, name)
+	}
 	sort.Strings(names)
 	return names
 }
@@ -133,15 +361,42 @@ func (m *Manager) Client(name string) (*MCPClient, bool) {
 	return client, ok
 }
 
+// readyClient returns the client for a named server, first waiting for it
+// to finish starting if it's still starting in the background.
+func (m *Manager) readyClient(ctx context.Context, name string) (*MCPClient, bool) {
+	m.mu.Lock()
+	starting := m.starting[name]
+	m.mu.Unlock()
+	if starting != nil {
+		select {
+		case <-starting:
+		case <-ctx.Done():
+			return nil, false
+		}
+	}
+	return m.Client(name)
+}
+
 // ServerStatus returns a human-readable status string for an MCP server.
 func (m *Manager) ServerStatus(name string) string {
 	m.mu.Lock()
 	client, ok := m.clients[name]
+	health := m.health[name]
+	_, starting := m.starting[name]
+	startErr := m.startErr[name]
 	m.mu.Unlock()
 
-	if !ok {
+	switch {
+	case starting:
+		return fmt.Sprintf("%s: starting…", name)
+	case startErr != nil:
+		return fmt.Sprintf("%s: failed to start: %v", name, startErr)
+	case !ok:
 		return fmt.Sprintf("%s: not connected", name)
 	}
+	if health != nil {
+		return health.status(name)
+	}
 
 	info := client.ServerInfoResult()
 	caps := client.Capabilities()
@@ -162,6 +417,9 @@ func (m *Manager) ServerStatus(name string) string {
 	if caps.Resources != nil {
 		features = append(features, "resources")
 	}
+	if caps.Prompts != nil {
+		features = append(features, "prompts")
+	}
 	if len(features) > 0 {
 		status += fmt.Sprintf(" [%s]", joinStrings(features, ", "))
 	}
@@ -169,6 +427,16 @@ func (m *Manager) ServerStatus(name string) string {
 	return status
 }
 
+// envTimeout reads a timeout in milliseconds from the environment
+// variable, or returns def if it's unset or invalid.
+func envTimeout(name string, def time.Duration) time.Duration {
+	ms, err := strconv.Atoi(os.Getenv(name))
+	if err != nil || ms < 0 {
+		return def
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
 func joinStrings(strs []string, sep string) string {
 	result := ""
 	for i, s := range strs {
@@ -1,7 +1,12 @@
 package mcp
 
 import (
+	"context"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/anthropics/claude-code-go/internal/tools"
 )
 
 func TestManager_Servers_Empty(t *testing.T) {
@@ -34,7 +39,7 @@ func TestManager_ServerStatus_NotConnected(t *testing.T) {
 func TestManager_TransportForConfig_StdioNoCommand(t *testing.T) {
 	m := NewManager("/tmp")
 
-	_, err := m.transportForConfig(ServerConfig{})
+	_, err := m.transportForConfig("test", ServerConfig{})
 	if err == nil {
 		t.Error("expected error for config with no url or command")
 	}
@@ -43,7 +48,7 @@ func TestManager_TransportForConfig_StdioNoCommand(t *testing.T) {
 func TestManager_TransportForConfig_SSE(t *testing.T) {
 	m := NewManager("/tmp")
 
-	transport, err := m.transportForConfig(ServerConfig{URL: "https://example.com/sse"})
+	transport, err := m.transportForConfig("test", ServerConfig{URL: "https://example.com/sse"})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -55,6 +60,26 @@ func TestManager_TransportForConfig_SSE(t *testing.T) {
 	transport.Close()
 }
 
+func TestManager_TransportForConfig_HTTP(t *testing.T) {
+	m := NewManager("/tmp")
+
+	transport, err := m.transportForConfig("test", ServerConfig{Type: "http", URL: "https://example.com/mcp"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := transport.(*HTTPTransport); !ok {
+		t.Errorf("expected HTTPTransport for type http, got %T", transport)
+	}
+	transport.Close()
+
+	if _, err := m.transportForConfig("test", ServerConfig{Type: "http"}); err == nil {
+		t.Error("expected error for http config with no url")
+	}
+	if _, err := m.transportForConfig("test", ServerConfig{Type: "websocket", URL: "wss://example.com"}); err == nil {
+		t.Error("expected error for unknown transport type")
+	}
+}
+
 func TestManager_Shutdown_Empty(t *testing.T) {
 	m := NewManager("/tmp")
 	// Should not panic.
@@ -80,3 +105,57 @@ func TestJoinStrings(t *testing.T) {
 		}
 	}
 }
+
+func TestManager_TimeoutsFromEnv(t *testing.T) {
+	t.Setenv("MCP_TIMEOUT", "5000")
+	t.Setenv("MCP_TOOL_TIMEOUT", "90000")
+	m := NewManager("/tmp")
+	if m.connectTimeout != 5*time.Second || m.toolTimeout != 90*time.Second {
+		t.Errorf("timeouts = %v, %v; want 5s, 1m30s", m.connectTimeout, m.toolTimeout)
+	}
+
+	t.Setenv("MCP_TOOL_TIMEOUT", "soon")
+	if m := NewManager("/tmp"); m.toolTimeout != 0 {
+		t.Errorf("tool timeout with an invalid value = %v, want the default of none", m.toolTimeout)
+	}
+}
+
+func TestManager_StartServersInBackground(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // for the server's log
+	m := NewManager(t.TempDir())
+	defer m.Shutdown()
+	m.connectTimeout = 300 * time.Millisecond
+
+	// A server that never answers initialize, so it starts until the
+	// connect timeout.
+	m.StartServersInBackground(context.Background(), map[string]ServerConfig{
+		"slow": {Command: "sh", Args: []string{"-c", "cat >/dev/null"}},
+	}, tools.NewRegistry(nil))
+
+	if got := m.Servers(); len(got) != 1 || got[0] != "slow" {
+		t.Errorf("Servers() while starting = %v, want [slow]", got)
+	}
+	if got := m.ServerStatus("slow"); got != "slow: starting…" {
+		t.Errorf("ServerStatus while starting = %q", got)
+	}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, ok := m.readyClient(cancelled, "slow"); ok {
+		t.Error("readyClient with a cancelled context returned a client")
+	}
+
+	start := time.Now()
+	if _, ok := m.readyClient(context.Background(), "slow"); ok {
+		t.Error("readyClient returned a client for a server that failed to start")
+	}
+	if time.Since(start) < 100*time.Millisecond {
+		t.Error("readyClient didn't wait for the server to finish starting")
+	}
+	if got := m.ServerStatus("slow"); !strings.HasPrefix(got, "slow: failed to start: ") {
+		t.Errorf("ServerStatus after failing = %q", got)
+	}
+	if got := m.Servers(); len(got) != 1 {
+		t.Errorf("Servers() after failing = %v, want the failed server listed", got)
+	}
+}
@@ -0,0 +1,60 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// cancelTimeout bounds sending notifications/cancelled after a call has
+// been given up on.
+const cancelTimeout = 5 * time.Second
+
+// timeoutFor returns how long a call of method may wait for a response.
+func (c *MCPClient) timeoutFor(method string) time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if method == "tools/call" {
+		return c.toolTimeout
+	}
+	return c.requestTimeout
+}
+
+// cancelRequest tells the server to stop working on a request the client
+// no longer waits for.
+func (c *MCPClient) cancelRequest(id int64, reason string) {
+	ctx, cancel := context.WithTimeout(context.Background(), cancelTimeout)
+	defer cancel()
+	c.notify(ctx, "notifications/cancelled", CancelledParams{RequestID: id, Reason: reason})
+}
+
+// watchProgress routes notifications/progress with token to fn until the
+// returned function is called.
+func (c *MCPClient) watchProgress(token json.RawMessage, fn func(ProgressParams)) func() {
+	key := string(token)
+	c.progressMu.Lock()
+	c.progress[key] = fn
+	c.progressMu.Unlock()
+	return func() {
+		c.progressMu.Lock()
+		delete(c.progress, key)
+		c.progressMu.Unlock()
+	}
+}
+
+// handleProgress handles notifications/progress, passing it to the
+// callback of the call it belongs to. Reports for calls that have
+// finished are dropped.
+func (c *MCPClient) handleProgress(_ context.Context, params json.RawMessage) (any, error) {
+	var p ProgressParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, nil
+	}
+	c.progressMu.Lock()
+	fn := c.progress[string(p.ProgressToken)]
+	c.progressMu.Unlock()
+	if fn != nil {
+		fn(p)
+	}
+	return nil, nil
+}
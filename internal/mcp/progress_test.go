@@ -0,0 +1,126 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// slowTransport answers tools/call after reporting progress, or never if
+// hang is set. Notifications it's sent are recorded.
+type slowTransport struct {
+	client *MCPClient
+	hang   bool
+
+	mu     sync.Mutex
+	notifs []*JSONRPCRequest
+}
+
+func (t *slowTransport) Send(ctx context.Context, req *JSONRPCRequest) (*JSONRPCResponse, error) {
+	if t.hang {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	var params ToolCallParams
+	json.Unmarshal(req.Params, &params)
+	if params.Meta != nil {
+		for _, p := range []ProgressParams{
+			{ProgressToken: params.Meta.ProgressToken, Progress: 1, Total: 4, Message: "indexing"},
+			{ProgressToken: json.RawMessage(`"other-call"`), Progress: 3},
+		} {
+			data, _ := json.Marshal(p)
+			t.client.dispatch(ctx, "notifications/progress", data)
+		}
+	}
+	result, _ := json.Marshal(ToolCallResult{Content: []ToolResultContent{{Type: "text", Text: "done"}}})
+	return &JSONRPCResponse{JSONRPC: "2.0", ID: req.ID, Result: result}, nil
+}
+
+func (t *slowTransport) Notify(_ context.Context, req *JSONRPCRequest) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.notifs = append(t.notifs, req)
+	return nil
+}
+
+func (t *slowTransport) Close() error { return nil }
+
+func (t *slowTransport) cancelled() []CancelledParams {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var out []CancelledParams
+	for _, n := range t.notifs {
+		if n.Method == "notifications/cancelled" {
+			var p CancelledParams
+			json.Unmarshal(n.Params, &p)
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func TestMCPClient_ToolTimeoutCancels(t *testing.T) {
+	transport := &slowTransport{hang: true}
+	client := NewMCPClient("slow", transport)
+	client.SetTimeouts(time.Minute, 20*time.Millisecond)
+
+	_, err := client.CallTool(context.Background(), "build", json.RawMessage(`{}`))
+	if err == nil || !strings.Contains(err.Error(), "no response within 20ms") {
+		t.Fatalf("CallTool error = %v, want a timeout", err)
+	}
+	eventually(t, "notifications/cancelled", func() bool { return len(transport.cancelled()) == 1 })
+	if got := transport.cancelled()[0]; got.RequestID != 1 || !strings.Contains(got.Reason, "timed out") {
+		t.Errorf("cancelled = %+v, want request 1 timed out", got)
+	}
+	select {
+	case err := <-client.failures:
+		t.Errorf("timeout reported as a transport failure: %v", err)
+	default:
+	}
+}
+
+func TestMCPClient_CancelledCallNotifiesServer(t *testing.T) {
+	transport := &slowTransport{hang: true}
+	client := NewMCPClient("slow", transport)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+	if _, err := client.CallTool(ctx, "build", json.RawMessage(`{}`)); !errors.Is(err, context.Canceled) {
+		t.Fatalf("CallTool error = %v, want context.Canceled", err)
+	}
+	eventually(t, "notifications/cancelled", func() bool { return len(transport.cancelled()) == 1 })
+	if got := transport.cancelled()[0]; got.Reason != "cancelled by the client" {
+		t.Errorf("reason = %q", got.Reason)
+	}
+}
+
+func TestMCPClient_ToolProgress(t *testing.T) {
+	transport := &slowTransport{}
+	client := NewMCPClient("slow", transport)
+	transport.client = client
+
+	var got []ProgressParams
+	if _, err := client.CallToolWithProgress(context.Background(), "build", nil, func(p ProgressParams) {
+		got = append(got, p)
+	}); err != nil {
+		t.Fatalf("CallToolWithProgress error: %v", err)
+	}
+	if len(got) != 1 || got[0].Progress != 1 || got[0].Total != 4 || got[0].Message != "indexing" {
+		t.Errorf("progress = %+v, want only this call's report", got)
+	}
+
+	// Without a callback no progress token is sent.
+	if _, err := client.CallTool(context.Background(), "build", nil); err != nil {
+		t.Fatalf("CallTool error: %v", err)
+	}
+	if len(got) != 1 {
+		t.Errorf("progress delivered after the call finished: %+v", got)
+	}
+}
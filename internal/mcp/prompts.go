@@ -0,0 +1,84 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ServerPrompt is a prompt template together with the server providing it.
+type ServerPrompt struct {
+	Server string
+	MCPPrompt
+}
+
+// CommandName returns the slash command name for the prompt,
+// "mcp__<server>__<prompt>".
+func (p ServerPrompt) CommandName() string {
+	return fmt.Sprintf("mcp__%s__%s", p.Server, p.Name)
+}
+
+// Prompts returns the prompts discovered on connected servers, sorted by
+// server and prompt name.
+func (m *Manager) Prompts() []ServerPrompt {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var out []ServerPrompt
+	for server, prompts := range m.prompts {
+		for _, p := range prompts {
+			out = append(out, ServerPrompt{Server: server, MCPPrompt: p})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Server != out[j].Server {
+			return out[i].Server < out[j].Server
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// GetPrompt resolves a prompt on the named server and returns its messages
+// as text to send to the conversation.
+func (m *Manager) GetPrompt(ctx context.Context, server, name string, args map[string]string) (string, error) {
+	client, ok := m.readyClient(ctx, server)
+	if !ok {
+		return "", fmt.Errorf("MCP server %q is not connected", server)
+	}
+	result, err := client.GetPrompt(ctx, name, args)
+	if err != nil {
+		return "", err
+	}
+	text := renderPromptMessages(result.Messages)
+	if text == "" {
+		return "", fmt.Errorf("prompt %s returned no text", name)
+	}
+	return text, nil
+}
+
+// renderPromptMessages joins the text of prompt messages into a single user
+// message. Assistant messages are labelled so the model can tell them
+// apart; content without text, such as images, is noted by type.
+func renderPromptMessages(msgs []MCPPromptMessage) string {
+	var parts []string
+	for _, msg := range msgs {
+		var text string
+		switch c := msg.Content; {
+		case c.Text != "":
+			text = c.Text
+		case c.Resource != nil && c.Resource.Text != "":
+			text = fmt.Sprintf("<resource uri=%q>\n%s\n</resource>", c.Resource.URI, c.Resource.Text)
+		case c.Type != "":
+			text = fmt.Sprintf("[%s content omitted]", c.Type)
+		default:
+			continue
+		}
+		if msg.Role == "assistant" {
+			text = "Assistant: " + text
+		}
+		parts = append(parts, text)
+	}
+	return strings.Join(parts, "\n\n")
+}
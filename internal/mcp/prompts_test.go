@@ -0,0 +1,71 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestMCPClient_Prompts(t *testing.T) {
+	transport := newMockTransport()
+
+	listResult, _ := json.Marshal(PromptsListResult{
+		Prompts: []MCPPrompt{{
+			Name:        "review_pr",
+			Description: "Review a pull request",
+			Arguments:   []MCPPromptArgument{{Name: "number", Required: true}},
+		}},
+	})
+	transport.enqueue(&JSONRPCResponse{JSONRPC: "2.0", Result: listResult})
+	getResult, _ := json.Marshal(PromptGetResult{
+		Messages: []MCPPromptMessage{{Role: "user", Content: MCPPromptContent{Type: "text", Text: "Review PR 42"}}},
+	})
+	transport.enqueue(&JSONRPCResponse{JSONRPC: "2.0", Result: getResult})
+
+	client := NewMCPClient("github", transport)
+	ctx := context.Background()
+
+	prompts, err := client.ListPrompts(ctx)
+	if err != nil {
+		t.Fatalf("ListPrompts error: %v", err)
+	}
+	if len(prompts) != 1 || prompts[0].Name != "review_pr" || !prompts[0].Arguments[0].Required {
+		t.Fatalf("prompts = %+v", prompts)
+	}
+
+	result, err := client.GetPrompt(ctx, "review_pr", map[string]string{"number": "42"})
+	if err != nil {
+		t.Fatalf("GetPrompt error: %v", err)
+	}
+	if len(result.Messages) != 1 || result.Messages[0].Content.Text != "Review PR 42" {
+		t.Errorf("messages = %+v", result.Messages)
+	}
+
+	var params PromptGetParams
+	if err := json.Unmarshal(transport.requests[1].Params, &params); err != nil {
+		t.Fatalf("unmarshal params: %v", err)
+	}
+	if transport.requests[1].Method != "prompts/get" || params.Name != "review_pr" || params.Arguments["number"] != "42" {
+		t.Errorf("request = %s %+v", transport.requests[1].Method, params)
+	}
+}
+
+func TestRenderPromptMessages(t *testing.T) {
+	got := renderPromptMessages([]MCPPromptMessage{
+		{Role: "user", Content: MCPPromptContent{Type: "text", Text: "Explain this file."}},
+		{Role: "user", Content: MCPPromptContent{Type: "resource", Resource: &MCPResourceContent{URI: "file:///a.go", Text: "package a"}}},
+		{Role: "assistant", Content: MCPPromptContent{Type: "text", Text: "Which part?"}},
+		{Role: "user", Content: MCPPromptContent{Type: "image"}},
+	})
+	want := "Explain this file.\n\n<resource uri=\"file:///a.go\">\npackage a\n</resource>\n\nAssistant: Which part?\n\n[image content omitted]"
+	if got != want {
+		t.Errorf("renderPromptMessages() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestServerPrompt_CommandName(t *testing.T) {
+	p := ServerPrompt{Server: "github", MCPPrompt: MCPPrompt{Name: "review_pr"}}
+	if got := p.CommandName(); got != "mcp__github__review_pr" {
+		t.Errorf("CommandName() = %q, want %q", got, "mcp__github__review_pr")
+	}
+}
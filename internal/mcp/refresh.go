@@ -0,0 +1,116 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// OnChange registers fn to be called whenever a server's tools or prompts
+// are rediscovered, such as after a list_changed notification or a
+// reconnect, so that callers can pass the new set to the model.
+func (m *Manager) OnChange(fn func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.listeners = append(m.listeners, fn)
+}
+
+// changed calls the change listeners.
+func (m *Manager) changed() {
+	m.mu.Lock()
+	listeners := slices.Clone(m.listeners)
+	m.mu.Unlock()
+	for _, fn := range listeners {
+		fn()
+	}
+}
+
+// refreshTools lists the server's tools and brings the registry in line:
+// tools the server no longer offers, or that the server's includeTools and
+// excludeTools filter out, are removed and the rest registered. It returns
+// the number of tools registered.
+func (m *Manager) refreshTools(ctx context.Context, name string, client *MCPClient) (int, error) {
+	mcpTools, err := client.ListTools(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("tool discovery failed: %w", err)
+	}
+
+	m.mu.Lock()
+	cfg := m.configs[name]
+	m.mu.Unlock()
+	names := make([]string, 0, len(mcpTools))
+	wrappers := make([]*MCPToolWrapper, 0, len(mcpTools))
+	for _, tool := range mcpTools {
+		if !cfg.AllowsTool(tool.Name) {
+			continue
+		}
+		w := NewMCPToolWrapper(name, tool, client)
+		wrappers = append(wrappers, w)
+		names = append(names, w.Name())
+	}
+
+	m.mu.Lock()
+	registry := m.registry
+	old := m.tools[name]
+	m.tools[name] = names
+	m.mu.Unlock()
+
+	for _, n := range old {
+		if !slices.Contains(names, n) {
+			registry.Unregister(n)
+		}
+	}
+	for _, w := range wrappers {
+		registry.Register(w)
+	}
+	return len(wrappers), nil
+}
+
+// refreshPrompts lists the server's prompts, if it offers any.
+func (m *Manager) refreshPrompts(ctx context.Context, name string, client *MCPClient) error {
+	if client.Capabilities().Prompts == nil {
+		return nil
+	}
+	prompts, err := client.ListPrompts(ctx)
+	if err != nil {
+		return fmt.Errorf("prompt discovery failed: %w", err)
+	}
+	m.mu.Lock()
+	m.prompts[name] = prompts
+	m.mu.Unlock()
+	return nil
+}
+
+// toolsChanged handles notifications/tools/list_changed by rediscovering
+// the server's tools. Resources need no such handling: they're listed
+// from the server each time they're asked for.
+func (m *Manager) toolsChanged(name string, client *MCPClient) RequestHandler {
+	return m.refreshOnNotify(func(ctx context.Context) error {
+		_, err := m.refreshTools(ctx, name, client)
+		return err
+	})
+}
+
+// promptsChanged handles notifications/prompts/list_changed by
+// rediscovering the server's prompts.
+func (m *Manager) promptsChanged(name string, client *MCPClient) RequestHandler {
+	return m.refreshOnNotify(func(ctx context.Context) error {
+		return m.refreshPrompts(ctx, name, client)
+	})
+}
+
+// refreshOnNotify returns a notification handler that runs refresh and
+// then tells the change listeners. A failed refresh keeps the old list;
+// if the connection is at fault, the health monitor reconnects, which
+// rediscovers everything.
+func (m *Manager) refreshOnNotify(refresh func(ctx context.Context) error) RequestHandler {
+	return func(context.Context, json.RawMessage) (any, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), m.connectTimeout)
+		defer cancel()
+		if err := refresh(ctx); err == nil {
+			m.changed()
+		}
+		return nil, nil
+	}
+}
@@ -0,0 +1,121 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/anthropics/claude-code-go/internal/tools"
+)
+
+func resultResponse(t *testing.T, result any) *JSONRPCResponse {
+	t.Helper()
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &JSONRPCResponse{JSONRPC: "2.0", Result: data}
+}
+
+func toolsList(t *testing.T, names ...string) *JSONRPCResponse {
+	var defs []MCPToolDef
+	for _, n := range names {
+		defs = append(defs, MCPToolDef{Name: n, InputSchema: json.RawMessage(`{}`)})
+	}
+	return resultResponse(t, ToolsListResult{Tools: defs})
+}
+
+func TestManager_ToolsListChanged(t *testing.T) {
+	m := NewManager("/tmp")
+	registry := tools.NewRegistry(nil)
+	m.registry = registry
+	changes := 0
+	m.OnChange(func() { changes++ })
+
+	mt := newMockTransport()
+	client := NewMCPClient("srv", mt)
+	client.Handle("notifications/tools/list_changed", m.toolsChanged("srv", client))
+
+	mt.enqueue(toolsList(t, "old", "kept"))
+	if _, err := m.discover(context.Background(), "srv", client); err != nil {
+		t.Fatalf("discover error: %v", err)
+	}
+
+	mt.enqueue(toolsList(t, "kept", "added"))
+	if _, err := client.dispatch(context.Background(), "notifications/tools/list_changed", nil); err != nil {
+		t.Fatalf("dispatch error: %v", err)
+	}
+
+	if registry.HasTool("mcp__srv__old") {
+		t.Error("tool the server dropped is still registered")
+	}
+	for _, name := range []string{"mcp__srv__kept", "mcp__srv__added"} {
+		if !registry.HasTool(name) {
+			t.Errorf("%s not registered after list_changed", name)
+		}
+	}
+	if changes != 2 {
+		t.Errorf("listener called %d times, want 2", changes)
+	}
+
+	// A failed refresh keeps the current tools and doesn't notify.
+	client.dispatch(context.Background(), "notifications/tools/list_changed", nil)
+	if !registry.HasTool("mcp__srv__added") || changes != 2 {
+		t.Errorf("failed refresh changed state: added=%v changes=%d", registry.HasTool("mcp__srv__added"), changes)
+	}
+}
+
+func TestManager_ToolFilters(t *testing.T) {
+	m := NewManager("/tmp")
+	registry := tools.NewRegistry(nil)
+	m.registry = registry
+	m.configs["srv"] = ServerConfig{Command: "srv", IncludeTools: []string{"get_*", "search"}, ExcludeTools: []string{"get_secret"}}
+
+	mt := newMockTransport()
+	client := NewMCPClient("srv", mt)
+	mt.enqueue(toolsList(t, "get_issue", "get_secret", "search", "delete_repo"))
+	n, err := m.discover(context.Background(), "srv", client)
+	if err != nil {
+		t.Fatalf("discover error: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("discover registered %d tools, want 2", n)
+	}
+	for tool, want := range map[string]bool{
+		"mcp__srv__get_issue": true, "mcp__srv__search": true,
+		"mcp__srv__get_secret": false, "mcp__srv__delete_repo": false,
+	} {
+		if registry.HasTool(tool) != want {
+			t.Errorf("HasTool(%s) = %v, want %v", tool, !want, want)
+		}
+	}
+}
+
+func TestManager_PromptsListChanged(t *testing.T) {
+	m := NewManager("/tmp")
+	m.registry = tools.NewRegistry(nil)
+	changes := 0
+	m.OnChange(func() { changes++ })
+
+	mt := newMockTransport()
+	client := NewMCPClient("srv", mt)
+	client.Handle("notifications/prompts/list_changed", m.promptsChanged("srv", client))
+	mt.enqueue(resultResponse(t, InitializeResult{
+		ProtocolVersion: ProtocolVersion,
+		Capabilities:    ServerCapabilities{Prompts: &PromptsCapability{}},
+	}))
+	if err := client.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize error: %v", err)
+	}
+
+	mt.enqueue(resultResponse(t, PromptsListResult{Prompts: []MCPPrompt{{Name: "review"}}}))
+	client.dispatch(context.Background(), "notifications/prompts/list_changed", nil)
+
+	prompts := m.Prompts()
+	if len(prompts) != 1 || prompts[0].CommandName() != "mcp__srv__review" {
+		t.Errorf("prompts = %+v, want mcp__srv__review", prompts)
+	}
+	if changes != 1 {
+		t.Errorf("listener called %d times, want 1", changes)
+	}
+}
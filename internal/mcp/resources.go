@@ -0,0 +1,64 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ServerResource is a resource together with the server offering it.
+type ServerResource struct {
+	Server string
+	MCPResource
+}
+
+// Resources lists the resources of every connected server that offers
+// them, ordered by server name. Servers that fail to answer are skipped.
+func (m *Manager) Resources(ctx context.Context) []ServerResource {
+	var out []ServerResource
+	for _, name := range m.Servers() {
+		client, ok := m.Client(name)
+		if !ok || client.Capabilities().Resources == nil {
+			continue
+		}
+		resources, err := client.ListResources(ctx)
+		if err != nil {
+			continue
+		}
+		for _, r := range resources {
+			out = append(out, ServerResource{Server: name, MCPResource: r})
+		}
+	}
+	return out
+}
+
+// ReadResourceText reads a resource from the named server and returns its
+// text contents. Binary contents are noted by MIME type, since they can't
+// go into a text message.
+func (m *Manager) ReadResourceText(ctx context.Context, server, uri string) (string, error) {
+	client, ok := m.readyClient(ctx, server)
+	if !ok {
+		return "", fmt.Errorf("MCP server %q is not connected", server)
+	}
+	contents, err := client.ReadResource(ctx, uri)
+	if err != nil {
+		return "", err
+	}
+	var parts []string
+	for _, c := range contents {
+		switch {
+		case c.Text != "":
+			parts = append(parts, c.Text)
+		case c.Blob != "":
+			mime := c.MIMEType
+			if mime == "" {
+				mime = "binary"
+			}
+			parts = append(parts, fmt.Sprintf("[%s content omitted]", mime))
+		}
+	}
+	if len(parts) == 0 {
+		return "", fmt.Errorf("resource %s has no content", uri)
+	}
+	return strings.Join(parts, "\n\n"), nil
+}
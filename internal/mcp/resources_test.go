@@ -0,0 +1,61 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestManager_Resources(t *testing.T) {
+	m := NewManager("/tmp")
+
+	docs := newMockTransport()
+	docsClient := NewMCPClient("docs", docs)
+	docsClient.capabilities = ServerCapabilities{Resources: &ResourceCapability{}}
+	docs.enqueue(resultResponse(t, ResourcesListResult{Resources: []MCPResource{
+		{URI: "page/intro", Name: "Introduction"},
+		{URI: "page/faq", Name: "FAQ"},
+	}}))
+
+	// A server without the resources capability isn't asked.
+	plain := newMockTransport()
+	toolsClient := NewMCPClient("tools", plain)
+
+	m.clients["docs"] = docsClient
+	m.clients["tools"] = toolsClient
+
+	got := m.Resources(context.Background())
+	if len(got) != 2 || got[0].Server != "docs" || got[0].URI != "page/intro" || got[1].Name != "FAQ" {
+		t.Errorf("Resources() = %+v", got)
+	}
+	if len(plain.requests) != 0 {
+		t.Errorf("server without resources got %d requests", len(plain.requests))
+	}
+}
+
+func TestManager_ReadResourceText(t *testing.T) {
+	m := NewManager("/tmp")
+	mt := newMockTransport()
+	m.clients["docs"] = NewMCPClient("docs", mt)
+
+	mt.enqueue(resultResponse(t, ResourceReadResult{Contents: []MCPResourceContent{
+		{URI: "page/intro", Text: "Welcome."},
+		{URI: "page/intro/logo", MIMEType: "image/png", Blob: "iVBORw0KGgo="},
+	}}))
+	text, err := m.ReadResourceText(context.Background(), "docs", "page/intro")
+	if err != nil {
+		t.Fatalf("ReadResourceText error: %v", err)
+	}
+	if text != "Welcome.\n\n[image/png content omitted]" {
+		t.Errorf("text = %q", text)
+	}
+
+	mt.enqueue(resultResponse(t, ResourceReadResult{}))
+	if _, err := m.ReadResourceText(context.Background(), "docs", "page/empty"); err == nil || !strings.Contains(err.Error(), "no content") {
+		t.Errorf("empty resource error = %v", err)
+	}
+
+	if _, err := m.ReadResourceText(context.Background(), "nope", "x"); err == nil || !strings.Contains(err.Error(), "not connected") {
+		t.Errorf("unknown server error = %v", err)
+	}
+}
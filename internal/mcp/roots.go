@@ -0,0 +1,72 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Roots returns the workspace roots offered to servers: the working
+// directory followed by any added directories.
+func (m *Manager) Roots() []Root {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	roots := make([]Root, 0, len(m.roots))
+	for _, dir := range m.roots {
+		roots = append(roots, Root{URI: fileURI(dir), Name: filepath.Base(dir)})
+	}
+	return roots
+}
+
+// AddRoots adds directories to the workspace roots and sends
+// notifications/roots/list_changed to connected servers, which then ask
+// for the new list. Directories already among the roots are ignored.
+func (m *Manager) AddRoots(ctx context.Context, dirs ...string) error {
+	m.mu.Lock()
+	changed := false
+	for _, dir := range dirs {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			m.mu.Unlock()
+			return fmt.Errorf("resolve %s: %w", dir, err)
+		}
+		if !slices.Contains(m.roots, abs) {
+			m.roots = append(m.roots, abs)
+			changed = true
+		}
+	}
+	clients := make([]*MCPClient, 0, len(m.clients))
+	for _, c := range m.clients {
+		clients = append(clients, c)
+	}
+	m.mu.Unlock()
+
+	if !changed {
+		return nil
+	}
+	var firstErr error
+	for _, c := range clients {
+		if err := c.notify(ctx, "notifications/roots/list_changed", nil); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("notify %s: %w", c.ServerName(), err)
+		}
+	}
+	return firstErr
+}
+
+// listRoots answers a server's roots/list request.
+func (m *Manager) listRoots(context.Context, json.RawMessage) (any, error) {
+	return RootsListResult{Roots: m.Roots()}, nil
+}
+
+// fileURI returns the file:// URI of an absolute path.
+func fileURI(path string) string {
+	p := filepath.ToSlash(path)
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p // Windows drive paths
+	}
+	return (&url.URL{Scheme: "file", Path: p}).String()
+}
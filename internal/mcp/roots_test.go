@@ -0,0 +1,79 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/anthropics/claude-code-go/internal/tools"
+)
+
+func TestManager_AddRoots(t *testing.T) {
+	m := NewManager("/work/app")
+	if err := m.AddRoots(context.Background(), "/work/lib", "/work/app", "/work/lib/"); err != nil {
+		t.Fatalf("AddRoots error: %v", err)
+	}
+
+	roots := m.Roots()
+	want := []Root{
+		{URI: "file:///work/app", Name: "app"},
+		{URI: "file:///work/lib", Name: "lib"},
+	}
+	if len(roots) != len(want) {
+		t.Fatalf("roots = %+v, want %+v", roots, want)
+	}
+	for i := range want {
+		if roots[i] != want[i] {
+			t.Errorf("roots[%d] = %+v, want %+v", i, roots[i], want[i])
+		}
+	}
+}
+
+func TestFileURI(t *testing.T) {
+	if got := fileURI("/home/me/my project"); got != "file:///home/me/my%20project" {
+		t.Errorf("fileURI = %q", got)
+	}
+}
+
+func TestRoots_OverSSE(t *testing.T) {
+	ts := legacySSEServer(t)
+	defer ts.Close()
+
+	m := NewManager("/work/app")
+	ctx := context.Background()
+	configs := map[string]ServerConfig{"remote": {Type: "sse", URL: ts.URL + "/mcp/sse"}}
+	if err := m.StartServers(ctx, configs, tools.NewRegistry(nil)); err != nil {
+		t.Fatalf("StartServers error: %v", err)
+	}
+	defer m.Shutdown()
+
+	if method := <-ts.notifications; method != "notifications/initialized" {
+		t.Fatalf("first notification = %q, want notifications/initialized", method)
+	}
+	if err := m.AddRoots(ctx, "/work/lib"); err != nil {
+		t.Fatalf("AddRoots error: %v", err)
+	}
+	select {
+	case method := <-ts.notifications:
+		if method != "notifications/roots/list_changed" {
+			t.Errorf("notification = %q, want notifications/roots/list_changed", method)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for roots/list_changed")
+	}
+
+	ts.events <- "event: message\ndata: {\"jsonrpc\":\"2.0\",\"id\":1,\"method\":\"roots/list\"}\n\n"
+	select {
+	case reply := <-ts.replies:
+		var result RootsListResult
+		if err := json.Unmarshal(reply.Result, &result); err != nil {
+			t.Fatalf("unmarshal roots/list result: %v", err)
+		}
+		if len(result.Roots) != 2 || result.Roots[1].URI != "file:///work/lib" {
+			t.Errorf("roots = %+v, want cwd and /work/lib", result.Roots)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the roots/list response")
+	}
+}
@@ -0,0 +1,253 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/anthropics/claude-code-go/internal/api"
+)
+
+// Sampling policies, set globally or per server.
+const (
+	SamplingAsk   = "ask"   // ask the user to approve each request (default)
+	SamplingAllow = "allow" // answer requests without asking
+	SamplingDeny  = "deny"  // refuse requests
+)
+
+const (
+	// DefaultSamplingMaxTokens caps the tokens a server may request per
+	// sampling call unless configured otherwise.
+	DefaultSamplingMaxTokens = 4096
+
+	// codeSamplingRejected is the error code servers receive when a
+	// sampling request is refused, matching the reference SDKs.
+	codeSamplingRejected = -1
+	codeInvalidParams    = -32602
+)
+
+// CreateMessageParams are sent by a server in a "sampling/createMessage"
+// request.
+type CreateMessageParams struct {
+	Messages         []SamplingMessage `json:"messages"`
+	ModelPreferences *ModelPreferences `json:"modelPreferences,omitempty"`
+	SystemPrompt     string            `json:"systemPrompt,omitempty"`
+	IncludeContext   string            `json:"includeContext,omitempty"` // not honored; no conversation context is shared
+	Temperature      *float64          `json:"temperature,omitempty"`
+	MaxTokens        int               `json:"maxTokens"`
+	StopSequences    []string          `json:"stopSequences,omitempty"`
+}
+
+// SamplingMessage is one message of a sampling request.
+type SamplingMessage struct {
+	Role    string          `json:"role"` // "user" or "assistant"
+	Content SamplingContent `json:"content"`
+}
+
+// SamplingContent is the content of a sampling message or result.
+type SamplingContent struct {
+	Type     string `json:"type"` // "text", "image", or "audio"
+	Text     string `json:"text,omitempty"`
+	Data     string `json:"data,omitempty"` // base64
+	MIMEType string `json:"mimeType,omitempty"`
+}
+
+// ModelPreferences are a server's hints about which model to sample.
+type ModelPreferences struct {
+	Hints                []ModelHint `json:"hints,omitempty"`
+	CostPriority         *float64    `json:"costPriority,omitempty"`
+	SpeedPriority        *float64    `json:"speedPriority,omitempty"`
+	IntelligencePriority *float64    `json:"intelligencePriority,omitempty"`
+}
+
+// ModelHint names a model, or part of a model name, a server prefers.
+type ModelHint struct {
+	Name string `json:"name,omitempty"`
+}
+
+// CreateMessageResult is the client's response to "sampling/createMessage".
+type CreateMessageResult struct {
+	Role       string          `json:"role"`
+	Content    SamplingContent `json:"content"`
+	Model      string          `json:"model"`
+	StopReason string          `json:"stopReason,omitempty"`
+}
+
+// SamplerConfig configures a Sampler.
+type SamplerConfig struct {
+	Client    *api.Client
+	Policy    string            // SamplingAsk, SamplingAllow, or SamplingDeny; "" means ask
+	Servers   map[string]string // policy overrides by server name
+	MaxTokens int               // per-request token cap; 0 uses DefaultSamplingMaxTokens
+
+	// Approve asks the user whether a server may sample. A nil Approve
+	// refuses requests that need approval.
+	Approve func(ctx context.Context, server string, params *CreateMessageParams) (bool, error)
+}
+
+// Sampler answers sampling/createMessage requests from MCP servers with
+// the local API client, so servers can use the model without their own
+// credentials.
+type Sampler struct {
+	cfg SamplerConfig
+}
+
+// NewSampler creates a sampler.
+func NewSampler(cfg SamplerConfig) *Sampler {
+	if cfg.MaxTokens <= 0 {
+		cfg.MaxTokens = DefaultSamplingMaxTokens
+	}
+	return &Sampler{cfg: cfg}
+}
+
+// Policy returns the sampling policy for the named server.
+func (s *Sampler) Policy(server string) string {
+	if p := s.cfg.Servers[server]; p != "" {
+		return p
+	}
+	if s.cfg.Policy != "" {
+		return s.cfg.Policy
+	}
+	return SamplingAsk
+}
+
+// Handler returns the sampling/createMessage handler for the named server.
+func (s *Sampler) Handler(server string) RequestHandler {
+	return func(ctx context.Context, raw json.RawMessage) (any, error) {
+		var params CreateMessageParams
+		if err := json.Unmarshal(raw, &params); err != nil {
+			return nil, &JSONRPCError{Code: codeInvalidParams, Message: "invalid sampling/createMessage params: " + err.Error()}
+		}
+		return s.CreateMessage(ctx, server, &params)
+	}
+}
+
+// CreateMessage checks the server's policy, asking for approval if
+// needed, and then sends the request to the API.
+func (s *Sampler) CreateMessage(ctx context.Context, server string, params *CreateMessageParams) (*CreateMessageResult, error) {
+	switch s.Policy(server) {
+	case SamplingAllow:
+	case SamplingDeny:
+		return nil, &JSONRPCError{Code: codeSamplingRejected, Message: fmt.Sprintf("sampling is disabled for MCP server %q", server)}
+	default:
+		approved := false
+		if s.cfg.Approve != nil {
+			var err error
+			if approved, err = s.cfg.Approve(ctx, server, params); err != nil {
+				return nil, err
+			}
+		}
+		if !approved {
+			return nil, &JSONRPCError{Code: codeSamplingRejected, Message: "user rejected sampling request"}
+		}
+	}
+
+	req, err := s.request(params)
+	if err != nil {
+		return nil, &JSONRPCError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	resp, err := s.cfg.Client.CreateMessageStream(ctx, req, &discardStreamHandler{})
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		return nil, fmt.Errorf("empty sampling response")
+	}
+
+	var text []string
+	for _, block := range resp.Content {
+		if block.Type == api.ContentTypeText {
+			text = append(text, block.Text)
+		}
+	}
+	return &CreateMessageResult{
+		Role:       api.RoleAssistant,
+		Content:    SamplingContent{Type: "text", Text: strings.Join(text, "")},
+		Model:      resp.Model,
+		StopReason: samplingStopReason(resp.StopReason),
+	}, nil
+}
+
+// request converts sampling params into an API request.
+func (s *Sampler) request(params *CreateMessageParams) (*api.CreateMessageRequest, error) {
+	if len(params.Messages) == 0 {
+		return nil, fmt.Errorf("sampling request has no messages")
+	}
+	req := &api.CreateMessageRequest{
+		Model:     samplingModel(params.ModelPreferences, s.cfg.Client.Model()),
+		MaxTokens: s.cfg.MaxTokens,
+		Temp:      params.Temperature,
+		StopSeqs:  params.StopSequences,
+	}
+	if params.MaxTokens > 0 && params.MaxTokens < req.MaxTokens {
+		req.MaxTokens = params.MaxTokens
+	}
+	if params.SystemPrompt != "" {
+		req.System = []api.SystemBlock{{Type: "text", Text: params.SystemPrompt}}
+	}
+	for _, msg := range params.Messages {
+		if msg.Role != api.RoleUser && msg.Role != api.RoleAssistant {
+			return nil, fmt.Errorf("unsupported message role %q", msg.Role)
+		}
+		var block api.ContentBlock
+		switch msg.Content.Type {
+		case "text":
+			block = api.ContentBlock{Type: api.ContentTypeText, Text: msg.Content.Text}
+		case "image":
+			block = api.ContentBlock{Type: "image", Source: &api.ImageSource{Type: "base64", MediaType: msg.Content.MIMEType, Data: msg.Content.Data}}
+		default:
+			return nil, fmt.Errorf("unsupported content type %q", msg.Content.Type)
+		}
+		req.Messages = append(req.Messages, api.NewBlockMessage(msg.Role, []api.ContentBlock{block}))
+	}
+	return req, nil
+}
+
+// samplingModel picks the first model matching one of the server's hints,
+// which name a model or part of one ("sonnet", "claude-haiku"), and
+// otherwise the current model.
+func samplingModel(prefs *ModelPreferences, current string) string {
+	if prefs == nil {
+		return current
+	}
+	for _, hint := range prefs.Hints {
+		name := strings.ToLower(strings.TrimSpace(hint.Name))
+		if name == "" {
+			continue
+		}
+		for _, opt := range api.AvailableModels {
+			if name == opt.Alias || strings.Contains(opt.ID, name) {
+				return opt.ID
+			}
+		}
+	}
+	return current
+}
+
+// discardStreamHandler ignores all streaming events.
+type discardStreamHandler struct{}
+
+func (h *discardStreamHandler) OnMessageStart(api.MessageResponse)              {}
+func (h *discardStreamHandler) OnContentBlockStart(int, api.ContentBlock)       {}
+func (h *discardStreamHandler) OnTextDelta(int, string)                         {}
+func (h *discardStreamHandler) OnThinkingDelta(int, string)                     {}
+func (h *discardStreamHandler) OnSignatureDelta(int, string)                    {}
+func (h *discardStreamHandler) OnInputJSONDelta(int, string)                    {}
+func (h *discardStreamHandler) OnContentBlockStop(int)                          {}
+func (h *discardStreamHandler) OnMessageDelta(api.MessageDeltaBody, *api.Usage) {}
+func (h *discardStreamHandler) OnMessageStop()                                  {}
+func (h *discardStreamHandler) OnError(error)                                   {}
+
+// samplingStopReason maps an API stop reason to MCP's naming.
+func samplingStopReason(reason string) string {
+	switch reason {
+	case "end_turn":
+		return "endTurn"
+	case "max_tokens":
+		return "maxTokens"
+	case "stop_sequence":
+		return "stopSequence"
+	}
+	return reason
+}
@@ -0,0 +1,138 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anthropics/claude-code-go/internal/api"
+	"github.com/anthropics/claude-code-go/internal/mock"
+)
+
+func samplingParams(text string) *CreateMessageParams {
+	return &CreateMessageParams{
+		Messages:  []SamplingMessage{{Role: "user", Content: SamplingContent{Type: "text", Text: text}}},
+		MaxTokens: 100,
+	}
+}
+
+func TestSampler_CreateMessage(t *testing.T) {
+	var got *api.CreateMessageRequest
+	backend := mock.NewBackend(mock.ResponderFunc(func(req *api.CreateMessageRequest) *api.MessageResponse {
+		got = req
+		return mock.TextResponse("a summary", 1)
+	}))
+	defer backend.Close()
+
+	s := NewSampler(SamplerConfig{Client: backend.Client(), Policy: SamplingAllow, MaxTokens: 50})
+	params := samplingParams("Summarize this")
+	params.SystemPrompt = "Be brief."
+	params.ModelPreferences = &ModelPreferences{Hints: []ModelHint{{Name: "gpt-4"}, {Name: "haiku"}}}
+
+	result, err := s.CreateMessage(context.Background(), "summarizer", params)
+	if err != nil {
+		t.Fatalf("CreateMessage error: %v", err)
+	}
+	if result.Content.Type != "text" || result.Content.Text != "a summary" {
+		t.Errorf("content = %+v, want text %q", result.Content, "a summary")
+	}
+	if result.Role != "assistant" || result.StopReason != "endTurn" {
+		t.Errorf("role = %q, stopReason = %q, want assistant and endTurn", result.Role, result.StopReason)
+	}
+
+	if got == nil {
+		t.Fatal("no API request made")
+	}
+	if got.Model != api.ModelClaude45Haiku {
+		t.Errorf("model = %q, want %q from the haiku hint", got.Model, api.ModelClaude45Haiku)
+	}
+	if got.MaxTokens != 50 {
+		t.Errorf("max tokens = %d, want the configured cap of 50", got.MaxTokens)
+	}
+	if len(got.System) != 1 || got.System[0].Text != "Be brief." {
+		t.Errorf("system = %+v, want the server's system prompt", got.System)
+	}
+}
+
+func TestSampler_Policy(t *testing.T) {
+	backend := mock.NewBackend(&mock.StaticResponder{Response: mock.TextResponse("ok", 1)})
+	defer backend.Close()
+
+	var asked []string
+	s := NewSampler(SamplerConfig{
+		Client:  backend.Client(),
+		Servers: map[string]string{"trusted": SamplingAllow, "blocked": SamplingDeny},
+		Approve: func(_ context.Context, server string, _ *CreateMessageParams) (bool, error) {
+			asked = append(asked, server)
+			return server == "approved", nil
+		},
+	})
+
+	tests := []struct {
+		server string
+		ok     bool
+	}{
+		{"trusted", true},
+		{"blocked", false},
+		{"approved", true},
+		{"other", false},
+	}
+	for _, tt := range tests {
+		_, err := s.CreateMessage(context.Background(), tt.server, samplingParams("hi"))
+		if tt.ok && err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.server, err)
+		}
+		if !tt.ok {
+			var rpcErr *JSONRPCError
+			if !errors.As(err, &rpcErr) || rpcErr.Code != codeSamplingRejected {
+				t.Errorf("%s: error = %v, want a rejection", tt.server, err)
+			}
+		}
+	}
+	if strings.Join(asked, ",") != "approved,other" {
+		t.Errorf("asked for %v, want only the servers using the ask policy", asked)
+	}
+	if backend.RequestCount() != 2 {
+		t.Errorf("API requests = %d, want 2", backend.RequestCount())
+	}
+}
+
+func TestSampling_OverSSE(t *testing.T) {
+	backend := mock.NewBackend(&mock.StaticResponder{Response: mock.TextResponse("sampled", 1)})
+	defer backend.Close()
+	ts := legacySSEServer(t)
+	defer ts.Close()
+
+	m := NewManager("/tmp")
+	m.SetSampler(NewSampler(SamplerConfig{Client: backend.Client(), Policy: SamplingAllow}))
+	client, err := m.startServer(context.Background(), "remote", ServerConfig{Type: "sse", URL: ts.URL + "/mcp/sse"})
+	if err != nil {
+		t.Fatalf("startServer error: %v", err)
+	}
+	defer client.Close()
+
+	req, _ := json.Marshal(map[string]any{
+		"jsonrpc": "2.0", "id": "s1", "method": "sampling/createMessage",
+		"params": samplingParams("Summarize"),
+	})
+	ts.events <- "event: message\ndata: " + string(req) + "\n\n"
+
+	select {
+	case reply := <-ts.replies:
+		if string(reply.ID) != `"s1"` || reply.Error != nil {
+			t.Fatalf("reply = id %s, error %+v", reply.ID, reply.Error)
+		}
+		var result CreateMessageResult
+		if err := json.Unmarshal(reply.Result, &result); err != nil {
+			t.Fatalf("unmarshal result: %v", err)
+		}
+		if result.Content.Text != "sampled" {
+			t.Errorf("result text = %q, want %q", result.Content.Text, "sampled")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the sampling response")
+	}
+}
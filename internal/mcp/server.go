@@ -0,0 +1,207 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/anthropics/claude-code-go/internal/tools"
+)
+
+// Server exposes a tool registry to MCP clients, as `claude mcp serve`
+// does over stdin and stdout. It answers initialize, ping, tools/list, and
+// tools/call; notifications/cancelled stops a running call.
+type Server struct {
+	name     string
+	version  string
+	registry *tools.Registry
+
+	mu      sync.Mutex
+	calls   map[string]context.CancelFunc // running tools/call requests by raw ID
+	slots   map[string]chan struct{}      // per-tool limits from MaxParallel
+	writeMu sync.Mutex                    // serializes writes to the client
+}
+
+// NewServer returns a server offering the tools in registry. Name and
+// version are reported in the initialize response.
+func NewServer(name, version string, registry *tools.Registry) *Server {
+	return &Server{
+		name:     name,
+		version:  version,
+		registry: registry,
+		calls:    make(map[string]context.CancelFunc),
+		slots:    make(map[string]chan struct{}),
+	}
+}
+
+// Serve reads newline-delimited JSON-RPC messages from r and writes the
+// responses to w until r is exhausted, then waits for calls in progress.
+// Requests are handled concurrently, so a long tool call doesn't hold up
+// the rest. Cancelling ctx cancels the running calls.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for scanner.Scan() {
+		var msg serverMessage
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil || msg.Method == "" {
+			// Not JSON-RPC, or a response; the server sends no requests.
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if data := handleIncoming(ctx, s.handle(msg.ID), &msg); data != nil {
+				s.writeMu.Lock()
+				w.Write(append(data, '\n'))
+				s.writeMu.Unlock()
+			}
+		}()
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read request: %w", err)
+	}
+	return nil
+}
+
+// handle returns the handler for a message with the given raw ID, which
+// tools/call uses to make the call cancellable.
+func (s *Server) handle(id json.RawMessage) IncomingHandler {
+	return func(ctx context.Context, method string, params json.RawMessage) (any, error) {
+		switch method {
+		case "initialize":
+			return s.initialize(params), nil
+		case "notifications/initialized":
+			return nil, nil
+		case "ping":
+			return struct{}{}, nil
+		case "tools/list":
+			return s.listTools(), nil
+		case "tools/call":
+			return s.callTool(ctx, string(id), params)
+		case "notifications/cancelled":
+			s.cancel(params)
+			return nil, nil
+		}
+		return nil, &JSONRPCError{Code: codeMethodNotFound, Message: "method not found: " + method}
+	}
+}
+
+// initialize answers the client's handshake. The client's protocol
+// version is accepted as long as it parses; only tools are offered.
+func (s *Server) initialize(params json.RawMessage) InitializeResult {
+	var p InitializeParams
+	json.Unmarshal(params, &p)
+	version := p.ProtocolVersion
+	if version == "" {
+		version = ProtocolVersion
+	}
+	return InitializeResult{
+		ProtocolVersion: version,
+		Capabilities:    ServerCapabilities{Tools: &ToolsCapability{}},
+		ServerInfo:      ServerInfo{Name: s.name, Version: s.version},
+	}
+}
+
+// listTools returns the registry's tools in registration order.
+func (s *Server) listTools() ToolsListResult {
+	defs := s.registry.Definitions()
+	result := ToolsListResult{Tools: make([]MCPToolDef, 0, len(defs))}
+	for _, d := range defs {
+		result.Tools = append(result.Tools, MCPToolDef{
+			Name:        d.Name,
+			Description: d.Description,
+			InputSchema: d.InputSchema,
+		})
+	}
+	return result
+}
+
+// callTool runs a tool through the registry, so permission rules and
+// middleware apply as in a session. Tool failures are reported in the
+// result with isError, not as JSON-RPC errors.
+func (s *Server) callTool(ctx context.Context, id string, params json.RawMessage) (any, error) {
+	var p ToolCallParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, &JSONRPCError{Code: codeInvalidParams, Message: "invalid params: " + err.Error()}
+	}
+	if !s.registry.HasTool(p.Name) {
+		return nil, &JSONRPCError{Code: codeInvalidParams, Message: "unknown tool: " + p.Name}
+	}
+	args := p.Arguments
+	if len(args) == 0 {
+		args = json.RawMessage("{}")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	s.mu.Lock()
+	s.calls[id] = cancel
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.calls, id)
+		s.mu.Unlock()
+	}()
+
+	release, err := s.acquire(ctx, p.Name)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	output, err := s.registry.Execute(ctx, p.Name, args)
+	if err != nil && output == "" {
+		output = "Error: " + err.Error()
+	}
+	return ToolCallResult{
+		Content: []ToolResultContent{{Type: "text", Text: output}},
+		// Tools report most failures as text starting with "Error:".
+		IsError: err != nil || strings.HasPrefix(output, "Error:"),
+	}, nil
+}
+
+// acquire waits until another call of the tool may run, as the loop
+// would allow, and returns a function that releases the slot.
+func (s *Server) acquire(ctx context.Context, name string) (func(), error) {
+	s.mu.Lock()
+	slot := s.slots[name]
+	if slot == nil {
+		slot = make(chan struct{}, s.registry.MaxParallel(name))
+		s.slots[name] = slot
+	}
+	s.mu.Unlock()
+
+	select {
+	case slot <- struct{}{}:
+		return func() { <-slot }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// cancel stops the call named in a notifications/cancelled. Request IDs
+// may be numbers or strings, so they're compared as raw JSON.
+func (s *Server) cancel(params json.RawMessage) {
+	var p struct {
+		RequestID json.RawMessage `json:"requestId"`
+	}
+	if json.Unmarshal(params, &p) != nil {
+		return
+	}
+	s.mu.Lock()
+	cancel := s.calls[string(p.RequestID)]
+	s.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+}
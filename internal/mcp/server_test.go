@@ -0,0 +1,170 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anthropics/claude-code-go/internal/tools"
+)
+
+// echoTool is a tool that returns its "text" input, or waits for its
+// context to be cancelled when "block" is set.
+type echoTool struct {
+	started chan struct{}
+}
+
+func (t *echoTool) Name() string        { return "Echo" }
+func (t *echoTool) Description() string { return "Echoes its input" }
+func (t *echoTool) InputSchema() json.RawMessage {
+	return json.RawMessage(`{"type":"object","properties":{"text":{"type":"string"}}}`)
+}
+func (t *echoTool) RequiresPermission(json.RawMessage) bool { return false }
+
+func (t *echoTool) Execute(ctx context.Context, input json.RawMessage) (string, error) {
+	var in struct {
+		Text  string `json:"text"`
+		Block bool   `json:"block"`
+	}
+	json.Unmarshal(input, &in)
+	if in.Block {
+		close(t.started)
+		<-ctx.Done()
+		return "Error: cancelled", nil
+	}
+	return in.Text, nil
+}
+
+func newTestServer() (*Server, *echoTool) {
+	tool := &echoTool{started: make(chan struct{})}
+	registry := tools.NewRegistry(nil)
+	registry.Register(tool)
+	return NewServer("claude", "test", registry), tool
+}
+
+// serveLines runs the server over the given request lines and returns its
+// responses by ID.
+func serveLines(t *testing.T, s *Server, lines ...string) map[string]testResponse {
+	t.Helper()
+	var out strings.Builder
+	if err := s.Serve(context.Background(), strings.NewReader(strings.Join(lines, "\n")+"\n"), &out); err != nil {
+		t.Fatalf("Serve: %v", err)
+	}
+	return decodeResponses(t, out.String())
+}
+
+// testResponse is a response as the client decodes it.
+type testResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *JSONRPCError   `json:"error"`
+}
+
+func decodeResponses(t *testing.T, out string) map[string]testResponse {
+	t.Helper()
+	resps := make(map[string]testResponse)
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		var msg struct {
+			ID json.RawMessage `json:"id"`
+			testResponse
+		}
+		if err := json.Unmarshal([]byte(line), &msg); err != nil {
+			t.Fatalf("bad response %q: %v", line, err)
+		}
+		resps[string(msg.ID)] = msg.testResponse
+	}
+	return resps
+}
+
+func TestServer_InitializeAndListTools(t *testing.T) {
+	s, _ := newTestServer()
+	resps := serveLines(t, s,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"desktop","version":"1"}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":"list","method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"resources/list"}`,
+	)
+	if len(resps) != 3 {
+		t.Fatalf("got %d responses, want 3 (none for the notification): %v", len(resps), resps)
+	}
+
+	var init InitializeResult
+	json.Unmarshal(resps["1"].Result, &init)
+	if init.ProtocolVersion != "2025-03-26" || init.ServerInfo.Name != "claude" || init.Capabilities.Tools == nil {
+		t.Errorf("initialize result = %+v", init)
+	}
+
+	var list ToolsListResult
+	json.Unmarshal(resps[`"list"`].Result, &list)
+	if len(list.Tools) != 1 || list.Tools[0].Name != "Echo" || !strings.Contains(string(list.Tools[0].InputSchema), `"text"`) {
+		t.Errorf("tools/list result = %+v", list)
+	}
+
+	if err := resps["3"].Error; err == nil || err.Code != codeMethodNotFound {
+		t.Errorf("resources/list error = %v, want method not found", err)
+	}
+}
+
+func TestServer_CallTool(t *testing.T) {
+	s, _ := newTestServer()
+	resps := serveLines(t, s,
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"Echo","arguments":{"text":"hello"}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"Echo","arguments":{"text":"Error: no such file"}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"Agent","arguments":{}}}`,
+	)
+
+	var result ToolCallResult
+	json.Unmarshal(resps["1"].Result, &result)
+	if len(result.Content) != 1 || result.Content[0].Text != "hello" || result.IsError {
+		t.Errorf("call result = %+v", result)
+	}
+
+	result = ToolCallResult{}
+	json.Unmarshal(resps["2"].Result, &result)
+	if !result.IsError {
+		t.Errorf("expected an \"Error:\" result to set isError: %+v", result)
+	}
+
+	if err := resps["3"].Error; err == nil || err.Code != codeInvalidParams || !strings.Contains(err.Message, "unknown tool") {
+		t.Errorf("unknown tool error = %v", err)
+	}
+}
+
+func TestServer_CancelledCall(t *testing.T) {
+	s, tool := newTestServer()
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	done := make(chan error, 1)
+	go func() { done <- s.Serve(context.Background(), inR, outW) }()
+
+	io.WriteString(inW, `{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"Echo","arguments":{"block":true}}}`+"\n")
+	select {
+	case <-tool.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("tool call never started")
+	}
+	io.WriteString(inW, `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":7,"reason":"user pressed Esc"}}`+"\n")
+
+	line := make(chan string, 1)
+	go func() {
+		r := bufio.NewReader(outR)
+		l, _ := r.ReadString('\n')
+		line <- l
+	}()
+	select {
+	case l := <-line:
+		if !strings.Contains(l, `"id":7`) || !strings.Contains(l, "cancelled") {
+			t.Errorf("response after cancel = %s", l)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("cancelled call did not finish")
+	}
+
+	inW.Close()
+	if err := <-done; err != nil {
+		t.Errorf("Serve: %v", err)
+	}
+}
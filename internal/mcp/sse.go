@@ -8,36 +8,48 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
 	"sync"
 )
 
-// SSETransport communicates with an MCP server over HTTP using
-// Server-Sent Events for responses.
+// SSETransport communicates with an MCP server over the legacy HTTP+SSE
+// transport: a long-lived GET stream first announces the endpoint to POST
+// messages to in an "endpoint" event, then carries the server's responses
+// as "message" events.
 type SSETransport struct {
 	baseURL    string
 	client     *http.Client
 	mu         sync.Mutex
 	endpointCh chan string // receives the messages endpoint from the SSE stream
-	endpoint   string     // resolved endpoint for sending messages
+	endpoint   string      // resolved endpoint for sending messages
+	pending    pendingCalls
+	handler    IncomingHandler
+	done       chan struct{} // closed when the SSE stream ends
 	cancel     context.CancelFunc
 	closed     bool
 }
 
 // NewSSETransport creates an SSE transport that connects to the given URL.
-// The URL should be the SSE endpoint of the MCP server.
-func NewSSETransport(url string) *SSETransport {
+// The URL should be the SSE endpoint of the MCP server. The headers are
+// sent on the stream and every POST.
+func NewSSETransport(url string, headers map[string]string) *SSETransport {
 	return &SSETransport{
 		baseURL:    url,
-		client:     &http.Client{},
+		client:     newHeaderClient(headers),
 		endpointCh: make(chan string, 1),
+		done:       make(chan struct{}),
 	}
 }
 
 // Connect establishes the SSE connection and discovers the messages endpoint.
+// ctx bounds the wait for the endpoint; the stream itself stays open until
+// Close.
 func (t *SSETransport) Connect(ctx context.Context) error {
-	connCtx, cancel := context.WithCancel(ctx)
+	connCtx, cancel := context.WithCancel(context.Background())
+	t.mu.Lock()
 	t.cancel = cancel
+	t.mu.Unlock()
 
 	req, err := http.NewRequestWithContext(connCtx, "GET", t.baseURL, nil)
 	if err != nil {
@@ -65,184 +77,209 @@ func (t *SSETransport) Connect(ctx context.Context) error {
 	// Wait for the endpoint event.
 	select {
 	case endpoint := <-t.endpointCh:
+		t.mu.Lock()
 		t.endpoint = endpoint
+		t.mu.Unlock()
 		return nil
+	case <-t.done:
+		cancel()
+		return fmt.Errorf("SSE stream ended before endpoint event")
 	case <-ctx.Done():
 		cancel()
-		resp.Body.Close()
 		return ctx.Err()
 	}
 }
 
-// readSSEStream reads SSE events from the response body.
-// It looks for an "endpoint" event to resolve the messages URL.
+// SetIncomingHandler sets the handler for requests and notifications
+// from the server.
+func (t *SSETransport) SetIncomingHandler(h IncomingHandler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.handler = h
+}
+
+// readSSEStream reads SSE events from the response body, resolving the
+// "endpoint" event to the messages URL, delivering responses to the
+// requests waiting for them, and passing server requests and
+// notifications to the incoming handler.
 func (t *SSETransport) readSSEStream(body io.ReadCloser) {
+	defer close(t.done)
 	defer body.Close()
 
 	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
 	var eventType string
+	var data strings.Builder
+
+	dispatch := func() {
+		defer func() { eventType = ""; data.Reset() }()
+		switch eventType {
+		case "endpoint":
+			endpoint, err := resolveEndpoint(t.baseURL, strings.TrimSpace(data.String()))
+			if err != nil {
+				return
+			}
+			select {
+			case t.endpointCh <- endpoint:
+			default:
+			}
+		case "message", "":
+			var msg serverMessage
+			if data.Len() == 0 || json.Unmarshal([]byte(data.String()), &msg) != nil {
+				return
+			}
+			if msg.Method != "" {
+				go t.handleRequest(&msg)
+			} else if resp := msg.response(); resp != nil {
+				t.pending.deliver(resp)
+			}
+		}
+	}
 
 	for scanner.Scan() {
 		line := scanner.Text()
-
-		if strings.HasPrefix(line, "event:") {
+		switch {
+		case line == "":
+			dispatch()
+		case strings.HasPrefix(line, "event:"):
 			eventType = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
-			continue
-		}
-
-		if strings.HasPrefix(line, "data:") {
-			data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
-
-			if eventType == "endpoint" {
-				// Resolve relative URL against base.
-				endpoint := data
-				if !strings.HasPrefix(endpoint, "http") {
-					// Build absolute URL from base URL.
-					base := t.baseURL
-					if idx := strings.LastIndex(base, "/"); idx > 8 { // after "https://"
-						base = base[:idx]
-					}
-					endpoint = base + "/" + strings.TrimPrefix(endpoint, "/")
-				}
-				select {
-				case t.endpointCh <- endpoint:
-				default:
-				}
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
 			}
-			eventType = ""
-			continue
-		}
-
-		if line == "" {
-			eventType = ""
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
 		}
 	}
+	dispatch()
 }
 
-// Send posts a JSON-RPC request to the server's messages endpoint
-// and reads the response from the SSE stream or inline response.
-func (t *SSETransport) Send(ctx context.Context, req *JSONRPCRequest) (*JSONRPCResponse, error) {
+// handleRequest runs the incoming handler for a server request or
+// notification and POSTs the response, if any.
+func (t *SSETransport) handleRequest(msg *serverMessage) {
 	t.mu.Lock()
-	defer t.mu.Unlock()
-
-	if t.endpoint == "" {
-		return nil, fmt.Errorf("SSE transport not connected (no endpoint)")
+	h := t.handler
+	t.mu.Unlock()
+	data := handleIncoming(context.Background(), h, msg)
+	if data == nil {
+		return
 	}
-
-	data, err := json.Marshal(req)
-	if err != nil {
-		return nil, fmt.Errorf("marshal request: %w", err)
+	if resp, err := t.postData(context.Background(), data); err == nil {
+		resp.Body.Close()
 	}
+}
 
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", t.endpoint, bytes.NewReader(data))
+// resolveEndpoint resolves the endpoint event's URL, which is usually a
+// path such as "/messages?sessionId=...", against the SSE URL.
+func resolveEndpoint(baseURL, endpoint string) (string, error) {
+	base, err := url.Parse(baseURL)
 	if err != nil {
-		return nil, fmt.Errorf("create POST request: %w", err)
+		return "", err
 	}
-	httpReq.Header.Set("Content-Type", "application/json")
-
-	httpResp, err := t.client.Do(httpReq)
+	ref, err := url.Parse(endpoint)
 	if err != nil {
-		return nil, fmt.Errorf("POST request: %w", err)
+		return "", err
 	}
-	defer httpResp.Body.Close()
+	return base.ResolveReference(ref).String(), nil
+}
 
-	if httpResp.StatusCode != http.StatusOK && httpResp.StatusCode != http.StatusAccepted {
-		body, _ := io.ReadAll(httpResp.Body)
-		return nil, fmt.Errorf("POST response status %d: %s", httpResp.StatusCode, string(body))
+// Send posts a JSON-RPC request to the server's messages endpoint and
+// waits for the response. Servers normally answer 202 Accepted and send
+// the response on the SSE stream; some answer inline in the POST body.
+func (t *SSETransport) Send(ctx context.Context, req *JSONRPCRequest) (*JSONRPCResponse, error) {
+	var ch chan *JSONRPCResponse
+	if req.ID != nil {
+		ch = t.pending.add(*req.ID)
+		defer t.pending.remove(*req.ID)
 	}
 
-	// The response may come as a direct JSON body or via SSE.
-	contentType := httpResp.Header.Get("Content-Type")
-
-	if strings.Contains(contentType, "text/event-stream") {
-		return t.readSSEResponse(httpResp.Body)
+	httpResp, err := t.post(ctx, req)
+	if err != nil {
+		return nil, err
 	}
+	defer httpResp.Body.Close()
 
-	// Direct JSON response.
+	if strings.Contains(httpResp.Header.Get("Content-Type"), "text/event-stream") {
+		return readHTTPEventStream(httpResp.Body, req.ID, func(msg *serverMessage) { go t.handleRequest(msg) })
+	}
 	body, err := io.ReadAll(httpResp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("read response body: %w", err)
 	}
-
-	// Some servers return 202 Accepted with no body for async handling.
-	if len(body) == 0 {
-		return nil, fmt.Errorf("empty response body")
+	if len(bytes.TrimSpace(body)) > 0 && strings.Contains(httpResp.Header.Get("Content-Type"), "application/json") {
+		var resp JSONRPCResponse
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return nil, fmt.Errorf("unmarshal response: %w", err)
+		}
+		return &resp, nil
 	}
 
-	var resp JSONRPCResponse
-	if err := json.Unmarshal(body, &resp); err != nil {
-		return nil, fmt.Errorf("unmarshal response: %w", err)
+	select {
+	case resp := <-ch:
+		return resp, nil
+	case <-t.done:
+		return nil, fmt.Errorf("SSE stream closed before response")
+	case <-ctx.Done():
+		return nil, ctx.Err()
 	}
-
-	return &resp, nil
 }
 
-// readSSEResponse reads a single JSON-RPC response from an SSE stream.
-func (t *SSETransport) readSSEResponse(body io.Reader) (*JSONRPCResponse, error) {
-	scanner := bufio.NewScanner(body)
-	var eventType string
-
-	for scanner.Scan() {
-		line := scanner.Text()
-
-		if strings.HasPrefix(line, "event:") {
-			eventType = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
-			continue
-		}
-
-		if strings.HasPrefix(line, "data:") {
-			data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
-
-			if eventType == "message" || eventType == "" {
-				var resp JSONRPCResponse
-				if err := json.Unmarshal([]byte(data), &resp); err != nil {
-					continue // Skip non-JSON data events.
-				}
-				return &resp, nil
-			}
-			eventType = ""
-			continue
-		}
+// Done returns a channel that is closed when the SSE stream ends.
+func (t *SSETransport) Done() <-chan struct{} {
+	return t.done
+}
 
-		if line == "" {
-			eventType = ""
-		}
+// Notify sends a JSON-RPC notification via POST.
+func (t *SSETransport) Notify(ctx context.Context, req *JSONRPCRequest) error {
+	httpResp, err := t.post(ctx, req)
+	if err != nil {
+		return err
 	}
+	httpResp.Body.Close()
+	return nil
+}
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("read SSE response: %w", err)
+// post sends one JSON-RPC message to the messages endpoint.
+func (t *SSETransport) post(ctx context.Context, msg *JSONRPCRequest) (*http.Response, error) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
 	}
-
-	return nil, fmt.Errorf("SSE stream ended without response")
+	resp, err := t.postData(ctx, data)
+	if err != nil {
+		return nil, fmt.Errorf("POST %s: %w", msg.Method, err)
+	}
+	return resp, nil
 }
 
-// Notify sends a JSON-RPC notification via POST.
-func (t *SSETransport) Notify(ctx context.Context, req *JSONRPCRequest) error {
+// postData POSTs an encoded JSON-RPC message to the messages endpoint and
+// returns the response if its status is a success.
+func (t *SSETransport) postData(ctx context.Context, data []byte) (*http.Response, error) {
 	t.mu.Lock()
-	defer t.mu.Unlock()
-
-	if t.endpoint == "" {
-		return fmt.Errorf("SSE transport not connected (no endpoint)")
+	endpoint, closed := t.endpoint, t.closed
+	t.mu.Unlock()
+	if closed {
+		return nil, fmt.Errorf("SSE transport closed")
 	}
-
-	data, err := json.Marshal(req)
-	if err != nil {
-		return fmt.Errorf("marshal notification: %w", err)
+	if endpoint == "" {
+		return nil, fmt.Errorf("SSE transport not connected (no endpoint)")
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", t.endpoint, bytes.NewReader(data))
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(data))
 	if err != nil {
-		return fmt.Errorf("create POST request: %w", err)
+		return nil, fmt.Errorf("create POST request: %w", err)
 	}
 	httpReq.Header.Set("Content-Type", "application/json")
 
 	httpResp, err := t.client.Do(httpReq)
 	if err != nil {
-		return fmt.Errorf("POST notification: %w", err)
+		return nil, err
 	}
-	httpResp.Body.Close()
-
-	return nil
+	if httpResp.StatusCode < 200 || httpResp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(httpResp.Body, 4096))
+		httpResp.Body.Close()
+		return nil, fmt.Errorf("response status %d: %s", httpResp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return httpResp, nil
 }
 
 // Close shuts down the SSE transport.
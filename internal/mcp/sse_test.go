@@ -0,0 +1,122 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// legacySSE is a minimal HTTP+SSE MCP server: GET /sse opens the event
+// stream and announces /messages, and each POST to /messages is
+// acknowledged with 202 while its response goes out on the stream.
+// Anything sent on events is written to the stream, the client's
+// responses to server requests arrive on replies, and the methods of its
+// notifications arrive on notifications.
+type legacySSE struct {
+	*httptest.Server
+	events        chan string
+	replies       chan serverMessage
+	notifications chan string
+}
+
+func legacySSEServer(t *testing.T) *legacySSE {
+	t.Helper()
+	events := make(chan string, 8)
+	replies := make(chan serverMessage, 8)
+	notifications := make(chan string, 8)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/mcp/sse", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: endpoint\ndata: /mcp/messages?sessionId=abc\n\n")
+		w.(http.Flusher).Flush()
+		for {
+			select {
+			case ev := <-events:
+				fmt.Fprint(w, ev)
+				w.(http.Flusher).Flush()
+			case <-r.Context().Done():
+				return
+			}
+		}
+	})
+	mux.HandleFunc("/mcp/messages", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("sessionId") != "abc" {
+			http.Error(w, "unknown session", http.StatusNotFound)
+			return
+		}
+		var req serverMessage
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		if req.Method == "" {
+			replies <- req
+			return
+		}
+		var result any
+		switch req.Method {
+		case "initialize":
+			result = InitializeResult{
+				ProtocolVersion: ProtocolVersion,
+				Capabilities:    ServerCapabilities{Tools: &ToolsCapability{}},
+				ServerInfo:      ServerInfo{Name: "sse-server", Version: "1.0.0"},
+			}
+		case "tools/list":
+			result = map[string]any{"tools": []MCPToolDef{{Name: "search", InputSchema: json.RawMessage(`{}`)}}}
+		default:
+			notifications <- req.Method
+			return // notifications get no response
+		}
+		data, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": result})
+		events <- "event: message\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/message\"}\n\n"
+		events <- "event: message\ndata: " + string(data) + "\n\n"
+	})
+	return &legacySSE{Server: httptest.NewServer(mux), events: events, replies: replies, notifications: notifications}
+}
+
+func TestSSETransport_Legacy(t *testing.T) {
+	ts := legacySSEServer(t)
+	defer ts.Close()
+
+	m := NewManager("/tmp")
+	ctx := context.Background()
+	client, err := m.startServer(ctx, "remote", ServerConfig{Type: "sse", URL: ts.URL + "/mcp/sse"})
+	if err != nil {
+		t.Fatalf("startServer error: %v", err)
+	}
+	defer client.Close()
+
+	if client.ServerInfoResult().Name != "sse-server" {
+		t.Errorf("server name = %q, want %q", client.ServerInfoResult().Name, "sse-server")
+	}
+	tools, err := client.ListTools(ctx)
+	if err != nil {
+		t.Fatalf("ListTools error: %v", err)
+	}
+	if len(tools) != 1 || tools[0].Name != "search" {
+		t.Errorf("tools = %+v, want one tool named search", tools)
+	}
+}
+
+func TestResolveEndpoint(t *testing.T) {
+	tests := []struct {
+		base, endpoint, want string
+	}{
+		{"https://example.com/mcp/sse", "/messages?sessionId=1", "https://example.com/messages?sessionId=1"},
+		{"https://example.com/mcp/sse", "messages?sessionId=1", "https://example.com/mcp/messages?sessionId=1"},
+		{"https://example.com/sse", "https://other.example.com/m", "https://other.example.com/m"},
+	}
+	for _, tt := range tests {
+		got, err := resolveEndpoint(tt.base, tt.endpoint)
+		if err != nil {
+			t.Fatalf("resolveEndpoint(%q, %q) error: %v", tt.base, tt.endpoint, err)
+		}
+		if got != tt.want {
+			t.Errorf("resolveEndpoint(%q, %q) = %q, want %q", tt.base, tt.endpoint, got, tt.want)
+		}
+	}
+}
@@ -2,7 +2,6 @@ package mcp
 
 import (
 	"bufio"
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -14,21 +13,32 @@ import (
 )
 
 // StdioTransport communicates with an MCP server subprocess via stdin/stdout.
+// A reader goroutine routes responses on stdout to the requests waiting for
+// them and hands server requests and notifications to the incoming handler.
 type StdioTransport struct {
-	cmd    *exec.Cmd
-	stdin  io.WriteCloser
-	stdout *bufio.Scanner
-	stderr bytes.Buffer
-	mu     sync.Mutex
-	done   chan struct{}
+	cmd      *exec.Cmd
+	stdin    io.WriteCloser
+	stdout   *bufio.Scanner
+	stderr   *ServerLog // this process's stderr, for error messages
+	mu       sync.Mutex // serializes writes to stdin
+	pending  pendingCalls
+	handler  IncomingHandler
+	done     chan struct{} // closed when the subprocess exits
+	readDone chan struct{} // closed when stdout is exhausted
+	readErr  error         // why stdout ended; set before readDone closes
 }
 
 // NewStdioTransport starts an MCP server subprocess and returns a transport.
 // The subprocess is started with the given command, args, and environment.
-// The cwd parameter sets the working directory for the subprocess.
-func NewStdioTransport(command string, args []string, env map[string]string, cwd string) (*StdioTransport, error) {
+// The cwd parameter sets the working directory for the subprocess. Its
+// stderr is also copied to log, if not nil, which outlives restarts.
+func NewStdioTransport(command string, args []string, env map[string]string, cwd string, log *ServerLog) (*StdioTransport, error) {
 	cmd := exec.Command(command, args...)
 	cmd.Dir = cwd
+	setProcessGroup(cmd)
+	// Don't wait on stderr forever if a child the server left behind
+	// still holds it open.
+	cmd.WaitDelay = time.Second
 
 	// Merge environment: inherit current env, override with server-specific vars.
 	cmdEnv := os.Environ()
@@ -49,14 +59,19 @@ func NewStdioTransport(command string, args []string, env map[string]string, cwd
 	}
 
 	t := &StdioTransport{
-		cmd:    cmd,
-		stdin:  stdin,
-		stdout: bufio.NewScanner(stdout),
-		done:   make(chan struct{}),
+		cmd:      cmd,
+		stdin:    stdin,
+		stdout:   bufio.NewScanner(stdout),
+		stderr:   newServerLog(),
+		done:     make(chan struct{}),
+		readDone: make(chan struct{}),
 	}
 
 	// Capture stderr for diagnostics.
-	cmd.Stderr = &t.stderr
+	cmd.Stderr = t.stderr
+	if log != nil {
+		cmd.Stderr = io.MultiWriter(t.stderr, log)
+	}
 
 	// Increase scanner buffer for large JSON responses (10MB).
 	t.stdout.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
@@ -71,23 +86,64 @@ func NewStdioTransport(command string, args []string, env map[string]string, cwd
 		cmd.Wait()
 		close(t.done)
 	}()
+	go t.readLoop()
 
 	return t, nil
 }
 
-// Send writes a JSON-RPC request to stdin and reads the response from stdout.
-func (t *StdioTransport) Send(ctx context.Context, req *JSONRPCRequest) (*JSONRPCResponse, error) {
+// SetIncomingHandler sets the handler for requests and notifications
+// from the server.
+func (t *StdioTransport) SetIncomingHandler(h IncomingHandler) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
+	t.handler = h
+}
+
+// readLoop reads messages from stdout until it is closed. Lines that
+// aren't JSON-RPC, such as stray log output, are skipped.
+func (t *StdioTransport) readLoop() {
+	defer close(t.readDone)
+	for t.stdout.Scan() {
+		var msg serverMessage
+		if err := json.Unmarshal(t.stdout.Bytes(), &msg); err != nil {
+			continue
+		}
+		if msg.Method != "" {
+			go t.handleRequest(&msg)
+			continue
+		}
+		if resp := msg.response(); resp != nil {
+			t.pending.deliver(resp)
+		}
+	}
+	t.readErr = t.stdout.Err()
+	if t.readErr == nil {
+		t.readErr = io.EOF
+	}
+}
 
+// handleRequest runs the incoming handler for a server request or
+// notification and writes the response, if any, to stdin.
+func (t *StdioTransport) handleRequest(msg *serverMessage) {
+	t.mu.Lock()
+	h := t.handler
+	t.mu.Unlock()
+	data := handleIncoming(context.Background(), h, msg)
+	if data == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stdin.Write(append(data, '\n'))
+}
+
+// Send writes a JSON-RPC request to stdin and waits for the response on
+// stdout.
+func (t *StdioTransport) Send(ctx context.Context, req *JSONRPCRequest) (*JSONRPCResponse, error) {
 	// Check if process is still alive.
 	select {
 	case <-t.done:
-		stderrStr := t.stderr.String()
-		if stderrStr != "" {
-			return nil, fmt.Errorf("subprocess exited: %s", stderrStr)
-		}
-		return nil, fmt.Errorf("subprocess exited")
+		return nil, t.exitError()
 	default:
 	}
 
@@ -97,52 +153,45 @@ func (t *StdioTransport) Send(ctx context.Context, req *JSONRPCRequest) (*JSONRP
 		return nil, fmt.Errorf("marshal request: %w", err)
 	}
 
-	data = append(data, '\n')
-	if _, err := t.stdin.Write(data); err != nil {
-		return nil, fmt.Errorf("write to stdin: %w", err)
+	var respCh chan *JSONRPCResponse
+	if req.ID != nil {
+		respCh = t.pending.add(*req.ID)
+		defer t.pending.remove(*req.ID)
 	}
 
-	// Read response line from stdout, respecting context cancellation.
-	type scanResult struct {
-		line []byte
-		err  error
+	t.mu.Lock()
+	_, err = t.stdin.Write(append(data, '\n'))
+	t.mu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("write to stdin: %w", err)
 	}
-	resultCh := make(chan scanResult, 1)
-
-	go func() {
-		if t.stdout.Scan() {
-			// Copy the bytes since the scanner reuses the buffer.
-			line := make([]byte, len(t.stdout.Bytes()))
-			copy(line, t.stdout.Bytes())
-			resultCh <- scanResult{line: line}
-		} else {
-			err := t.stdout.Err()
-			if err == nil {
-				err = io.EOF
-			}
-			resultCh <- scanResult{err: err}
-		}
-	}()
 
 	select {
 	case <-ctx.Done():
 		return nil, ctx.Err()
-	case result := <-resultCh:
-		if result.err != nil {
-			stderrStr := t.stderr.String()
-			if stderrStr != "" {
-				return nil, fmt.Errorf("read stdout: %w (stderr: %s)", result.err, stderrStr)
-			}
-			return nil, fmt.Errorf("read stdout: %w", result.err)
+	case resp := <-respCh:
+		return resp, nil
+	case <-t.readDone:
+		stderrStr := t.stderr.String()
+		if stderrStr != "" {
+			return nil, fmt.Errorf("read stdout: %w (stderr: %s)", t.readErr, stderrStr)
 		}
+		return nil, fmt.Errorf("read stdout: %w", t.readErr)
+	}
+}
 
-		var resp JSONRPCResponse
-		if err := json.Unmarshal(result.line, &resp); err != nil {
-			return nil, fmt.Errorf("unmarshal response: %w (raw: %s)", err, string(result.line))
-		}
+// Done returns a channel that is closed when the subprocess exits.
+func (t *StdioTransport) Done() <-chan struct{} {
+	return t.done
+}
 
-		return &resp, nil
+// exitError describes the subprocess exiting, with its stderr if any.
+func (t *StdioTransport) exitError() error {
+	stderrStr := t.stderr.String()
+	if stderrStr != "" {
+		return fmt.Errorf("subprocess exited: %s", stderrStr)
 	}
+	return fmt.Errorf("subprocess exited")
 }
 
 // Notify writes a JSON-RPC notification to stdin (no response expected).
@@ -163,7 +212,8 @@ func (t *StdioTransport) Notify(ctx context.Context, req *JSONRPCRequest) error
 	return nil
 }
 
-// Close gracefully shuts down the subprocess.
+// Close gracefully shuts down the subprocess, then kills its process
+// group so no children it started outlive it.
 func (t *StdioTransport) Close() error {
 	// Close stdin to signal EOF to the subprocess.
 	t.stdin.Close()
@@ -171,13 +221,9 @@ func (t *StdioTransport) Close() error {
 	// Wait for the process to exit with a timeout.
 	select {
 	case <-t.done:
-		return nil
 	case <-time.After(5 * time.Second):
-		// Force kill if graceful shutdown fails.
-		if t.cmd.Process != nil {
-			t.cmd.Process.Kill()
-		}
-		<-t.done
-		return nil
 	}
+	killProcessGroup(t.cmd)
+	<-t.done
+	return nil
 }
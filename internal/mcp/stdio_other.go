@@ -0,0 +1,21 @@
+//go:build !unix
+
+package mcp
+
+import "os/exec"
+
+// setProcessGroup is a no-op on non-Unix platforms.
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// killProcessGroup kills only the server process on non-Unix platforms.
+func killProcessGroup(cmd *exec.Cmd) {
+	if cmd.Process != nil {
+		cmd.Process.Kill()
+	}
+}
+
+// limitCommand returns the command unchanged; limits are only applied on
+// Unix.
+func limitCommand(command string, args []string, limits *ProcessLimits) (string, []string) {
+	return command, args
+}
@@ -0,0 +1,43 @@
+//go:build unix
+
+package mcp
+
+import (
+	"fmt"
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup puts the server in its own process group, so that
+// killProcessGroup reaches any children it started and the terminal's
+// Ctrl-C doesn't.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// killProcessGroup kills the server and everything left in its process
+// group, even after the server itself has exited.
+func killProcessGroup(cmd *exec.Cmd) {
+	if cmd.Process != nil {
+		syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
+
+// limitCommand wraps the command in a shell that sets the limits with
+// ulimit and then execs it. Memory is limited as address space, which
+// Node and Go servers reserve far beyond what they use (see ProcessLimits);
+// an RSS limit would need a cgroup, which we can't count on creating.
+func limitCommand(command string, args []string, limits *ProcessLimits) (string, []string) {
+	if limits == nil || (limits.CPUSeconds == 0 && limits.MemoryMB == 0) {
+		return command, args
+	}
+	script := ""
+	if limits.CPUSeconds > 0 {
+		script += fmt.Sprintf("ulimit -t %d && ", limits.CPUSeconds)
+	}
+	if limits.MemoryMB > 0 {
+		script += fmt.Sprintf("ulimit -v %d && ", limits.MemoryMB*1024)
+	}
+	script += `exec "$@"`
+	return "sh", append([]string{"-c", script, "mcp-server", command}, args...)
+}
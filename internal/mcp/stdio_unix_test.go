@@ -0,0 +1,66 @@
+//go:build unix
+
+package mcp
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestStdioTransport_CloseKillsProcessGroup(t *testing.T) {
+	pidFile := filepath.Join(t.TempDir(), "child.pid")
+	// The server starts a child that ignores stdin closing, as a dev
+	// server or watcher might.
+	transport, err := NewStdioTransport("sh", []string{"-c", `sleep 60 & echo $! > "$PID_FILE"; cat >/dev/null`},
+		map[string]string{"PID_FILE": pidFile}, t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("NewStdioTransport error: %v", err)
+	}
+	var pid int
+	eventually(t, "the child to start", func() bool {
+		data, _ := os.ReadFile(pidFile)
+		pid, _ = strconv.Atoi(strings.TrimSpace(string(data)))
+		return pid > 0
+	})
+
+	transport.Close()
+	eventually(t, "the child to be killed", func() bool { return !running(pid) })
+}
+
+// running reports whether the process exists and isn't a zombie waiting
+// to be reaped.
+func running(pid int) bool {
+	if syscall.Kill(pid, 0) != nil {
+		return false
+	}
+	stat, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return true // no /proc; the signal check will have to do
+	}
+	fields := strings.Fields(string(stat[strings.LastIndexByte(string(stat), ')')+1:]))
+	return len(fields) == 0 || fields[0] != "Z"
+}
+
+func TestStdioTransport_Limits(t *testing.T) {
+	command, args := limitCommand("sh", []string{"-c", `echo "limits $(ulimit -t) $(ulimit -v)" >&2; cat >/dev/null`},
+		&ProcessLimits{CPUSeconds: 30, MemoryMB: 2048})
+	transport, err := NewStdioTransport(command, args, nil, t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("NewStdioTransport error: %v", err)
+	}
+	defer transport.Close()
+	eventually(t, "the limits to be reported", func() bool {
+		return strings.Contains(transport.stderr.String(), "limits")
+	})
+	if got := transport.stderr.String(); !strings.Contains(got, "limits 30 2097152") {
+		t.Errorf("stderr = %q, want a 30s CPU limit and 2 GB address space", got)
+	}
+
+	if command, args := limitCommand("server", []string{"--flag"}, &ProcessLimits{}); command != "server" || len(args) != 1 {
+		t.Errorf("limitCommand with no limits = %q %q, want the command unchanged", command, args)
+	}
+}
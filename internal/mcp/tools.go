@@ -8,6 +8,8 @@ import (
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/anthropics/claude-code-go/internal/conversation"
 )
 
 // MCPToolWrapper bridges an MCP server tool to the tools.Tool interface.
@@ -42,11 +44,36 @@ func (w *MCPToolWrapper) RequiresPermission(_ json.RawMessage) bool {
 }
 
 func (w *MCPToolWrapper) Execute(ctx context.Context, input json.RawMessage) (string, error) {
-	result, err := w.client.CallTool(ctx, w.toolName, input)
+	var onProgress func(ProgressParams)
+	if sink := conversation.ToolProgressSink(ctx); sink != nil {
+		onProgress = func(p ProgressParams) {
+			sink(conversation.ToolProgress{Progress: p.Progress, Total: p.Total, Message: p.Message})
+		}
+	}
+	result, err := w.client.CallToolWithProgress(ctx, w.toolName, input, onProgress)
 	if err != nil {
 		return "", err
 	}
+	return toolResultText(result)
+}
 
+// CallTool calls a tool on the named server and returns its text, as
+// "mcp" hooks do. A result the server marks as an error is an error.
+func (m *Manager) CallTool(ctx context.Context, server, tool string, args json.RawMessage) (string, error) {
+	client, ok := m.readyClient(ctx, server)
+	if !ok {
+		return "", fmt.Errorf("MCP server %q is not connected", server)
+	}
+	result, err := client.CallTool(ctx, tool, args)
+	if err != nil {
+		return "", err
+	}
+	return toolResultText(result)
+}
+
+// toolResultText returns the text content of a tool call result, with an
+// error if the server marked it as one.
+func toolResultText(result json.RawMessage) (string, error) {
 	// Parse the tool call result and extract text content.
 	var callResult ToolCallResult
 	if err := json.Unmarshal(result, &callResult); err != nil {
@@ -131,7 +158,7 @@ func (t *ListMcpResourcesTool) Execute(ctx context.Context, input json.RawMessag
 			continue
 		}
 
-		client, ok := t.manager.Client(name)
+		client, ok := t.manager.readyClient(ctx, name)
 		if !ok {
 			continue
 		}
@@ -201,7 +228,7 @@ func (t *ReadMcpResourceTool) Execute(ctx context.Context, input json.RawMessage
 		return "", fmt.Errorf("invalid input: %w", err)
 	}
 
-	client, ok := t.manager.Client(params.Server)
+	client, ok := t.manager.readyClient(ctx, params.Server)
 	if !ok {
 		return "", fmt.Errorf("MCP server %q not found", params.Server)
 	}
@@ -287,7 +314,7 @@ func (t *SubscribeMcpResourceTool) Execute(ctx context.Context, input json.RawMe
 		return "", fmt.Errorf("invalid input: %w", err)
 	}
 
-	client, ok := t.manager.Client(params.Server)
+	client, ok := t.manager.readyClient(ctx, params.Server)
 	if !ok {
 		return "", fmt.Errorf("MCP server %q not found", params.Server)
 	}
@@ -477,7 +504,7 @@ func (t *SubscribePollingTool) Execute(ctx context.Context, input json.RawMessag
 		params.IntervalMs = 5000
 	}
 
-	_, ok := t.manager.Client(params.Server)
+	_, ok := t.manager.readyClient(ctx, params.Server)
 	if !ok {
 		return "", fmt.Errorf("MCP server %q not found", params.Server)
 	}
@@ -492,11 +519,15 @@ func (t *SubscribePollingTool) Execute(ctx context.Context, input json.RawMessag
 		cancel:  cancel,
 	})
 
-	// Start polling in a goroutine.
+	// Start polling in a goroutine. Each result is compared with the
+	// previous one, and changes are reported to the manager's update
+	// listeners; failed polls are skipped.
 	go func() {
 		ticker := time.NewTicker(time.Duration(params.IntervalMs) * time.Millisecond)
 		defer ticker.Stop()
 
+		var last string
+		polled := false
 		for {
 			select {
 			case <-pollCtx.Done():
@@ -507,12 +538,23 @@ func (t *SubscribePollingTool) Execute(ctx context.Context, input json.RawMessag
 					return
 				}
 
+				update := ResourceUpdate{SubscriptionID: subID, Server: params.Server}
+				var err error
 				switch params.Type {
 				case "tool":
-					client.CallTool(pollCtx, params.ToolName, params.Arguments)
+					update.Tool = params.ToolName
+					update.Content, err = pollTool(pollCtx, client, params.ToolName, params.Arguments)
 				case "resource":
-					client.ReadResource(pollCtx, params.URI)
+					update.URI = params.URI
+					update.Content, err = t.manager.ReadResourceText(pollCtx, params.Server, params.URI)
+				}
+				if err != nil {
+					continue
+				}
+				if polled && update.Content != last {
+					t.manager.resourceUpdated(update)
 				}
+				last, polled = update.Content, true
 			}
 		}
 	}()
@@ -528,6 +570,25 @@ func (t *SubscribePollingTool) Execute(ctx context.Context, input json.RawMessag
 	return string(result), nil
 }
 
+// pollTool calls a polled tool and returns its text result.
+func pollTool(ctx context.Context, client *MCPClient, name string, args json.RawMessage) (string, error) {
+	if len(args) == 0 {
+		args = json.RawMessage("{}")
+	}
+	result, err := client.CallTool(ctx, name, args)
+	if err != nil {
+		return "", err
+	}
+	var callResult ToolCallResult
+	if err := json.Unmarshal(result, &callResult); err != nil {
+		return string(result), nil
+	}
+	if callResult.IsError {
+		return "", fmt.Errorf("MCP tool error: %s", extractTexts(callResult.Content))
+	}
+	return extractTexts(callResult.Content), nil
+}
+
 // UnsubscribePollingTool stops polling.
 type UnsubscribePollingTool struct {
 	subscriptions *subscriptionStore
@@ -639,6 +700,14 @@ func (s *subscriptionStore) add(sub subscription) string {
 	return id
 }
 
+func (s *subscriptionStore) get(id string) (subscription, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub, ok := s.subs[id]
+	return sub, ok
+}
+
 func (s *subscriptionStore) remove(id string) (subscription, bool) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
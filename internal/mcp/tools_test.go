@@ -3,6 +3,7 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	"strings"
 	"testing"
 )
 
@@ -149,6 +150,27 @@ func TestMCPToolWrapper_ExecuteError(t *testing.T) {
 	}
 }
 
+func TestManager_CallTool(t *testing.T) {
+	m := NewManager("/tmp")
+	mt := newMockTransport()
+	m.clients["policy"] = NewMCPClient("policy", mt)
+
+	mt.enqueue(resultResponse(t, ToolCallResult{Content: []ToolResultContent{{Type: "text", Text: `{"decision":"approve"}`}}}))
+	text, err := m.CallTool(context.Background(), "policy", "check", json.RawMessage(`{"tool_name":"Bash"}`))
+	if err != nil || text != `{"decision":"approve"}` {
+		t.Errorf("CallTool = %q, %v", text, err)
+	}
+
+	mt.enqueue(resultResponse(t, ToolCallResult{Content: []ToolResultContent{{Type: "text", Text: "denied"}}, IsError: true}))
+	if _, err := m.CallTool(context.Background(), "policy", "check", json.RawMessage(`{}`)); err == nil || !strings.Contains(err.Error(), "denied") {
+		t.Errorf("tool error = %v", err)
+	}
+
+	if _, err := m.CallTool(context.Background(), "nope", "check", json.RawMessage(`{}`)); err == nil || !strings.Contains(err.Error(), "not connected") {
+		t.Errorf("unknown server error = %v", err)
+	}
+}
+
 func TestExtractTexts(t *testing.T) {
 	tests := []struct {
 		name    string
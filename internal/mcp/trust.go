@@ -0,0 +1,195 @@
+package mcp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Trust choices for a project server that hasn't been approved.
+const (
+	TrustApprove = "approve" // run this server
+	TrustReject  = "reject"  // don't run it, and don't ask again
+	TrustAll     = "all"     // run every server in this project's config, now and later
+)
+
+// TrustPrompt asks the user whether to run a project server, returning one
+// of the trust choices.
+type TrustPrompt func(s ConfiguredServer) (string, error)
+
+// TrustStore records the user's decisions about project servers. Servers
+// in a project's .mcp.json come from the checked-out repository, so they
+// only run once approved. A decision
+// covers the server's exact config; if the config changes, the user is
+// asked again. The record lives in the user's home directory, out of
+// reach of the repository.
+type TrustStore struct {
+	path     string
+	Projects map[string]*ProjectTrust `json:"projects"` // keyed by absolute project dir
+}
+
+// ProjectTrust holds the decisions for one project.
+type ProjectTrust struct {
+	TrustAll bool              `json:"trustAll,omitempty"`
+	Approved map[string]string `json:"approved,omitempty"` // server name → config fingerprint
+	Rejected map[string]string `json:"rejected,omitempty"` // server name → config fingerprint
+}
+
+// DefaultTrustPath returns the trust record's path, ~/.claude/mcp-trust.json.
+func DefaultTrustPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".claude", "mcp-trust.json"), nil
+}
+
+// LoadTrustStore reads the trust record at path. A missing file is an
+// empty record.
+func LoadTrustStore(path string) (*TrustStore, error) {
+	s := &TrustStore{path: path, Projects: make(map[string]*ProjectTrust)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if s.Projects == nil {
+		s.Projects = make(map[string]*ProjectTrust)
+	}
+	return s, nil
+}
+
+// Save writes the trust record.
+func (s *TrustStore) Save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, append(data, '\n'), 0600)
+}
+
+// NeedsTrust reports whether a server comes from the project and must be
+// approved before it runs. Local servers are kept in the home directory,
+// like user servers, so only the user can have added them.
+func NeedsTrust(s ConfiguredServer) bool {
+	return s.Scope == ScopeProject
+}
+
+// project returns the decisions for a project, creating them if needed.
+func (s *TrustStore) project(cwd string) *ProjectTrust {
+	key := projectKey(cwd)
+	p := s.Projects[key]
+	if p == nil {
+		p = &ProjectTrust{Approved: make(map[string]string), Rejected: make(map[string]string)}
+		s.Projects[key] = p
+	}
+	if p.Approved == nil {
+		p.Approved = make(map[string]string)
+	}
+	if p.Rejected == nil {
+		p.Rejected = make(map[string]string)
+	}
+	return p
+}
+
+// Record stores a trust choice for a server in the project.
+func (s *TrustStore) Record(cwd, name string, cfg ServerConfig, choice string) {
+	p := s.project(cwd)
+	fp := fingerprint(cfg)
+	delete(p.Approved, name)
+	delete(p.Rejected, name)
+	switch choice {
+	case TrustAll:
+		p.TrustAll = true
+		p.Approved[name] = fp
+	case TrustApprove:
+		p.Approved[name] = fp
+	case TrustReject:
+		p.Rejected[name] = fp
+	}
+}
+
+// Reset forgets every decision for the project.
+func (s *TrustStore) Reset(cwd string) {
+	delete(s.Projects, projectKey(cwd))
+}
+
+// Filter returns cfg without the project servers the user hasn't
+// approved, asking about each server not decided yet. With a nil ask, as
+// in non-interactive runs, undecided servers are left out, as are those
+// after a failed prompt. New decisions are saved.
+func (s *TrustStore) Filter(cwd string, cfg *MCPConfig, ask TrustPrompt) (*MCPConfig, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	scopes := make(map[string]ConfiguredServer)
+	for _, srv := range ListServers(cwd) {
+		scopes[srv.Name] = srv
+	}
+
+	names := make([]string, 0, len(cfg.MCPServers))
+	for name := range cfg.MCPServers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	p := s.project(cwd)
+	out := &MCPConfig{MCPServers: make(map[string]ServerConfig)}
+	changed := false
+	var askErr error
+	for _, name := range names {
+		sc := cfg.MCPServers[name]
+		srv, ok := scopes[name]
+		if !ok || !NeedsTrust(srv) || p.TrustAll || p.Approved[name] == fingerprint(sc) {
+			out.MCPServers[name] = sc
+			continue
+		}
+		if p.Rejected[name] == fingerprint(sc) || ask == nil {
+			continue
+		}
+		choice, err := ask(srv)
+		if err != nil {
+			askErr, ask = err, nil
+			continue
+		}
+		s.Record(cwd, name, sc, choice)
+		changed = true
+		if choice != TrustReject {
+			out.MCPServers[name] = sc
+		}
+	}
+	if changed {
+		if err := s.Save(); err != nil {
+			return out, fmt.Errorf("save MCP server approvals: %w", err)
+		}
+	}
+	return out, askErr
+}
+
+// projectKey identifies a project by its absolute directory.
+func projectKey(cwd string) string {
+	if abs, err := filepath.Abs(cwd); err == nil {
+		return abs
+	}
+	return cwd
+}
+
+// fingerprint identifies a server config, so that an approval doesn't
+// carry over to a changed command or URL.
+func fingerprint(cfg ServerConfig) string {
+	data, _ := json.Marshal(cfg)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
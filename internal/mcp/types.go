@@ -2,7 +2,11 @@
 // to external tool servers via JSON-RPC 2.0 over stdio or SSE transports.
 package mcp
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+)
 
 // JSON-RPC 2.0 types.
 
@@ -37,15 +41,135 @@ func (e *JSONRPCError) Error() string {
 
 // ServerConfig describes how to connect to an MCP server.
 type ServerConfig struct {
-	Command string            `json:"command"`
+	Type    string            `json:"type,omitempty"` // "stdio", "sse", or "http"; inferred if empty
+	Command string            `json:"command,omitempty"`
 	Args    []string          `json:"args,omitempty"`
 	Env     map[string]string `json:"env,omitempty"`
-	URL     string            `json:"url,omitempty"` // for SSE transport
+	URL     string            `json:"url,omitempty"`     // for SSE and HTTP transports
+	Headers map[string]string `json:"headers,omitempty"` // sent with every SSE and HTTP request
+
+	// Command, Args, Env values, URL, and Headers values may refer to
+	// environment variables as ${VAR} or ${VAR:-default}; see Expand.
+
+	// IncludeTools and ExcludeTools limit which of the server's tools are
+	// offered to the model. Entries are tool names as the server reports
+	// them, or glob patterns such as "get_*". With IncludeTools set, only
+	// matching tools are offered; ExcludeTools then removes more.
+	IncludeTools []string `json:"includeTools,omitempty"`
+	ExcludeTools []string `json:"excludeTools,omitempty"`
+
+	// Timeout limits each tool call, in milliseconds, overriding
+	// MCP_TOOL_TIMEOUT.
+	Timeout int `json:"timeout,omitempty"`
+
+	// MaxRestarts caps how many times a stdio server that exits or stops
+	// answering is restarted within ten minutes before it's left stopped.
+	// Zero means the default of 3, and -1 never restarts it.
+	MaxRestarts int `json:"maxRestarts,omitempty"`
+
+	// Limits caps a stdio server's resources. Only applied on Unix.
+	Limits *ProcessLimits `json:"limits,omitempty"`
+}
+
+// ProcessLimits are resource limits for a stdio server process, applied
+// with ulimit. Zero fields are unlimited.
+//
+// MemoryMB limits address space (ulimit -v), not resident memory. Node/V8
+// reserves several GB of address space at startup and fails under most
+// limits, and the Go runtime can too; cap their heaps through Env instead,
+// with NODE_OPTIONS=--max-old-space-size=<MB> or GOMEMLIMIT=<MB>MiB.
+type ProcessLimits struct {
+	CPUSeconds int `json:"cpuSeconds,omitempty"` // CPU time
+	MemoryMB   int `json:"memoryMB,omitempty"`   // address space; see above
+}
+
+// defaultMaxRestarts is the restart cap for servers without MaxRestarts.
+const defaultMaxRestarts = 3
+
+// maxRestarts returns the server's restart cap, or -1 if it's never
+// restarted.
+func (c ServerConfig) maxRestarts() int {
+	if c.MaxRestarts == 0 {
+		return defaultMaxRestarts
+	}
+	return c.MaxRestarts
+}
+
+// AllowsTool reports whether the server's tool is offered to the model
+// under IncludeTools and ExcludeTools.
+func (c ServerConfig) AllowsTool(name string) bool {
+	if len(c.IncludeTools) > 0 && !matchesAny(c.IncludeTools, name) {
+		return false
+	}
+	return !matchesAny(c.ExcludeTools, name)
+}
+
+// matchesAny reports whether name matches any of the glob patterns.
+func matchesAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// TransportType returns the configured transport, inferring "sse" for
+// servers with only a URL and "stdio" otherwise.
+func (c ServerConfig) TransportType() string {
+	switch {
+	case c.Type != "":
+		return c.Type
+	case c.URL != "":
+		return "sse"
+	}
+	return "stdio"
+}
+
+// Validate checks that the config names a known transport and has what
+// that transport needs.
+func (c ServerConfig) Validate() error {
+	switch typ := c.TransportType(); typ {
+	case "http", "sse":
+		if c.URL == "" {
+			return fmt.Errorf("%s server config must have a 'url'", typ)
+		}
+	case "stdio":
+		if c.Command == "" {
+			return fmt.Errorf("stdio server config must have a 'command'")
+		}
+		if len(c.Headers) > 0 {
+			return fmt.Errorf("stdio server config can't have 'headers'")
+		}
+		if c.MaxRestarts < -1 {
+			return fmt.Errorf("'maxRestarts' must be -1 or more")
+		}
+		if l := c.Limits; l != nil && (l.CPUSeconds < 0 || l.MemoryMB < 0) {
+			return fmt.Errorf("'limits' can't be negative")
+		}
+	default:
+		return fmt.Errorf("unknown transport type %q", typ)
+	}
+	if c.TransportType() != "stdio" && (c.MaxRestarts != 0 || c.Limits != nil) {
+		return fmt.Errorf("only stdio servers can have 'maxRestarts' or 'limits'")
+	}
+	for _, patterns := range [][]string{c.IncludeTools, c.ExcludeTools} {
+		for _, p := range patterns {
+			if _, err := path.Match(p, ""); err != nil {
+				return fmt.Errorf("invalid tool pattern %q", p)
+			}
+		}
+	}
+	return nil
 }
 
 // MCPConfig is the top-level .mcp.json structure.
 type MCPConfig struct {
 	MCPServers map[string]ServerConfig `json:"mcpServers"`
+
+	// DisabledMCPServers names servers turned off for the project. Only
+	// read from the local scope.
+	DisabledMCPServers []string `json:"disabledMcpServers,omitempty"`
 }
 
 // MCP protocol types.
@@ -58,7 +182,33 @@ type InitializeParams struct {
 }
 
 // ClientCapabilities advertises what the client supports.
-type ClientCapabilities struct{}
+type ClientCapabilities struct {
+	Roots       *RootsCapability       `json:"roots,omitempty"`
+	Sampling    *SamplingCapability    `json:"sampling,omitempty"`
+	Elicitation *ElicitationCapability `json:"elicitation,omitempty"`
+}
+
+// RootsCapability indicates the client answers roots/list.
+type RootsCapability struct {
+	ListChanged bool `json:"listChanged,omitempty"` // client sends notifications/roots/list_changed
+}
+
+// SamplingCapability indicates the client answers sampling/createMessage.
+type SamplingCapability struct{}
+
+// ElicitationCapability indicates the client answers elicitation/create.
+type ElicitationCapability struct{}
+
+// Root is a directory the client tells servers they may work in.
+type Root struct {
+	URI  string `json:"uri"` // file:// URI
+	Name string `json:"name,omitempty"`
+}
+
+// RootsListResult is the client's response to "roots/list".
+type RootsListResult struct {
+	Roots []Root `json:"roots"`
+}
 
 // ClientInfo identifies the client.
 type ClientInfo struct {
@@ -77,6 +227,7 @@ type InitializeResult struct {
 type ServerCapabilities struct {
 	Tools     *ToolsCapability     `json:"tools,omitempty"`
 	Resources *ResourceCapability  `json:"resources,omitempty"`
+	Prompts   *PromptsCapability   `json:"prompts,omitempty"`
 }
 
 // ToolsCapability indicates the server supports tools.
@@ -90,6 +241,11 @@ type ResourceCapability struct {
 	ListChanged bool `json:"listChanged,omitempty"`
 }
 
+// PromptsCapability indicates the server supports prompts.
+type PromptsCapability struct {
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
 // ServerInfo identifies the server.
 type ServerInfo struct {
 	Name    string `json:"name"`
@@ -112,6 +268,27 @@ type MCPToolDef struct {
 type ToolCallParams struct {
 	Name      string          `json:"name"`
 	Arguments json.RawMessage `json:"arguments,omitempty"`
+	Meta      *RequestMeta    `json:"_meta,omitempty"`
+}
+
+// RequestMeta is the _meta field of a request's params.
+type RequestMeta struct {
+	ProgressToken json.RawMessage `json:"progressToken,omitempty"` // asks for notifications/progress
+}
+
+// ProgressParams are sent by the server in "notifications/progress".
+type ProgressParams struct {
+	ProgressToken json.RawMessage `json:"progressToken"`
+	Progress      float64         `json:"progress"`
+	Total         float64         `json:"total,omitempty"`
+	Message       string          `json:"message,omitempty"`
+}
+
+// CancelledParams are sent in "notifications/cancelled" when the client
+// stops waiting for a request.
+type CancelledParams struct {
+	RequestID int64  `json:"requestId"`
+	Reason    string `json:"reason,omitempty"`
 }
 
 // ToolCallResult is the response to "tools/call".
@@ -154,6 +331,7 @@ type MCPResourceContent struct {
 	URI      string `json:"uri"`
 	MIMEType string `json:"mimeType,omitempty"`
 	Text     string `json:"text,omitempty"`
+	Blob     string `json:"blob,omitempty"` // base64, for binary resources
 }
 
 // ResourceSubscribeParams are sent in a "resources/subscribe" request.
@@ -166,5 +344,50 @@ type ResourceUnsubscribeParams struct {
 	URI string `json:"uri"`
 }
 
+// PromptsListResult is the response to "prompts/list".
+type PromptsListResult struct {
+	Prompts []MCPPrompt `json:"prompts"`
+}
+
+// MCPPrompt describes a prompt template provided by an MCP server.
+type MCPPrompt struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description,omitempty"`
+	Arguments   []MCPPromptArgument `json:"arguments,omitempty"`
+}
+
+// MCPPromptArgument describes an argument a prompt accepts.
+type MCPPromptArgument struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// PromptGetParams are sent in a "prompts/get" request.
+type PromptGetParams struct {
+	Name      string            `json:"name"`
+	Arguments map[string]string `json:"arguments,omitempty"`
+}
+
+// PromptGetResult is the response to "prompts/get".
+type PromptGetResult struct {
+	Description string             `json:"description,omitempty"`
+	Messages    []MCPPromptMessage `json:"messages"`
+}
+
+// MCPPromptMessage is one message of a resolved prompt.
+type MCPPromptMessage struct {
+	Role    string           `json:"role"` // "user" or "assistant"
+	Content MCPPromptContent `json:"content"`
+}
+
+// MCPPromptContent is the content of a prompt message. Embedded resources
+// carry their text in Resource.
+type MCPPromptContent struct {
+	Type     string              `json:"type"` // "text", "image", "audio", "resource"
+	Text     string              `json:"text,omitempty"`
+	Resource *MCPResourceContent `json:"resource,omitempty"`
+}
+
 // MCP protocol version.
 const ProtocolVersion = "2024-11-05"
@@ -128,6 +128,51 @@ func TestServerConfigMarshal(t *testing.T) {
 	}
 }
 
+func TestServerConfigAllowsTool(t *testing.T) {
+	tests := []struct {
+		include, exclude []string
+		tool             string
+		want             bool
+	}{
+		{nil, nil, "anything", true},
+		{[]string{"search", "get_*"}, nil, "get_issue", true},
+		{[]string{"search", "get_*"}, nil, "delete_repo", false},
+		{nil, []string{"delete_*"}, "delete_repo", false},
+		{nil, []string{"delete_*"}, "search", true},
+		{[]string{"get_*"}, []string{"get_secret"}, "get_secret", false},
+		{[]string{"get_*"}, []string{"get_secret"}, "get_issue", true},
+	}
+	for _, tt := range tests {
+		cfg := ServerConfig{Command: "srv", IncludeTools: tt.include, ExcludeTools: tt.exclude}
+		if got := cfg.AllowsTool(tt.tool); got != tt.want {
+			t.Errorf("include %v exclude %v: AllowsTool(%q) = %v, want %v", tt.include, tt.exclude, tt.tool, got, tt.want)
+		}
+	}
+
+	bad := ServerConfig{Command: "srv", ExcludeTools: []string{"get_["}}
+	if err := bad.Validate(); err == nil {
+		t.Error("Validate accepted a malformed tool pattern")
+	}
+}
+
+func TestServerConfigValidateProcessOptions(t *testing.T) {
+	tests := []struct {
+		cfg  ServerConfig
+		want bool // valid
+	}{
+		{ServerConfig{Command: "srv", MaxRestarts: -1, Limits: &ProcessLimits{CPUSeconds: 60, MemoryMB: 512}}, true},
+		{ServerConfig{Command: "srv", MaxRestarts: -2}, false},
+		{ServerConfig{Command: "srv", Limits: &ProcessLimits{MemoryMB: -1}}, false},
+		{ServerConfig{URL: "https://example.com/mcp", MaxRestarts: 5}, false},
+		{ServerConfig{URL: "https://example.com/mcp", Limits: &ProcessLimits{}}, false},
+	}
+	for _, tt := range tests {
+		if err := tt.cfg.Validate(); (err == nil) != tt.want {
+			t.Errorf("Validate(%+v) = %v, want valid %v", tt.cfg, err, tt.want)
+		}
+	}
+}
+
 func TestMCPConfigMarshal(t *testing.T) {
 	cfg := MCPConfig{
 		MCPServers: map[string]ServerConfig{
@@ -213,3 +258,40 @@ func TestInitializeParamsMarshal(t *testing.T) {
 		t.Errorf("clientInfo.name = %v", clientInfo["name"])
 	}
 }
+
+func TestServerConfigExpand(t *testing.T) {
+	env := map[string]string{"API_TOKEN": "s3cret", "HOST": "mcp.example.com", "EMPTY": ""}
+	lookup := func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+	cfg := ServerConfig{
+		Command: "${BIN_DIR:-/usr/local/bin}/server",
+		Args:    []string{"--token", "${API_TOKEN}", "--mode=${EMPTY:-safe}"},
+		Env:     map[string]string{"TOKEN": "${API_TOKEN}", "REGION": "${REGION}"},
+		URL:     "https://${HOST}/mcp",
+		Headers: map[string]string{"Authorization": "Bearer ${API_TOKEN}", "X-Team": "${TEAM}"},
+	}
+	got, missing := cfg.Expand(lookup)
+
+	if got.Command != "/usr/local/bin/server" {
+		t.Errorf("Command = %q", got.Command)
+	}
+	if got.Args[1] != "s3cret" || got.Args[2] != "--mode=safe" {
+		t.Errorf("Args = %q", got.Args)
+	}
+	if got.Env["TOKEN"] != "s3cret" || got.Env["REGION"] != "" {
+		t.Errorf("Env = %v", got.Env)
+	}
+	if got.URL != "https://mcp.example.com/mcp" || got.Headers["Authorization"] != "Bearer s3cret" {
+		t.Errorf("URL = %q, Headers = %v", got.URL, got.Headers)
+	}
+	if len(missing) != 2 || missing[0] != "REGION" || missing[1] != "TEAM" {
+		t.Errorf("missing = %v, want [REGION TEAM]", missing)
+	}
+
+	// The original config is left as written, so it can be saved again.
+	if cfg.Args[1] != "${API_TOKEN}" || cfg.Headers["Authorization"] != "Bearer ${API_TOKEN}" {
+		t.Errorf("Expand modified the config: %+v", cfg)
+	}
+}
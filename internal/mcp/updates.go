@@ -0,0 +1,89 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"unicode/utf8"
+)
+
+// updateMaxBytes caps the polled content included in an update's reminder.
+const updateMaxBytes = 10 * 1024
+
+// ResourceUpdate reports a change seen by a subscription: a subscribed
+// resource the server says was updated, or a polled tool or resource
+// whose result differs from the previous poll.
+type ResourceUpdate struct {
+	SubscriptionID string
+	Server         string
+	URI            string // the resource; "" for a polled tool
+	Tool           string // the polled tool, if any
+	Content        string // the new result of a poll; "" for server notifications
+}
+
+// Target names what changed, as @server:uri for resources.
+func (u ResourceUpdate) Target() string {
+	if u.Tool != "" {
+		return fmt.Sprintf("tool %s on %s", u.Tool, u.Server)
+	}
+	return "@" + u.Server + ":" + u.URI
+}
+
+// Reminder is the text to tell the model about the update.
+func (u ResourceUpdate) Reminder() string {
+	if u.Tool == "" && u.Content == "" {
+		return fmt.Sprintf("The MCP resource %s changed (subscription %s). Read it with ReadMcpResource if the change matters to the task.",
+			u.Target(), u.SubscriptionID)
+	}
+	content := u.Content
+	if len(content) > updateMaxBytes {
+		n := updateMaxBytes
+		for n > 0 && !utf8.RuneStart(content[n]) {
+			n--
+		}
+		content = content[:n] + fmt.Sprintf("\n[truncated: %d of %d bytes shown]", n, len(u.Content))
+	}
+	return fmt.Sprintf("Polling subscription %s saw %s change. The new result:\n\n%s", u.SubscriptionID, u.Target(), content)
+}
+
+// OnResourceUpdate registers fn to be called when a subscription sees a
+// change. It may be called from any goroutine.
+func (m *Manager) OnResourceUpdate(fn func(ResourceUpdate)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.updateListeners = append(m.updateListeners, fn)
+}
+
+// resourceUpdated calls the update listeners.
+func (m *Manager) resourceUpdated(u ResourceUpdate) {
+	m.mu.Lock()
+	listeners := slices.Clone(m.updateListeners)
+	m.mu.Unlock()
+	for _, fn := range listeners {
+		fn(u)
+	}
+}
+
+// resourcesUpdated handles notifications/resources/updated by reporting
+// the change to each subscription to the resource. Updates for resources
+// nobody subscribed to are ignored.
+func (m *Manager) resourcesUpdated(name string) RequestHandler {
+	return func(_ context.Context, params json.RawMessage) (any, error) {
+		var p struct {
+			URI string `json:"uri"`
+		}
+		if json.Unmarshal(params, &p) != nil || p.URI == "" {
+			return nil, nil
+		}
+		ids := globalSubscriptionStore.findByServerURI(name, p.URI)
+		sort.Strings(ids)
+		for _, id := range ids {
+			if sub, ok := globalSubscriptionStore.get(id); ok && sub.subType == "resource" {
+				m.resourceUpdated(ResourceUpdate{SubscriptionID: id, Server: name, URI: p.URI})
+			}
+		}
+		return nil, nil
+	}
+}
@@ -0,0 +1,49 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestManager_ResourcesUpdated(t *testing.T) {
+	m := NewManager("/tmp")
+	var got []ResourceUpdate
+	m.OnResourceUpdate(func(u ResourceUpdate) { got = append(got, u) })
+
+	id := globalSubscriptionStore.add(subscription{server: "docs", uri: "file:///readme.md", subType: "resource"})
+	defer globalSubscriptionStore.remove(id)
+
+	handle := m.resourcesUpdated("docs")
+	handle(context.Background(), json.RawMessage(`{"uri":"file:///readme.md"}`))
+	handle(context.Background(), json.RawMessage(`{"uri":"file:///other.md"}`))
+	m.resourcesUpdated("other")(context.Background(), json.RawMessage(`{"uri":"file:///readme.md"}`))
+
+	if len(got) != 1 {
+		t.Fatalf("updates = %+v, want one for the subscribed resource", got)
+	}
+	want := ResourceUpdate{SubscriptionID: id, Server: "docs", URI: "file:///readme.md"}
+	if got[0] != want {
+		t.Errorf("update = %+v, want %+v", got[0], want)
+	}
+	if r := got[0].Reminder(); !strings.Contains(r, "@docs:file:///readme.md changed") || !strings.Contains(r, "ReadMcpResource") {
+		t.Errorf("Reminder() = %q", r)
+	}
+}
+
+func TestResourceUpdateReminder_Polled(t *testing.T) {
+	u := ResourceUpdate{SubscriptionID: "sub_1", Server: "ci", Tool: "build_status", Content: "passing"}
+	if got := u.Target(); got != "tool build_status on ci" {
+		t.Errorf("Target() = %q", got)
+	}
+	if got := u.Reminder(); !strings.HasSuffix(got, "saw tool build_status on ci change. The new result:\n\npassing") {
+		t.Errorf("Reminder() = %q", got)
+	}
+
+	u.Content = strings.Repeat("é", updateMaxBytes)
+	got := u.Reminder()
+	if !strings.Contains(got, "[truncated: 10240 of 20480 bytes shown]") {
+		t.Errorf("Reminder() of a large result doesn't note the truncation: %q", got[len(got)-80:])
+	}
+}
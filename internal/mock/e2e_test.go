@@ -3,13 +3,19 @@ package mock_test
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/anthropics/claude-code-go/internal/api"
+	"github.com/anthropics/claude-code-go/internal/config"
 	"github.com/anthropics/claude-code-go/internal/conversation"
+	"github.com/anthropics/claude-code-go/internal/hooks"
 	"github.com/anthropics/claude-code-go/internal/mock"
 	"github.com/anthropics/claude-code-go/internal/tools"
 )
@@ -625,3 +631,377 @@ func TestE2E_OnTurnComplete(t *testing.T) {
 		t.Errorf("saved messages = %d, want 2", len(savedMessages))
 	}
 }
+
+// --- E2E: per-turn metadata ---
+
+func TestE2E_TurnMetadata(t *testing.T) {
+	workDir := t.TempDir()
+	writeInput, _ := json.Marshal(map[string]interface{}{
+		"file_path": filepath.Join(workDir, "out.txt"),
+		"content":   "x",
+	})
+
+	_, loop := setupLoop(t, mock.NewScriptedResponder([]*api.MessageResponse{
+		mock.ToolUseResponse("toolu_1", "FileWrite", writeInput, 1),
+		mock.TextResponse("done", 2),
+	}), &collectingHandler{})
+
+	if err := loop.SendMessage(context.Background(), "write it"); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+
+	turns := loop.History().Turns()
+	if len(turns) != 2 {
+		t.Fatalf("turns = %d, want 2", len(turns))
+	}
+	if turns[0].StopReason != api.StopReasonToolUse || turns[1].StopReason != api.StopReasonEndTurn {
+		t.Errorf("stop reasons = %q, %q", turns[0].StopReason, turns[1].StopReason)
+	}
+	if len(turns[0].Tools) != 1 || turns[0].Tools[0] != "FileWrite" {
+		t.Errorf("turn 1 tools = %v, want [FileWrite]", turns[0].Tools)
+	}
+	if len(turns[1].Tools) != 0 {
+		t.Errorf("turn 2 tools = %v, want none", turns[1].Tools)
+	}
+	if turns[0].Model == "" || turns[0].StartedAt.IsZero() {
+		t.Errorf("turn 1 missing model or start time: %+v", turns[0])
+	}
+
+	st := conversation.SummarizeTurns(turns)
+	if st.Turns != 2 || st.ToolCalls != 1 || st.ToolCounts["FileWrite"] != 1 {
+		t.Errorf("unexpected stats: %+v", st)
+	}
+}
+
+// --- E2E: large sub-agent output is condensed ---
+
+func TestE2E_AgentLargeOutputSummarized(t *testing.T) {
+	bigOutput := strings.Repeat("finding: main.go:42 uses a deprecated API\n", 1000)
+	agentInput, _ := json.Marshal(map[string]interface{}{
+		"description":   "scan repo",
+		"prompt":        "find deprecated APIs",
+		"subagent_type": "general-purpose",
+	})
+
+	b := mock.NewBackend(mock.NewScriptedResponder([]*api.MessageResponse{
+		mock.ToolUseResponse("toolu_agent", "Agent", agentInput, 1), // parent
+		mock.TextResponse(bigOutput, 2),                               // sub-agent
+		mock.TextResponse("main.go:42 uses a deprecated API", 3),      // summarizer
+		mock.TextResponse("done", 4),                                  // parent
+	}))
+	t.Cleanup(b.Close)
+	client := b.Client()
+
+	bgStore := tools.NewBackgroundTaskStore()
+	registry := tools.NewRegistry(&tools.AlwaysAllowPermissionHandler{})
+	registry.Register(tools.NewAgentTool(client, nil, nil, nil, bgStore, nil))
+	registry.Register(tools.NewTaskOutputTool(bgStore))
+
+	loop := conversation.NewLoop(conversation.LoopConfig{
+		Client:   client,
+		Tools:    registry.Definitions(),
+		ToolExec: registry,
+		Handler:  &collectingHandler{},
+	})
+	if err := loop.SendMessage(context.Background(), "scan"); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+
+	reqs := b.Requests()
+	if len(reqs) != 4 {
+		t.Fatalf("backend requests = %d, want 4", len(reqs))
+	}
+	if reqs[2].Body.Model != api.ModelClaude45Haiku {
+		t.Errorf("summary model = %q, want %q", reqs[2].Body.Model, api.ModelClaude45Haiku)
+	}
+
+	results := reqs[3].ToolResults()
+	if len(results) != 1 {
+		t.Fatalf("tool results = %d, want 1", len(results))
+	}
+	var out struct {
+		AgentID    string `json:"agentId"`
+		Content    string `json:"content"`
+		Summarized bool   `json:"summarized"`
+	}
+	if err := json.Unmarshal([]byte(mock.ToolResultContent(results[0])), &out); err != nil {
+		t.Fatalf("parsing agent result: %v", err)
+	}
+	if !out.Summarized || len(out.Content) >= len(bigOutput) {
+		t.Errorf("agent result should be condensed, got %d chars", len(out.Content))
+	}
+	if !strings.Contains(out.Content, "TaskOutput") {
+		t.Error("condensed result should point at TaskOutput for the full text")
+	}
+
+	task, ok := bgStore.Get(out.AgentID)
+	if !ok {
+		t.Fatalf("full output not stored for %s", out.AgentID)
+	}
+	if task.Result != bigOutput {
+		t.Error("stored output should be the full sub-agent text")
+	}
+}
+
+// --- E2E: sampling parameters ---
+
+func TestE2E_SamplingParamsSentInRequest(t *testing.T) {
+	b := mock.NewBackend(&mock.StaticResponder{Response: mock.TextResponse("ok", 1)})
+	t.Cleanup(b.Close)
+
+	temp, topP := 0.2, 0.8
+	loop := conversation.NewLoop(conversation.LoopConfig{
+		Client:        b.Client(),
+		Handler:       &collectingHandler{},
+		Temperature:   &temp,
+		TopP:          &topP,
+		StopSequences: []string{"###"},
+	})
+	if err := loop.SendMessage(context.Background(), "hi"); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+
+	req := b.LastRequest().Body
+	if req.Temp == nil || *req.Temp != 0.2 {
+		t.Errorf("temperature = %v, want 0.2", req.Temp)
+	}
+	if req.TopP == nil || *req.TopP != 0.8 {
+		t.Errorf("top_p = %v, want 0.8", req.TopP)
+	}
+	if len(req.StopSeqs) != 1 || req.StopSeqs[0] != "###" {
+		t.Errorf("stop_sequences = %v, want [###]", req.StopSeqs)
+	}
+}
+
+// slowTool sleeps for the requested time and records how many calls ran at
+// once.
+type slowTool struct {
+	name string
+
+	mu            sync.Mutex
+	running, peak int
+}
+
+func (s *slowTool) Name() string                              { return s.name }
+func (s *slowTool) Description() string                       { return "sleeps" }
+func (s *slowTool) InputSchema() json.RawMessage              { return json.RawMessage(`{"type":"object"}`) }
+func (s *slowTool) RequiresPermission(_ json.RawMessage) bool { return false }
+
+func (s *slowTool) Execute(_ context.Context, input json.RawMessage) (string, error) {
+	var in struct {
+		Label string `json:"label"`
+		Ms    int    `json:"ms"`
+	}
+	json.Unmarshal(input, &in)
+	s.mu.Lock()
+	s.running++
+	s.peak = max(s.peak, s.running)
+	s.mu.Unlock()
+	time.Sleep(time.Duration(in.Ms) * time.Millisecond)
+	s.mu.Lock()
+	s.running--
+	s.mu.Unlock()
+	return in.Label, nil
+}
+
+// parallelTool is a slowTool that implements tools.ParallelTool.
+type parallelTool struct{ *slowTool }
+
+func (p parallelTool) MaxParallel() int { return 8 }
+
+func TestE2E_ParallelToolCalls(t *testing.T) {
+	call := func(id, name string, ms int) mock.ToolCall {
+		input, _ := json.Marshal(map[string]any{"label": id, "ms": ms})
+		return mock.ToolCall{ID: id, Name: name, Input: input}
+	}
+	responder := mock.NewScriptedResponder([]*api.MessageResponse{
+		// Later calls finish first, so results must be put back in order.
+		mock.MultiToolUseResponse([]mock.ToolCall{
+			call("a1", "Slow", 80), call("a2", "Slow", 60), call("a3", "Slow", 40), call("a4", "Slow", 20),
+			call("b1", "Seq", 10), call("b2", "Seq", 10),
+		}, 1),
+		mock.TextResponse("done", 2),
+	})
+	b := mock.NewBackend(responder)
+	t.Cleanup(b.Close)
+
+	slow, seq := &slowTool{name: "Slow"}, &slowTool{name: "Seq"}
+	registry := tools.NewRegistry(&tools.AlwaysAllowPermissionHandler{})
+	registry.Register(parallelTool{slow})
+	registry.Register(seq)
+	registry.SetLimits(map[string]config.ToolLimit{"Slow": {MaxConcurrent: 2}})
+
+	loop := conversation.NewLoop(conversation.LoopConfig{
+		Client:   b.Client(),
+		Tools:    registry.Definitions(),
+		ToolExec: registry,
+		Handler:  &collectingHandler{},
+	})
+	if err := loop.SendMessage(context.Background(), "go"); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+
+	var got []string
+	for _, r := range b.Requests()[1].ToolResults() {
+		got = append(got, mock.ToolResultContent(r))
+	}
+	if strings.Join(got, ",") != "a1,a2,a3,a4,b1,b2" {
+		t.Errorf("results = %v, want call order", got)
+	}
+	if slow.peak != 2 {
+		t.Errorf("Slow peak concurrency = %d, want the maxConcurrent limit of 2", slow.peak)
+	}
+	if seq.peak != 1 {
+		t.Errorf("Seq peak concurrency = %d, want 1", seq.peak)
+	}
+}
+
+func TestE2E_SteerRunningLoop(t *testing.T) {
+	var loop *conversation.Loop
+	calls := 0
+	responder := mock.ResponderFunc(func(_ *api.CreateMessageRequest) *api.MessageResponse {
+		calls++
+		switch calls {
+		case 1:
+			loop.Steer("also check b.go")
+			return mock.ToolUseResponse("toolu_1", "FileRead", json.RawMessage(`{"file_path":"/nonexistent/a.go"}`), 1)
+		case 2:
+			loop.Steer("wrap up")
+			return mock.TextResponse("Still looking.", 2)
+		}
+		return mock.TextResponse("Done.", 3)
+	})
+	b, l := setupLoop(t, responder, &collectingHandler{})
+	loop = l
+
+	if err := loop.SendMessage(context.Background(), "look at a.go"); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	reqs := b.Requests()
+	if len(reqs) != 3 {
+		t.Fatalf("got %d requests, want 3", len(reqs))
+	}
+	// A message sent during a tool call rides along with its results.
+	last := reqs[1].Body.Messages[len(reqs[1].Body.Messages)-1]
+	if len(reqs[1].ToolResults()) != 1 || !strings.Contains(string(last.Content), "also check b.go") {
+		t.Errorf("second request's last message = %s", last.Content)
+	}
+	// One sent as the loop finished starts another round.
+	last = reqs[2].Body.Messages[len(reqs[2].Body.Messages)-1]
+	if last.Role != api.RoleUser || !strings.Contains(string(last.Content), "wrap up") {
+		t.Errorf("third request's last message = %s", last.Content)
+	}
+	if loop.Steer("too late") {
+		t.Error("Steer should fail once the loop has stopped")
+	}
+}
+
+func TestE2E_RemindersRideAlong(t *testing.T) {
+	var loop *conversation.Loop
+	calls := 0
+	responder := mock.ResponderFunc(func(_ *api.CreateMessageRequest) *api.MessageResponse {
+		calls++
+		if calls == 1 {
+			loop.Remind("The MCP resource @docs:readme changed.")
+			return mock.ToolUseResponse("toolu_1", "FileRead", json.RawMessage(`{"file_path":"/nonexistent/a.go"}`), 1)
+		}
+		return mock.TextResponse("Done.", 2)
+	})
+	b, l := setupLoop(t, responder, &collectingHandler{})
+	loop = l
+
+	if err := loop.SendMessage(context.Background(), "look at a.go"); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	// A reminder queued during a turn goes with the tool results.
+	reqs := b.Requests()
+	var blocks []api.ContentBlock
+	last := reqs[1].Body.Messages[len(reqs[1].Body.Messages)-1]
+	json.Unmarshal(last.Content, &blocks)
+	if len(blocks) != 2 || blocks[1].Text != "<system-reminder>\nThe MCP resource @docs:readme changed.\n</system-reminder>" {
+		t.Errorf("second request's last message = %s", last.Content)
+	}
+
+	// One queued between turns goes after the next user message.
+	loop.Remind("The MCP resource @docs:faq changed.")
+	if err := loop.SendMessage(context.Background(), "thanks"); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	reqs = b.Requests()
+	blocks = nil
+	msgs := reqs[len(reqs)-1].Body.Messages
+	if err := json.Unmarshal(msgs[len(msgs)-1].Content, &blocks); err != nil || len(blocks) != 2 {
+		t.Fatalf("last user message = %s, want the prompt and a reminder", msgs[len(msgs)-1].Content)
+	}
+	if blocks[0].Text != "thanks" || !strings.Contains(blocks[1].Text, "@docs:faq") {
+		t.Errorf("blocks = %+v", blocks)
+	}
+}
+
+func TestE2E_OnMessageAndToolResult(t *testing.T) {
+	workDir := t.TempDir()
+	writeInput, _ := json.Marshal(map[string]interface{}{
+		"file_path": filepath.Join(workDir, "out.txt"),
+		"content":   "x",
+	})
+	_, loop := setupLoop(t, mock.NewScriptedResponder([]*api.MessageResponse{
+		mock.ToolUseResponse("toolu_1", "FileWrite", writeInput, 1),
+		mock.TextResponse("done", 2),
+	}), &collectingHandler{})
+
+	var events []string
+	loop.SetOnMessage(func(h *conversation.History) {
+		events = append(events, fmt.Sprintf("message:%d", h.Len()))
+	})
+	loop.SetOnToolResult(func(result api.ContentBlock) {
+		events = append(events, "result:"+result.ToolUseID)
+	})
+	loop.SetOnTurnComplete(func(h *conversation.History) {
+		events = append(events, fmt.Sprintf("turn:%d", h.Len()))
+	})
+
+	if err := loop.SendMessage(context.Background(), "write it"); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	// The user message and the tool call are saved before the tool runs,
+	// and its result as soon as it finishes.
+	want := "message:1 message:2 result:toolu_1 turn:3 turn:4"
+	if got := strings.Join(events, " "); got != want {
+		t.Errorf("events = %q, want %q", got, want)
+	}
+}
+
+func TestE2E_UserPromptSubmitHook(t *testing.T) {
+	b := mock.NewBackend(&mock.StaticResponder{Response: mock.TextResponse("ok", 1)})
+	t.Cleanup(b.Close)
+	runner := hooks.NewRunner(hooks.HookConfig{UserPromptSubmit: hooks.HookList{
+		{Type: "command", Command: `grep -q '[A-Z]\+-[0-9]\+' || echo '{"decision":"block","reason":"Reference a ticket, e.g. ENG-123."}'`},
+		{Type: "command", Command: `echo '{"additionalContext":"Current branch: main"}'`},
+	}})
+	loop := conversation.NewLoop(conversation.LoopConfig{
+		Client:  b.Client(),
+		Handler: &collectingHandler{},
+		Hooks:   runner,
+	})
+
+	err := loop.SendMessage(context.Background(), "fix the login bug")
+	var blocked *conversation.PromptBlockedError
+	if !errors.As(err, &blocked) || blocked.Reason != "Reference a ticket, e.g. ENG-123." || blocked.Prompt != "fix the login bug" {
+		t.Fatalf("SendMessage = %v, want the prompt blocked with the hook's reason", err)
+	}
+	if b.RequestCount() != 0 || loop.History().Len() != 0 {
+		t.Fatalf("a blocked prompt was sent: %d requests, %d messages", b.RequestCount(), loop.History().Len())
+	}
+
+	if err := loop.SendMessage(context.Background(), "ENG-42: fix the login bug"); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	msgs := b.LastRequest().Body.Messages
+	var blocks []api.ContentBlock
+	if err := json.Unmarshal(msgs[len(msgs)-1].Content, &blocks); err != nil || len(blocks) != 2 {
+		t.Fatalf("user message = %s, want the prompt and the hook's context", msgs[len(msgs)-1].Content)
+	}
+	if blocks[0].Text != "ENG-42: fix the login bug" || !strings.Contains(blocks[1].Text, "Current branch: main") {
+		t.Errorf("blocks = %+v", blocks)
+	}
+}
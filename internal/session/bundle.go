@@ -0,0 +1,368 @@
+package session
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/anthropics/claude-code-go/internal/api"
+)
+
+// BundleVersion is the format version written to a bundle's manifest.
+// Bundles with a newer version are refused on import.
+const BundleVersion = 1
+
+// Bundle entry names. Saved background tasks go under bundleTasksDir.
+const (
+	bundleManifest = "manifest.json"
+	bundleSession  = "session.json"
+	bundleChanges  = "changes.json"
+	bundleContext  = "context.json"
+	bundleTasksDir = "tasks/"
+)
+
+// bundleMaxEntry caps the size of one bundle entry read on import.
+const bundleMaxEntry = 256 << 20
+
+// BundleManifest describes a session bundle.
+type BundleManifest struct {
+	Version    int       `json:"version"`
+	SessionID  string    `json:"session_id"`
+	Title      string    `json:"title,omitempty"`
+	CWD        string    `json:"cwd"`
+	ExportedAt time.Time `json:"exported_at"`
+	CLIVersion string    `json:"cli_version,omitempty"`
+}
+
+// FileChange is one file edit made during a session, taken from its
+// FileEdit, FileWrite, and NotebookEdit calls.
+type FileChange struct {
+	Tool   string `json:"tool"`
+	Path   string `json:"path"`
+	Diff   string `json:"diff,omitempty"`
+	Failed bool   `json:"failed,omitempty"` // the tool reported an error
+}
+
+// FileChanges lists the file edits in msgs, in order.
+func FileChanges(msgs []api.Message) []FileChange {
+	var changes []FileChange
+	index := make(map[string]int) // tool_use ID -> position in changes
+	for _, msg := range msgs {
+		var blocks []api.ContentBlock
+		if json.Unmarshal(msg.Content, &blocks) != nil {
+			continue
+		}
+		for _, b := range blocks {
+			switch b.Type {
+			case api.ContentTypeToolUse:
+				if c, ok := fileChange(b); ok {
+					index[b.ID] = len(changes)
+					changes = append(changes, c)
+				}
+			case api.ContentTypeToolResult:
+				if i, ok := index[b.ToolUseID]; ok {
+					changes[i].Failed = b.IsError || strings.HasPrefix(toolResultText(b.Content), "Error:")
+				}
+			}
+		}
+	}
+	return changes
+}
+
+// fileChange describes a tool call that edits a file.
+func fileChange(b api.ContentBlock) (FileChange, bool) {
+	var in struct {
+		FilePath     string `json:"file_path"`
+		NotebookPath string `json:"notebook_path"`
+		OldString    string `json:"old_string"`
+		NewString    string `json:"new_string"`
+		Content      string `json:"content"`
+		NewSource    string `json:"new_source"`
+		Edits        []struct {
+			OldString string `json:"old_string"`
+			NewString string `json:"new_string"`
+		} `json:"edits"`
+	}
+	if json.Unmarshal(b.Input, &in) != nil {
+		return FileChange{}, false
+	}
+	c := FileChange{Tool: b.Name, Path: in.FilePath}
+	switch b.Name {
+	case "FileEdit", "Edit":
+		c.Diff = simpleDiff(in.OldString, in.NewString)
+	case "MultiEdit":
+		var diffs []string
+		for _, e := range in.Edits {
+			diffs = append(diffs, simpleDiff(e.OldString, e.NewString))
+		}
+		c.Diff = strings.Join(diffs, "\n")
+	case "FileWrite", "Write":
+		c.Diff = simpleDiff("", in.Content)
+	case "NotebookEdit":
+		c.Path = in.NotebookPath
+		c.Diff = simpleDiff("", in.NewSource)
+	default:
+		return FileChange{}, false
+	}
+	return c, c.Path != ""
+}
+
+// ExportBundle writes a session to w as a gzipped tar archive holding its
+// metadata and messages, todo list, saved background tasks, pinned
+// context, and the file changes it made, so it can be imported and
+// resumed elsewhere. Bundles
+// are not encrypted, even when the session is at rest.
+func (s *Store) ExportBundle(id string, w io.Writer) error {
+	sess, err := s.Load(id)
+	if err != nil {
+		return err
+	}
+	manifest := BundleManifest{
+		Version:    BundleVersion,
+		SessionID:  sess.ID,
+		Title:      sess.Title,
+		CWD:        sess.CWD,
+		ExportedAt: time.Now(),
+		CLIVersion: s.version,
+	}
+	full := *sess
+	full.MessageLog = false
+	full.MessageCount = 0
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	for _, entry := range []struct {
+		name string
+		v    any
+	}{
+		{bundleManifest, manifest},
+		{bundleSession, &full},
+		{bundleChanges, FileChanges(sess.Messages)},
+	} {
+		data, err := json.MarshalIndent(entry.v, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling %s: %w", entry.name, err)
+		}
+		if err := writeTarFile(tw, entry.name, data); err != nil {
+			return err
+		}
+	}
+
+	snap, err := s.PinnedContext(id)
+	if err != nil {
+		return err
+	}
+	if snap != nil {
+		data, err := json.MarshalIndent(snap, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling %s: %w", bundleContext, err)
+		}
+		if err := writeTarFile(tw, bundleContext, data); err != nil {
+			return err
+		}
+	}
+
+	tasks, err := os.ReadDir(s.TasksDir(id))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("reading task directory: %w", err)
+	}
+	for _, entry := range tasks {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.TasksDir(id), entry.Name()))
+		if err != nil {
+			return fmt.Errorf("reading task: %w", err)
+		}
+		if data, err = Open(data); err != nil {
+			return err
+		}
+		if err := writeTarFile(tw, bundleTasksDir+entry.Name(), data); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("writing bundle: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("writing bundle: %w", err)
+	}
+	return nil
+}
+
+// writeTarFile adds a file to a tar archive.
+func writeTarFile(tw *tar.Writer, name string, data []byte) error {
+	hdr := &tar.Header{Typeflag: tar.TypeReg, Name: name, Mode: 0600, Size: int64(len(data)), ModTime: time.Now()}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("writing bundle: %w", err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("writing bundle: %w", err)
+	}
+	return nil
+}
+
+// ImportedSession is the result of importing a bundle.
+type ImportedSession struct {
+	Session  *Session
+	Manifest BundleManifest
+	Changes  []FileChange
+}
+
+// ImportBundle reads a bundle written by ExportBundle and saves its
+// session in the store, for the project at cwd. When the session was
+// exported from another directory, paths under that directory in its
+// messages and tasks are rewritten to cwd, so the conversation refers to
+// the files in this checkout. A session whose ID is already in the store
+// is refused.
+func (s *Store) ImportBundle(r io.Reader, cwd string) (*ImportedSession, error) {
+	return s.importBundle(r, cwd, "")
+}
+
+// importBundle is ImportBundle. A non-empty newID imports the session as
+// a fork under that ID instead of its own.
+func (s *Store) importBundle(r io.Reader, cwd, newID string) (*ImportedSession, error) {
+	files, err := readBundle(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var imported ImportedSession
+	if err := unmarshalBundleFile(files, bundleManifest, &imported.Manifest); err != nil {
+		return nil, err
+	}
+	if imported.Manifest.Version > BundleVersion {
+		return nil, fmt.Errorf("bundle format version %d is newer than this version supports (%d); upgrade to import it", imported.Manifest.Version, BundleVersion)
+	}
+
+	oldCWD := imported.Manifest.CWD
+	for name, data := range files {
+		files[name] = rewritePaths(data, oldCWD, cwd)
+	}
+
+	var sess Session
+	if err := unmarshalBundleFile(files, bundleSession, &sess); err != nil {
+		return nil, err
+	}
+	if sess.ID == "" || strings.ContainsAny(sess.ID, `/\`) {
+		return nil, fmt.Errorf("bundle has an invalid session ID %q", sess.ID)
+	}
+	if newID != "" {
+		sess.ForkedFrom = sess.ID
+		sess.ID = newID
+	}
+	if s.Exists(sess.ID) {
+		return nil, fmt.Errorf("session %s already exists in this project", sess.ID)
+	}
+	if sess.Name != "" && s.CheckName(sess.ID, sess.Name) != nil {
+		sess.Name = "" // taken here
+	}
+	if _, ok := files[bundleChanges]; ok {
+		if err := unmarshalBundleFile(files, bundleChanges, &imported.Changes); err != nil {
+			return nil, err
+		}
+	}
+
+	sess.CWD = cwd
+	sess.MessageLog = false
+	if err := s.Save(&sess); err != nil {
+		return nil, err
+	}
+	if _, ok := files[bundleContext]; ok {
+		var snap ContextSnapshot
+		if err := unmarshalBundleFile(files, bundleContext, &snap); err != nil {
+			return nil, err
+		}
+		if err := s.PinContext(sess.ID, &snap); err != nil {
+			return nil, err
+		}
+	}
+
+	for name, data := range files {
+		if !strings.HasPrefix(name, bundleTasksDir) {
+			continue
+		}
+		if err := os.MkdirAll(s.TasksDir(sess.ID), 0700); err != nil {
+			return nil, fmt.Errorf("creating task directory: %w", err)
+		}
+		dest := filepath.Join(s.TasksDir(sess.ID), path.Base(name))
+		if data, err = Seal(data); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(dest, data, 0600); err != nil {
+			return nil, fmt.Errorf("writing task: %w", err)
+		}
+	}
+
+	imported.Session = &sess
+	return &imported, nil
+}
+
+// readBundle reads the regular files of a gzipped tar archive by name.
+func readBundle(r io.Reader) (map[string][]byte, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("not a session bundle: %w", err)
+	}
+	defer gz.Close()
+
+	files := make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading bundle: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if hdr.Size > bundleMaxEntry {
+			return nil, fmt.Errorf("bundle entry %s is too large", hdr.Name)
+		}
+		data, err := io.ReadAll(io.LimitReader(tr, bundleMaxEntry))
+		if err != nil {
+			return nil, fmt.Errorf("reading bundle: %w", err)
+		}
+		files[path.Clean(hdr.Name)] = data
+	}
+	return files, nil
+}
+
+// unmarshalBundleFile decodes a JSON file from a bundle.
+func unmarshalBundleFile(files map[string][]byte, name string, v any) error {
+	data, ok := files[name]
+	if !ok {
+		return fmt.Errorf("not a session bundle: %s is missing", name)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("parsing %s: %w", name, err)
+	}
+	return nil
+}
+
+// rewritePaths replaces the directory oldDir with newDir in JSON data,
+// where it is followed by a path separator or ends a string.
+func rewritePaths(data []byte, oldDir, newDir string) []byte {
+	if oldDir == "" || oldDir == newDir {
+		return data
+	}
+	quote := func(s string) []byte {
+		b, _ := json.Marshal(s)
+		return b[1 : len(b)-1] // as it appears inside a JSON string
+	}
+	o, n := quote(oldDir), quote(newDir)
+	data = bytes.ReplaceAll(data, append(append([]byte{}, o...), '/'), append(append([]byte{}, n...), '/'))
+	return bytes.ReplaceAll(data, append(append([]byte{}, o...), '"'), append(append([]byte{}, n...), '"'))
+}
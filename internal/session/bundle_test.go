@@ -0,0 +1,136 @@
+package session
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/anthropics/claude-code-go/internal/api"
+	"github.com/anthropics/claude-code-go/internal/tools"
+)
+
+func bundleTestSession() *Session {
+	editInput, _ := json.Marshal(map[string]string{
+		"file_path":  "/home/me/proj/main.go",
+		"old_string": "a",
+		"new_string": "b",
+	})
+	writeInput, _ := json.Marshal(map[string]string{"file_path": "/home/me/proj/new.go", "content": "package x"})
+	ok, _ := json.Marshal("edited")
+	failed, _ := json.Marshal("Error: file_path must be an absolute path")
+	return &Session{
+		ID:    "bundled",
+		CWD:   "/home/me/proj",
+		Title: "Rename a to b",
+		Messages: []api.Message{
+			api.NewTextMessage(api.RoleUser, "rename a in /home/me/proj/main.go"),
+			api.NewBlockMessage(api.RoleAssistant, []api.ContentBlock{
+				{Type: "tool_use", ID: "tu1", Name: "FileEdit", Input: editInput},
+				{Type: "tool_use", ID: "tu2", Name: "FileWrite", Input: writeInput},
+			}),
+			api.NewBlockMessage(api.RoleUser, []api.ContentBlock{
+				{Type: "tool_result", ToolUseID: "tu1", Content: ok},
+				{Type: "tool_result", ToolUseID: "tu2", Content: failed},
+			}),
+			api.NewTextMessage(api.RoleAssistant, "Done."),
+		},
+		Todos: []tools.TodoItem{{Content: "Rename", Status: "completed", ActiveForm: "Renaming"}},
+	}
+}
+
+func TestFileChanges(t *testing.T) {
+	changes := FileChanges(bundleTestSession().Messages)
+	if len(changes) != 2 {
+		t.Fatalf("changes = %+v, want 2", changes)
+	}
+	if c := changes[0]; c.Tool != "FileEdit" || c.Path != "/home/me/proj/main.go" || c.Diff != "-a\n+b" || c.Failed {
+		t.Errorf("edit change = %+v", c)
+	}
+	if c := changes[1]; c.Tool != "FileWrite" || !c.Failed {
+		t.Errorf("failed write change = %+v", c)
+	}
+}
+
+func TestBundleRoundTrip(t *testing.T) {
+	src := NewStoreWithDir(t.TempDir())
+	if err := src.Save(bundleTestSession()); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if err := os.MkdirAll(src.TasksDir("bundled"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	task := `{"id":"agent-1","prompt":"look at /home/me/proj/lib"}`
+	if err := os.WriteFile(filepath.Join(src.TasksDir("bundled"), "agent-1.json"), []byte(task), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := src.ExportBundle("bundled", &buf); err != nil {
+		t.Fatalf("ExportBundle: %v", err)
+	}
+	bundle := buf.Bytes()
+
+	dst := NewStoreWithDir(t.TempDir())
+	imported, err := dst.ImportBundle(bytes.NewReader(bundle), "/work/proj")
+	if err != nil {
+		t.Fatalf("ImportBundle: %v", err)
+	}
+	if imported.Manifest.CWD != "/home/me/proj" || imported.Manifest.Version != BundleVersion {
+		t.Errorf("manifest = %+v", imported.Manifest)
+	}
+	if len(imported.Changes) != 2 || imported.Changes[0].Path != "/work/proj/main.go" {
+		t.Errorf("changes = %+v, want paths under the new directory", imported.Changes)
+	}
+
+	loaded, err := dst.Load("bundled")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if loaded.CWD != "/work/proj" || loaded.Title != "Rename a to b" || len(loaded.Messages) != 4 {
+		t.Errorf("imported session = %+v", loaded)
+	}
+	if got := FirstUserMessage(loaded); got != "rename a in /work/proj/main.go" {
+		t.Errorf("first message = %q, want the path rewritten", got)
+	}
+	if len(loaded.Todos) != 1 || loaded.Todos[0].Status != "completed" {
+		t.Errorf("todos = %+v", loaded.Todos)
+	}
+	data, err := os.ReadFile(filepath.Join(dst.TasksDir("bundled"), "agent-1.json"))
+	if err != nil || !strings.Contains(string(data), "/work/proj/lib") {
+		t.Errorf("imported task = %q, %v", data, err)
+	}
+
+	if _, err := dst.ImportBundle(bytes.NewReader(bundle), "/work/proj"); err == nil {
+		t.Error("expected error importing a session that already exists")
+	}
+}
+
+func TestImportBundleRejects(t *testing.T) {
+	store := NewStoreWithDir(t.TempDir())
+	if _, err := store.ImportBundle(strings.NewReader("not a bundle"), "/tmp"); err == nil {
+		t.Error("expected error for a file that isn't a bundle")
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	writeTarFile(tw, bundleManifest, []byte(`{"version": 99}`))
+	tw.Close()
+	gz.Close()
+	if _, err := store.ImportBundle(&buf, "/tmp"); err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Errorf("error for a newer bundle = %v", err)
+	}
+}
+
+func TestRewritePaths(t *testing.T) {
+	in := `{"a":"/p/x/f.go","b":"/p/x","c":"/p/xy/f.go"}`
+	want := `{"a":"/q/f.go","b":"/q","c":"/p/xy/f.go"}`
+	if got := string(rewritePaths([]byte(in), "/p/x", "/q")); got != want {
+		t.Errorf("rewritePaths = %s, want %s", got, want)
+	}
+}
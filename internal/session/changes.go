@@ -0,0 +1,245 @@
+package session
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	udiff "github.com/aymanbagabas/go-udiff"
+
+	"github.com/anthropics/claude-code-go/internal/tools"
+)
+
+// changeBaseMax caps the size of a file's original content kept in the
+// change ledger. Larger files are listed without a diff.
+const changeBaseMax = 1 << 20
+
+// ChangeRecord is one entry in a session's change ledger: a tool about to
+// modify a file. The first record for each path also keeps the file as it
+// was before the session touched it, so the session's cumulative diff can
+// be shown after the messages that made it are compacted away.
+type ChangeRecord struct {
+	Path  string    `json:"path"`
+	Tool  string    `json:"tool"`
+	Time  time.Time `json:"time"`
+	First bool      `json:"first,omitempty"`
+
+	// Set on first records only.
+	Existed  bool   `json:"existed,omitempty"`   // the file existed before
+	Base     []byte `json:"base,omitempty"`      // its content then, encrypted on disk with encryption on
+	TooLarge bool   `json:"too_large,omitempty"` // Base was over changeBaseMax and not kept
+}
+
+// changeLedgerPath returns the path of a session's change ledger. It is
+// a file next to the metadata with either backend, like TasksDir.
+func (s *Store) changeLedgerPath(id string) string {
+	return filepath.Join(s.dir, id+".changes.jsonl")
+}
+
+// RecordChange appends a file modification to the session's change
+// ledger. e is the file's state just before the change, as given to
+// tools.UndoStore's OnSnapshot hook.
+func (s *Store) RecordChange(id string, e tools.UndoEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.readOnly[id] {
+		return nil
+	}
+	if s.ledgers == nil {
+		s.ledgers = make(map[string]map[string]bool)
+	}
+	seen, ok := s.ledgers[id]
+	if !ok {
+		seen = make(map[string]bool)
+		records, err := readChangeLedger(s.changeLedgerPath(id))
+		if err != nil {
+			return err
+		}
+		for _, r := range records {
+			seen[r.Path] = true
+		}
+		s.ledgers[id] = seen
+	}
+
+	rec := ChangeRecord{Path: e.Path, Tool: e.Tool, Time: e.Time, First: !seen[e.Path]}
+	if rec.First {
+		rec.Existed = e.Existed
+		if len(e.Content) > changeBaseMax {
+			rec.TooLarge = true
+		} else {
+			rec.Base = e.Content
+		}
+	}
+	if len(rec.Base) > 0 {
+		var err error
+		if rec.Base, err = Seal(rec.Base); err != nil {
+			return err
+		}
+	}
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("marshaling change: %w", err)
+	}
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return fmt.Errorf("creating session directory: %w", err)
+	}
+	f, err := os.OpenFile(s.changeLedgerPath(id), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("opening change ledger: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("writing change ledger: %w", err)
+	}
+	seen[e.Path] = true
+	return nil
+}
+
+// Changes returns the session's change ledger, oldest first. A session
+// that modified no files has none.
+func (s *Store) Changes(id string) ([]ChangeRecord, error) {
+	return readChangeLedger(s.changeLedgerPath(id))
+}
+
+// readChangeLedger reads a change ledger, skipping lines that don't parse
+// (a partial line left by a crash) and decrypting the original contents.
+func readChangeLedger(path string) ([]ChangeRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading change ledger: %w", err)
+	}
+	defer f.Close()
+
+	var records []ChangeRecord
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 4*changeBaseMax)
+	for scanner.Scan() {
+		var r ChangeRecord
+		if json.Unmarshal(scanner.Bytes(), &r) != nil || r.Path == "" {
+			continue
+		}
+		base, err := Open(r.Base)
+		if err != nil {
+			return nil, err
+		}
+		r.Base = base
+		records = append(records, r)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading change ledger: %w", err)
+	}
+	return records, nil
+}
+
+// FileDiff is the net change a session made to one file: the file as it
+// was before the session first modified it, against the file on disk now.
+type FileDiff struct {
+	Path    string
+	Tools   []string // tools that modified it, each once, in order
+	Edits   int      // modifications recorded
+	Status  string   // "added", "modified", "deleted", or "unchanged"
+	Diff    string   // unified diff; empty when unchanged, binary, or too large
+	Note    string   // why there is no diff, e.g. "binary file"
+	Added   int      // lines added
+	Removed int      // lines removed
+}
+
+// CumulativeDiff works out what the ledger's session did to each file it
+// modified, comparing the content kept in the ledger with the files on
+// disk now. Changes made since by anything else show up too. Paths in the
+// diff headers are relative to cwd where they are under it.
+func CumulativeDiff(records []ChangeRecord, cwd string) []FileDiff {
+	var diffs []FileDiff
+	index := make(map[string]int) // path -> position in diffs
+	var firsts []ChangeRecord
+	for _, r := range records {
+		i, ok := index[r.Path]
+		if !ok {
+			i = len(diffs)
+			index[r.Path] = i
+			diffs = append(diffs, FileDiff{Path: r.Path})
+			firsts = append(firsts, r)
+		}
+		d := &diffs[i]
+		d.Edits++
+		if !slices.Contains(d.Tools, r.Tool) {
+			d.Tools = append(d.Tools, r.Tool)
+		}
+	}
+
+	for i, first := range firsts {
+		d := &diffs[i]
+		current, err := os.ReadFile(d.Path)
+		exists := err == nil
+		switch {
+		case !first.Existed && !exists, first.Existed && exists && bytes.Equal(first.Base, current):
+			d.Status = "unchanged"
+			continue
+		case !first.Existed:
+			d.Status = "added"
+		case !exists:
+			d.Status = "deleted"
+		default:
+			d.Status = "modified"
+		}
+		if first.TooLarge || len(current) > changeBaseMax {
+			d.Note = "too large to diff"
+			continue
+		}
+		if bytes.IndexByte(first.Base, 0) >= 0 || bytes.IndexByte(current, 0) >= 0 {
+			d.Note = "binary file"
+			continue
+		}
+
+		name := d.Path
+		if rel, err := filepath.Rel(cwd, d.Path); err == nil && cwd != "" && !strings.HasPrefix(rel, "..") {
+			name = rel
+		}
+		from, to := "a/"+name, "b/"+name
+		if d.Status == "added" {
+			from = "/dev/null"
+		} else if d.Status == "deleted" {
+			to = "/dev/null"
+		}
+		d.Diff = udiff.Unified(from, to, string(first.Base), string(current))
+		hunks := false // past the ---/+++ header
+		for _, line := range strings.Split(d.Diff, "\n") {
+			switch {
+			case strings.HasPrefix(line, "@@"):
+				hunks = true
+			case !hunks:
+			case strings.HasPrefix(line, "+"):
+				d.Added++
+			case strings.HasPrefix(line, "-"):
+				d.Removed++
+			}
+		}
+	}
+	return diffs
+}
+
+// Summary describes the net change in a line, e.g.
+// "modified, +3 -1, FileEdit ×2".
+func (d FileDiff) Summary() string {
+	parts := []string{d.Status}
+	if d.Added > 0 || d.Removed > 0 {
+		parts = append(parts, fmt.Sprintf("+%d -%d", d.Added, d.Removed))
+	}
+	if d.Note != "" {
+		parts = append(parts, d.Note)
+	}
+	tools := strings.Join(d.Tools, ", ")
+	if d.Edits > 1 {
+		tools += fmt.Sprintf(" ×%d", d.Edits)
+	}
+	return strings.Join(append(parts, tools), ", ")
+}
@@ -0,0 +1,96 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/anthropics/claude-code-go/internal/tools"
+)
+
+func TestChangeLedger(t *testing.T) {
+	store := NewStoreWithDir(t.TempDir())
+	cwd := t.TempDir()
+	edited := filepath.Join(cwd, "main.go")
+	created := filepath.Join(cwd, "new.txt")
+	reverted := filepath.Join(cwd, "same.txt")
+	os.WriteFile(edited, []byte("package main\n\nfunc main() {}\n"), 0600)
+	os.WriteFile(reverted, []byte("keep\n"), 0600)
+
+	undo := tools.NewUndoStore()
+	undo.OnSnapshot(func(e tools.UndoEntry) {
+		if err := store.RecordChange("s1", e); err != nil {
+			t.Errorf("RecordChange: %v", err)
+		}
+	})
+	edit := tools.NewFileEditTool()
+	edit.SetUndoStore(undo)
+	write := tools.NewFileWriteTool()
+	write.SetUndoStore(undo)
+	run := func(tool tools.Tool, in any) {
+		t.Helper()
+		input, _ := json.Marshal(in)
+		if out, err := tool.Execute(context.Background(), input); err != nil || strings.HasPrefix(out, "Error") {
+			t.Fatalf("%s: %q, %v", tool.Name(), out, err)
+		}
+	}
+	run(edit, tools.FileEditInput{FilePath: edited, OldString: "func main() {}", NewString: "func main() {\n\tprintln(1)\n}"})
+	run(edit, tools.FileEditInput{FilePath: edited, OldString: "println(1)", NewString: "println(2)"})
+	run(write, tools.FileWriteInput{FilePath: created, Content: "hello\n"})
+	run(write, tools.FileWriteInput{FilePath: reverted, Content: "changed\n"})
+	if _, err := undo.Undo(1); err != nil {
+		t.Fatal(err)
+	}
+
+	// A new store reads the ledger back.
+	records, err := NewStoreWithDir(store.Dir()).Changes("s1")
+	if err != nil {
+		t.Fatalf("Changes: %v", err)
+	}
+	if len(records) != 4 || !records[0].First || records[1].First || string(records[0].Base) != "package main\n\nfunc main() {}\n" {
+		t.Fatalf("records = %+v, want 4 with the original content on the first", records)
+	}
+
+	diffs := CumulativeDiff(records, cwd)
+	if len(diffs) != 3 {
+		t.Fatalf("diffs = %+v, want one per file", diffs)
+	}
+	mainGo := diffs[0]
+	if mainGo.Status != "modified" || mainGo.Edits != 2 || mainGo.Added != 3 || mainGo.Removed != 1 {
+		t.Errorf("main.go = %+v, want modified by 2 edits, +3 -1", mainGo)
+	}
+	if !strings.Contains(mainGo.Diff, "--- a/main.go") || !strings.Contains(mainGo.Diff, "+\tprintln(2)") || strings.Contains(mainGo.Diff, "println(1)") {
+		t.Errorf("main.go diff:\n%s", mainGo.Diff)
+	}
+	if mainGo.Summary() != "modified, +3 -1, FileEdit ×2" {
+		t.Errorf("Summary = %q", mainGo.Summary())
+	}
+	if diffs[1].Status != "added" || !strings.Contains(diffs[1].Diff, "--- /dev/null") {
+		t.Errorf("new.txt = %+v, want added", diffs[1])
+	}
+	if diffs[2].Status != "unchanged" || diffs[2].Diff != "" {
+		t.Errorf("same.txt = %+v, want unchanged after undo", diffs[2])
+	}
+
+	// Forks carry the ledger; deleting a session removes it.
+	sess := &Session{ID: "s1"}
+	if err := store.Save(sess); err != nil {
+		t.Fatal(err)
+	}
+	fork, err := store.Fork("s1")
+	if err != nil {
+		t.Fatalf("Fork: %v", err)
+	}
+	if got, _ := store.Changes(fork.ID); len(got) != 4 {
+		t.Errorf("fork has %d changes, want 4", len(got))
+	}
+	if err := store.Delete("s1"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := store.Changes("s1"); len(got) != 0 {
+		t.Errorf("deleted session still has %d changes", len(got))
+	}
+}
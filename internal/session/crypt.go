@@ -0,0 +1,261 @@
+package session
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Encryption modes for the sessionEncryption setting.
+const (
+	EncryptionKeychain   = "keychain"   // a random key kept in the OS keychain
+	EncryptionPassphrase = "passphrase" // a key derived from a passphrase
+)
+
+// encMagic starts every encrypted payload, followed by the GCM nonce and
+// the ciphertext. JSON and gzip never start with a zero byte, so stored
+// content can be told apart by its first bytes, as with gzipMagic.
+var encMagic = []byte("\x00enc1")
+
+// keyCheckText is encrypted into the encryption config so a wrong key is
+// caught before anything is written with it.
+const keyCheckText = "claude-code-go session key"
+
+// pbkdf2Iterations is the PBKDF2-SHA256 work factor for passphrases.
+const pbkdf2Iterations = 600000
+
+// ErrEncrypted is returned when reading content encrypted at rest without
+// the key; see SetEncryptionKey.
+var ErrEncrypted = errors.New("session is encrypted; set sessionEncryption to read it")
+
+var (
+	cryptMu     sync.RWMutex
+	sessionAEAD cipher.AEAD // set by SetEncryptionKey
+)
+
+// SetEncryptionKey turns on encryption at rest for every store in the
+// process. From then on message content, journaled tool results, and the
+// original file contents in change ledgers are written encrypted with key
+// (32 bytes, for AES-256-GCM), and content encrypted earlier can be read.
+// Content written before encryption was turned on stays readable as it
+// is. The todo list is sealed in the metadata; the rest of it, such as
+// names, times, and token counts, is not encrypted.
+func SetEncryptionKey(key []byte) error {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return fmt.Errorf("session encryption key: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return fmt.Errorf("session encryption key: %w", err)
+	}
+	cryptMu.Lock()
+	defer cryptMu.Unlock()
+	sessionAEAD = aead
+	return nil
+}
+
+// Encrypted reports whether SetEncryptionKey has turned encryption on.
+func Encrypted() bool {
+	cryptMu.RLock()
+	defer cryptMu.RUnlock()
+	return sessionAEAD != nil
+}
+
+// Seal encrypts data when encryption is on and returns it unchanged
+// otherwise.
+func Seal(data []byte) ([]byte, error) {
+	cryptMu.RLock()
+	aead := sessionAEAD
+	cryptMu.RUnlock()
+	if aead == nil {
+		return data, nil
+	}
+	return sealWith(aead, data)
+}
+
+// sealWith encrypts data with aead under a random nonce.
+func sealWith(aead cipher.AEAD, data []byte) ([]byte, error) {
+	out := make([]byte, len(encMagic)+aead.NonceSize(), len(encMagic)+aead.NonceSize()+len(data)+aead.Overhead())
+	copy(out, encMagic)
+	if _, err := rand.Read(out[len(encMagic):]); err != nil {
+		return nil, fmt.Errorf("encrypting session data: %w", err)
+	}
+	return aead.Seal(out, out[len(encMagic):], data, nil), nil
+}
+
+// Open decrypts data written by Seal. Data that isn't encrypted is
+// returned unchanged. Encrypted data without a key set gives ErrEncrypted.
+func Open(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, encMagic) {
+		return data, nil
+	}
+	cryptMu.RLock()
+	aead := sessionAEAD
+	cryptMu.RUnlock()
+	if aead == nil {
+		return nil, ErrEncrypted
+	}
+	return openWith(aead, data)
+}
+
+// openWith decrypts data that starts with encMagic.
+func openWith(aead cipher.AEAD, data []byte) ([]byte, error) {
+	data = data[len(encMagic):]
+	if len(data) < aead.NonceSize() {
+		return nil, fmt.Errorf("decrypting session data: too short")
+	}
+	plain, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting session data: wrong key or corrupt data")
+	}
+	return plain, nil
+}
+
+// sealTodos moves the todo list of a session's metadata into EncTodos
+// when encryption is on, since todos describe the work as the messages do.
+func (sess *Session) sealTodos() error {
+	if !Encrypted() || len(sess.Todos) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(sess.Todos)
+	if err != nil {
+		return fmt.Errorf("marshaling todos: %w", err)
+	}
+	if sess.EncTodos, err = Seal(data); err != nil {
+		return err
+	}
+	sess.Todos = nil
+	return nil
+}
+
+// openTodos restores a todo list sealed by sealTodos.
+func (sess *Session) openTodos() error {
+	if sess.EncTodos == nil {
+		return nil
+	}
+	data, err := Open(sess.EncTodos)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &sess.Todos); err != nil {
+		return fmt.Errorf("parsing todos: %w", err)
+	}
+	sess.EncTodos = nil
+	return nil
+}
+
+// encryptionConfig is ~/.claude/session-encryption.json, which holds the
+// passphrase salt and a check value for the key.
+type encryptionConfig struct {
+	Mode  string `json:"mode"`
+	Salt  []byte `json:"salt,omitempty"`
+	Check []byte `json:"check"`
+}
+
+// EncryptionKey returns the session encryption key for mode. For
+// EncryptionKeychain it is a random key kept in the OS keychain, created
+// on first use. For EncryptionPassphrase it is derived with PBKDF2 from
+// what passphrase returns, with a salt kept in
+// ~/.claude/session-encryption.json. The key is checked against that
+// file, which the first call writes, so a wrong passphrase or a changed
+// keychain entry is an error instead of sessions no key can read.
+func EncryptionKey(mode string, passphrase func() (string, error)) ([]byte, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("getting home directory: %w", err)
+	}
+	var keychain func() ([]byte, error)
+	if mode == EncryptionKeychain {
+		keychain = keychainKey
+	}
+	return encryptionKeyAt(filepath.Join(home, ".claude", "session-encryption.json"), mode, passphrase, keychain)
+}
+
+// encryptionKeyAt is EncryptionKey with the config file and keychain
+// lookup given, for tests.
+func encryptionKeyAt(path, mode string, passphrase func() (string, error), keychain func() ([]byte, error)) ([]byte, error) {
+	var cfg encryptionConfig
+	data, err := os.ReadFile(path)
+	exists := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("reading session encryption config: %w", err)
+	}
+	if exists {
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", path, err)
+		}
+		if cfg.Mode != mode {
+			return nil, fmt.Errorf("sessions are encrypted with sessionEncryption %q, not %q; remove %s to start over with new sessions", cfg.Mode, mode, path)
+		}
+	}
+
+	var key []byte
+	switch mode {
+	case EncryptionKeychain:
+		if key, err = keychain(); err != nil {
+			return nil, err
+		}
+	case EncryptionPassphrase:
+		pass, err := passphrase()
+		if err != nil {
+			return nil, err
+		}
+		if pass == "" {
+			return nil, fmt.Errorf("empty session passphrase")
+		}
+		if !exists {
+			cfg.Salt = make([]byte, 16)
+			if _, err := rand.Read(cfg.Salt); err != nil {
+				return nil, fmt.Errorf("generating salt: %w", err)
+			}
+		}
+		if key, err = pbkdf2.Key(sha256.New, pass, cfg.Salt, pbkdf2Iterations, 32); err != nil {
+			return nil, fmt.Errorf("deriving session key: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unknown sessionEncryption %q (use %q or %q)", mode, EncryptionKeychain, EncryptionPassphrase)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("session encryption key: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("session encryption key: %w", err)
+	}
+	if exists {
+		if plain, err := openWith(aead, cfg.Check); err != nil || string(plain) != keyCheckText {
+			if mode == EncryptionPassphrase {
+				return nil, fmt.Errorf("wrong session passphrase")
+			}
+			return nil, fmt.Errorf("the keychain's session key doesn't match the one sessions were encrypted with")
+		}
+		return key, nil
+	}
+
+	cfg.Mode = mode
+	if cfg.Check, err = sealWith(aead, []byte(keyCheckText)); err != nil {
+		return nil, err
+	}
+	if data, err = json.MarshalIndent(&cfg, "", "  "); err != nil {
+		return nil, fmt.Errorf("marshaling session encryption config: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("creating config directory: %w", err)
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		return nil, fmt.Errorf("writing session encryption config: %w", err)
+	}
+	return key, nil
+}
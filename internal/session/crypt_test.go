@@ -0,0 +1,187 @@
+package session
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/anthropics/claude-code-go/internal/api"
+	"github.com/anthropics/claude-code-go/internal/conversation"
+	"github.com/anthropics/claude-code-go/internal/tools"
+)
+
+// setKey turns encryption on with key for the rest of the test.
+func setKey(t *testing.T, key byte) {
+	t.Helper()
+	if err := SetEncryptionKey(bytes.Repeat([]byte{key}, 32)); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { clearKey() })
+}
+
+func clearKey() {
+	cryptMu.Lock()
+	defer cryptMu.Unlock()
+	sessionAEAD = nil
+}
+
+// secretSession has a small message, one large enough to be compressed,
+// a tool call whose result is journaled, and a todo list.
+func secretSession(id string) *Session {
+	return &Session{
+		ID: id,
+		Messages: []api.Message{
+			api.NewTextMessage(api.RoleUser, "the secret is hunter2"),
+			api.NewTextMessage(api.RoleAssistant, strings.Repeat("hunter2 ", 4096)),
+			api.NewBlockMessage(api.RoleAssistant, []api.ContentBlock{
+				{Type: api.ContentTypeToolUse, ID: "t1", Name: "Bash", Input: []byte(`{"command":"ls"}`)},
+			}),
+		},
+		Turns: []conversation.TurnMetadata{{Model: "claude-sonnet-4-6"}},
+		Todos: []tools.TodoItem{{Content: "rotate hunter2", Status: "pending", ActiveForm: "Rotating hunter2"}},
+	}
+}
+
+func TestEncryptedMessageLog(t *testing.T) {
+	dir := t.TempDir()
+	setKey(t, 1)
+	store := NewStoreWithDir(dir)
+	if err := store.Save(secretSession("enc")); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if err := store.AppendToolResult("enc", conversation.MakeToolResult("t1", "hunter2.txt", false)); err != nil {
+		t.Fatalf("AppendToolResult: %v", err)
+	}
+	data, _ := os.ReadFile(store.messageLogPath("enc"))
+	if bytes.Contains(data, []byte("hunter2")) {
+		t.Errorf("message log has plaintext content:\n%s", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "enc.json")); bytes.Contains(data, []byte("hunter2")) {
+		t.Errorf("metadata has plaintext todos:\n%s", data)
+	}
+
+	loaded, err := NewStoreWithDir(dir).Load("enc")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(loaded.Messages) != 4 || MessageText(loaded.Messages[0]) != "the secret is hunter2" ||
+		!strings.Contains(string(loaded.Messages[3].Content), "hunter2.txt") {
+		t.Errorf("loaded messages = %+v, want the three saved and the journaled result", loaded.Messages)
+	}
+	if len(loaded.Turns) != 1 {
+		t.Errorf("loaded %d turns, want 1", len(loaded.Turns))
+	}
+	if len(loaded.Todos) != 1 || loaded.Todos[0].Content != "rotate hunter2" || loaded.EncTodos != nil {
+		t.Errorf("loaded todos = %+v, want the saved list", loaded.Todos)
+	}
+
+	setKey(t, 2)
+	if _, err := NewStoreWithDir(dir).Load("enc"); err == nil {
+		t.Error("Load with the wrong key should fail")
+	}
+	clearKey()
+	if _, err := NewStoreWithDir(dir).Load("enc"); !errors.Is(err, ErrEncrypted) {
+		t.Errorf("Load without a key = %v, want ErrEncrypted", err)
+	}
+}
+
+func TestEncryptedSQLite(t *testing.T) {
+	setKey(t, 1)
+	store := newSQLiteStore(t, t.TempDir())
+	if err := store.Save(secretSession("enc")); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if err := store.AppendToolResult("enc", conversation.MakeToolResult("t1", "hunter2.txt", false)); err != nil {
+		t.Fatalf("AppendToolResult: %v", err)
+	}
+	var n int
+	store.db.QueryRow("SELECT COUNT(*) FROM messages WHERE CAST(message AS TEXT) LIKE '%hunter2%'").Scan(&n)
+	if n != 0 {
+		t.Errorf("%d messages stored in plaintext", n)
+	}
+	store.db.QueryRow("SELECT COUNT(*) FROM tool_results WHERE result LIKE '%hunter2%'").Scan(&n)
+	if n != 0 {
+		t.Errorf("%d tool results stored in plaintext", n)
+	}
+	store.db.QueryRow("SELECT COUNT(*) FROM sessions WHERE meta LIKE '%hunter2%'").Scan(&n)
+	if n != 0 {
+		t.Errorf("%d sessions with plaintext todos", n)
+	}
+	store.db.QueryRow("SELECT COUNT(*) FROM messages_fts").Scan(&n)
+	if n != 0 {
+		t.Errorf("%d messages indexed, want none", n)
+	}
+
+	loaded, err := store.Load("enc")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(loaded.Messages) != 4 || MessageText(loaded.Messages[0]) != "the secret is hunter2" {
+		t.Errorf("loaded messages = %+v", loaded.Messages)
+	}
+	if len(loaded.Todos) != 1 || loaded.Todos[0].Content != "rotate hunter2" {
+		t.Errorf("loaded todos = %+v, want the saved list", loaded.Todos)
+	}
+	matches, err := store.Search(Filter{Text: "secret is"})
+	if err != nil || len(matches) != 1 || matches[0].Snippet == "" {
+		t.Errorf("Search = %+v, %v; want the session, found without the index", matches, err)
+	}
+}
+
+func TestEncryptedChangeLedger(t *testing.T) {
+	setKey(t, 1)
+	store := NewStoreWithDir(t.TempDir())
+	if err := store.RecordChange("enc", tools.UndoEntry{Path: "/p/a.txt", Tool: "FileEdit", Existed: true, Content: []byte("hunter2\n")}); err != nil {
+		t.Fatalf("RecordChange: %v", err)
+	}
+	data, _ := os.ReadFile(store.changeLedgerPath("enc"))
+	if bytes.Contains(data, []byte("hunter2")) {
+		t.Errorf("change ledger has plaintext content:\n%s", data)
+	}
+	records, err := store.Changes("enc")
+	if err != nil || len(records) != 1 || string(records[0].Base) != "hunter2\n" {
+		t.Errorf("Changes = %+v, %v; want the original content", records, err)
+	}
+}
+
+func TestEncryptionKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session-encryption.json")
+	pass := func(p string) func() (string, error) {
+		return func() (string, error) { return p, nil }
+	}
+
+	key, err := encryptionKeyAt(path, EncryptionPassphrase, pass("correct horse"), nil)
+	if err != nil || len(key) != 32 {
+		t.Fatalf("first passphrase key = %x, %v", key, err)
+	}
+	again, err := encryptionKeyAt(path, EncryptionPassphrase, pass("correct horse"), nil)
+	if err != nil || !bytes.Equal(again, key) {
+		t.Errorf("same passphrase = %x, %v; want the same key", again, err)
+	}
+	if _, err := encryptionKeyAt(path, EncryptionPassphrase, pass("battery staple"), nil); err == nil || !strings.Contains(err.Error(), "wrong session passphrase") {
+		t.Errorf("wrong passphrase = %v, want an error", err)
+	}
+	if _, err := encryptionKeyAt(path, EncryptionKeychain, nil, nil); err == nil {
+		t.Error("switching modes should fail")
+	}
+
+	path = filepath.Join(t.TempDir(), "session-encryption.json")
+	keychain := func(b byte) func() ([]byte, error) {
+		return func() ([]byte, error) { return bytes.Repeat([]byte{b}, 32), nil }
+	}
+	if _, err := encryptionKeyAt(path, EncryptionKeychain, nil, keychain(1)); err != nil {
+		t.Fatalf("keychain key: %v", err)
+	}
+	if _, err := encryptionKeyAt(path, EncryptionKeychain, nil, keychain(1)); err != nil {
+		t.Errorf("same keychain key: %v", err)
+	}
+	if _, err := encryptionKeyAt(path, EncryptionKeychain, nil, keychain(2)); err == nil {
+		t.Error("a changed keychain key should fail")
+	}
+	if _, err := encryptionKeyAt(path, "rot13", nil, nil); err == nil {
+		t.Error("an unknown mode should fail")
+	}
+}
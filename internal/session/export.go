@@ -0,0 +1,345 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/anthropics/claude-code-go/internal/api"
+)
+
+// Export formats supported by Export.
+const (
+	FormatMarkdown = "md"
+	FormatHTML     = "html"
+	FormatText     = "text"
+)
+
+// ExportOptions controls optional sections of an exported conversation.
+type ExportOptions struct {
+	// CostSummary is appended as a "Usage" section when non-empty.
+	CostSummary string
+}
+
+// Export renders a session as a shareable document in the given format
+// ("md", "html", or "text").
+func Export(sess *Session, format string, opts ExportOptions) (string, error) {
+	switch strings.ToLower(format) {
+	case FormatMarkdown, "markdown", "":
+		return ExportMarkdown(sess, opts), nil
+	case FormatHTML, "htm":
+		return ExportHTML(sess, opts), nil
+	case FormatText, "txt":
+		return ExportText(sess, opts), nil
+	default:
+		return "", fmt.Errorf("unsupported export format %q (use md, html, or text)", format)
+	}
+}
+
+// exportPart is one renderable piece of a message.
+type exportPart struct {
+	kind    string // "text", "thinking", "tool_use", "tool_result", "diff", "image"
+	title   string // tool name or file path
+	body    string
+	isError bool
+}
+
+// exportTurn is a message broken into renderable parts.
+type exportTurn struct {
+	role  string
+	parts []exportPart
+}
+
+// exportTurns converts API messages into render-ready turns. Tool inputs that
+// describe file edits are turned into diffs.
+func exportTurns(msgs []api.Message) []exportTurn {
+	var turns []exportTurn
+	for _, msg := range msgs {
+		turn := exportTurn{role: msg.Role}
+
+		var text string
+		if err := json.Unmarshal(msg.Content, &text); err == nil {
+			turn.parts = append(turn.parts, exportPart{kind: "text", body: text})
+			turns = append(turns, turn)
+			continue
+		}
+
+		var blocks []api.ContentBlock
+		if err := json.Unmarshal(msg.Content, &blocks); err != nil {
+			continue
+		}
+		for _, b := range blocks {
+			switch b.Type {
+			case "text":
+				turn.parts = append(turn.parts, exportPart{kind: "text", body: b.Text})
+			case "thinking":
+				turn.parts = append(turn.parts, exportPart{kind: "thinking", body: b.Thinking})
+			case "image":
+				turn.parts = append(turn.parts, exportPart{kind: "image", body: "[image]"})
+			case "tool_use":
+				turn.parts = append(turn.parts, toolUseParts(b)...)
+			case "tool_result":
+				turn.parts = append(turn.parts, exportPart{
+					kind:    "tool_result",
+					body:    toolResultText(b.Content),
+					isError: b.IsError,
+				})
+			}
+		}
+		if len(turn.parts) > 0 {
+			turns = append(turns, turn)
+		}
+	}
+	return turns
+}
+
+// toolUseParts renders a tool_use block. Calls that change a file
+// (FileEdit, FileWrite, NotebookEdit; see fileChange) are rendered as a
+// diff instead.
+func toolUseParts(b api.ContentBlock) []exportPart {
+	if c, ok := fileChange(b); ok {
+		return []exportPart{{kind: "diff", title: c.Tool + " " + c.Path, body: c.Diff}}
+	}
+
+	var input map[string]any
+	_ = json.Unmarshal(b.Input, &input)
+	pretty, err := json.MarshalIndent(input, "", "  ")
+	if err != nil || input == nil {
+		pretty = b.Input
+	}
+	return []exportPart{{kind: "tool_use", title: b.Name, body: string(pretty)}}
+}
+
+// simpleDiff renders a replacement as removed/added lines.
+func simpleDiff(oldStr, newStr string) string {
+	var sb strings.Builder
+	if oldStr != "" {
+		for _, line := range strings.Split(oldStr, "\n") {
+			sb.WriteString("-" + line + "\n")
+		}
+	}
+	if newStr != "" {
+		for _, line := range strings.Split(newStr, "\n") {
+			sb.WriteString("+" + line + "\n")
+		}
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// toolResultText flattens tool result content (a string or content blocks).
+func toolResultText(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	var blocks []api.ContentBlock
+	if err := json.Unmarshal(raw, &blocks); err != nil {
+		return string(raw)
+	}
+	var parts []string
+	for _, b := range blocks {
+		switch b.Type {
+		case "text":
+			parts = append(parts, b.Text)
+		case "image":
+			parts = append(parts, "[image]")
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// exportRoleLabel returns a display label for a turn. User turns that only
+// carry tool results are labeled as tool output.
+func exportRoleLabel(t exportTurn) string {
+	if t.role == "assistant" {
+		return "Assistant"
+	}
+	for _, p := range t.parts {
+		if p.kind != "tool_result" {
+			return "User"
+		}
+	}
+	return "Tool output"
+}
+
+// fence returns a code fence long enough not to collide with body.
+func fence(body string) string {
+	f := "```"
+	for strings.Contains(body, f) {
+		f += "`"
+	}
+	return f
+}
+
+// ExportMarkdown renders a session as Markdown.
+func ExportMarkdown(sess *Session, opts ExportOptions) string {
+	var sb strings.Builder
+	sb.WriteString("# Conversation " + sess.ID + "\n\n")
+	if sess.Model != "" {
+		sb.WriteString("- Model: " + sess.Model + "\n")
+	}
+	if sess.CWD != "" {
+		sb.WriteString("- Directory: " + sess.CWD + "\n")
+	}
+	if !sess.CreatedAt.IsZero() {
+		sb.WriteString("- Started: " + sess.CreatedAt.Format("2006-01-02 15:04:05") + "\n")
+	}
+	sb.WriteString("\n")
+
+	for _, t := range exportTurns(sess.Messages) {
+		sb.WriteString("## " + exportRoleLabel(t) + "\n\n")
+		for _, p := range t.parts {
+			switch p.kind {
+			case "text":
+				sb.WriteString(p.body + "\n\n")
+			case "thinking":
+				sb.WriteString("<details><summary>Thinking</summary>\n\n" + p.body + "\n\n</details>\n\n")
+			case "image":
+				sb.WriteString("_" + p.body + "_\n\n")
+			case "tool_use":
+				f := fence(p.body)
+				sb.WriteString("**Tool: " + p.title + "**\n\n" + f + "json\n" + p.body + "\n" + f + "\n\n")
+			case "diff":
+				f := fence(p.body)
+				sb.WriteString("**" + p.title + "**\n\n" + f + "diff\n" + p.body + "\n" + f + "\n\n")
+			case "tool_result":
+				label := "Result"
+				if p.isError {
+					label = "Error"
+				}
+				f := fence(p.body)
+				sb.WriteString("**" + label + ":**\n\n" + f + "\n" + p.body + "\n" + f + "\n\n")
+			}
+		}
+	}
+
+	if opts.CostSummary != "" {
+		sb.WriteString("## Usage\n\n```\n" + opts.CostSummary + "\n```\n")
+	}
+	return sb.String()
+}
+
+// ExportText renders a session as plain text for reading in a terminal.
+// Tool calls and their results are one line each; thinking is left out.
+func ExportText(sess *Session, opts ExportOptions) string {
+	var sb strings.Builder
+	for _, t := range exportTurns(sess.Messages) {
+		var lines []string
+		for _, p := range t.parts {
+			switch p.kind {
+			case "text":
+				if body := strings.TrimSpace(p.body); body != "" {
+					lines = append(lines, body)
+				}
+			case "image":
+				lines = append(lines, p.body)
+			case "tool_use", "diff":
+				lines = append(lines, "[Tool: "+p.title+"]")
+			case "tool_result":
+				label := "Result"
+				if p.isError {
+					label = "Error"
+				}
+				n := strings.Count(strings.TrimRight(p.body, "\n"), "\n") + 1
+				lines = append(lines, fmt.Sprintf("[%s: %d lines]", label, n))
+			}
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		sb.WriteString(exportRoleLabel(t) + ":\n")
+		for _, line := range lines {
+			sb.WriteString(line + "\n")
+		}
+		sb.WriteString("\n")
+	}
+
+	if opts.CostSummary != "" {
+		sb.WriteString(opts.CostSummary + "\n")
+	}
+	return sb.String()
+}
+
+const exportHTMLStyle = `body{font-family:-apple-system,BlinkMacSystemFont,"Segoe UI",sans-serif;max-width:900px;margin:2em auto;padding:0 1em;color:#222}
+.meta{color:#666;font-size:.9em}
+.turn{margin:1.5em 0;padding:1em;border-radius:8px}
+.user{background:#eef4ff}
+.assistant{background:#f6f6f6}
+.tool{background:#fffbea}
+.role{font-weight:bold;margin-bottom:.5em}
+pre{background:#fff;border:1px solid #ddd;padding:.75em;overflow-x:auto;white-space:pre-wrap}
+.text{white-space:pre-wrap}
+.add{color:#116611}
+.del{color:#aa1111}
+.error{border-color:#d33}`
+
+// ExportHTML renders a session as a standalone HTML document.
+func ExportHTML(sess *Session, opts ExportOptions) string {
+	esc := html.EscapeString
+	var sb strings.Builder
+	sb.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	sb.WriteString("<title>Conversation " + esc(sess.ID) + "</title>\n")
+	sb.WriteString("<style>\n" + exportHTMLStyle + "\n</style>\n</head>\n<body>\n")
+	sb.WriteString("<h1>Conversation " + esc(sess.ID) + "</h1>\n<div class=\"meta\">")
+	if sess.Model != "" {
+		sb.WriteString("Model: " + esc(sess.Model) + "<br>")
+	}
+	if sess.CWD != "" {
+		sb.WriteString("Directory: " + esc(sess.CWD) + "<br>")
+	}
+	if !sess.CreatedAt.IsZero() {
+		sb.WriteString("Started: " + esc(sess.CreatedAt.Format("2006-01-02 15:04:05")))
+	}
+	sb.WriteString("</div>\n")
+
+	for _, t := range exportTurns(sess.Messages) {
+		label := exportRoleLabel(t)
+		class := t.role
+		if label == "Tool output" {
+			class = "tool"
+		}
+		sb.WriteString("<div class=\"turn " + class + "\">\n<div class=\"role\">" + label + "</div>\n")
+		for _, p := range t.parts {
+			switch p.kind {
+			case "text":
+				sb.WriteString("<div class=\"text\">" + esc(p.body) + "</div>\n")
+			case "thinking":
+				sb.WriteString("<details><summary>Thinking</summary><div class=\"text\">" + esc(p.body) + "</div></details>\n")
+			case "image":
+				sb.WriteString("<p><em>" + esc(p.body) + "</em></p>\n")
+			case "tool_use":
+				sb.WriteString("<p><strong>Tool: " + esc(p.title) + "</strong></p>\n<pre>" + esc(p.body) + "</pre>\n")
+			case "diff":
+				sb.WriteString("<p><strong>" + esc(p.title) + "</strong></p>\n<pre>")
+				for _, line := range strings.Split(p.body, "\n") {
+					switch {
+					case strings.HasPrefix(line, "+"):
+						sb.WriteString("<span class=\"add\">" + esc(line) + "</span>\n")
+					case strings.HasPrefix(line, "-"):
+						sb.WriteString("<span class=\"del\">" + esc(line) + "</span>\n")
+					default:
+						sb.WriteString(esc(line) + "\n")
+					}
+				}
+				sb.WriteString("</pre>\n")
+			case "tool_result":
+				if p.isError {
+					sb.WriteString("<pre class=\"error\">" + esc(p.body) + "</pre>\n")
+				} else {
+					sb.WriteString("<pre>" + esc(p.body) + "</pre>\n")
+				}
+			}
+		}
+		sb.WriteString("</div>\n")
+	}
+
+	if opts.CostSummary != "" {
+		sb.WriteString("<h2>Usage</h2>\n<pre>" + esc(opts.CostSummary) + "</pre>\n")
+	}
+	sb.WriteString("</body>\n</html>\n")
+	return sb.String()
+}
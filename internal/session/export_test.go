@@ -0,0 +1,88 @@
+package session
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/anthropics/claude-code-go/internal/api"
+)
+
+func exportTestSession() *Session {
+	editInput, _ := json.Marshal(map[string]string{
+		"file_path":  "main.go",
+		"old_string": "fmt.Println(a)",
+		"new_string": "fmt.Println(b)",
+	})
+	result, _ := json.Marshal("edited <ok>")
+	return &Session{
+		ID:    "exp-1",
+		Model: "claude-sonnet-4-6",
+		Messages: []api.Message{
+			api.NewTextMessage("user", "change a to b"),
+			api.NewBlockMessage("assistant", []api.ContentBlock{
+				{Type: "text", Text: "Sure."},
+				{Type: "tool_use", ID: "tu1", Name: "FileEdit", Input: editInput},
+			}),
+			api.NewBlockMessage("user", []api.ContentBlock{
+				{Type: "tool_result", ToolUseID: "tu1", Content: result},
+			}),
+		},
+	}
+}
+
+func TestExportMarkdown(t *testing.T) {
+	out, err := Export(exportTestSession(), FormatMarkdown, ExportOptions{CostSummary: "Total cost: $0.01"})
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	for _, want := range []string{
+		"# Conversation exp-1",
+		"## User",
+		"change a to b",
+		"**FileEdit main.go**",
+		"-fmt.Println(a)",
+		"+fmt.Println(b)",
+		"## Tool output",
+		"edited <ok>",
+		"## Usage",
+		"Total cost: $0.01",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("markdown export missing %q", want)
+		}
+	}
+}
+
+func TestExportHTML(t *testing.T) {
+	out, err := Export(exportTestSession(), FormatHTML, ExportOptions{})
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if !strings.HasPrefix(out, "<!DOCTYPE html>") {
+		t.Error("html export should be a standalone document")
+	}
+	if !strings.Contains(out, "edited &lt;ok&gt;") {
+		t.Error("html export should escape tool output")
+	}
+	if !strings.Contains(out, `<span class="add">+fmt.Println(b)</span>`) {
+		t.Error("html export should highlight added diff lines")
+	}
+}
+
+func TestExportText(t *testing.T) {
+	out, err := Export(exportTestSession(), FormatText, ExportOptions{})
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	want := "User:\nchange a to b\n\nAssistant:\nSure.\n[Tool: FileEdit main.go]\n\nTool output:\n[Result: 1 lines]\n\n"
+	if out != want {
+		t.Errorf("text export = %q, want %q", out, want)
+	}
+}
+
+func TestExportUnknownFormat(t *testing.T) {
+	if _, err := Export(exportTestSession(), "pdf", ExportOptions{}); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
@@ -0,0 +1,64 @@
+package session
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os/exec"
+	"os/user"
+	"runtime"
+	"strings"
+)
+
+// keychainService names the session key's entry in the OS keychain.
+const keychainService = "claude-code-go session key"
+
+// keychainKey returns the session encryption key kept in the OS keychain:
+// the macOS login keychain via security(1), or the Secret Service via
+// secret-tool(1) elsewhere. A key is generated and stored on first use.
+func keychainKey() ([]byte, error) {
+	account := "claude"
+	if u, err := user.Current(); err == nil {
+		account = u.Username
+	}
+
+	var lookup, store *exec.Cmd
+	var stdin string
+	switch runtime.GOOS {
+	case "darwin":
+		lookup = exec.Command("security", "find-generic-password", "-a", account, "-s", keychainService, "-w")
+	case "linux", "freebsd", "openbsd", "netbsd":
+		lookup = exec.Command("secret-tool", "lookup", "service", keychainService, "account", account)
+	default:
+		return nil, fmt.Errorf("no OS keychain support on %s; set sessionEncryption to %q", runtime.GOOS, EncryptionPassphrase)
+	}
+	if out, err := lookup.Output(); err == nil {
+		if key, err := hex.DecodeString(strings.TrimSpace(string(out))); err == nil && len(key) == 32 {
+			return key, nil
+		}
+		return nil, fmt.Errorf("the keychain entry %q is not a session key", keychainService)
+	} else if _, ok := err.(*exec.ExitError); !ok {
+		return nil, fmt.Errorf("reading the keychain: %w", err)
+	}
+
+	// Not found: create one.
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("generating session key: %w", err)
+	}
+	secret := hex.EncodeToString(key)
+	if runtime.GOOS == "darwin" {
+		store = exec.Command("security", "add-generic-password", "-a", account, "-s", keychainService, "-w", secret)
+	} else {
+		store = exec.Command("secret-tool", "store", "--label="+keychainService, "service", keychainService, "account", account)
+		stdin = secret
+	}
+	store.Stdin = strings.NewReader(stdin)
+	var stderr bytes.Buffer
+	store.Stderr = &stderr
+	if err := store.Run(); err != nil {
+		return nil, fmt.Errorf("saving the session key in the keychain: %v %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return key, nil
+}
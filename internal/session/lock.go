@@ -0,0 +1,112 @@
+package session
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// LockedError reports that a session is open in another claude process.
+type LockedError struct {
+	ID  string
+	PID int // the other process, when it could be read from the lock file
+}
+
+func (e *LockedError) Error() string {
+	if e.PID > 0 {
+		return fmt.Sprintf("session %s is open in another claude process (pid %d)", e.ID, e.PID)
+	}
+	return fmt.Sprintf("session %s is open in another claude process", e.ID)
+}
+
+// lockPath returns the path of a session's lock file. It is a file next
+// to the metadata with either backend.
+func (s *Store) lockPath(id string) string {
+	return filepath.Join(s.dir, id+".lock")
+}
+
+// Lock claims a session for this process until Unlock or Close, so a
+// second process resuming it can't interleave its saves with this one's.
+// It takes an exclusive flock on the session's lock file and writes this
+// process's ID in it. A session held by another process gives a
+// *LockedError; one this store already holds is no error. On platforms
+// without flock, Lock always succeeds.
+func (s *Store) Lock(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.locks[id]; ok {
+		return nil
+	}
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return fmt.Errorf("creating session directory: %w", err)
+	}
+	f, err := os.OpenFile(s.lockPath(id), os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return fmt.Errorf("opening session lock: %w", err)
+	}
+	ok, err := flockFile(f)
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("locking session: %w", err)
+	}
+	if !ok {
+		data, _ := io.ReadAll(f)
+		f.Close()
+		pid, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+		return &LockedError{ID: id, PID: pid}
+	}
+	if err := f.Truncate(0); err == nil {
+		f.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	}
+
+	if s.locks == nil {
+		s.locks = make(map[string]*os.File)
+	}
+	s.locks[id] = f
+	return nil
+}
+
+// Unlock releases a session claimed by Lock. The lock file stays, since
+// removing it could let two processes lock different files of the same
+// name.
+func (s *Store) Unlock(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if f, ok := s.locks[id]; ok {
+		f.Close()
+		delete(s.locks, id)
+	}
+}
+
+// SetReadOnly makes the store skip writes to a session: Save,
+// AppendToolResult, and RecordChange do nothing for it. A process that
+// finds a session locked by another can still show and continue it this
+// way without corrupting the other's history.
+func (s *Store) SetReadOnly(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.readOnly == nil {
+		s.readOnly = make(map[string]bool)
+	}
+	s.readOnly[id] = true
+}
+
+// IsReadOnly reports whether SetReadOnly was called for the session.
+func (s *Store) IsReadOnly(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.readOnly[id]
+}
+
+// unlockAll releases every session this store holds.
+func (s *Store) unlockAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, f := range s.locks {
+		f.Close()
+		delete(s.locks, id)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package session
+
+import "os"
+
+// flockFile is a no-op on non-Unix platforms; sessions aren't locked.
+func flockFile(f *os.File) (bool, error) {
+	return true, nil
+}
@@ -0,0 +1,95 @@
+//go:build unix
+
+package session
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/anthropics/claude-code-go/internal/api"
+	"github.com/anthropics/claude-code-go/internal/tools"
+)
+
+func TestStoreLock(t *testing.T) {
+	dir := t.TempDir()
+	first := NewStoreWithDir(dir)
+	second := NewStoreWithDir(dir) // stands in for another process
+	sess := &Session{ID: "shared", Messages: []api.Message{api.NewTextMessage(api.RoleUser, "hello")}}
+	if err := first.Save(sess); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := first.Lock("shared"); err != nil {
+		t.Fatalf("Lock: %v", err)
+	}
+	if err := first.Lock("shared"); err != nil {
+		t.Errorf("locking a held session again: %v", err)
+	}
+	var locked *LockedError
+	if err := second.Lock("shared"); !errors.As(err, &locked) || locked.PID != os.Getpid() {
+		t.Fatalf("second Lock = %v, want a LockedError naming this process", err)
+	}
+	if err := second.Delete("shared"); !errors.As(err, &locked) {
+		t.Errorf("Delete of a locked session = %v, want a LockedError", err)
+	}
+
+	// A read-only store leaves the session alone.
+	second.SetReadOnly("shared")
+	changed := &Session{ID: "shared", Messages: []api.Message{api.NewTextMessage(api.RoleUser, "overwritten")}}
+	if err := second.Save(changed); err != nil {
+		t.Fatalf("read-only Save: %v", err)
+	}
+	second.AppendToolResult("shared", api.ContentBlock{Type: api.ContentTypeToolResult, ToolUseID: "toolu_1"})
+	second.RecordChange("shared", tools.UndoEntry{Path: "/tmp/x", Tool: "FileWrite"})
+	loaded, err := first.Load("shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if FirstUserMessage(loaded) != "hello" || len(loaded.Messages) != 1 {
+		t.Errorf("session changed by a read-only store: %+v", loaded.Messages)
+	}
+	if changes, _ := first.Changes("shared"); len(changes) != 0 {
+		t.Errorf("read-only store recorded %d changes", len(changes))
+	}
+
+	first.Close()
+	if err := second.Lock("shared"); err != nil {
+		t.Errorf("Lock after the holder closed: %v", err)
+	}
+	if err := second.Delete("shared"); err != nil {
+		t.Errorf("Delete by the holder: %v", err)
+	}
+}
+
+func TestPruneKeepsOpenSessions(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStoreWithDir(dir)
+	other := NewStoreWithDir(dir) // stands in for another process
+	for _, sess := range []*Session{
+		{ID: "new", UpdatedAt: time.Now()},
+		{ID: "mine", UpdatedAt: time.Now().AddDate(0, 0, -3)},
+		{ID: "theirs", UpdatedAt: time.Now().AddDate(0, 0, -3)},
+	} {
+		saveAt(t, store, sess)
+	}
+	if err := store.Lock("mine"); err != nil {
+		t.Fatal(err)
+	}
+	if err := other.Lock("theirs"); err != nil {
+		t.Fatal(err)
+	}
+	for _, dryRun := range []bool{true, false} {
+		res, err := store.Prune(RetentionPolicy{MaxAge: time.Hour}, dryRun)
+		if err != nil {
+			t.Fatalf("Prune: %v", err)
+		}
+		if len(res.Pruned) != 0 || len(res.Kept) != 2 {
+			t.Errorf("dry run %v: pruned %d, kept %+v; want both open sessions kept", dryRun, len(res.Pruned), res.Kept)
+		}
+	}
+	if !store.Exists("mine") || !store.Exists("theirs") {
+		t.Error("an open session was pruned")
+	}
+}
@@ -0,0 +1,396 @@
+package session
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/anthropics/claude-code-go/internal/api"
+	"github.com/anthropics/claude-code-go/internal/conversation"
+)
+
+// Message log record types. The log is append-only: a compaction (or any
+// other rewrite of earlier history) appends a reset record followed by the
+// new message window, so earlier history stays on disk but is not loaded.
+// Turn records are not affected by resets. Tool result records journal
+// each tool call as it finishes, until the message carrying the turn's
+// results is written; see AppendToolResult.
+const (
+	logRecordMessage    = "message"
+	logRecordReset      = "reset"
+	logRecordTurn       = "turn"
+	logRecordToolResult = "tool_result"
+)
+
+// logRecord is a single line of a session's message log. A large message
+// is stored gzipped in Gzip instead of in Message; see compressMessage.
+// With encryption on, a message or tool result is stored encrypted in Enc
+// instead; see Seal.
+type logRecord struct {
+	Type    string                     `json:"type"`
+	Message *api.Message               `json:"message,omitempty"`
+	Gzip    []byte                     `json:"gzip,omitempty"`
+	Enc     []byte                     `json:"enc,omitempty"`
+	Turn    *conversation.TurnMetadata `json:"turn,omitempty"`
+	Result  *api.ContentBlock          `json:"result,omitempty"`
+}
+
+// maxLogLine is the longest message log line that can be read back.
+var maxLogLine = 64 << 20
+
+// interruptedResult is the result recorded for a tool call that was still
+// running when the session ended.
+const interruptedResult = "Interrupted: the session ended before this tool call finished."
+
+// compressThreshold is the size of a message's JSON above which the store
+// keeps it gzipped. Below it, compression saves too little to be worth
+// making the log unreadable to grep.
+const compressThreshold = 16 << 10
+
+// gzipMagic starts every gzip stream. JSON never starts with it, which
+// lets stored messages be told apart by their first bytes.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// compressMessage returns a message's JSON gzipped, or nil to store it as
+// is: when it is under compressThreshold or doesn't compress by at least a
+// quarter (base64 in the message log takes back a third).
+func compressMessage(data []byte) []byte {
+	if len(data) <= compressThreshold {
+		return nil
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write(data)
+	if zw.Close() != nil || buf.Len() > len(data)*3/4 {
+		return nil
+	}
+	return buf.Bytes()
+}
+
+// decodeMessage parses a stored message, decrypting and gunzipping it
+// first if it was encrypted or compressed.
+func decodeMessage(data []byte) (api.Message, error) {
+	var msg api.Message
+	data, err := Open(data)
+	if err != nil {
+		return msg, err
+	}
+	if bytes.HasPrefix(data, gzipMagic) {
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return msg, fmt.Errorf("decompressing message: %w", err)
+		}
+		if data, err = io.ReadAll(zr); err != nil {
+			return msg, fmt.Errorf("decompressing message: %w", err)
+		}
+	}
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return msg, fmt.Errorf("parsing message: %w", err)
+	}
+	return msg, nil
+}
+
+// logState tracks what has been appended to a session's message log.
+type logState struct {
+	written int      // messages in the current window already on disk
+	last    [32]byte // fingerprint of the last written message
+	turns   int      // turn records already on disk
+	torn    bool     // the log ends in a partial line from a crash
+}
+
+// messageLogPath returns the path of a session's message log.
+func (s *Store) messageLogPath(id string) string {
+	return filepath.Join(s.dir, id+".messages.jsonl")
+}
+
+// messageFingerprint hashes a message so the store can detect when history
+// was rewritten rather than appended to.
+func messageFingerprint(msg api.Message) [32]byte {
+	return sha256.Sum256(append([]byte(msg.Role+"\x00"), msg.Content...))
+}
+
+// appendMessageLog appends messages added since the last save. If earlier
+// messages changed (e.g. compaction replaced them with a summary), a reset
+// record is written followed by the full current window.
+func (s *Store) appendMessageLog(session *Session) error {
+	path := s.messageLogPath(session.ID)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.logs == nil {
+		s.logs = make(map[string]*logState)
+	}
+	state, ok := s.logs[session.ID]
+	if !ok {
+		var err error
+		if state, err = s.readLogState(path); err != nil {
+			return err
+		}
+		s.logs[session.ID] = state
+	}
+
+	msgs := session.Messages
+	var buf bytes.Buffer
+	if state.torn {
+		buf.WriteByte('\n') // keep the first new record off the partial line
+	}
+	if state.written > len(msgs) ||
+		(state.written > 0 && messageFingerprint(msgs[state.written-1]) != state.last) {
+		line, _ := json.Marshal(logRecord{Type: logRecordReset})
+		buf.Write(line)
+		buf.WriteByte('\n')
+		state.written = 0
+	}
+
+	for i := state.written; i < len(msgs); i++ {
+		data, err := json.Marshal(&msgs[i])
+		if err != nil {
+			return fmt.Errorf("marshaling message: %w", err)
+		}
+		rec := logRecord{Type: logRecordMessage, Message: &msgs[i]}
+		if z := compressMessage(data); z != nil {
+			rec = logRecord{Type: logRecordMessage, Gzip: z}
+			data = z
+		}
+		if Encrypted() {
+			enc, err := Seal(data)
+			if err != nil {
+				return err
+			}
+			rec = logRecord{Type: logRecordMessage, Enc: enc}
+		}
+		line, err := json.Marshal(rec)
+		if err != nil {
+			return fmt.Errorf("marshaling message: %w", err)
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	if state.turns > len(session.Turns) {
+		state.turns = len(session.Turns)
+	}
+	for i := state.turns; i < len(session.Turns); i++ {
+		line, err := json.Marshal(logRecord{Type: logRecordTurn, Turn: &session.Turns[i]})
+		if err != nil {
+			return fmt.Errorf("marshaling turn: %w", err)
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	if buf.Len() == 0 {
+		return nil
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("opening message log: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("writing message log: %w", err)
+	}
+
+	state.written = len(msgs)
+	if len(msgs) > 0 {
+		state.last = messageFingerprint(msgs[len(msgs)-1])
+	}
+	state.turns = len(session.Turns)
+	state.torn = false
+	return nil
+}
+
+// readLogState reads how much of a session is already in its message log.
+// The count is of the messages as written, without the results
+// completeInterruptedTurn adds on load, so that saving a resumed session
+// appends those. A log that can't be read to the end is an error: counting
+// only part of it would append the rest of the history a second time.
+func (s *Store) readLogState(path string) (*logState, error) {
+	state := &logState{}
+	msgs, turns, _, err := readMessageLog(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	state.written = len(msgs)
+	if len(msgs) > 0 {
+		state.last = messageFingerprint(msgs[len(msgs)-1])
+	}
+	state.turns = len(turns)
+	if f, err := os.Open(path); err == nil {
+		last := make([]byte, 1)
+		if info, err := f.Stat(); err == nil && info.Size() > 0 {
+			if _, err := f.ReadAt(last, info.Size()-1); err == nil {
+				state.torn = last[0] != '\n'
+			}
+		}
+		f.Close()
+	}
+	return state, nil
+}
+
+// AppendToolResult journals the result of one tool call as soon as it
+// finishes, ahead of the save that writes the turn's results together. If
+// the process dies before that save, loading the session keeps the
+// results journaled so far; see completeInterruptedTurn. It is safe to
+// call from several goroutines.
+func (s *Store) AppendToolResult(id string, result api.ContentBlock) error {
+	if s.IsReadOnly(id) {
+		return nil
+	}
+	if s.db != nil {
+		return s.appendToolResultSQL(id, result)
+	}
+	rec := logRecord{Type: logRecordToolResult, Result: &result}
+	if Encrypted() {
+		data, err := json.Marshal(result)
+		if err != nil {
+			return fmt.Errorf("marshaling tool result: %w", err)
+		}
+		if rec.Enc, err = Seal(data); err != nil {
+			return err
+		}
+		rec.Result = nil
+	}
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("marshaling tool result: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path := s.messageLogPath(id)
+	if s.logs == nil {
+		s.logs = make(map[string]*logState)
+	}
+	state, ok := s.logs[id]
+	if !ok {
+		var err error
+		if state, err = s.readLogState(path); err != nil {
+			return err
+		}
+		s.logs[id] = state
+	}
+	if state.torn {
+		line = append([]byte{'\n'}, line...)
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("opening message log: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("writing message log: %w", err)
+	}
+	state.torn = false
+	return nil
+}
+
+// completeInterruptedTurn closes a turn that was cut off while its tools
+// ran. If the last message is an assistant message with tool calls, it
+// appends their results, the ones journaled as they finished and an
+// interrupted result for the rest, so the history is valid to continue.
+func completeInterruptedTurn(msgs []api.Message, pending []api.ContentBlock) []api.Message {
+	if len(msgs) == 0 || msgs[len(msgs)-1].Role != api.RoleAssistant {
+		return msgs
+	}
+	var blocks []api.ContentBlock
+	if json.Unmarshal(msgs[len(msgs)-1].Content, &blocks) != nil {
+		return msgs
+	}
+	done := make(map[string]api.ContentBlock, len(pending))
+	for _, r := range pending {
+		done[r.ToolUseID] = r
+	}
+	var results []api.ContentBlock
+	for _, b := range blocks {
+		if b.Type != api.ContentTypeToolUse {
+			continue
+		}
+		if r, ok := done[b.ID]; ok {
+			results = append(results, r)
+		} else {
+			results = append(results, conversation.MakeToolResult(b.ID, interruptedResult, true))
+		}
+	}
+	if len(results) == 0 {
+		return msgs
+	}
+	return append(msgs, api.NewBlockMessage(api.RoleUser, results))
+}
+
+// readMessageLog replays a message log, returning the messages after the
+// last reset record, all turn metadata, and the tool results journaled
+// since the last message. A truncated line (from a crash mid-write) is
+// ignored; an encrypted record that can't be decrypted is an error, so a
+// session is never loaded, and then saved, without it.
+func readMessageLog(path string) ([]api.Message, []conversation.TurnMetadata, []api.ContentBlock, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer f.Close()
+
+	var msgs []api.Message
+	var turns []conversation.TurnMetadata
+	var pending []api.ContentBlock
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLogLine)
+	for scanner.Scan() {
+		var rec logRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			continue
+		}
+		switch rec.Type {
+		case logRecordReset:
+			msgs, pending = nil, nil
+		case logRecordMessage:
+			if rec.Enc != nil {
+				msg, err := decodeMessage(rec.Enc)
+				if err != nil {
+					return nil, nil, nil, err
+				}
+				msgs = append(msgs, msg)
+			} else if rec.Gzip != nil {
+				if msg, err := decodeMessage(rec.Gzip); err == nil {
+					msgs = append(msgs, msg)
+				}
+			} else if rec.Message != nil {
+				msgs = append(msgs, *rec.Message)
+			}
+			pending = nil
+		case logRecordTurn:
+			if rec.Turn != nil {
+				turns = append(turns, *rec.Turn)
+			}
+		case logRecordToolResult:
+			if rec.Enc != nil {
+				data, err := Open(rec.Enc)
+				if err != nil {
+					return nil, nil, nil, err
+				}
+				var result api.ContentBlock
+				if err := json.Unmarshal(data, &result); err != nil {
+					return nil, nil, nil, fmt.Errorf("parsing tool result: %w", err)
+				}
+				pending = append(pending, result)
+			} else if rec.Result != nil {
+				pending = append(pending, *rec.Result)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return msgs, turns, pending, fmt.Errorf("reading message log: %w", err)
+	}
+	return msgs, turns, pending, nil
+}
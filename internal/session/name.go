@@ -0,0 +1,110 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// namePattern is what a session name may look like: letters, digits, and
+// ".-_", starting with a letter or digit.
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// ValidateName checks that name can name a session. Names are for typing
+// on the command line, and one made of digits alone could be taken for a
+// session ID.
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("invalid session name %q: use up to 64 letters, digits, and . - _", name)
+	}
+	if strings.Trim(name, "0123456789") == "" {
+		return fmt.Errorf("invalid session name %q: a name can't be only digits", name)
+	}
+	return nil
+}
+
+// CheckName reports whether session id can take name: it must be valid
+// and not used by another session in the project.
+func (s *Store) CheckName(id, name string) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	other, err := s.findName(name)
+	if err != nil {
+		return err
+	}
+	if other != "" && other != id {
+		return fmt.Errorf("session %s is already named %q", other, name)
+	}
+	return nil
+}
+
+// Rename sets the name of a saved session, so it can be resumed with
+// `claude -r <name>`, without changing when it was last updated. An empty
+// name removes it. A session open in another process can't be renamed,
+// since its next save would put the old name back.
+func (s *Store) Rename(id, name string) error {
+	if !s.Exists(id) {
+		return fmt.Errorf("session %s not found", id)
+	}
+	if name != "" {
+		if err := s.CheckName(id, name); err != nil {
+			return err
+		}
+	}
+	return s.updateMeta(id, func(meta *Session) { meta.Name = name })
+}
+
+// Resolve returns the ID of the session ref refers to: a session ID, or
+// the name of a session in the project.
+func (s *Store) Resolve(ref string) (string, error) {
+	if ref == "" || strings.ContainsAny(ref, `/\`) {
+		return "", fmt.Errorf("invalid session ID %q", ref)
+	}
+	if s.Exists(ref) {
+		return ref, nil
+	}
+	id, err := s.findName(ref)
+	if err != nil {
+		return "", err
+	}
+	if id == "" {
+		return "", fmt.Errorf("session %s not found", ref)
+	}
+	return id, nil
+}
+
+// findName returns the ID of the session named name, or "" if none is.
+// Only metadata is read, so it stays cheap with many long sessions.
+func (s *Store) findName(name string) (string, error) {
+	if s.db != nil {
+		return s.findNameSQL(name)
+	}
+	entries, err := os.ReadDir(s.dir)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("reading session directory: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		var meta struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		}
+		if json.Unmarshal(data, &meta) == nil && meta.Name == name {
+			return meta.ID, nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,106 @@
+package session
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anthropics/claude-code-go/internal/api"
+)
+
+func TestValidateName(t *testing.T) {
+	for _, name := range []string{"bugfix-auth", "v1.2_rc", "7up"} {
+		if err := ValidateName(name); err != nil {
+			t.Errorf("ValidateName(%q) = %v, want nil", name, err)
+		}
+	}
+	for _, name := range []string{"", "-x", "has space", "a/b", "1712345678", strings.Repeat("a", 65)} {
+		if err := ValidateName(name); err == nil {
+			t.Errorf("ValidateName(%q) = nil, want an error", name)
+		}
+	}
+}
+
+func TestStoreRename(t *testing.T) {
+	for _, backend := range []string{"files", "sqlite"} {
+		t.Run(backend, func(t *testing.T) {
+			store := NewStoreWithDir(t.TempDir())
+			if backend == "sqlite" {
+				store = newSQLiteStore(t, t.TempDir())
+			}
+			for _, id := range []string{"s1", "s2"} {
+				if err := store.Save(&Session{ID: id, Messages: []api.Message{api.NewTextMessage("user", id)}}); err != nil {
+					t.Fatalf("Save: %v", err)
+				}
+			}
+			before, _ := store.Load("s1")
+
+			if err := store.Rename("s1", "bugfix-auth"); err != nil {
+				t.Fatalf("Rename: %v", err)
+			}
+			if id, err := store.Resolve("bugfix-auth"); err != nil || id != "s1" {
+				t.Errorf("Resolve(name) = %q, %v; want s1", id, err)
+			}
+			if id, err := store.Resolve("s2"); err != nil || id != "s2" {
+				t.Errorf("Resolve(id) = %q, %v; want s2", id, err)
+			}
+			if _, err := store.Resolve("nope"); err == nil {
+				t.Error("Resolve of an unknown name should fail")
+			}
+			loaded, _ := store.Load("s1")
+			if loaded.Name != "bugfix-auth" || !loaded.UpdatedAt.Equal(before.UpdatedAt) {
+				t.Errorf("loaded name %q, updated %v; want the name and the old time %v", loaded.Name, loaded.UpdatedAt, before.UpdatedAt)
+			}
+
+			if err := store.Rename("s2", "bugfix-auth"); err == nil || !strings.Contains(err.Error(), "already named") {
+				t.Errorf("duplicate name = %v, want an error", err)
+			}
+			if err := store.Rename("s1", "bugfix-auth"); err != nil {
+				t.Errorf("renaming to its own name = %v, want nil", err)
+			}
+			if err := store.Rename("s1", ""); err != nil {
+				t.Fatalf("clearing the name: %v", err)
+			}
+			if _, err := store.Resolve("bugfix-auth"); err == nil {
+				t.Error("a cleared name should no longer resolve")
+			}
+		})
+	}
+}
+
+func TestNameNotCopied(t *testing.T) {
+	store := NewStoreWithDir(t.TempDir())
+	sess := &Session{ID: "orig", Name: "feature", CreatedAt: time.Now(), Messages: []api.Message{api.NewTextMessage("user", "hi")}}
+	if err := store.Save(sess); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	fork, err := store.Fork("orig")
+	if err != nil {
+		t.Fatalf("Fork: %v", err)
+	}
+	if fork.Name != "" {
+		t.Errorf("fork name = %q, want none", fork.Name)
+	}
+
+	// An imported session keeps its name unless it is taken here.
+	var buf bytes.Buffer
+	if err := store.ExportBundle("orig", &buf); err != nil {
+		t.Fatalf("ExportBundle: %v", err)
+	}
+	imported, err := store.importBundle(bytes.NewReader(buf.Bytes()), "/p", GenerateID())
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if imported.Session.Name != "" {
+		t.Errorf("imported name = %q, want it dropped", imported.Session.Name)
+	}
+	other := NewStoreWithDir(t.TempDir())
+	imported, err = other.ImportBundle(bytes.NewReader(buf.Bytes()), "/p")
+	if err != nil {
+		t.Fatalf("ImportBundle: %v", err)
+	}
+	if imported.Session.Name != "feature" {
+		t.Errorf("imported name = %q, want feature", imported.Session.Name)
+	}
+}
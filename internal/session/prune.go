@@ -0,0 +1,258 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RetentionPolicy limits the sessions a project keeps. A zero field sets
+// no limit.
+type RetentionPolicy struct {
+	MaxAge   time.Duration // since the session was last updated
+	MaxCount int           // sessions, counting from the newest
+	MaxBytes int64         // total size on disk, counting from the newest
+}
+
+// IsZero reports whether the policy sets no limit.
+func (p RetentionPolicy) IsZero() bool {
+	return p.MaxAge <= 0 && p.MaxCount <= 0 && p.MaxBytes <= 0
+}
+
+// pruneMinAge is how recently updated a session can be and still be
+// pruned for the count or size limits: a day, so a burst of new sessions
+// never pushes out the ones being worked on.
+const pruneMinAge = 24 * time.Hour
+
+// PrunedSession is a session past a retention limit.
+type PrunedSession struct {
+	Session *Session // metadata only; Messages may be empty
+	Bytes   int64    // size on disk
+	Reason  string   // the limit it is past
+}
+
+// PruneResult lists what Store.Prune deleted, or would delete in a dry
+// run, and the sessions past a limit that it kept.
+type PruneResult struct {
+	Pruned []PrunedSession
+	Kept   []PrunedSession // Reason says why it was kept
+}
+
+// Freed returns the bytes taken by the pruned sessions.
+func (r *PruneResult) Freed() int64 {
+	var n int64
+	for _, p := range r.Pruned {
+		n += p.Bytes
+	}
+	return n
+}
+
+// Prune deletes the sessions past the policy's limits. The count and size
+// limits keep the newest sessions and delete the older ones. Named and
+// pinned sessions are set aside: never deleted, and not counted toward the
+// limits. Also kept are the most recent session, so `claude -c` always has
+// something to continue, sessions open in any process, and, for the count
+// and size limits, sessions updated in the last day. A dry run only
+// reports what would go. Pruning stops at the first session that fails to
+// delete.
+func (s *Store) Prune(p RetentionPolicy, dryRun bool) (*PruneResult, error) {
+	res := &PruneResult{}
+	if p.IsZero() {
+		return res, nil
+	}
+	metas, err := s.listMeta()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	var count int
+	var size int64
+	for i, meta := range metas {
+		ps := PrunedSession{Session: meta, Bytes: s.sessionSize(meta.ID)}
+		age := now.Sub(meta.UpdatedAt)
+		switch {
+		case p.MaxAge > 0 && age > p.MaxAge:
+			ps.Reason = fmt.Sprintf("not updated for %d days", int(age/(24*time.Hour)))
+		case p.MaxCount > 0 && count >= p.MaxCount && age > pruneMinAge:
+			ps.Reason = fmt.Sprintf("beyond the newest %d sessions", p.MaxCount)
+		case p.MaxBytes > 0 && size+ps.Bytes > p.MaxBytes && age > pruneMinAge:
+			ps.Reason = fmt.Sprintf("beyond the newest %s", FormatBytes(p.MaxBytes))
+		}
+		exempt := pruneExemption(meta)
+		if exempt == "" && ps.Reason == "" {
+			count++
+			size += ps.Bytes
+			continue
+		}
+		if exempt == "" && i == 0 {
+			exempt = "most recent session"
+		}
+		if exempt == "" {
+			exempt = s.openExemption(meta.ID, dryRun)
+		}
+		if exempt != "" {
+			if ps.Reason != "" {
+				ps.Reason = exempt
+				res.Kept = append(res.Kept, ps)
+			}
+			continue
+		}
+		if !dryRun {
+			if err := s.Delete(meta.ID); err != nil {
+				if _, ok := err.(*LockedError); ok {
+					ps.Reason = "open in another process"
+					res.Kept = append(res.Kept, ps)
+					continue
+				}
+				return res, err
+			}
+		}
+		res.Pruned = append(res.Pruned, ps)
+	}
+	return res, nil
+}
+
+// pruneExemption says why the user wants a session kept, or returns "".
+func pruneExemption(meta *Session) string {
+	switch {
+	case meta.Name != "":
+		return "named " + meta.Name
+	case meta.Pinned:
+		return "pinned"
+	}
+	return ""
+}
+
+// openExemption reports a session open in a process, or returns "".
+// Delete refuses one open in another process, so only a dry run has to
+// check for that.
+func (s *Store) openExemption(id string, dryRun bool) string {
+	s.mu.Lock()
+	_, held := s.locks[id]
+	s.mu.Unlock()
+	if held {
+		return "open in this process"
+	}
+	if dryRun {
+		if err := s.Lock(id); err != nil {
+			return "open in another process"
+		}
+		s.Unlock(id)
+	}
+	return ""
+}
+
+// Pin sets whether a saved session is pinned, which keeps Prune from
+// deleting it, without changing when it was last updated.
+func (s *Store) Pin(id string, pinned bool) error {
+	if !s.Exists(id) {
+		return fmt.Errorf("session %s not found", id)
+	}
+	return s.updateMeta(id, func(meta *Session) { meta.Pinned = pinned })
+}
+
+// pruneStampPath returns the path of the file whose modification time
+// records the last automatic prune.
+func (s *Store) pruneStampPath() string {
+	return filepath.Join(s.dir, "prune.stamp")
+}
+
+// AutoPrune runs Prune if the store hasn't been pruned automatically in
+// the last day, so starting claude stays cheap. It returns nil when it
+// didn't run.
+func (s *Store) AutoPrune(p RetentionPolicy) (*PruneResult, error) {
+	if p.IsZero() {
+		return nil, nil
+	}
+	stamp := s.pruneStampPath()
+	if info, err := os.Stat(stamp); err == nil && time.Since(info.ModTime()) < 24*time.Hour {
+		return nil, nil
+	}
+	if _, err := os.Stat(s.dir); os.IsNotExist(err) {
+		return nil, nil // no sessions yet
+	}
+	// Stamp first, so a prune that fails isn't retried at every start.
+	if err := os.WriteFile(stamp, nil, 0600); err != nil {
+		return nil, fmt.Errorf("recording prune: %w", err)
+	}
+	now := time.Now()
+	os.Chtimes(stamp, now, now)
+	return s.Prune(p, false)
+}
+
+// listMeta returns the metadata of every session, newest first, without
+// reading messages.
+func (s *Store) listMeta() ([]*Session, error) {
+	if s.db != nil {
+		return s.listMetaSQL()
+	}
+	entries, err := os.ReadDir(s.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading session directory: %w", err)
+	}
+	var metas []*Session
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		var meta Session
+		if json.Unmarshal(data, &meta) != nil || meta.ID == "" {
+			continue // skip corrupt files, as List does
+		}
+		metas = append(metas, &meta)
+	}
+	sort.Slice(metas, func(i, j int) bool { return metas[i].UpdatedAt.After(metas[j].UpdatedAt) })
+	return metas, nil
+}
+
+// sessionSize returns the bytes a session takes on disk: its files, and
+// with SQLite its rows.
+func (s *Store) sessionSize(id string) int64 {
+	var n int64
+	paths := []string{s.TasksDir(id), s.changeLedgerPath(id), s.contextPath(id)}
+	if s.db != nil {
+		n += s.sessionSizeSQL(id)
+	} else {
+		paths = append(paths, filepath.Join(s.dir, id+".json"), s.messageLogPath(id))
+	}
+	if s.transcriptDir != "" {
+		paths = append(paths, filepath.Join(s.transcriptDir, id+".jsonl"))
+	}
+	for _, path := range paths {
+		filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if info, err := d.Info(); err == nil && !d.IsDir() {
+				n += info.Size()
+			}
+			return nil
+		})
+	}
+	return n
+}
+
+// FormatBytes formats a size as B, KB, MB, or GB.
+func FormatBytes(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
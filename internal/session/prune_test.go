@@ -0,0 +1,154 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/anthropics/claude-code-go/internal/api"
+)
+
+// saveAt saves a session without Save's update of UpdatedAt.
+func saveAt(t *testing.T, store *Store, sess *Session) {
+	t.Helper()
+	err := os.MkdirAll(store.dir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if store.db != nil {
+		err = store.writeSQL(sess)
+	} else if err = store.appendMessageLog(sess); err == nil {
+		err = store.writeMeta(sess)
+	}
+	if err != nil {
+		t.Fatalf("saving %s: %v", sess.ID, err)
+	}
+}
+
+func TestStorePrune(t *testing.T) {
+	for _, backend := range []string{"files", "sqlite"} {
+		t.Run(backend, func(t *testing.T) {
+			store := NewStoreWithDir(t.TempDir())
+			if backend == "sqlite" {
+				store = newSQLiteStore(t, t.TempDir())
+			}
+			ago := func(days int) time.Time { return time.Now().AddDate(0, 0, -days) }
+			for _, sess := range []*Session{
+				{ID: "new", UpdatedAt: ago(0)},
+				{ID: "a", UpdatedAt: ago(2)},
+				{ID: "b", UpdatedAt: ago(10)},
+				{ID: "c", UpdatedAt: ago(40)},
+				{ID: "named", Name: "keep-me", UpdatedAt: ago(50)},
+				{ID: "pinned", UpdatedAt: ago(60)},
+			} {
+				sess.Messages = []api.Message{api.NewTextMessage(api.RoleUser, "hello from "+sess.ID)}
+				saveAt(t, store, sess)
+			}
+			if err := store.Pin("pinned", true); err != nil {
+				t.Fatalf("Pin: %v", err)
+			}
+			ids := func(ps []PrunedSession) []string {
+				var out []string
+				for _, p := range ps {
+					out = append(out, p.Session.ID)
+				}
+				return out
+			}
+
+			res, err := store.Prune(RetentionPolicy{MaxAge: 30 * 24 * time.Hour}, true)
+			if err != nil {
+				t.Fatalf("Prune: %v", err)
+			}
+			if got := ids(res.Pruned); !reflect.DeepEqual(got, []string{"c"}) {
+				t.Errorf("dry run by age pruned %v, want [c]", got)
+			}
+			if got := ids(res.Kept); !reflect.DeepEqual(got, []string{"named", "pinned"}) {
+				t.Errorf("dry run by age kept %v, want [named pinned]", got)
+			}
+			if !store.Exists("c") {
+				t.Error("a dry run deleted a session")
+			}
+			if res.Pruned[0].Bytes == 0 || res.Freed() != res.Pruned[0].Bytes {
+				t.Errorf("pruned size = %d, freed %d", res.Pruned[0].Bytes, res.Freed())
+			}
+
+			// Named and pinned sessions don't take up the count.
+			res, err = store.Prune(RetentionPolicy{MaxCount: 2}, true)
+			if err != nil {
+				t.Fatalf("Prune: %v", err)
+			}
+			if got := ids(res.Pruned); !reflect.DeepEqual(got, []string{"b", "c"}) {
+				t.Errorf("dry run by count pruned %v, want [b c]", got)
+			}
+
+			// Sessions updated in the last day outlast the count limit.
+			res, err = store.Prune(RetentionPolicy{MaxCount: 1}, true)
+			if err != nil {
+				t.Fatalf("Prune: %v", err)
+			}
+			if got := ids(res.Pruned); !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+				t.Errorf("dry run by count 1 pruned %v, want [a b c]", got)
+			}
+
+			res, err = store.Prune(RetentionPolicy{MaxAge: 5 * 24 * time.Hour}, false)
+			if err != nil {
+				t.Fatalf("Prune: %v", err)
+			}
+			if got := ids(res.Pruned); !reflect.DeepEqual(got, []string{"b", "c"}) {
+				t.Errorf("prune by age deleted %v, want [b c]", got)
+			}
+			for id, want := range map[string]bool{"new": true, "a": true, "b": false, "c": false, "named": true, "pinned": true} {
+				if store.Exists(id) != want {
+					t.Errorf("Exists(%s) = %v, want %v", id, !want, want)
+				}
+			}
+
+			// The most recent session is kept, however old.
+			res, err = store.Prune(RetentionPolicy{MaxAge: time.Nanosecond}, false)
+			if err != nil {
+				t.Fatalf("Prune: %v", err)
+			}
+			if got := ids(res.Pruned); !reflect.DeepEqual(got, []string{"a"}) {
+				t.Errorf("prune of everything deleted %v, want [a]", got)
+			}
+			if !store.Exists("new") {
+				t.Error("the most recent session was pruned")
+			}
+		})
+	}
+}
+
+func TestStoreAutoPrune(t *testing.T) {
+	store := NewStoreWithDir(filepath.Join(t.TempDir(), "sessions"))
+	policy := RetentionPolicy{MaxAge: 24 * time.Hour}
+	if res, err := store.AutoPrune(policy); res != nil || err != nil {
+		t.Errorf("AutoPrune without sessions = %+v, %v; want nothing done", res, err)
+	}
+	for _, sess := range []*Session{{ID: "old", UpdatedAt: time.Now().AddDate(0, 0, -3)}, {ID: "new", UpdatedAt: time.Now()}} {
+		saveAt(t, store, sess)
+	}
+	res, err := store.AutoPrune(policy)
+	if err != nil || res == nil || len(res.Pruned) != 1 {
+		t.Fatalf("AutoPrune = %+v, %v; want old pruned", res, err)
+	}
+	saveAt(t, store, &Session{ID: "old2", UpdatedAt: time.Now().AddDate(0, 0, -3)})
+	if res, err := store.AutoPrune(policy); res != nil || err != nil {
+		t.Errorf("second AutoPrune the same day = %+v, %v; want nothing done", res, err)
+	}
+	if !store.Exists("old2") {
+		t.Error("a second AutoPrune the same day deleted a session")
+	}
+	if res, err := store.AutoPrune(RetentionPolicy{}); res != nil || err != nil {
+		t.Errorf("AutoPrune without limits = %+v, %v", res, err)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	for n, want := range map[int64]string{512: "512 B", 3 << 10: "3.0 KB", 5 << 20: "5.0 MB", 3 << 30: "3.0 GB"} {
+		if got := FormatBytes(n); got != want {
+			t.Errorf("FormatBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
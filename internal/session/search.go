@@ -0,0 +1,265 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// snippetContext is how many characters of context a search snippet
+// shows on each side of the match.
+const snippetContext = 40
+
+// Filter selects sessions for `claude sessions list` and `search`.
+// Zero fields don't filter.
+type Filter struct {
+	Since time.Time // last updated at or after
+	Until time.Time // last updated before
+	Text  string    // case-insensitive, in the title or any message
+}
+
+// Match is a session selected by a Filter. Snippet shows where Text
+// matched in a message; it is empty when the filter has no text or the
+// title matched, since listings show the title anyway.
+type Match struct {
+	Session *Session
+	Snippet string
+}
+
+// Search returns the store's sessions that pass the filter, newest first.
+// A SQLite store answers from its indexes; otherwise every session is
+// loaded and filtered.
+func (s *Store) Search(f Filter) ([]Match, error) {
+	if s.db != nil {
+		return s.searchSQL(f)
+	}
+	sessions, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	return f.Apply(sessions), nil
+}
+
+// Apply returns the sessions that pass the filter, in the given order.
+func (f Filter) Apply(sessions []*Session) []Match {
+	var out []Match
+	for _, sess := range sessions {
+		if !f.Since.IsZero() && sess.UpdatedAt.Before(f.Since) {
+			continue
+		}
+		if !f.Until.IsZero() && !sess.UpdatedAt.Before(f.Until) {
+			continue
+		}
+		if f.Text == "" {
+			out = append(out, Match{Session: sess})
+			continue
+		}
+		if snippet, ok := searchSession(sess, f.Text); ok {
+			out = append(out, Match{Session: sess, Snippet: snippet})
+		}
+	}
+	return out
+}
+
+// searchSession looks for text in the session's title, then in its
+// messages: text, thinking, tool inputs, and tool results.
+func searchSession(sess *Session, text string) (string, bool) {
+	query := strings.Map(unicode.ToLower, text)
+	if _, ok := findSnippet(sess.Title, query); ok {
+		return "", true
+	}
+	for _, t := range exportTurns(sess.Messages) {
+		for _, p := range t.parts {
+			if p.kind == "image" {
+				continue
+			}
+			if snippet, ok := findSnippet(p.title+"\n"+p.body, query); ok {
+				return snippet, true
+			}
+		}
+	}
+	return "", false
+}
+
+// findSnippet returns the text around the first case-insensitive match of
+// query (already lowered with unicode.ToLower) in s, on one line.
+func findSnippet(s, query string) (string, bool) {
+	// Lowering rune by rune keeps rune offsets the same in s and lower.
+	runes := []rune(s)
+	lower := strings.Map(unicode.ToLower, s)
+	i := strings.Index(lower, query)
+	if i < 0 {
+		return "", false
+	}
+	start := len([]rune(lower[:i]))
+	end := start + len([]rune(query))
+	from, to := max(start-snippetContext, 0), min(end+snippetContext, len(runes))
+
+	snippet := strings.Join(strings.Fields(string(runes[from:to])), " ")
+	if from > 0 {
+		snippet = "..." + snippet
+	}
+	if to < len(runes) {
+		snippet += "..."
+	}
+	return snippet, true
+}
+
+// ParseFilterTime parses a --since or --until value: a date (2006-01-02),
+// a date and time (2006-01-02T15:04), or an age such as 7d, 12h, or 30m
+// counted back from now. As an upper bound, a date means the end of that
+// day.
+func ParseFilterTime(s string, now time.Time, upper bool) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		if upper {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02T15:04", s, time.Local); err == nil {
+		return t, nil
+	}
+	if n, err := strconv.Atoi(strings.TrimSuffix(s, "d")); err == nil && strings.HasSuffix(s, "d") && n >= 0 {
+		return now.AddDate(0, 0, -n), nil
+	}
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (use YYYY-MM-DD, YYYY-MM-DDTHH:MM, or an age like 7d or 12h)", s)
+}
+
+// Delete removes a session: its metadata, message log, saved background
+// tasks, change ledger, lock file, and transcript. A session open in
+// another process is refused with a *LockedError.
+func (s *Store) Delete(id string) error {
+	if id == "" || strings.ContainsAny(id, `/\`) {
+		return fmt.Errorf("invalid session ID %q", id)
+	}
+	if s.Exists(id) {
+		if err := s.Lock(id); err != nil {
+			return err
+		}
+		defer s.Unlock(id)
+	}
+	var paths []string
+	if s.db != nil {
+		if err := s.deleteSQL(id); err != nil {
+			return err
+		}
+	} else {
+		if err := os.Remove(filepath.Join(s.dir, id+".json")); err != nil {
+			if os.IsNotExist(err) {
+				return fmt.Errorf("session %s not found", id)
+			}
+			return fmt.Errorf("deleting session: %w", err)
+		}
+		paths = append(paths, s.messageLogPath(id))
+	}
+	paths = append(paths, s.TasksDir(id), s.changeLedgerPath(id), s.contextPath(id), s.lockPath(id))
+	if s.transcriptDir != "" { // also when encryption has disabled TranscriptPath
+		paths = append(paths, filepath.Join(s.transcriptDir, id+".jsonl"))
+	}
+	s.Unlock(id) // so the lock file can be removed on Windows
+	for _, path := range paths {
+		if err := os.RemoveAll(path); err != nil {
+			return fmt.Errorf("deleting session: %w", err)
+		}
+	}
+
+	s.mu.Lock()
+	delete(s.logs, id)
+	delete(s.transcripts, id)
+	delete(s.ledgers, id)
+	s.mu.Unlock()
+	return nil
+}
+
+// AllStores returns a store for each project with saved sessions, for
+// commands that look across projects. Their transcripts are not known;
+// use NewStore with a session's CWD to reach them.
+func AllStores() ([]*Store, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("getting home directory: %w", err)
+	}
+	dirs, err := filepath.Glob(filepath.Join(home, ".claude", "projects", "*", "sessions"))
+	if err != nil {
+		return nil, err
+	}
+	stores := make([]*Store, 0, len(dirs))
+	for _, dir := range dirs {
+		store := NewStoreWithDir(dir)
+		if err := store.openExistingSQLite(); err != nil {
+			return nil, err
+		}
+		stores = append(stores, store)
+	}
+	return stores, nil
+}
+
+// ListAll returns the sessions of every project, newest first. Each
+// session's CWD says which project it belongs to.
+func ListAll() ([]*Session, error) {
+	stores, err := AllStores()
+	if err != nil {
+		return nil, err
+	}
+	var all []*Session
+	for _, store := range stores {
+		sessions, err := store.List()
+		store.Close()
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, sessions...)
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].UpdatedAt.After(all[j].UpdatedAt) })
+	return all, nil
+}
+
+// FindSession loads a session by ID from whichever project has it. A
+// session name is looked up in every project too, and must name a session
+// in only one of them.
+func FindSession(id string) (*Session, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) {
+		return nil, fmt.Errorf("invalid session ID %q", id)
+	}
+	stores, err := AllStores()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		for _, store := range stores {
+			store.Close()
+		}
+	}()
+	for _, store := range stores {
+		if store.Exists(id) {
+			return store.Load(id)
+		}
+	}
+	var found *Session
+	for _, store := range stores {
+		named, err := store.findName(id)
+		if err != nil || named == "" {
+			continue
+		}
+		sess, err := store.Load(named)
+		if err != nil {
+			return nil, err
+		}
+		if found != nil {
+			return nil, fmt.Errorf("sessions in %s and %s are both named %q; resume by ID", found.CWD, sess.CWD, id)
+		}
+		found = sess
+	}
+	if found == nil {
+		return nil, fmt.Errorf("session %s not found", id)
+	}
+	return found, nil
+}
@@ -0,0 +1,132 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/anthropics/claude-code-go/internal/api"
+)
+
+func TestFilterApply(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 10, d, 12, 0, 0, 0, time.UTC) }
+	sessions := []*Session{
+		{ID: "a", UpdatedAt: day(10), Title: "Fix the login test", Messages: []api.Message{api.NewTextMessage(api.RoleUser, "hi")}},
+		{ID: "b", UpdatedAt: day(5), Messages: []api.Message{
+			api.NewTextMessage(api.RoleUser, "Quick question about the JSON parser in internal/config: why does it reject trailing commas in arrays? It used to accept them before the upgrade."),
+		}},
+		{ID: "c", UpdatedAt: day(1), Messages: []api.Message{api.NewTextMessage(api.RoleUser, "nothing here")}},
+	}
+	ids := func(ms []Match) string {
+		var s string
+		for _, m := range ms {
+			s += m.Session.ID
+		}
+		return s
+	}
+
+	if got := ids((Filter{}).Apply(sessions)); got != "abc" {
+		t.Errorf("no filter = %q, want abc", got)
+	}
+	if got := ids((Filter{Since: day(5), Until: day(10)}).Apply(sessions)); got != "b" {
+		t.Errorf("date range = %q, want b", got)
+	}
+	if got := (Filter{Text: "LOGIN"}).Apply(sessions); ids(got) != "a" || got[0].Snippet != "" {
+		t.Errorf("title search = %+v, want a with no snippet", got)
+	}
+	got := Filter{Text: "trailing commas"}.Apply(sessions)
+	if ids(got) != "b" {
+		t.Fatalf("message search = %q, want b", ids(got))
+	}
+	if want := "...in internal/config: why does it reject trailing commas in arrays? It used to accept them befor..."; got[0].Snippet != want {
+		t.Errorf("snippet = %q, want %q", got[0].Snippet, want)
+	}
+}
+
+func TestParseFilterTime(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.Local)
+	tests := []struct {
+		in    string
+		upper bool
+		want  time.Time
+	}{
+		{"2026-10-01", false, time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local)},
+		{"2026-10-01", true, time.Date(2026, 10, 2, 0, 0, 0, 0, time.Local)},
+		{"2026-10-01T09:30", true, time.Date(2026, 10, 1, 9, 30, 0, 0, time.Local)},
+		{"7d", false, now.AddDate(0, 0, -7)},
+		{"12h", false, now.Add(-12 * time.Hour)},
+	}
+	for _, tt := range tests {
+		got, err := ParseFilterTime(tt.in, now, tt.upper)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("ParseFilterTime(%q, %v) = %v, %v; want %v", tt.in, tt.upper, got, err, tt.want)
+		}
+	}
+	if _, err := ParseFilterTime("last week", now, false); err == nil {
+		t.Error("expected error for an unparseable time")
+	}
+}
+
+func TestStoreDelete(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStoreWithDir(dir)
+	store.SetTranscriptDir(filepath.Join(dir, "transcripts"))
+	sess := &Session{ID: "gone", Messages: []api.Message{api.NewTextMessage(api.RoleUser, "hi")}}
+	if err := store.Save(sess); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if err := os.MkdirAll(store.TasksDir("gone"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Delete("gone"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	for _, path := range []string{
+		filepath.Join(dir, "gone.json"), store.messageLogPath("gone"), store.TasksDir("gone"), store.TranscriptPath("gone"),
+	} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s still exists after Delete", path)
+		}
+	}
+	if err := store.Delete("gone"); err == nil {
+		t.Error("expected error deleting a missing session")
+	}
+	if err := store.Delete("../gone"); err == nil {
+		t.Error("expected error for an ID with a path separator")
+	}
+}
+
+func TestListAllAndFindSession(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	for _, p := range []struct{ dir, id, cwd string }{
+		{"a", "one", "/src/a"},
+		{"b", "two", "/src/b"},
+	} {
+		store := NewStoreWithDir(filepath.Join(home, ".claude", "projects", p.dir, "sessions"))
+		if err := store.Save(&Session{ID: p.id, CWD: p.cwd}); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+	}
+
+	all, err := ListAll()
+	if err != nil {
+		t.Fatalf("ListAll: %v", err)
+	}
+	if len(all) != 2 || all[0].ID != "two" || all[1].ID != "one" {
+		t.Errorf("ListAll = %v, want two then one", all)
+	}
+
+	sess, err := FindSession("one")
+	if err != nil {
+		t.Fatalf("FindSession: %v", err)
+	}
+	if sess.CWD != "/src/a" {
+		t.Errorf("CWD = %q, want /src/a", sess.CWD)
+	}
+	if _, err := FindSession("missing"); err == nil {
+		t.Error("expected error for a missing session")
+	}
+}
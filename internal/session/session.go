@@ -1,12 +1,19 @@
 // Package session manages conversation session persistence.
 //
-// Sessions are stored as JSON files under ~/.claude/projects/<hash>/sessions/.
-// The directory structure and file format are designed to match the official
-// Claude Code CLI for interoperability.
+// Sessions are stored under ~/.claude/projects/<hash>/sessions/ as a small
+// JSON metadata file (<id>.json) plus an append-only message log
+// (<id>.messages.jsonl), so saving a long session only writes the new
+// messages. Older sessions that embed their messages in the JSON file
+// still load. Projects can instead keep their sessions in a SQLite
+// database in the same directory; see Store.UseSQLite.
+// Each save also appends new messages to a JSONL transcript under
+// ~/.claude/projects/<sanitized-cwd>/<id>.jsonl, in the format the official
+// Claude Code CLI uses, for interoperability.
 package session
 
 import (
 	"crypto/sha256"
+	"database/sql"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
@@ -14,9 +21,12 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/anthropics/claude-code-go/internal/api"
+	"github.com/anthropics/claude-code-go/internal/conversation"
+	"github.com/anthropics/claude-code-go/internal/tools"
 )
 
 // Session represents a saved conversation.
@@ -27,11 +37,74 @@ type Session struct {
 	Messages  []api.Message `json:"messages"`
 	CreatedAt time.Time     `json:"created_at"`
 	UpdatedAt time.Time     `json:"updated_at"`
+
+	// Title is a short description of the session, generated from its
+	// first exchange; see Store.SetTitler.
+	Title string `json:"title,omitempty"`
+
+	// Name is a name given with /rename or `claude sessions rename`,
+	// unique within the project, by which the session can be resumed.
+	Name string `json:"name,omitempty"`
+
+	// Pinned keeps the session from being pruned; see Store.Prune.
+	Pinned bool `json:"pinned,omitempty"`
+
+	// ForkedFrom is the ID of the session this one was copied from by
+	// Store.Fork.
+	ForkedFrom string `json:"forked_from,omitempty"`
+
+	// Turns holds per-turn metadata (duration, model, usage, tools).
+	Turns []conversation.TurnMetadata `json:"turns,omitempty"`
+
+	// Todos is the TodoWrite list at the last save, restored on resume.
+	Todos []tools.TodoItem `json:"todos,omitempty"`
+	// EncTodos holds Todos sealed, in place of Todos, in the metadata of
+	// a session saved with encryption on; see sealTodos.
+	EncTodos []byte `json:"enc_todos,omitempty"`
+
+	// AgentCosts records what each sub-agent run consumed, for /cost.
+	AgentCosts []tools.AgentCost `json:"agent_costs,omitempty"`
+
+	// Usage is the session's cumulative usage as of the last save. Use
+	// TotalUsage, which covers sessions saved without it.
+	Usage *Usage `json:"usage,omitempty"`
+
+	// MessageLog is set in the metadata file when messages live in the
+	// session's message log instead of the Messages field.
+	MessageLog bool `json:"message_log,omitempty"`
+	// MessageCount is the number of messages at the last save.
+	MessageCount int `json:"message_count,omitempty"`
+}
+
+// CostUSD estimates what the session has cost so far: its turns, priced
+// by the model each used, plus its sub-agent runs.
+func (s *Session) CostUSD() float64 {
+	return s.rollupUsage().CostUSD
 }
 
 // Store manages reading and writing sessions to disk.
 type Store struct {
 	dir string // e.g. ~/.claude/projects/<hash>/sessions/
+
+	transcriptDir string // e.g. ~/.claude/projects/-home-user-project/; "" disables transcripts
+	version       string // CLI version recorded in transcript entries
+
+	mu          sync.Mutex
+	logs        map[string]*logState
+	transcripts map[string]*transcriptState
+	ledgers     map[string]map[string]bool // paths in each session's change ledger
+	locks       map[string]*os.File        // lock files of sessions held by Lock
+	readOnly    map[string]bool            // sessions whose writes are skipped
+	runContext  *ContextSnapshot           // pinned to sessions without a snapshot; see SetContext
+	pinned      map[string]bool            // sessions checked for a snapshot
+
+	titler  TitleFunc
+	titling map[string]bool   // sessions whose title was requested
+	titles  map[string]string // generated titles by session ID
+	titleWG sync.WaitGroup    // running title requests
+	metaMu  sync.Mutex        // serializes writes of metadata files
+
+	db *sql.DB // set when the project's sessions are in SQLite
 }
 
 // NewStore creates a session store for the given working directory.
@@ -47,10 +120,18 @@ func NewStore(cwd string) (*Store, error) {
 	projectHash := hex.EncodeToString(h[:16]) // 32 hex chars
 
 	dir := filepath.Join(home, ".claude", "projects", projectHash, "sessions")
-	return &Store{dir: dir}, nil
+	s := &Store{
+		dir:           dir,
+		transcriptDir: filepath.Join(home, ".claude", "projects", ProjectDirName(cwd)),
+	}
+	if err := s.openExistingSQLite(); err != nil {
+		return nil, err
+	}
+	return s, nil
 }
 
 // NewStoreWithDir creates a session store at a specific directory (for testing).
+// Transcripts are disabled unless enabled with SetTranscriptDir.
 func NewStoreWithDir(dir string) *Store {
 	return &Store{dir: dir}
 }
@@ -60,29 +141,143 @@ func (s *Store) Dir() string {
 	return s.dir
 }
 
+// TasksDir returns the directory where the session's background tasks are
+// saved.
+func (s *Store) TasksDir(id string) string {
+	return filepath.Join(s.dir, id+".tasks")
+}
+
 // Save persists a session to disk. It creates the directory if needed.
+// New messages are appended to the message log; the metadata file is
+// rewritten without them, so the cost of a save does not grow with the
+// length of the session. Saving after each message, rather than each
+// turn, is cheap for the same reason.
 func (s *Store) Save(session *Session) error {
+	if s.IsReadOnly(session.ID) {
+		return nil
+	}
 	if err := os.MkdirAll(s.dir, 0700); err != nil {
 		return fmt.Errorf("creating session directory: %w", err)
 	}
 
 	session.UpdatedAt = time.Now()
+	usage := session.rollupUsage()
+	session.Usage = &usage
 
-	data, err := json.MarshalIndent(session, "", "  ")
+	if s.db != nil {
+		if err := s.saveSQL(session); err != nil {
+			return err
+		}
+	} else {
+		if err := s.appendMessageLog(session); err != nil {
+			return err
+		}
+		if err := s.writeMeta(session); err != nil {
+			return err
+		}
+	}
+	s.pinContext(session.ID)
+
+	return s.appendTranscript(session)
+}
+
+// writeMeta rewrites the session's metadata file, without its messages.
+func (s *Store) writeMeta(session *Session) error {
+	// Holding metaMu while picking up a generated title means a title
+	// written in the background is never overwritten by a stale save.
+	s.metaMu.Lock()
+	defer s.metaMu.Unlock()
+	s.applyTitle(session)
+
+	meta := *session
+	meta.Messages = nil
+	meta.Turns = nil
+	meta.MessageLog = true
+	meta.MessageCount = len(session.Messages)
+	if err := meta.sealTodos(); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(&meta, "", "  ")
 	if err != nil {
 		return fmt.Errorf("marshaling session: %w", err)
 	}
 
 	path := filepath.Join(s.dir, session.ID+".json")
-	if err := os.WriteFile(path, data, 0600); err != nil {
+	if err := writeFileAtomic(path, data); err != nil {
 		return fmt.Errorf("writing session file: %w", err)
 	}
+	return nil
+}
+
+// updateMeta changes the metadata of a saved session in place, leaving
+// its messages and UpdatedAt as they were. A session open in another
+// process is refused with a *LockedError, since its next save would undo
+// the change; one this store holds is updated.
+func (s *Store) updateMeta(id string, change func(*Session)) error {
+	s.mu.Lock()
+	_, held := s.locks[id]
+	s.mu.Unlock()
+	if !held {
+		if err := s.Lock(id); err != nil {
+			return err
+		}
+		defer s.Unlock(id)
+	}
 
+	s.metaMu.Lock()
+	defer s.metaMu.Unlock()
+	if s.db != nil {
+		return s.updateMetaSQL(id, change)
+	}
+	path := filepath.Join(s.dir, id+".json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading session file: %w", err)
+	}
+	var meta Session
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return fmt.Errorf("parsing session file: %w", err)
+	}
+	change(&meta)
+	if data, err = json.MarshalIndent(&meta, "", "  "); err != nil {
+		return fmt.Errorf("marshaling session: %w", err)
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		return fmt.Errorf("writing session file: %w", err)
+	}
 	return nil
 }
 
+// writeFileAtomic writes data to a temporary file next to path and renames
+// it into place, so a crash mid-write never leaves a truncated metadata
+// file that would make the session unloadable.
+func writeFileAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp) // no-op after a successful rename
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
 // Load reads a session by ID from disk.
 func (s *Store) Load(id string) (*Session, error) {
+	if s.db != nil {
+		return s.loadSQL(id)
+	}
 	path := filepath.Join(s.dir, id+".json")
 	return s.loadFile(path)
 }
@@ -101,8 +296,89 @@ func (s *Store) MostRecent() (*Session, error) {
 	return sessions[0], nil
 }
 
+// Exists reports whether the store has a session with the given ID.
+func (s *Store) Exists(id string) bool {
+	if s.db != nil {
+		return s.existsSQL(id)
+	}
+	_, err := os.Stat(filepath.Join(s.dir, id+".json"))
+	return err == nil
+}
+
+// Fork copies a saved session to a new ID, with its todo list, saved
+// background tasks, and change ledger, and saves the copy. Work continued in the copy leaves
+// the original session and its transcript as they were.
+func (s *Store) Fork(id string) (*Session, error) {
+	orig, err := s.Load(id)
+	if err != nil {
+		return nil, err
+	}
+	fork := *orig
+	fork.ID = GenerateID()
+	fork.ForkedFrom = orig.ID
+	fork.Name = "" // names are unique
+	fork.Pinned = false
+	fork.CreatedAt = time.Now()
+	fork.Messages = append([]api.Message(nil), orig.Messages...)
+	fork.Turns = append([]conversation.TurnMetadata(nil), orig.Turns...)
+	fork.MessageLog = false
+	fork.MessageCount = 0
+	if err := s.Save(&fork); err != nil {
+		return nil, err
+	}
+
+	tasks, err := os.ReadDir(s.TasksDir(orig.ID))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("reading task directory: %w", err)
+	}
+	for _, entry := range tasks {
+		if entry.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.TasksDir(orig.ID), entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("reading task: %w", err)
+		}
+		if err := os.MkdirAll(s.TasksDir(fork.ID), 0700); err != nil {
+			return nil, fmt.Errorf("creating task directory: %w", err)
+		}
+		if err := os.WriteFile(filepath.Join(s.TasksDir(fork.ID), entry.Name()), data, 0600); err != nil {
+			return nil, fmt.Errorf("writing task: %w", err)
+		}
+	}
+
+	ledger, err := os.ReadFile(s.changeLedgerPath(orig.ID))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("reading change ledger: %w", err)
+	}
+	if len(ledger) > 0 {
+		if err := os.WriteFile(s.changeLedgerPath(fork.ID), ledger, 0600); err != nil {
+			return nil, fmt.Errorf("writing change ledger: %w", err)
+		}
+	}
+
+	snap, err := os.ReadFile(s.contextPath(orig.ID))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("reading context snapshot: %w", err)
+	}
+	if len(snap) > 0 {
+		if err := writeFileAtomic(s.contextPath(fork.ID), snap); err != nil {
+			return nil, fmt.Errorf("writing context snapshot: %w", err)
+		}
+	}
+	return &fork, nil
+}
+
 // List returns all sessions sorted by UpdatedAt (newest first).
 func (s *Store) List() ([]*Session, error) {
+	if s.db != nil {
+		return s.querySQL("")
+	}
+	return s.listFiles()
+}
+
+// listFiles reads every session saved as JSON, newest first.
+func (s *Store) listFiles() ([]*Session, error) {
 	entries, err := os.ReadDir(s.dir)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -142,6 +418,18 @@ func (s *Store) loadFile(path string) (*Session, error) {
 	if err := json.Unmarshal(data, &sess); err != nil {
 		return nil, fmt.Errorf("parsing session file: %w", err)
 	}
+	if err := sess.openTodos(); err != nil {
+		return nil, err
+	}
+
+	if sess.MessageLog {
+		msgs, turns, pending, err := readMessageLog(s.messageLogPath(sess.ID))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		sess.Messages = completeInterruptedTurn(msgs, pending)
+		sess.Turns = turns
+	}
 
 	return &sess, nil
 }
@@ -1,13 +1,18 @@
 package session
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/anthropics/claude-code-go/internal/api"
+	"github.com/anthropics/claude-code-go/internal/conversation"
+	"github.com/anthropics/claude-code-go/internal/tools"
 )
 
 func TestStoreRoundTrip(t *testing.T) {
@@ -56,6 +61,26 @@ func TestStoreRoundTrip(t *testing.T) {
 	}
 }
 
+func TestStoreTodosRoundTrip(t *testing.T) {
+	store := NewStoreWithDir(t.TempDir())
+	todos := []tools.TodoItem{
+		{Content: "Write tests", Status: "completed", ActiveForm: "Writing tests"},
+		{Content: "Fix bug", Status: "in_progress", ActiveForm: "Fixing bug"},
+	}
+	sess := &Session{ID: "todos", Messages: []api.Message{api.NewTextMessage("user", "hi")}, Todos: todos}
+	if err := store.Save(sess); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded, err := store.Load("todos")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(loaded.Todos) != 2 || loaded.Todos[1] != todos[1] {
+		t.Errorf("Todos = %+v, want %+v", loaded.Todos, todos)
+	}
+}
+
 func TestStoreMostRecent(t *testing.T) {
 	dir := t.TempDir()
 	store := NewStoreWithDir(dir)
@@ -203,3 +228,481 @@ func TestGenerateID(t *testing.T) {
 		t.Logf("Warning: IDs are identical (timing collision), acceptable in rare cases")
 	}
 }
+
+func TestTranscriptAppend(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStoreWithDir(filepath.Join(dir, "sessions"))
+	store.SetTranscriptDir(filepath.Join(dir, "transcripts"))
+	store.SetVersion("1.2.3")
+
+	sess := &Session{
+		ID:       "tx-1",
+		CWD:      "/tmp/project",
+		Messages: []api.Message{api.NewTextMessage("user", "hello")},
+	}
+	if err := store.Save(sess); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	sess.Messages = append(sess.Messages, api.NewTextMessage("assistant", "hi"))
+	if err := store.Save(sess); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	entries, err := ReadTranscript(store.TranscriptPath(sess.ID))
+	if err != nil {
+		t.Fatalf("ReadTranscript: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("entries = %d, want 2", len(entries))
+	}
+	if entries[0].Type != EntryTypeUser || entries[1].Type != EntryTypeAssistant {
+		t.Errorf("types = %q, %q", entries[0].Type, entries[1].Type)
+	}
+	if entries[0].ParentUUID != nil {
+		t.Errorf("first entry parentUuid = %q, want null", *entries[0].ParentUUID)
+	}
+	if entries[1].ParentUUID == nil || *entries[1].ParentUUID != entries[0].UUID {
+		t.Errorf("second entry parentUuid should link to first entry")
+	}
+	if entries[1].SessionID != "tx-1" || entries[1].Version != "1.2.3" || entries[1].CWD != "/tmp/project" {
+		t.Errorf("unexpected entry metadata: %+v", entries[1])
+	}
+
+	// A fresh store (e.g. after resume) must continue appending, not rewrite.
+	store2 := NewStoreWithDir(filepath.Join(dir, "sessions"))
+	store2.SetTranscriptDir(filepath.Join(dir, "transcripts"))
+	sess.Messages = append(sess.Messages, api.NewTextMessage("user", "again"))
+	if err := store2.Save(sess); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	entries, _ = ReadTranscript(store.TranscriptPath(sess.ID))
+	if len(entries) != 3 {
+		t.Fatalf("entries after resume = %d, want 3", len(entries))
+	}
+	if entries[2].ParentUUID == nil || *entries[2].ParentUUID != entries[1].UUID {
+		t.Errorf("resumed entry should link to previous entry")
+	}
+}
+
+func TestTranscriptCompaction(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStoreWithDir(filepath.Join(dir, "sessions"))
+	store.SetTranscriptDir(filepath.Join(dir, "transcripts"))
+
+	sess := &Session{ID: "tx-2", Messages: []api.Message{
+		api.NewTextMessage("user", "one"),
+		api.NewTextMessage("assistant", "two"),
+		api.NewTextMessage("user", "three"),
+	}}
+	if err := store.Save(sess); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	sess.Messages = []api.Message{api.NewTextMessage("user", "[Conversation Summary]\nstuff")}
+	if err := store.Save(sess); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	entries, err := ReadTranscript(store.TranscriptPath(sess.ID))
+	if err != nil {
+		t.Fatalf("ReadTranscript: %v", err)
+	}
+	if len(entries) != 5 {
+		t.Fatalf("entries = %d, want 5", len(entries))
+	}
+	summary := entries[3]
+	if summary.Type != EntryTypeSummary {
+		t.Fatalf("entries[3].Type = %q, want summary", summary.Type)
+	}
+	if summary.LeafUUID != entries[2].UUID {
+		t.Errorf("summary leafUuid should reference last message before compaction")
+	}
+	if entries[4].ParentUUID != nil {
+		t.Errorf("compacted history should start a new chain")
+	}
+}
+
+func TestProjectDirName(t *testing.T) {
+	if got := ProjectDirName("/home/user/my.project"); got != "-home-user-my-project" {
+		t.Errorf("ProjectDirName = %q", got)
+	}
+	long := "/" + strings.Repeat("a", 300)
+	if got := ProjectDirName(long); len(got) <= 200 || got[:200] != ProjectDirName(long)[:200] {
+		t.Errorf("long path not truncated with hash suffix: %q", got)
+	}
+}
+
+func TestStoreMessageLogAppendOnly(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStoreWithDir(dir)
+
+	sess := &Session{ID: "log-1", Messages: []api.Message{api.NewTextMessage("user", "one")}}
+	if err := store.Save(sess); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	sess.Messages = append(sess.Messages, api.NewTextMessage("assistant", "two"))
+	if err := store.Save(sess); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	// The metadata file must not embed the messages.
+	data, err := os.ReadFile(filepath.Join(dir, "log-1.json"))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if strings.Contains(string(data), "two") {
+		t.Error("metadata file should not contain message content")
+	}
+
+	logData, err := os.ReadFile(filepath.Join(dir, "log-1.messages.jsonl"))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if n := strings.Count(string(logData), "\n"); n != 2 {
+		t.Errorf("message log lines = %d, want 2 (each message written once)", n)
+	}
+
+	loaded, err := store.Load("log-1")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(loaded.Messages) != 2 || loaded.MessageCount != 2 {
+		t.Errorf("loaded %d messages (count %d), want 2", len(loaded.Messages), loaded.MessageCount)
+	}
+}
+
+func TestStoreMessageLogCompaction(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStoreWithDir(dir)
+
+	sess := &Session{ID: "log-2", Messages: []api.Message{
+		api.NewTextMessage("user", "one"),
+		api.NewTextMessage("assistant", "two"),
+		api.NewTextMessage("user", "three"),
+	}}
+	if err := store.Save(sess); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	sess.Messages = []api.Message{
+		api.NewTextMessage("user", "[Conversation Summary]\nsummary"),
+		api.NewTextMessage("assistant", "ok"),
+	}
+	if err := store.Save(sess); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	// A fresh store must see only the compacted window.
+	loaded, err := NewStoreWithDir(dir).Load("log-2")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(loaded.Messages) != 2 {
+		t.Fatalf("loaded %d messages, want 2", len(loaded.Messages))
+	}
+	var text string
+	json.Unmarshal(loaded.Messages[1].Content, &text)
+	if text != "ok" {
+		t.Errorf("last message = %q, want %q", text, "ok")
+	}
+}
+
+func TestStoreMessageLogCompression(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStoreWithDir(dir)
+
+	output := strings.Repeat("ok  \tgithub.com/example/pkg\t0.012s\n", 2000)
+	sess := &Session{ID: "big", Messages: []api.Message{
+		api.NewTextMessage("user", "run the tests"),
+		api.NewTextMessage("assistant", output),
+	}}
+	if err := store.Save(sess); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "big.messages.jsonl"))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if !strings.Contains(string(data), "run the tests") {
+		t.Error("small message should be stored as is")
+	}
+	if strings.Contains(string(data), "example/pkg") || len(data) > len(output)/4 {
+		t.Errorf("large message should be compressed; log is %d bytes", len(data))
+	}
+
+	loaded, err := NewStoreWithDir(dir).Load("big")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(loaded.Messages) != 2 || MessageText(loaded.Messages[1]) != strings.TrimSpace(output) {
+		t.Error("compressed message did not round-trip")
+	}
+}
+
+func TestStoreLoadLegacySession(t *testing.T) {
+	dir := t.TempDir()
+	legacy := Session{ID: "old", Messages: []api.Message{api.NewTextMessage("user", "hi")}}
+	data, _ := json.Marshal(legacy)
+	if err := os.WriteFile(filepath.Join(dir, "old.json"), data, 0600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	loaded, err := NewStoreWithDir(dir).Load("old")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(loaded.Messages) != 1 {
+		t.Errorf("loaded %d messages, want 1", len(loaded.Messages))
+	}
+}
+
+func TestStoreTurnsRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStoreWithDir(dir)
+
+	sess := &Session{
+		ID:       "turns-1",
+		Messages: []api.Message{api.NewTextMessage("user", "hi"), api.NewTextMessage("assistant", "hello")},
+		Turns: []conversation.TurnMetadata{
+			{Model: "claude-sonnet-4-6", StopReason: "end_turn", DurationMs: 1200, Usage: api.Usage{InputTokens: 10, OutputTokens: 5}},
+		},
+	}
+	if err := store.Save(sess); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	// Compaction resets messages but must keep turn history.
+	sess.Messages = []api.Message{api.NewTextMessage("user", "summary")}
+	sess.Turns = append(sess.Turns, conversation.TurnMetadata{Model: "claude-sonnet-4-6", Tools: []string{"Bash"}})
+	if err := store.Save(sess); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded, err := NewStoreWithDir(dir).Load("turns-1")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(loaded.Turns) != 2 {
+		t.Fatalf("turns = %d, want 2", len(loaded.Turns))
+	}
+	if loaded.Turns[0].DurationMs != 1200 || loaded.Turns[0].Usage.OutputTokens != 5 {
+		t.Errorf("turn 0 = %+v", loaded.Turns[0])
+	}
+	if len(loaded.Turns[1].Tools) != 1 || loaded.Turns[1].Tools[0] != "Bash" {
+		t.Errorf("turn 1 tools = %v", loaded.Turns[1].Tools)
+	}
+}
+
+func TestStoreUsageRollup(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStoreWithDir(dir)
+	cacheRead := 1_000_000
+	sess := &Session{
+		ID: "usage-1",
+		Turns: []conversation.TurnMetadata{
+			{Model: "claude-sonnet-4-6", Usage: api.Usage{InputTokens: 1_000_000, OutputTokens: 100_000, CacheReadInputTokens: &cacheRead}},
+			{Model: "claude-sonnet-4-6", Usage: api.Usage{InputTokens: 500_000}},
+		},
+		AgentCosts: []tools.AgentCost{
+			{Model: "claude-haiku-4-5", Usage: api.Usage{InputTokens: 400_000}, CostUSD: 0.32},
+		},
+	}
+	if err := store.Save(sess); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	// The rollup is in the metadata file, for readers that skip the log.
+	data, _ := os.ReadFile(filepath.Join(dir, "usage-1.json"))
+	var meta Session
+	if err := json.Unmarshal(data, &meta); err != nil || meta.Usage == nil {
+		t.Fatalf("metadata usage = %v, %v; want it saved", meta.Usage, err)
+	}
+	u := meta.TotalUsage()
+	if u.InputTokens != 1_900_000 || u.OutputTokens != 100_000 || u.CacheReadTokens != 1_000_000 {
+		t.Errorf("tokens = %+v", u)
+	}
+	// 3.00 + 1.50 + 0.30 + 1.50 for the turns, 0.32 for the agent.
+	if want := 6.62; u.CostUSD < want-0.001 || u.CostUSD > want+0.001 {
+		t.Errorf("cost = %.4f, want %.4f", u.CostUSD, want)
+	}
+	if got, want := u.String(), "$6.6200, 3.0M tokens, claude-sonnet-4-6 87%, claude-haiku-4-5 13%"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	// Sessions saved before rollups add up their turns.
+	sess.Usage = nil
+	if got := sess.TotalUsage(); got.TotalTokens() != u.TotalTokens() || got.CostUSD != u.CostUSD {
+		t.Errorf("rollup from turns = %+v, want %+v", got, u)
+	}
+	if got := (&Session{}).TotalUsage().String(); got != "" {
+		t.Errorf("empty session usage = %q, want none", got)
+	}
+
+	summary := meta.CostSummary()
+	for _, want := range []string{"Input tokens:  1900000", "Total cost:    $6.6200", "Models:        claude-sonnet-4-6 87%", "Agent runs:    1"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("CostSummary() missing %q:\n%s", want, summary)
+		}
+	}
+	if got := (&Session{}).CostSummary(); got != "" {
+		t.Errorf("empty session cost summary = %q, want none", got)
+	}
+}
+
+func TestStoreFork(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStoreWithDir(dir)
+	store.SetTranscriptDir(filepath.Join(dir, "transcripts"))
+	orig := &Session{ID: "orig", Title: "Original", Messages: []api.Message{
+		api.NewTextMessage("user", "one"),
+		api.NewTextMessage("assistant", "two"),
+	}}
+	if err := store.Save(orig); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if err := os.MkdirAll(store.TasksDir("orig"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(store.TasksDir("orig"), "agent-1.json"), []byte(`{}`), 0600)
+	origTranscript, _ := os.ReadFile(store.TranscriptPath("orig"))
+
+	fork, err := store.Fork("orig")
+	if err != nil {
+		t.Fatalf("Fork: %v", err)
+	}
+	if fork.ID == "orig" || fork.ForkedFrom != "orig" || fork.Title != "Original" {
+		t.Errorf("fork = %+v", fork)
+	}
+	if _, err := os.Stat(filepath.Join(store.TasksDir(fork.ID), "agent-1.json")); err != nil {
+		t.Errorf("saved task not copied: %v", err)
+	}
+
+	// Continuing the fork leaves the original alone.
+	fork.Messages = append(fork.Messages, api.NewTextMessage("user", "three"))
+	if err := store.Save(fork); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	loaded, err := store.Load("orig")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(loaded.Messages) != 2 {
+		t.Errorf("original has %d messages, want 2", len(loaded.Messages))
+	}
+	if data, _ := os.ReadFile(store.TranscriptPath("orig")); string(data) != string(origTranscript) {
+		t.Error("original transcript changed")
+	}
+	if loaded, _ := store.Load(fork.ID); len(loaded.Messages) != 3 {
+		t.Errorf("fork has %d messages, want 3", len(loaded.Messages))
+	}
+}
+
+// toolUseMessage is an assistant message calling the given tools.
+func toolUseMessage(ids ...string) api.Message {
+	var blocks []api.ContentBlock
+	for _, id := range ids {
+		blocks = append(blocks, api.ContentBlock{Type: api.ContentTypeToolUse, ID: id, Name: "Bash", Input: json.RawMessage(`{}`)})
+	}
+	return api.NewBlockMessage("assistant", blocks)
+}
+
+func TestStoreInterruptedTurn(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStoreWithDir(dir)
+	sess := &Session{ID: "crash", Messages: []api.Message{
+		api.NewTextMessage("user", "build it"),
+		toolUseMessage("toolu_1", "toolu_2"),
+	}}
+	if err := store.Save(sess); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	// toolu_1 finished; the process died while toolu_2 ran.
+	if err := store.AppendToolResult("crash", api.ContentBlock{Type: api.ContentTypeToolResult, ToolUseID: "toolu_1", Content: json.RawMessage(`"built"`)}); err != nil {
+		t.Fatalf("AppendToolResult: %v", err)
+	}
+
+	other := NewStoreWithDir(dir)
+	loaded, err := other.Load("crash")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(loaded.Messages) != 3 {
+		t.Fatalf("loaded %d messages, want 3 (with the results)", len(loaded.Messages))
+	}
+	var results []api.ContentBlock
+	json.Unmarshal(loaded.Messages[2].Content, &results)
+	if len(results) != 2 || results[0].ToolUseID != "toolu_1" || results[0].IsError ||
+		results[1].ToolUseID != "toolu_2" || !results[1].IsError {
+		t.Errorf("results = %+v, want toolu_1's result and toolu_2 interrupted", results)
+	}
+
+	// Continuing the resumed session writes the completed turn.
+	loaded.Messages = append(loaded.Messages, api.NewTextMessage("assistant", "ok"))
+	if err := other.Save(loaded); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	again, err := NewStoreWithDir(dir).Load("crash")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(again.Messages) != 4 || again.Messages[2].Role != "user" {
+		t.Errorf("after resuming, %d messages, want 4 with the results third", len(again.Messages))
+	}
+}
+
+func TestStoreUnreadableMessageLog(t *testing.T) {
+	defer func(n int) { maxLogLine = n }(maxLogLine)
+	maxLogLine = 64 << 10
+
+	dir := t.TempDir()
+	sess := &Session{ID: "long", Messages: []api.Message{api.NewTextMessage("user", "one")}}
+	if err := NewStoreWithDir(dir).Save(sess); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	path := filepath.Join(dir, "long.messages.jsonl")
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	fmt.Fprintf(f, "{\"type\":\"message\",\"message\":{\"role\":\"assistant\",\"content\":%q}}\n", strings.Repeat("x", 128<<10))
+	f.Close()
+	before, _ := os.ReadFile(path)
+
+	// A store that can't read the log to the end must not append the
+	// whole history to it again.
+	sess.Messages = append(sess.Messages, api.NewTextMessage("assistant", "two"))
+	if err := NewStoreWithDir(dir).Save(sess); err == nil {
+		t.Error("expected Save to fail on an unreadable message log")
+	}
+	if err := NewStoreWithDir(dir).AppendToolResult("long", api.ContentBlock{Type: api.ContentTypeToolResult, ToolUseID: "t1"}); err == nil {
+		t.Error("expected AppendToolResult to fail on an unreadable message log")
+	}
+	if after, _ := os.ReadFile(path); !bytes.Equal(after, before) {
+		t.Errorf("message log changed:\n%s", after[len(before):])
+	}
+}
+
+func TestStoreTornMessageLog(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStoreWithDir(dir)
+	sess := &Session{ID: "torn", Messages: []api.Message{api.NewTextMessage("user", "one")}}
+	if err := store.Save(sess); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	// A crash mid-append leaves a partial line.
+	f, _ := os.OpenFile(filepath.Join(dir, "torn.messages.jsonl"), os.O_APPEND|os.O_WRONLY, 0600)
+	f.WriteString(`{"type":"message","message":{"role":"assi`)
+	f.Close()
+
+	other := NewStoreWithDir(dir)
+	loaded, err := other.Load("torn")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	loaded.Messages = append(loaded.Messages, api.NewTextMessage("assistant", "two"))
+	if err := other.Save(loaded); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	again, err := NewStoreWithDir(dir).Load("torn")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(again.Messages) != 2 {
+		t.Errorf("loaded %d messages, want 2 (the partial line skipped)", len(again.Messages))
+	}
+}
@@ -0,0 +1,151 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/anthropics/claude-code-go/internal/config"
+	"github.com/anthropics/claude-code-go/internal/skills"
+)
+
+// ContextSnapshot is the instructions a session was started with: the
+// CLAUDE.md files and skills in its context. It is pinned to the session
+// so a resume can run with the same ones, however the files have changed
+// since.
+type ContextSnapshot struct {
+	TakenAt  time.Time              `json:"taken_at"`
+	ClaudeMD []config.ClaudeMDEntry `json:"claude_md,omitempty"`
+	Skills   []skills.Skill         `json:"skills,omitempty"`
+}
+
+// contextPath returns the path of a session's context snapshot, a file
+// next to the metadata with either backend. It is JSON, but not named
+// .json, which would list it as a session.
+func (s *Store) contextPath(id string) string {
+	return filepath.Join(s.dir, id+".context")
+}
+
+// SetContext sets the context this process runs with. Saving a session
+// that has no snapshot yet pins this one to it, so new sessions, and
+// older ones resumed, record what they ran with.
+func (s *Store) SetContext(snap *ContextSnapshot) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.runContext = snap
+	s.pinned = nil
+}
+
+// pinContext writes the process's context as the session's snapshot if
+// it has none. Errors are ignored: the snapshot only matters on resume.
+func (s *Store) pinContext(id string) {
+	s.mu.Lock()
+	snap := s.runContext
+	done := s.pinned[id]
+	if snap != nil && !done {
+		if s.pinned == nil {
+			s.pinned = make(map[string]bool)
+		}
+		s.pinned[id] = true
+	}
+	s.mu.Unlock()
+	if snap == nil || done {
+		return
+	}
+	if _, err := os.Stat(s.contextPath(id)); err == nil {
+		return
+	}
+	s.PinContext(id, snap)
+}
+
+// PinContext replaces the session's context snapshot, as when the user
+// chooses to refresh a resumed session to the current files.
+func (s *Store) PinContext(id string, snap *ContextSnapshot) error {
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return fmt.Errorf("marshaling context snapshot: %w", err)
+	}
+	if data, err = Seal(data); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return fmt.Errorf("creating session directory: %w", err)
+	}
+	if err := writeFileAtomic(s.contextPath(id), data); err != nil {
+		return fmt.Errorf("writing context snapshot: %w", err)
+	}
+	return nil
+}
+
+// PinnedContext returns the session's context snapshot, or nil for a
+// session saved without one.
+func (s *Store) PinnedContext(id string) (*ContextSnapshot, error) {
+	data, err := os.ReadFile(s.contextPath(id))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading context snapshot: %w", err)
+	}
+	if data, err = Open(data); err != nil {
+		return nil, err
+	}
+	var snap ContextSnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("parsing context snapshot: %w", err)
+	}
+	return &snap, nil
+}
+
+// ContextChanges describes how current differs from the pinned snapshot,
+// one line per CLAUDE.md file or skill added, removed, or changed. It is
+// empty when they match.
+func ContextChanges(pinned, current *ContextSnapshot) []string {
+	var changes []string
+	was := make(map[string]string)
+	for _, e := range pinned.ClaudeMD {
+		was[e.Path] = e.Content
+	}
+	for _, e := range current.ClaudeMD {
+		content, ok := was[e.Path]
+		delete(was, e.Path)
+		switch {
+		case !ok:
+			changes = append(changes, "added "+e.Path)
+		case content != e.Content:
+			changes = append(changes, "changed "+e.Path)
+		}
+	}
+	for _, e := range pinned.ClaudeMD {
+		if _, ok := was[e.Path]; ok {
+			changes = append(changes, "removed "+e.Path)
+		}
+	}
+
+	wasSkill := make(map[string]skills.Skill)
+	for _, sk := range pinned.Skills {
+		wasSkill[sk.Name] = sk
+	}
+	for _, sk := range current.Skills {
+		old, ok := wasSkill[sk.Name]
+		delete(wasSkill, sk.Name)
+		if !ok {
+			changes = append(changes, "added skill "+sk.Name)
+			continue
+		}
+		// A skill moved, e.g. with the project to another machine, is
+		// the same skill.
+		old.FilePath = sk.FilePath
+		if old != sk {
+			changes = append(changes, "changed skill "+sk.Name)
+		}
+	}
+	for _, sk := range pinned.Skills {
+		if _, ok := wasSkill[sk.Name]; ok {
+			changes = append(changes, "removed skill "+sk.Name)
+		}
+	}
+	return changes
+}
@@ -0,0 +1,107 @@
+package session
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/anthropics/claude-code-go/internal/api"
+	"github.com/anthropics/claude-code-go/internal/config"
+	"github.com/anthropics/claude-code-go/internal/skills"
+)
+
+func testSnapshot(claudeMD string) *ContextSnapshot {
+	return &ContextSnapshot{
+		ClaudeMD: []config.ClaudeMDEntry{{Path: "/p/CLAUDE.md", Type: "Project", Content: claudeMD}},
+		Skills:   []skills.Skill{{Name: "commit", Trigger: "/commit", Content: "Write a commit message.", FilePath: "/p/.claude/skills/commit.md"}},
+	}
+}
+
+func TestStorePinsContext(t *testing.T) {
+	store := NewStoreWithDir(t.TempDir())
+	store.SetContext(testSnapshot("Use tabs."))
+	sess := &Session{ID: "s1", CWD: "/p", Messages: []api.Message{api.NewTextMessage("user", "hi")}}
+	if err := store.Save(sess); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	// A later process with other files doesn't replace the snapshot.
+	later := NewStoreWithDir(store.Dir())
+	later.SetContext(testSnapshot("Use spaces."))
+	if err := later.Save(sess); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	pinned, err := later.PinnedContext("s1")
+	if err != nil || pinned == nil || pinned.ClaudeMD[0].Content != "Use tabs." {
+		t.Fatalf("PinnedContext = %+v, %v; want the first snapshot", pinned, err)
+	}
+	if !reflect.DeepEqual(pinned.Skills, testSnapshot("").Skills) {
+		t.Errorf("pinned skills = %+v", pinned.Skills)
+	}
+
+	// Refreshing replaces it.
+	if err := later.PinContext("s1", testSnapshot("Use spaces.")); err != nil {
+		t.Fatalf("PinContext: %v", err)
+	}
+	if pinned, _ = later.PinnedContext("s1"); pinned.ClaudeMD[0].Content != "Use spaces." {
+		t.Errorf("after PinContext, content = %q", pinned.ClaudeMD[0].Content)
+	}
+
+	if none, err := store.PinnedContext("missing"); none != nil || err != nil {
+		t.Errorf("PinnedContext of a session without one = %+v, %v", none, err)
+	}
+
+	fork, err := later.Fork("s1")
+	if err != nil {
+		t.Fatalf("Fork: %v", err)
+	}
+	if snap, _ := later.PinnedContext(fork.ID); snap == nil || snap.ClaudeMD[0].Content != "Use spaces." {
+		t.Errorf("fork snapshot = %+v, want the original's", snap)
+	}
+
+	var buf bytes.Buffer
+	if err := later.ExportBundle("s1", &buf); err != nil {
+		t.Fatalf("ExportBundle: %v", err)
+	}
+	other := NewStoreWithDir(t.TempDir())
+	if _, err := other.ImportBundle(&buf, "/q"); err != nil {
+		t.Fatalf("ImportBundle: %v", err)
+	}
+	if snap, _ := other.PinnedContext("s1"); snap == nil || snap.ClaudeMD[0].Path != "/q/CLAUDE.md" {
+		t.Errorf("imported snapshot = %+v, want it moved to /q", snap)
+	}
+
+	if err := later.Delete("s1"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := os.Stat(later.contextPath("s1")); !os.IsNotExist(err) {
+		t.Errorf("snapshot left after Delete: %v", err)
+	}
+}
+
+func TestContextChanges(t *testing.T) {
+	pinned := testSnapshot("Use tabs.")
+	if changes := ContextChanges(pinned, testSnapshot("Use tabs.")); len(changes) != 0 {
+		t.Errorf("same context: changes = %v", changes)
+	}
+
+	moved := testSnapshot("Use tabs.")
+	moved.Skills[0].FilePath = "/elsewhere/commit.md"
+	if changes := ContextChanges(pinned, moved); len(changes) != 0 {
+		t.Errorf("moved skill: changes = %v, want none", changes)
+	}
+
+	current := testSnapshot("Use spaces.")
+	current.ClaudeMD = append(current.ClaudeMD, config.ClaudeMDEntry{Path: "/home/u/.claude/CLAUDE.md", Type: "User", Content: "Be brief."})
+	current.Skills = []skills.Skill{{Name: "review", Content: "Review the diff."}}
+	want := []string{
+		"changed /p/CLAUDE.md",
+		"added /home/u/.claude/CLAUDE.md",
+		"added skill review",
+		"removed skill commit",
+	}
+	if got := ContextChanges(pinned, current); !reflect.DeepEqual(got, want) {
+		t.Errorf("ContextChanges = %q, want %q", got, want)
+	}
+}
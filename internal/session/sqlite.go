@@ -0,0 +1,565 @@
+package session
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	_ "modernc.org/sqlite" // registers the "sqlite" driver
+
+	"github.com/anthropics/claude-code-go/internal/api"
+	"github.com/anthropics/claude-code-go/internal/conversation"
+)
+
+// sqliteFile is the database, in the session directory, that holds a
+// project's sessions once the SQLite backend is enabled.
+const sqliteFile = "sessions.db"
+
+// sqliteSchema creates the tables. Session metadata is stored as JSON
+// with the columns listings filter and sort on beside it; messages_fts
+// indexes the text of every message for search. tool_results journals
+// results until the message carrying them is saved, like the message
+// log's tool result records. The trigram tokenizer
+// makes MATCH a case-insensitive substring search, as on the JSON files.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS sessions (
+	id         TEXT PRIMARY KEY,
+	meta       TEXT NOT NULL,
+	title      TEXT NOT NULL DEFAULT '',
+	updated_at INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS sessions_updated_at ON sessions (updated_at);
+CREATE TABLE IF NOT EXISTS messages (
+	session_id TEXT NOT NULL,
+	seq        INTEGER NOT NULL,
+	message    TEXT NOT NULL,
+	PRIMARY KEY (session_id, seq)
+);
+CREATE TABLE IF NOT EXISTS turns (
+	session_id TEXT NOT NULL,
+	seq        INTEGER NOT NULL,
+	turn       TEXT NOT NULL,
+	PRIMARY KEY (session_id, seq)
+);
+CREATE TABLE IF NOT EXISTS tool_results (
+	session_id  TEXT NOT NULL,
+	tool_use_id TEXT NOT NULL,
+	result      TEXT NOT NULL,
+	PRIMARY KEY (session_id, tool_use_id)
+);
+CREATE VIRTUAL TABLE IF NOT EXISTS messages_fts USING fts5(
+	body, session_id UNINDEXED, seq UNINDEXED, tokenize = 'trigram'
+);`
+
+// openSQLite opens (creating if needed) the session database at path.
+func openSQLite(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, fmt.Errorf("opening session database: %w", err)
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("creating session database: %w", err)
+	}
+	return db, nil
+}
+
+// UseSQLite moves the store to the SQLite backend: sessions are kept in
+// sessions.db in the session directory instead of one JSON file and
+// message log each, which keeps listing and searching fast with many
+// sessions. Sessions already saved as JSON are copied in the first time;
+// the files are left in place. Once the database exists, NewStore uses it
+// for the project whether or not this is called.
+func (s *Store) UseSQLite() error {
+	if s.db != nil {
+		return nil
+	}
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return fmt.Errorf("creating session directory: %w", err)
+	}
+	db, err := openSQLite(filepath.Join(s.dir, sqliteFile))
+	if err != nil {
+		return err
+	}
+
+	files, err := s.listFiles()
+	if err != nil {
+		db.Close()
+		return err
+	}
+	s.db = db
+	for _, sess := range files {
+		if s.Exists(sess.ID) {
+			continue
+		}
+		if err := s.writeSQL(sess); err != nil {
+			s.db = nil
+			db.Close()
+			return fmt.Errorf("copying session %s into the database: %w", sess.ID, err)
+		}
+	}
+	return nil
+}
+
+// openExistingSQLite switches the store to its session database if the
+// project has one.
+func (s *Store) openExistingSQLite() error {
+	path := filepath.Join(s.dir, sqliteFile)
+	if _, err := os.Stat(path); err != nil {
+		return nil
+	}
+	db, err := openSQLite(path)
+	if err != nil {
+		return err
+	}
+	s.db = db
+	return nil
+}
+
+// Close releases the session database, if the store uses one, and the
+// sessions locked with Lock.
+func (s *Store) Close() error {
+	s.unlockAll()
+	if s.db == nil {
+		return nil
+	}
+	return s.db.Close()
+}
+
+// saveSQL saves a session to the database, picking up a generated title
+// as writeMeta does.
+func (s *Store) saveSQL(session *Session) error {
+	s.metaMu.Lock()
+	defer s.metaMu.Unlock()
+	s.applyTitle(session)
+	return s.writeSQL(session)
+}
+
+// writeSQL writes a session's metadata and any messages and turns added
+// since the last save. As with the message log, a history whose earlier
+// messages changed (compaction) replaces the stored one.
+func (s *Store) writeSQL(session *Session) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.logs == nil {
+		s.logs = make(map[string]*logState)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("saving session: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			delete(s.logs, session.ID) // re-read what's stored next time
+		}
+	}()
+
+	state, ok := s.logs[session.ID]
+	if !ok {
+		if state, err = storedLogState(tx, session.ID); err != nil {
+			return err
+		}
+	}
+
+	msgs := session.Messages
+	start := state.written
+	if start > len(msgs) || (start > 0 && messageFingerprint(msgs[start-1]) != state.last) {
+		for _, table := range []string{"messages", "messages_fts"} {
+			if _, err = tx.Exec("DELETE FROM "+table+" WHERE session_id = ?", session.ID); err != nil {
+				return fmt.Errorf("saving session: %w", err)
+			}
+		}
+		start = 0
+	}
+	if start < len(msgs) {
+		if _, err = tx.Exec("DELETE FROM tool_results WHERE session_id = ?", session.ID); err != nil {
+			return fmt.Errorf("saving session: %w", err)
+		}
+	}
+	for i := start; i < len(msgs); i++ {
+		data, merr := json.Marshal(msgs[i])
+		if merr != nil {
+			return fmt.Errorf("marshaling message: %w", merr)
+		}
+		var stored any = string(data)
+		if z := compressMessage(data); z != nil {
+			stored = z // a BLOB; loadSQL tells it apart by the gzip header
+			data = z
+		}
+		if Encrypted() {
+			if stored, err = Seal(data); err != nil {
+				return err
+			}
+		}
+		if _, err = tx.Exec("INSERT INTO messages (session_id, seq, message) VALUES (?, ?, ?)", session.ID, i, stored); err != nil {
+			return fmt.Errorf("saving session: %w", err)
+		}
+		// Indexing an encrypted message would keep its text in the clear.
+		if body := messageSearchText(msgs[i]); body != "" && !Encrypted() {
+			if _, err = tx.Exec("INSERT INTO messages_fts (body, session_id, seq) VALUES (?, ?, ?)", body, session.ID, i); err != nil {
+				return fmt.Errorf("saving session: %w", err)
+			}
+		}
+	}
+
+	for i := state.turns; i < len(session.Turns); i++ {
+		data, merr := json.Marshal(session.Turns[i])
+		if merr != nil {
+			return fmt.Errorf("marshaling turn: %w", merr)
+		}
+		if _, err = tx.Exec("INSERT OR REPLACE INTO turns (session_id, seq, turn) VALUES (?, ?, ?)", session.ID, i, string(data)); err != nil {
+			return fmt.Errorf("saving session: %w", err)
+		}
+	}
+
+	meta := *session
+	meta.Messages = nil
+	meta.Turns = nil
+	meta.MessageLog = false
+	meta.MessageCount = len(msgs)
+	if err = meta.sealTodos(); err != nil {
+		return err
+	}
+	data, err := json.Marshal(&meta)
+	if err != nil {
+		return fmt.Errorf("marshaling session: %w", err)
+	}
+	if _, err = tx.Exec(`INSERT INTO sessions (id, meta, title, updated_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET meta = excluded.meta, title = excluded.title, updated_at = excluded.updated_at`,
+		session.ID, string(data), session.Title, session.UpdatedAt.UnixNano()); err != nil {
+		return fmt.Errorf("saving session: %w", err)
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("saving session: %w", err)
+	}
+
+	state.written = len(msgs)
+	if len(msgs) > 0 {
+		state.last = messageFingerprint(msgs[len(msgs)-1])
+	}
+	state.turns = max(state.turns, len(session.Turns))
+	s.logs[session.ID] = state
+	return nil
+}
+
+// storedLogState reads how much of a session is already in the database.
+func storedLogState(tx *sql.Tx, id string) (*logState, error) {
+	state := &logState{}
+	var last sql.NullString
+	err := tx.QueryRow("SELECT COUNT(*), (SELECT message FROM messages WHERE session_id = ?1 ORDER BY seq DESC LIMIT 1) FROM messages WHERE session_id = ?1", id).
+		Scan(&state.written, &last)
+	if err != nil {
+		return nil, fmt.Errorf("reading session: %w", err)
+	}
+	if last.Valid {
+		msg, err := decodeMessage([]byte(last.String))
+		if err != nil {
+			return nil, err
+		}
+		state.last = messageFingerprint(msg)
+	}
+	if err := tx.QueryRow("SELECT COUNT(*) FROM turns WHERE session_id = ?", id).Scan(&state.turns); err != nil {
+		return nil, fmt.Errorf("reading session: %w", err)
+	}
+	return state, nil
+}
+
+// messageSearchText is the text of a message that search looks at: its
+// text, thinking, tool inputs, and tool results.
+func messageSearchText(msg api.Message) string {
+	var parts []string
+	for _, t := range exportTurns([]api.Message{msg}) {
+		for _, p := range t.parts {
+			if p.kind != "image" {
+				parts = append(parts, strings.TrimSpace(p.title+"\n"+p.body))
+			}
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// loadSQL reads a session from the database.
+func (s *Store) loadSQL(id string) (*Session, error) {
+	sessions, err := s.querySQL("WHERE id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(sessions) == 0 {
+		return nil, fmt.Errorf("session %s not found", id)
+	}
+	return sessions[0], nil
+}
+
+// querySQL loads the sessions matching a WHERE clause, newest first, with
+// their messages and turns.
+func (s *Store) querySQL(where string, args ...any) ([]*Session, error) {
+	rows, err := s.db.Query("SELECT meta FROM sessions "+where+" ORDER BY updated_at DESC", args...)
+	if err != nil {
+		return nil, fmt.Errorf("reading sessions: %w", err)
+	}
+	var sessions []*Session
+	byID := make(map[string]*Session)
+	for rows.Next() {
+		var meta string
+		if err := rows.Scan(&meta); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("reading sessions: %w", err)
+		}
+		var sess Session
+		if err := json.Unmarshal([]byte(meta), &sess); err != nil {
+			continue // skip corrupt rows
+		}
+		if err := sess.openTodos(); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		sessions = append(sessions, &sess)
+		byID[sess.ID] = &sess
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reading sessions: %w", err)
+	}
+	if len(sessions) == 0 {
+		return nil, nil
+	}
+
+	err = s.eachRow("SELECT session_id, message FROM messages WHERE session_id IN (SELECT id FROM sessions "+where+") ORDER BY session_id, seq", args,
+		func(id string, data []byte) error {
+			msg, err := decodeMessage(data)
+			if err != nil {
+				return err
+			}
+			if sess := byID[id]; sess != nil {
+				sess.Messages = append(sess.Messages, msg)
+			}
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	err = s.eachRow("SELECT session_id, turn FROM turns WHERE session_id IN (SELECT id FROM sessions "+where+") ORDER BY session_id, seq", args,
+		func(id string, data []byte) error {
+			var turn conversation.TurnMetadata
+			if err := json.Unmarshal(data, &turn); err != nil {
+				return fmt.Errorf("parsing turn: %w", err)
+			}
+			if sess := byID[id]; sess != nil {
+				sess.Turns = append(sess.Turns, turn)
+			}
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	pending := make(map[string][]api.ContentBlock)
+	err = s.eachRow("SELECT session_id, result FROM tool_results WHERE session_id IN (SELECT id FROM sessions "+where+") ORDER BY rowid", args,
+		func(id string, data []byte) error {
+			data, err := Open(data)
+			if err != nil {
+				return err
+			}
+			var result api.ContentBlock
+			if err := json.Unmarshal(data, &result); err != nil {
+				return fmt.Errorf("parsing tool result: %w", err)
+			}
+			pending[id] = append(pending[id], result)
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	for _, sess := range sessions {
+		sess.Messages = completeInterruptedTurn(sess.Messages, pending[sess.ID])
+	}
+	return sessions, nil
+}
+
+// appendToolResultSQL journals a finished tool call's result.
+func (s *Store) appendToolResultSQL(id string, result api.ContentBlock) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("marshaling tool result: %w", err)
+	}
+	if data, err = Seal(data); err != nil {
+		return err
+	}
+	if _, err := s.db.Exec("INSERT OR REPLACE INTO tool_results (session_id, tool_use_id, result) VALUES (?, ?, ?)", id, result.ToolUseID, string(data)); err != nil {
+		return fmt.Errorf("saving tool result: %w", err)
+	}
+	return nil
+}
+
+// eachRow runs a query returning (session ID, JSON) rows and calls fn on
+// each.
+func (s *Store) eachRow(query string, args []any, fn func(id string, data []byte) error) error {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return fmt.Errorf("reading sessions: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id, data string
+		if err := rows.Scan(&id, &data); err != nil {
+			return fmt.Errorf("reading sessions: %w", err)
+		}
+		if err := fn(id, []byte(data)); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// searchSQL applies a filter in the database: dates on the indexed
+// update time and text through the full-text index, or the title. Only
+// the matching sessions are loaded.
+func (s *Store) searchSQL(f Filter) ([]Match, error) {
+	var conds []string
+	var args []any
+	if !f.Since.IsZero() {
+		conds = append(conds, "updated_at >= ?")
+		args = append(args, f.Since.UnixNano())
+	}
+	if !f.Until.IsZero() {
+		conds = append(conds, "updated_at < ?")
+		args = append(args, f.Until.UnixNano())
+	}
+	// The trigram index can't look up fewer than three characters, and
+	// doesn't have encrypted messages; those searches scan the loaded
+	// sessions instead.
+	indexed := utf8.RuneCountInString(f.Text) >= 3 && !Encrypted()
+	if f.Text != "" && indexed {
+		conds = append(conds, `(title LIKE ? ESCAPE '\' OR id IN (SELECT session_id FROM messages_fts WHERE messages_fts MATCH ?))`)
+		args = append(args, "%"+likeEscaper.Replace(f.Text)+"%", `"`+strings.ReplaceAll(f.Text, `"`, `""`)+`"`)
+	}
+	where := ""
+	if len(conds) > 0 {
+		where = "WHERE " + strings.Join(conds, " AND ")
+	}
+	sessions, err := s.querySQL(where, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	if f.Text == "" {
+		return Filter{}.Apply(sessions), nil
+	}
+	if !indexed {
+		return Filter{Text: f.Text}.Apply(sessions), nil
+	}
+	matches := make([]Match, 0, len(sessions))
+	for _, sess := range sessions {
+		// The index found it; searching the loaded session finds the
+		// snippet.
+		snippet, _ := searchSession(sess, f.Text)
+		matches = append(matches, Match{Session: sess, Snippet: snippet})
+	}
+	return matches, nil
+}
+
+// likeEscaper escapes the wildcards of a LIKE pattern.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// deleteSQL removes a session's rows.
+func (s *Store) deleteSQL(id string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("deleting session: %w", err)
+	}
+	defer tx.Rollback()
+	res, err := tx.Exec("DELETE FROM sessions WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("deleting session: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("session %s not found", id)
+	}
+	for _, table := range []string{"messages", "turns", "messages_fts", "tool_results"} {
+		if _, err := tx.Exec("DELETE FROM "+table+" WHERE session_id = ?", id); err != nil {
+			return fmt.Errorf("deleting session: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("deleting session: %w", err)
+	}
+	return nil
+}
+
+// writeTitleSQL records a generated title unless one was set meanwhile.
+func (s *Store) writeTitleSQL(id, title string) {
+	s.db.Exec("UPDATE sessions SET title = ?1, meta = json_set(meta, '$.title', ?1) WHERE id = ?2 AND title = ''", title, id)
+}
+
+// updateMetaSQL rewrites a session's stored metadata with change applied.
+func (s *Store) updateMetaSQL(id string, change func(*Session)) error {
+	var data string
+	if err := s.db.QueryRow("SELECT meta FROM sessions WHERE id = ?", id).Scan(&data); err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("session %s not found", id)
+		}
+		return fmt.Errorf("reading session: %w", err)
+	}
+	var meta Session
+	if err := json.Unmarshal([]byte(data), &meta); err != nil {
+		return fmt.Errorf("parsing session: %w", err)
+	}
+	change(&meta)
+	updated, err := json.Marshal(&meta)
+	if err != nil {
+		return fmt.Errorf("marshaling session: %w", err)
+	}
+	if _, err := s.db.Exec("UPDATE sessions SET meta = ? WHERE id = ?", string(updated), id); err != nil {
+		return fmt.Errorf("updating session: %w", err)
+	}
+	return nil
+}
+
+// findNameSQL returns the ID of the session named name, or "".
+func (s *Store) findNameSQL(name string) (string, error) {
+	var id string
+	err := s.db.QueryRow("SELECT id FROM sessions WHERE json_extract(meta, '$.name') = ?", name).Scan(&id)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("looking up session name: %w", err)
+	}
+	return id, nil
+}
+
+// listMetaSQL returns every session's metadata, newest first.
+func (s *Store) listMetaSQL() ([]*Session, error) {
+	var metas []*Session
+	err := s.eachRow("SELECT id, meta FROM sessions ORDER BY updated_at DESC", nil, func(id string, data []byte) error {
+		var meta Session
+		if err := json.Unmarshal(data, &meta); err != nil {
+			return fmt.Errorf("parsing session %s: %w", id, err)
+		}
+		metas = append(metas, &meta)
+		return nil
+	})
+	return metas, err
+}
+
+// sessionSizeSQL returns the bytes of a session's rows, not counting the
+// database's own overhead.
+func (s *Store) sessionSizeSQL(id string) int64 {
+	var n int64
+	s.db.QueryRow(`SELECT
+		(SELECT COALESCE(SUM(length(meta)), 0) FROM sessions WHERE id = ?1) +
+		(SELECT COALESCE(SUM(length(message)), 0) FROM messages WHERE session_id = ?1) +
+		(SELECT COALESCE(SUM(length(turn)), 0) FROM turns WHERE session_id = ?1)`, id).Scan(&n)
+	return n
+}
+
+// existsSQL reports whether the database has the session.
+func (s *Store) existsSQL(id string) bool {
+	var n int
+	return s.db.QueryRow("SELECT COUNT(*) FROM sessions WHERE id = ?", id).Scan(&n) == nil && n > 0
+}
@@ -0,0 +1,310 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anthropics/claude-code-go/internal/api"
+	"github.com/anthropics/claude-code-go/internal/conversation"
+)
+
+// newSQLiteStore returns a store using the SQLite backend in a temporary
+// directory.
+func newSQLiteStore(t *testing.T, dir string) *Store {
+	t.Helper()
+	store := NewStoreWithDir(dir)
+	if err := store.UseSQLite(); err != nil {
+		t.Fatalf("UseSQLite: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestSQLiteRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	store := newSQLiteStore(t, dir)
+
+	sess := &Session{
+		ID:       "sql-1",
+		Model:    "claude-sonnet-4-6",
+		CWD:      "/tmp/project",
+		Messages: []api.Message{api.NewTextMessage(api.RoleUser, "one")},
+		Turns:    []conversation.TurnMetadata{{Model: "claude-sonnet-4-6", StopReason: "end_turn"}},
+	}
+	if err := store.Save(sess); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	sess.Messages = append(sess.Messages, api.NewTextMessage(api.RoleAssistant, "two"))
+	sess.Turns = append(sess.Turns, conversation.TurnMetadata{Model: "claude-sonnet-4-6", Tools: []string{"Bash"}})
+	if err := store.Save(sess); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	// A second store opens the database without being told to.
+	other := NewStoreWithDir(dir)
+	if err := other.openExistingSQLite(); err != nil {
+		t.Fatalf("openExistingSQLite: %v", err)
+	}
+	defer other.Close()
+	loaded, err := other.Load("sql-1")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if loaded.Model != sess.Model || loaded.CWD != sess.CWD {
+		t.Errorf("loaded metadata = %+v", loaded)
+	}
+	if len(loaded.Messages) != 2 || loaded.MessageCount != 2 {
+		t.Errorf("loaded %d messages (count %d), want 2", len(loaded.Messages), loaded.MessageCount)
+	}
+	if len(loaded.Turns) != 2 || loaded.Turns[1].Tools[0] != "Bash" {
+		t.Errorf("loaded turns = %+v, want 2", loaded.Turns)
+	}
+
+	var n int
+	store.db.QueryRow("SELECT COUNT(*) FROM messages WHERE session_id = ?", "sql-1").Scan(&n)
+	if n != 2 {
+		t.Errorf("stored messages = %d, want 2 (each written once)", n)
+	}
+	if !store.Exists("sql-1") || store.Exists("missing") {
+		t.Error("Exists is wrong")
+	}
+	if _, err := store.Load("missing"); err == nil {
+		t.Error("Load of a missing session should fail")
+	}
+}
+
+func TestSQLiteCompaction(t *testing.T) {
+	store := newSQLiteStore(t, t.TempDir())
+
+	sess := &Session{ID: "sql-2", Messages: []api.Message{
+		api.NewTextMessage(api.RoleUser, "one"),
+		api.NewTextMessage(api.RoleAssistant, "two"),
+		api.NewTextMessage(api.RoleUser, "three"),
+	}}
+	if err := store.Save(sess); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	sess.Messages = []api.Message{
+		api.NewTextMessage(api.RoleUser, "[Conversation Summary]\nsummary"),
+		api.NewTextMessage(api.RoleAssistant, "ok"),
+	}
+	if err := store.Save(sess); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded, err := store.Load("sql-2")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(loaded.Messages) != 2 {
+		t.Fatalf("loaded %d messages, want 2", len(loaded.Messages))
+	}
+	var text string
+	json.Unmarshal(loaded.Messages[1].Content, &text)
+	if text != "ok" {
+		t.Errorf("last message = %q, want %q", text, "ok")
+	}
+	// The compacted-away text is gone from the search index too.
+	if got, _ := store.Search(Filter{Text: "three"}); len(got) != 0 {
+		t.Errorf("search after compaction = %d matches, want 0", len(got))
+	}
+}
+
+func TestSQLiteCompression(t *testing.T) {
+	store := newSQLiteStore(t, t.TempDir())
+	output := strings.Repeat("PASS: TestParser (0.00s)\n", 2000) + "FAIL: TestLexer"
+	sess := &Session{ID: "big", Messages: []api.Message{
+		api.NewTextMessage(api.RoleUser, "run the tests"),
+		api.NewTextMessage(api.RoleAssistant, output),
+	}}
+	if err := store.Save(sess); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	var size int
+	store.db.QueryRow("SELECT length(message) FROM messages WHERE session_id = 'big' AND seq = 1").Scan(&size)
+	if size == 0 || size > len(output)/4 {
+		t.Errorf("stored message is %d bytes, want it compressed", size)
+	}
+	loaded, err := store.Load("big")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if MessageText(loaded.Messages[1]) != output {
+		t.Error("compressed message did not round-trip")
+	}
+	// The search index keeps the text.
+	if got, _ := store.Search(Filter{Text: "TestLexer"}); len(got) != 1 {
+		t.Errorf("search = %d matches, want 1", len(got))
+	}
+}
+
+func TestSQLiteMigratesJSONSessions(t *testing.T) {
+	dir := t.TempDir()
+	files := NewStoreWithDir(dir)
+	sess := &Session{ID: "json-1", Messages: []api.Message{api.NewTextMessage(api.RoleUser, "from a file")}}
+	if err := files.Save(sess); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	store := newSQLiteStore(t, dir)
+	loaded, err := store.Load("json-1")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if FirstUserMessage(loaded) != "from a file" {
+		t.Errorf("migrated session = %+v", loaded)
+	}
+	if !loaded.UpdatedAt.Equal(sess.UpdatedAt) {
+		t.Errorf("UpdatedAt = %v, want %v kept", loaded.UpdatedAt, sess.UpdatedAt)
+	}
+	// Enabling it again doesn't copy the session twice.
+	store.db.Close()
+	store.db = nil
+	if err := store.UseSQLite(); err != nil {
+		t.Fatalf("UseSQLite: %v", err)
+	}
+	if list, _ := store.List(); len(list) != 1 {
+		t.Errorf("listed %d sessions, want 1", len(list))
+	}
+}
+
+func TestSQLiteSearch(t *testing.T) {
+	store := newSQLiteStore(t, t.TempDir())
+	day := func(d int) time.Time { return time.Date(2026, 10, d, 12, 0, 0, 0, time.UTC) }
+	for _, sess := range []*Session{
+		{ID: "a", UpdatedAt: day(10), Title: "Fix the login test", Messages: []api.Message{api.NewTextMessage(api.RoleUser, "hi")}},
+		{ID: "b", UpdatedAt: day(5), Messages: []api.Message{
+			api.NewTextMessage(api.RoleUser, "Why does the parser reject Trailing Commas in arrays?"),
+		}},
+		{ID: "c", UpdatedAt: day(1), Messages: []api.Message{api.NewTextMessage(api.RoleUser, "nothing here, 100%")}},
+	} {
+		if err := store.writeSQL(sess); err != nil {
+			t.Fatalf("writeSQL: %v", err)
+		}
+	}
+	ids := func(ms []Match) string {
+		var s string
+		for _, m := range ms {
+			s += m.Session.ID
+		}
+		return s
+	}
+	search := func(f Filter) []Match {
+		t.Helper()
+		got, err := store.Search(f)
+		if err != nil {
+			t.Fatalf("Search: %v", err)
+		}
+		return got
+	}
+
+	if got := ids(search(Filter{})); got != "abc" {
+		t.Errorf("no filter = %q, want abc", got)
+	}
+	if got := ids(search(Filter{Since: day(5), Until: day(10)})); got != "b" {
+		t.Errorf("date range = %q, want b", got)
+	}
+	if got := search(Filter{Text: "LOGIN"}); ids(got) != "a" || got[0].Snippet != "" {
+		t.Errorf("title search = %+v, want a with no snippet", got)
+	}
+	got := search(Filter{Text: "trailing commas"})
+	if ids(got) != "b" || got[0].Snippet == "" {
+		t.Errorf("message search = %+v, want b with a snippet", got)
+	}
+	if got := ids(search(Filter{Text: "hi"})); got != "ac" {
+		t.Errorf("short search = %q, want ac", got)
+	}
+	if got := ids(search(Filter{Text: `0% "x`})); got != "" {
+		t.Errorf("quoted search = %q, want none", got)
+	}
+	if got := ids(search(Filter{Text: "100%"})); got != "c" {
+		t.Errorf("wildcard search = %q, want c", got)
+	}
+}
+
+func TestSQLiteDelete(t *testing.T) {
+	store := newSQLiteStore(t, t.TempDir())
+	sess := &Session{ID: "gone", Messages: []api.Message{api.NewTextMessage(api.RoleUser, "delete me")}}
+	if err := store.Save(sess); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if err := store.Delete("gone"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if store.Exists("gone") {
+		t.Error("session still exists after Delete")
+	}
+	if got, _ := store.Search(Filter{Text: "delete me"}); len(got) != 0 {
+		t.Errorf("search after Delete = %d matches, want 0", len(got))
+	}
+	if err := store.Delete("gone"); err == nil {
+		t.Error("deleting a missing session should fail")
+	}
+}
+
+func TestSQLiteTitle(t *testing.T) {
+	store := newSQLiteStore(t, t.TempDir())
+	store.SetTitler(func(ctx context.Context, msgs []api.Message) (string, error) {
+		return "Generated title", nil
+	})
+	sess := &Session{ID: "titled", Messages: []api.Message{
+		api.NewTextMessage(api.RoleUser, "hello"),
+		api.NewTextMessage(api.RoleAssistant, "hi"),
+	}}
+	if err := store.Save(sess); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	store.titleWG.Wait()
+
+	loaded, err := store.Load("titled")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if loaded.Title != "Generated title" {
+		t.Errorf("Title = %q, want the generated title", loaded.Title)
+	}
+	if got, _ := store.Search(Filter{Text: "generated"}); len(got) != 1 {
+		t.Errorf("title search = %d matches, want 1", len(got))
+	}
+}
+
+func TestSQLiteInterruptedTurn(t *testing.T) {
+	store := newSQLiteStore(t, t.TempDir())
+	sess := &Session{ID: "crash", Messages: []api.Message{
+		api.NewTextMessage(api.RoleUser, "build it"),
+		toolUseMessage("toolu_1", "toolu_2"),
+	}}
+	if err := store.Save(sess); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if err := store.AppendToolResult("crash", api.ContentBlock{Type: api.ContentTypeToolResult, ToolUseID: "toolu_1", Content: json.RawMessage(`"built"`)}); err != nil {
+		t.Fatalf("AppendToolResult: %v", err)
+	}
+
+	loaded, err := store.Load("crash")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	var results []api.ContentBlock
+	json.Unmarshal(loaded.Messages[len(loaded.Messages)-1].Content, &results)
+	if len(results) != 2 || results[0].IsError || !results[1].IsError {
+		t.Fatalf("results = %+v, want toolu_1's result and toolu_2 interrupted", results)
+	}
+
+	// Saving the turn's results clears the journal.
+	if err := store.Save(loaded); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	var n int
+	store.db.QueryRow("SELECT COUNT(*) FROM tool_results").Scan(&n)
+	if n != 0 {
+		t.Errorf("journaled results = %d after save, want 0", n)
+	}
+	if again, _ := store.Load("crash"); len(again.Messages) != 3 {
+		t.Errorf("loaded %d messages, want 3", len(again.Messages))
+	}
+}
@@ -0,0 +1,261 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// ErrSyncConflict is returned by SyncBackend.Put when the remote session
+// is not the version the caller expected.
+var ErrSyncConflict = errors.New("remote session changed")
+
+// RemoteSession is a session bundle stored by a SyncBackend.
+type RemoteSession struct {
+	ID      string
+	ETag    string // changes whenever the bundle does
+	Updated time.Time
+	Size    int64
+}
+
+// SyncBackend stores session bundles remotely, grouped by project, so
+// that `claude sessions sync` can carry sessions between machines.
+// Implementations must be safe to use from one goroutine at a time.
+type SyncBackend interface {
+	// List returns the project's remote sessions. A project with none,
+	// including one never synced, has an empty list.
+	List(ctx context.Context, project string) ([]RemoteSession, error)
+
+	// Get returns a session's bundle and its ETag.
+	Get(ctx context.Context, project, id string) ([]byte, string, error)
+
+	// Put stores a session's bundle and returns its new ETag. prev is
+	// the ETag the remote bundle is expected to have, or "" if it is
+	// expected not to exist; when the backend can check, a mismatch
+	// gives ErrSyncConflict. The ETag may be "" if the backend didn't
+	// report one.
+	Put(ctx context.Context, project, id string, data []byte, prev string) (string, error)
+}
+
+// syncStatePath returns the path of the file recording what the store
+// last synced. It is JSON, but not named .json, which would list it as a
+// session.
+func (s *Store) syncStatePath() string {
+	return filepath.Join(s.dir, "sync.state")
+}
+
+// syncedVersion is a session as of its last sync: its local update time
+// and the remote bundle's ETag. A side whose version differs has changed
+// since.
+type syncedVersion struct {
+	Updated time.Time `json:"updated"`
+	ETag    string    `json:"etag"`
+}
+
+// SyncResult lists what Store.Sync did, by session ID.
+type SyncResult struct {
+	Pushed    []string
+	Pulled    []string
+	Conflicts []string          // sessions changed on both sides; see Sync
+	Skipped   map[string]string // sessions left alone, with the reason
+}
+
+// Sync pushes the store's new and changed sessions to the backend under
+// project, and pulls those new or changed remotely, as bundles. cwd is
+// the project's directory on this machine; pulled sessions have their
+// paths rewritten to it, as with ImportBundle.
+//
+// Changes are judged against the last sync, recorded in sync.state in the
+// store's directory. A session changed on both sides is a conflict: the
+// remote version is imported as a fork of it, and the local one is pushed
+// over it, so nothing is lost and both show up in /resume. A session
+// that was synced and has since been deleted on one side is not copied
+// back. A session open in another process isn't replaced.
+func (s *Store) Sync(ctx context.Context, b SyncBackend, project, cwd string) (*SyncResult, error) {
+	if Encrypted() {
+		return nil, fmt.Errorf("sessions are encrypted at rest and sync would upload them in the clear")
+	}
+	local, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	remoteList, err := b.List(ctx, project)
+	if err != nil {
+		return nil, fmt.Errorf("listing remote sessions: %w", err)
+	}
+	state, err := s.readSyncState()
+	if err != nil {
+		return nil, err
+	}
+
+	localByID := make(map[string]*Session, len(local))
+	remote := make(map[string]RemoteSession, len(remoteList))
+	var ids []string
+	for _, sess := range local {
+		localByID[sess.ID] = sess
+		ids = append(ids, sess.ID)
+	}
+	for _, r := range remoteList {
+		remote[r.ID] = r
+		if localByID[r.ID] == nil {
+			ids = append(ids, r.ID)
+		}
+	}
+	sort.Strings(ids)
+
+	res := &SyncResult{Skipped: make(map[string]string)}
+	push := func(sess *Session, prev string) error {
+		var buf bytes.Buffer
+		if err := s.ExportBundle(sess.ID, &buf); err != nil {
+			return err
+		}
+		etag, err := b.Put(ctx, project, sess.ID, buf.Bytes(), prev)
+		if errors.Is(err, ErrSyncConflict) {
+			res.Skipped[sess.ID] = "changed remotely during sync; sync again"
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("pushing session %s: %w", sess.ID, err)
+		}
+		state[sess.ID] = syncedVersion{Updated: sess.UpdatedAt, ETag: etag}
+		res.Pushed = append(res.Pushed, sess.ID)
+		return nil
+	}
+	// pull imports the remote session id, replacing the local one, or as
+	// a fork under newID when that is set.
+	pull := func(id, newID string) (*Session, error) {
+		data, etag, err := b.Get(ctx, project, id)
+		if err != nil {
+			return nil, fmt.Errorf("pulling session %s: %w", id, err)
+		}
+		if newID == "" && s.Exists(id) {
+			if err := s.Delete(id); err != nil {
+				var locked *LockedError
+				if errors.As(err, &locked) {
+					res.Skipped[id] = fmt.Sprintf("open in process %d", locked.PID)
+					return nil, nil
+				}
+				return nil, err
+			}
+		}
+		imported, err := s.importBundle(bytes.NewReader(data), cwd, newID)
+		if err != nil {
+			return nil, fmt.Errorf("pulling session %s: %w", id, err)
+		}
+		if newID == "" {
+			state[id] = syncedVersion{Updated: imported.Session.UpdatedAt, ETag: etag}
+			res.Pulled = append(res.Pulled, id)
+		}
+		return imported.Session, nil
+	}
+
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+		last, synced := state[id]
+		sess, r := localByID[id], remote[id]
+		hasRemote := r.ID != ""
+		localChanged := sess != nil && (!synced || !sess.UpdatedAt.Equal(last.Updated))
+		remoteChanged := hasRemote && (!synced || r.ETag != last.ETag)
+
+		switch {
+		case sess != nil && !hasRemote:
+			if synced && !localChanged {
+				continue // deleted remotely
+			}
+			err = push(sess, "")
+		case sess == nil:
+			if synced && !remoteChanged {
+				continue // deleted here
+			}
+			_, err = pull(id, "")
+		case localChanged && remoteChanged:
+			var fork *Session
+			if fork, err = pull(id, GenerateID()); err != nil {
+				break
+			}
+			res.Conflicts = append(res.Conflicts, id)
+			if err = push(sess, r.ETag); err != nil {
+				break
+			}
+			err = push(fork, "")
+		case localChanged:
+			err = push(sess, r.ETag)
+		case remoteChanged:
+			_, err = pull(id, "")
+		}
+		if err != nil {
+			s.writeSyncState(state)
+			return res, err
+		}
+	}
+
+	// Fill in ETags the backend didn't return from a PUT. Without them
+	// the next sync would take the sessions for changed remotely.
+	missing := false
+	for _, id := range res.Pushed {
+		missing = missing || state[id].ETag == ""
+	}
+	if missing {
+		if remoteList, err := b.List(ctx, project); err == nil {
+			for _, r := range remoteList {
+				if v, ok := state[r.ID]; ok && v.ETag == "" {
+					v.ETag = r.ETag
+					state[r.ID] = v
+				}
+			}
+		}
+	}
+	return res, s.writeSyncState(state)
+}
+
+// readSyncState reads sync.state; a store never synced has an empty state.
+func (s *Store) readSyncState() (map[string]syncedVersion, error) {
+	state := make(map[string]syncedVersion)
+	data, err := os.ReadFile(s.syncStatePath())
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading sync state: %w", err)
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("parsing sync state: %w", err)
+	}
+	return state, nil
+}
+
+// writeSyncState replaces sync.state.
+func (s *Store) writeSyncState(state map[string]syncedVersion) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling sync state: %w", err)
+	}
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return fmt.Errorf("creating session directory: %w", err)
+	}
+	if err := writeFileAtomic(s.syncStatePath(), data); err != nil {
+		return fmt.Errorf("writing sync state: %w", err)
+	}
+	return nil
+}
+
+// readAllLimited reads a response body up to bundleMaxEntry bytes.
+func readAllLimited(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, bundleMaxEntry+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > bundleMaxEntry {
+		return nil, fmt.Errorf("remote session is too large")
+	}
+	return data, nil
+}
@@ -0,0 +1,287 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Options configures an S3Backend.
+type S3Options struct {
+	Bucket string
+	Prefix string // key prefix, e.g. "claude/sessions"
+	Region string // default us-east-1
+
+	// Endpoint is the service URL for S3-compatible stores (MinIO, R2).
+	// Empty uses AWS: https://s3.<region>.amazonaws.com.
+	Endpoint string
+
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string // for temporary credentials
+}
+
+// S3Backend is a SyncBackend storing bundles in an S3 bucket, as
+// <prefix>/<project>/<id>.bundle, with path-style requests signed with
+// AWS Signature Version 4.
+type S3Backend struct {
+	opts     S3Options
+	endpoint *url.URL
+	client   *http.Client
+	now      func() time.Time // for tests
+}
+
+// NewS3Backend returns a backend for the bucket in opts.
+func NewS3Backend(opts S3Options) (*S3Backend, error) {
+	if opts.Bucket == "" {
+		return nil, fmt.Errorf("S3 sync needs a bucket")
+	}
+	if opts.AccessKeyID == "" || opts.SecretAccessKey == "" {
+		return nil, fmt.Errorf("S3 sync needs AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	}
+	if opts.Region == "" {
+		opts.Region = "us-east-1"
+	}
+	if opts.Endpoint == "" {
+		opts.Endpoint = "https://s3." + opts.Region + ".amazonaws.com"
+	}
+	u, err := url.Parse(opts.Endpoint)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", opts.Endpoint)
+	}
+	opts.Prefix = strings.Trim(opts.Prefix, "/")
+	return &S3Backend{opts: opts, endpoint: u, client: &http.Client{Timeout: 5 * time.Minute}, now: time.Now}, nil
+}
+
+// key returns the object key of a project's path.
+func (b *S3Backend) key(project, name string) string {
+	key := project + "/" + name
+	if b.opts.Prefix != "" {
+		key = b.opts.Prefix + "/" + key
+	}
+	return key
+}
+
+// do sends a signed request for an object key ("" for the bucket).
+func (b *S3Backend) do(ctx context.Context, method, key string, query url.Values, body []byte, header http.Header) (*http.Response, error) {
+	u := *b.endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + b.opts.Bucket
+	if key != "" {
+		u.Path += "/" + key
+	}
+	u.RawPath = ""
+	u.RawQuery = canonicalQuery(query)
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	signV4(req, body, b.opts, b.now())
+	return b.client.Do(req)
+}
+
+// s3ListResult is the part of a ListObjectsV2 response List reads.
+type s3ListResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		ETag         string    `xml:"ETag"`
+		LastModified time.Time `xml:"LastModified"`
+		Size         int64     `xml:"Size"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// List pages through ListObjectsV2 under the project's prefix.
+func (b *S3Backend) List(ctx context.Context, project string) ([]RemoteSession, error) {
+	prefix := b.key(project, "")
+	var out []RemoteSession
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		resp, err := b.do(ctx, http.MethodGet, "", query, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("listing bucket: %s", s3Error(resp))
+		}
+		var result s3ListResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("parsing bucket listing: %w", err)
+		}
+		for _, c := range result.Contents {
+			name := strings.TrimPrefix(c.Key, prefix)
+			if strings.Contains(name, "/") || !strings.HasSuffix(name, bundleExt) {
+				continue
+			}
+			out = append(out, RemoteSession{ID: strings.TrimSuffix(name, bundleExt), ETag: c.ETag, Updated: c.LastModified, Size: c.Size})
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return out, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// Get downloads a bundle.
+func (b *S3Backend) Get(ctx context.Context, project, id string) ([]byte, string, error) {
+	resp, err := b.do(ctx, http.MethodGet, b.key(project, id+bundleExt), nil, nil, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("downloading: %s", s3Error(resp))
+	}
+	data, err := readAllLimited(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, resp.Header.Get("ETag"), nil
+}
+
+// Put uploads a bundle with a conditional write (If-Match or
+// If-None-Match), which S3 answers with 412 when it doesn't hold.
+func (b *S3Backend) Put(ctx context.Context, project, id string, data []byte, prev string) (string, error) {
+	header := http.Header{"Content-Type": {"application/gzip"}}
+	if prev == "" {
+		header.Set("If-None-Match", "*")
+	} else {
+		header.Set("If-Match", prev)
+	}
+	resp, err := b.do(ctx, http.MethodPut, b.key(project, id+bundleExt), nil, data, header)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusPreconditionFailed, resp.StatusCode == http.StatusConflict:
+		return "", ErrSyncConflict
+	case resp.StatusCode != http.StatusOK:
+		return "", fmt.Errorf("uploading: %s", s3Error(resp))
+	}
+	return resp.Header.Get("ETag"), nil
+}
+
+// s3Error describes a failed response by its status and S3 error code.
+func s3Error(resp *http.Response) string {
+	var e struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if xml.Unmarshal(data, &e) == nil && e.Code != "" {
+		return fmt.Sprintf("%s: %s %s", resp.Status, e.Code, e.Message)
+	}
+	return resp.Status
+}
+
+// signV4 signs req with AWS Signature Version 4 for S3, covering the
+// host and every header set on the request.
+func signV4(req *http.Request, body []byte, opts S3Options, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+	payload := sha256.Sum256(body)
+	payloadHash := hex.EncodeToString(payload[:])
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if opts.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", opts.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		headers[strings.ToLower(k)] = strings.TrimSpace(strings.Join(v, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonHeaders strings.Builder
+	for _, k := range names {
+		canonHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signed := strings.Join(names, ";")
+
+	canonical := strings.Join([]string{
+		req.Method,
+		s3Escape(req.URL.Path, false),
+		canonicalQuery(req.URL.Query()),
+		canonHeaders.String(),
+		signed,
+		payloadHash,
+	}, "\n")
+	scope := day + "/" + opts.Region + "/s3/aws4_request"
+	sum := sha256.Sum256([]byte(canonical))
+	toSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(sum[:])
+
+	key := []byte("AWS4" + opts.SecretAccessKey)
+	for _, part := range []string{day, opts.Region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		opts.AccessKeyID, scope, signed, hex.EncodeToString(hmacSHA256(key, toSign))))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// canonicalQuery encodes a query string as SigV4 requires: sorted, with
+// RFC 3986 escaping. Sending it in the same form keeps the two in step.
+func canonicalQuery(q url.Values) string {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		vals := append([]string(nil), q[k]...)
+		sort.Strings(vals)
+		for _, v := range vals {
+			parts = append(parts, s3Escape(k, true)+"="+s3Escape(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// s3Escape percent-encodes everything but RFC 3986 unreserved characters,
+// and "/" unless escapeSlash is set.
+func s3Escape(s string, escapeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/' && !escapeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,194 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// bundleExt names session bundles stored by a SyncBackend.
+const bundleExt = ".bundle"
+
+// WebDAVBackend is a SyncBackend storing bundles on a WebDAV server, as
+// <base>/<project>/<id>.bundle.
+type WebDAVBackend struct {
+	base     *url.URL
+	username string
+	password string
+	client   *http.Client
+}
+
+// NewWebDAVBackend returns a backend for the WebDAV collection at
+// baseURL. Credentials in the URL are used when username is empty.
+func NewWebDAVBackend(baseURL, username, password string) (*WebDAVBackend, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid WebDAV URL %q", baseURL)
+	}
+	if username == "" && u.User != nil {
+		username = u.User.Username()
+		password, _ = u.User.Password()
+	}
+	u.User = nil
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/"
+	return &WebDAVBackend{base: u, username: username, password: password, client: &http.Client{Timeout: 5 * time.Minute}}, nil
+}
+
+// url returns the URL of a path under the base collection.
+func (w *WebDAVBackend) url(elem ...string) string {
+	u := *w.base
+	u.Path = path.Join(append([]string{u.Path}, elem...)...)
+	if len(elem) == 1 {
+		u.Path += "/" // a collection
+	}
+	return u.String()
+}
+
+// do sends a request with the backend's credentials.
+func (w *WebDAVBackend) do(ctx context.Context, method, target string, body []byte, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if w.username != "" {
+		req.SetBasicAuth(w.username, w.password)
+	}
+	return w.client.Do(req)
+}
+
+// davMultistatus is the part of a PROPFIND response List reads.
+type davMultistatus struct {
+	Responses []struct {
+		Href     string `xml:"href"`
+		Propstat []struct {
+			Prop struct {
+				ETag         string `xml:"getetag"`
+				LastModified string `xml:"getlastmodified"`
+				Length       int64  `xml:"getcontentlength"`
+			} `xml:"prop"`
+			Status string `xml:"status"`
+		} `xml:"propstat"`
+	} `xml:"response"`
+}
+
+const davPropfind = `<?xml version="1.0" encoding="utf-8"?>
+<propfind xmlns="DAV:"><prop><getetag/><getlastmodified/><getcontentlength/></prop></propfind>`
+
+// List reads the project's collection with PROPFIND.
+func (w *WebDAVBackend) List(ctx context.Context, project string) ([]RemoteSession, error) {
+	resp, err := w.do(ctx, "PROPFIND", w.url(project), []byte(davPropfind),
+		http.Header{"Depth": {"1"}, "Content-Type": {"application/xml"}})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusMultiStatus {
+		return nil, fmt.Errorf("PROPFIND: %s", resp.Status)
+	}
+	var ms davMultistatus
+	if err := xml.NewDecoder(resp.Body).Decode(&ms); err != nil {
+		return nil, fmt.Errorf("parsing PROPFIND response: %w", err)
+	}
+	var out []RemoteSession
+	for _, r := range ms.Responses {
+		href, err := url.PathUnescape(r.Href)
+		if err != nil {
+			href = r.Href
+		}
+		name := path.Base(href)
+		if !strings.HasSuffix(name, bundleExt) {
+			continue
+		}
+		rs := RemoteSession{ID: strings.TrimSuffix(name, bundleExt)}
+		for _, ps := range r.Propstat {
+			if !strings.Contains(ps.Status, " 200 ") {
+				continue
+			}
+			rs.ETag = ps.Prop.ETag
+			rs.Size = ps.Prop.Length
+			rs.Updated, _ = http.ParseTime(ps.Prop.LastModified)
+		}
+		out = append(out, rs)
+	}
+	return out, nil
+}
+
+// Get downloads a bundle.
+func (w *WebDAVBackend) Get(ctx context.Context, project, id string) ([]byte, string, error) {
+	resp, err := w.do(ctx, http.MethodGet, w.url(project, id+bundleExt), nil, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("GET: %s", resp.Status)
+	}
+	data, err := readAllLimited(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, resp.Header.Get("ETag"), nil
+}
+
+// Put uploads a bundle with If-Match or If-None-Match, creating the
+// project's collection first if needed. Servers that ignore those headers
+// can't report a conflict.
+func (w *WebDAVBackend) Put(ctx context.Context, project, id string, data []byte, prev string) (string, error) {
+	header := http.Header{"Content-Type": {"application/gzip"}}
+	if prev == "" {
+		header.Set("If-None-Match", "*")
+	} else {
+		header.Set("If-Match", prev)
+	}
+	for attempt := 0; ; attempt++ {
+		resp, err := w.do(ctx, http.MethodPut, w.url(project, id+bundleExt), data, header)
+		if err != nil {
+			return "", err
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		switch {
+		case resp.StatusCode == http.StatusPreconditionFailed:
+			return "", ErrSyncConflict
+		case (resp.StatusCode == http.StatusConflict || resp.StatusCode == http.StatusNotFound) && attempt == 0:
+			// The collection doesn't exist yet. RFC 4918 says 409, but
+			// some servers answer 404.
+			if err := w.mkcol(ctx, project); err != nil {
+				return "", err
+			}
+			continue
+		case resp.StatusCode >= 300:
+			return "", fmt.Errorf("PUT: %s", resp.Status)
+		}
+		return resp.Header.Get("ETag"), nil
+	}
+}
+
+// mkcol creates the base collection, if needed, and the project's.
+func (w *WebDAVBackend) mkcol(ctx context.Context, project string) error {
+	for _, target := range []string{w.base.String(), w.url(project)} {
+		resp, err := w.do(ctx, "MKCOL", target, nil, nil)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		// 405 Method Not Allowed means the collection exists.
+		if resp.StatusCode >= 300 && resp.StatusCode != http.StatusMethodNotAllowed {
+			return fmt.Errorf("MKCOL: %s", resp.Status)
+		}
+	}
+	return nil
+}
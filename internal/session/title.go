@@ -0,0 +1,220 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/anthropics/claude-code-go/internal/api"
+)
+
+// titleModel is the small model used to title sessions.
+const titleModel = api.ModelClaude45Haiku
+
+// titleTimeout bounds a title request, which runs in the background.
+const titleTimeout = 30 * time.Second
+
+// titleMaxChars caps a title, generated or derived from the first message.
+const titleMaxChars = 60
+
+// titleExchangeChars caps how much of each side of the first exchange is
+// sent to the title model.
+const titleExchangeChars = 2000
+
+const titlePrompt = `You write titles for coding assistant sessions. Given the first exchange of a session, reply with a title of 3-7 words that says what the user is working on, in sentence case, with no quotes or trailing punctuation. Reply with the title only.`
+
+// TitleFunc generates a title for a session from its first exchange.
+type TitleFunc func(ctx context.Context, msgs []api.Message) (string, error)
+
+// SetTitler makes Save title each untitled session in the background
+// once it has a reply, using fn. Without a titler, sessions are shown by
+// their first message.
+func (s *Store) SetTitler(fn TitleFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.titler = fn
+}
+
+// GenerateTitle asks the title model for a short title describing the
+// first exchange in msgs.
+func GenerateTitle(ctx context.Context, client *api.Client, msgs []api.Message) (string, error) {
+	var b strings.Builder
+	for _, msg := range msgs {
+		text := truncateRunes(MessageText(msg), titleExchangeChars)
+		if text == "" {
+			continue
+		}
+		label := "User"
+		if msg.Role == api.RoleAssistant {
+			label = "Assistant"
+		}
+		fmt.Fprintf(&b, "%s: %s\n\n", label, text)
+	}
+	if b.Len() == 0 {
+		return "", fmt.Errorf("no text to title")
+	}
+
+	req := &api.CreateMessageRequest{
+		Model:     titleModel,
+		MaxTokens: 50,
+		System:    []api.SystemBlock{{Type: "text", Text: titlePrompt}},
+		Messages:  []api.Message{api.NewTextMessage(api.RoleUser, b.String())},
+	}
+	resp, err := client.CreateMessage(ctx, req)
+	if err != nil {
+		return "", fmt.Errorf("generating title: %w", err)
+	}
+	for _, block := range resp.Content {
+		if block.Type == api.ContentTypeText {
+			if title := cleanTitle(block.Text); title != "" {
+				return title, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("empty title response")
+}
+
+// cleanTitle trims what models tend to wrap a title in: a "Title:"
+// label, quotes, trailing punctuation, and any lines after the first.
+func cleanTitle(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	if len(s) >= 6 && strings.EqualFold(s[:6], "title:") {
+		s = s[6:]
+	}
+	s = strings.Trim(strings.TrimSpace(s), "\"'`*")
+	s = strings.TrimRight(s, ".")
+	return truncateRunes(strings.TrimSpace(s), titleMaxChars)
+}
+
+// DisplayTitle returns the session's title, or for an untitled session
+// its first user message, cut to fit on one line.
+func (s *Session) DisplayTitle() string {
+	if s.Title != "" {
+		return s.Title
+	}
+	text := strings.Join(strings.Fields(FirstUserMessage(s)), " ")
+	return truncateRunes(text, titleMaxChars)
+}
+
+// FirstUserMessage extracts the text of the first user message in a
+// session.
+func FirstUserMessage(sess *Session) string {
+	for _, msg := range sess.Messages {
+		if msg.Role == api.RoleUser {
+			return MessageText(msg)
+		}
+	}
+	return ""
+}
+
+// messageText returns a message's text: its string content, or its first
+// text block.
+func MessageText(msg api.Message) string {
+	// Content can be a JSON string or []ContentBlock.
+	var text string
+	if err := json.Unmarshal(msg.Content, &text); err == nil {
+		return strings.TrimSpace(text)
+	}
+	var blocks []api.ContentBlock
+	if err := json.Unmarshal(msg.Content, &blocks); err == nil {
+		for _, b := range blocks {
+			if b.Type == api.ContentTypeText && b.Text != "" {
+				return strings.TrimSpace(b.Text)
+			}
+		}
+	}
+	return ""
+}
+
+// truncateRunes cuts s to at most n characters, ending in "..." when cut.
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return strings.TrimSpace(string(r[:n-3])) + "..."
+}
+
+// applyTitle fills in a title generated for an untitled session and, once
+// the session has its first reply, starts generating one if a titler is
+// set. Each session is titled at most once per store.
+func (s *Store) applyTitle(session *Session) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if session.Title == "" {
+		session.Title = s.titles[session.ID]
+	}
+	if session.Title != "" || s.titler == nil || s.titling[session.ID] {
+		return
+	}
+	exchange := firstExchange(session.Messages)
+	if exchange == nil {
+		return
+	}
+	if s.titling == nil {
+		s.titling = make(map[string]bool)
+	}
+	s.titling[session.ID] = true
+
+	fn := s.titler
+	s.titleWG.Add(1)
+	go func() {
+		defer s.titleWG.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), titleTimeout)
+		defer cancel()
+		title, err := fn(ctx, exchange)
+		if err != nil || title == "" {
+			return
+		}
+		s.mu.Lock()
+		if s.titles == nil {
+			s.titles = make(map[string]string)
+		}
+		s.titles[session.ID] = title
+		s.mu.Unlock()
+		s.writeTitle(session.ID, title)
+	}()
+}
+
+// firstExchange returns the messages up to and including the first
+// assistant reply, or nil if there is no reply yet.
+func firstExchange(msgs []api.Message) []api.Message {
+	for i, msg := range msgs {
+		if msg.Role == api.RoleAssistant {
+			return append([]api.Message(nil), msgs[:i+1]...)
+		}
+	}
+	return nil
+}
+
+// writeTitle records a generated title in the session's metadata file
+// right away, so it shows in listings before the next save. A title set
+// in the meantime is kept.
+func (s *Store) writeTitle(id, title string) {
+	s.metaMu.Lock()
+	defer s.metaMu.Unlock()
+	if s.db != nil {
+		s.writeTitleSQL(id, title)
+		return
+	}
+	path := filepath.Join(s.dir, id+".json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	var meta Session
+	if json.Unmarshal(data, &meta) != nil || meta.Title != "" {
+		return
+	}
+	meta.Title = title
+	if data, err = json.MarshalIndent(&meta, "", "  "); err == nil {
+		writeFileAtomic(path, data)
+	}
+}
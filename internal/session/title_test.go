@@ -0,0 +1,154 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/anthropics/claude-code-go/internal/api"
+)
+
+func TestStoreTitlesAfterFirstExchange(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStoreWithDir(dir)
+	var calls atomic.Int32
+	store.SetTitler(func(ctx context.Context, msgs []api.Message) (string, error) {
+		calls.Add(1)
+		if len(msgs) != 2 || msgs[1].Role != api.RoleAssistant {
+			return "", fmt.Errorf("got %d messages, want the first exchange", len(msgs))
+		}
+		return "Fix the flaky login test", nil
+	})
+
+	sess := &Session{ID: "titled", Messages: []api.Message{api.NewTextMessage(api.RoleUser, "the login test is flaky")}}
+	if err := store.Save(sess); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	store.titleWG.Wait()
+	if calls.Load() != 0 {
+		t.Fatal("titler called before the first reply")
+	}
+
+	sess.Messages = append(sess.Messages, api.NewTextMessage(api.RoleAssistant, "Let me look."))
+	if err := store.Save(sess); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	store.titleWG.Wait()
+
+	// The title is on disk before the next save.
+	loaded, err := NewStoreWithDir(dir).Load("titled")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if loaded.Title != "Fix the flaky login test" {
+		t.Errorf("saved title = %q", loaded.Title)
+	}
+
+	// Later saves keep it and don't ask again.
+	sess.Messages = append(sess.Messages, api.NewTextMessage(api.RoleUser, "thanks"))
+	if err := store.Save(sess); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	store.titleWG.Wait()
+	if sess.Title != "Fix the flaky login test" {
+		t.Errorf("session title after the next save = %q", sess.Title)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("titler called %d times, want 1", calls.Load())
+	}
+}
+
+func TestDisplayTitle(t *testing.T) {
+	long := strings.Repeat("word ", 20)
+	tests := []struct {
+		name string
+		sess *Session
+		want string
+	}{
+		{"title", &Session{Title: "Add retries", Messages: []api.Message{api.NewTextMessage(api.RoleUser, "hi")}}, "Add retries"},
+		{"first message", &Session{Messages: []api.Message{api.NewTextMessage(api.RoleUser, "fix\nthe  build")}}, "fix the build"},
+		{"long first message", &Session{Messages: []api.Message{api.NewTextMessage(api.RoleUser, long)}}, strings.TrimSpace(long[:57]) + "..."},
+		{"empty", &Session{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.sess.DisplayTitle(); got != tt.want {
+				t.Errorf("DisplayTitle() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCleanTitle(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Refactor the parser", "Refactor the parser"},
+		{"\"Refactor the parser.\"", "Refactor the parser"},
+		{"Title: Refactor the parser", "Refactor the parser"},
+		{"Refactor the parser\n\nThis session is about...", "Refactor the parser"},
+		{"  ", ""},
+	}
+	for _, tt := range tests {
+		if got := cleanTitle(tt.in); got != tt.want {
+			t.Errorf("cleanTitle(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFirstUserMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		sess *Session
+		want string
+	}{
+		{
+			name: "simple text message",
+			sess: &Session{
+				Messages: []api.Message{
+					api.NewTextMessage(api.RoleUser, "hello world"),
+				},
+			},
+			want: "hello world",
+		},
+		{
+			name: "assistant first then user",
+			sess: &Session{
+				Messages: []api.Message{
+					api.NewTextMessage(api.RoleAssistant, "I am Claude"),
+					api.NewTextMessage(api.RoleUser, "hi there"),
+				},
+			},
+			want: "hi there",
+		},
+		{
+			name: "empty session",
+			sess: &Session{
+				Messages: []api.Message{},
+			},
+			want: "",
+		},
+		{
+			name: "block message with text",
+			sess: &Session{
+				Messages: []api.Message{
+					api.NewBlockMessage(api.RoleUser, []api.ContentBlock{
+						{Type: api.ContentTypeText, Text: "block text"},
+					}),
+				},
+			},
+			want: "block text",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FirstUserMessage(tt.sess)
+			if got != tt.want {
+				t.Errorf("FirstUserMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
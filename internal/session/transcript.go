@@ -0,0 +1,266 @@
+package session
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/anthropics/claude-code-go/internal/api"
+)
+
+// Transcript entry types, matching the JS CLI's JSONL transcript format.
+// Tool calls and tool results are not separate entry types: like the JS CLI,
+// tool_use blocks live in "assistant" entries and tool_result blocks in
+// "user" entries.
+const (
+	EntryTypeUser      = "user"
+	EntryTypeAssistant = "assistant"
+	EntryTypeSummary   = "summary"
+)
+
+// TranscriptEntry is a single line of a JSONL session transcript.
+// The shape matches the JS CLI so external tools (and hooks reading
+// transcript_path) can consume either implementation's transcripts.
+type TranscriptEntry struct {
+	ParentUUID  *string      `json:"parentUuid"`
+	IsSidechain bool         `json:"isSidechain"`
+	UserType    string       `json:"userType,omitempty"`
+	CWD         string       `json:"cwd,omitempty"`
+	SessionID   string       `json:"sessionId,omitempty"`
+	Version     string       `json:"version,omitempty"`
+	Type        string       `json:"type"`
+	Message     *api.Message `json:"message,omitempty"`
+	UUID        string       `json:"uuid,omitempty"`
+	Timestamp   string       `json:"timestamp,omitempty"`
+
+	// Summary entry fields.
+	Summary  string `json:"summary,omitempty"`
+	LeafUUID string `json:"leafUuid,omitempty"`
+}
+
+// transcriptState tracks how much of a session has been written to its
+// transcript so that each save only appends new messages.
+type transcriptState struct {
+	written  int    // number of session messages already in the transcript
+	lastUUID string // UUID of the most recent message entry
+}
+
+// projectDirMaxLen matches the JS CLI's limit on sanitized directory names.
+const projectDirMaxLen = 200
+
+var nonAlphanumeric = regexp.MustCompile(`[^a-zA-Z0-9]`)
+
+// ProjectDirName converts a working directory into the directory name the
+// JS CLI uses under ~/.claude/projects/ (non-alphanumerics replaced by "-",
+// long paths truncated and suffixed with a hash).
+func ProjectDirName(cwd string) string {
+	name := nonAlphanumeric.ReplaceAllString(cwd, "-")
+	if len(name) <= projectDirMaxLen {
+		return name
+	}
+	// Same 32-bit string hash as the JS implementation.
+	var h int32
+	for _, c := range cwd {
+		h = (h << 5) - h + int32(c)
+	}
+	n := int64(h)
+	if n < 0 {
+		n = -n
+	}
+	return name[:projectDirMaxLen] + "-" + formatBase36(n)
+}
+
+// formatBase36 formats a non-negative integer in base 36.
+func formatBase36(n int64) string {
+	const digits = "0123456789abcdefghijklmnopqrstuvwxyz"
+	if n == 0 {
+		return "0"
+	}
+	var buf []byte
+	for n > 0 {
+		buf = append([]byte{digits[n%36]}, buf...)
+		n /= 36
+	}
+	return string(buf)
+}
+
+// SetTranscriptDir enables JSONL transcripts, written to <dir>/<id>.jsonl.
+// An empty dir disables transcripts.
+func (s *Store) SetTranscriptDir(dir string) {
+	s.transcriptDir = dir
+}
+
+// SetVersion sets the CLI version recorded in transcript entries.
+func (s *Store) SetVersion(version string) {
+	s.version = version
+}
+
+// TranscriptPath returns the JSONL transcript path for a session, or ""
+// if transcripts are disabled. They are with encryption on, since the
+// official format has no place for it.
+func (s *Store) TranscriptPath(id string) string {
+	if s.transcriptDir == "" || Encrypted() {
+		return ""
+	}
+	return filepath.Join(s.transcriptDir, id+".jsonl")
+}
+
+// appendTranscript writes any messages not yet in the session's transcript.
+// If the message list shrank since the last write (compaction), a summary
+// entry is recorded and the compacted history starts a new message chain.
+func (s *Store) appendTranscript(session *Session) error {
+	path := s.TranscriptPath(session.ID)
+	if path == "" {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.transcripts == nil {
+		s.transcripts = make(map[string]*transcriptState)
+	}
+	state, ok := s.transcripts[session.ID]
+	if !ok {
+		state = scanTranscript(path)
+		s.transcripts[session.ID] = state
+	}
+
+	var entries []TranscriptEntry
+	now := transcriptTimestamp(time.Now())
+
+	if len(session.Messages) < state.written {
+		entries = append(entries, TranscriptEntry{
+			Type:     EntryTypeSummary,
+			Summary:  compactionSummary(session.Messages),
+			LeafUUID: state.lastUUID,
+		})
+		state.written = 0
+		state.lastUUID = ""
+	}
+
+	for _, msg := range session.Messages[state.written:] {
+		entry := TranscriptEntry{
+			UserType:  "external",
+			CWD:       session.CWD,
+			SessionID: session.ID,
+			Version:   s.version,
+			Type:      msg.Role,
+			Message:   &msg,
+			UUID:      newUUID(),
+			Timestamp: now,
+		}
+		if state.lastUUID != "" {
+			parent := state.lastUUID
+			entry.ParentUUID = &parent
+		}
+		entries = append(entries, entry)
+		state.lastUUID = entry.UUID
+	}
+	state.written = len(session.Messages)
+
+	if len(entries) == 0 {
+		return nil
+	}
+
+	if err := os.MkdirAll(s.transcriptDir, 0700); err != nil {
+		return fmt.Errorf("creating transcript directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("opening transcript: %w", err)
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	for _, e := range entries {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("marshaling transcript entry: %w", err)
+		}
+		w.Write(line)
+		w.WriteByte('\n')
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("writing transcript: %w", err)
+	}
+	return nil
+}
+
+// ReadTranscript parses a JSONL transcript file. Malformed lines are skipped.
+func ReadTranscript(path string) ([]TranscriptEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []TranscriptEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var e TranscriptEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return entries, fmt.Errorf("reading transcript: %w", err)
+	}
+	return entries, nil
+}
+
+// scanTranscript reconstructs the write state of an existing transcript
+// (e.g. after resuming a session in a new process).
+func scanTranscript(path string) *transcriptState {
+	state := &transcriptState{}
+	entries, err := ReadTranscript(path)
+	if err != nil {
+		return state
+	}
+	for _, e := range entries {
+		switch e.Type {
+		case EntryTypeUser, EntryTypeAssistant:
+			state.written++
+			state.lastUUID = e.UUID
+		case EntryTypeSummary:
+			// Compaction restarts the chain with the compacted history.
+			state.written = 0
+			state.lastUUID = ""
+		}
+	}
+	return state
+}
+
+// compactionSummary extracts the summary text from a compacted message list,
+// whose first message holds the summary.
+func compactionSummary(msgs []api.Message) string {
+	if len(msgs) == 0 {
+		return ""
+	}
+	var text string
+	if err := json.Unmarshal(msgs[0].Content, &text); err == nil {
+		return text
+	}
+	return ""
+}
+
+// transcriptTimestamp formats a time like JavaScript's Date.toISOString().
+func transcriptTimestamp(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000Z")
+}
+
+// newUUID returns a random RFC 4122 version 4 UUID.
+func newUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,147 @@
+package session
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Usage is a session's cumulative token use and cost, sub-agent runs
+// included. Save records it in the metadata so listings and other tools
+// can show it without adding up the turns.
+type Usage struct {
+	InputTokens      int     `json:"input_tokens"`
+	OutputTokens     int     `json:"output_tokens"`
+	CacheReadTokens  int     `json:"cache_read_tokens,omitempty"`
+	CacheWriteTokens int     `json:"cache_write_tokens,omitempty"`
+	CostUSD          float64 `json:"cost_usd"`
+
+	// Models maps each model used to its total tokens.
+	Models map[string]int `json:"models,omitempty"`
+}
+
+// TotalTokens returns all tokens, including cache reads and writes.
+func (u Usage) TotalTokens() int {
+	return u.InputTokens + u.OutputTokens + u.CacheReadTokens + u.CacheWriteTokens
+}
+
+// ModelMix describes the models used by their share of the tokens, most
+// used first: "claude-sonnet-4-6 92%, claude-haiku-4-5 8%". A single
+// model is shown alone.
+func (u Usage) ModelMix() string {
+	models := make([]string, 0, len(u.Models))
+	total := 0
+	for m, n := range u.Models {
+		models = append(models, m)
+		total += n
+	}
+	sort.Slice(models, func(i, j int) bool {
+		if u.Models[models[i]] != u.Models[models[j]] {
+			return u.Models[models[i]] > u.Models[models[j]]
+		}
+		return models[i] < models[j]
+	})
+	if len(models) == 1 || total == 0 {
+		return strings.Join(models, ", ")
+	}
+	parts := make([]string, len(models))
+	for i, m := range models {
+		parts[i] = fmt.Sprintf("%s %.0f%%", m, 100*float64(u.Models[m])/float64(total))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// String summarizes the usage as "$0.4210, 152.3k tokens, <model mix>",
+// or "" for a session that hasn't used any.
+func (u Usage) String() string {
+	if u.TotalTokens() == 0 {
+		return ""
+	}
+	parts := []string{fmt.Sprintf("$%.4f", u.CostUSD), formatTokens(u.TotalTokens()) + " tokens"}
+	if mix := u.ModelMix(); mix != "" {
+		parts = append(parts, mix)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// CostSummary describes what the session cost, as /cost does, for
+// exports. It is "" for a session that hasn't used any tokens.
+func (s *Session) CostSummary() string {
+	u := s.TotalUsage()
+	if u.TotalTokens() == 0 {
+		return ""
+	}
+	summary := fmt.Sprintf(`Token Usage:
+  Input tokens:  %d
+  Output tokens: %d
+  Cache read:    %d
+  Cache write:   %d
+  API turns:     %d
+  Total cost:    $%.4f`,
+		u.InputTokens, u.OutputTokens, u.CacheReadTokens, u.CacheWriteTokens, len(s.Turns), u.CostUSD)
+	if mix := u.ModelMix(); mix != "" {
+		summary += "\n  Models:        " + mix
+	}
+	if n := len(s.AgentCosts); n > 0 {
+		summary += fmt.Sprintf("\n  Agent runs:    %d", n)
+	}
+	return summary
+}
+
+// formatTokens abbreviates a token count: 950, 12.4k, 1.2M.
+func formatTokens(n int) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1_000_000)
+	case n >= 1000:
+		return fmt.Sprintf("%.1fk", float64(n)/1000)
+	}
+	return fmt.Sprintf("%d", n)
+}
+
+// TotalUsage returns the session's usage: the rollup saved with it, or
+// for sessions saved before rollups were, one added up from its turns.
+func (s *Session) TotalUsage() Usage {
+	if s.Usage != nil {
+		return *s.Usage
+	}
+	return s.rollupUsage()
+}
+
+// rollupUsage adds up the session's turns, each priced at its own model,
+// and its sub-agent runs.
+func (s *Session) rollupUsage() Usage {
+	u := Usage{Models: make(map[string]int)}
+	for _, t := range s.Turns {
+		u.InputTokens += t.Usage.InputTokens
+		u.OutputTokens += t.Usage.OutputTokens
+		if t.Usage.CacheReadInputTokens != nil {
+			u.CacheReadTokens += *t.Usage.CacheReadInputTokens
+		}
+		if t.Usage.CacheCreationInputTokens != nil {
+			u.CacheWriteTokens += *t.Usage.CacheCreationInputTokens
+		}
+		u.CostUSD += t.Usage.Cost(t.Model)
+		if t.Model != "" {
+			u.Models[t.Model] += t.Usage.TotalTokens()
+		}
+	}
+	for _, c := range s.AgentCosts {
+		u.InputTokens += c.Usage.InputTokens
+		u.OutputTokens += c.Usage.OutputTokens
+		if c.Usage.CacheReadInputTokens != nil {
+			u.CacheReadTokens += *c.Usage.CacheReadInputTokens
+		}
+		if c.Usage.CacheCreationInputTokens != nil {
+			u.CacheWriteTokens += *c.Usage.CacheCreationInputTokens
+		}
+		u.CostUSD += c.CostUSD
+		if c.Model != "" {
+			u.Models[c.Model] += c.Tokens()
+		}
+	}
+	if len(u.Models) == 0 {
+		u.Models = nil
+	}
+	return u
+}
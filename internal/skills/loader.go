@@ -132,6 +132,8 @@ func parseSkill(content, filePath string) Skill {
 			s.Description = value
 		case "trigger":
 			s.Trigger = value
+		case "disable-model-invocation":
+			s.DisableModelInvocation = value == "true"
 		}
 	}
 
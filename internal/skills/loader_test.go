@@ -209,3 +209,19 @@ func containsStr(s, substr string) bool {
 	}
 	return false
 }
+
+func TestParseSkill_DisableModelInvocation(t *testing.T) {
+	content := `---
+name: release
+disable-model-invocation: true
+---
+Cut a release.`
+
+	skill := parseSkill(content, "test.md")
+	if !skill.DisableModelInvocation {
+		t.Error("expected DisableModelInvocation to be set")
+	}
+	if skill := parseSkill("---\nname: x\n---\nbody", "test.md"); skill.DisableModelInvocation {
+		t.Error("DisableModelInvocation should default to false")
+	}
+}
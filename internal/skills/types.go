@@ -8,9 +8,13 @@ package skills
 
 // Skill represents a loaded skill definition.
 type Skill struct {
-	Name        string // skill name from frontmatter
-	Description string // short description from frontmatter
-	Trigger     string // slash command trigger, e.g. "/commit"
-	Content     string // markdown body (instructions/prompt)
-	FilePath    string // source file path for debugging
+	Name        string `json:"name"`                  // skill name from frontmatter
+	Description string `json:"description,omitempty"` // short description from frontmatter
+	Trigger     string `json:"trigger,omitempty"`     // slash command trigger, e.g. "/commit"
+	Content     string `json:"content"`               // markdown body (instructions/prompt)
+	FilePath    string `json:"file_path,omitempty"`   // source file path for debugging
+
+	// DisableModelInvocation hides the skill from the Skill tool, so only
+	// the user can run it ("disable-model-invocation: true").
+	DisableModelInvocation bool `json:"disable_model_invocation,omitempty"`
 }
@@ -4,9 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/anthropics/claude-code-go/internal/agents"
 	"github.com/anthropics/claude-code-go/internal/api"
 	"github.com/anthropics/claude-code-go/internal/conversation"
 )
@@ -25,31 +30,57 @@ type AgentInput struct {
 	Isolation       *string `json:"isolation,omitempty"`
 }
 
+// defaultAgentParallelism is how many Agent calls from one turn run at
+// once; settings can change it with toolLimits.Agent.maxConcurrent.
+const defaultAgentParallelism = 4
+
 // agentState tracks a running or completed sub-agent.
 type agentState struct {
-	id      string
-	loop    *conversation.Loop
-	history *conversation.History
-	done    chan struct{}
-	result  string
-	err     error
-	usage   api.Usage
-	turns   int
-	startMs int64
+	id       string
+	loop     *conversation.Loop
+	history  *conversation.History
+	progress *agentProgressHandler
+	done     chan struct{}
+	result   string
+	err      error
+	cost     AgentCost       // what the latest run consumed
+	task     *BackgroundTask // set once the agent has run in the background
+	chain    []agentFrame    // the agents the latest run is nested in, ending with this one
+
+	cacheState  string // repository state the first run is cached under; "" if not cached
+	cachePrompt string // the first run's prompt
+
+	runStart  int       // index of the latest run's first turn
+	startedAt time.Time // when the latest run started
+
+	description  string
+	subagentType string
+	outputDir    string // where the final report is saved, if anywhere
+	outputFile   string // the saved report, once written
+	outputErr    error
 }
 
 // AgentTool spawns sub-agents with isolated conversation loops.
 type AgentTool struct {
 	client   *api.Client
 	system   []api.SystemBlock
-	tools    []api.ToolDefinition
 	toolExec conversation.ToolExecutor
 	bgStore  *BackgroundTaskStore
 	hooks    conversation.HookRunner // Phase 7: propagated to sub-agents
+	spiller  *conversation.ResultSpiller
+	custom   []agents.Agent // custom agent definitions, by subagent_type
+	maxDepth int            // how deeply agents may nest
+
+	repoState func() string // fingerprints the working tree for the result cache
+
+	toolsMu sync.Mutex
+	tools   []api.ToolDefinition // replaced by SetTools as MCP tools change
 
 	mu     sync.Mutex
 	agents map[string]*agentState
 	nextID int
+	costs  []AgentCost // one entry per finished run, oldest first
+	cache  []cachedRun // completed runs of agents with CacheResults, oldest first
 }
 
 // NewAgentTool creates a new Agent tool.
@@ -68,14 +99,53 @@ func NewAgentTool(
 		toolExec: toolExec,
 		bgStore:  bgStore,
 		hooks:    hooks,
+		maxDepth: DefaultMaxAgentDepth,
 		agents:   make(map[string]*agentState),
 	}
 }
 
+// SetResultSpiller saves sub-agents' oversized tool results to files, as
+// for the main conversation.
+func (t *AgentTool) SetResultSpiller(s *conversation.ResultSpiller) {
+	t.spiller = s
+}
+
+// SetTools replaces the tool definitions offered to sub-agents started
+// afterwards, for example when an MCP server's tools change. The Agent
+// tool's own definition is left out; it is added by nesting depth.
+func (t *AgentTool) SetTools(defs []api.ToolDefinition) {
+	defs = slices.DeleteFunc(slices.Clone(defs), func(d api.ToolDefinition) bool { return d.Name == t.Name() })
+	t.toolsMu.Lock()
+	defer t.toolsMu.Unlock()
+	t.tools = defs
+}
+
+// SetCustomAgents makes custom agent definitions available as
+// subagent_type values.
+func (t *AgentTool) SetCustomAgents(defs []agents.Agent) {
+	t.custom = defs
+}
+
 func (t *AgentTool) Name() string { return "Agent" }
 
 func (t *AgentTool) Description() string {
-	return `Launch a new agent to handle complex, multi-step tasks autonomously. The agent gets its own isolated conversation context and can use all available tools. Use the description parameter for a short summary and prompt for the full task description. Supports background execution and resuming previous agents.`
+	desc := `Launch a new agent to handle complex, multi-step tasks autonomously. The agent gets its own isolated conversation context and can use all available tools. Use the description parameter for a short summary and prompt for the full task description. Supports background execution and resuming previous agents. To work on several independent tasks at once, make multiple Agent calls in one message; they run in parallel.`
+	if len(t.custom) == 0 {
+		return desc
+	}
+	var b strings.Builder
+	b.WriteString(desc)
+	b.WriteString("\n\nAvailable agent types (subagent_type):\n- general-purpose: General-purpose agent with all tools.")
+	for _, a := range t.custom {
+		b.WriteString("\n- " + a.Name)
+		if a.Description != "" {
+			b.WriteString(": " + a.Description)
+		}
+		if a.Tools != nil {
+			b.WriteString(" (Tools: " + strings.Join(a.Tools, ", ") + ")")
+		}
+	}
+	return b.String()
 }
 
 func (t *AgentTool) InputSchema() json.RawMessage {
@@ -136,6 +206,9 @@ func (t *AgentTool) RequiresPermission(_ json.RawMessage) bool {
 	return false // sub-agents inherit the parent's permission handler
 }
 
+// MaxParallel lets several Agent calls from one turn run concurrently.
+func (t *AgentTool) MaxParallel() int { return defaultAgentParallelism }
+
 func (t *AgentTool) Execute(ctx context.Context, input json.RawMessage) (string, error) {
 	var in AgentInput
 	if err := json.Unmarshal(input, &in); err != nil {
@@ -145,114 +218,320 @@ func (t *AgentTool) Execute(ctx context.Context, input json.RawMessage) (string,
 	if in.Prompt == "" {
 		return "Error: prompt is required", nil
 	}
+	background := in.RunInBackground != nil && *in.RunInBackground
+
+	// Refuse to nest too deeply or to hand a task back down the chain it
+	// came from; either would burn tokens without making progress.
+	chain := agentChain(ctx)
+	if msg := t.checkDelegation(chain, in.Prompt); msg != "" {
+		return msg, nil
+	}
 
 	// Handle resume.
 	if in.Resume != nil && *in.Resume != "" {
-		return t.resumeAgent(ctx, *in.Resume, in.Prompt)
+		return t.resumeAgent(ctx, *in.Resume, in.Prompt, background)
 	}
 
-	// Generate a unique agent ID.
-	agentID := t.generateID()
-	startMs := time.Now().UnixMilli()
-
-	// Create an isolated conversation loop for the sub-agent.
-	history := conversation.NewHistory()
-	handler := &conversation.PrintStreamHandler{}
-
-	loopCfg := conversation.LoopConfig{
-		Client:   t.client,
-		System:   t.system,
-		Tools:    t.tools,
-		ToolExec: t.toolExec,
-		Handler:  handler,
-		History:  history,
-		Hooks:    t.hooks, // Phase 7: propagate hooks to sub-agents
+	// Answer a repeated question from the cache rather than searching
+	// the repository again.
+	var cacheState string
+	if !background {
+		cacheState = t.cacheKey(in.SubagentType)
+		if out := t.cachedResult(in.SubagentType, in.Prompt, cacheState); out != "" {
+			return out, nil
+		}
 	}
-	agentLoop := conversation.NewLoop(loopCfg)
 
-	state := &agentState{
-		id:      agentID,
-		loop:    agentLoop,
-		history: history,
-		done:    make(chan struct{}),
-		startMs: startMs,
+	// Create an isolated conversation loop for the sub-agent. Its output
+	// is reported as progress under this call rather than printed;
+	// background agents report nothing.
+	agentID := t.generateID()
+	if err := t.runStartHook(ctx, agentID, in.Description, in.SubagentType, in.Prompt, background); err != nil {
+		return "Error: " + err.Error(), nil
 	}
+	var sink func(conversation.AgentProgress)
+	if !background {
+		sink = conversation.AgentProgressSink(ctx)
+	}
+	state := t.newAgent(agentID, in.Description, in.SubagentType, in.Model, in.MaxTurns, len(chain)+1, conversation.NewHistory(), sink)
+	state.chain = append(slices.Clip(chain), agentFrame{agentID, in.SubagentType, in.Prompt})
+	state.cacheState, state.cachePrompt = cacheState, in.Prompt
 
 	t.mu.Lock()
 	t.agents[agentID] = state
 	t.mu.Unlock()
 
-	// Background execution.
-	if in.RunInBackground != nil && *in.RunInBackground {
-		bgCtx, bgCancel := context.WithCancel(context.Background())
+	if background {
+		return t.startBackground(state, in.Prompt), nil
+	}
+
+	// Synchronous execution.
+	state.beginRun()
+	err := t.runAgent(ctx, state, in.Prompt)
+	t.finishRun(state, err)
+	state.progress.finish(state.cost)
+	close(state.done)
+	return t.completedResult(ctx, state), nil
+}
 
-		bgTask := &BackgroundTask{
-			ID:     agentID,
-			Ctx:    bgCtx,
-			Cancel: bgCancel,
-			Done:   state.done,
+// newAgent builds a sub-agent of the given type, nested depth levels
+// below the main conversation, that continues history. Its output is
+// reported as progress to sink rather than printed.
+func (t *AgentTool) newAgent(id, description, subagentType string, modelOverride *string, maxTurnsOverride *int, depth int, history *conversation.History, sink func(conversation.AgentProgress)) *agentState {
+	handler := newAgentProgressHandler(sink, id, description)
+
+	t.toolsMu.Lock()
+	toolDefs := t.tools
+	t.toolsMu.Unlock()
+	client, system, toolExec := t.client, t.system, t.toolExec
+	if depth < t.maxDepth && toolExec != nil && toolExec.HasTool(t.Name()) {
+		// Agents above the depth limit may delegate in turn.
+		toolDefs = append(slices.Clip(toolDefs), api.ToolDefinition{Name: t.Name(), Description: t.Description(), InputSchema: t.InputSchema()})
+	}
+	model, maxTurns, maxTokens, outputDir := "", 0, 0, ""
+	if def := t.customAgent(subagentType); def != nil {
+		system = []api.SystemBlock{{Type: "text", Text: def.SystemPrompt}}
+		if def.Tools != nil {
+			toolDefs, toolExec = restrictTools(toolDefs, toolExec, def.Tools)
+		}
+		model, maxTurns, maxTokens = def.Model, max(def.MaxTurns, 0), max(def.MaxTokens, 0)
+		outputDir = def.OutputDir
+	}
+	if modelOverride != nil && *modelOverride != "" {
+		model = *modelOverride
+	}
+	// max_turns can tighten an agent's turn limit but not lift it.
+	if maxTurnsOverride != nil && *maxTurnsOverride > 0 && (maxTurns == 0 || *maxTurnsOverride < maxTurns) {
+		maxTurns = *maxTurnsOverride
+	}
+	if id := agents.ResolveModel(model); (id != "" || maxTokens > 0) && client != nil {
+		client = client.Clone()
+		if id != "" {
+			client.SetModel(id)
 		}
-		t.bgStore.Add(bgTask)
-
-		go func() {
-			defer close(state.done)
-			err := t.runAgent(bgCtx, state, in.Prompt, in.MaxTurns)
-			state.err = err
-			state.result = t.extractResult(state)
-
-			bgTask.Result = state.result
-			bgTask.Err = err
-		}()
-
-		result := map[string]interface{}{
-			"status":  "async_launched",
-			"agentId": agentID,
-			"message": fmt.Sprintf("Agent %s launched in background", agentID),
+		if maxTokens > 0 {
+			client.SetMaxTokens(maxTokens)
 		}
-		out, _ := json.Marshal(result)
-		return string(out), nil
 	}
 
-	// Synchronous execution.
-	err := t.runAgent(ctx, state, in.Prompt, in.MaxTurns)
-	close(state.done)
-	if err != nil {
-		state.err = err
+	state := &agentState{
+		id:           id,
+		history:      history,
+		progress:     handler,
+		done:         make(chan struct{}),
+		description:  description,
+		subagentType: subagentType,
+		outputDir:    outputDir,
+	}
+	state.loop = conversation.NewLoop(conversation.LoopConfig{
+		Client:   client,
+		System:   system,
+		Tools:    toolDefs,
+		ToolExec: toolExec,
+		Handler:  handler,
+		History:  history,
+		Hooks:    t.hooks, // Phase 7: propagate hooks to sub-agents
+		Spiller:  t.spiller,
+		OnTurnComplete: func(*conversation.History) {
+			// Keep a background agent's saved conversation current, so
+			// it can be resumed if the process exits mid-run.
+			if state.task != nil {
+				_ = t.bgStore.Save(state.task)
+			}
+		},
+	})
+	state.loop.SetMaxTurns(maxTurns)
+	return state
+}
+
+// startBackground runs the agent's next prompt detached from the current
+// turn, as a background task that TaskOutput reads and /tasks lists. The
+// task's record, including the agent's conversation, is saved with the
+// session so the agent can be read or resumed after a restart.
+func (t *AgentTool) startBackground(state *agentState, prompt string) string {
+	ctx, cancel := context.WithCancel(context.Background())
+	task := t.agentTask(state)
+	task.Ctx, task.Cancel = ctx, cancel
+	task.Steer = state.loop.Steer
+	state.task = task
+	state.progress.start(nil)
+	state.beginRun()
+	t.bgStore.Add(task)
+
+	go func() {
+		defer cancel()
+		err := t.runAgent(ctx, state, prompt)
+		t.finishRun(state, err)
+		task.OutputFile = state.outputFile
+		t.bgStore.Finish(task, state.result, state.err)
+	}()
+
+	result := map[string]interface{}{
+		"status":  "async_launched",
+		"agentId": state.id,
+		"message": fmt.Sprintf("Agent %s launched in background. Read its result with TaskOutput; if it is interrupted, continue it with resume.", state.id),
 	}
+	out, _ := json.Marshal(result)
+	return string(out)
+}
+
+// agentTask creates the background task tracking the agent's current run.
+func (t *AgentTool) agentTask(state *agentState) *BackgroundTask {
+	return &BackgroundTask{
+		ID:          state.id,
+		Kind:        "agent",
+		Description: state.description,
+		Done:        state.done,
+		OutputFile:  state.outputFile,
+		Snapshot: func(rec *TaskRecord) {
+			rec.SubagentType = state.subagentType
+			rec.Messages = state.history.Messages()
+		},
+	}
+}
+
+// beginRun marks the start of a run, so its usage can be told apart from
+// earlier runs of a resumed agent.
+func (s *agentState) beginRun() {
+	s.runStart = len(s.history.Turns())
+	s.startedAt = time.Now()
+}
+
+// finishRun records the outcome and cost of one run of the agent.
+func (t *AgentTool) finishRun(state *agentState, err error) {
+	state.err = err
 	state.result = t.extractResult(state)
+	state.cost = t.recordCost(state)
+	state.saveOutput()
+
+	if t.hooks != nil {
+		// The run's context may be cancelled; the hooks should still run.
+		_ = t.hooks.RunSubagentStop(context.Background(), conversation.SubagentEvent{
+			AgentID:     state.id,
+			AgentType:   state.subagentType,
+			Description: state.description,
+			Status:      finishedStatus(err),
+			ResultChars: len(state.result),
+			ToolUses:    state.cost.ToolUses,
+			Tokens:      state.cost.Tokens(),
+			CostUSD:     state.cost.CostUSD,
+			DurationMs:  state.cost.DurationMs,
+		})
+	}
+}
 
-	durationMs := time.Now().UnixMilli() - startMs
+// runStartHook fires the SubagentStart hooks before a run. An error means
+// a hook blocked the run.
+func (t *AgentTool) runStartHook(ctx context.Context, id, description, subagentType, prompt string, background bool) error {
+	if t.hooks == nil {
+		return nil
+	}
+	return t.hooks.RunSubagentStart(ctx, conversation.SubagentEvent{
+		AgentID:     id,
+		AgentType:   subagentType,
+		Description: description,
+		Prompt:      prompt,
+		Background:  background,
+	})
+}
+
+// completedResult formats the Agent tool result for a finished run. Usage
+// and cost cover this run only.
+func (t *AgentTool) completedResult(ctx context.Context, state *agentState) string {
+	content, summarized := t.summarizeAgentResult(ctx, state.id, state.result)
+	t.cacheRun(state, content, summarized)
 
 	result := map[string]interface{}{
 		"status":            "completed",
-		"agentId":           agentID,
-		"content":           state.result,
-		"totalToolUseCount": state.turns,
-		"totalDurationMs":   durationMs,
-		"usage":             state.usage,
+		"agentId":           state.id,
+		"content":           content,
+		"totalToolUseCount": state.cost.ToolUses,
+		"totalDurationMs":   state.cost.DurationMs,
+		"usage":             state.cost.Usage,
+		"costUSD":           state.cost.CostUSD,
+	}
+	if summarized {
+		result["summarized"] = true
+		result["fullOutputChars"] = len(state.result)
+	}
+	if state.outputFile != "" {
+		result["outputFile"] = state.outputFile
+	} else if state.outputErr != nil {
+		result["outputFileError"] = state.outputErr.Error()
 	}
 	out, _ := json.Marshal(result)
-	return string(out), nil
+	return string(out)
+}
+
+// customAgent returns the custom agent named name, or nil.
+func (t *AgentTool) customAgent(name string) *agents.Agent {
+	for i := range t.custom {
+		if t.custom[i].Name == name {
+			return &t.custom[i]
+		}
+	}
+	return nil
+}
+
+// restrictedExecutor hides tools a custom agent isn't allowed to use.
+type restrictedExecutor struct {
+	conversation.ToolExecutor
+	allowed []string
+}
+
+func (e *restrictedExecutor) HasTool(name string) bool {
+	return slices.Contains(e.allowed, name) && e.ToolExecutor.HasTool(name)
+}
+
+func (e *restrictedExecutor) Execute(ctx context.Context, name string, input []byte) (string, error) {
+	if !slices.Contains(e.allowed, name) {
+		return "", fmt.Errorf("tool %s is not available to this agent", name)
+	}
+	return e.ToolExecutor.Execute(ctx, name, input)
+}
+
+// restrictTools limits a sub-agent to the allowed tools.
+func restrictTools(defs []api.ToolDefinition, exec conversation.ToolExecutor, names []string) ([]api.ToolDefinition, conversation.ToolExecutor) {
+	allowed := make([]string, len(names))
+	for i, name := range names {
+		allowed[i] = agents.ToolName(name)
+	}
+	var kept []api.ToolDefinition
+	for _, d := range defs {
+		if slices.Contains(allowed, d.Name) {
+			kept = append(kept, d)
+		}
+	}
+	if exec != nil {
+		exec = &restrictedExecutor{ToolExecutor: exec, allowed: allowed}
+	}
+	return kept, exec
 }
 
-// runAgent sends a message to the sub-agent loop with optional turn limit.
-func (t *AgentTool) runAgent(ctx context.Context, state *agentState, prompt string, maxTurns *int) error {
-	// For now, use the standard SendMessage which runs the full agentic loop.
-	// A max_turns limit would require modifying the loop, so we just run it to completion.
-	err := state.loop.SendMessage(ctx, prompt)
+// runAgent sends a message to the sub-agent loop, which stops at the
+// agent's turn limit if it has one.
+func (t *AgentTool) runAgent(ctx context.Context, state *agentState, prompt string) error {
+	err := state.loop.SendMessage(withAgentChain(ctx, state.chain), prompt)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-// resumeAgent continues a previous agent with a new prompt.
-func (t *AgentTool) resumeAgent(ctx context.Context, agentID string, prompt string) (string, error) {
+// resumeAgent continues a previous agent with a new prompt. Agents from
+// an earlier run of the session are rebuilt from their saved records.
+func (t *AgentTool) resumeAgent(ctx context.Context, agentID string, prompt string, background bool) (string, error) {
+	chain := agentChain(ctx)
 	t.mu.Lock()
 	state, ok := t.agents[agentID]
+	if !ok {
+		if state = t.restoreAgent(agentID, len(chain)+1); state != nil {
+			t.agents[agentID] = state
+		}
+	}
 	t.mu.Unlock()
 
-	if !ok {
+	if state == nil {
 		return fmt.Sprintf("Error: agent %s not found", agentID), nil
 	}
 
@@ -262,30 +541,107 @@ func (t *AgentTool) resumeAgent(ctx context.Context, agentID string, prompt stri
 	default:
 		return fmt.Sprintf("Error: agent %s is still running", agentID), nil
 	}
+	if err := t.runStartHook(ctx, agentID, state.description, state.subagentType, prompt, background); err != nil {
+		return "Error: " + err.Error(), nil
+	}
 
 	// Reset done channel for new run.
 	state.done = make(chan struct{})
-	startMs := time.Now().UnixMilli()
+	state.chain = append(slices.Clip(chain), agentFrame{agentID, state.subagentType, prompt})
+	state.cacheState = "" // a resumed run answers a follow-up, not the cached prompt
+	if background {
+		return t.startBackground(state, prompt), nil
+	}
+	state.progress.start(conversation.AgentProgressSink(ctx))
+	state.beginRun()
+	err := t.runAgent(ctx, state, prompt)
+	t.finishRun(state, err)
+	state.progress.finish(state.cost)
+	close(state.done)
+	if state.task != nil {
+		// The agent has a saved record; bring it up to date.
+		task := t.agentTask(state)
+		task.Result, task.Err = state.result, state.err
+		state.task = task
+		t.bgStore.Add(task)
+	}
+	return t.completedResult(ctx, state), nil
+}
 
-	err := state.loop.SendMessage(ctx, prompt)
+// restoreAgent rebuilds a background agent saved by an earlier run of the
+// session, nested depth levels deep, or returns nil if there is none.
+func (t *AgentTool) restoreAgent(agentID string, depth int) *agentState {
+	task, ok := t.bgStore.Get(agentID)
+	if !ok || task.Record == nil || task.Record.Kind != "agent" {
+		return nil
+	}
+	rec := task.Record
+	history := conversation.NewHistoryFrom(completeExchanges(rec.Messages))
+	state := t.newAgent(rec.ID, rec.Description, rec.SubagentType, nil, nil, depth, history, nil)
+	state.result, state.outputFile = rec.Result, rec.OutputFile
+	state.task = task
 	close(state.done)
-	if err != nil {
-		state.err = err
+	return state
+}
+
+// completeExchanges drops a trailing assistant message whose tool calls
+// never got results, as when the process exited while they ran.
+func completeExchanges(msgs []api.Message) []api.Message {
+	if len(msgs) == 0 || msgs[len(msgs)-1].Role != api.RoleAssistant {
+		return msgs
 	}
-	state.result = t.extractResult(state)
+	var blocks []api.ContentBlock
+	if json.Unmarshal(msgs[len(msgs)-1].Content, &blocks) == nil {
+		for _, b := range blocks {
+			if b.Type == api.ContentTypeToolUse {
+				return msgs[:len(msgs)-1]
+			}
+		}
+	}
+	return msgs
+}
 
-	durationMs := time.Now().UnixMilli() - startMs
+// saveOutput writes the agent's final report to its output directory, if
+// it has one. A resumed agent overwrites the file from its earlier run.
+func (s *agentState) saveOutput() {
+	if s.outputDir == "" || strings.TrimSpace(s.result) == "" {
+		return
+	}
+	path := s.outputFile
+	if path == "" {
+		path = filepath.Join(s.outputDir, time.Now().Format("2006-01-02-150405")+"-"+outputSlug(s.description, s.id)+".md")
+	}
+	s.outputErr = os.MkdirAll(s.outputDir, 0o755)
+	if s.outputErr == nil {
+		s.outputErr = os.WriteFile(path, []byte(strings.TrimSpace(s.result)+"\n"), 0o644)
+	}
+	if s.outputErr == nil {
+		s.outputFile = path
+	}
+}
 
-	result := map[string]interface{}{
-		"status":            "completed",
-		"agentId":           agentID,
-		"content":           state.result,
-		"totalToolUseCount": state.turns,
-		"totalDurationMs":   durationMs,
-		"usage":             state.usage,
+// outputSlug turns a task description into a file name component, falling
+// back to fallback if nothing usable is left.
+func outputSlug(description, fallback string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(description) {
+		switch {
+		case r >= 'a' && r <= 'z' || r >= '0' && r <= '9':
+			b.WriteRune(r)
+			dash = false
+		case !dash && b.Len() > 0:
+			b.WriteByte('-')
+			dash = true
+		}
+		if b.Len() >= 50 {
+			break
+		}
 	}
-	out, _ := json.Marshal(result)
-	return string(out), nil
+	if slug := strings.Trim(b.String(), "-"); slug != "" {
+		return slug
+	}
+	return fallback
 }
 
 // extractResult gets the last assistant text from the sub-agent's history.
@@ -0,0 +1,164 @@
+package tools
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// agentCacheMax is how many runs the result cache keeps; the oldest
+	// are dropped past it.
+	agentCacheMax = 32
+
+	// agentCacheTTL is how long a cached run can be reused. The
+	// repository fingerprint misses changes outside the working tree,
+	// such as a dependency installed elsewhere, so results don't live
+	// for the whole session.
+	agentCacheTTL = 30 * time.Minute
+)
+
+// cachedRun is the result of a completed run of an agent whose definition
+// sets CacheResults, kept for reuse within the session.
+type cachedRun struct {
+	agentID      string
+	subagentType string
+	prompt       string
+	repoState    string // GitRepoState when the run started
+	content      string // the content the Agent call returned
+	summarized   bool
+	at           time.Time
+}
+
+// SetRepoState sets the function that fingerprints the working tree for
+// the result cache. Results are cached and reused only while it returns
+// a non-empty string, so nil disables caching.
+func (t *AgentTool) SetRepoState(fn func() string) {
+	t.repoState = fn
+}
+
+// ClearResultCache forgets cached agent results, as when the conversation
+// is cleared.
+func (t *AgentTool) ClearResultCache() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cache = nil
+}
+
+// cacheKey returns the repository state to cache a run of subagentType
+// under, or "" if its results aren't cached.
+func (t *AgentTool) cacheKey(subagentType string) string {
+	if def := t.customAgent(subagentType); def == nil || !def.CacheResults || t.repoState == nil {
+		return ""
+	}
+	return t.repoState()
+}
+
+// cachedResult returns the Agent result for a cached run of subagentType
+// on a near-identical prompt in the same repository state, or "".
+func (t *AgentTool) cachedResult(subagentType, prompt, repoState string) string {
+	if repoState == "" {
+		return ""
+	}
+	t.mu.Lock()
+	var hit *cachedRun
+	for i := len(t.cache) - 1; i >= 0; i-- {
+		c := &t.cache[i]
+		if time.Since(c.at) > agentCacheTTL {
+			break // older runs have expired too
+		}
+		if c.subagentType == subagentType && c.repoState == repoState && similarTask(c.prompt, prompt) {
+			hit = c
+			break
+		}
+	}
+	t.mu.Unlock()
+	if hit == nil {
+		return ""
+	}
+
+	result := map[string]interface{}{
+		"status":            "completed",
+		"agentId":           hit.agentID,
+		"content":           hit.content,
+		"cached":            true,
+		"totalToolUseCount": 0,
+		"totalDurationMs":   0,
+		"costUSD":           0,
+		"message": fmt.Sprintf("Reused the result of %s from %s ago for a near-identical prompt; the repository hasn't changed since. Rephrase the prompt if you need a fresh search.",
+			hit.agentID, time.Since(hit.at).Round(time.Second)),
+	}
+	if hit.summarized {
+		result["summarized"] = true
+	}
+	out, _ := json.Marshal(result)
+	return string(out)
+}
+
+// cacheRun remembers the content returned for a completed run, if the
+// agent's results are cached, dropping expired runs and the oldest past
+// agentCacheMax.
+func (t *AgentTool) cacheRun(state *agentState, content string, summarized bool) {
+	if state.cacheState == "" || state.err != nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for len(t.cache) > 0 && (len(t.cache) >= agentCacheMax || time.Since(t.cache[0].at) > agentCacheTTL) {
+		t.cache[0] = cachedRun{} // release the content
+		t.cache = t.cache[1:]
+	}
+	t.cache = append(t.cache, cachedRun{
+		agentID:      state.id,
+		subagentType: state.subagentType,
+		prompt:       state.cachePrompt,
+		repoState:    state.cacheState,
+		content:      content,
+		summarized:   summarized,
+		at:           time.Now(),
+	})
+}
+
+// GitRepoState fingerprints the git working tree at dir: HEAD, plus the
+// status, size, and modification time of every changed or untracked file.
+// It returns "" if dir isn't in a git repository.
+func GitRepoState(dir string) string {
+	git := func(args ...string) ([]byte, error) {
+		cmd := exec.Command("git", append([]string{"--no-optional-locks"}, args...)...)
+		cmd.Dir = dir
+		return cmd.Output()
+	}
+	head, err := git("rev-parse", "HEAD")
+	if err != nil {
+		return ""
+	}
+	status, err := git("status", "--porcelain", "-z", "--untracked-files=all")
+	if err != nil {
+		return ""
+	}
+	root, err := git("rev-parse", "--show-toplevel")
+	if err != nil {
+		return ""
+	}
+
+	h := sha256.New()
+	h.Write(head)
+	for _, entry := range bytes.Split(status, []byte{0}) {
+		h.Write(entry)
+		// Entries are "XY path"; renames are followed by the old path
+		// as a separate entry, which stat simply won't find.
+		if len(entry) > 3 {
+			if info, err := os.Stat(filepath.Join(string(bytes.TrimSpace(root)), string(entry[3:]))); err == nil {
+				fmt.Fprintf(h, "\x00%d\x00%d", info.Size(), info.ModTime().UnixNano())
+			}
+		}
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,87 @@
+package tools
+
+import (
+	"time"
+
+	"github.com/anthropics/claude-code-go/internal/api"
+	"github.com/anthropics/claude-code-go/internal/conversation"
+)
+
+// AgentCost is what one run of a sub-agent consumed. A resumed agent gets
+// one entry per run.
+type AgentCost struct {
+	AgentID      string    `json:"agent_id"`
+	Description  string    `json:"description,omitempty"`
+	SubagentType string    `json:"subagent_type,omitempty"`
+	Model        string    `json:"model,omitempty"`
+	Usage        api.Usage `json:"usage"`
+	ToolUses     int       `json:"tool_uses"`
+	CostUSD      float64   `json:"cost_usd"`
+	DurationMs   int64     `json:"duration_ms"`
+	FinishedAt   time.Time `json:"finished_at"`
+}
+
+// Tokens returns the run's total tokens, including cache reads and writes.
+func (c AgentCost) Tokens() int { return c.Usage.TotalTokens() }
+
+// runCost totals the usage, tool calls, and cost of turns, pricing each
+// turn at its own model. model is the last model used.
+func runCost(turns []conversation.TurnMetadata) (usage api.Usage, toolUses int, cost float64, model string) {
+	var cacheWrite, cacheRead int
+	for _, turn := range turns {
+		usage.InputTokens += turn.Usage.InputTokens
+		usage.OutputTokens += turn.Usage.OutputTokens
+		if turn.Usage.CacheCreationInputTokens != nil {
+			cacheWrite += *turn.Usage.CacheCreationInputTokens
+		}
+		if turn.Usage.CacheReadInputTokens != nil {
+			cacheRead += *turn.Usage.CacheReadInputTokens
+		}
+		toolUses += len(turn.Tools)
+		cost += turn.Usage.Cost(turn.Model)
+		if turn.Model != "" {
+			model = turn.Model
+		}
+	}
+	if cacheWrite > 0 {
+		usage.CacheCreationInputTokens = &cacheWrite
+	}
+	if cacheRead > 0 {
+		usage.CacheReadInputTokens = &cacheRead
+	}
+	return usage, toolUses, cost, model
+}
+
+// Costs returns the cost of every sub-agent run in the session, oldest
+// first.
+func (t *AgentTool) Costs() []AgentCost {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]AgentCost(nil), t.costs...)
+}
+
+// SetCosts replaces the recorded sub-agent costs, as when a session is
+// resumed or cleared.
+func (t *AgentTool) SetCosts(costs []AgentCost) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.costs = append([]AgentCost(nil), costs...)
+}
+
+// recordCost totals the turns of the agent's run that just finished and
+// adds them to the session's sub-agent costs.
+func (t *AgentTool) recordCost(state *agentState) AgentCost {
+	turns := state.history.Turns()
+	c := AgentCost{
+		AgentID:      state.id,
+		Description:  state.description,
+		SubagentType: state.subagentType,
+		DurationMs:   time.Since(state.startedAt).Milliseconds(),
+		FinishedAt:   time.Now(),
+	}
+	c.Usage, c.ToolUses, c.CostUSD, c.Model = runCost(turns[min(state.runStart, len(turns)):])
+	t.mu.Lock()
+	t.costs = append(t.costs, c)
+	t.mu.Unlock()
+	return c
+}
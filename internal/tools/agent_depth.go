@@ -0,0 +1,110 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// DefaultMaxAgentDepth is how deeply agents nest unless settings say
+// otherwise: the main conversation's sub-agents may start agents of their
+// own, but those may not.
+const DefaultMaxAgentDepth = 2
+
+// similarTaskThreshold is the share of words two prompts must have in
+// common to count as the same task.
+const similarTaskThreshold = 0.8
+
+// agentFrame is one level of delegation: a sub-agent and the task it was
+// given.
+type agentFrame struct {
+	id           string
+	subagentType string
+	prompt       string
+}
+
+type agentChainKey struct{}
+
+// withAgentChain returns a context for tools run by the innermost agent
+// of chain.
+func withAgentChain(ctx context.Context, chain []agentFrame) context.Context {
+	return context.WithValue(ctx, agentChainKey{}, chain)
+}
+
+// agentChain returns the agents the call in ctx is nested in, outermost
+// first. It is empty in the main conversation.
+func agentChain(ctx context.Context) []agentFrame {
+	chain, _ := ctx.Value(agentChainKey{}).([]agentFrame)
+	return chain
+}
+
+// SetMaxDepth sets how deeply agents may nest. At depth 1 sub-agents can't
+// start agents of their own; 0 uses DefaultMaxAgentDepth.
+func (t *AgentTool) SetMaxDepth(n int) {
+	if n <= 0 {
+		n = DefaultMaxAgentDepth
+	}
+	t.maxDepth = n
+}
+
+// checkDelegation returns a tool error if an agent nested in chain may not
+// start a sub-agent with prompt: because the chain is already as deep as
+// allowed, or because an agent up the chain was given the same task.
+func (t *AgentTool) checkDelegation(chain []agentFrame, prompt string) string {
+	if len(chain) == 0 {
+		return ""
+	}
+	if len(chain) >= t.maxDepth {
+		return fmt.Sprintf("Error: agent nesting limit reached. This agent is %d levels deep (%s) and maxAgentDepth is %d, so it can't start another agent. Do the task with your own tools, or report back what is left for the caller to handle.",
+			len(chain), formatChain(chain), t.maxDepth)
+	}
+	for _, f := range chain {
+		if similarTask(f.prompt, prompt) {
+			return fmt.Sprintf("Error: this task was already delegated to %s higher up the agent chain (%s). Delegating it again would loop without making progress; do the task with your own tools instead.",
+				f.id, formatChain(chain))
+		}
+	}
+	return ""
+}
+
+// formatChain describes a delegation chain, e.g.
+// "main → agent-1 (Plan) → agent-3 (general-purpose)".
+func formatChain(chain []agentFrame) string {
+	parts := []string{"main"}
+	for _, f := range chain {
+		if f.subagentType != "" {
+			parts = append(parts, fmt.Sprintf("%s (%s)", f.id, f.subagentType))
+		} else {
+			parts = append(parts, f.id)
+		}
+	}
+	return strings.Join(parts, " → ")
+}
+
+// similarTask reports whether two prompts share enough of their words to
+// describe the same task, ignoring case, punctuation, and word order.
+func similarTask(a, b string) bool {
+	wa, wb := promptWords(a), promptWords(b)
+	if len(wa) == 0 || len(wb) == 0 {
+		return false
+	}
+	common := 0
+	for w := range wa {
+		if wb[w] {
+			common++
+		}
+	}
+	union := len(wa) + len(wb) - common
+	return float64(common)/float64(union) >= similarTaskThreshold
+}
+
+func promptWords(s string) map[string]bool {
+	words := make(map[string]bool)
+	for _, w := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		words[w] = true
+	}
+	return words
+}
@@ -0,0 +1,100 @@
+package tools
+
+import (
+	"encoding/json"
+	"unicode/utf8"
+
+	"github.com/anthropics/claude-code-go/internal/api"
+	"github.com/anthropics/claude-code-go/internal/conversation"
+)
+
+// agentProgressTextBytes is how much of a sub-agent's latest text is kept
+// for progress display.
+const agentProgressTextBytes = 400
+
+// agentProgressHandler is a sub-agent's stream handler. Instead of printing
+// the sub-agent's output, it reports the current turn, tool call, and tail
+// of text to a progress sink, if the parent provided one.
+type agentProgressHandler struct {
+	sink     func(conversation.AgentProgress)
+	p        conversation.AgentProgress
+	toolIdx  int
+	toolJSON []byte
+}
+
+func newAgentProgressHandler(sink func(conversation.AgentProgress), agentID, description string) *agentProgressHandler {
+	return &agentProgressHandler{
+		sink:    sink,
+		p:       conversation.AgentProgress{AgentID: agentID, Description: description},
+		toolIdx: -1,
+	}
+}
+
+// start begins a new run reporting to sink, as when an agent is resumed
+// from a different Agent call.
+func (h *agentProgressHandler) start(sink func(conversation.AgentProgress)) {
+	h.sink = sink
+	h.p.Done = false
+	h.p.Tool, h.p.ToolInput, h.p.Text = "", nil, ""
+}
+
+// finish reports that the run is over, with what it consumed.
+func (h *agentProgressHandler) finish(cost AgentCost) {
+	h.p.Done = true
+	h.p.ToolUses, h.p.Tokens, h.p.CostUSD, h.p.DurationMs = cost.ToolUses, cost.Tokens(), cost.CostUSD, cost.DurationMs
+	h.emit()
+}
+
+func (h *agentProgressHandler) emit() {
+	if h.sink != nil {
+		h.sink(h.p)
+	}
+}
+
+func (h *agentProgressHandler) OnMessageStart(_ api.MessageResponse) {
+	h.p.Turns++
+	h.p.Tool, h.p.ToolInput = "", nil
+	h.emit()
+}
+
+func (h *agentProgressHandler) OnContentBlockStart(index int, block api.ContentBlock) {
+	switch block.Type {
+	case api.ContentTypeToolUse:
+		h.toolIdx, h.toolJSON = index, nil
+		h.p.Tool, h.p.ToolInput = block.Name, nil
+		h.emit()
+	case api.ContentTypeText:
+		h.p.Text = ""
+	}
+}
+
+func (h *agentProgressHandler) OnTextDelta(_ int, text string) {
+	h.p.Text += text
+	if over := len(h.p.Text) - agentProgressTextBytes; over > 0 {
+		for over < len(h.p.Text) && !utf8.RuneStart(h.p.Text[over]) {
+			over++
+		}
+		h.p.Text = h.p.Text[over:]
+	}
+	h.emit()
+}
+
+func (h *agentProgressHandler) OnInputJSONDelta(index int, partialJSON string) {
+	if index == h.toolIdx {
+		h.toolJSON = append(h.toolJSON, partialJSON...)
+	}
+}
+
+func (h *agentProgressHandler) OnContentBlockStop(index int) {
+	if index == h.toolIdx {
+		h.p.ToolInput = json.RawMessage(h.toolJSON)
+		h.toolIdx = -1
+		h.emit()
+	}
+}
+
+func (h *agentProgressHandler) OnThinkingDelta(_ int, _ string)                     {}
+func (h *agentProgressHandler) OnSignatureDelta(_ int, _ string)                    {}
+func (h *agentProgressHandler) OnMessageDelta(_ api.MessageDeltaBody, _ *api.Usage) {}
+func (h *agentProgressHandler) OnMessageStop()                                      {}
+func (h *agentProgressHandler) OnError(_ error)                                     {}
@@ -0,0 +1,90 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/anthropics/claude-code-go/internal/api"
+)
+
+// agentSummaryThreshold is the result size (in characters) above which a
+// sub-agent's output is summarized before being returned to the parent
+// conversation. The full text stays retrievable via TaskOutput.
+const agentSummaryThreshold = 20_000
+
+// agentSummaryModel is the small model used to condense large results.
+const agentSummaryModel = api.ModelClaude45Haiku
+
+// agentSummaryFallbackChars is how much of the raw output is kept when the
+// summarization call fails.
+const agentSummaryFallbackChars = 8_000
+
+const agentSummaryPrompt = `You condense the output of a sub-agent before it is handed back to the agent that launched it. Preserve every concrete finding: file paths, line numbers, function and type names, commands, error messages, and conclusions. Drop repetition, narration, and raw listings that add no information. Respond with the condensed output only.`
+
+// summarizeAgentResult returns result unchanged when it is small. Larger
+// results are stored as a completed background task under agentID (so the
+// parent can fetch the full text with TaskOutput) and replaced with a
+// summary produced by a small model. The second return value reports
+// whether the result was condensed.
+func (t *AgentTool) summarizeAgentResult(ctx context.Context, agentID, result string) (string, bool) {
+	if len(result) <= agentSummaryThreshold || t.client == nil {
+		return result, false
+	}
+
+	if t.bgStore != nil {
+		done := make(chan struct{})
+		close(done)
+		t.bgStore.Add(&BackgroundTask{ID: agentID, Done: done, Result: result})
+	}
+
+	summary, err := t.requestSummary(ctx, result)
+	if err != nil || summary == "" {
+		summary = result[:agentSummaryFallbackChars] + "\n..."
+	}
+
+	note := fmt.Sprintf("[Agent output was %d characters and has been condensed.", len(result))
+	if t.bgStore != nil {
+		note += fmt.Sprintf(" Use TaskOutput with task_id %q to read the full output.", agentID)
+	}
+	return note + "]\n\n" + summary, true
+}
+
+// requestSummary asks the summary model to condense a sub-agent result.
+func (t *AgentTool) requestSummary(ctx context.Context, result string) (string, error) {
+	req := &api.CreateMessageRequest{
+		Model:     agentSummaryModel,
+		MaxTokens: 4096,
+		System:    []api.SystemBlock{{Type: "text", Text: agentSummaryPrompt}},
+		Messages:  []api.Message{api.NewTextMessage(api.RoleUser, result)},
+	}
+	resp, err := t.client.CreateMessageStream(ctx, req, &discardStreamHandler{})
+	if err != nil {
+		return "", fmt.Errorf("summarizing agent output: %w", err)
+	}
+	if resp == nil {
+		return "", fmt.Errorf("empty summarization response")
+	}
+
+	var sb strings.Builder
+	for _, block := range resp.Content {
+		if block.Type == api.ContentTypeText {
+			sb.WriteString(block.Text)
+		}
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
+
+// discardStreamHandler ignores all streaming events.
+type discardStreamHandler struct{}
+
+func (h *discardStreamHandler) OnMessageStart(api.MessageResponse)              {}
+func (h *discardStreamHandler) OnContentBlockStart(int, api.ContentBlock)       {}
+func (h *discardStreamHandler) OnTextDelta(int, string)                         {}
+func (h *discardStreamHandler) OnThinkingDelta(int, string)                     {}
+func (h *discardStreamHandler) OnSignatureDelta(int, string)                    {}
+func (h *discardStreamHandler) OnInputJSONDelta(int, string)                    {}
+func (h *discardStreamHandler) OnContentBlockStop(int)                          {}
+func (h *discardStreamHandler) OnMessageDelta(api.MessageDeltaBody, *api.Usage) {}
+func (h *discardStreamHandler) OnMessageStop()                                  {}
+func (h *discardStreamHandler) OnError(error)                                   {}
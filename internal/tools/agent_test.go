@@ -0,0 +1,514 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anthropics/claude-code-go/internal/agents"
+	"github.com/anthropics/claude-code-go/internal/api"
+	"github.com/anthropics/claude-code-go/internal/conversation"
+	"github.com/anthropics/claude-code-go/internal/hooks"
+	"github.com/anthropics/claude-code-go/internal/mock"
+)
+
+func TestAgentToolCustomAgentsDescription(t *testing.T) {
+	tool := NewAgentTool(nil, nil, nil, nil, nil, nil)
+	if strings.Contains(tool.Description(), "Available agent types") {
+		t.Error("description should not list agent types without custom agents")
+	}
+	tool.SetCustomAgents([]agents.Agent{{Name: "reviewer", Description: "Reviews diffs", Tools: []string{"Read", "Grep"}}})
+	if desc := tool.Description(); !strings.Contains(desc, "- reviewer: Reviews diffs (Tools: Read, Grep)") {
+		t.Errorf("unexpected description:\n%s", desc)
+	}
+	if tool.customAgent("reviewer") == nil || tool.customAgent("other") != nil {
+		t.Error("customAgent lookup failed")
+	}
+}
+
+func TestRestrictTools(t *testing.T) {
+	reg := NewRegistry(nil)
+	for _, name := range []string{"FileRead", "Grep", "Bash"} {
+		reg.Register(&mockTool{name: name, result: name + " ran"})
+	}
+
+	defs, exec := restrictTools(reg.Definitions(), reg, []string{"Read", "Grep"})
+	var names []string
+	for _, d := range defs {
+		names = append(names, d.Name)
+	}
+	if got := strings.Join(names, ","); got != "FileRead,Grep" {
+		t.Errorf("definitions = %s, want FileRead,Grep", got)
+	}
+	if !exec.HasTool("FileRead") || exec.HasTool("Bash") {
+		t.Error("HasTool should only report allowed tools")
+	}
+	if _, err := exec.Execute(context.Background(), "Bash", []byte(`{}`)); err == nil {
+		t.Error("expected an error running a disallowed tool")
+	}
+	if out, err := exec.Execute(context.Background(), "Grep", []byte(`{}`)); err != nil || out != "Grep ran" {
+		t.Errorf("Grep = %q, %v", out, err)
+	}
+}
+
+func TestAgentToolCustomAgentLimits(t *testing.T) {
+	// The model never stops calling Grep, so only the turn limit ends the run.
+	b := mock.NewBackend(mock.ResponderFunc(func(req *api.CreateMessageRequest) *api.MessageResponse {
+		return mock.ToolUseResponse("toolu_1", "Grep", json.RawMessage(`{}`), 1)
+	}))
+	defer b.Close()
+
+	reg := NewRegistry(nil)
+	for _, name := range []string{"Grep", "Bash"} {
+		reg.Register(&mockTool{name: name, result: name + " ran"})
+	}
+	tool := NewAgentTool(b.Client(), nil, reg.Definitions(), reg, nil, nil)
+	tool.SetCustomAgents([]agents.Agent{{
+		Name: "explorer", Description: "Explores", Tools: []string{"Grep"},
+		Model: "haiku", MaxTurns: 2, MaxTokens: 1024, SystemPrompt: "Explore.",
+	}})
+
+	run := func(extra string) []*mock.CapturedRequest {
+		t.Helper()
+		before := b.RequestCount()
+		input := fmt.Sprintf(`{"description": "d", "prompt": "look", "subagent_type": "explorer"%s}`, extra)
+		if _, err := tool.Execute(context.Background(), json.RawMessage(input)); err != nil {
+			t.Fatal(err)
+		}
+		return b.Requests()[before:]
+	}
+
+	reqs := run("")
+	if len(reqs) != 2 {
+		t.Fatalf("requests = %d, want 2 (maxTurns)", len(reqs))
+	}
+	for _, r := range reqs {
+		if r.Body.Model != api.ResolveModelAlias("haiku") || r.Body.MaxTokens != 1024 {
+			t.Errorf("model = %q, max_tokens = %d", r.Body.Model, r.Body.MaxTokens)
+		}
+		if len(r.Body.Tools) != 1 || r.Body.Tools[0].Name != "Grep" {
+			t.Errorf("tools = %+v, want only Grep", r.Body.Tools)
+		}
+	}
+	if tool.client.Model() == api.ResolveModelAlias("haiku") {
+		t.Error("the parent client's model should not change")
+	}
+
+	if reqs := run(`, "max_turns": 1`); len(reqs) != 1 {
+		t.Errorf("max_turns 1: requests = %d, want 1", len(reqs))
+	}
+	if reqs := run(`, "max_turns": 5`); len(reqs) != 2 {
+		t.Errorf("max_turns 5: requests = %d, want the agent's limit of 2", len(reqs))
+	}
+}
+
+func TestRunCost(t *testing.T) {
+	cached := 100
+	turns := []conversation.TurnMetadata{
+		{Model: api.ModelClaude46Sonnet, Usage: api.Usage{InputTokens: 1_000_000, OutputTokens: 5, CacheReadInputTokens: &cached}, Tools: []string{"Grep", "Glob"}},
+		{Model: api.ModelClaude45Haiku, Usage: api.Usage{InputTokens: 20, OutputTokens: 1_000_000, CacheReadInputTokens: &cached}},
+	}
+
+	usage, toolUses, cost, model := runCost(turns)
+	if usage.InputTokens != 1_000_020 || usage.OutputTokens != 1_000_005 || toolUses != 2 {
+		t.Errorf("usage = %+v, tool uses = %d", usage, toolUses)
+	}
+	if model != api.ModelClaude45Haiku {
+		t.Errorf("model = %q, want the last turn's", model)
+	}
+	// Each turn is priced at its own model: $3 of Sonnet input plus $4
+	// of Haiku output, and a little for the rest.
+	if cost < 7 || cost > 7.01 {
+		t.Errorf("cost = %v, want about 7", cost)
+	}
+	if usage.CacheReadInputTokens == nil || *usage.CacheReadInputTokens != 200 || usage.CacheCreationInputTokens != nil {
+		t.Errorf("cache usage = %v, %v", usage.CacheReadInputTokens, usage.CacheCreationInputTokens)
+	}
+}
+
+func TestAgentToolReportsProgress(t *testing.T) {
+	b := mock.NewBackend(mock.NewScriptedResponder([]*api.MessageResponse{
+		mock.ToolUseWithTextResponse("Searching.", "toolu_1", "Grep", json.RawMessage(`{"pattern":"TODO"}`), 1),
+		mock.TextResponse("Found 3 TODOs.", 2),
+	}))
+	defer b.Close()
+
+	reg := NewRegistry(nil)
+	reg.Register(&mockTool{name: "Grep", result: "a.go:1: TODO"})
+	tool := NewAgentTool(b.Client(), nil, reg.Definitions(), reg, nil, nil)
+
+	var reports []conversation.AgentProgress
+	ctx := conversation.WithAgentProgress(context.Background(), func(p conversation.AgentProgress) {
+		reports = append(reports, p)
+	})
+	if _, err := tool.Execute(ctx, json.RawMessage(`{"description": "Find TODOs", "prompt": "find", "subagent_type": "general-purpose"}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(reports) == 0 {
+		t.Fatal("no progress reported")
+	}
+	sawTool := false
+	for _, p := range reports {
+		if p.Description != "Find TODOs" || p.AgentID == "" {
+			t.Errorf("report = %+v", p)
+		}
+		if p.Tool == "Grep" && string(p.ToolInput) == `{"pattern":"TODO"}` && p.Turns == 1 {
+			sawTool = true
+		}
+	}
+	if !sawTool {
+		t.Errorf("no report of the Grep call in turn 1: %+v", reports)
+	}
+	last := reports[len(reports)-1]
+	if !last.Done || last.Turns != 2 || last.Tool != "" || last.Text != "Found 3 TODOs." {
+		t.Errorf("last report = %+v", last)
+	}
+	if last.ToolUses != 1 || last.Tokens == 0 || last.CostUSD == 0 {
+		t.Errorf("last report should total the run: %+v", last)
+	}
+}
+
+func TestAgentToolRecordsCosts(t *testing.T) {
+	b := mock.NewBackend(&mock.StaticResponder{Response: mock.TextResponse("Done.", 1)})
+	defer b.Close()
+	tool := NewAgentTool(b.Client(), nil, nil, NewRegistry(nil), nil, nil)
+
+	run := func(input string) map[string]any {
+		t.Helper()
+		out, err := tool.Execute(context.Background(), json.RawMessage(input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var result map[string]any
+		if err := json.Unmarshal([]byte(out), &result); err != nil {
+			t.Fatalf("result %q: %v", out, err)
+		}
+		return result
+	}
+	first := run(`{"description": "Review", "prompt": "review", "subagent_type": "code-reviewer"}`)
+	second := run(`{"description": "Review", "prompt": "again", "subagent_type": "code-reviewer", "resume": "` + first["agentId"].(string) + `"}`)
+
+	// Each run is priced on its own turns: 10 input and 20 output tokens
+	// of Sonnet.
+	want := api.Usage{InputTokens: 10, OutputTokens: 20}.Cost(api.ModelClaude46Sonnet)
+	for _, result := range []map[string]any{first, second} {
+		if result["costUSD"] != want {
+			t.Errorf("costUSD = %v, want %v", result["costUSD"], want)
+		}
+	}
+	costs := tool.Costs()
+	if len(costs) != 2 {
+		t.Fatalf("costs = %+v, want one per run", costs)
+	}
+	for _, c := range costs {
+		if c.AgentID != first["agentId"] || c.SubagentType != "code-reviewer" || c.Model != api.ModelClaude46Sonnet || c.Tokens() != 30 || c.CostUSD != want {
+			t.Errorf("cost = %+v", c)
+		}
+	}
+
+	tool.SetCosts(nil)
+	if costs := tool.Costs(); len(costs) != 0 {
+		t.Errorf("after SetCosts(nil): %+v", costs)
+	}
+}
+
+func TestAgentToolSavesOutput(t *testing.T) {
+	b := mock.NewBackend(&mock.StaticResponder{Response: mock.TextResponse("## Goal\nAdd caching.", 1)})
+	defer b.Close()
+
+	dir := filepath.Join(t.TempDir(), "plans")
+	tool := NewAgentTool(b.Client(), nil, nil, nil, nil, nil)
+	tool.SetCustomAgents([]agents.Agent{{Name: "Plan", Description: "Plans", SystemPrompt: "Plan.", OutputDir: dir}})
+
+	out, err := tool.Execute(context.Background(), json.RawMessage(`{"description": "Plan the cache layer!", "prompt": "plan", "subagent_type": "Plan"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result struct {
+		OutputFile string `json:"outputFile"`
+	}
+	json.Unmarshal([]byte(out), &result)
+	if filepath.Dir(result.OutputFile) != dir || !strings.HasSuffix(result.OutputFile, "-plan-the-cache-layer.md") {
+		t.Fatalf("outputFile = %q", result.OutputFile)
+	}
+	if data, err := os.ReadFile(result.OutputFile); err != nil || string(data) != "## Goal\nAdd caching.\n" {
+		t.Errorf("saved plan = %q, %v", data, err)
+	}
+}
+
+func TestOutputSlug(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"Plan the cache layer!", "plan-the-cache-layer"},
+		{"  --Refactor: auth/session  ", "refactor-auth-session"},
+		{"???", "agent-1"},
+	}
+	for _, tt := range tests {
+		if got := outputSlug(tt.in, "agent-1"); got != tt.want {
+			t.Errorf("outputSlug(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestAgentToolResumesSavedBackgroundAgent(t *testing.T) {
+	b := mock.NewBackend(mock.NewScriptedResponder([]*api.MessageResponse{
+		mock.TextResponse("Auth lives in auth.go.", 1),
+		mock.TextResponse("Tokens are refreshed in token.go.", 2),
+	}))
+	defer b.Close()
+	dir := filepath.Join(t.TempDir(), "sess.tasks")
+
+	store := NewBackgroundTaskStore()
+	store.Open(dir)
+	tool := NewAgentTool(b.Client(), nil, nil, nil, store, nil)
+	out, err := tool.Execute(context.Background(), json.RawMessage(`{"description": "Find auth", "prompt": "where is auth?", "subagent_type": "general-purpose", "run_in_background": true}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var launched struct{ AgentID string }
+	json.Unmarshal([]byte(out), &launched)
+	task, ok := store.Get(launched.AgentID)
+	if !ok {
+		t.Fatalf("no background task for %s", out)
+	}
+	<-task.Done
+
+	// After a restart, TaskOutput reads the saved result and resume
+	// continues the saved conversation.
+	restored := NewBackgroundTaskStore()
+	restored.Open(dir)
+	out, _ = NewTaskOutputTool(restored).Execute(context.Background(), json.RawMessage(`{"task_id": "`+launched.AgentID+`", "block": false}`))
+	if !strings.Contains(out, `"status":"completed"`) || !strings.Contains(out, "Auth lives in auth.go.") {
+		t.Errorf("TaskOutput after restart = %s", out)
+	}
+
+	tool = NewAgentTool(b.Client(), nil, nil, nil, restored, nil)
+	out, err = tool.Execute(context.Background(), json.RawMessage(`{"description": "Find auth", "prompt": "and tokens?", "subagent_type": "general-purpose", "resume": "`+launched.AgentID+`"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "token.go") {
+		t.Errorf("resumed result = %s", out)
+	}
+	if n := len(b.LastRequest().Body.Messages); n != 3 {
+		t.Errorf("resumed request has %d messages, want the saved 2 plus the new prompt", n)
+	}
+	if task, _ := restored.Get(launched.AgentID); !strings.Contains(task.Result, "token.go") {
+		t.Errorf("saved task not updated by the resumed run: %+v", task)
+	}
+}
+
+func TestCompleteExchanges(t *testing.T) {
+	toolUse := api.NewBlockMessage(api.RoleAssistant, []api.ContentBlock{{Type: api.ContentTypeToolUse, ID: "toolu_1", Name: "Grep", Input: json.RawMessage(`{}`)}})
+	msgs := []api.Message{api.NewTextMessage(api.RoleUser, "find"), toolUse}
+	if got := completeExchanges(msgs); len(got) != 1 {
+		t.Errorf("dangling tool call kept: %d messages", len(got))
+	}
+	msgs[1] = api.NewTextMessage(api.RoleAssistant, "done")
+	if got := completeExchanges(msgs); len(got) != 2 {
+		t.Errorf("complete exchange trimmed: %d messages", len(got))
+	}
+}
+
+func TestAgentToolSubagentHooks(t *testing.T) {
+	b := mock.NewBackend(&mock.StaticResponder{Response: mock.TextResponse("Done.", 1)})
+	defer b.Close()
+
+	log := filepath.Join(t.TempDir(), "hooks.log")
+	runner := hooks.NewRunner(hooks.HookConfig{
+		SubagentStart: []hooks.HookDef{{Type: "command", Command: `echo "start $AGENT_TYPE $AGENT_PROMPT" >> ` + log + `; test "$AGENT_TYPE" != blocked`}},
+		SubagentStop:  []hooks.HookDef{{Type: "command", Command: `echo "stop $AGENT_TYPE $AGENT_STATUS $AGENT_RESULT_CHARS" >> ` + log}},
+	})
+	tool := NewAgentTool(b.Client(), nil, nil, NewRegistry(nil), nil, runner)
+
+	if _, err := tool.Execute(context.Background(), json.RawMessage(`{"description": "d", "prompt": "look around", "subagent_type": "general-purpose"}`)); err != nil {
+		t.Fatal(err)
+	}
+	out, err := tool.Execute(context.Background(), json.RawMessage(`{"description": "d", "prompt": "nope", "subagent_type": "blocked"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out, "Error: SubagentStart hook blocked") {
+		t.Errorf("blocked run result = %q", out)
+	}
+	if n := len(b.Requests()); n != 1 {
+		t.Errorf("requests = %d, want only the unblocked run's", n)
+	}
+
+	got, _ := os.ReadFile(log)
+	want := "start general-purpose look around\nstop general-purpose completed 5\nstart blocked nope\n"
+	if string(got) != want {
+		t.Errorf("hook log = %q, want %q", got, want)
+	}
+}
+
+func TestAgentToolNesting(t *testing.T) {
+	const task = "Fix the flaky test in internal/api"
+	b := mock.NewBackend(mock.NewScriptedResponder([]*api.MessageResponse{
+		// The sub-agent tries to hand its own task to another agent.
+		mock.ToolUseResponse("toolu_1", "Agent", json.RawMessage(`{"description": "Fix test", "prompt": "fix the flaky test in internal/api.", "subagent_type": "general-purpose"}`), 1),
+		mock.TextResponse("Fixed it myself.", 2),
+	}))
+	defer b.Close()
+
+	reg := NewRegistry(nil)
+	tool := NewAgentTool(b.Client(), nil, reg.Definitions(), reg, nil, nil)
+	reg.Register(tool)
+
+	if _, err := tool.Execute(context.Background(), json.RawMessage(`{"description": "Fix test", "prompt": "`+task+`", "subagent_type": "general-purpose"}`)); err != nil {
+		t.Fatal(err)
+	}
+	reqs := b.Requests()
+	if len(reqs) != 2 {
+		t.Fatalf("requests = %d, want 2", len(reqs))
+	}
+	if tools := reqs[0].Body.Tools; len(tools) != 1 || tools[0].Name != "Agent" {
+		t.Errorf("a first-level sub-agent should be offered Agent, got %+v", tools)
+	}
+	results := reqs[1].ToolResults()
+	if len(results) != 1 || !strings.Contains(string(results[0].Content), "already delegated") {
+		t.Errorf("nested call result = %+v, want a delegation loop error", results)
+	}
+
+	// At depth 1 sub-agents get no Agent tool, and a call from one is
+	// refused.
+	tool.SetMaxDepth(1)
+	b.SetResponder(&mock.StaticResponder{Response: mock.TextResponse("Done.", 3)})
+	if _, err := tool.Execute(context.Background(), json.RawMessage(`{"description": "d", "prompt": "p", "subagent_type": "general-purpose"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if tools := b.LastRequest().Body.Tools; len(tools) != 0 {
+		t.Errorf("at max depth 1 sub-agents should get no tools, got %+v", tools)
+	}
+	ctx := withAgentChain(context.Background(), []agentFrame{{id: "agent-1", subagentType: "Plan", prompt: "plan"}})
+	out, _ := tool.Execute(ctx, json.RawMessage(`{"description": "d", "prompt": "something else", "subagent_type": "general-purpose"}`))
+	if !strings.Contains(out, "nesting limit reached") || !strings.Contains(out, "main → agent-1 (Plan)") {
+		t.Errorf("call past the depth limit = %q", out)
+	}
+}
+
+func TestSimilarTask(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"Fix the flaky test in internal/api", "fix the flaky test in internal/api.", true},
+		{"Find all callers of Save", "Find all callers of Save, please", true},
+		{"Find all callers of Save", "Rename Save to Store everywhere", false},
+		{"", "anything", false},
+	}
+	for _, tt := range tests {
+		if got := similarTask(tt.a, tt.b); got != tt.want {
+			t.Errorf("similarTask(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestAgentToolCachesResults(t *testing.T) {
+	b := mock.NewBackend(&mock.StaticResponder{Response: mock.TextResponse("Sessions are saved in internal/session/session.go:120.", 1)})
+	defer b.Close()
+	tool := NewAgentTool(b.Client(), nil, nil, NewRegistry(nil), nil, nil)
+	tool.SetCustomAgents([]agents.Agent{
+		{Name: "Explore", SystemPrompt: "Search.", CacheResults: true},
+		{Name: "Plan", SystemPrompt: "Plan."},
+	})
+	repo := "state-1"
+	tool.SetRepoState(func() string { return repo })
+
+	run := func(subagentType, prompt string) map[string]any {
+		t.Helper()
+		out, err := tool.Execute(context.Background(), json.RawMessage(fmt.Sprintf(`{"description": "d", "prompt": %q, "subagent_type": %q}`, prompt, subagentType)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var result map[string]any
+		if err := json.Unmarshal([]byte(out), &result); err != nil {
+			t.Fatalf("result %q: %v", out, err)
+		}
+		return result
+	}
+
+	first := run("Explore", "Where is the session saved to disk?")
+	second := run("Explore", "where is the session saved to disk")
+	if second["cached"] != true || second["agentId"] != first["agentId"] || second["content"] != first["content"] {
+		t.Errorf("near-identical prompt should reuse the first result, got %+v", second)
+	}
+	if n := b.RequestCount(); n != 1 {
+		t.Errorf("requests = %d, want 1", n)
+	}
+
+	run("Explore", "How are permissions checked?")
+	run("Plan", "Where is the session saved to disk?")
+	if n := b.RequestCount(); n != 3 {
+		t.Errorf("requests = %d, want a new run for a different prompt and for an uncached agent", n)
+	}
+
+	repo = "state-2"
+	if result := run("Explore", "Where is the session saved to disk?"); result["cached"] == true {
+		t.Error("a changed repository should not reuse cached results")
+	}
+	tool.ClearResultCache()
+	if result := run("Explore", "Where is the session saved to disk?"); result["cached"] == true {
+		t.Error("ClearResultCache should drop cached results")
+	}
+	if n := b.RequestCount(); n != 5 {
+		t.Errorf("requests = %d, want 5", n)
+	}
+}
+
+func TestAgentResultCacheBounded(t *testing.T) {
+	tool := &AgentTool{}
+	for i := 0; i < agentCacheMax+5; i++ {
+		tool.cacheRun(&agentState{id: fmt.Sprintf("agent-%d", i), subagentType: "Explore", cachePrompt: fmt.Sprintf("question %d", i), cacheState: "s"}, "answer", false)
+	}
+	if len(tool.cache) != agentCacheMax || tool.cache[0].agentID != "agent-5" {
+		t.Errorf("cache holds %d runs starting with %s, want the last %d", len(tool.cache), tool.cache[0].agentID, agentCacheMax)
+	}
+
+	last := agentCacheMax + 4
+	prompt := fmt.Sprintf("question %d", last)
+	if tool.cachedResult("Explore", prompt, "s") == "" {
+		t.Fatal("recent run not reused")
+	}
+	for i := range tool.cache {
+		tool.cache[i].at = tool.cache[i].at.Add(-agentCacheTTL - time.Second)
+	}
+	if tool.cachedResult("Explore", prompt, "s") != "" {
+		t.Error("expired run reused")
+	}
+	tool.cacheRun(&agentState{id: "fresh", subagentType: "Explore", cachePrompt: "new question", cacheState: "s"}, "answer", false)
+	if len(tool.cache) != 1 {
+		t.Errorf("cache holds %d runs, want expired runs dropped", len(tool.cache))
+	}
+}
+
+func TestGitRepoState(t *testing.T) {
+	dir := initGitRepo(t)
+	clean := GitRepoState(dir)
+	if clean == "" || GitRepoState(dir) != clean {
+		t.Fatalf("state of a clean repository = %q, want a stable fingerprint", clean)
+	}
+
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("two\n"), 0644)
+	edited := GitRepoState(dir)
+	if edited == clean {
+		t.Error("editing a file should change the state")
+	}
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("three\n"), 0644)
+	if GitRepoState(dir) == edited {
+		t.Error("editing a modified file again should change the state")
+	}
+	os.WriteFile(filepath.Join(dir, "b.txt"), []byte("new\n"), 0644)
+	if GitRepoState(dir) == edited {
+		t.Error("adding an untracked file should change the state")
+	}
+
+	if got := GitRepoState(t.TempDir()); got != "" {
+		t.Errorf("state outside a repository = %q, want empty", got)
+	}
+}
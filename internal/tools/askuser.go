@@ -8,6 +8,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -42,6 +43,7 @@ type AskUserRequestMsg struct {
 type AskUserTool struct {
 	reader  *bufio.Reader
 	program *tea.Program // nil in print mode
+	mu      sync.Mutex   // one prompt at a time
 }
 
 // NewAskUserTool creates a new AskUserQuestion tool (print mode fallback).
@@ -141,43 +143,67 @@ func (t *AskUserTool) Execute(ctx context.Context, input json.RawMessage) (strin
 		return "Error: at least one question is required", nil
 	}
 
+	answers, err := t.Ask(ctx, in.Questions)
+	if err != nil {
+		return "", err
+	}
+	result := map[string]interface{}{
+		"questions": in.Questions,
+		"answers":   answers,
+	}
+	out, _ := json.Marshal(result)
+	return string(out), nil
+}
+
+// Ask presents questions to the user and returns the answers keyed by
+// question text. Questions without options take free text. If the user
+// cancels, the answers given so far are returned. Other callers, such as
+// MCP elicitation, use Ask to prompt the user the same way the tool does.
+func (t *AskUserTool) Ask(ctx context.Context, questions []AskUserQuestionItem) (map[string]string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
 	// TUI mode: delegate to the BT event loop via channel handshake.
 	if t.program != nil {
 		responseCh := make(chan map[string]string, 1)
 		t.program.Send(AskUserRequestMsg{
-			Questions:  in.Questions,
+			Questions:  questions,
 			ResponseCh: responseCh,
 		})
 
 		select {
 		case <-ctx.Done():
-			return "", ctx.Err()
+			return nil, ctx.Err()
 		case answers := <-responseCh:
-			result := map[string]interface{}{
-				"questions": in.Questions,
-				"answers":   answers,
-			}
-			out, _ := json.Marshal(result)
-			return string(out), nil
+			return answers, nil
 		}
 	}
 
 	// Print mode fallback: use terminal stdin.
-	return t.executeTerminal(ctx, in)
+	return t.askTerminal(ctx, questions)
 }
 
-// executeTerminal handles AskUser in non-TUI mode using fmt/bufio.
-func (t *AskUserTool) executeTerminal(ctx context.Context, in AskUserInput) (string, error) {
+// askTerminal handles AskUser in non-TUI mode using fmt/bufio.
+func (t *AskUserTool) askTerminal(ctx context.Context, questions []AskUserQuestionItem) (map[string]string, error) {
 	answers := make(map[string]string)
 
-	for _, q := range in.Questions {
+	for _, q := range questions {
 		select {
 		case <-ctx.Done():
-			return "", ctx.Err()
+			return nil, ctx.Err()
 		default:
 		}
 
 		fmt.Printf("\n[%s] %s\n", q.Header, q.Question)
+		if len(q.Options) == 0 {
+			fmt.Print("> ")
+			line, err := t.reader.ReadString('\n')
+			if err != nil {
+				return nil, fmt.Errorf("reading user input: %w", err)
+			}
+			answers[q.Question] = strings.TrimSpace(line)
+			continue
+		}
 		for i, opt := range q.Options {
 			fmt.Printf("  %d. %s - %s\n", i+1, opt.Label, opt.Description)
 		}
@@ -192,7 +218,7 @@ func (t *AskUserTool) executeTerminal(ctx context.Context, in AskUserInput) (str
 
 		line, err := t.reader.ReadString('\n')
 		if err != nil {
-			return "", fmt.Errorf("reading user input: %w", err)
+			return nil, fmt.Errorf("reading user input: %w", err)
 		}
 
 		line = strings.TrimSpace(line)
@@ -210,7 +236,7 @@ func (t *AskUserTool) executeTerminal(ctx context.Context, in AskUserInput) (str
 					fmt.Print("Enter your custom input: ")
 					custom, err := t.reader.ReadString('\n')
 					if err != nil {
-						return "", fmt.Errorf("reading custom input: %w", err)
+						return nil, fmt.Errorf("reading custom input: %w", err)
 					}
 					selected = append(selected, strings.TrimSpace(custom))
 				} else {
@@ -227,7 +253,7 @@ func (t *AskUserTool) executeTerminal(ctx context.Context, in AskUserInput) (str
 				fmt.Print("Enter your custom input: ")
 				custom, readErr := t.reader.ReadString('\n')
 				if readErr != nil {
-					return "", fmt.Errorf("reading custom input: %w", readErr)
+					return nil, fmt.Errorf("reading custom input: %w", readErr)
 				}
 				answers[q.Question] = strings.TrimSpace(custom)
 			} else {
@@ -236,10 +262,5 @@ func (t *AskUserTool) executeTerminal(ctx context.Context, in AskUserInput) (str
 		}
 	}
 
-	result := map[string]interface{}{
-		"questions": in.Questions,
-		"answers":   answers,
-	}
-	out, _ := json.Marshal(result)
-	return string(out), nil
+	return answers, nil
 }
@@ -2,24 +2,105 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
+	"time"
+
+	"github.com/anthropics/claude-code-go/internal/api"
 )
 
-// BackgroundTask represents a task running in the background (e.g., a sub-agent).
+// BackgroundTask represents a task running in the background (e.g., a sub-agent
+// or a Bash command).
 type BackgroundTask struct {
-	ID         string
-	Ctx        context.Context
-	Cancel     context.CancelFunc
-	Done       chan struct{}
-	Result     string
-	Err        error
-	OutputFile string
+	ID          string
+	Kind        string // "agent", "bash", or "server"
+	Description string
+	StartedAt   time.Time
+	Ctx         context.Context
+	Cancel      context.CancelFunc
+	Done        chan struct{}
+	Result      string
+	Err         error
+	OutputFile  string
+	Output      func() string             // output so far while running; nil if not streamed
+	Health      func() string             // current health of a server; nil for other tasks
+	Steer       func(message string) bool // sends a running agent a message; nil for other tasks
+
+	// Snapshot adds task-specific state, such as an agent's conversation,
+	// to the task's saved record. Tasks with a Snapshot are saved to the
+	// store's directory and restored after a restart; others exist only
+	// while their process runs.
+	Snapshot func(*TaskRecord)
+
+	// Record is the saved record a restored task was loaded from.
+	Record *TaskRecord
+
+	dir string // directory the task is saved in; "" if not saved
+}
+
+// Task statuses reported by Status, TaskOutput, and /tasks.
+const (
+	TaskRunning     = "running"
+	TaskCompleted   = "completed"
+	TaskError       = "error"
+	TaskInterrupted = "interrupted" // stopped, or its process exited, before finishing
+)
+
+// Status returns the task's status.
+func (t *BackgroundTask) Status() string {
+	select {
+	case <-t.Done:
+	default:
+		return TaskRunning
+	}
+	if t.Record != nil {
+		if t.Record.Status == TaskRunning {
+			return TaskInterrupted
+		}
+		return t.Record.Status
+	}
+	return finishedStatus(t.Err)
+}
+
+// finishedStatus returns the status of a task that ended with err.
+func finishedStatus(err error) string {
+	switch {
+	case err == nil:
+		return TaskCompleted
+	case errors.Is(err, context.Canceled):
+		return TaskInterrupted
+	}
+	return TaskError
+}
+
+// TaskRecord is the saved form of a background task.
+type TaskRecord struct {
+	ID           string        `json:"id"`
+	Kind         string        `json:"kind"`
+	Description  string        `json:"description,omitempty"`
+	Status       string        `json:"status"`
+	Result       string        `json:"result,omitempty"`
+	Error        string        `json:"error,omitempty"`
+	OutputFile   string        `json:"output_file,omitempty"`
+	StartedAt    time.Time     `json:"started_at"`
+	UpdatedAt    time.Time     `json:"updated_at"`
+	SubagentType string        `json:"subagent_type,omitempty"`
+	Messages     []api.Message `json:"messages,omitempty"` // an agent's conversation, for resuming it
 }
 
 // BackgroundTaskStore manages background tasks shared by Agent, TaskOutput, and TaskStop tools.
 type BackgroundTaskStore struct {
 	mu    sync.Mutex
 	tasks map[string]*BackgroundTask
+	dir   string // where tasks with a Snapshot are saved; "" disables saving
+
+	seal, open func([]byte) ([]byte, error) // encode saved records; see SetCodec
 }
 
 // NewBackgroundTaskStore creates a new background task store.
@@ -29,11 +110,18 @@ func NewBackgroundTaskStore() *BackgroundTaskStore {
 	}
 }
 
-// Add registers a background task.
+// Add registers a background task, saving it if it has a Snapshot.
 func (s *BackgroundTaskStore) Add(task *BackgroundTask) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	if task.StartedAt.IsZero() {
+		task.StartedAt = time.Now()
+	}
+	if task.Snapshot != nil {
+		task.dir = s.dir
+	}
 	s.tasks[task.ID] = task
+	s.mu.Unlock()
+	s.Save(task)
 }
 
 // Get retrieves a background task by ID.
@@ -44,9 +132,188 @@ func (s *BackgroundTaskStore) Get(id string) (*BackgroundTask, bool) {
 	return t, ok
 }
 
+// List returns every task, oldest first.
+func (s *BackgroundTaskStore) List() []*BackgroundTask {
+	s.mu.Lock()
+	tasks := make([]*BackgroundTask, 0, len(s.tasks))
+	for _, t := range s.tasks {
+		tasks = append(tasks, t)
+	}
+	s.mu.Unlock()
+	sort.Slice(tasks, func(i, j int) bool {
+		if !tasks[i].StartedAt.Equal(tasks[j].StartedAt) {
+			return tasks[i].StartedAt.Before(tasks[j].StartedAt)
+		}
+		return tasks[i].ID < tasks[j].ID
+	})
+	return tasks
+}
+
 // Remove deletes a background task from the store.
 func (s *BackgroundTaskStore) Remove(id string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	delete(s.tasks, id)
 }
+
+// SetCodec sets functions that encode task records as they are saved and
+// decode them as they are restored, such as encryption. Records that fail
+// to decode are skipped like corrupt ones.
+func (s *BackgroundTaskStore) SetCodec(seal, open func([]byte) ([]byte, error)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seal, s.open = seal, open
+}
+
+// Open sets the directory tasks are saved in, usually one per session, and
+// restores the tasks saved there. Tasks that were still running when their
+// process exited come back as interrupted. Finished tasks restored from a
+// previous directory are dropped; running tasks keep saving to the
+// directory they started in.
+func (s *BackgroundTaskStore) Open(dir string) error {
+	s.mu.Lock()
+	s.dir = dir
+	open := s.open
+	for id, t := range s.tasks {
+		if t.Record != nil && t.dir != dir {
+			delete(s.tasks, id)
+		}
+	}
+	s.mu.Unlock()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("reading task directory: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		if open != nil {
+			if data, err = open(data); err != nil {
+				continue
+			}
+		}
+		var rec TaskRecord
+		if err := json.Unmarshal(data, &rec); err != nil || rec.ID == "" {
+			continue // skip corrupt files
+		}
+		done := make(chan struct{})
+		close(done)
+		task := &BackgroundTask{
+			ID:          rec.ID,
+			Kind:        rec.Kind,
+			Description: rec.Description,
+			StartedAt:   rec.StartedAt,
+			Done:        done,
+			Result:      rec.Result,
+			OutputFile:  rec.OutputFile,
+			Record:      &rec,
+			dir:         dir,
+		}
+		if rec.Error != "" {
+			task.Err = errors.New(rec.Error)
+		}
+
+		s.mu.Lock()
+		if _, running := s.tasks[rec.ID]; !running {
+			s.tasks[rec.ID] = task
+		}
+		s.mu.Unlock()
+	}
+	return nil
+}
+
+// Finish records a task's result, saves it, and then closes its Done
+// channel, so StopAll doesn't return before the final record is written.
+func (s *BackgroundTaskStore) Finish(task *BackgroundTask, result string, err error) {
+	task.Result, task.Err = result, err
+	_ = s.save(task, finishedStatus(err))
+	close(task.Done)
+}
+
+// Save writes a task's record if it has a Snapshot and the store has a
+// directory. Tasks call it as they make progress.
+func (s *BackgroundTaskStore) Save(task *BackgroundTask) error {
+	return s.save(task, task.Status())
+}
+
+func (s *BackgroundTaskStore) save(task *BackgroundTask, status string) error {
+	if task.Snapshot == nil || task.dir == "" {
+		return nil
+	}
+	rec := TaskRecord{
+		ID:          task.ID,
+		Kind:        task.Kind,
+		Description: task.Description,
+		Status:      status,
+		Result:      task.Result,
+		OutputFile:  task.OutputFile,
+		StartedAt:   task.StartedAt,
+		UpdatedAt:   time.Now(),
+	}
+	if task.Err != nil {
+		rec.Error = task.Err.Error()
+	}
+	task.Snapshot(&rec)
+
+	if err := os.MkdirAll(task.dir, 0700); err != nil {
+		return fmt.Errorf("creating task directory: %w", err)
+	}
+	data, err := json.MarshalIndent(&rec, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling task: %w", err)
+	}
+	s.mu.Lock()
+	seal := s.seal
+	s.mu.Unlock()
+	if seal != nil {
+		if data, err = seal(data); err != nil {
+			return err
+		}
+	}
+	if err := os.WriteFile(filepath.Join(task.dir, task.ID+".json"), data, 0600); err != nil {
+		return fmt.Errorf("writing task file: %w", err)
+	}
+	return nil
+}
+
+// backgroundStopTimeout bounds how long StopAll waits for tasks to exit.
+const backgroundStopTimeout = 5 * time.Second
+
+// StopAll cancels every running task and waits briefly for them to finish.
+// It is called when the session ends so background commands and servers
+// don't outlive it. Saved tasks record that they were interrupted and can
+// be resumed in a later run.
+func (s *BackgroundTaskStore) StopAll() {
+	s.mu.Lock()
+	tasks := make([]*BackgroundTask, 0, len(s.tasks))
+	for _, t := range s.tasks {
+		tasks = append(tasks, t)
+	}
+	s.mu.Unlock()
+
+	for _, t := range tasks {
+		if t.Cancel != nil {
+			t.Cancel()
+		}
+	}
+	deadline := time.After(backgroundStopTimeout)
+	for _, t := range tasks {
+		if t.Done == nil {
+			continue
+		}
+		select {
+		case <-t.Done:
+		case <-deadline:
+			return
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBackgroundTaskStoreSavesAndRestores(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "sess.tasks")
+	store := NewBackgroundTaskStore()
+	if err := store.Open(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	done := &BackgroundTask{ID: "agent-1", Kind: "agent", Description: "Finished", Done: make(chan struct{}),
+		Snapshot: func(rec *TaskRecord) { rec.SubagentType = "Explore" }}
+	stopped := &BackgroundTask{ID: "agent-2", Kind: "agent", Done: make(chan struct{}), Snapshot: func(*TaskRecord) {}}
+	running := &BackgroundTask{ID: "agent-3", Kind: "agent", Done: make(chan struct{}), Snapshot: func(*TaskRecord) {}}
+	shell := &BackgroundTask{ID: "bash-1", Kind: "bash", Done: make(chan struct{})}
+	for _, task := range []*BackgroundTask{done, stopped, running, shell} {
+		store.Add(task)
+	}
+	store.Finish(done, "the answer", nil)
+	store.Finish(stopped, "partial", context.Canceled)
+	if got := stopped.Status(); got != TaskInterrupted {
+		t.Errorf("cancelled task status = %q, want interrupted", got)
+	}
+
+	// A new process sees the agents but not the shell command; the agent
+	// that never finished comes back interrupted.
+	restored := NewBackgroundTaskStore()
+	if err := restored.Open(dir); err != nil {
+		t.Fatal(err)
+	}
+	tasks := restored.List()
+	if len(tasks) != 3 {
+		t.Fatalf("restored %d tasks, want 3", len(tasks))
+	}
+	want := map[string]string{"agent-1": TaskCompleted, "agent-2": TaskInterrupted, "agent-3": TaskInterrupted}
+	for _, task := range tasks {
+		if got := task.Status(); got != want[task.ID] {
+			t.Errorf("%s status = %q, want %q", task.ID, got, want[task.ID])
+		}
+	}
+	if task, _ := restored.Get("agent-1"); task.Result != "the answer" || task.Description != "Finished" || task.Record.SubagentType != "Explore" {
+		t.Errorf("restored task = %+v", task)
+	}
+
+	// Switching sessions drops finished tasks restored from the old one.
+	if err := restored.Open(filepath.Join(t.TempDir(), "other.tasks")); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(restored.List()); n != 0 {
+		t.Errorf("%d tasks left after switching sessions", n)
+	}
+}
+
+func TestBackgroundTaskStoreSkipsCorruptRecords(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "bad.json"), []byte("{"), 0o600)
+	store := NewBackgroundTaskStore()
+	if err := store.Open(dir); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(store.List()); n != 0 {
+		t.Errorf("restored %d tasks from a corrupt file", n)
+	}
+	if err := store.Open(filepath.Join(dir, "missing")); err != nil {
+		t.Errorf("a missing directory should not be an error: %v", err)
+	}
+}
+
+func TestBackgroundTaskStoreCodec(t *testing.T) {
+	dir := t.TempDir()
+	reverse := func(data []byte) ([]byte, error) {
+		out := make([]byte, len(data))
+		for i, b := range data {
+			out[len(data)-1-i] = b
+		}
+		return out, nil
+	}
+	store := NewBackgroundTaskStore()
+	store.SetCodec(reverse, reverse)
+	if err := store.Open(dir); err != nil {
+		t.Fatal(err)
+	}
+	task := &BackgroundTask{ID: "agent-1", Kind: "agent", Done: make(chan struct{}), Snapshot: func(*TaskRecord) {}}
+	store.Add(task)
+	store.Finish(task, "the answer", nil)
+	if data, _ := os.ReadFile(filepath.Join(dir, "agent-1.json")); strings.Contains(string(data), "the answer") {
+		t.Errorf("record saved without the codec: %s", data)
+	}
+
+	restored := NewBackgroundTaskStore()
+	restored.SetCodec(reverse, reverse)
+	if err := restored.Open(dir); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := restored.Get("agent-1"); !ok || got.Result != "the answer" {
+		t.Errorf("restored task = %+v, want it decoded", got)
+	}
+	plain := NewBackgroundTaskStore()
+	if err := plain.Open(dir); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(plain.List()); n != 0 {
+		t.Errorf("restored %d tasks without the codec, want them skipped", n)
+	}
+}
+
+func TestSteerTask(t *testing.T) {
+	store := NewBackgroundTaskStore()
+	var got []string
+	agent := &BackgroundTask{ID: "agent-1", Kind: "agent", Done: make(chan struct{}),
+		Steer: func(msg string) bool { got = append(got, msg); return true }}
+	shell := &BackgroundTask{ID: "bash-1", Kind: "bash", Done: make(chan struct{})}
+	store.Add(agent)
+	store.Add(shell)
+
+	out, _ := NewTaskSteerTool(store).Execute(context.Background(), []byte(`{"task_id": "agent-1", "message": "focus on auth"}`))
+	if !strings.Contains(out, `"status":"delivered"`) || len(got) != 1 || got[0] != "focus on auth" {
+		t.Errorf("result = %s, delivered = %v", out, got)
+	}
+	if err := SteerTask(store, "bash-1", "hi"); err == nil || !strings.Contains(err.Error(), "not an agent") {
+		t.Errorf("steering a shell command: %v", err)
+	}
+	if err := SteerTask(store, "nope", "hi"); err == nil {
+		t.Error("expected an error for an unknown task")
+	}
+	store.Finish(agent, "done", nil)
+	if err := SteerTask(store, "agent-1", "hi"); err == nil || !strings.Contains(err.Error(), "resume") {
+		t.Errorf("steering a finished agent: %v", err)
+	}
+}
@@ -1,34 +1,52 @@
 package tools
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
+
+	"github.com/anthropics/claude-code-go/internal/conversation"
 )
 
 const (
 	bashDefaultTimeout = 120 * time.Second
 	bashMaxTimeout     = 600 * time.Second
+	bashMaxOutput      = 100_000
+
+	// bashWaitDelay is how long to wait for output pipes to close after the
+	// process group is killed, in case a child escaped the group.
+	bashWaitDelay = 2 * time.Second
 )
 
 // BashInput is the input schema for the Bash tool.
 type BashInput struct {
-	Command                  string `json:"command"`
-	Description              string `json:"description,omitempty"`
-	Timeout                  *int   `json:"timeout,omitempty"` // milliseconds
-	RunInBackground          *bool  `json:"run_in_background,omitempty"`
-	DangerouslyDisableSandbox *bool `json:"dangerouslyDisableSandbox,omitempty"`
+	Command                   string `json:"command"`
+	Description               string `json:"description,omitempty"`
+	Timeout                   *int   `json:"timeout,omitempty"`    // milliseconds
+	TimeoutMs                 *int   `json:"timeout_ms,omitempty"` // alias for timeout
+	RunInBackground           *bool  `json:"run_in_background,omitempty"`
+	DangerouslyDisableSandbox *bool  `json:"dangerouslyDisableSandbox,omitempty"`
+	Reset                     *bool  `json:"reset,omitempty"` // reset the persistent shell before running
+	TTY                       *bool  `json:"tty,omitempty"`   // nil = use a PTY for known-interactive commands
 }
 
 // BashTool executes shell commands.
 type BashTool struct {
 	workDir string
 	env     map[string]string // additional environment variables from settings
+	shell   *shellSession     // nil = each command starts from a clean shell in workDir
+	bgStore *BackgroundTaskStore
+
+	mu     sync.Mutex
+	nextID int
 }
 
 // NewBashTool creates a Bash tool that runs commands in the given directory.
@@ -41,10 +59,44 @@ func NewBashToolWithEnv(workDir string, env map[string]string) *BashTool {
 	return &BashTool{workDir: workDir, env: env}
 }
 
+// SetPersistentShell enables or disables carrying the working directory,
+// exported variables, and shell functions across calls.
+func (t *BashTool) SetPersistentShell(on bool) {
+	if on && t.shell == nil {
+		t.shell = newShellSession(t.workDir)
+	} else if !on && t.shell != nil {
+		t.shell.Reset()
+		t.shell = nil
+	}
+}
+
+// Close removes the persistent shell's saved state from disk. The tool
+// can still be used afterwards, starting from a clean shell.
+func (t *BashTool) Close() {
+	if t.shell != nil {
+		t.shell.Reset()
+	}
+}
+
+// SetBackgroundStore enables run_in_background, registering background
+// commands in store so TaskOutput and TaskStop can reach them.
+func (t *BashTool) SetBackgroundStore(store *BackgroundTaskStore) {
+	t.bgStore = store
+}
+
+// Cwd returns the directory the next command will run in.
+func (t *BashTool) Cwd() string {
+	if t.shell != nil {
+		return t.shell.Cwd()
+	}
+	return t.workDir
+}
+
 func (t *BashTool) Name() string { return "Bash" }
 
 func (t *BashTool) Description() string {
-	return `Executes a bash command. Use for running shell commands, scripts, installing packages, compiling code, managing files via CLI, or any other terminal task. Commands run in the working directory. Specify an optional timeout in milliseconds (max 600000ms / 10 minutes). Commands timeout after 120000ms (2 minutes) by default.`
+	def, max := t.timeouts()
+	return fmt.Sprintf(`Executes a bash command. Use for running shell commands, scripts, installing packages, compiling code, managing files via CLI, or any other terminal task. Commands run in the working directory. Specify an optional timeout in milliseconds (max %dms). Commands timeout after %dms by default; on timeout the command and its child processes are killed and any output produced so far is returned.`, max.Milliseconds(), def.Milliseconds()) + ` Set run_in_background to start a long-running command without waiting for it; read its output with TaskOutput and stop it with TaskStop.`
 }
 
 func (t *BashTool) InputSchema() json.RawMessage {
@@ -61,7 +113,11 @@ func (t *BashTool) InputSchema() json.RawMessage {
     },
     "timeout": {
       "type": "number",
-      "description": "Optional timeout in milliseconds (max 600000)"
+      "description": "Optional timeout in milliseconds"
+    },
+    "timeout_ms": {
+      "type": "number",
+      "description": "Alias for timeout"
     },
     "run_in_background": {
       "type": "boolean",
@@ -70,6 +126,14 @@ func (t *BashTool) InputSchema() json.RawMessage {
     "dangerouslyDisableSandbox": {
       "type": "boolean",
       "description": "Set to true to override sandbox mode and run without sandboxing"
+    },
+    "reset": {
+      "type": "boolean",
+      "description": "Reset the shell session (working directory, exported variables, functions) before running. May be used without a command."
+    },
+    "tty": {
+      "type": "boolean",
+      "description": "Run the command under a pseudo-terminal. Defaults to true for known-interactive commands (ssh, sudo, editors, pagers) and false otherwise."
     }
   },
   "required": ["command"],
@@ -87,60 +151,254 @@ func (t *BashTool) Execute(ctx context.Context, input json.RawMessage) (string,
 		return "", fmt.Errorf("parsing Bash input: %w", err)
 	}
 
+	reset := in.Reset != nil && *in.Reset
+	if reset && t.shell != nil {
+		t.shell.Reset()
+	}
+
 	if in.Command == "" {
+		if reset {
+			return fmt.Sprintf("Shell session reset. Working directory: %s", t.Cwd()), nil
+		}
 		return "Error: command is required", nil
 	}
 
-	// Determine timeout.
-	timeout := bashDefaultTimeout
-	if in.Timeout != nil {
-		d := time.Duration(*in.Timeout) * time.Millisecond
-		if d > bashMaxTimeout {
-			d = bashMaxTimeout
-		}
-		if d > 0 {
-			timeout = d
-		}
+	tty := needsPTY(in.Command)
+	if in.TTY != nil {
+		tty = *in.TTY
+	}
+
+	if in.RunInBackground != nil && *in.RunInBackground {
+		return t.startBackground(in.Command, tty)
 	}
 
+	timeout := t.timeout(in)
 	cmdCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	cmd := exec.CommandContext(cmdCtx, "bash", "-c", in.Command)
-	cmd.Dir = t.workDir
+	script, dir := in.Command, t.workDir
+	if t.shell != nil {
+		var err error
+		script, dir, err = t.shell.prepare(in.Command, true)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	out := &bashOutput{sink: conversation.ToolOutput(ctx), tty: tty}
+	wait, err := t.start(cmdCtx, script, dir, out)
+	if err != nil {
+		return "", err
+	}
+	err = wait()
+
+	var shellNote string
+	if t.shell != nil {
+		shellNote = t.shell.update()
+	}
+
+	var timedOut time.Duration
+	if cmdCtx.Err() == context.DeadlineExceeded {
+		timedOut = timeout
+	}
+	return formatBashResult(out, shellNote, err, timedOut)
+}
+
+// timeouts returns the default and maximum command timeouts. They can be
+// overridden with BASH_DEFAULT_TIMEOUT_MS and BASH_MAX_TIMEOUT_MS in the
+// settings env block or the process environment.
+func (t *BashTool) timeouts() (def, max time.Duration) {
+	def = t.envMillis("BASH_DEFAULT_TIMEOUT_MS", bashDefaultTimeout)
+	max = t.envMillis("BASH_MAX_TIMEOUT_MS", bashMaxTimeout)
+	if max < def {
+		max = def
+	}
+	return def, max
+}
+
+// timeout returns the timeout for a call: the requested timeout (timeout
+// or timeout_ms) capped at the maximum, or the default.
+func (t *BashTool) timeout(in BashInput) time.Duration {
+	def, max := t.timeouts()
+	requested := in.Timeout
+	if requested == nil {
+		requested = in.TimeoutMs
+	}
+	if requested == nil || *requested <= 0 {
+		return def
+	}
+	d := time.Duration(*requested) * time.Millisecond
+	if d > max {
+		d = max
+	}
+	return d
+}
+
+// envMillis reads a positive millisecond count from the settings env block
+// or the process environment.
+func (t *BashTool) envMillis(name string, fallback time.Duration) time.Duration {
+	v, ok := t.env[name]
+	if !ok {
+		v = os.Getenv(name)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(v))
+	if err != nil || n <= 0 {
+		return fallback
+	}
+	return time.Duration(n) * time.Millisecond
+}
+
+// start launches bash running script, writing its output to out, and
+// returns a function that waits for it to finish. If out.tty is set the
+// command gets a pseudo-terminal as its controlling terminal and stdio.
+func (t *BashTool) start(ctx context.Context, script, dir string, out *bashOutput) (wait func() error, err error) {
+	cmd := exec.CommandContext(ctx, "bash", "-c", script)
+	cmd.Dir = dir
 
 	// Apply environment variables from settings.
-	if len(t.env) > 0 {
+	if len(t.env) > 0 || out.tty {
 		cmd.Env = os.Environ()
 		for k, v := range t.env {
 			cmd.Env = append(cmd.Env, k+"="+v)
 		}
 	}
 
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+	cmd.WaitDelay = bashWaitDelay
+
+	if !out.tty {
+		cmd.Stdout = io.MultiWriter(&out.stdout, out)
+		cmd.Stderr = io.MultiWriter(&out.stderr, out)
+		setProcessGroup(cmd)
+		if err := cmd.Start(); err != nil {
+			return nil, fmt.Errorf("starting command: %w", err)
+		}
+		return cmd.Wait, nil
+	}
+
+	pty, tty, err := openPTY()
+	if err != nil {
+		return nil, fmt.Errorf("opening pseudo-terminal: %w", err)
+	}
+	setPTYSize(pty, ptyRows, ptyCols)
+	// Pagers would wait for a keypress that never comes; TUI programs
+	// need a terminal type to draw.
+	cmd.Env = append(cmd.Env, "PAGER=cat", "GIT_PAGER=cat", "TERM=xterm-256color")
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = tty, tty, tty
+	cmd.SysProcAttr = ptyProcAttr()
+	setProcessGroup(cmd)
+	if err := cmd.Start(); err != nil {
+		pty.Close()
+		tty.Close()
+		return nil, fmt.Errorf("starting command: %w", err)
+	}
+	tty.Close()
+
+	copied := make(chan struct{})
+	go func() {
+		defer close(copied)
+		// Reads fail with EIO once every process has closed the terminal.
+		io.Copy(io.MultiWriter(&out.stdout, out), pty)
+	}()
+
+	return func() error {
+		err := cmd.Wait()
+		// Background children can hold the terminal open; don't wait for
+		// them longer than it takes to drain what bash itself wrote.
+		select {
+		case <-copied:
+		case <-time.After(ptyDrainTimeout):
+		}
+		pty.Close()
+		<-copied
+		return err
+	}, nil
+}
 
-	err := cmd.Run()
+// startBackground launches command without waiting for it. Its output is
+// readable through TaskOutput while it runs, and it can be stopped with
+// TaskStop.
+func (t *BashTool) startBackground(command string, tty bool) (string, error) {
+	if t.bgStore == nil {
+		return "Error: background commands are not available", nil
+	}
+
+	script, dir := command, t.workDir
+	if t.shell != nil {
+		var err error
+		script, dir, err = t.shell.prepare(command, false)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out := newBackgroundOutput(tty)
+	wait, err := t.start(ctx, script, dir, out)
+	if err != nil {
+		cancel()
+		return "", err
+	}
 
+	task := &BackgroundTask{
+		ID:          t.generateID(),
+		Kind:        "bash",
+		Description: command,
+		Ctx:         ctx,
+		Cancel:      cancel,
+		Done:        make(chan struct{}),
+		Output:      out.String,
+	}
+	t.bgStore.Add(task)
+
+	go func() {
+		defer close(task.Done)
+		defer cancel()
+		err := wait()
+		task.Result, task.Err = formatBashResult(out, "", err, 0)
+	}()
+
+	return fmt.Sprintf("Command running in background with ID: %s. Use TaskOutput to read its output and TaskStop to stop it.", task.ID), nil
+}
+
+// generateID returns an ID for a background command.
+func (t *BashTool) generateID() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.nextID++
+	return fmt.Sprintf("bash_%d", t.nextID)
+}
+
+// formatBashResult renders a finished command's output for the model:
+// stdout, then stderr, then any shell note and the exit status. A non-zero
+// timedOut is the timeout the command was killed after; the partial output
+// is kept.
+func formatBashResult(out *bashOutput, shellNote string, err error, timedOut time.Duration) (string, error) {
 	var result strings.Builder
-	if stdout.Len() > 0 {
-		result.Write(stdout.Bytes())
+	if out.tty {
+		result.WriteString(cleanPTYOutput(out.stdout.String()))
+	} else if out.stdout.Len() > 0 {
+		result.WriteString(out.stdout.String())
 	}
-	if stderr.Len() > 0 {
+	if out.stderr.Len() > 0 {
 		if result.Len() > 0 {
 			result.WriteString("\n")
 		}
-		result.WriteString(stderr.String())
+		result.WriteString(out.stderr.String())
+	}
+	if shellNote != "" {
+		if result.Len() > 0 {
+			result.WriteString("\n")
+		}
+		result.WriteString(shellNote)
 	}
 
 	if err != nil {
-		if cmdCtx.Err() == context.DeadlineExceeded {
+		if timedOut > 0 {
 			if result.Len() > 0 {
 				result.WriteString("\n")
 			}
-			result.WriteString("Command timed out")
-			return result.String(), nil
+			result.WriteString(fmt.Sprintf("Command timed out after %dms", timedOut.Milliseconds()))
+			return out.truncate(result.String()), nil
 		}
 
 		if exitErr, ok := err.(*exec.ExitError); ok {
@@ -148,7 +406,7 @@ func (t *BashTool) Execute(ctx context.Context, input json.RawMessage) (string,
 				result.WriteString("\n")
 			}
 			result.WriteString(fmt.Sprintf("Exit code: %d", exitErr.ExitCode()))
-			return result.String(), nil
+			return out.truncate(result.String()), nil
 		}
 
 		return "", fmt.Errorf("executing command: %w", err)
@@ -159,11 +417,113 @@ func (t *BashTool) Execute(ctx context.Context, input json.RawMessage) (string,
 		output = "(no output)"
 	}
 
-	// Truncate very large outputs.
-	const maxOutput = 100_000
-	if len(output) > maxOutput {
-		output = output[:maxOutput] + "\n... (output truncated)"
+	return out.truncate(output), nil
+}
+
+// truncateBashOutput caps very large outputs.
+func truncateBashOutput(output string) string {
+	if len(output) > bashMaxOutput {
+		output = output[:bashMaxOutput] + "\n... (output truncated)"
+	}
+	return output
+}
+
+// truncate caps a result built from the output. A background command's
+// streams are already bounded, keeping their ends, which cutting the
+// result's tail would lose.
+func (o *bashOutput) truncate(result string) string {
+	if o.stdout.limit > 0 {
+		return result
+	}
+	return truncateBashOutput(result)
+}
+
+// bashOutput collects a command's stdout and stderr. Writes to the
+// bashOutput itself receive both streams interleaved as they arrive; they
+// are forwarded to sink (the TUI's live region) and, for background
+// commands, kept so TaskOutput can report progress.
+type bashOutput struct {
+	stdout, stderr tailBuffer // written only by exec's copy goroutines
+
+	tty bool // output came from a pseudo-terminal (stdout holds both streams)
+
+	mu       sync.Mutex
+	sink     func(chunk string)
+	keep     bool
+	combined tailBuffer
+}
+
+// newBackgroundOutput returns the output of a background command. It may
+// run for the rest of the session, so only the most recent output is kept:
+// half of bashMaxOutput for each stream of the result, and bashMaxOutput of
+// both interleaved for TaskOutput while it runs.
+func newBackgroundOutput(tty bool) *bashOutput {
+	return &bashOutput{
+		stdout:   tailBuffer{limit: bashMaxOutput / 2},
+		stderr:   tailBuffer{limit: bashMaxOutput / 2},
+		tty:      tty,
+		keep:     true,
+		combined: tailBuffer{limit: bashMaxOutput},
 	}
+}
 
-	return output, nil
+func (o *bashOutput) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.sink != nil {
+		o.sink(string(p))
+	}
+	if o.keep {
+		o.combined.Write(p)
+	}
+	return len(p), nil
+}
+
+// String returns the interleaved output received so far.
+func (o *bashOutput) String() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.combined.String()
+}
+
+// tailBuffer collects output. With a limit, it keeps only the last limit
+// bytes, so a command that writes for hours can't grow it without bound;
+// the slice is compacted once it holds twice that, so trimming stays cheap.
+type tailBuffer struct {
+	buf     []byte
+	limit   int // 0 = keep everything
+	dropped bool
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.buf = append(b.buf, p...)
+	if b.limit > 0 && len(b.buf) > 2*b.limit {
+		n := copy(b.buf, b.buf[len(b.buf)-b.limit:])
+		b.buf = b.buf[:n]
+		b.dropped = true
+	}
+	return len(p), nil
+}
+
+// Len returns the number of bytes String would return, before any note
+// about dropped output.
+func (b *tailBuffer) Len() int {
+	if b.limit > 0 && len(b.buf) > b.limit {
+		return b.limit
+	}
+	return len(b.buf)
+}
+
+// String returns the output kept, starting with a note if earlier output
+// was dropped.
+func (b *tailBuffer) String() string {
+	data := b.buf[len(b.buf)-b.Len():]
+	if !b.dropped && len(data) == len(b.buf) {
+		return string(data)
+	}
+	// Don't start in the middle of a UTF-8 sequence.
+	for len(data) > 0 && !utf8.RuneStart(data[0]) {
+		data = data[1:]
+	}
+	return "... (earlier output dropped)\n" + string(data)
 }
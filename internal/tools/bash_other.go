@@ -0,0 +1,9 @@
+//go:build !unix
+
+package tools
+
+import "os/exec"
+
+// setProcessGroup is a no-op on non-Unix platforms; cancellation kills only
+// the shell process.
+func setProcessGroup(cmd *exec.Cmd) {}
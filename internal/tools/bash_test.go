@@ -3,8 +3,14 @@ package tools
 import (
 	"context"
 	"encoding/json"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/anthropics/claude-code-go/internal/conversation"
 )
 
 func TestBashTool_SimpleCommand(t *testing.T) {
@@ -111,3 +117,289 @@ func TestBashTool_ContextCancellation(t *testing.T) {
 		t.Log("no error on cancelled context (command may not have started)")
 	}
 }
+
+func runBash(t *testing.T, tool *BashTool, in BashInput) string {
+	t.Helper()
+	input, _ := json.Marshal(in)
+	result, err := tool.Execute(context.Background(), input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return result
+}
+
+func TestBashTool_PersistentShell(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	tool := NewBashTool(dir)
+	tool.SetPersistentShell(true)
+
+	runBash(t, tool, BashInput{Command: "cd sub && export GREETING=hi && greet() { echo \"$GREETING there\"; }"})
+	if got := runBash(t, tool, BashInput{Command: "basename \"$PWD\""}); strings.TrimSpace(got) != "sub" {
+		t.Errorf("cwd not preserved, got %q", got)
+	}
+	if got := runBash(t, tool, BashInput{Command: "greet"}); strings.TrimSpace(got) != "hi there" {
+		t.Errorf("variables/functions not preserved, got %q", got)
+	}
+	if got := tool.Cwd(); filepath.Base(got) != "sub" {
+		t.Errorf("Cwd() = %q, want .../sub", got)
+	}
+
+	// State is saved even when the command exits early.
+	runBash(t, tool, BashInput{Command: "cd .. && exit 3"})
+	if got := tool.Cwd(); got != dir {
+		t.Errorf("Cwd() after exit = %q, want %q", got, dir)
+	}
+
+	runBash(t, tool, BashInput{Command: "cd sub"})
+	reset := true
+	got := runBash(t, tool, BashInput{Reset: &reset})
+	if !strings.Contains(got, "Shell session reset") {
+		t.Errorf("unexpected reset result %q", got)
+	}
+	if got := runBash(t, tool, BashInput{Command: "pwd; echo \"[$GREETING]\""}); !strings.Contains(got, dir+"\n") || !strings.Contains(got, "[]") {
+		t.Errorf("state survived reset: %q", got)
+	}
+}
+
+func TestBashTool_CloseRemovesShellState(t *testing.T) {
+	tool := NewBashTool(t.TempDir())
+	tool.SetPersistentShell(true)
+
+	runBash(t, tool, BashInput{Command: "export GREETING=hi"})
+	stateDir := tool.shell.stateDir
+	if _, err := os.Stat(stateDir); err != nil {
+		t.Fatalf("state directory missing before Close: %v", err)
+	}
+	tool.Close()
+	if _, err := os.Stat(stateDir); !os.IsNotExist(err) {
+		t.Errorf("state directory %s survived Close: %v", stateDir, err)
+	}
+}
+
+func TestBashTool_PersistentShellRemovedDir(t *testing.T) {
+	dir := t.TempDir()
+	tool := NewBashTool(dir)
+	tool.SetPersistentShell(true)
+
+	got := runBash(t, tool, BashInput{Command: "mkdir gone && cd gone && rmdir ../gone"})
+	if !strings.Contains(got, "no longer exists") {
+		t.Errorf("expected note about removed directory, got %q", got)
+	}
+	if tool.Cwd() != dir {
+		t.Errorf("Cwd() = %q, want %q", tool.Cwd(), dir)
+	}
+}
+
+func TestBashTool_NonPersistentShell(t *testing.T) {
+	dir := t.TempDir()
+	tool := NewBashTool(dir)
+	tool.SetPersistentShell(false)
+
+	runBash(t, tool, BashInput{Command: "cd / && export GREETING=hi"})
+	got := runBash(t, tool, BashInput{Command: "pwd; echo \"[$GREETING]\""})
+	if !strings.Contains(got, dir) || !strings.Contains(got, "[]") {
+		t.Errorf("state leaked between calls: %q", got)
+	}
+}
+
+func TestBashTool_StreamsOutput(t *testing.T) {
+	tool := NewBashTool(t.TempDir())
+
+	var mu sync.Mutex
+	var streamed strings.Builder
+	ctx := conversation.WithToolOutput(context.Background(), func(chunk string) {
+		mu.Lock()
+		defer mu.Unlock()
+		streamed.WriteString(chunk)
+	})
+
+	input, _ := json.Marshal(BashInput{Command: "echo one; echo two >&2; echo three"})
+	result, err := tool.Execute(ctx, input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"one", "two", "three"} {
+		if !strings.Contains(streamed.String(), want) {
+			t.Errorf("streamed output missing %q: %q", want, streamed.String())
+		}
+		if !strings.Contains(result, want) {
+			t.Errorf("result missing %q: %q", want, result)
+		}
+	}
+}
+
+func TestBashTool_Background(t *testing.T) {
+	store := NewBackgroundTaskStore()
+	tool := NewBashTool(t.TempDir())
+	tool.SetBackgroundStore(store)
+	output := NewTaskOutputTool(store)
+
+	bg := true
+	started := runBash(t, tool, BashInput{
+		Command:         "echo started; while [ ! -f done ]; do sleep 0.05; done; echo finished",
+		RunInBackground: &bg,
+	})
+	if !strings.Contains(started, "bash_1") {
+		t.Fatalf("expected background task ID, got %q", started)
+	}
+
+	// Output is readable before the command exits.
+	task, _ := store.Get("bash_1")
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(task.Output(), "started") {
+		if time.Now().After(deadline) {
+			t.Fatalf("no partial output, got %q", task.Output())
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	in, _ := json.Marshal(TaskOutputInput{TaskID: "bash_1"})
+	got, _ := output.Execute(context.Background(), in)
+	if !strings.Contains(got, `"running"`) || !strings.Contains(got, "started") {
+		t.Errorf("expected running status with partial output, got %s", got)
+	}
+
+	runBash(t, tool, BashInput{Command: "touch done"})
+	in, _ = json.Marshal(TaskOutputInput{TaskID: "bash_1", Block: true})
+	got, _ = output.Execute(context.Background(), in)
+	if !strings.Contains(got, `"completed"`) || !strings.Contains(got, "finished") {
+		t.Errorf("expected completed status with full output, got %s", got)
+	}
+}
+
+func TestBashTool_BackgroundOutputBounded(t *testing.T) {
+	store := NewBackgroundTaskStore()
+	tool := NewBashTool(t.TempDir())
+	tool.SetBackgroundStore(store)
+
+	bg := true
+	runBash(t, tool, BashInput{
+		Command:         "for i in $(seq 1 50000); do echo \"line $i\"; echo \"err $i\" >&2; done",
+		RunInBackground: &bg,
+	})
+	task, _ := store.Get("bash_1")
+	<-task.Done
+
+	out := task.Output()
+	if len(out) > bashMaxOutput+100 || !strings.HasPrefix(out, "... (earlier output dropped)") || !strings.Contains(out, "line 50000") {
+		t.Errorf("Output() kept %d bytes, want the last %d with a note; ends %q", len(out), bashMaxOutput, out[max(0, len(out)-40):])
+	}
+	if len(task.Result) > bashMaxOutput+200 || strings.Contains(task.Result, "line 1\n") || !strings.Contains(task.Result, "line 50000") || !strings.Contains(task.Result, "err 50000") {
+		t.Errorf("Result should keep the end of each stream, got %d bytes", len(task.Result))
+	}
+}
+
+func TestTailBuffer(t *testing.T) {
+	b := tailBuffer{limit: 4}
+	b.Write([]byte("abc"))
+	if got := b.String(); got != "abc" {
+		t.Errorf("String() = %q, want abc", got)
+	}
+	b.Write([]byte("d\u00e9fgh"))
+	if got := b.String(); got != "... (earlier output dropped)\nfgh" {
+		t.Errorf("String() = %q, want the last 4 bytes on a rune boundary", got)
+	}
+	if len(b.buf) > 2*b.limit {
+		t.Errorf("buffer holds %d bytes, want at most %d", len(b.buf), 2*b.limit)
+	}
+}
+
+func TestBashTool_BackgroundUnavailable(t *testing.T) {
+	tool := NewBashTool(t.TempDir())
+	bg := true
+	got := runBash(t, tool, BashInput{Command: "echo hi", RunInBackground: &bg})
+	if !strings.HasPrefix(got, "Error:") {
+		t.Errorf("expected error without a task store, got %q", got)
+	}
+}
+
+func TestBashTool_PTY(t *testing.T) {
+	if _, err := os.Stat("/dev/ptmx"); err != nil {
+		t.Skip("no pseudo-terminal support")
+	}
+	tool := NewBashTool(t.TempDir())
+
+	on := true
+	got := runBash(t, tool, BashInput{Command: "[ -t 0 ] && [ -t 1 ] && echo tty; printf 'a\\rb\\n'; echo oops >&2", TTY: &on})
+	if got != "tty\nb\noops\n" {
+		t.Errorf("unexpected PTY output %q", got)
+	}
+
+	got = runBash(t, tool, BashInput{Command: "[ -t 1 ] && echo tty || echo notty"})
+	if strings.TrimSpace(got) != "notty" {
+		t.Errorf("expected no PTY by default, got %q", got)
+	}
+
+	// A known-interactive command gets a PTY without asking, and can open
+	// /dev/tty without touching the caller's terminal.
+	got = runBash(t, tool, BashInput{Command: "less --version >/dev/null 2>&1; echo hi > /dev/tty"})
+	if strings.TrimSpace(got) != "hi" {
+		t.Errorf("expected /dev/tty output captured, got %q", got)
+	}
+}
+
+func TestNeedsPTY(t *testing.T) {
+	tests := []struct {
+		command string
+		want    bool
+	}{
+		{"ls -la", false},
+		{"ssh host uptime", true},
+		{"cd /tmp && sudo make install", true},
+		{"git log | less", true},
+		{"TERM=xterm /usr/bin/top -n 1", true},
+		{"echo ssh", false},
+		{"go test ./...", false},
+	}
+	for _, tt := range tests {
+		if got := needsPTY(tt.command); got != tt.want {
+			t.Errorf("needsPTY(%q) = %v, want %v", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestBashTool_TimeoutKillsProcessGroup(t *testing.T) {
+	dir := t.TempDir()
+	tool := NewBashTool(dir)
+
+	timeout := 300
+	start := time.Now()
+	got := runBash(t, tool, BashInput{
+		Command:   "echo before; (sleep 1; touch child-survived) & sleep 30",
+		TimeoutMs: &timeout,
+	})
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("timeout took %v", elapsed)
+	}
+	if !strings.Contains(got, "before") || !strings.Contains(got, "Command timed out after 300ms") {
+		t.Errorf("expected partial output and timeout message, got %q", got)
+	}
+
+	time.Sleep(1500 * time.Millisecond)
+	if _, err := os.Stat(filepath.Join(dir, "child-survived")); err == nil {
+		t.Error("child process survived the timeout")
+	}
+}
+
+func TestBashTool_TimeoutSettings(t *testing.T) {
+	tool := NewBashToolWithEnv(t.TempDir(), map[string]string{
+		"BASH_DEFAULT_TIMEOUT_MS": "200",
+		"BASH_MAX_TIMEOUT_MS":     "400",
+	})
+	if def, max := tool.timeouts(); def != 200*time.Millisecond || max != 400*time.Millisecond {
+		t.Errorf("timeouts() = %v, %v", def, max)
+	}
+	if !strings.Contains(tool.Description(), "max 400ms") {
+		t.Errorf("description doesn't reflect configured max: %s", tool.Description())
+	}
+
+	if got := runBash(t, tool, BashInput{Command: "sleep 10"}); !strings.Contains(got, "timed out after 200ms") {
+		t.Errorf("expected default timeout from settings, got %q", got)
+	}
+	requested := 60_000
+	if got := runBash(t, tool, BashInput{Command: "sleep 10", Timeout: &requested}); !strings.Contains(got, "timed out after 400ms") {
+		t.Errorf("expected requested timeout capped at max, got %q", got)
+	}
+}
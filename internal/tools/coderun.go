@@ -0,0 +1,248 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	codeRunDefaultTimeout = 30 * time.Second
+	codeRunMaxTimeout     = 120 * time.Second
+	codeRunMaxOutput      = 30_000
+	codeRunCPUSeconds     = 60
+	codeRunMemoryMB       = 1024
+	codeRunFileSizeMB     = 64
+)
+
+// CodeRunInput is the input schema for the CodeRun tool.
+type CodeRunInput struct {
+	Language string `json:"language"` // python, node, or go
+	Code     string `json:"code"`
+	Stdin    string `json:"stdin,omitempty"`
+	Timeout  *int   `json:"timeout,omitempty"` // milliseconds
+}
+
+// codeRunLanguage describes how to run a snippet in one language.
+type codeRunLanguage struct {
+	file     string   // name the snippet is saved as
+	programs []string // interpreters to look for, in order of preference
+	args     []string // arguments before the file name
+	env      []string
+	// limitMemory applies the memory limit as an address-space limit.
+	// Runtimes that reserve large address ranges up front set their own
+	// limits in env or args instead.
+	limitMemory bool
+}
+
+var codeRunLanguages = map[string]codeRunLanguage{
+	"python": {
+		file:        "main.py",
+		programs:    []string{"python3", "python"},
+		env:         []string{"PYTHONDONTWRITEBYTECODE=1", "PYTHONIOENCODING=utf-8"},
+		limitMemory: true,
+	},
+	"node": {
+		file:     "main.js",
+		programs: []string{"node"},
+		args:     []string{fmt.Sprintf("--max-old-space-size=%d", codeRunMemoryMB/2)},
+	},
+	"go": {
+		file:     "main.go",
+		programs: []string{"go"},
+		args:     []string{"run"},
+		env: []string{
+			"GOPROXY=off", "GOTOOLCHAIN=local", "GOFLAGS=-mod=mod",
+			fmt.Sprintf("GOMEMLIMIT=%dMiB", codeRunMemoryMB/2),
+		},
+	},
+}
+
+// CodeRunTool runs short Python, Node, or Go snippets in a temporary
+// directory, without network access, unable to write files outside that
+// directory, and with CPU, memory, and file size limits. It is meant for
+// calculations and data transformations that shouldn't touch the project
+// tree. It can still read any file the user can.
+type CodeRunTool struct {
+	lookPath func(string) (string, error)
+}
+
+// NewCodeRunTool creates a new CodeRun tool.
+func NewCodeRunTool() *CodeRunTool {
+	return &CodeRunTool{lookPath: exec.LookPath}
+}
+
+func (t *CodeRunTool) Name() string { return "CodeRun" }
+
+func (t *CodeRunTool) Description() string {
+	return `Runs a short Python, Node.js, or Go program in an empty temporary directory and returns its output. Use this for calculations, data transformations, and checking how a language feature behaves, instead of writing scripts into the project.
+
+- The program has no network access, can't write files outside its temporary directory, and is limited in CPU time, memory, and file size. The temporary directory is deleted afterwards.
+- Only the standard library is available; third-party packages and Go modules cannot be downloaded.
+- Print results to stdout. Data can be passed on stdin with the stdin parameter.
+- Go code must be a complete program with package main and a main function.
+- timeout is in milliseconds (default 30000, max 120000).`
+}
+
+func (t *CodeRunTool) InputSchema() json.RawMessage {
+	return json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "language": {
+      "type": "string",
+      "enum": ["python", "node", "go"],
+      "description": "The language of the code"
+    },
+    "code": {
+      "type": "string",
+      "description": "The program to run"
+    },
+    "stdin": {
+      "type": "string",
+      "description": "Data to pass to the program on stdin"
+    },
+    "timeout": {
+      "type": "number",
+      "description": "Optional timeout in milliseconds (default 30000, max 120000)"
+    }
+  },
+  "required": ["language", "code"],
+  "additionalProperties": false
+}`)
+}
+
+func (t *CodeRunTool) RequiresPermission(_ json.RawMessage) bool {
+	return true // runs arbitrary code, which can read the user's files
+}
+
+func (t *CodeRunTool) Execute(ctx context.Context, input json.RawMessage) (string, error) {
+	var in CodeRunInput
+	if err := json.Unmarshal(input, &in); err != nil {
+		return "", fmt.Errorf("parsing CodeRun input: %w", err)
+	}
+	lang, ok := codeRunLanguages[in.Language]
+	if !ok {
+		return fmt.Sprintf("Error: unsupported language %q (expected python, node, or go)", in.Language), nil
+	}
+	if strings.TrimSpace(in.Code) == "" {
+		return "Error: code is required", nil
+	}
+	program := ""
+	for _, name := range lang.programs {
+		if path, err := t.lookPath(name); err == nil {
+			program = path
+			break
+		}
+	}
+	if program == "" {
+		return fmt.Sprintf("Error: %s is not installed", lang.programs[0]), nil
+	}
+
+	dir, err := os.MkdirTemp("", "coderun-")
+	if err != nil {
+		return "", fmt.Errorf("creating temp dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	if err := os.WriteFile(filepath.Join(dir, lang.file), []byte(in.Code), 0o600); err != nil {
+		return "", fmt.Errorf("writing code: %w", err)
+	}
+
+	args, err := sandbox(codeRunCommand(lang, program), codeRunWritable(dir, in.Language, program))
+	if err != nil {
+		return fmt.Sprintf("Error: cannot run code in a sandbox: %v", err), nil
+	}
+
+	timeout := codeRunDefaultTimeout
+	if in.Timeout != nil && *in.Timeout > 0 {
+		timeout = min(time.Duration(*in.Timeout)*time.Millisecond, codeRunMaxTimeout)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Dir = dir
+	cmd.Env = append(codeRunEnv(dir), lang.env...)
+	cmd.Stdin = strings.NewReader(in.Stdin)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	cmd.WaitDelay = bashWaitDelay
+	setProcessGroup(cmd)
+	err = cmd.Run()
+
+	result := strings.TrimRight(out.String(), "\n")
+	if len(result) > codeRunMaxOutput {
+		n := codeRunMaxOutput
+		for n > 0 && !utf8.RuneStart(result[n]) {
+			n--
+		}
+		result = result[:n] + "\n... (output truncated)"
+	}
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Sprintf("%s\nError: timed out after %s", result, timeout), nil
+		}
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return fmt.Sprintf("Error: running %s: %v", in.Language, err), nil
+		}
+		return strings.TrimLeft(fmt.Sprintf("%s\nExit code: %d", result, exitErr.ExitCode()), "\n"), nil
+	}
+	if result == "" {
+		result = "(no output)"
+	}
+	return result, nil
+}
+
+// codeRunCommand returns the command line that runs a snippet under the
+// resource limits. The limits are set by a bash wrapper that then execs the
+// interpreter, so they apply to it and everything it starts.
+func codeRunCommand(lang codeRunLanguage, program string) []string {
+	limits := fmt.Sprintf("ulimit -t %d -f %d", codeRunCPUSeconds, codeRunFileSizeMB*1024)
+	if lang.limitMemory {
+		limits += fmt.Sprintf(" -v %d", codeRunMemoryMB*1024)
+	}
+	args := []string{"bash", "-c", limits + ` && exec "$@"`, "coderun", program}
+	args = append(args, lang.args...)
+	return append(args, lang.file)
+}
+
+// codeRunWritable returns the directories a snippet may write to: its
+// temporary directory, and for Go the build cache, which go run compiles
+// through. Symlinks are resolved, as the sandboxes match real paths.
+func codeRunWritable(dir, language, program string) []string {
+	dirs := []string{dir}
+	if language == "go" {
+		if out, err := exec.Command(program, "env", "GOCACHE").Output(); err == nil {
+			if cache := strings.TrimSpace(string(out)); filepath.IsAbs(cache) && os.MkdirAll(cache, 0o755) == nil {
+				dirs = append(dirs, cache)
+			}
+		}
+	}
+	for i, d := range dirs {
+		if real, err := filepath.EvalSymlinks(d); err == nil {
+			dirs[i] = real
+		}
+	}
+	return dirs
+}
+
+// codeRunEnv returns a minimal environment for snippets, so they don't see
+// credentials from the user's environment. Temporary files go to dir.
+func codeRunEnv(dir string) []string {
+	env := []string{"TMPDIR=" + dir}
+	for _, key := range []string{"PATH", "HOME", "LANG", "GOROOT", "GOPATH", "GOCACHE"} {
+		if v, ok := os.LookupEnv(key); ok {
+			env = append(env, key+"="+v)
+		}
+	}
+	return env
+}
@@ -0,0 +1,18 @@
+package tools
+
+import (
+	"strconv"
+	"strings"
+)
+
+// sandbox wraps args to run under a sandbox profile that denies network
+// access and file writes outside the writable directories.
+func sandbox(args, writable []string) ([]string, error) {
+	var allowed strings.Builder
+	allowed.WriteString(`(literal "/dev/null") (literal "/dev/tty") (regex #"^/dev/fd/")`)
+	for _, dir := range writable {
+		allowed.WriteString(" (subpath " + strconv.Quote(dir) + ")")
+	}
+	profile := `(version 1)(allow default)(deny network*)(deny file-write* (require-not (require-any ` + allowed.String() + `)))`
+	return append([]string{"/usr/bin/sandbox-exec", "-p", profile}, args...), nil
+}
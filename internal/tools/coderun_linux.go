@@ -0,0 +1,94 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+var (
+	unshareOnce sync.Once
+	unshareErr  error
+)
+
+// sandbox wraps args to run in new network and mount namespaces. The
+// network namespace has only a loopback interface that is down. In the
+// mount namespace every mount is remounted read-only, except bind mounts
+// of the writable directories. unshare -r maps the current user to root
+// in a new user namespace, so no privileges are needed.
+func sandbox(args, writable []string) ([]string, error) {
+	unshareOnce.Do(func() {
+		// User namespaces can be disabled by the kernel or a security
+		// module; find out once rather than failing on every run.
+		if out, err := exec.Command("unshare", "-rnm", "true").CombinedOutput(); err != nil {
+			unshareErr = fmt.Errorf("unshare -rnm failed: %v %s", err, out)
+		}
+	})
+	if unshareErr != nil {
+		return nil, unshareErr
+	}
+	mounts, err := os.ReadFile("/proc/self/mounts")
+	if err != nil {
+		return nil, fmt.Errorf("reading mounts: %w", err)
+	}
+	script := readOnlyMountsScript(string(mounts), writable)
+	return append([]string{"unshare", "-rnm", "--", "bash", "-c", script, "sandbox"}, args...), nil
+}
+
+// readOnlyMountsScript returns a bash script that bind-mounts each
+// writable directory onto itself, remounts every other mount in mounts
+// (in /proc/self/mounts format) read-only, and then execs its arguments.
+// A mount that can't be made read-only fails the script rather than
+// leaving it writable.
+func readOnlyMountsScript(mounts string, writable []string) string {
+	var b strings.Builder
+	b.WriteString("set -e\n")
+	skip := make(map[string]bool)
+	for _, dir := range writable {
+		fmt.Fprintf(&b, "mount --bind %s %s\n", shellQuote(dir), shellQuote(dir))
+		skip[dir] = true
+	}
+	for _, line := range strings.Split(mounts, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		mp := unescapeMountField(fields[1])
+		if skip[mp] {
+			continue
+		}
+		skip[mp] = true
+		// Flags locked by the parent namespace must be kept on remount.
+		opts := "remount,bind,ro"
+		for _, opt := range strings.Split(fields[3], ",") {
+			switch opt {
+			case "nosuid", "nodev", "noexec", "noatime", "nodiratime", "relatime", "strictatime":
+				opts += "," + opt
+			}
+		}
+		fmt.Fprintf(&b, "mount -o %s %s\n", opts, shellQuote(mp))
+	}
+	// Enter the working directory again, which may now be a bind mount.
+	b.WriteString(`cd "$PWD"` + "\n")
+	b.WriteString(`exec "$@"` + "\n")
+	return b.String()
+}
+
+// unescapeMountField undoes the octal escapes (\040 for a space, and so
+// on) in a /proc/self/mounts field.
+func unescapeMountField(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) && isOctal(s[i+1]) && isOctal(s[i+2]) && isOctal(s[i+3]) {
+			b.WriteByte((s[i+1]-'0')<<6 | (s[i+2]-'0')<<3 | (s[i+3] - '0'))
+			i += 3
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func isOctal(c byte) bool { return c >= '0' && c <= '7' }
@@ -0,0 +1,26 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadOnlyMountsScript(t *testing.T) {
+	mounts := "overlay / overlay rw,relatime 0 0\n" +
+		"proc /proc proc rw,nosuid,nodev,noexec,relatime 0 0\n" +
+		"/dev/sda1 /mnt/my\\040disk ext4 rw 0 0\n" +
+		"tmpfs /tmp/work tmpfs rw 0 0\n"
+	got := readOnlyMountsScript(mounts, []string{"/tmp/work"})
+	want := strings.Join([]string{
+		"set -e",
+		"mount --bind '/tmp/work' '/tmp/work'",
+		"mount -o remount,bind,ro,relatime '/'",
+		"mount -o remount,bind,ro,nosuid,nodev,noexec,relatime '/proc'",
+		"mount -o remount,bind,ro '/mnt/my disk'",
+		`cd "$PWD"`,
+		`exec "$@"`,
+	}, "\n") + "\n"
+	if got != want {
+		t.Errorf("script:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !linux && !darwin
+
+package tools
+
+import "errors"
+
+// sandbox is not supported on this platform, so CodeRun refuses to run
+// code rather than run it with network access.
+func sandbox(args, writable []string) ([]string, error) {
+	return nil, errors.New("network isolation is not supported on this platform")
+}
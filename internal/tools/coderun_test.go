@@ -0,0 +1,102 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// runCode runs a snippet, skipping the test if the interpreter or network
+// isolation isn't available.
+func runCode(t *testing.T, in CodeRunInput) string {
+	t.Helper()
+	if _, err := exec.LookPath(codeRunLanguages[in.Language].programs[0]); err != nil {
+		t.Skipf("%s not installed", in.Language)
+	}
+	if _, err := sandbox(nil, nil); err != nil {
+		t.Skipf("network isolation unavailable: %v", err)
+	}
+	input, _ := json.Marshal(in)
+	out, err := NewCodeRunTool().Execute(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	return out
+}
+
+func TestCodeRunPython(t *testing.T) {
+	out := runCode(t, CodeRunInput{
+		Language: "python",
+		Code:     "import os, sys\nprint(sum(int(x) for x in sys.stdin.read().split()))\nprint(os.listdir('.'))",
+		Stdin:    "1 2 3",
+	})
+	if out != "6\n['main.py']" {
+		t.Errorf("got %q", out)
+	}
+}
+
+func TestCodeRunExitCode(t *testing.T) {
+	out := runCode(t, CodeRunInput{Language: "node", Code: "console.error('boom'); process.exit(3)"})
+	if !strings.Contains(out, "boom") || !strings.HasSuffix(out, "Exit code: 3") {
+		t.Errorf("got %q", out)
+	}
+}
+
+func TestCodeRunNoNetwork(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("reached"))
+	}))
+	defer srv.Close()
+
+	out := runCode(t, CodeRunInput{
+		Language: "python",
+		Code:     "import urllib.request\nprint(urllib.request.urlopen('" + srv.URL + "', timeout=2).read().decode())",
+	})
+	if strings.Contains(out, "reached") || !strings.Contains(out, "Exit code: 1") {
+		t.Errorf("expected the request to fail, got %q", out)
+	}
+}
+
+func TestCodeRunNoWritesOutsideTempDir(t *testing.T) {
+	outside := filepath.Join(t.TempDir(), "pwned")
+	out := runCode(t, CodeRunInput{
+		Language: "python",
+		Code:     "open('inside', 'w').write('ok')\nprint('inside ok')\nopen(" + strconv.Quote(outside) + ", 'w').write('pwned')",
+	})
+	if !strings.Contains(out, "inside ok") || !strings.Contains(out, "Exit code: 1") {
+		t.Errorf("expected the write outside the temp dir to fail, got %q", out)
+	}
+	if _, err := os.Stat(outside); !os.IsNotExist(err) {
+		t.Errorf("snippet wrote %s", outside)
+	}
+}
+
+func TestCodeRunTruncatesOnRuneBoundary(t *testing.T) {
+	out := runCode(t, CodeRunInput{Language: "python", Code: "print('a' + 'é' * 20000)"})
+	if !strings.HasSuffix(out, "\n... (output truncated)") || !utf8.ValidString(out) {
+		t.Errorf("got %d bytes ending %q, valid UTF-8: %v", len(out), out[len(out)-30:], utf8.ValidString(out))
+	}
+}
+
+func TestCodeRunTimeout(t *testing.T) {
+	timeout := 500
+	out := runCode(t, CodeRunInput{Language: "python", Code: "while True: pass", Timeout: &timeout})
+	if !strings.Contains(out, "timed out") {
+		t.Errorf("got %q", out)
+	}
+}
+
+func TestCodeRunUnsupportedLanguage(t *testing.T) {
+	out, err := NewCodeRunTool().Execute(context.Background(), json.RawMessage(`{"language":"ruby","code":"puts 1"}`))
+	if err != nil || !strings.HasPrefix(out, "Error: unsupported language") {
+		t.Errorf("got %q, %v", out, err)
+	}
+}
@@ -0,0 +1,301 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	containerDefaultTimeout = 120 * time.Second
+	containerMaxTimeout     = 600 * time.Second
+	containerDefaultTail    = 200
+	containerMaxOutput      = 100_000
+)
+
+// ContainerInput is the input schema for the Container tool.
+type ContainerInput struct {
+	Operation   string            `json:"operation"`             // exec, logs, cp, ps
+	Container   string            `json:"container,omitempty"`   // container name or ID
+	Service     string            `json:"service,omitempty"`     // compose service, instead of container
+	Command     string            `json:"command,omitempty"`     // exec: shell command
+	Workdir     string            `json:"workdir,omitempty"`     // exec: working directory in the container
+	User        string            `json:"user,omitempty"`        // exec: user to run as
+	Env         map[string]string `json:"env,omitempty"`         // exec: extra environment variables
+	Tail        int               `json:"tail,omitempty"`        // logs: number of lines
+	Since       string            `json:"since,omitempty"`       // logs: e.g. 10m or a timestamp
+	Source      string            `json:"source,omitempty"`      // cp: source path
+	Destination string            `json:"destination,omitempty"` // cp: destination path
+	Timeout     *int              `json:"timeout,omitempty"`     // milliseconds
+}
+
+// ContainerTool runs commands in Docker or Podman containers and compose
+// services, reads their logs, and copies files in and out of them.
+type ContainerTool struct {
+	workDir  string
+	lookPath func(string) (string, error)
+}
+
+// NewContainerTool creates a Container tool. Relative host paths and
+// compose projects are resolved against workDir.
+func NewContainerTool(workDir string) *ContainerTool {
+	return &ContainerTool{workDir: workDir, lookPath: exec.LookPath}
+}
+
+func (t *ContainerTool) Name() string { return "Container" }
+
+func (t *ContainerTool) Description() string {
+	return `Works with Docker or Podman containers, or services of the docker compose project in the working directory. Use this instead of running docker exec through Bash.
+
+Operations:
+- exec: runs command with sh -c inside the container (workdir, user, env, and timeout in milliseconds are optional)
+- logs: shows the last lines of output (tail, default 200; since, e.g. "10m")
+- cp: copies a file or directory between the host and the container. Prefix the path inside the container with "container:", e.g. {"source": "container:/app/log.txt", "destination": "log.txt"}
+- ps: lists running containers, or the state of a compose service
+
+Set either container (a name or ID) or service (a compose service name). logs and ps never need approval.`
+}
+
+func (t *ContainerTool) InputSchema() json.RawMessage {
+	return json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "operation": {
+      "type": "string",
+      "enum": ["exec", "logs", "cp", "ps"],
+      "description": "The operation to run"
+    },
+    "container": {
+      "type": "string",
+      "description": "Container name or ID"
+    },
+    "service": {
+      "type": "string",
+      "description": "Compose service name, instead of container"
+    },
+    "command": {
+      "type": "string",
+      "description": "exec: shell command to run in the container"
+    },
+    "workdir": {
+      "type": "string",
+      "description": "exec: working directory inside the container"
+    },
+    "user": {
+      "type": "string",
+      "description": "exec: user to run the command as"
+    },
+    "env": {
+      "type": "object",
+      "additionalProperties": {"type": "string"},
+      "description": "exec: extra environment variables"
+    },
+    "tail": {
+      "type": "number",
+      "description": "logs: number of lines to show (default 200)"
+    },
+    "since": {
+      "type": "string",
+      "description": "logs: only show logs since this time (e.g. 10m, 2024-01-02T15:04:05)"
+    },
+    "source": {
+      "type": "string",
+      "description": "cp: source path; prefix with container: for a path in the container"
+    },
+    "destination": {
+      "type": "string",
+      "description": "cp: destination path; prefix with container: for a path in the container"
+    },
+    "timeout": {
+      "type": "number",
+      "description": "Optional timeout in milliseconds (default 120000, max 600000)"
+    }
+  },
+  "required": ["operation"],
+  "additionalProperties": false
+}`)
+}
+
+// RequiresPermission returns false for operations that only read container
+// state.
+func (t *ContainerTool) RequiresPermission(input json.RawMessage) bool {
+	var in ContainerInput
+	if err := json.Unmarshal(input, &in); err != nil {
+		return true
+	}
+	return in.Operation != "logs" && in.Operation != "ps"
+}
+
+func (t *ContainerTool) Execute(ctx context.Context, input json.RawMessage) (string, error) {
+	var in ContainerInput
+	if err := json.Unmarshal(input, &in); err != nil {
+		return "", fmt.Errorf("parsing Container input: %w", err)
+	}
+
+	runtime := t.runtime()
+	if runtime == "" {
+		return "Error: neither docker nor podman is installed", nil
+	}
+	for _, arg := range []string{in.Container, in.Service, in.User, in.Since} {
+		if strings.HasPrefix(arg, "-") {
+			return fmt.Sprintf("Error: invalid argument %q", arg), nil
+		}
+	}
+
+	args, errMsg := containerArgs(in)
+	if errMsg != "" {
+		return "Error: " + errMsg, nil
+	}
+
+	timeout := containerDefaultTimeout
+	if in.Timeout != nil && *in.Timeout > 0 {
+		timeout = min(time.Duration(*in.Timeout)*time.Millisecond, containerMaxTimeout)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, runtime, args...)
+	cmd.Dir = t.workDir
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out // logs are written to both streams; keep them interleaved
+	err := cmd.Run()
+
+	result := strings.TrimRight(out.String(), "\n")
+	if len(result) > containerMaxOutput {
+		result = result[:containerMaxOutput] + "\n... (output truncated)"
+	}
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Sprintf("%s\nError: command timed out after %s", result, timeout), nil
+		}
+		var exitErr *exec.ExitError
+		if in.Operation == "exec" && errors.As(err, &exitErr) {
+			return fmt.Sprintf("%s\nExit code: %d", result, exitErr.ExitCode()), nil
+		}
+		if result == "" {
+			result = err.Error()
+		}
+		return fmt.Sprintf("Error: %s %s failed: %s", runtime, in.Operation, result), nil
+	}
+	if result == "" {
+		result = "(no output)"
+	}
+	return result, nil
+}
+
+// runtime returns the container CLI to use, preferring docker.
+func (t *ContainerTool) runtime() string {
+	for _, name := range []string{"docker", "podman"} {
+		if _, err := t.lookPath(name); err == nil {
+			return name
+		}
+	}
+	return ""
+}
+
+// containerArgs translates a structured request into runtime arguments. It
+// returns a message instead of arguments if the request is invalid.
+func containerArgs(in ContainerInput) ([]string, string) {
+	if in.Container != "" && in.Service != "" {
+		return nil, "set either container or service, not both"
+	}
+	target := in.Container
+	var prefix []string
+	if in.Service != "" {
+		target = in.Service
+		prefix = []string{"compose"}
+	}
+	needTarget := func() string {
+		if target == "" {
+			return "container or service is required"
+		}
+		return ""
+	}
+
+	switch in.Operation {
+	case "exec":
+		if msg := needTarget(); msg != "" {
+			return nil, msg
+		}
+		if in.Command == "" {
+			return nil, "command is required for exec"
+		}
+		args := append(prefix, "exec")
+		if in.Service != "" {
+			args = append(args, "-T") // compose allocates a TTY by default
+		}
+		if in.Workdir != "" {
+			args = append(args, "--workdir", in.Workdir)
+		}
+		if in.User != "" {
+			args = append(args, "--user", in.User)
+		}
+		for _, k := range sortedKeys(in.Env) {
+			args = append(args, "--env", k+"="+in.Env[k])
+		}
+		return append(args, target, "sh", "-c", in.Command), ""
+
+	case "logs":
+		if msg := needTarget(); msg != "" {
+			return nil, msg
+		}
+		tail := in.Tail
+		if tail <= 0 {
+			tail = containerDefaultTail
+		}
+		args := append(prefix, "logs", "--tail", strconv.Itoa(tail))
+		if in.Service != "" {
+			args = append(args, "--no-color")
+		}
+		if in.Since != "" {
+			args = append(args, "--since", in.Since)
+		}
+		return append(args, target), ""
+
+	case "cp":
+		if msg := needTarget(); msg != "" {
+			return nil, msg
+		}
+		src, srcIn := strings.CutPrefix(in.Source, "container:")
+		dst, dstIn := strings.CutPrefix(in.Destination, "container:")
+		if src == "" || dst == "" {
+			return nil, "source and destination are required for cp"
+		}
+		if srcIn == dstIn {
+			return nil, `exactly one of source and destination must be a "container:" path`
+		}
+		if srcIn {
+			src = target + ":" + src
+		} else {
+			dst = target + ":" + dst
+		}
+		return append(prefix, "cp", "--", src, dst), ""
+
+	case "ps":
+		if in.Service != "" {
+			return []string{"compose", "ps", in.Service}, ""
+		}
+		return []string{"ps", "--format", "table {{.Names}}\t{{.Image}}\t{{.Status}}\t{{.Ports}}"}, ""
+
+	case "":
+		return nil, "operation is required"
+	}
+	return nil, fmt.Sprintf("unknown operation %q", in.Operation)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
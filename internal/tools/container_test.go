@@ -0,0 +1,94 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestContainerArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		in   ContainerInput
+		want []string
+	}{
+		{"exec", ContainerInput{Operation: "exec", Container: "web", Command: "ls -la", Workdir: "/app", Env: map[string]string{"B": "2", "A": "1"}},
+			[]string{"exec", "--workdir", "/app", "--env", "A=1", "--env", "B=2", "web", "sh", "-c", "ls -la"}},
+		{"exec service", ContainerInput{Operation: "exec", Service: "db", Command: "psql", User: "postgres"},
+			[]string{"compose", "exec", "-T", "--user", "postgres", "db", "sh", "-c", "psql"}},
+		{"logs", ContainerInput{Operation: "logs", Container: "web", Since: "10m"},
+			[]string{"logs", "--tail", "200", "--since", "10m", "web"}},
+		{"logs service", ContainerInput{Operation: "logs", Service: "web", Tail: 50},
+			[]string{"compose", "logs", "--tail", "50", "--no-color", "web"}},
+		{"cp out", ContainerInput{Operation: "cp", Container: "web", Source: "container:/app/log.txt", Destination: "log.txt"},
+			[]string{"cp", "--", "web:/app/log.txt", "log.txt"}},
+		{"cp in", ContainerInput{Operation: "cp", Service: "web", Source: "conf.yml", Destination: "container:/etc/conf.yml"},
+			[]string{"compose", "cp", "--", "conf.yml", "web:/etc/conf.yml"}},
+		{"ps service", ContainerInput{Operation: "ps", Service: "web"},
+			[]string{"compose", "ps", "web"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, errMsg := containerArgs(tt.in)
+			if errMsg != "" {
+				t.Fatalf("unexpected error: %s", errMsg)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContainerArgs_Invalid(t *testing.T) {
+	tests := []struct {
+		in   ContainerInput
+		want string
+	}{
+		{ContainerInput{Operation: "exec", Command: "ls"}, "container or service is required"},
+		{ContainerInput{Operation: "exec", Container: "web"}, "command is required for exec"},
+		{ContainerInput{Operation: "logs", Container: "a", Service: "b"}, "set either container or service, not both"},
+		{ContainerInput{Operation: "cp", Container: "web", Source: "a", Destination: "b"}, `exactly one of source and destination must be a "container:" path`},
+		{ContainerInput{Operation: "run"}, `unknown operation "run"`},
+	}
+	for _, tt := range tests {
+		if _, got := containerArgs(tt.in); got != tt.want {
+			t.Errorf("containerArgs(%+v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestContainerTool_Execute(t *testing.T) {
+	argsFile := fakeCommand(t, "docker", "hello from container\n")
+	tool := NewContainerTool(t.TempDir())
+
+	input, _ := json.Marshal(ContainerInput{Operation: "exec", Container: "web", Command: "echo hi"})
+	result, err := tool.Execute(context.Background(), input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != "hello from container" {
+		t.Errorf("unexpected result %q", result)
+	}
+	args, _ := os.ReadFile(argsFile)
+	if got := strings.Fields(string(args)); !reflect.DeepEqual(got, []string{"exec", "web", "sh", "-c", "echo", "hi"}) {
+		t.Errorf("unexpected args %q", got)
+	}
+
+	input, _ = json.Marshal(ContainerInput{Operation: "exec", Container: "--privileged", Command: "id"})
+	if result, _ := tool.Execute(context.Background(), input); result != `Error: invalid argument "--privileged"` {
+		t.Errorf("expected flag injection to be rejected, got %q", result)
+	}
+}
+
+func TestContainerTool_RequiresPermission(t *testing.T) {
+	tool := NewContainerTool("")
+	for op, want := range map[string]bool{"exec": true, "cp": true, "logs": false, "ps": false} {
+		if got := tool.RequiresPermission(json.RawMessage(`{"operation":"` + op + `"}`)); got != want {
+			t.Errorf("RequiresPermission(%s) = %v, want %v", op, got, want)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package tools
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	downloadDefaultTimeout = 5 * time.Minute
+	downloadMaxTimeout     = 30 * time.Minute
+	downloadDefaultMaxSize = 100 << 20 // bytes
+	downloadMaxSize        = 2 << 30
+)
+
+// DownloadFileInput is the input schema for the DownloadFile tool.
+type DownloadFileInput struct {
+	URL         string `json:"url"`
+	Path        string `json:"path"`                   // absolute destination path
+	SHA256      string `json:"sha256,omitempty"`       // expected hex digest
+	ContentType string `json:"content_type,omitempty"` // expected media type, e.g. "application/gzip" or "application/*"
+	MaxBytes    int64  `json:"max_bytes,omitempty"`
+	Overwrite   bool   `json:"overwrite,omitempty"`
+	Timeout     *int   `json:"timeout,omitempty"` // seconds
+}
+
+// DownloadFileTool saves a URL to a file, checking its size, content type,
+// and optionally its SHA-256 digest before the file appears at the
+// destination. Permission rules match the URL's domain, as for WebFetch;
+// the destination is also checked against the file editing rules, and
+// must be in a working directory to be allowed without asking.
+type DownloadFileTool struct {
+	httpClient *http.Client
+}
+
+// NewDownloadFileTool creates a new DownloadFile tool. If httpClient is nil,
+// a default client is used.
+func NewDownloadFileTool(httpClient *http.Client) *DownloadFileTool {
+	if httpClient == nil {
+		httpClient = &http.Client{}
+	}
+	return &DownloadFileTool{httpClient: httpClient}
+}
+
+func (t *DownloadFileTool) Name() string { return "DownloadFile" }
+
+func (t *DownloadFileTool) Description() string {
+	return `Downloads a URL to a file. Use this for release artifacts, archives, and other files instead of running curl -o or wget through Bash.
+
+- path must be an absolute path. Existing files are not replaced unless overwrite is true.
+- Pass sha256 whenever a checksum is published; the download fails and nothing is written if it doesn't match.
+- content_type checks the response's media type, e.g. "application/gzip" or "application/*". Without it, HTML responses are rejected unless path ends in .html, since they are usually error or login pages.
+- max_bytes caps the download size (default 100 MB, max 2 GB).
+- timeout is in seconds (default 300, max 1800).`
+}
+
+func (t *DownloadFileTool) InputSchema() json.RawMessage {
+	return json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "url": {
+      "type": "string",
+      "description": "The http or https URL to download",
+      "format": "uri"
+    },
+    "path": {
+      "type": "string",
+      "description": "The absolute path to save the file to"
+    },
+    "sha256": {
+      "type": "string",
+      "description": "Expected SHA-256 digest of the file, in hex"
+    },
+    "content_type": {
+      "type": "string",
+      "description": "Expected media type, e.g. \"application/zip\" or \"application/*\""
+    },
+    "max_bytes": {
+      "type": "integer",
+      "description": "Maximum download size in bytes (default 100 MB, max 2 GB)"
+    },
+    "overwrite": {
+      "type": "boolean",
+      "description": "Replace the file if it already exists"
+    },
+    "timeout": {
+      "type": "integer",
+      "description": "Timeout in seconds (default 300, max 1800)"
+    }
+  },
+  "required": ["url", "path"],
+  "additionalProperties": false
+}`)
+}
+
+func (t *DownloadFileTool) RequiresPermission(_ json.RawMessage) bool {
+	return true // network access and a file write
+}
+
+func (t *DownloadFileTool) Execute(ctx context.Context, input json.RawMessage) (string, error) {
+	var in DownloadFileInput
+	if err := json.Unmarshal(input, &in); err != nil {
+		return "", fmt.Errorf("parsing DownloadFile input: %w", err)
+	}
+
+	target, err := url.Parse(in.URL)
+	if err != nil || target.Host == "" || (target.Scheme != "http" && target.Scheme != "https") {
+		return fmt.Sprintf("Error: invalid URL %q: must be an absolute http or https URL", in.URL), nil
+	}
+	if in.Path == "" {
+		return "Error: path is required", nil
+	}
+	if !filepath.IsAbs(in.Path) {
+		return "Error: path must be an absolute path", nil
+	}
+	if info, err := os.Stat(in.Path); err == nil {
+		if info.IsDir() {
+			return fmt.Sprintf("Error: %s is a directory", in.Path), nil
+		}
+		if !in.Overwrite {
+			return fmt.Sprintf("Error: %s already exists; set overwrite to replace it", in.Path), nil
+		}
+	}
+	wantSum := strings.ToLower(strings.TrimSpace(in.SHA256))
+	if wantSum != "" {
+		if b, err := hex.DecodeString(wantSum); err != nil || len(b) != sha256.Size {
+			return fmt.Sprintf("Error: sha256 must be %d hex characters", 2*sha256.Size), nil
+		}
+	}
+	maxBytes := int64(downloadDefaultMaxSize)
+	if in.MaxBytes > 0 {
+		maxBytes = min(in.MaxBytes, downloadMaxSize)
+	}
+
+	timeout := downloadDefaultTimeout
+	if in.Timeout != nil && *in.Timeout > 0 {
+		timeout = min(time.Duration(*in.Timeout)*time.Second, downloadMaxTimeout)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return fmt.Sprintf("Error creating request: %v", err), nil
+	}
+	req.Header.Set("User-Agent", "ClaudeCode/1.0")
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return fmt.Sprintf("Error: request failed: %v", err), nil
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Sprintf("Error: %s returned %s", resp.Request.URL, resp.Status), nil
+	}
+	if resp.ContentLength > maxBytes {
+		return fmt.Sprintf("Error: download is %d bytes, over the %d byte limit", resp.ContentLength, maxBytes), nil
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if msg := checkDownloadContentType(mediaType, in.ContentType, in.Path); msg != "" {
+		return "Error: " + msg, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(in.Path), 0755); err != nil {
+		return fmt.Sprintf("Error creating directories: %v", err), nil
+	}
+	f, err := os.CreateTemp(filepath.Dir(in.Path), "."+filepath.Base(in.Path)+".download*")
+	if err != nil {
+		return fmt.Sprintf("Error creating file: %v", err), nil
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp) // no-op after a successful rename
+
+	// Read one byte past the limit to tell whether the body was cut off.
+	hash := sha256.New()
+	n, err := io.Copy(io.MultiWriter(f, hash), io.LimitReader(resp.Body, maxBytes+1))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Sprintf("Error downloading %s: %v", in.URL, err), nil
+	}
+	if n > maxBytes {
+		return fmt.Sprintf("Error: download exceeds the %d byte limit", maxBytes), nil
+	}
+	sum := hex.EncodeToString(hash.Sum(nil))
+	if wantSum != "" && sum != wantSum {
+		return fmt.Sprintf("Error: SHA-256 mismatch for %s: expected %s, got %s. The file was not saved.", in.URL, wantSum, sum), nil
+	}
+	if err := os.Chmod(tmp, 0644); err != nil {
+		return fmt.Sprintf("Error writing file: %v", err), nil
+	}
+	if err := os.Rename(tmp, in.Path); err != nil {
+		return fmt.Sprintf("Error writing file: %v", err), nil
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "Downloaded %s to %s (%d bytes", resp.Request.URL, in.Path, n)
+	if mediaType != "" {
+		out.WriteString(", " + mediaType)
+	}
+	fmt.Fprintf(&out, ").\nSHA-256: %s", sum)
+	if wantSum != "" {
+		out.WriteString(" (verified)")
+	}
+	return out.String(), nil
+}
+
+// checkDownloadContentType returns an error message if the response media
+// type doesn't match the expected pattern, or, with no expectation, if an
+// HTML page came back for a file that isn't HTML.
+func checkDownloadContentType(mediaType, want, dest string) string {
+	if want != "" {
+		if ok, _ := path.Match(strings.ToLower(want), mediaType); !ok {
+			return fmt.Sprintf("expected content type %s, got %q", want, mediaType)
+		}
+		return ""
+	}
+	if mediaType == "text/html" || mediaType == "application/xhtml+xml" {
+		switch strings.ToLower(filepath.Ext(dest)) {
+		case ".html", ".htm", ".xhtml":
+			return ""
+		}
+		return "server returned an HTML page, which is usually an error or login page; set content_type to \"text/html\" if this is expected"
+	}
+	return ""
+}
@@ -0,0 +1,84 @@
+package tools
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func runDownload(t *testing.T, in DownloadFileInput) string {
+	t.Helper()
+	input, _ := json.Marshal(in)
+	result, err := NewDownloadFileTool(nil).Execute(context.Background(), input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return result
+}
+
+func TestDownloadFile(t *testing.T) {
+	payload := strings.Repeat("artifact", 100)
+	sum := sha256.Sum256([]byte(payload))
+	digest := hex.EncodeToString(sum[:])
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/app.tar.gz":
+			w.Header().Set("Content-Type", "application/gzip")
+			w.Write([]byte(payload))
+		case "/login":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte("<html>sign in</html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	dir := t.TempDir()
+
+	dest := filepath.Join(dir, "out", "app.tar.gz")
+	result := runDownload(t, DownloadFileInput{URL: srv.URL + "/app.tar.gz", Path: dest, SHA256: strings.ToUpper(digest)})
+	if !strings.Contains(result, "800 bytes, application/gzip") || !strings.Contains(result, digest+" (verified)") {
+		t.Errorf("unexpected result:\n%s", result)
+	}
+	if data, _ := os.ReadFile(dest); string(data) != payload {
+		t.Error("downloaded file content mismatch")
+	}
+
+	tests := []struct {
+		name string
+		in   DownloadFileInput
+		want string
+	}{
+		{"exists", DownloadFileInput{URL: srv.URL + "/app.tar.gz", Path: dest}, "already exists"},
+		{"relative path", DownloadFileInput{URL: srv.URL + "/app.tar.gz", Path: "app.tar.gz"}, "must be an absolute path"},
+		{"checksum mismatch", DownloadFileInput{URL: srv.URL + "/app.tar.gz", Path: filepath.Join(dir, "bad"), SHA256: strings.Repeat("0", 64)}, "SHA-256 mismatch"},
+		{"too large", DownloadFileInput{URL: srv.URL + "/app.tar.gz", Path: filepath.Join(dir, "big"), MaxBytes: 100}, "over the 100 byte limit"},
+		{"content type", DownloadFileInput{URL: srv.URL + "/app.tar.gz", Path: filepath.Join(dir, "zip"), ContentType: "application/zip"}, "expected content type application/zip"},
+		{"html page", DownloadFileInput{URL: srv.URL + "/login", Path: filepath.Join(dir, "app.zip")}, "HTML page"},
+		{"not found", DownloadFileInput{URL: srv.URL + "/missing", Path: filepath.Join(dir, "missing")}, "404 Not Found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := runDownload(t, tt.in); !strings.HasPrefix(result, "Error:") || !strings.Contains(result, tt.want) {
+				t.Errorf("got %q, want error containing %q", result, tt.want)
+			}
+		})
+	}
+	for _, name := range []string{"bad", "big", "zip", "app.zip", "missing"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			t.Errorf("%s should not have been written", name)
+		}
+	}
+
+	if result := runDownload(t, DownloadFileInput{URL: srv.URL + "/app.tar.gz", Path: dest, Overwrite: true, ContentType: "application/*"}); strings.HasPrefix(result, "Error") {
+		t.Errorf("overwrite failed: %s", result)
+	}
+}
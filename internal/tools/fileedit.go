@@ -14,20 +14,35 @@ type FileEditInput struct {
 	OldString  string `json:"old_string"`
 	NewString  string `json:"new_string"`
 	ReplaceAll bool   `json:"replace_all,omitempty"`
+	Strict     bool   `json:"strict,omitempty"` // disable whitespace-tolerant matching
 }
 
 // FileEditTool performs exact string replacements in files.
-type FileEditTool struct{}
+type FileEditTool struct {
+	undo    *UndoStore
+	tracker *ReadTracker
+}
 
 // NewFileEditTool creates a new FileEdit tool.
 func NewFileEditTool() *FileEditTool {
 	return &FileEditTool{}
 }
 
+// SetUndoStore records each edit in store so it can be undone.
+func (t *FileEditTool) SetUndoStore(store *UndoStore) {
+	t.undo = store
+}
+
+// SetReadTracker makes edits fail if the file changed on disk since it was
+// last read.
+func (t *FileEditTool) SetReadTracker(tracker *ReadTracker) {
+	t.tracker = tracker
+}
+
 func (t *FileEditTool) Name() string { return "FileEdit" }
 
 func (t *FileEditTool) Description() string {
-	return `Performs exact string replacements in files. The old_string must be unique in the file unless replace_all is true. The new_string must be different from old_string. Use this tool for making targeted edits to existing files.`
+	return `Performs exact string replacements in files. The old_string must be unique in the file unless replace_all is true. The new_string must be different from old_string. Use this tool for making targeted edits to existing files. If old_string doesn't match exactly, a unique match that differs only in whitespace or line endings is used, with new_string re-indented to fit; set strict to disable this. Edits are refused if the file changed on disk since you last read it; read it again first.`
 }
 
 func (t *FileEditTool) InputSchema() json.RawMessage {
@@ -50,6 +65,11 @@ func (t *FileEditTool) InputSchema() json.RawMessage {
       "type": "boolean",
       "description": "Replace all occurrences of old_string (default false)",
       "default": false
+    },
+    "strict": {
+      "type": "boolean",
+      "description": "Require old_string to match exactly, disabling whitespace-tolerant and approximate matching (default false)",
+      "default": false
     }
   },
   "required": ["file_path", "old_string", "new_string"],
@@ -73,6 +93,13 @@ func (t *FileEditTool) Execute(_ context.Context, input json.RawMessage) (string
 	if in.OldString == in.NewString {
 		return "Error: new_string must be different from old_string", nil
 	}
+	if strings.Contains(in.NewString, RedactionMarkerPrefix) && !strings.Contains(in.OldString, RedactionMarkerPrefix) {
+		return redactedWriteError, nil
+	}
+
+	if msg := t.tracker.CheckStale(in.FilePath); msg != "" {
+		return msg, nil
+	}
 
 	// Read the file.
 	data, err := os.ReadFile(in.FilePath)
@@ -87,8 +114,20 @@ func (t *FileEditTool) Execute(_ context.Context, input json.RawMessage) (string
 
 	// Check that old_string exists.
 	count := strings.Count(content, in.OldString)
-	if count == 0 {
-		return fmt.Sprintf("Error: old_string not found in %s. Make sure the string matches exactly, including whitespace and indentation.", in.FilePath), nil
+	var fuzzy *editMatch
+	if count == 0 && !in.Strict {
+		var candidates int
+		fuzzy, candidates = findFuzzyMatch(content, in.OldString, in.NewString)
+		if fuzzy == nil && candidates > 1 {
+			return fmt.Sprintf("Error: old_string not found exactly in %s, and it loosely matches %d places. Provide more surrounding context to make it unique.", in.FilePath, candidates), nil
+		}
+	}
+	if count == 0 && fuzzy == nil {
+		msg := fmt.Sprintf("Error: old_string not found in %s. Make sure the string matches exactly, including whitespace and indentation.", in.FilePath)
+		if hint := closestMatch(content, in.OldString); hint != "" {
+			msg += "\n\n" + hint
+		}
+		return msg, nil
 	}
 
 	// If not replace_all, verify uniqueness.
@@ -98,7 +137,9 @@ func (t *FileEditTool) Execute(_ context.Context, input json.RawMessage) (string
 
 	// Perform replacement.
 	var newContent string
-	if in.ReplaceAll {
+	if fuzzy != nil {
+		newContent = content[:fuzzy.start] + fuzzy.replacement + content[fuzzy.end:]
+	} else if in.ReplaceAll {
 		newContent = strings.ReplaceAll(content, in.OldString, in.NewString)
 	} else {
 		newContent = strings.Replace(content, in.OldString, in.NewString, 1)
@@ -110,10 +151,17 @@ func (t *FileEditTool) Execute(_ context.Context, input json.RawMessage) (string
 		return fmt.Sprintf("Error: %v", err), nil
 	}
 
-	if err := os.WriteFile(in.FilePath, []byte(newContent), info.Mode().Perm()); err != nil {
+	if err := t.undo.Snapshot(in.FilePath, t.Name()); err != nil {
+		return fmt.Sprintf("Error saving undo state: %v", err), nil
+	}
+	if err := writeFileAtomic(in.FilePath, []byte(newContent), info.Mode().Perm()); err != nil {
 		return fmt.Sprintf("Error writing file: %v", err), nil
 	}
+	t.tracker.RecordCurrent(in.FilePath)
 
+	if fuzzy != nil {
+		return fmt.Sprintf("Successfully edited %s (old_string matched %s).", in.FilePath, fuzzy.strategy), nil
+	}
 	if in.ReplaceAll {
 		return fmt.Sprintf("Replaced %d occurrences in %s.", count, in.FilePath), nil
 	}
@@ -0,0 +1,315 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// fuzzyMinSimilarity is how similar the lines of an anchored match must
+	// be to old_string.
+	fuzzyMinSimilarity = 0.75
+
+	// closestMinSimilarity is the similarity below which no closest match
+	// is suggested.
+	closestMinSimilarity = 0.5
+
+	// closestMaxWork bounds the line comparisons spent looking for a
+	// closest match in large files.
+	closestMaxWork = 2_000_000
+)
+
+// editMatch is a region of a file matched by a non-exact old_string, and the
+// text to replace it with.
+type editMatch struct {
+	start, end  int
+	replacement string
+	strategy    string // how old_string was matched, for the result message
+}
+
+// lineSpan is the byte range of one line, excluding its newline.
+type lineSpan struct{ start, end int }
+
+func splitLineSpans(content string) []lineSpan {
+	var spans []lineSpan
+	start := 0
+	for i := 0; i < len(content); i++ {
+		if content[i] == '\n' {
+			spans = append(spans, lineSpan{start, i})
+			start = i + 1
+		}
+	}
+	if start < len(content) {
+		spans = append(spans, lineSpan{start, len(content)})
+	}
+	return spans
+}
+
+// findFuzzyMatch looks for old_string in content when it doesn't occur
+// verbatim. It tries, in order: the file's CRLF line endings; line-by-line
+// matching with whitespace normalized; and an anchored match whose first and
+// last lines agree and whose body is similar. A strategy only succeeds if it
+// matches exactly one place. If the best strategy is ambiguous, the number
+// of candidates is returned instead.
+func findFuzzyMatch(content, oldStr, newStr string) (*editMatch, int) {
+	if strings.Contains(content, "\r\n") && !strings.Contains(oldStr, "\r") {
+		crlfOld := strings.ReplaceAll(oldStr, "\n", "\r\n")
+		switch n := strings.Count(content, crlfOld); n {
+		case 0:
+		case 1:
+			start := strings.Index(content, crlfOld)
+			return &editMatch{
+				start:       start,
+				end:         start + len(crlfOld),
+				replacement: strings.ReplaceAll(newStr, "\n", "\r\n"),
+				strategy:    "with CRLF line endings",
+			}, 1
+		default:
+			return nil, n
+		}
+	}
+
+	oldLines := strings.Split(strings.TrimSuffix(oldStr, "\n"), "\n")
+	if strings.TrimSpace(oldStr) == "" {
+		return nil, 0
+	}
+	spans := splitLineSpans(content)
+	fileLines := make([]string, len(spans))
+	for i, s := range spans {
+		fileLines[i] = strings.TrimSuffix(content[s.start:s.end], "\r")
+	}
+	normOld := normalizeLines(oldLines)
+	normFile := normalizeLines(fileLines)
+
+	// Whitespace-normalized line match.
+	var found []int
+	for i := 0; i+len(normOld) <= len(normFile); i++ {
+		if equalLines(normFile[i:i+len(normOld)], normOld) {
+			found = append(found, i)
+		}
+	}
+	if len(found) == 1 {
+		i := found[0]
+		return lineMatch(content, spans, fileLines, i, i+len(oldLines)-1, oldStr, oldLines, newStr,
+			"after normalizing whitespace"), 1
+	}
+	if len(found) > 1 {
+		return nil, len(found)
+	}
+
+	// Anchored match: same first and last lines, similar body.
+	n := len(normOld)
+	if n < 3 || normOld[0] == "" || normOld[n-1] == "" {
+		return nil, 0
+	}
+	slack := max(1, n/4)
+	type candidate struct {
+		first, last int
+		score       float64
+	}
+	var candidates []candidate
+	for i := range normFile {
+		if normFile[i] != normOld[0] {
+			continue
+		}
+		best := candidate{first: -1}
+		for j := i + n - 1 - slack; j <= i+n-1+slack && j < len(normFile); j++ {
+			if j <= i || normFile[j] != normOld[n-1] {
+				continue
+			}
+			score := lineSimilarity(normOld, normFile[i:j+1])
+			if score >= fuzzyMinSimilarity && score > best.score {
+				best = candidate{i, j, score}
+			}
+		}
+		if best.first >= 0 {
+			candidates = append(candidates, best)
+		}
+	}
+	if len(candidates) != 1 {
+		return nil, len(candidates)
+	}
+	c := candidates[0]
+	return lineMatch(content, spans, fileLines, c.first, c.last, oldStr, oldLines, newStr,
+		fmt.Sprintf("approximately (%.0f%% similar)", c.score*100)), 1
+}
+
+// lineMatch builds an editMatch replacing file lines first..last with
+// new_string, re-indented from old_string's indentation to the file's.
+func lineMatch(content string, spans []lineSpan, fileLines []string, first, last int, oldStr string, oldLines []string, newStr, strategy string) *editMatch {
+	m := &editMatch{
+		start:    spans[first].start,
+		end:      spans[last].end,
+		strategy: strategy,
+	}
+	// Keep a CR that ends the last line.
+	if strings.HasSuffix(content[m.start:m.end], "\r") {
+		m.end--
+	}
+
+	oldIndent, fileIndent := "", ""
+	for k, l := range oldLines {
+		if strings.TrimSpace(l) != "" && first+k <= last {
+			oldIndent = leadingWhitespace(l)
+			fileIndent = leadingWhitespace(fileLines[first+k])
+			break
+		}
+	}
+	newLines := strings.Split(strings.TrimSuffix(newStr, "\n"), "\n")
+	for k, l := range newLines {
+		if strings.TrimSpace(l) != "" {
+			newLines[k] = reindent(l, oldIndent, fileIndent)
+		}
+	}
+	m.replacement = strings.Join(newLines, "\n")
+
+	// The match covers whole lines without their final newline; keep the
+	// newline semantics an exact replacement would have had.
+	oldNL, newNL := strings.HasSuffix(oldStr, "\n"), strings.HasSuffix(newStr, "\n")
+	switch {
+	case oldNL && !newNL && spans[last].end < len(content):
+		m.end = spans[last].end + 1
+	case !oldNL && newNL:
+		m.replacement += "\n"
+	}
+	return m
+}
+
+// closestMatch finds the run of file lines most similar to old_string and
+// renders a line diff against it, or returns "" if nothing is close.
+func closestMatch(content, oldStr string) string {
+	oldLines := strings.Split(strings.TrimSuffix(oldStr, "\n"), "\n")
+	fileLines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	n := len(oldLines)
+	if n > len(fileLines) || n*len(fileLines) > closestMaxWork {
+		return ""
+	}
+	normOld := normalizeLines(oldLines)
+	normFile := normalizeLines(fileLines)
+
+	bestStart, bestScore := -1, 0.0
+	for i := 0; i+n <= len(normFile); i++ {
+		score := 0.0
+		for k := range normOld {
+			score += diceSimilarity(normOld[k], normFile[i+k])
+		}
+		if score /= float64(n); score > bestScore {
+			bestStart, bestScore = i, score
+		}
+	}
+	if bestStart < 0 || bestScore < closestMinSimilarity {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Closest match at lines %d-%d (%.0f%% similar; - old_string, + file):\n",
+		bestStart+1, bestStart+n, bestScore*100)
+	for k, old := range oldLines {
+		line := fileLines[bestStart+k]
+		if old == line {
+			fmt.Fprintf(&b, "  %s\n", line)
+		} else {
+			fmt.Fprintf(&b, "- %s\n+ %s\n", visibleWhitespace(old), visibleWhitespace(line))
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// visibleWhitespace shows tabs and trailing spaces, since differences in
+// them are otherwise invisible in a diff.
+func visibleWhitespace(s string) string {
+	trimmed := strings.TrimRight(s, " ")
+	s = trimmed + strings.Repeat("·", len(s)-len(trimmed))
+	return strings.ReplaceAll(s, "\t", "→   ")
+}
+
+func normalizeLines(lines []string) []string {
+	out := make([]string, len(lines))
+	for i, l := range lines {
+		out[i] = strings.Join(strings.Fields(l), " ")
+	}
+	return out
+}
+
+func equalLines(a, b []string) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// reindent moves a line of new_string from old_string's indentation to the
+// file's. When one uses spaces and the other tabs, deeper indentation is
+// converted at the same ratio.
+func reindent(line, oldIndent, fileIndent string) string {
+	ws := leadingWhitespace(line)
+	body := line[len(ws):]
+	spacesToTabs := oldIndent != "" && fileIndent != "" &&
+		strings.Trim(oldIndent, " ") == "" && strings.Trim(fileIndent, "\t") == "" &&
+		len(oldIndent)%len(fileIndent) == 0
+	tabsToSpaces := oldIndent != "" && fileIndent != "" &&
+		strings.Trim(oldIndent, "\t") == "" && strings.Trim(fileIndent, " ") == "" &&
+		len(fileIndent)%len(oldIndent) == 0
+	switch {
+	case spacesToTabs && strings.Trim(ws, " ") == "":
+		unit := len(oldIndent) / len(fileIndent)
+		return strings.Repeat("\t", len(ws)/unit) + strings.Repeat(" ", len(ws)%unit) + body
+	case tabsToSpaces && strings.Trim(ws, "\t") == "":
+		unit := len(fileIndent) / len(oldIndent)
+		return strings.Repeat(" ", len(ws)*unit) + body
+	case strings.HasPrefix(ws, oldIndent):
+		return fileIndent + line[len(oldIndent):]
+	}
+	return line
+}
+
+func leadingWhitespace(s string) string {
+	return s[:len(s)-len(strings.TrimLeft(s, " \t"))]
+}
+
+// lineSimilarity is the Dice coefficient of the longest common subsequence
+// of two line lists.
+func lineSimilarity(a, b []string) float64 {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for i := range a {
+		for j := range b {
+			switch {
+			case a[i] == b[j]:
+				cur[j+1] = prev[j] + 1
+			case prev[j+1] > cur[j]:
+				cur[j+1] = prev[j+1]
+			default:
+				cur[j+1] = cur[j]
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return 2 * float64(prev[len(b)]) / float64(len(a)+len(b))
+}
+
+// diceSimilarity compares two strings by their character bigrams.
+func diceSimilarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) < 2 || len(rb) < 2 {
+		return 0
+	}
+	bigrams := make(map[[2]rune]int, len(ra))
+	for i := 0; i+1 < len(ra); i++ {
+		bigrams[[2]rune{ra[i], ra[i+1]}]++
+	}
+	shared := 0
+	for i := 0; i+1 < len(rb); i++ {
+		k := [2]rune{rb[i], rb[i+1]}
+		if bigrams[k] > 0 {
+			bigrams[k]--
+			shared++
+		}
+	}
+	return 2 * float64(shared) / float64(len(ra)+len(rb)-2)
+}
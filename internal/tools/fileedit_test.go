@@ -149,3 +149,126 @@ func TestFileEditTool_RequiresPermission(t *testing.T) {
 		t.Error("FileEdit should require permission")
 	}
 }
+
+func runFileEdit(t *testing.T, content string, in FileEditInput) (string, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.go")
+	os.WriteFile(path, []byte(content), 0644)
+	in.FilePath = path
+	input, _ := json.Marshal(in)
+	result, err := NewFileEditTool().Execute(context.Background(), input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	return result, string(data)
+}
+
+func TestFileEditTool_WhitespaceTolerant(t *testing.T) {
+	content := "func f() {\n\tif x {\n\t\treturn 1\n\t}\n}\n"
+	result, data := runFileEdit(t, content, FileEditInput{
+		OldString: "    if x {\n        return 1\n    }",
+		NewString: "    if x {\n        return 2\n    }",
+	})
+	if !strings.Contains(result, "after normalizing whitespace") {
+		t.Errorf("result = %q, want whitespace note", result)
+	}
+	want := "func f() {\n\tif x {\n\t\treturn 2\n\t}\n}\n"
+	if data != want {
+		t.Errorf("file = %q, want %q", data, want)
+	}
+}
+
+func TestFileEditTool_CRLF(t *testing.T) {
+	result, data := runFileEdit(t, "a\r\nb\r\nc\r\n", FileEditInput{
+		OldString: "a\nb\n",
+		NewString: "x\ny\n",
+	})
+	if !strings.Contains(result, "CRLF") {
+		t.Errorf("result = %q, want CRLF note", result)
+	}
+	if data != "x\r\ny\r\nc\r\n" {
+		t.Errorf("file = %q", data)
+	}
+}
+
+func TestFileEditTool_AnchoredFuzzy(t *testing.T) {
+	content := "start()\none()\ntwo()\nthree()\nfour()\nend()\nother()\n"
+	result, data := runFileEdit(t, content, FileEditInput{
+		OldString: "start()\none()\ntwo()\nthree()\nfive()\nend()\n",
+		NewString: "replaced()\n",
+	})
+	if !strings.Contains(result, "approximately") {
+		t.Errorf("result = %q, want approximate match note", result)
+	}
+	if data != "replaced()\nother()\n" {
+		t.Errorf("file = %q", data)
+	}
+}
+
+func TestFileEditTool_Strict(t *testing.T) {
+	content := "func f() {\n\treturn 1\n}\n"
+	result, data := runFileEdit(t, content, FileEditInput{
+		OldString: "    return 1",
+		NewString: "    return 2",
+		Strict:    true,
+	})
+	if !strings.Contains(result, "not found") {
+		t.Errorf("result = %q, want not found", result)
+	}
+	if !strings.Contains(result, "Closest match at lines 2-2") || !strings.Contains(result, "+ →   return 1") {
+		t.Errorf("result = %q, want closest match diff", result)
+	}
+	if data != content {
+		t.Errorf("strict edit modified the file: %q", data)
+	}
+}
+
+func TestFileEditTool_FuzzyAmbiguous(t *testing.T) {
+	content := "\tx = 1\ny = 2\n\tx = 1\n"
+	result, data := runFileEdit(t, content, FileEditInput{
+		OldString: "  x = 1",
+		NewString: "  x = 3",
+	})
+	if !strings.Contains(result, "loosely matches 2 places") {
+		t.Errorf("result = %q, want ambiguity error", result)
+	}
+	if data != content {
+		t.Errorf("ambiguous edit modified the file: %q", data)
+	}
+}
+
+func TestReindent(t *testing.T) {
+	tests := []struct {
+		line, oldIndent, fileIndent, want string
+	}{
+		{"    x", "    ", "\t", "\tx"},
+		{"        x", "    ", "\t", "\t\tx"},
+		{"      x", "    ", "\t", "\t  x"},
+		{"\t\tx", "\t", "  ", "    x"},
+		{"  x", "  ", "    ", "    x"},
+		{"x", "  ", "    ", "x"},
+	}
+	for _, tt := range tests {
+		if got := reindent(tt.line, tt.oldIndent, tt.fileIndent); got != tt.want {
+			t.Errorf("reindent(%q, %q, %q) = %q, want %q", tt.line, tt.oldIndent, tt.fileIndent, got, tt.want)
+		}
+	}
+}
+
+func TestFileEditTool_RedactionMarker(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	os.WriteFile(path, []byte("token: J9ZtaDeUv7fAmpHoY2G2fLDZ1nwIXh4P\nretries: 1\n"), 0o644)
+
+	tool := NewFileEditTool()
+	input, _ := json.Marshal(FileEditInput{
+		FilePath:  path,
+		OldString: "retries: 1",
+		NewString: "token: [REDACTED:high-entropy]\nretries: 2",
+	})
+	result, _ := tool.Execute(context.Background(), input)
+	if !strings.Contains(result, "redaction marker") {
+		t.Errorf("expected a redaction marker error, got %q", result)
+	}
+}
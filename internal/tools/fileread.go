@@ -2,19 +2,28 @@ package tools
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
+	"unicode/utf8"
+
+	"github.com/anthropics/claude-code-go/internal/api"
+	"github.com/anthropics/claude-code-go/internal/conversation"
 )
 
 const (
 	fileReadDefaultLimit = 2000
 	fileReadMaxLineLen   = 2000
+
+	// fileReadMaxBytes bounds the text returned by one read, so a file of
+	// very long lines can't flood the context even within the line limit.
+	fileReadMaxBytes = 256 * 1024
 )
 
 // FileReadInput is the input schema for the FileRead tool.
@@ -22,21 +31,31 @@ type FileReadInput struct {
 	FilePath string `json:"file_path"`
 	Offset   *int   `json:"offset,omitempty"` // 1-based line number
 	Limit    *int   `json:"limit,omitempty"`
-	Pages    string `json:"pages,omitempty"` // PDF page range (not implemented yet)
+	Pages    string `json:"pages,omitempty"` // PDF page range, e.g. "3", "1-5", "10-"
+
+	// PageImages renders PDF pages as images instead of extracting text.
+	PageImages bool `json:"page_images,omitempty"`
 }
 
 // FileReadTool reads files from the local filesystem.
-type FileReadTool struct{}
+type FileReadTool struct {
+	tracker *ReadTracker
+}
 
 // NewFileReadTool creates a new FileRead tool.
 func NewFileReadTool() *FileReadTool {
 	return &FileReadTool{}
 }
 
+// SetReadTracker records each file read in tracker.
+func (t *FileReadTool) SetReadTracker(tracker *ReadTracker) {
+	t.tracker = tracker
+}
+
 func (t *FileReadTool) Name() string { return "FileRead" }
 
 func (t *FileReadTool) Description() string {
-	return `Reads a file from the local filesystem. The file_path parameter must be an absolute path. By default reads up to 2000 lines from the beginning. Use offset and limit for large files. Results are returned with line numbers (cat -n format).`
+	return `Reads a file from the local filesystem. The file_path parameter must be an absolute path. By default reads up to 2000 lines from the beginning; lines longer than 2000 characters are truncated, and a note at the end says where to continue. Use offset and limit for large files. Results are returned with line numbers (cat -n format). Images (PNG, JPEG, GIF, WebP) are returned as images you can see; large ones are resized. PDFs are returned as text page by page, up to 20 pages per read; use pages to select a range and page_images to see pages as images. Tool results too large to return in full are saved under .claude/tool-output/ and their preview gives the file's path; read the rest of such a result here with offset and limit.`
 }
 
 func (t *FileReadTool) InputSchema() json.RawMessage {
@@ -57,7 +76,11 @@ func (t *FileReadTool) InputSchema() json.RawMessage {
     },
     "pages": {
       "type": "string",
-      "description": "Page range for PDF files (e.g., \"1-5\"). Only applicable to PDF files."
+      "description": "Page range for PDF files (e.g., \"3\", \"1-5\", \"10-\"). Only applicable to PDF files."
+    },
+    "page_images": {
+      "type": "boolean",
+      "description": "Return PDF pages as images instead of extracted text. Use for scanned documents or when layout and figures matter. At most 10 pages at a time."
     }
   },
   "required": ["file_path"],
@@ -69,7 +92,7 @@ func (t *FileReadTool) RequiresPermission(_ json.RawMessage) bool {
 	return false // Read-only, no permission needed.
 }
 
-func (t *FileReadTool) Execute(_ context.Context, input json.RawMessage) (string, error) {
+func (t *FileReadTool) Execute(ctx context.Context, input json.RawMessage) (string, error) {
 	var in FileReadInput
 	if err := json.Unmarshal(input, &in); err != nil {
 		return "", fmt.Errorf("parsing FileRead input: %w", err)
@@ -93,21 +116,28 @@ func (t *FileReadTool) Execute(_ context.Context, input json.RawMessage) (string
 	}
 
 	// Dispatch based on file extension.
-	ext := strings.ToLower(filepath.Ext(in.FilePath))
-	switch ext {
+	var result string
+	switch strings.ToLower(filepath.Ext(in.FilePath)) {
 	case ".png", ".jpg", ".jpeg", ".gif", ".webp", ".bmp":
-		return t.readImage(in.FilePath, ext)
+		result, err = t.readImage(ctx, in.FilePath)
 	case ".pdf":
-		return t.readPDF(in.FilePath, in.Pages)
+		result, err = t.readPDF(ctx, in.FilePath, in.Pages, in.PageImages)
 	case ".ipynb":
-		return t.readNotebook(in.FilePath)
+		result, err = t.readNotebook(in.FilePath)
+	default:
+		result, err = t.readTextFile(in.FilePath, in.Offset, in.Limit)
 	}
-
-	// Default: read as text file.
-	return t.readTextFile(in.FilePath, in.Offset, in.Limit)
+	// Record the version stat'ed before reading: if the file changed in
+	// between, a later edit is refused rather than allowed on stale content.
+	if err == nil && !strings.HasPrefix(result, "Error") {
+		t.tracker.Record(in.FilePath, info)
+	}
+	return result, err
 }
 
-// readTextFile reads a file as text with optional offset/limit.
+// readTextFile reads a file as text with optional offset/limit. Long lines
+// are truncated and output stops at fileReadMaxBytes; a trailing note says
+// what was left out and where to continue.
 func (t *FileReadTool) readTextFile(filePath string, offsetPtr *int, limitPtr *int) (string, error) {
 	f, err := os.Open(filePath)
 	if err != nil {
@@ -126,36 +156,42 @@ func (t *FileReadTool) readTextFile(filePath string, offsetPtr *int, limitPtr *i
 		limit = *limitPtr
 	}
 
-	scanner := bufio.NewScanner(f)
-	// Allow long lines.
-	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	r := bufio.NewReaderSize(f, 64*1024)
+	if head, _ := r.Peek(8000); bytes.IndexByte(head, 0) >= 0 {
+		return fmt.Sprintf("Error: %s appears to be a binary file", filePath), nil
+	}
 
 	var result strings.Builder
 	lineNum := 0
-	linesRead := 0
-
-	for scanner.Scan() {
+	lastShown := 0
+	truncatedLines := 0
+	hitByteLimit := false
+
+	for {
+		inWindow := lineNum+1 >= offset && lineNum+1 < offset+limit && !hitByteLimit
+		line, truncated, err := readFileLine(r, inWindow)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Sprintf("Error reading file: %v", err), nil
+		}
 		lineNum++
-		if lineNum < offset {
+		if !inWindow {
 			continue
 		}
-		if linesRead >= limit {
-			break
-		}
 
-		line := scanner.Text()
-		// Truncate long lines.
-		if len(line) > fileReadMaxLineLen {
-			line = line[:fileReadMaxLineLen]
+		entry := fmt.Sprintf("%6d\t%s\n", lineNum, line)
+		if result.Len()+len(entry) > fileReadMaxBytes && result.Len() > 0 {
+			hitByteLimit = true
+			continue
+		}
+		if truncated {
+			truncatedLines++
 		}
-
 		// cat -n format: right-aligned line number + tab + content
-		fmt.Fprintf(&result, "%6d\t%s\n", lineNum, line)
-		linesRead++
-	}
-
-	if err := scanner.Err(); err != nil {
-		return fmt.Sprintf("Error reading file: %v", err), nil
+		result.WriteString(entry)
+		lastShown = lineNum
 	}
 
 	output := result.String()
@@ -166,133 +202,108 @@ func (t *FileReadTool) readTextFile(filePath string, offsetPtr *int, limitPtr *i
 		return fmt.Sprintf("(no lines in range: offset=%d, total lines=%d)", offset, lineNum), nil
 	}
 
-	return output, nil
-}
-
-// readImage reads an image file and returns base64-encoded content.
-func (t *FileReadTool) readImage(filePath string, ext string) (string, error) {
-	data, err := os.ReadFile(filePath)
-	if err != nil {
-		return fmt.Sprintf("Error reading image: %v", err), nil
+	var notes []string
+	if lastShown < lineNum {
+		reason := ""
+		if hitByteLimit {
+			reason = fmt.Sprintf("output limited to %s; ", formatFileSize(fileReadMaxBytes))
+		}
+		notes = append(notes, fmt.Sprintf("%sshowing lines %d-%d of %d. Use offset=%d to read more.",
+			reason, offset, lastShown, lineNum, lastShown+1))
 	}
-
-	// Determine media type.
-	var mediaType string
-	switch ext {
-	case ".png":
-		mediaType = "image/png"
-	case ".jpg", ".jpeg":
-		mediaType = "image/jpeg"
-	case ".gif":
-		mediaType = "image/gif"
-	case ".webp":
-		mediaType = "image/webp"
-	case ".bmp":
-		mediaType = "image/bmp"
-	default:
-		mediaType = "application/octet-stream"
+	if truncatedLines > 0 {
+		notes = append(notes, fmt.Sprintf("%d lines were longer than %d characters and were truncated.",
+			truncatedLines, fileReadMaxLineLen))
 	}
-
-	encoded := base64.StdEncoding.EncodeToString(data)
-
-	result := map[string]interface{}{
-		"type":       "image",
-		"media_type": mediaType,
-		"data":       encoded,
-		"size":       len(data),
+	if len(notes) > 0 {
+		output += "\n(" + strings.Join(notes, " ") + ")"
 	}
-	out, _ := json.Marshal(result)
-	return string(out), nil
+	return output, nil
 }
 
-// readPDF extracts text from a PDF file using pdftotext.
-func (t *FileReadTool) readPDF(filePath string, pages string) (string, error) {
-	args := []string{filePath, "-"}
-	if pages != "" {
-		// Parse page range for pdftotext -f (first) and -l (last) flags.
-		parts := strings.SplitN(pages, "-", 2)
-		if len(parts) == 2 {
-			args = []string{"-f", parts[0], "-l", parts[1], filePath, "-"}
-		} else if len(parts) == 1 {
-			args = []string{"-f", parts[0], "-l", parts[0], filePath, "-"}
+// readFileLine reads one line without its line ending. If keep is false the
+// line is skipped without being buffered. Kept lines longer than
+// fileReadMaxLineLen are cut at a rune boundary and reported as truncated.
+func readFileLine(r *bufio.Reader, keep bool) (string, bool, error) {
+	var line []byte
+	read := false
+	for {
+		chunk, err := r.ReadSlice('\n')
+		read = read || len(chunk) > 0
+		// Buffer a little past the limit so the line ending can be trimmed
+		// before deciding whether the line was cut.
+		if keep && len(line) <= fileReadMaxLineLen+2 {
+			line = append(line, chunk...)
 		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err == io.EOF && read {
+			break
+		}
+		if err != nil {
+			return "", false, err
+		}
+		break
 	}
 
-	cmd := exec.Command("pdftotext", args...)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		// pdftotext not available, try a basic info message.
-		return fmt.Sprintf("Error: pdftotext not available to read PDF. Install poppler-utils. (%v)", err), nil
-	}
-
-	text := string(output)
-	if text == "" {
-		return "(empty PDF or no extractable text)", nil
+	line = bytes.TrimSuffix(line, []byte("\n"))
+	line = bytes.TrimSuffix(line, []byte("\r"))
+	if len(line) <= fileReadMaxLineLen {
+		return string(line), false, nil
 	}
-
-	// Truncate if very large.
-	const maxPDFOutput = 200_000
-	if len(text) > maxPDFOutput {
-		text = text[:maxPDFOutput] + "\n... (PDF content truncated)"
+	cut := fileReadMaxLineLen
+	for cut > 0 && !utf8.RuneStart(line[cut]) {
+		cut--
 	}
-
-	return text, nil
+	return string(line[:cut]), true, nil
 }
 
-// readNotebook reads a Jupyter notebook and renders all cells with outputs.
-func (t *FileReadTool) readNotebook(filePath string) (string, error) {
+// readImage reads an image file and attaches it to the tool result as an
+// image content block, resized to fit API limits. The text result only
+// describes the image.
+func (t *FileReadTool) readImage(ctx context.Context, filePath string) (string, error) {
 	data, err := os.ReadFile(filePath)
 	if err != nil {
-		return fmt.Sprintf("Error reading notebook: %v", err), nil
-	}
-
-	var notebook struct {
-		Cells []struct {
-			CellType string      `json:"cell_type"`
-			Source   interface{} `json:"source"`
-			Outputs  []struct {
-				OutputType string      `json:"output_type"`
-				Text       interface{} `json:"text"`
-				Data       interface{} `json:"data"`
-			} `json:"outputs"`
-		} `json:"cells"`
+		return fmt.Sprintf("Error reading image: %v", err), nil
 	}
 
-	if err := json.Unmarshal(data, &notebook); err != nil {
-		return fmt.Sprintf("Error parsing notebook: %v", err), nil
+	img, err := processImage(data)
+	if err != nil {
+		return fmt.Sprintf("Error reading image %s: %v", filePath, err), nil
 	}
 
-	var result strings.Builder
-	for i, cell := range notebook.Cells {
-		fmt.Fprintf(&result, "--- Cell %d [%s] ---\n", i+1, cell.CellType)
-
-		// Render source.
-		source := flattenNotebookSource(cell.Source)
-		result.WriteString(source)
-		if !strings.HasSuffix(source, "\n") {
-			result.WriteString("\n")
-		}
-
-		// Render outputs.
-		for _, out := range cell.Outputs {
-			if out.Text != nil {
-				text := flattenNotebookSource(out.Text)
-				if text != "" {
-					fmt.Fprintf(&result, "[Output]\n%s", text)
-					if !strings.HasSuffix(text, "\n") {
-						result.WriteString("\n")
-					}
-				}
-			}
+	format := strings.ToUpper(strings.TrimPrefix(img.mediaType, "image/"))
+	summary := fmt.Sprintf("Image: %s (%s, %s", filePath, format, formatFileSize(int64(len(img.data))))
+	if img.width > 0 {
+		summary += fmt.Sprintf(", %dx%d", img.width, img.height)
+		if img.resized() {
+			summary += fmt.Sprintf(", resized from %dx%d", img.origW, img.origH)
 		}
-		result.WriteString("\n")
 	}
+	summary += ")"
+
+	block := api.ContentBlock{
+		Type: api.ContentTypeImage,
+		Source: &api.ImageSource{
+			Type:      "base64",
+			MediaType: img.mediaType,
+			Data:      base64.StdEncoding.EncodeToString(img.data),
+		},
+	}
+	if !conversation.AttachToolResultBlock(ctx, block) {
+		return summary + "\n(image content is not available in this context)", nil
+	}
+	return summary, nil
+}
 
-	output := result.String()
-	if output == "" {
-		return "(empty notebook)", nil
+// readNotebook reads a Jupyter notebook and renders all cells with outputs.
+func (t *FileReadTool) readNotebook(filePath string) (string, error) {
+	notebook, errMsg := loadNotebook(filePath)
+	if errMsg != "" {
+		return errMsg, nil
 	}
-	return output, nil
+	return renderNotebook(notebook, filePath), nil
 }
 
 // flattenNotebookSource converts a notebook source field (string or []string) to a single string.
@@ -1,12 +1,21 @@
 package tools
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"unicode/utf8"
+
+	"github.com/anthropics/claude-code-go/internal/api"
+	"github.com/anthropics/claude-code-go/internal/conversation"
 )
 
 func TestFileReadTool_BasicRead(t *testing.T) {
@@ -56,10 +65,14 @@ func TestFileReadTool_OffsetAndLimit(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	resultLines := strings.Split(strings.TrimSpace(result), "\n")
+	body, note, _ := strings.Cut(result, "\n(")
+	resultLines := strings.Split(strings.TrimSpace(body), "\n")
 	if len(resultLines) != 3 {
 		t.Errorf("expected 3 lines, got %d:\n%s", len(resultLines), result)
 	}
+	if !strings.Contains(note, "showing lines 5-7 of 20. Use offset=8 to read more.") {
+		t.Errorf("expected pagination note, got:\n%s", result)
+	}
 	// First line should be line 5.
 	if !strings.Contains(resultLines[0], "5\t") {
 		t.Errorf("expected line number 5, got: %s", resultLines[0])
@@ -112,3 +125,179 @@ func TestFileReadTool_RequiresPermission(t *testing.T) {
 		t.Error("FileRead should not require permission (read-only)")
 	}
 }
+
+func TestFileReadTool_DefaultLimit(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "big.txt")
+	os.WriteFile(path, []byte(strings.Repeat("x\n", fileReadDefaultLimit+500)), 0644)
+
+	input, _ := json.Marshal(FileReadInput{FilePath: path})
+	result, _ := NewFileReadTool().Execute(context.Background(), input)
+	if strings.Count(result, "\tx\n") != fileReadDefaultLimit {
+		t.Errorf("expected %d lines", fileReadDefaultLimit)
+	}
+	if !strings.Contains(result, "showing lines 1-2000 of 2500. Use offset=2001 to read more.") {
+		t.Errorf("expected pagination note, got tail:\n%s", result[len(result)-200:])
+	}
+
+	// Reading the rest has no note.
+	offset := 2001
+	input, _ = json.Marshal(FileReadInput{FilePath: path, Offset: &offset})
+	result, _ = NewFileReadTool().Execute(context.Background(), input)
+	if strings.Contains(result, "(") {
+		t.Errorf("unexpected note reading the last page:\n%s", result[len(result)-200:])
+	}
+}
+
+func TestFileReadTool_LongLines(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "long.txt")
+	// A 2MB line is longer than bufio's default buffer.
+	long := strings.Repeat("é", 1024*1024)
+	os.WriteFile(path, []byte("short\n"+long+"\r\nend\n"), 0644)
+
+	input, _ := json.Marshal(FileReadInput{FilePath: path})
+	result, err := NewFileReadTool().Execute(context.Background(), input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(result, "\n")
+	if len(lines) != 5 {
+		t.Fatalf("expected 3 lines, a blank line, and a note, got %d", len(lines))
+	}
+	_, content, _ := strings.Cut(lines[1], "\t")
+	if len(content) != fileReadMaxLineLen || !utf8.ValidString(content) {
+		t.Errorf("truncated line has %d bytes (valid UTF-8: %v), want %d", len(content), utf8.ValidString(content), fileReadMaxLineLen)
+	}
+	if !strings.HasSuffix(lines[2], "\tend") {
+		t.Errorf("line 3 = %q, want end", lines[2])
+	}
+	if !strings.Contains(lines[4], "1 lines were longer than 2000 characters") {
+		t.Errorf("note = %q, want truncation note", lines[4])
+	}
+}
+
+func TestFileReadTool_ByteLimit(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "wide.txt")
+	line := strings.Repeat("a", 1000) + "\n"
+	os.WriteFile(path, []byte(strings.Repeat(line, 1000)), 0644)
+
+	input, _ := json.Marshal(FileReadInput{FilePath: path})
+	result, _ := NewFileReadTool().Execute(context.Background(), input)
+	if len(result) > fileReadMaxBytes+200 {
+		t.Errorf("result is %d bytes, want about %d", len(result), fileReadMaxBytes)
+	}
+	if !strings.Contains(result, "output limited to 256.0K; showing lines 1-") {
+		t.Errorf("expected byte limit note, got tail:\n%s", result[len(result)-200:])
+	}
+}
+
+func TestFileReadTool_Binary(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "blob.bin")
+	os.WriteFile(path, []byte{0x7f, 'E', 'L', 'F', 0, 0, 1}, 0644)
+
+	input, _ := json.Marshal(FileReadInput{FilePath: path})
+	result, _ := NewFileReadTool().Execute(context.Background(), input)
+	if !strings.Contains(result, "binary file") {
+		t.Errorf("result = %q, want binary file error", result)
+	}
+}
+
+func writeTestPNG(t *testing.T, path string, w, h int) {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 0x80, A: 0xff})
+		}
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFileReadTool_Image(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "shot.png")
+	writeTestPNG(t, path, 40, 30)
+	orig, _ := os.ReadFile(path)
+
+	ctx, attachments := conversation.WithToolAttachments(context.Background())
+	input, _ := json.Marshal(FileReadInput{FilePath: path})
+	result, err := NewFileReadTool().Execute(ctx, input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(result, "PNG") || !strings.Contains(result, "40x30") {
+		t.Errorf("summary = %q, want format and dimensions", result)
+	}
+
+	blocks := attachments.Blocks()
+	if len(blocks) != 1 {
+		t.Fatalf("got %d attached blocks, want 1", len(blocks))
+	}
+	src := blocks[0].Source
+	if blocks[0].Type != api.ContentTypeImage || src == nil || src.MediaType != "image/png" {
+		t.Fatalf("unexpected block: %+v", blocks[0])
+	}
+	if src.Data != base64.StdEncoding.EncodeToString(orig) {
+		t.Error("small image should be sent unchanged")
+	}
+}
+
+func TestFileReadTool_ImageResized(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "wide.png")
+	writeTestPNG(t, path, 3000, 100)
+
+	ctx, attachments := conversation.WithToolAttachments(context.Background())
+	input, _ := json.Marshal(FileReadInput{FilePath: path})
+	result, _ := NewFileReadTool().Execute(ctx, input)
+	if !strings.Contains(result, "2000x66, resized from 3000x100") {
+		t.Errorf("summary = %q, want resize note", result)
+	}
+
+	blocks := attachments.Blocks()
+	if len(blocks) != 1 {
+		t.Fatalf("got %d attached blocks, want 1", len(blocks))
+	}
+	data, _ := base64.StdEncoding.DecodeString(blocks[0].Source.Data)
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Width != 2000 || cfg.Height != 66 {
+		t.Errorf("resized to %dx%d, want 2000x66", cfg.Width, cfg.Height)
+	}
+}
+
+func TestFileReadTool_ImageWithoutAttachments(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "shot.png")
+	writeTestPNG(t, path, 10, 10)
+
+	input, _ := json.Marshal(FileReadInput{FilePath: path})
+	result, _ := NewFileReadTool().Execute(context.Background(), input)
+	if !strings.HasPrefix(result, "Image: ") || strings.Contains(result, "base64") {
+		t.Errorf("result = %q, want a text summary only", result)
+	}
+}
+
+func TestFileReadTool_UnsupportedImage(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "fake.png")
+	os.WriteFile(path, []byte("not an image"), 0644)
+
+	input, _ := json.Marshal(FileReadInput{FilePath: path})
+	result, _ := NewFileReadTool().Execute(context.Background(), input)
+	if !strings.HasPrefix(result, "Error") {
+		t.Errorf("result = %q, want error", result)
+	}
+}
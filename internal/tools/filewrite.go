@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 // FileWriteInput is the input schema for the FileWrite tool.
@@ -15,17 +16,31 @@ type FileWriteInput struct {
 }
 
 // FileWriteTool creates or overwrites files.
-type FileWriteTool struct{}
+type FileWriteTool struct {
+	undo    *UndoStore
+	tracker *ReadTracker
+}
 
 // NewFileWriteTool creates a new FileWrite tool.
 func NewFileWriteTool() *FileWriteTool {
 	return &FileWriteTool{}
 }
 
+// SetUndoStore records each write in store so it can be undone.
+func (t *FileWriteTool) SetUndoStore(store *UndoStore) {
+	t.undo = store
+}
+
+// SetReadTracker makes writes fail if an existing file changed on disk
+// since it was last read.
+func (t *FileWriteTool) SetReadTracker(tracker *ReadTracker) {
+	t.tracker = tracker
+}
+
 func (t *FileWriteTool) Name() string { return "FileWrite" }
 
 func (t *FileWriteTool) Description() string {
-	return `Creates or overwrites a file with the given content. The file_path must be an absolute path. Parent directories are created if they don't exist.`
+	return `Creates or overwrites a file with the given content. The file_path must be an absolute path. Parent directories are created if they don't exist. Overwriting a file that changed on disk since you last read it is refused; read it again first.`
 }
 
 func (t *FileWriteTool) InputSchema() json.RawMessage {
@@ -63,6 +78,11 @@ func (t *FileWriteTool) Execute(_ context.Context, input json.RawMessage) (strin
 	if !filepath.IsAbs(in.FilePath) {
 		return "Error: file_path must be an absolute path", nil
 	}
+	if strings.Contains(in.Content, RedactionMarkerPrefix) {
+		if old, _ := os.ReadFile(in.FilePath); !strings.Contains(string(old), RedactionMarkerPrefix) {
+			return redactedWriteError, nil
+		}
+	}
 
 	// Create parent directories.
 	dir := filepath.Dir(in.FilePath)
@@ -70,9 +90,24 @@ func (t *FileWriteTool) Execute(_ context.Context, input json.RawMessage) (strin
 		return fmt.Sprintf("Error creating directories: %v", err), nil
 	}
 
-	if err := os.WriteFile(in.FilePath, []byte(in.Content), 0644); err != nil {
+	perm := os.FileMode(0644)
+	if info, err := os.Stat(in.FilePath); err == nil {
+		if info.IsDir() {
+			return fmt.Sprintf("Error: %s is a directory", in.FilePath), nil
+		}
+		perm = info.Mode().Perm()
+	}
+
+	if msg := t.tracker.CheckStale(in.FilePath); msg != "" {
+		return msg, nil
+	}
+	if err := t.undo.Snapshot(in.FilePath, t.Name()); err != nil {
+		return fmt.Sprintf("Error saving undo state: %v", err), nil
+	}
+	if err := writeFileAtomic(in.FilePath, []byte(in.Content), perm); err != nil {
 		return fmt.Sprintf("Error writing file: %v", err), nil
 	}
+	t.tracker.RecordCurrent(in.FilePath)
 
 	return fmt.Sprintf("Successfully wrote to %s (%d bytes).", in.FilePath, len(in.Content)), nil
 }
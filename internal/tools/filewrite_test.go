@@ -100,3 +100,19 @@ func TestFileWriteTool_RequiresPermission(t *testing.T) {
 		t.Error("FileWrite should require permission")
 	}
 }
+
+func TestFileWriteTool_RedactionMarker(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".env")
+	os.WriteFile(path, []byte("TOKEN=J9ZtaDeUv7fAmpHoY2G2fLDZ1nwIXh4P\n"), 0o644)
+
+	tool := NewFileWriteTool()
+	input, _ := json.Marshal(FileWriteInput{FilePath: path, Content: "TOKEN=[REDACTED:high-entropy]\nDEBUG=1\n"})
+	result, _ := tool.Execute(context.Background(), input)
+	if !strings.Contains(result, "redaction marker") {
+		t.Errorf("expected a redaction marker error, got %q", result)
+	}
+	if data, _ := os.ReadFile(path); !strings.Contains(string(data), "J9Zta") {
+		t.Errorf("file was overwritten: %q", data)
+	}
+}
@@ -0,0 +1,299 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+const (
+	gitDefaultLogLimit = 20
+	gitMaxOutput       = 100_000
+)
+
+// GitInput is the input schema for the Git tool.
+type GitInput struct {
+	Operation string   `json:"operation"`         // status, diff, log, stage, commit, branch, stash
+	Action    string   `json:"action,omitempty"`  // branch: list/create/switch/delete; stash: list/push/pop/apply/drop/show
+	Paths     []string `json:"paths,omitempty"`   // limit diff/log/stage/stash push to these paths
+	Ref       string   `json:"ref,omitempty"`     // diff/log revision or range; start point for branch create
+	Name      string   `json:"name,omitempty"`    // branch name or stash entry
+	Message   string   `json:"message,omitempty"` // commit or stash message
+	Staged    bool     `json:"staged,omitempty"`  // diff: show staged changes
+	All       bool     `json:"all,omitempty"`     // stage: all changes; commit: include tracked modifications
+	Limit     int      `json:"limit,omitempty"`   // log: maximum commits
+}
+
+// GitTool runs a fixed set of git operations with structured arguments.
+// History-rewriting and remote operations (push, reset, rebase, amend,
+// force-delete) are deliberately not exposed.
+type GitTool struct {
+	workDir string
+}
+
+// NewGitTool creates a Git tool operating on the repository at workDir.
+func NewGitTool(workDir string) *GitTool {
+	return &GitTool{workDir: workDir}
+}
+
+func (t *GitTool) Name() string { return "Git" }
+
+func (t *GitTool) Description() string {
+	return `Runs common git operations in the working directory with structured arguments. Operations: status; diff (staged, ref, paths); log (ref, paths, limit); stage (paths, or all); commit (message, all); branch (action: list, create, switch, delete; name, ref); stash (action: list, push, pop, apply, drop, show; message, name, paths). Read-only operations don't need approval, but the paths they name are checked against the Read permission rules. Pushing, resetting, rebasing, and amending are not available here.`
+}
+
+func (t *GitTool) InputSchema() json.RawMessage {
+	return json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "operation": {
+      "type": "string",
+      "enum": ["status", "diff", "log", "stage", "commit", "branch", "stash"],
+      "description": "The git operation to run"
+    },
+    "action": {
+      "type": "string",
+      "enum": ["list", "create", "switch", "delete", "push", "pop", "apply", "drop", "show"],
+      "description": "Sub-action for branch (list, create, switch, delete) and stash (list, push, pop, apply, drop, show). Defaults to list."
+    },
+    "paths": {
+      "type": "array",
+      "items": {"type": "string"},
+      "description": "Limit diff, log, stage, or stash push to these paths"
+    },
+    "ref": {
+      "type": "string",
+      "description": "Revision or range for diff and log (e.g. HEAD~3, main..feature); start point for branch create"
+    },
+    "name": {
+      "type": "string",
+      "description": "Branch name, or stash entry (e.g. stash@{1})"
+    },
+    "message": {
+      "type": "string",
+      "description": "Commit message, or stash message"
+    },
+    "staged": {
+      "type": "boolean",
+      "description": "diff: show staged changes instead of unstaged ones"
+    },
+    "all": {
+      "type": "boolean",
+      "description": "stage: stage all changes including untracked files; commit: also commit modified tracked files"
+    },
+    "limit": {
+      "type": "number",
+      "description": "log: maximum number of commits (default 20)"
+    }
+  },
+  "required": ["operation"],
+  "additionalProperties": false
+}`)
+}
+
+// RequiresPermission returns false for operations that only read the
+// repository, unless they name paths (including the path in a rev:path
+// ref), which the permission handler checks against the Read rules.
+func (t *GitTool) RequiresPermission(input json.RawMessage) bool {
+	var in GitInput
+	if err := json.Unmarshal(input, &in); err != nil {
+		return true
+	}
+	switch in.Operation {
+	case "status":
+		return false
+	case "diff", "log":
+		return len(in.Paths) > 0 || strings.Contains(in.Ref, ":")
+	case "branch":
+		return in.Action != "" && in.Action != "list"
+	case "stash":
+		return in.Action != "" && in.Action != "list" && in.Action != "show"
+	}
+	return true
+}
+
+func (t *GitTool) Execute(ctx context.Context, input json.RawMessage) (string, error) {
+	var in GitInput
+	if err := json.Unmarshal(input, &in); err != nil {
+		return "", fmt.Errorf("parsing Git input: %w", err)
+	}
+
+	for _, arg := range append([]string{in.Ref, in.Name}, in.Paths...) {
+		if strings.HasPrefix(arg, "-") {
+			return fmt.Sprintf("Error: invalid argument %q", arg), nil
+		}
+	}
+
+	args, errMsg := gitArgs(in)
+	if errMsg != "" {
+		return "Error: " + errMsg, nil
+	}
+
+	out, err := t.run(ctx, args)
+	if err != nil {
+		return fmt.Sprintf("Error: git %s failed: %s", in.Operation, out), nil
+	}
+	if out == "" {
+		switch in.Operation {
+		case "status":
+			out = "(clean)"
+		case "diff":
+			out = "(no changes)"
+		default:
+			out = "(no output)"
+		}
+	}
+	if len(out) > gitMaxOutput {
+		out = out[:gitMaxOutput] + "\n... (output truncated)"
+	}
+	return out, nil
+}
+
+// gitArgs translates a structured request into git arguments. It returns a
+// message instead of arguments if the request is invalid.
+func gitArgs(in GitInput) ([]string, string) {
+	withPaths := func(args []string) []string {
+		if len(in.Paths) == 0 {
+			return args
+		}
+		return append(append(args, "--"), in.Paths...)
+	}
+
+	switch in.Operation {
+	case "status":
+		return []string{"status", "--short", "--branch"}, ""
+
+	case "diff":
+		args := []string{"diff", "--no-color", "--no-ext-diff"}
+		if in.Staged {
+			args = append(args, "--cached")
+		}
+		if in.Ref != "" {
+			args = append(args, in.Ref)
+		}
+		return withPaths(args), ""
+
+	case "log":
+		limit := in.Limit
+		if limit <= 0 {
+			limit = gitDefaultLogLimit
+		}
+		args := []string{"log", "--no-color", "--date=short",
+			"--format=%h %ad %an%n    %s", "-n", strconv.Itoa(limit)}
+		if in.Ref != "" {
+			args = append(args, in.Ref)
+		}
+		return withPaths(args), ""
+
+	case "stage":
+		if in.All {
+			return []string{"add", "--all"}, ""
+		}
+		if len(in.Paths) == 0 {
+			return nil, "stage requires paths or all"
+		}
+		return withPaths([]string{"add"}), ""
+
+	case "commit":
+		if strings.TrimSpace(in.Message) == "" {
+			return nil, "commit requires a message"
+		}
+		args := []string{"commit", "-m", in.Message}
+		if in.All {
+			args = append(args, "--all")
+		}
+		return args, ""
+
+	case "branch":
+		switch in.Action {
+		case "", "list":
+			return []string{"branch", "--list", "-vv", "--no-color"}, ""
+		case "create", "switch", "delete":
+			if in.Name == "" {
+				return nil, "branch " + in.Action + " requires a name"
+			}
+		default:
+			return nil, fmt.Sprintf("unknown branch action %q", in.Action)
+		}
+		switch in.Action {
+		case "create":
+			args := []string{"branch", in.Name}
+			if in.Ref != "" {
+				args = append(args, in.Ref)
+			}
+			return args, ""
+		case "switch":
+			return []string{"switch", in.Name}, ""
+		default:
+			// -d refuses to delete unmerged branches.
+			return []string{"branch", "-d", in.Name}, ""
+		}
+
+	case "stash":
+		switch in.Action {
+		case "", "list":
+			return []string{"stash", "list"}, ""
+		case "push":
+			args := []string{"stash", "push"}
+			if in.Message != "" {
+				args = append(args, "-m", in.Message)
+			}
+			return withPaths(args), ""
+		case "pop", "apply", "drop":
+			args := []string{"stash", in.Action}
+			if in.Name != "" {
+				args = append(args, in.Name)
+			}
+			return args, ""
+		case "show":
+			args := []string{"stash", "show", "-p", "--no-color"}
+			if in.Name != "" {
+				args = append(args, in.Name)
+			}
+			return args, ""
+		default:
+			return nil, fmt.Sprintf("unknown stash action %q", in.Action)
+		}
+
+	case "":
+		return nil, "operation is required"
+	}
+	return nil, fmt.Sprintf("unknown operation %q", in.Operation)
+}
+
+// run executes git and returns its output. On failure the output is git's
+// stderr (or stdout if stderr is empty).
+func (t *GitTool) run(ctx context.Context, args []string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = t.workDir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_PAGER=cat")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = strings.TrimSpace(stdout.String())
+		}
+		if msg == "" {
+			msg = err.Error()
+		}
+		return msg, err
+	}
+
+	out := strings.TrimRight(stdout.String(), "\n")
+	// Some successful operations (switch, stash pop) report on stderr.
+	if errOut := strings.TrimSpace(stderr.String()); errOut != "" {
+		if out != "" {
+			out += "\n"
+		}
+		out += errOut
+	}
+	return out, nil
+}
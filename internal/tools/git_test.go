@@ -0,0 +1,136 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// initGitRepo creates a repository with one commit.
+func initGitRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "Test"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("one\n"), 0644)
+	exec.Command("git", "-C", dir, "add", ".").Run()
+	if out, err := exec.Command("git", "-C", dir, "commit", "-q", "-m", "initial").CombinedOutput(); err != nil {
+		t.Fatalf("git commit: %v\n%s", err, out)
+	}
+	return dir
+}
+
+func runGit(t *testing.T, tool *GitTool, in GitInput) string {
+	t.Helper()
+	input, _ := json.Marshal(in)
+	result, err := tool.Execute(context.Background(), input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return result
+}
+
+func TestGitTool_CommitWorkflow(t *testing.T) {
+	dir := initGitRepo(t)
+	tool := NewGitTool(dir)
+
+	if got := runGit(t, tool, GitInput{Operation: "status"}); !strings.Contains(got, "## main") {
+		t.Errorf("expected branch header in status, got %q", got)
+	}
+
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("two\n"), 0644)
+	if got := runGit(t, tool, GitInput{Operation: "diff"}); !strings.Contains(got, "-one") || !strings.Contains(got, "+two") {
+		t.Errorf("expected unstaged diff, got %q", got)
+	}
+
+	runGit(t, tool, GitInput{Operation: "stage", Paths: []string{"a.txt"}})
+	if got := runGit(t, tool, GitInput{Operation: "diff", Staged: true}); !strings.Contains(got, "+two") {
+		t.Errorf("expected staged diff, got %q", got)
+	}
+
+	if got := runGit(t, tool, GitInput{Operation: "commit", Message: "Update a"}); !strings.Contains(got, "Update a") {
+		t.Errorf("unexpected commit output %q", got)
+	}
+	got := runGit(t, tool, GitInput{Operation: "log", Limit: 1})
+	if !strings.Contains(got, "Update a") || strings.Contains(got, "initial") {
+		t.Errorf("expected only the latest commit in log, got %q", got)
+	}
+
+	if got := runGit(t, tool, GitInput{Operation: "commit", Message: "empty"}); !strings.HasPrefix(got, "Error: git commit failed") {
+		t.Errorf("expected failure with nothing to commit, got %q", got)
+	}
+}
+
+func TestGitTool_BranchAndStash(t *testing.T) {
+	dir := initGitRepo(t)
+	tool := NewGitTool(dir)
+
+	runGit(t, tool, GitInput{Operation: "branch", Action: "create", Name: "feature"})
+	runGit(t, tool, GitInput{Operation: "branch", Action: "switch", Name: "feature"})
+	if got := runGit(t, tool, GitInput{Operation: "branch"}); !strings.Contains(got, "* feature") {
+		t.Errorf("expected feature checked out, got %q", got)
+	}
+
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("wip\n"), 0644)
+	runGit(t, tool, GitInput{Operation: "stash", Action: "push", Message: "wip"})
+	if got := runGit(t, tool, GitInput{Operation: "stash"}); !strings.Contains(got, "wip") {
+		t.Errorf("expected stash entry, got %q", got)
+	}
+	runGit(t, tool, GitInput{Operation: "stash", Action: "pop"})
+	if data, _ := os.ReadFile(filepath.Join(dir, "a.txt")); string(data) != "wip\n" {
+		t.Errorf("stash pop didn't restore changes, got %q", data)
+	}
+}
+
+func TestGitTool_RejectsOptionInjection(t *testing.T) {
+	tool := NewGitTool(t.TempDir())
+	for _, in := range []GitInput{
+		{Operation: "diff", Ref: "--output=/tmp/x"},
+		{Operation: "branch", Action: "delete", Name: "-D"},
+		{Operation: "stage", Paths: []string{"--all"}},
+		{Operation: "push"},
+		{Operation: "commit"},
+	} {
+		if got := runGit(t, tool, in); !strings.HasPrefix(got, "Error:") {
+			t.Errorf("expected error for %+v, got %q", in, got)
+		}
+	}
+}
+
+func TestGitTool_RequiresPermission(t *testing.T) {
+	tool := NewGitTool(t.TempDir())
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{`{"operation": "status"}`, false},
+		{`{"operation": "log"}`, false},
+		{`{"operation": "log", "ref": "main..feature"}`, false},
+		{`{"operation": "diff", "paths": ["a.txt"]}`, true},
+		{`{"operation": "diff", "ref": "HEAD:secrets/.env"}`, true},
+		{`{"operation": "branch"}`, false},
+		{`{"operation": "stash", "action": "show"}`, false},
+		{`{"operation": "commit", "message": "x"}`, true},
+		{`{"operation": "branch", "action": "switch", "name": "x"}`, true},
+		{`{"operation": "stash", "action": "pop"}`, true},
+	}
+	for _, tt := range tests {
+		if got := tool.RequiresPermission(json.RawMessage(tt.input)); got != tt.want {
+			t.Errorf("RequiresPermission(%s) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"sort"
@@ -18,20 +19,30 @@ type GlobInput struct {
 	Path    string `json:"path,omitempty"`
 }
 
+// globMaxResults caps the number of paths returned.
+const globMaxResults = 100
+
 // GlobTool performs file pattern matching.
 type GlobTool struct {
-	workDir string
+	workDir          string
+	respectGitignore bool
 }
 
 // NewGlobTool creates a new Glob tool with the given working directory.
 func NewGlobTool(workDir string) *GlobTool {
-	return &GlobTool{workDir: workDir}
+	return &GlobTool{workDir: workDir, respectGitignore: true}
+}
+
+// SetRespectGitignore controls whether files matched by .gitignore are
+// skipped. .claudeignore files are always honored.
+func (t *GlobTool) SetRespectGitignore(respect bool) {
+	t.respectGitignore = respect
 }
 
 func (t *GlobTool) Name() string { return "Glob" }
 
 func (t *GlobTool) Description() string {
-	return `Fast file pattern matching tool. Supports glob patterns like "**/*.js" or "src/**/*.ts". Returns matching file paths sorted by modification time.`
+	return `Fast file pattern matching tool. Supports glob patterns like "**/*.js" or "src/**/*.ts". Returns matching file paths sorted by modification time, most recent first, up to 100 results. Files ignored by .gitignore or .claudeignore, and node_modules and .git directories, are skipped unless the pattern names them.`
 }
 
 func (t *GlobTool) InputSchema() json.RawMessage {
@@ -56,7 +67,7 @@ func (t *GlobTool) RequiresPermission(_ json.RawMessage) bool {
 	return false // Read-only.
 }
 
-func (t *GlobTool) Execute(_ context.Context, input json.RawMessage) (string, error) {
+func (t *GlobTool) Execute(ctx context.Context, input json.RawMessage) (string, error) {
 	var in GlobInput
 	if err := json.Unmarshal(input, &in); err != nil {
 		return "", fmt.Errorf("parsing Glob input: %w", err)
@@ -85,37 +96,52 @@ func (t *GlobTool) Execute(_ context.Context, input json.RawMessage) (string, er
 		return fmt.Sprintf("Error: %s is not a directory", searchDir), nil
 	}
 
-	// Use doublestar for ** support.
-	fsys := os.DirFS(searchDir)
-	matches, err := doublestar.Glob(fsys, in.Pattern)
-	if err != nil {
-		return fmt.Sprintf("Error matching pattern: %v", err), nil
-	}
-
-	if len(matches) == 0 {
-		return fmt.Sprintf("No files matched pattern: %s in %s", in.Pattern, searchDir), nil
+	if !doublestar.ValidatePattern(in.Pattern) {
+		return fmt.Sprintf("Error matching pattern: invalid pattern %q", in.Pattern), nil
 	}
 
-	// Convert to absolute paths and collect with mod times.
 	type fileEntry struct {
 		path    string
 		modTime int64
 	}
 	var entries []fileEntry
 
-	for _, m := range matches {
-		absPath := filepath.Join(searchDir, m)
-		fi, err := os.Stat(absPath)
+	ignore := newIgnoreMatcher(searchDir, t.respectGitignore)
+	err = filepath.WalkDir(searchDir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
-			continue // skip files we can't stat
+			return nil // skip unreadable entries
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
 		}
-		if fi.IsDir() {
-			continue // skip directories, only return files
+		if path == searchDir {
+			return nil
 		}
-		entries = append(entries, fileEntry{
-			path:    absPath,
-			modTime: fi.ModTime().UnixNano(),
-		})
+		rel := filepath.ToSlash(strings.TrimPrefix(path, searchDir+string(filepath.Separator)))
+		if d.IsDir() {
+			// Ignored directories are still searched when the pattern
+			// names them, e.g. "node_modules/pkg/**/*.js".
+			if ignore.ignored(path, true) && !strings.HasPrefix(in.Pattern, rel+"/") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ok, _ := doublestar.Match(in.Pattern, rel); !ok || ignore.ignored(path, false) {
+			return nil
+		}
+		fi, err := os.Stat(path)
+		if err != nil || fi.IsDir() {
+			return nil // only return files
+		}
+		entries = append(entries, fileEntry{path: path, modTime: fi.ModTime().UnixNano()})
+		return nil
+	})
+	if err != nil && ctx.Err() != nil {
+		return "Error: search cancelled", nil
+	}
+
+	if len(entries) == 0 {
+		return fmt.Sprintf("No files matched pattern: %s in %s", in.Pattern, searchDir), nil
 	}
 
 	// Sort by modification time, most recent first.
@@ -124,7 +150,12 @@ func (t *GlobTool) Execute(_ context.Context, input json.RawMessage) (string, er
 	})
 
 	var result strings.Builder
-	for _, e := range entries {
+	for i, e := range entries {
+		if i == globMaxResults {
+			fmt.Fprintf(&result, "(Showing the %d most recently modified of %d matching files. Use a more specific pattern or path to narrow the results.)\n",
+				globMaxResults, len(entries))
+			break
+		}
 		result.WriteString(e.path)
 		result.WriteString("\n")
 	}
@@ -3,6 +3,7 @@ package tools
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -89,3 +90,89 @@ func TestGlobTool_RequiresPermission(t *testing.T) {
 		t.Error("Glob should not require permission (read-only)")
 	}
 }
+
+func TestGlobTool_Ignores(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, ".git"), 0755)
+	for _, p := range []string{"node_modules/pkg", "build", "src/gen", "vendor"} {
+		os.MkdirAll(filepath.Join(dir, p), 0755)
+	}
+	files := []string{
+		"main.go", "node_modules/pkg/index.go", "build/out.go",
+		"src/a.go", "src/gen/z.go", "src/keep.log", "src/drop.log",
+		"vendor/v.go", ".git/hooks.go",
+	}
+	for _, f := range files {
+		os.WriteFile(filepath.Join(dir, f), []byte("x"), 0644)
+	}
+	os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("# build output\nbuild/\n*.log\n!keep.log\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "src", ".gitignore"), []byte("/gen\n"), 0644)
+	os.WriteFile(filepath.Join(dir, ".claudeignore"), []byte("vendor\n"), 0644)
+
+	glob := func(tool *GlobTool, pattern string) string {
+		input, _ := json.Marshal(GlobInput{Pattern: pattern})
+		result, _ := tool.Execute(context.Background(), input)
+		return result
+	}
+
+	tool := NewGlobTool(dir)
+	result := glob(tool, "**/*")
+	for _, want := range []string{"main.go", "src/a.go", "keep.log"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("expected %s in results, got:\n%s", want, result)
+		}
+	}
+	for _, skip := range []string{"node_modules", "build/", "gen/", "drop.log", "vendor", ".git/"} {
+		if strings.Contains(result, skip) {
+			t.Errorf("expected %s to be skipped, got:\n%s", skip, result)
+		}
+	}
+
+	// Naming an ignored directory explicitly searches it.
+	if result := glob(tool, "node_modules/pkg/*.go"); !strings.Contains(result, "index.go") {
+		t.Errorf("explicit node_modules pattern: got\n%s", result)
+	}
+
+	// .gitignore can be turned off; .claudeignore still applies.
+	tool.SetRespectGitignore(false)
+	result = glob(tool, "**/*")
+	if !strings.Contains(result, "build/out.go") || !strings.Contains(result, "drop.log") {
+		t.Errorf("expected gitignored files when not respecting .gitignore, got:\n%s", result)
+	}
+	if strings.Contains(result, "vendor") {
+		t.Errorf(".claudeignore should still apply, got:\n%s", result)
+	}
+}
+
+func TestGlobTool_ResultCap(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < globMaxResults+5; i++ {
+		os.WriteFile(filepath.Join(dir, fmt.Sprintf("f%03d.txt", i)), []byte("x"), 0644)
+	}
+
+	input, _ := json.Marshal(GlobInput{Pattern: "*.txt"})
+	result, _ := NewGlobTool(dir).Execute(context.Background(), input)
+	lines := strings.Split(result, "\n")
+	if len(lines) != globMaxResults+1 {
+		t.Fatalf("got %d lines, want %d paths and a note", len(lines), globMaxResults)
+	}
+	if !strings.Contains(lines[globMaxResults], "of 105 matching files") {
+		t.Errorf("note = %q", lines[globMaxResults])
+	}
+}
+
+func TestIgnoreMatcher_ParentRepo(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, ".git"), 0755)
+	os.MkdirAll(filepath.Join(dir, "sub", "tmp"), 0755)
+	os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("tmp/\n"), 0644)
+
+	// Searching a subdirectory still honors the repository's .gitignore.
+	m := newIgnoreMatcher(filepath.Join(dir, "sub"), true)
+	if !m.ignored(filepath.Join(dir, "sub", "tmp"), true) {
+		t.Error("sub/tmp should be ignored by the root .gitignore")
+	}
+	if m.ignored(filepath.Join(dir, "sub", "tmp"), false) {
+		t.Error("a file named tmp should not match a directory-only rule")
+	}
+}
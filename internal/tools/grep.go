@@ -5,7 +5,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 )
 
@@ -29,18 +31,25 @@ type GrepInput struct {
 
 // GrepTool searches file contents using ripgrep.
 type GrepTool struct {
-	workDir string
+	workDir          string
+	respectGitignore bool
 }
 
 // NewGrepTool creates a new Grep tool with the given working directory.
 func NewGrepTool(workDir string) *GrepTool {
-	return &GrepTool{workDir: workDir}
+	return &GrepTool{workDir: workDir, respectGitignore: true}
+}
+
+// SetRespectGitignore controls whether files matched by .gitignore are
+// skipped. .claudeignore files are always honored.
+func (t *GrepTool) SetRespectGitignore(respect bool) {
+	t.respectGitignore = respect
 }
 
 func (t *GrepTool) Name() string { return "Grep" }
 
 func (t *GrepTool) Description() string {
-	return `Content search using regular expressions (ripgrep-compatible). Output modes: "content" shows matching lines with context, "files_with_matches" (default) shows only file paths, "count" shows match counts.`
+	return `Content search using regular expressions (ripgrep-compatible). Prefer this over running grep or rg through Bash. Output modes: "content" shows matching lines, with context lines via -A/-B/-C; "files_with_matches" (default) shows only file paths; "count" shows match counts per file. Filter files with glob (e.g. "*.go", "**/*.{ts,tsx}") or type (e.g. go, py, js). Use multiline for patterns that span lines, and head_limit/offset to page through large results. Hidden and binary files are skipped.`
 }
 
 func (t *GrepTool) InputSchema() json.RawMessage {
@@ -190,16 +199,25 @@ func (t *GrepTool) Execute(ctx context.Context, input json.RawMessage) (string,
 		return "Error: pattern is required", nil
 	}
 
-	// Check if rg is available.
+	searchPath := t.workDir
+	if in.Path != "" {
+		searchPath = in.Path
+		if !filepath.IsAbs(searchPath) {
+			searchPath = filepath.Join(t.workDir, searchPath)
+		}
+	}
+
+	// Prefer ripgrep, which also honors .gitignore; otherwise use the
+	// built-in searcher.
 	rgPath, err := exec.LookPath("rg")
 	if err != nil {
-		return t.fallbackGrep(ctx, &in)
+		return t.searchNative(ctx, &in, searchPath)
 	}
 
-	return t.executeRipgrep(ctx, rgPath, &in)
+	return t.executeRipgrep(ctx, rgPath, &in, searchPath)
 }
 
-func (t *GrepTool) executeRipgrep(ctx context.Context, rgPath string, in *GrepInput) (string, error) {
+func (t *GrepTool) executeRipgrep(ctx context.Context, rgPath string, in *GrepInput, searchPath string) (string, error) {
 	args := []string{}
 
 	// Output mode.
@@ -263,14 +281,19 @@ func (t *GrepTool) executeRipgrep(ctx context.Context, rgPath string, in *GrepIn
 		args = append(args, "-U", "--multiline-dotall")
 	}
 
+	// Ignore files. rg only knows .gitignore itself, so a .claudeignore
+	// in the working directory is passed explicitly.
+	if !t.respectGitignore {
+		args = append(args, "--no-ignore-vcs")
+	}
+	if claudeIgnore := filepath.Join(t.workDir, ".claudeignore"); fileExists(claudeIgnore) {
+		args = append(args, "--ignore-file", claudeIgnore)
+	}
+	args = append(args, "--glob", "!node_modules/")
+
 	// Pattern.
 	args = append(args, "--", in.Pattern)
 
-	// Search path.
-	searchPath := t.workDir
-	if in.Path != "" {
-		searchPath = in.Path
-	}
 	args = append(args, searchPath)
 
 	cmd := exec.CommandContext(ctx, rgPath, args...)
@@ -309,60 +332,9 @@ func (t *GrepTool) executeRipgrep(ctx context.Context, rgPath string, in *GrepIn
 	return strings.TrimRight(output, "\n"), nil
 }
 
-// fallbackGrep uses Go's built-in grep when ripgrep is not available.
-func (t *GrepTool) fallbackGrep(ctx context.Context, in *GrepInput) (string, error) {
-	// Fall back to standard grep.
-	args := []string{"-r", "--include=*"}
-
-	if in.IgnoreCase != nil && *in.IgnoreCase {
-		args = append(args, "-i")
-	}
-
-	mode := in.OutputMode
-	if mode == "" {
-		mode = "files_with_matches"
-	}
-
-	switch mode {
-	case "files_with_matches":
-		args = append(args, "-l")
-	case "count":
-		args = append(args, "-c")
-	case "content":
-		args = append(args, "-n")
-	}
-
-	if in.Glob != "" {
-		args = append(args, "--include="+in.Glob)
-	}
-
-	args = append(args, "--", in.Pattern)
-
-	searchPath := t.workDir
-	if in.Path != "" {
-		searchPath = in.Path
-	}
-	args = append(args, searchPath)
-
-	grepPath, err := exec.LookPath("grep")
-	if err != nil {
-		return "Error: neither ripgrep (rg) nor grep found on the system.", nil
-	}
-
-	cmd := exec.CommandContext(ctx, grepPath, args...)
-	var stdout bytes.Buffer
-	cmd.Stdout = &stdout
-
-	cmd.Run() // ignore error, grep returns 1 for no matches
-
-	output := stdout.String()
-	output = applyOffsetLimit(output, in.Offset, in.HeadLimit)
-
-	if output == "" {
-		return "No matches found.", nil
-	}
-
-	return strings.TrimRight(output, "\n"), nil
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
 }
 
 // applyOffsetLimit applies line offset and limit to output text.
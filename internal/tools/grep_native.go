@@ -0,0 +1,353 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// grepFileTypes maps ripgrep --type names to the file globs they cover.
+var grepFileTypes = map[string][]string{
+	"c":          {"*.c", "*.h"},
+	"cpp":        {"*.cpp", "*.cc", "*.cxx", "*.hpp", "*.hh", "*.hxx", "*.h"},
+	"cs":         {"*.cs"},
+	"css":        {"*.css", "*.scss", "*.sass", "*.less"},
+	"go":         {"*.go"},
+	"html":       {"*.html", "*.htm"},
+	"java":       {"*.java"},
+	"js":         {"*.js", "*.jsx", "*.mjs", "*.cjs"},
+	"json":       {"*.json"},
+	"kotlin":     {"*.kt", "*.kts"},
+	"lua":        {"*.lua"},
+	"make":       {"Makefile", "makefile", "GNUmakefile", "*.mk"},
+	"md":         {"*.md", "*.markdown"},
+	"markdown":   {"*.md", "*.markdown"},
+	"php":        {"*.php"},
+	"proto":      {"*.proto"},
+	"py":         {"*.py", "*.pyi"},
+	"python":     {"*.py", "*.pyi"},
+	"rb":         {"*.rb"},
+	"ruby":       {"*.rb", "Gemfile", "Rakefile"},
+	"rust":       {"*.rs"},
+	"scala":      {"*.scala"},
+	"sh":         {"*.sh", "*.bash", "*.zsh"},
+	"sql":        {"*.sql"},
+	"swift":      {"*.swift"},
+	"toml":       {"*.toml"},
+	"ts":         {"*.ts", "*.tsx", "*.mts", "*.cts"},
+	"typescript": {"*.ts", "*.tsx", "*.mts", "*.cts"},
+	"txt":        {"*.txt"},
+	"xml":        {"*.xml"},
+	"yaml":       {"*.yaml", "*.yml"},
+}
+
+// grepOptions is a validated search request for the built-in searcher.
+type grepOptions struct {
+	re         *regexp.Regexp
+	root       string
+	singleFile bool
+	mode       string
+	lineNums   bool
+	before     int
+	after      int
+	multiline  bool
+	glob       string
+	types      []string
+}
+
+// grepFileResult holds the output for one file.
+type grepFileResult struct {
+	path  string
+	lines []string // content mode: formatted output lines
+	count int      // matching lines
+}
+
+// searchNative searches files with Go's regexp engine when ripgrep isn't
+// installed. Like ripgrep it skips hidden, ignored, and binary files. Files
+// are searched in parallel; results are reported in path order.
+func (t *GrepTool) searchNative(ctx context.Context, in *GrepInput, searchPath string) (string, error) {
+	opts, errMsg := newGrepOptions(in, searchPath)
+	if errMsg != "" {
+		return "Error: " + errMsg, nil
+	}
+
+	info, err := os.Stat(searchPath)
+	if err != nil {
+		return fmt.Sprintf("Error: %s: %v", searchPath, err), nil
+	}
+	opts.singleFile = !info.IsDir()
+
+	paths := make(chan string)
+	var (
+		mu      sync.Mutex
+		results []grepFileResult
+		wg      sync.WaitGroup
+	)
+	for i := 0; i < runtime.NumCPU(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range paths {
+				if r, ok := opts.searchFile(path); ok {
+					mu.Lock()
+					results = append(results, r)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	ignore := newIgnoreMatcher(searchPath, t.respectGitignore)
+	walkErr := filepath.WalkDir(searchPath, func(path string, d fs.DirEntry, err error) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			return nil // unreadable entries are skipped, as rg does
+		}
+		if path != searchPath && (strings.HasPrefix(d.Name(), ".") || ignore.ignored(path, d.IsDir())) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !d.Type().IsRegular() {
+			return nil
+		}
+		if !opts.singleFile && !opts.includes(path) {
+			return nil
+		}
+		paths <- path
+		return nil
+	})
+	close(paths)
+	wg.Wait()
+	if walkErr != nil && ctx.Err() != nil {
+		return "Search timed out.", nil
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].path < results[j].path })
+	var out strings.Builder
+	for i, r := range results {
+		switch opts.mode {
+		case "files_with_matches":
+			out.WriteString(r.path + "\n")
+		case "count":
+			if opts.singleFile {
+				fmt.Fprintf(&out, "%d\n", r.count)
+			} else {
+				fmt.Fprintf(&out, "%s:%d\n", r.path, r.count)
+			}
+		default:
+			if i > 0 && (opts.before > 0 || opts.after > 0) {
+				out.WriteString("--\n")
+			}
+			for _, l := range r.lines {
+				out.WriteString(l + "\n")
+			}
+		}
+	}
+
+	output := applyOffsetLimit(out.String(), in.Offset, in.HeadLimit)
+	if strings.TrimSpace(output) == "" {
+		return "No matches found.", nil
+	}
+	return strings.TrimRight(output, "\n"), nil
+}
+
+// newGrepOptions validates the input for the built-in searcher.
+func newGrepOptions(in *GrepInput, searchPath string) (*grepOptions, string) {
+	opts := &grepOptions{
+		root:     searchPath,
+		mode:     in.OutputMode,
+		lineNums: true,
+		glob:     in.Glob,
+	}
+	if opts.mode == "" {
+		opts.mode = "files_with_matches"
+	}
+	if in.LineNums != nil {
+		opts.lineNums = *in.LineNums
+	}
+	if in.Multiline != nil {
+		opts.multiline = *in.Multiline
+	}
+
+	// -C and context are aliases; -A and -B override them.
+	ctxLines := in.CtxLines
+	if ctxLines == nil {
+		ctxLines = in.Context
+	}
+	if ctxLines != nil {
+		opts.before, opts.after = *ctxLines, *ctxLines
+	}
+	if in.Before != nil {
+		opts.before = *in.Before
+	}
+	if in.After != nil {
+		opts.after = *in.After
+	}
+
+	flags := ""
+	if in.IgnoreCase != nil && *in.IgnoreCase {
+		flags += "i"
+	}
+	if opts.multiline {
+		flags += "s"
+	}
+	pattern := in.Pattern
+	if flags != "" {
+		pattern = "(?" + flags + ")" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Sprintf("invalid regex: %v", err)
+	}
+	opts.re = re
+
+	if in.Glob != "" && !doublestar.ValidatePattern(in.Glob) {
+		return nil, fmt.Sprintf("invalid glob: %s", in.Glob)
+	}
+	if in.FileType != "" {
+		types, ok := grepFileTypes[in.FileType]
+		if !ok {
+			return nil, fmt.Sprintf("unrecognized file type: %s", in.FileType)
+		}
+		opts.types = types
+	}
+	return opts, ""
+}
+
+// includes reports whether path passes the glob and type filters. As in rg,
+// a glob without a slash matches the file name anywhere in the tree.
+func (o *grepOptions) includes(path string) bool {
+	name := filepath.Base(path)
+	if o.glob != "" {
+		target := name
+		if strings.Contains(o.glob, "/") {
+			target, _ = filepath.Rel(o.root, path)
+			target = filepath.ToSlash(target)
+		}
+		negate := strings.HasPrefix(o.glob, "!")
+		ok, _ := doublestar.Match(strings.TrimPrefix(o.glob, "!"), target)
+		if ok == negate {
+			return false
+		}
+	}
+	if len(o.types) > 0 {
+		for _, g := range o.types {
+			if ok, _ := doublestar.Match(g, name); ok {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// searchFile searches one file. It reports false if the file has no
+// matches, can't be read, or looks binary.
+func (o *grepOptions) searchFile(path string) (grepFileResult, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return grepFileResult{}, false
+	}
+	if bytes.IndexByte(data[:min(len(data), 8000)], 0) >= 0 {
+		return grepFileResult{}, false
+	}
+
+	// Find the matching lines. In multiline mode a match marks every line
+	// it spans.
+	lineStarts := []int{0}
+	for i, b := range data {
+		if b == '\n' && i+1 < len(data) {
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+	lineOf := func(off int) int {
+		return sort.Search(len(lineStarts), func(i int) bool { return lineStarts[i] > off }) - 1
+	}
+	lineText := func(i int) string {
+		end := len(data)
+		if i+1 < len(lineStarts) {
+			end = lineStarts[i+1]
+		}
+		return strings.TrimRight(string(data[lineStarts[i]:end]), "\r\n")
+	}
+
+	matched := map[int]bool{}
+	if o.multiline {
+		for _, loc := range o.re.FindAllIndex(data, -1) {
+			end := loc[1]
+			if end > loc[0] {
+				end--
+			}
+			for l := lineOf(loc[0]); l <= lineOf(end); l++ {
+				matched[l] = true
+			}
+		}
+	} else {
+		for i := range lineStarts {
+			if o.re.MatchString(lineText(i)) {
+				matched[i] = true
+			}
+		}
+	}
+	if len(matched) == 0 {
+		return grepFileResult{}, false
+	}
+
+	r := grepFileResult{path: path, count: len(matched)}
+	if o.mode != "content" {
+		return r, true
+	}
+
+	// Render matches with context, separating non-adjacent groups with
+	// "--" like rg. Match lines use ':' and context lines '-'.
+	matchLines := make([]int, 0, len(matched))
+	for l := range matched {
+		matchLines = append(matchLines, l)
+	}
+	sort.Ints(matchLines)
+	last := -1
+	for _, m := range matchLines {
+		from := max(m-o.before, last+1)
+		if (o.before > 0 || o.after > 0) && last >= 0 && from > last+1 {
+			r.lines = append(r.lines, "--")
+		}
+		to := min(m+o.after, len(lineStarts)-1)
+		for l := from; l <= to; l++ {
+			if l <= last {
+				continue
+			}
+			sep := "-"
+			if matched[l] {
+				sep = ":"
+			}
+			r.lines = append(r.lines, o.formatLine(path, l, sep, lineText(l)))
+			last = l
+		}
+	}
+	return r, true
+}
+
+func (o *grepOptions) formatLine(path string, line int, sep, text string) string {
+	prefix := ""
+	if !o.singleFile {
+		prefix = path + sep
+	}
+	if o.lineNums {
+		prefix += fmt.Sprintf("%d%s", line+1, sep)
+	}
+	return prefix + text
+}
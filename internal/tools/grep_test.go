@@ -124,8 +124,6 @@ func TestApplyOffsetLimit(t *testing.T) {
 	}
 }
 
-func intPtr(n int) *int { return &n }
-
 // buildGrepInput creates JSON input from a map, handling dash-prefixed keys.
 func buildGrepInput(t *testing.T, m map[string]interface{}) json.RawMessage {
 	t.Helper()
@@ -135,3 +133,102 @@ func buildGrepInput(t *testing.T, m map[string]interface{}) json.RawMessage {
 	}
 	return json.RawMessage(data)
 }
+
+func grepNative(t *testing.T, dir string, in GrepInput) string {
+	t.Helper()
+	result, err := NewGrepTool(dir).searchNative(context.Background(), &in, dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return result
+}
+
+func intPtr(n int) *int { return &n }
+
+func TestGrepNative_ContextLines(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("1\n2\nhit\n4\n5\n6\n7\nhit\n9\n"), 0644)
+
+	result := grepNative(t, dir, GrepInput{Pattern: "hit", OutputMode: "content", CtxLines: intPtr(1)})
+	p := filepath.Join(dir, "a.txt")
+	want := strings.Join([]string{
+		p + "-2-2",
+		p + ":3:hit",
+		p + "-4-4",
+		"--",
+		p + "-7-7",
+		p + ":8:hit",
+		p + "-9-9",
+	}, "\n")
+	if result != want {
+		t.Errorf("got:\n%s\nwant:\n%s", result, want)
+	}
+
+	// -A and -B override -C; without context there are no separators.
+	result = grepNative(t, dir, GrepInput{Pattern: "hit", OutputMode: "content", CtxLines: intPtr(1), Before: intPtr(0), After: intPtr(0)})
+	if strings.Count(result, "\n") != 1 {
+		t.Errorf("expected two match lines, got:\n%s", result)
+	}
+}
+
+func TestGrepNative_CountAndFilters(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "sub"), 0755)
+	os.MkdirAll(filepath.Join(dir, ".hidden"), 0755)
+	os.WriteFile(filepath.Join(dir, "a.go"), []byte("x\nx\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "sub", "b.go"), []byte("x\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "c.py"), []byte("x\n"), 0644)
+	os.WriteFile(filepath.Join(dir, ".hidden", "d.go"), []byte("x\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "e.bin"), []byte("x\x00\n"), 0644)
+
+	result := grepNative(t, dir, GrepInput{Pattern: "x", OutputMode: "count"})
+	want := filepath.Join(dir, "a.go") + ":2\n" + filepath.Join(dir, "c.py") + ":1\n" + filepath.Join(dir, "sub", "b.go") + ":1"
+	if result != want {
+		t.Errorf("got:\n%s\nwant:\n%s", result, want)
+	}
+
+	result = grepNative(t, dir, GrepInput{Pattern: "x", FileType: "go"})
+	if strings.Contains(result, "c.py") || !strings.Contains(result, "b.go") {
+		t.Errorf("type filter: got\n%s", result)
+	}
+
+	result = grepNative(t, dir, GrepInput{Pattern: "x", Glob: "*.py"})
+	if result != filepath.Join(dir, "c.py") {
+		t.Errorf("glob filter: got\n%s", result)
+	}
+
+	result = grepNative(t, dir, GrepInput{Pattern: "x", FileType: "nope"})
+	if !strings.Contains(result, "unrecognized file type") {
+		t.Errorf("unknown type: got %q", result)
+	}
+}
+
+func TestGrepNative_Multiline(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.go"), []byte("type T struct {\n\tA int\n}\nfunc f() {}\n"), 0644)
+
+	multi := true
+	result := grepNative(t, dir, GrepInput{Pattern: `struct \{.*?\}`, OutputMode: "content", Multiline: &multi})
+	p := filepath.Join(dir, "a.go")
+	want := p + ":1:type T struct {\n" + p + ":2:\tA int\n" + p + ":3:}"
+	if result != want {
+		t.Errorf("got:\n%s\nwant:\n%s", result, want)
+	}
+
+	result = grepNative(t, dir, GrepInput{Pattern: `struct \{.*?\}`})
+	if !strings.Contains(result, "No matches") {
+		t.Errorf("without multiline the pattern should not match, got:\n%s", result)
+	}
+}
+
+func TestGrepNative_HeadLimitAndSingleFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.txt")
+	os.WriteFile(path, []byte("m1\nm2\nm3\nm4\n"), 0644)
+
+	in := GrepInput{Pattern: "m", OutputMode: "content", HeadLimit: intPtr(2), Offset: intPtr(1)}
+	result, _ := NewGrepTool(dir).searchNative(context.Background(), &in, path)
+	if result != "2:m2\n3:m3" {
+		t.Errorf("got %q", result)
+	}
+}
@@ -0,0 +1,327 @@
+package tools
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// htmlSkipped are elements whose content is never rendered.
+var htmlSkipped = map[atom.Atom]bool{
+	atom.Head: true, atom.Script: true, atom.Style: true, atom.Noscript: true,
+	atom.Template: true, atom.Svg: true, atom.Canvas: true, atom.Iframe: true,
+	atom.Nav: true, atom.Footer: true, atom.Form: true, atom.Button: true,
+	atom.Select: true, atom.Input: true, atom.Textarea: true,
+}
+
+// htmlBlocks are elements rendered as separate blocks.
+var htmlBlocks = map[atom.Atom]bool{
+	atom.Address: true, atom.Article: true, atom.Aside: true, atom.Blockquote: true,
+	atom.Dd: true, atom.Details: true, atom.Div: true, atom.Dl: true, atom.Dt: true,
+	atom.Figcaption: true, atom.Figure: true, atom.H1: true, atom.H2: true,
+	atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true, atom.Header: true,
+	atom.Hr: true, atom.Li: true, atom.Main: true, atom.Ol: true, atom.P: true,
+	atom.Pre: true, atom.Section: true, atom.Summary: true, atom.Table: true,
+	atom.Ul: true,
+}
+
+var (
+	reSpaces     = regexp.MustCompile(`[ \t\r\n\f]+`)
+	reBlankLines = regexp.MustCompile(`\n{3,}`)
+)
+
+// htmlToMarkdown converts an HTML page to Markdown, keeping headings,
+// links, lists, code blocks, and tables and dropping scripts, styles, and
+// navigation. Relative links are resolved against base if it is non-nil.
+// The page's <main> (or single <article>) is used when present.
+func htmlToMarkdown(src string, base *url.URL) string {
+	doc, err := html.Parse(strings.NewReader(src))
+	if err != nil {
+		return strings.TrimSpace(src)
+	}
+	root := findElement(doc, atom.Main)
+	if root == nil {
+		if articles := findAllElements(doc, atom.Article); len(articles) == 1 {
+			root = articles[0]
+		}
+	}
+	if root == nil {
+		root = findElement(doc, atom.Body)
+	}
+	if root == nil {
+		root = doc
+	}
+
+	c := &mdConverter{base: base}
+	out := c.children(root)
+	if title := findElement(doc, atom.Title); title != nil && findElement(root, atom.H1) == nil {
+		if t := collapseSpaces(textContent(title)); t != "" {
+			out = "# " + t + "\n\n" + out
+		}
+	}
+	lines := strings.Split(out, "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(l, " ")
+	}
+	return strings.TrimSpace(reBlankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
+
+type mdConverter struct {
+	base *url.URL
+}
+
+// children renders the children of n, grouping runs of inline content into
+// paragraphs separated from block elements by blank lines.
+func (c *mdConverter) children(n *html.Node) string {
+	var out, para strings.Builder
+	flush := func() {
+		if p := strings.TrimSpace(para.String()); p != "" {
+			out.WriteString(p + "\n\n")
+		}
+		para.Reset()
+	}
+	for ch := n.FirstChild; ch != nil; ch = ch.NextSibling {
+		if ch.Type == html.ElementNode && htmlSkipped[ch.DataAtom] {
+			continue
+		}
+		if ch.Type == html.ElementNode && htmlBlocks[ch.DataAtom] {
+			flush()
+			if b := strings.TrimSpace(c.block(ch)); b != "" {
+				out.WriteString(b + "\n\n")
+			}
+			continue
+		}
+		para.WriteString(c.inline(ch))
+	}
+	flush()
+	return out.String()
+}
+
+// block renders a block-level element.
+func (c *mdConverter) block(n *html.Node) string {
+	switch n.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		level := int(n.Data[1] - '0')
+		return strings.Repeat("#", level) + " " + strings.TrimSpace(c.inlineChildren(n))
+	case atom.Hr:
+		return "---"
+	case atom.Pre:
+		text := strings.Trim(textContent(n), "\n")
+		return "```" + codeLanguage(n) + "\n" + text + "\n```"
+	case atom.Ul, atom.Ol:
+		return c.list(n)
+	case atom.Blockquote:
+		lines := strings.Split(strings.TrimSpace(c.children(n)), "\n")
+		for i, l := range lines {
+			lines[i] = strings.TrimRight("> "+l, " ")
+		}
+		return strings.Join(lines, "\n")
+	case atom.Table:
+		return c.table(n)
+	}
+	return c.children(n)
+}
+
+// list renders a ul or ol, indenting nested content under each marker.
+func (c *mdConverter) list(n *html.Node) string {
+	var out strings.Builder
+	i := 1
+	for li := n.FirstChild; li != nil; li = li.NextSibling {
+		if li.Type != html.ElementNode || li.DataAtom != atom.Li {
+			continue
+		}
+		marker := "- "
+		if n.DataAtom == atom.Ol {
+			marker = fmt.Sprintf("%d. ", i)
+			i++
+		}
+		body := strings.TrimSpace(reBlankLines.ReplaceAllString(c.children(li), "\n\n"))
+		body = strings.ReplaceAll(body, "\n\n", "\n")
+		indent := strings.Repeat(" ", len(marker))
+		out.WriteString(marker + strings.ReplaceAll(body, "\n", "\n"+indent) + "\n")
+	}
+	return out.String()
+}
+
+// table renders a table as a Markdown table, using the first row as the
+// header.
+func (c *mdConverter) table(n *html.Node) string {
+	var rows [][]string
+	for _, tr := range findAllElements(n, atom.Tr) {
+		var row []string
+		for td := tr.FirstChild; td != nil; td = td.NextSibling {
+			if td.Type == html.ElementNode && (td.DataAtom == atom.Td || td.DataAtom == atom.Th) {
+				cell := strings.TrimSpace(collapseSpaces(c.inlineChildren(td)))
+				row = append(row, strings.ReplaceAll(cell, "|", `\|`))
+			}
+		}
+		if len(row) > 0 {
+			rows = append(rows, row)
+		}
+	}
+	if len(rows) == 0 {
+		return ""
+	}
+	cols := 0
+	for _, r := range rows {
+		cols = max(cols, len(r))
+	}
+	var out strings.Builder
+	for i, r := range rows {
+		for len(r) < cols {
+			r = append(r, "")
+		}
+		out.WriteString("| " + strings.Join(r, " | ") + " |\n")
+		if i == 0 {
+			out.WriteString("|" + strings.Repeat(" --- |", cols) + "\n")
+		}
+	}
+	return out.String()
+}
+
+func (c *mdConverter) inlineChildren(n *html.Node) string {
+	var b strings.Builder
+	for ch := n.FirstChild; ch != nil; ch = ch.NextSibling {
+		b.WriteString(c.inline(ch))
+	}
+	return b.String()
+}
+
+// inline renders a node as inline Markdown. Block elements nested inside
+// inline ones are flattened.
+func (c *mdConverter) inline(n *html.Node) string {
+	switch n.Type {
+	case html.TextNode:
+		return collapseSpaces(n.Data)
+	case html.ElementNode:
+	default:
+		return ""
+	}
+	if htmlSkipped[n.DataAtom] {
+		return ""
+	}
+
+	switch n.DataAtom {
+	case atom.Br:
+		return "\n"
+	case atom.A:
+		text := strings.TrimSpace(c.inlineChildren(n))
+		href := c.resolve(attr(n, "href"))
+		if href == "" || text == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(href, "javascript:") {
+			return text
+		}
+		return "[" + text + "](" + href + ")"
+	case atom.Img:
+		alt := attr(n, "alt")
+		if alt == "" {
+			return ""
+		}
+		return "![" + alt + "](" + c.resolve(attr(n, "src")) + ")"
+	case atom.Strong, atom.B:
+		return wrapInline("**", c.inlineChildren(n))
+	case atom.Em, atom.I:
+		return wrapInline("*", c.inlineChildren(n))
+	case atom.Code, atom.Kbd, atom.Samp:
+		text := textContent(n)
+		if strings.TrimSpace(text) == "" {
+			return text
+		}
+		return "`" + text + "`"
+	}
+	if htmlBlocks[n.DataAtom] {
+		return " " + c.inlineChildren(n) + " "
+	}
+	return c.inlineChildren(n)
+}
+
+// wrapInline wraps text in a Markdown marker, keeping surrounding spaces
+// outside the marker so the emphasis still parses.
+func wrapInline(marker, text string) string {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+	lead := text[:strings.Index(text, trimmed)]
+	trail := text[len(lead)+len(trimmed):]
+	return lead + marker + trimmed + marker + trail
+}
+
+func (c *mdConverter) resolve(ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || c.base == nil || strings.HasPrefix(ref, "#") {
+		return ref
+	}
+	u, err := c.base.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return u.String()
+}
+
+// codeLanguage returns the language of a pre block from a "language-x"
+// class on it or its code child.
+func codeLanguage(pre *html.Node) string {
+	nodes := []*html.Node{pre}
+	if code := findElement(pre, atom.Code); code != nil {
+		nodes = append(nodes, code)
+	}
+	for _, n := range nodes {
+		for _, class := range strings.Fields(attr(n, "class")) {
+			if lang, ok := strings.CutPrefix(class, "language-"); ok {
+				return lang
+			}
+		}
+	}
+	return ""
+}
+
+func collapseSpaces(s string) string {
+	return reSpaces.ReplaceAllString(s, " ")
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	for ch := n.FirstChild; ch != nil; ch = ch.NextSibling {
+		b.WriteString(textContent(ch))
+	}
+	return b.String()
+}
+
+func findElement(n *html.Node, a atom.Atom) *html.Node {
+	if n.Type == html.ElementNode && n.DataAtom == a {
+		return n
+	}
+	for ch := n.FirstChild; ch != nil; ch = ch.NextSibling {
+		if found := findElement(ch, a); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func findAllElements(n *html.Node, a atom.Atom) []*html.Node {
+	var found []*html.Node
+	if n.Type == html.ElementNode && n.DataAtom == a {
+		found = append(found, n)
+	}
+	for ch := n.FirstChild; ch != nil; ch = ch.NextSibling {
+		found = append(found, findAllElements(ch, a)...)
+	}
+	return found
+}
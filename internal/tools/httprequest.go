@@ -0,0 +1,335 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	httpRequestDefaultTimeout = 30 * time.Second
+	httpRequestMaxTimeout     = 120 * time.Second
+	httpRequestMaxBodySize    = 1024 * 1024 // bytes of response body read
+	httpRequestMaxOutput      = 100_000     // characters of response body returned
+)
+
+// HttpRequestAuth describes credentials taken from environment variables,
+// so secrets never appear in the conversation.
+type HttpRequestAuth struct {
+	Type     string `json:"type"`               // "bearer", "basic", or "header"
+	Env      string `json:"env"`                // variable holding the token or password
+	Username string `json:"username,omitempty"` // basic auth user name
+	Header   string `json:"header,omitempty"`   // header name for type "header"
+}
+
+// HttpRequestInput is the input schema for the HttpRequest tool.
+type HttpRequestInput struct {
+	Method  string            `json:"method,omitempty"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+	Auth    *HttpRequestAuth  `json:"auth,omitempty"`
+	Timeout *int              `json:"timeout,omitempty"` // seconds
+}
+
+// HttpRequestTool makes arbitrary HTTP requests, for working with APIs
+// without going through curl.
+type HttpRequestTool struct {
+	httpClient  *http.Client
+	getenv      func(string) string
+	credentials map[string][]string // variables auth may send, by host pattern
+}
+
+// NewHttpRequestTool creates a new HttpRequest tool. If httpClient is nil, a
+// default client is used. Either way, redirects to another host are not
+// followed (see sameHostRedirect).
+func NewHttpRequestTool(httpClient *http.Client) *HttpRequestTool {
+	client := &http.Client{}
+	if httpClient != nil {
+		*client = *httpClient
+	}
+	client.CheckRedirect = sameHostRedirect
+	return &HttpRequestTool{httpClient: client, getenv: os.Getenv}
+}
+
+// SetCredentials sets the environment variables auth may send, by host:
+// "api.github.com", or "*.example.com" for its subdomains. A variable not
+// listed for the request's host isn't read, so an approved domain can't
+// be sent arbitrary secrets from the environment.
+func (t *HttpRequestTool) SetCredentials(credentials map[string][]string) {
+	t.credentials = credentials
+}
+
+// credentialAllowed reports whether the variable env may be sent to host.
+func (t *HttpRequestTool) credentialAllowed(host, env string) bool {
+	host = strings.ToLower(host)
+	for pattern, names := range t.credentials {
+		pattern = strings.ToLower(pattern)
+		if pattern != host && !(strings.HasPrefix(pattern, "*.") && strings.HasSuffix(host, pattern[1:])) {
+			continue
+		}
+		for _, name := range names {
+			if name == env {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sameHostRedirect follows redirects only within the requested host. The
+// permission check only saw the first URL, and the auth header would be
+// sent along, so a redirect elsewhere is returned to the model to request
+// itself.
+func sameHostRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= 10 {
+		return fmt.Errorf("stopped after 10 redirects")
+	}
+	if req.URL.Host != via[0].URL.Host {
+		return http.ErrUseLastResponse
+	}
+	return nil
+}
+
+// HttpRequestSummary describes an HttpRequest call for permission prompts:
+// the method and URL, and the environment variable whose value it sends.
+func HttpRequestSummary(input json.RawMessage) string {
+	var in HttpRequestInput
+	if json.Unmarshal(input, &in) != nil || in.URL == "" {
+		return ""
+	}
+	method := strings.ToUpper(in.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+	s := method + " " + in.URL
+	if in.Auth != nil && in.Auth.Env != "" {
+		s += fmt.Sprintf(" (sends $%s as %s auth)", in.Auth.Env, in.Auth.Type)
+	}
+	return s
+}
+
+func (t *HttpRequestTool) Name() string { return "HttpRequest" }
+
+func (t *HttpRequestTool) Description() string {
+	return `Makes an HTTP request and returns the response status, headers, and body. Use this for calling APIs instead of running curl through Bash.
+
+- method defaults to GET. Any method is allowed.
+- headers and body are sent as given; set Content-Type yourself when sending a body.
+- To authenticate, use auth instead of pasting secrets into headers. The credential is read from the named environment variable and is redacted from the output. Only variables the user has allowed for the request's host in the httpCredentials setting can be used:
+  - {"type": "bearer", "env": "API_TOKEN"} sends "Authorization: Bearer <token>"
+  - {"type": "basic", "username": "me", "env": "API_PASSWORD"} sends basic auth
+  - {"type": "header", "header": "X-Api-Key", "env": "API_KEY"} sends the token in a custom header
+- Responses larger than 1 MB are truncated, and binary bodies are summarized rather than returned.
+- timeout is in seconds (default 30, max 120).`
+}
+
+func (t *HttpRequestTool) InputSchema() json.RawMessage {
+	return json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "method": {
+      "type": "string",
+      "description": "HTTP method (default GET)"
+    },
+    "url": {
+      "type": "string",
+      "description": "The http or https URL to request",
+      "format": "uri"
+    },
+    "headers": {
+      "type": "object",
+      "additionalProperties": {"type": "string"},
+      "description": "Request headers"
+    },
+    "body": {
+      "type": "string",
+      "description": "Request body"
+    },
+    "auth": {
+      "type": "object",
+      "properties": {
+        "type": {"type": "string", "enum": ["bearer", "basic", "header"]},
+        "env": {"type": "string", "description": "Environment variable holding the token or password"},
+        "username": {"type": "string", "description": "User name for basic auth"},
+        "header": {"type": "string", "description": "Header name for header auth"}
+      },
+      "required": ["type", "env"],
+      "additionalProperties": false
+    },
+    "timeout": {
+      "type": "integer",
+      "description": "Timeout in seconds (default 30, max 120)"
+    }
+  },
+  "required": ["url"],
+  "additionalProperties": false
+}`)
+}
+
+func (t *HttpRequestTool) RequiresPermission(_ json.RawMessage) bool {
+	return true // network access
+}
+
+func (t *HttpRequestTool) Execute(ctx context.Context, input json.RawMessage) (string, error) {
+	var in HttpRequestInput
+	if err := json.Unmarshal(input, &in); err != nil {
+		return "", fmt.Errorf("parsing HttpRequest input: %w", err)
+	}
+
+	target, err := url.Parse(in.URL)
+	if err != nil || target.Host == "" || (target.Scheme != "http" && target.Scheme != "https") {
+		return fmt.Sprintf("Error: invalid URL %q: must be an absolute http or https URL", in.URL), nil
+	}
+	method := strings.ToUpper(in.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	timeout := httpRequestDefaultTimeout
+	if in.Timeout != nil && *in.Timeout > 0 {
+		timeout = min(time.Duration(*in.Timeout)*time.Second, httpRequestMaxTimeout)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var body io.Reader
+	if in.Body != "" {
+		body = strings.NewReader(in.Body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), body)
+	if err != nil {
+		return fmt.Sprintf("Error creating request: %v", err), nil
+	}
+	req.Header.Set("User-Agent", "ClaudeCode/1.0")
+	for k, v := range in.Headers {
+		req.Header.Set(k, v)
+	}
+
+	var secret string
+	if in.Auth != nil {
+		var errMsg string
+		if secret, errMsg = t.applyAuth(req, in.Auth); errMsg != "" {
+			return "Error: " + errMsg, nil
+		}
+	}
+
+	startTime := time.Now()
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return redactSecret(fmt.Sprintf("Error: request failed: %v", err), secret), nil
+	}
+	defer resp.Body.Close()
+
+	// Read one byte past the limit to tell whether the body was cut off.
+	data, err := io.ReadAll(io.LimitReader(resp.Body, httpRequestMaxBodySize+1))
+	if err != nil {
+		return fmt.Sprintf("Error reading response: %v", err), nil
+	}
+	truncated := len(data) > httpRequestMaxBodySize
+	if truncated {
+		data = data[:httpRequestMaxBodySize]
+	}
+	duration := time.Since(startTime)
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "%s %s\n", resp.Proto, resp.Status)
+	names := make([]string, 0, len(resp.Header))
+	for name := range resp.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, v := range resp.Header[name] {
+			fmt.Fprintf(&out, "%s: %s\n", name, v)
+		}
+	}
+	out.WriteString("\n")
+	out.WriteString(formatResponseBody(data, resp.Header.Get("Content-Type")))
+	if truncated {
+		fmt.Fprintf(&out, "\n\n... (response truncated at %d bytes)", httpRequestMaxBodySize)
+	}
+	if loc, err := resp.Location(); err == nil && loc.Host != resp.Request.URL.Host {
+		fmt.Fprintf(&out, "\n\n(redirect to another host not followed; request %s separately if needed)", loc)
+	}
+	fmt.Fprintf(&out, "\n\n(%s %s took %dms)", method, resp.Request.URL, duration.Milliseconds())
+	return redactSecret(out.String(), secret), nil
+}
+
+// applyAuth adds the credentials described by auth to req and returns the
+// secret value so it can be redacted from the output.
+func (t *HttpRequestTool) applyAuth(req *http.Request, auth *HttpRequestAuth) (string, string) {
+	if auth.Env == "" {
+		return "", "auth.env is required"
+	}
+	if host := req.URL.Hostname(); !t.credentialAllowed(host, auth.Env) {
+		return "", fmt.Sprintf("environment variable %s may not be sent to %s; the user can allow it in httpCredentials in ~/.claude/settings.json, e.g. {\"%s\": [\"%s\"]}", auth.Env, host, host, auth.Env)
+	}
+	secret := t.getenv(auth.Env)
+	if secret == "" {
+		return "", fmt.Sprintf("environment variable %s is not set", auth.Env)
+	}
+	switch auth.Type {
+	case "bearer":
+		req.Header.Set("Authorization", "Bearer "+secret)
+	case "basic":
+		if auth.Username == "" {
+			return "", "auth.username is required for basic auth"
+		}
+		req.SetBasicAuth(auth.Username, secret)
+	case "header":
+		if auth.Header == "" {
+			return "", "auth.header is required for header auth"
+		}
+		req.Header.Set(auth.Header, secret)
+	default:
+		return "", fmt.Sprintf("unknown auth type %q (expected bearer, basic, or header)", auth.Type)
+	}
+	return secret, ""
+}
+
+// formatResponseBody renders a response body for the model: JSON is
+// indented, other text is returned as is, and binary content is summarized.
+func formatResponseBody(data []byte, contentType string) string {
+	if len(data) == 0 {
+		return "(empty body)"
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if (mediaType != "" && !isTextMediaType(mediaType)) || bytes.IndexByte(data[:min(len(data), 8000)], 0) >= 0 {
+		return fmt.Sprintf("(binary body: %d bytes of %s)", len(data), contentType)
+	}
+	if mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") {
+		var buf bytes.Buffer
+		if json.Indent(&buf, data, "", "  ") == nil {
+			data = buf.Bytes()
+		}
+	}
+	text := string(data)
+	if len(text) > httpRequestMaxOutput {
+		cut := httpRequestMaxOutput
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		text = text[:cut] + fmt.Sprintf("\n\n... (body truncated to %d of %d characters)", cut, len(text))
+	}
+	return text
+}
+
+// redactSecret replaces every occurrence of secret with a placeholder, in
+// case a server echoes the credential back.
+func redactSecret(s, secret string) string {
+	if secret == "" {
+		return s
+	}
+	return strings.ReplaceAll(s, secret, "[REDACTED]")
+}
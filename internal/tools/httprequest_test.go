@@ -0,0 +1,185 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func runHttpRequest(t *testing.T, tool *HttpRequestTool, in HttpRequestInput) string {
+	t.Helper()
+	input, _ := json.Marshal(in)
+	result, err := tool.Execute(context.Background(), input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return result
+}
+
+func TestHttpRequest_MethodHeadersBody(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Request-Id", "abc")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"method":%q,"trace":%q,"body":%q}`, r.Method, r.Header.Get("X-Trace"), body)
+	}))
+	defer srv.Close()
+
+	result := runHttpRequest(t, NewHttpRequestTool(nil), HttpRequestInput{
+		Method:  "post",
+		URL:     srv.URL + "/items",
+		Headers: map[string]string{"X-Trace": "t1"},
+		Body:    "hello",
+	})
+	for _, want := range []string{
+		"HTTP/1.1 201 Created",
+		"X-Request-Id: abc",
+		"{\n  \"method\": \"POST\",\n  \"trace\": \"t1\",\n  \"body\": \"hello\"\n}",
+		"(POST " + srv.URL + "/items took",
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("expected %q in:\n%s", want, result)
+		}
+	}
+}
+
+func TestHttpRequest_AuthFromEnv(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Echo the credential back to check that it is redacted.
+		fmt.Fprintf(w, "auth=%s key=%s", r.Header.Get("Authorization"), r.Header.Get("X-Api-Key"))
+	}))
+	defer srv.Close()
+
+	tool := NewHttpRequestTool(nil)
+	tool.getenv = func(name string) string {
+		if name == "TEST_TOKEN" || name == "AWS_SECRET_ACCESS_KEY" {
+			return "s3cret"
+		}
+		return ""
+	}
+	tool.SetCredentials(map[string][]string{"127.0.0.1": {"TEST_TOKEN", "MISSING"}})
+
+	result := runHttpRequest(t, tool, HttpRequestInput{URL: srv.URL, Auth: &HttpRequestAuth{Type: "bearer", Env: "TEST_TOKEN"}})
+	if !strings.Contains(result, "auth=Bearer [REDACTED]") || strings.Contains(result, "s3cret") {
+		t.Errorf("expected redacted bearer token, got:\n%s", result)
+	}
+
+	result = runHttpRequest(t, tool, HttpRequestInput{URL: srv.URL, Auth: &HttpRequestAuth{Type: "header", Header: "X-Api-Key", Env: "TEST_TOKEN"}})
+	if !strings.Contains(result, "key=[REDACTED]") {
+		t.Errorf("expected custom auth header, got:\n%s", result)
+	}
+
+	result = runHttpRequest(t, tool, HttpRequestInput{URL: srv.URL, Auth: &HttpRequestAuth{Type: "bearer", Env: "MISSING"}})
+	if result != "Error: environment variable MISSING is not set" {
+		t.Errorf("unexpected result for missing variable: %q", result)
+	}
+
+	// Variables not listed for the host aren't read or sent.
+	result = runHttpRequest(t, tool, HttpRequestInput{URL: srv.URL, Auth: &HttpRequestAuth{Type: "bearer", Env: "AWS_SECRET_ACCESS_KEY"}})
+	if !strings.HasPrefix(result, "Error: environment variable AWS_SECRET_ACCESS_KEY may not be sent to 127.0.0.1") {
+		t.Errorf("expected an unlisted variable to be refused, got %q", result)
+	}
+	tool.SetCredentials(map[string][]string{"api.example.com": {"TEST_TOKEN"}})
+	result = runHttpRequest(t, tool, HttpRequestInput{URL: srv.URL, Auth: &HttpRequestAuth{Type: "bearer", Env: "TEST_TOKEN"}})
+	if !strings.Contains(result, "may not be sent to 127.0.0.1") {
+		t.Errorf("expected a variable listed for another host to be refused, got %q", result)
+	}
+}
+
+func TestHttpRequest_CredentialAllowed(t *testing.T) {
+	tool := NewHttpRequestTool(nil)
+	tool.SetCredentials(map[string][]string{"api.github.com": {"GITHUB_TOKEN"}, "*.example.com": {"EXAMPLE_KEY"}})
+	tests := []struct {
+		host, env string
+		want      bool
+	}{
+		{"api.github.com", "GITHUB_TOKEN", true},
+		{"API.GitHub.com", "GITHUB_TOKEN", true},
+		{"api.github.com", "AWS_SECRET_ACCESS_KEY", false},
+		{"evil.com", "GITHUB_TOKEN", false},
+		{"a.example.com", "EXAMPLE_KEY", true},
+		{"example.com", "EXAMPLE_KEY", false},
+		{"badexample.com", "EXAMPLE_KEY", false},
+	}
+	for _, tt := range tests {
+		if got := tool.credentialAllowed(tt.host, tt.env); got != tt.want {
+			t.Errorf("credentialAllowed(%q, %q) = %v, want %v", tt.host, tt.env, got, tt.want)
+		}
+	}
+}
+
+func TestHttpRequest_CrossHostRedirect(t *testing.T) {
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "leaked key=%s", r.Header.Get("X-Api-Key"))
+	}))
+	defer other.Close()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/away":
+			http.Redirect(w, r, other.URL+"/", http.StatusFound)
+		case "/moved":
+			http.Redirect(w, r, "/here", http.StatusFound)
+		default:
+			fmt.Fprintf(w, "here key=%s", r.Header.Get("X-Api-Key"))
+		}
+	}))
+	defer srv.Close()
+
+	tool := NewHttpRequestTool(nil)
+	tool.getenv = func(string) string { return "s3cret" }
+	tool.SetCredentials(map[string][]string{"127.0.0.1": {"TEST_TOKEN"}})
+	auth := &HttpRequestAuth{Type: "header", Header: "X-Api-Key", Env: "TEST_TOKEN"}
+
+	result := runHttpRequest(t, tool, HttpRequestInput{URL: srv.URL + "/away", Auth: auth})
+	if strings.Contains(result, "leaked") || !strings.Contains(result, "302 Found") || !strings.Contains(result, "redirect to another host not followed") {
+		t.Errorf("expected the cross-host redirect to be returned, got:\n%s", result)
+	}
+	result = runHttpRequest(t, tool, HttpRequestInput{URL: srv.URL + "/moved", Auth: auth})
+	if !strings.Contains(result, "here key=[REDACTED]") {
+		t.Errorf("expected the same-host redirect to be followed, got:\n%s", result)
+	}
+}
+
+func TestHttpRequestSummary(t *testing.T) {
+	got := HttpRequestSummary([]byte(`{"method": "post", "url": "https://api.example.com", "auth": {"type": "bearer", "env": "API_TOKEN"}}`))
+	if want := "POST https://api.example.com (sends $API_TOKEN as bearer auth)"; got != want {
+		t.Errorf("HttpRequestSummary = %q, want %q", got, want)
+	}
+}
+
+func TestHttpRequest_Limits(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/big":
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte(strings.Repeat("a", httpRequestMaxBodySize+100)))
+		case "/image":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte{0x89, 'P', 'N', 'G', 0})
+		}
+	}))
+	defer srv.Close()
+
+	tool := NewHttpRequestTool(nil)
+	result := runHttpRequest(t, tool, HttpRequestInput{URL: srv.URL + "/big"})
+	if !strings.Contains(result, "(body truncated to 100000 of 1048576 characters)") ||
+		!strings.Contains(result, "(response truncated at 1048576 bytes)") {
+		t.Errorf("expected truncation notes, got tail:\n%s", result[len(result)-200:])
+	}
+
+	result = runHttpRequest(t, tool, HttpRequestInput{URL: srv.URL + "/image"})
+	if !strings.Contains(result, "(binary body: 5 bytes of image/png)") {
+		t.Errorf("expected binary summary, got:\n%s", result)
+	}
+
+	result = runHttpRequest(t, tool, HttpRequestInput{URL: "ftp://example.com/file"})
+	if !strings.HasPrefix(result, "Error: invalid URL") {
+		t.Errorf("expected invalid URL error, got %q", result)
+	}
+}
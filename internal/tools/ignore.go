@@ -0,0 +1,156 @@
+package tools
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// defaultIgnoredDirs are skipped by file searches unless a pattern names
+// them explicitly.
+var defaultIgnoredDirs = map[string]bool{
+	".git":         true,
+	"node_modules": true,
+}
+
+// ignoreRule is one pattern from a .gitignore or .claudeignore file.
+type ignoreRule struct {
+	pattern  string
+	negate   bool
+	dirOnly  bool
+	anchored bool // matched against the path relative to the file's directory
+}
+
+// ignoreMatcher decides which paths a file search skips, following
+// .gitignore semantics: rules from ignore files in a directory apply to
+// everything beneath it, later rules override earlier ones, and deeper
+// files override shallower ones. Ignore files in directories above the
+// search root, up to the enclosing git repository, are honored too.
+//
+// It is not safe for concurrent use.
+type ignoreMatcher struct {
+	root      string   // top directory whose ignore files apply
+	fileNames []string // ignore files read in each directory
+	rules     map[string][]ignoreRule
+}
+
+// newIgnoreMatcher creates a matcher for a search under searchRoot.
+// .claudeignore files are always honored; .gitignore files only if
+// gitignore is true.
+func newIgnoreMatcher(searchRoot string, gitignore bool) *ignoreMatcher {
+	m := &ignoreMatcher{
+		root:      searchRoot,
+		fileNames: []string{".claudeignore"},
+		rules:     make(map[string][]ignoreRule),
+	}
+	if gitignore {
+		m.fileNames = []string{".gitignore", ".claudeignore"}
+	}
+	for dir := searchRoot; ; {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			m.root = dir
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	return m
+}
+
+// ignored reports whether path should be skipped.
+func (m *ignoreMatcher) ignored(path string, isDir bool) bool {
+	name := filepath.Base(path)
+	if isDir && defaultIgnoredDirs[name] {
+		return true
+	}
+	rel, err := filepath.Rel(m.root, path)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+
+	ignored := false
+	dir := ""
+	parts := strings.Split(rel, "/")
+	for i := range parts {
+		sub := strings.Join(parts[i:], "/")
+		for _, r := range m.rulesFor(dir) {
+			if r.dirOnly && !isDir {
+				continue
+			}
+			target := name
+			if r.anchored {
+				target = sub
+			}
+			if ok, _ := doublestar.Match(r.pattern, target); ok {
+				ignored = !r.negate
+			}
+		}
+		if dir == "" {
+			dir = parts[i]
+		} else {
+			dir += "/" + parts[i]
+		}
+	}
+	return ignored
+}
+
+// rulesFor returns the rules from the ignore files in dir, which is
+// relative to the matcher root, loading them on first use.
+func (m *ignoreMatcher) rulesFor(dir string) []ignoreRule {
+	if rules, ok := m.rules[dir]; ok {
+		return rules
+	}
+	var rules []ignoreRule
+	for _, name := range m.fileNames {
+		rules = append(rules, readIgnoreFile(filepath.Join(m.root, filepath.FromSlash(dir), name))...)
+	}
+	m.rules[dir] = rules
+	return rules
+}
+
+// readIgnoreFile parses an ignore file; a missing file has no rules.
+func readIgnoreFile(path string) []ignoreRule {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var rules []ignoreRule
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var r ignoreRule
+		if strings.HasPrefix(line, "!") {
+			r.negate = true
+			line = line[1:]
+		}
+		line = strings.TrimPrefix(line, `\`) // escaped leading "#" or "!"
+		if strings.HasSuffix(line, "/") {
+			r.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		// A slash anywhere but the end anchors the pattern to the
+		// directory containing the ignore file.
+		if strings.Contains(line, "/") {
+			r.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		r.pattern = line
+		rules = append(rules, r)
+	}
+	return rules
+}
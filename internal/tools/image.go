@@ -0,0 +1,161 @@
+package tools
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif" // register GIF decoding
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+const (
+	// imageMaxDimension is the longest edge sent to the API. Larger images
+	// are downscaled by the API anyway, so sending them only costs tokens.
+	imageMaxDimension = 2000
+
+	// imageMaxBytes keeps the base64-encoded image under the API's 5MB
+	// per-image limit.
+	imageMaxBytes = 3_750_000
+)
+
+// processedImage is an image ready to be sent as a content block.
+type processedImage struct {
+	data      []byte
+	mediaType string
+	width     int // 0 if unknown
+	height    int
+	origW     int
+	origH     int
+}
+
+// resized reports whether the image was scaled down.
+func (p *processedImage) resized() bool {
+	return p.width != p.origW || p.height != p.origH
+}
+
+// processImage prepares raw image file contents for the API. Images that are
+// already small enough are passed through unchanged; larger ones are
+// downscaled and re-encoded as PNG, or JPEG if PNG is still too large.
+func processImage(data []byte) (*processedImage, error) {
+	mediaType := http.DetectContentType(data)
+	switch mediaType {
+	case "image/png", "image/jpeg", "image/gif":
+	case "image/webp":
+		// The standard library can't decode WebP, so it can only be sent as is.
+		if len(data) > imageMaxBytes {
+			return nil, fmt.Errorf("WebP image is too large (%s); convert it to PNG or JPEG first", formatFileSize(int64(len(data))))
+		}
+		return &processedImage{data: data, mediaType: mediaType}, nil
+	default:
+		return nil, fmt.Errorf("unsupported image format (%s); supported formats are PNG, JPEG, GIF, and WebP", mediaType)
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decoding image: %w", err)
+	}
+	p := &processedImage{
+		data: data, mediaType: mediaType,
+		width: cfg.Width, height: cfg.Height,
+		origW: cfg.Width, origH: cfg.Height,
+	}
+	if len(data) <= imageMaxBytes && cfg.Width <= imageMaxDimension && cfg.Height <= imageMaxDimension {
+		return p, nil
+	}
+
+	// GIFs decode to their first frame.
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decoding image: %w", err)
+	}
+
+	w, h := fitDimensions(cfg.Width, cfg.Height, imageMaxDimension)
+	for w > 0 && h > 0 {
+		scaled := scaleImage(img, w, h)
+		if out, mt, ok := encodeImage(scaled); ok {
+			p.data, p.mediaType, p.width, p.height = out, mt, w, h
+			return p, nil
+		}
+		w, h = w/2, h/2
+	}
+	return nil, errors.New("image is too large to send even after resizing")
+}
+
+// fitDimensions scales w×h down so neither edge exceeds limit, keeping the
+// aspect ratio.
+func fitDimensions(w, h, limit int) (int, int) {
+	if w <= limit && h <= limit {
+		return w, h
+	}
+	if w >= h {
+		return limit, max(1, h*limit/w)
+	}
+	return max(1, w*limit/h), limit
+}
+
+// encodeImage encodes img as PNG, falling back to JPEG at decreasing quality
+// when the PNG exceeds imageMaxBytes. It reports false if nothing fits.
+func encodeImage(img image.Image) ([]byte, string, bool) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err == nil && buf.Len() <= imageMaxBytes {
+		return buf.Bytes(), "image/png", true
+	}
+
+	// JPEG has no alpha channel; flatten onto white so transparent areas
+	// don't turn black.
+	b := img.Bounds()
+	flat := image.NewRGBA(b)
+	draw.Draw(flat, b, &image.Uniform{C: color.White}, image.Point{}, draw.Src)
+	draw.Draw(flat, b, img, b.Min, draw.Over)
+	for _, quality := range []int{85, 60, 40} {
+		buf.Reset()
+		if err := jpeg.Encode(&buf, flat, &jpeg.Options{Quality: quality}); err == nil && buf.Len() <= imageMaxBytes {
+			return buf.Bytes(), "image/jpeg", true
+		}
+	}
+	return nil, "", false
+}
+
+// scaleImage downscales src to w×h by averaging the source pixels covered by
+// each destination pixel.
+func scaleImage(src image.Image, w, h int) *image.NRGBA {
+	sb := src.Bounds()
+	sw, sh := sb.Dx(), sb.Dy()
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		y0 := sb.Min.Y + y*sh/h
+		y1 := max(y0+1, sb.Min.Y+(y+1)*sh/h)
+		for x := 0; x < w; x++ {
+			x0 := sb.Min.X + x*sw/w
+			x1 := max(x0+1, sb.Min.X+(x+1)*sw/w)
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					// RGBA returns alpha-premultiplied 16-bit values.
+					pr, pg, pb, pa := src.At(sx, sy).RGBA()
+					r += uint64(pr)
+					g += uint64(pg)
+					b += uint64(pb)
+					a += uint64(pa)
+					n++
+				}
+			}
+			c := color.NRGBA{}
+			if a > 0 {
+				c = color.NRGBA{
+					R: uint8(r * 0xff / a),
+					G: uint8(g * 0xff / a),
+					B: uint8(b * 0xff / a),
+					A: uint8(a / n >> 8),
+				}
+			}
+			dst.SetNRGBA(x, y, c)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,141 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/anthropics/claude-code-go/internal/config"
+)
+
+// allToolsLimit is the settings key for limits on all tool calls together.
+const allToolsLimit = "*"
+
+// waitsOnTools are the tools whose calls wait on other tool calls: an
+// Agent's sub-agent runs tools, and TaskOutput can wait for a background
+// agent. They don't take a slot of the all-tools concurrency limit, which
+// the calls they wait on may need, and when nested in an agent they don't
+// take one of their own tool's either, since the parent agent holds it.
+var waitsOnTools = map[string]bool{"Agent": true, "TaskOutput": true}
+
+// toolLimits enforces the concurrency and rate limits from settings.
+// Calls over a concurrency limit wait for a slot; calls over a rate limit
+// are rejected with a message telling the model when to retry, rather than
+// blocking the loop for up to a minute.
+type toolLimits struct {
+	mu       sync.Mutex
+	limiters map[string]*toolLimiter // by tool name, including allToolsLimit
+	now      func() time.Time
+}
+
+// toolLimiter tracks one limit.
+type toolLimiter struct {
+	limit  config.ToolLimit
+	slots  chan struct{} // buffered to MaxConcurrent; nil if unlimited
+	starts []time.Time   // call start times within the last minute
+}
+
+func newToolLimits(limits map[string]config.ToolLimit) *toolLimits {
+	l := &toolLimits{limiters: make(map[string]*toolLimiter), now: time.Now}
+	for name, limit := range limits {
+		if limit.MaxConcurrent <= 0 && limit.PerMinute <= 0 {
+			continue
+		}
+		lim := &toolLimiter{limit: limit}
+		if limit.MaxConcurrent > 0 {
+			lim.slots = make(chan struct{}, limit.MaxConcurrent)
+		}
+		l.limiters[name] = lim
+	}
+	return l
+}
+
+// acquire waits for a concurrency slot for the named tool and records the
+// call against its rate limits. It returns a function that releases the
+// slot, or a message explaining which rate limit was hit.
+func (l *toolLimits) acquire(ctx context.Context, name string) (release func(), msg string, err error) {
+	tool, all := l.limiters[name], l.limiters[allToolsLimit]
+	if tool == nil && all == nil {
+		return func() {}, "", nil
+	}
+
+	l.mu.Lock()
+	now := l.now()
+	for _, lim := range []*toolLimiter{tool, all} {
+		if wait := lim.rateWait(now); wait > 0 {
+			l.mu.Unlock()
+			scope := name
+			if lim == all {
+				scope = "all tools"
+			}
+			return nil, fmt.Sprintf("Rate limit exceeded: %s is limited to %d calls per minute. Try again in %s.",
+				scope, lim.limit.PerMinute, wait.Round(time.Second)), nil
+		}
+	}
+	tool.record(now)
+	all.record(now)
+	l.mu.Unlock()
+
+	// Take the tool's own slot before the shared one, so a call waiting on
+	// its tool doesn't hold a slot other tools could use.
+	slotted := []*toolLimiter{tool, all}
+	if waitsOnTools[name] {
+		slotted = []*toolLimiter{tool}
+		if len(agentChain(ctx)) > 0 {
+			slotted = nil
+		}
+	}
+	var held []*toolLimiter
+	release = func() {
+		for _, lim := range held {
+			<-lim.slots
+		}
+	}
+	for _, lim := range slotted {
+		if lim == nil || lim.slots == nil {
+			continue
+		}
+		select {
+		case lim.slots <- struct{}{}:
+			held = append(held, lim)
+		case <-ctx.Done():
+			release()
+			return nil, "", ctx.Err()
+		}
+	}
+	return release, "", nil
+}
+
+// maxConcurrent returns the concurrency limit set for the named tool, or
+// 0 if there is none.
+func (l *toolLimits) maxConcurrent(name string) int {
+	if l == nil || l.limiters[name] == nil {
+		return 0
+	}
+	return l.limiters[name].limit.MaxConcurrent
+}
+
+// rateWait returns how long until another call fits in the rate limit, or
+// zero if one fits now. It also forgets calls older than a minute.
+func (lim *toolLimiter) rateWait(now time.Time) time.Duration {
+	if lim == nil || lim.limit.PerMinute <= 0 {
+		return 0
+	}
+	cutoff := now.Add(-time.Minute)
+	i := 0
+	for i < len(lim.starts) && !lim.starts[i].After(cutoff) {
+		i++
+	}
+	lim.starts = lim.starts[i:]
+	if len(lim.starts) < lim.limit.PerMinute {
+		return 0
+	}
+	return lim.starts[0].Sub(cutoff)
+}
+
+func (lim *toolLimiter) record(now time.Time) {
+	if lim != nil && lim.limit.PerMinute > 0 {
+		lim.starts = append(lim.starts, now)
+	}
+}
@@ -0,0 +1,187 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/anthropics/claude-code-go/internal/config"
+)
+
+// blockingTool runs until release is closed and tracks how many calls run
+// at once.
+type blockingTool struct {
+	mockTool
+	release chan struct{}
+	running atomic.Int32
+	peak    atomic.Int32
+}
+
+func (t *blockingTool) Execute(_ context.Context, _ json.RawMessage) (string, error) {
+	n := t.running.Add(1)
+	for {
+		p := t.peak.Load()
+		if n <= p || t.peak.CompareAndSwap(p, n) {
+			break
+		}
+	}
+	<-t.release
+	t.running.Add(-1)
+	return "ok", nil
+}
+
+func TestRegistry_ConcurrencyLimit(t *testing.T) {
+	tests := []struct {
+		name   string
+		limits map[string]config.ToolLimit
+	}{
+		{"per tool", map[string]config.ToolLimit{"Bash": {MaxConcurrent: 2}}},
+		{"all tools", map[string]config.ToolLimit{"*": {MaxConcurrent: 2}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool := &blockingTool{mockTool: mockTool{name: "Bash"}, release: make(chan struct{})}
+			r := NewRegistry(nil)
+			r.Register(tool)
+			r.SetLimits(tt.limits)
+
+			var wg sync.WaitGroup
+			for range 5 {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					r.Execute(context.Background(), "Bash", []byte(`{}`))
+				}()
+			}
+			time.Sleep(50 * time.Millisecond)
+			if got := tool.running.Load(); got != 2 {
+				t.Errorf("%d calls running, want 2", got)
+			}
+			close(tool.release)
+			wg.Wait()
+			if got := tool.peak.Load(); got != 2 {
+				t.Errorf("peak concurrency = %d, want 2", got)
+			}
+		})
+	}
+}
+
+func TestRegistry_ConcurrencyLimitCancel(t *testing.T) {
+	tool := &blockingTool{mockTool: mockTool{name: "Bash"}, release: make(chan struct{})}
+	defer close(tool.release)
+	r := NewRegistry(nil)
+	r.Register(tool)
+	r.SetLimits(map[string]config.ToolLimit{"Bash": {MaxConcurrent: 1}})
+
+	go r.Execute(context.Background(), "Bash", []byte(`{}`))
+	time.Sleep(20 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := r.Execute(ctx, "Bash", []byte(`{}`)); err != context.DeadlineExceeded {
+		t.Errorf("err = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+// delegatingTool runs a Bash call through the registry, as an Agent's
+// sub-agent would, nesting another delegating call first if depth > 0.
+type delegatingTool struct {
+	mockTool
+	registry *Registry
+	depth    int
+}
+
+func (t *delegatingTool) Execute(ctx context.Context, _ json.RawMessage) (string, error) {
+	ctx = withAgentChain(ctx, append(agentChain(ctx), agentFrame{id: "a"}))
+	if len(agentChain(ctx)) <= t.depth {
+		return t.registry.Execute(ctx, t.name, []byte(`{}`))
+	}
+	return t.registry.Execute(ctx, "Bash", []byte(`{}`))
+}
+
+func TestRegistry_ConcurrencyLimitAgent(t *testing.T) {
+	r := NewRegistry(nil)
+	r.Register(&mockTool{name: "Bash", result: "ok"})
+	r.Register(&delegatingTool{mockTool: mockTool{name: "Agent"}, registry: r, depth: 1})
+	r.SetLimits(map[string]config.ToolLimit{"*": {MaxConcurrent: 1}, "Agent": {MaxConcurrent: 1}})
+
+	done := make(chan string)
+	go func() {
+		out, _ := r.Execute(context.Background(), "Agent", []byte(`{}`))
+		done <- out
+	}()
+	select {
+	case out := <-done:
+		if out != "ok" {
+			t.Errorf("Agent output = %q, want the sub-agent's Bash output", out)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Agent call deadlocked waiting for a slot its sub-agent needs")
+	}
+}
+
+func TestRegistry_RateLimit(t *testing.T) {
+	r := NewRegistry(nil)
+	r.Register(&mockTool{name: "WebFetch", result: "page"})
+	r.Register(&mockTool{name: "Glob", result: "files"})
+	r.SetLimits(map[string]config.ToolLimit{
+		"WebFetch": {PerMinute: 2},
+		"*":        {PerMinute: 4},
+	})
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	r.limits.now = func() time.Time { return now }
+
+	exec := func(name string) (string, error) {
+		return r.Execute(context.Background(), name, []byte(`{}`))
+	}
+	for i := range 2 {
+		if out, err := exec("WebFetch"); err != nil || out != "page" {
+			t.Fatalf("call %d: got %q, %v", i, out, err)
+		}
+		now = now.Add(10 * time.Second)
+	}
+	out, err := exec("WebFetch")
+	if err == nil || !strings.Contains(out, "WebFetch is limited to 2 calls per minute. Try again in 40s") {
+		t.Errorf("got %q, %v", out, err)
+	}
+
+	// Rejected calls don't count against the global limit.
+	exec("Glob")
+	exec("Glob")
+	if out, _ := exec("Glob"); !strings.Contains(out, "all tools is limited to 4 calls per minute") {
+		t.Errorf("got %q", out)
+	}
+
+	now = now.Add(41 * time.Second)
+	if out, err := exec("WebFetch"); err != nil || out != "page" {
+		t.Errorf("after the window passed: got %q, %v", out, err)
+	}
+}
+
+func TestRegistryMaxParallel(t *testing.T) {
+	reg := NewRegistry(nil)
+	reg.Register(&mockTool{name: "Bash"})
+	reg.Register(NewAgentTool(nil, nil, nil, nil, nil, nil))
+
+	if got := reg.MaxParallel("Bash"); got != 1 {
+		t.Errorf("Bash = %d, want 1", got)
+	}
+	if got := reg.MaxParallel("Missing"); got != 1 {
+		t.Errorf("unknown tool = %d, want 1", got)
+	}
+	if got := reg.MaxParallel("Agent"); got != defaultAgentParallelism {
+		t.Errorf("Agent = %d, want the default %d", got, defaultAgentParallelism)
+	}
+
+	reg.SetLimits(map[string]config.ToolLimit{"Agent": {MaxConcurrent: 2}, "Bash": {MaxConcurrent: 3}})
+	if got := reg.MaxParallel("Agent"); got != 2 {
+		t.Errorf("Agent with maxConcurrent = %d, want 2", got)
+	}
+	if got := reg.MaxParallel("Bash"); got != 1 {
+		t.Errorf("Bash with maxConcurrent = %d, want 1: only ParallelTools run in parallel", got)
+	}
+}
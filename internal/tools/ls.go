@@ -0,0 +1,184 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// lsMaxEntries caps the number of entries listed for a single directory.
+const lsMaxEntries = 1000
+
+// LSInput is the input schema for the LS tool.
+type LSInput struct {
+	Path   string   `json:"path,omitempty"`
+	Ignore []string `json:"ignore,omitempty"`
+}
+
+// LSTool lists the contents of a directory.
+type LSTool struct {
+	workDir string
+}
+
+// NewLSTool creates a new LS tool with the given working directory.
+func NewLSTool(workDir string) *LSTool {
+	return &LSTool{workDir: workDir}
+}
+
+func (t *LSTool) Name() string { return "LS" }
+
+func (t *LSTool) Description() string {
+	return `Lists files and directories in a given path, with each entry's type, size, and modification time. The path defaults to the working directory; relative paths are resolved against it. You can optionally provide an array of glob patterns to ignore with the ignore parameter. Prefer the Glob and Grep tools if you know which files to search for.`
+}
+
+func (t *LSTool) InputSchema() json.RawMessage {
+	return json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "path": {
+      "type": "string",
+      "description": "The directory to list. Defaults to the working directory if omitted."
+    },
+    "ignore": {
+      "type": "array",
+      "items": {"type": "string"},
+      "description": "List of glob patterns to ignore, matched against entry names"
+    }
+  },
+  "additionalProperties": false
+}`)
+}
+
+func (t *LSTool) RequiresPermission(_ json.RawMessage) bool {
+	return false // Read-only.
+}
+
+func (t *LSTool) Execute(_ context.Context, input json.RawMessage) (string, error) {
+	var in LSInput
+	if err := json.Unmarshal(input, &in); err != nil {
+		return "", fmt.Errorf("parsing LS input: %w", err)
+	}
+
+	dir := t.workDir
+	if in.Path != "" {
+		if filepath.IsAbs(in.Path) {
+			dir = in.Path
+		} else {
+			dir = filepath.Join(t.workDir, in.Path)
+		}
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Sprintf("Error: directory not found: %s", dir), nil
+	}
+	if !info.IsDir() {
+		return fmt.Sprintf("Error: %s is not a directory", dir), nil
+	}
+
+	for _, pattern := range in.Ignore {
+		if !doublestar.ValidatePattern(pattern) {
+			return fmt.Sprintf("Error: invalid ignore pattern: %s", pattern), nil
+		}
+	}
+
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Sprintf("Error reading directory: %v", err), nil
+	}
+
+	type lsEntry struct {
+		kind    string
+		size    string
+		modTime string
+		name    string
+	}
+	var entries []lsEntry
+	ignored := 0
+	for _, de := range dirEntries {
+		if lsIgnored(de.Name(), in.Ignore) {
+			ignored++
+			continue
+		}
+		fi, err := de.Info()
+		if err != nil {
+			continue // removed since ReadDir
+		}
+		e := lsEntry{
+			kind:    "file",
+			size:    formatFileSize(fi.Size()),
+			modTime: fi.ModTime().Format("2006-01-02 15:04"),
+			name:    de.Name(),
+		}
+		switch {
+		case fi.Mode()&os.ModeSymlink != 0:
+			e.kind = "link"
+			if target, err := os.Readlink(filepath.Join(dir, de.Name())); err == nil {
+				e.name += " -> " + target
+			}
+		case fi.IsDir():
+			e.kind = "dir"
+			e.size = "-"
+			e.name += "/"
+		case !fi.Mode().IsRegular():
+			e.kind = "other"
+		}
+		entries = append(entries, e)
+	}
+
+	// Directories first, then by name.
+	sort.Slice(entries, func(i, j int) bool {
+		if (entries[i].kind == "dir") != (entries[j].kind == "dir") {
+			return entries[i].kind == "dir"
+		}
+		return entries[i].name < entries[j].name
+	})
+
+	var result strings.Builder
+	fmt.Fprintf(&result, "%s/\n", strings.TrimRight(dir, string(filepath.Separator)))
+	if len(entries) == 0 {
+		result.WriteString("(empty directory)")
+	}
+	for i, e := range entries {
+		if i == lsMaxEntries {
+			fmt.Fprintf(&result, "... (%d more entries not shown)\n", len(entries)-lsMaxEntries)
+			break
+		}
+		fmt.Fprintf(&result, "%-5s  %8s  %s  %s\n", e.kind, e.size, e.modTime, e.name)
+	}
+	if ignored > 0 {
+		fmt.Fprintf(&result, "(%d entries ignored)\n", ignored)
+	}
+
+	return strings.TrimRight(result.String(), "\n"), nil
+}
+
+// lsIgnored reports whether name matches any of the ignore patterns.
+func lsIgnored(name string, patterns []string) bool {
+	for _, p := range patterns {
+		if ok, _ := doublestar.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// formatFileSize renders a byte count in a compact human-readable form.
+func formatFileSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%c", float64(n)/float64(div), "KMGTPE"[exp])
+}
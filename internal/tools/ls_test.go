@@ -0,0 +1,94 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLSTool_Listing(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "b.go"), []byte("package b"), 0644)
+	os.WriteFile(filepath.Join(dir, "big.bin"), make([]byte, 3*1024), 0644)
+	os.Mkdir(filepath.Join(dir, "src"), 0755)
+	os.Symlink("b.go", filepath.Join(dir, "link.go"))
+
+	tool := NewLSTool(dir)
+	result, err := tool.Execute(context.Background(), json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lines := strings.Split(result, "\n")
+	if lines[0] != dir+"/" {
+		t.Errorf("expected header %q, got %q", dir+"/", lines[0])
+	}
+	if len(lines) != 5 {
+		t.Fatalf("expected header and 4 entries, got:\n%s", result)
+	}
+	if !strings.HasPrefix(lines[1], "dir") || !strings.HasSuffix(lines[1], "src/") {
+		t.Errorf("expected directory first, got %q", lines[1])
+	}
+	if !strings.Contains(result, "3.0K") {
+		t.Errorf("expected human-readable size, got:\n%s", result)
+	}
+	if !strings.Contains(result, "link.go -> b.go") {
+		t.Errorf("expected symlink target, got:\n%s", result)
+	}
+}
+
+func TestLSTool_Ignore(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "keep.go"), nil, 0644)
+	os.WriteFile(filepath.Join(dir, "skip.log"), nil, 0644)
+	os.Mkdir(filepath.Join(dir, "node_modules"), 0755)
+
+	tool := NewLSTool(dir)
+	input, _ := json.Marshal(LSInput{Ignore: []string{"*.log", "node_modules"}})
+	result, err := tool.Execute(context.Background(), input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(result, "keep.go") {
+		t.Errorf("expected keep.go, got:\n%s", result)
+	}
+	if strings.Contains(result, "skip.log") || strings.Contains(result, "node_modules") {
+		t.Errorf("ignored entries listed:\n%s", result)
+	}
+	if !strings.Contains(result, "(2 entries ignored)") {
+		t.Errorf("expected ignored count, got:\n%s", result)
+	}
+}
+
+func TestLSTool_Errors(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "file.txt"), nil, 0644)
+	tool := NewLSTool(dir)
+
+	for _, in := range []LSInput{
+		{Path: "missing"},
+		{Path: "file.txt"},
+		{Ignore: []string{"[bad"}},
+	} {
+		input, _ := json.Marshal(in)
+		result, err := tool.Execute(context.Background(), input)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.HasPrefix(result, "Error") {
+			t.Errorf("expected error for %+v, got %q", in, result)
+		}
+	}
+}
+
+func TestFormatFileSize(t *testing.T) {
+	tests := map[int64]string{0: "0B", 1023: "1023B", 1024: "1.0K", 1536: "1.5K", 5 << 20: "5.0M"}
+	for n, want := range tests {
+		if got := formatFileSize(n); got != want {
+			t.Errorf("formatFileSize(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
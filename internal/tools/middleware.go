@@ -0,0 +1,48 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Middleware intercepts every tool call dispatched by a Registry, so
+// cross-cutting features such as secret redaction, metrics, and audit
+// logging live in one place instead of in each tool.
+//
+// Middleware added with Registry.Use forms a chain: BeforeTool hooks run in
+// the order they were added, and AfterTool hooks in reverse order, so the
+// first middleware sees the original input and the final output.
+type Middleware interface {
+	// BeforeTool runs before the permission check, so permission rules see
+	// the input that will actually run. It returns the input to use; nil
+	// keeps it unchanged. A non-nil error vetoes the call, and the error
+	// message is returned to the model as the tool result.
+	BeforeTool(ctx context.Context, toolName string, input json.RawMessage) (json.RawMessage, error)
+
+	// AfterTool runs once the tool has executed, with its output and error.
+	// It returns the output to use, for example redacted or annotated. A
+	// non-nil error vetoes the result: the model gets the error message
+	// instead of the output.
+	AfterTool(ctx context.Context, toolName string, input json.RawMessage, output string, execErr error) (string, error)
+}
+
+// MiddlewareFuncs adapts a pair of functions to the Middleware interface.
+// Either function may be nil.
+type MiddlewareFuncs struct {
+	Before func(ctx context.Context, toolName string, input json.RawMessage) (json.RawMessage, error)
+	After  func(ctx context.Context, toolName string, input json.RawMessage, output string, execErr error) (string, error)
+}
+
+func (f MiddlewareFuncs) BeforeTool(ctx context.Context, toolName string, input json.RawMessage) (json.RawMessage, error) {
+	if f.Before == nil {
+		return nil, nil
+	}
+	return f.Before(ctx, toolName, input)
+}
+
+func (f MiddlewareFuncs) AfterTool(ctx context.Context, toolName string, input json.RawMessage, output string, execErr error) (string, error) {
+	if f.After == nil {
+		return output, nil
+	}
+	return f.After(ctx, toolName, input, output, execErr)
+}
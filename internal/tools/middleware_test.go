@@ -0,0 +1,131 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// echoTool returns its input, so tests can see rewritten input.
+type echoTool struct{ mockTool }
+
+func (t *echoTool) Execute(_ context.Context, input json.RawMessage) (string, error) {
+	return string(input), nil
+}
+
+func TestRegistry_MiddlewareOrder(t *testing.T) {
+	r := NewRegistry(nil)
+	r.Register(&echoTool{mockTool{name: "Echo"}})
+
+	var calls []string
+	tag := func(name string) Middleware {
+		return MiddlewareFuncs{
+			Before: func(_ context.Context, _ string, input json.RawMessage) (json.RawMessage, error) {
+				calls = append(calls, "before "+name)
+				return json.RawMessage(strings.TrimSuffix(string(input), "}") + `,"` + name + `":true}`), nil
+			},
+			After: func(_ context.Context, _ string, _ json.RawMessage, output string, _ error) (string, error) {
+				calls = append(calls, "after "+name)
+				return output + " " + name, nil
+			},
+		}
+	}
+	r.Use(tag("a"), tag("b"))
+
+	out, err := r.Execute(context.Background(), "Echo", []byte(`{"x":1}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `{"x":1,"a":true,"b":true} b a`; out != want {
+		t.Errorf("output = %q, want %q", out, want)
+	}
+	if got := strings.Join(calls, ", "); got != "before a, before b, after b, after a" {
+		t.Errorf("calls = %s", got)
+	}
+}
+
+func TestRegistry_MiddlewareRewriteBeforePermission(t *testing.T) {
+	perm := &recordingPermission{}
+	r := NewRegistry(perm)
+	r.Register(&mockTool{name: "Bash", needsPermission: true, result: "ok"})
+	r.Use(MiddlewareFuncs{Before: func(_ context.Context, _ string, _ json.RawMessage) (json.RawMessage, error) {
+		return json.RawMessage(`{"command":"ls"}`), nil
+	}})
+
+	if _, err := r.Execute(context.Background(), "Bash", []byte(`{"command":"rm -rf /"}`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if perm.input != `{"command":"ls"}` {
+		t.Errorf("permission saw %s, want the rewritten input", perm.input)
+	}
+}
+
+func TestRegistry_MiddlewareVeto(t *testing.T) {
+	tool := &countingTool{mockTool: mockTool{name: "Bash", result: "secret output"}}
+	r := NewRegistry(nil)
+	r.Register(tool)
+	r.Use(MiddlewareFuncs{Before: func(_ context.Context, _ string, input json.RawMessage) (json.RawMessage, error) {
+		if strings.Contains(string(input), "curl") {
+			return nil, errors.New("network commands are not allowed")
+		}
+		return nil, nil
+	}})
+
+	out, err := r.Execute(context.Background(), "Bash", []byte(`{"command":"curl example.com"}`))
+	if err == nil || out != "Tool call blocked: network commands are not allowed" {
+		t.Errorf("got %q, %v", out, err)
+	}
+	if tool.calls != 0 {
+		t.Errorf("tool ran %d times after a veto", tool.calls)
+	}
+
+	r.Use(MiddlewareFuncs{After: func(_ context.Context, _ string, _ json.RawMessage, output string, _ error) (string, error) {
+		if strings.Contains(output, "secret") {
+			return "", errors.New("output contains a secret")
+		}
+		return output, nil
+	}})
+	out, err = r.Execute(context.Background(), "Bash", []byte(`{"command":"ls"}`))
+	if err == nil || out != "Tool result withheld: output contains a secret" {
+		t.Errorf("got %q, %v", out, err)
+	}
+}
+
+func TestRegistry_MiddlewareSeesToolError(t *testing.T) {
+	r := NewRegistry(nil)
+	r.Register(&mockTool{name: "Fail", result: "partial", err: errors.New("boom")})
+	var seen error
+	r.Use(MiddlewareFuncs{After: func(_ context.Context, _ string, _ json.RawMessage, output string, execErr error) (string, error) {
+		seen = execErr
+		return output + " (annotated)", nil
+	}})
+
+	out, err := r.Execute(context.Background(), "Fail", []byte(`{}`))
+	if err == nil || err.Error() != "boom" || out != "partial (annotated)" {
+		t.Errorf("got %q, %v", out, err)
+	}
+	if seen == nil || seen.Error() != "boom" {
+		t.Errorf("middleware saw error %v, want boom", seen)
+	}
+}
+
+// recordingPermission allows every call and records the last input.
+type recordingPermission struct{ input string }
+
+func (p *recordingPermission) RequestPermission(_ context.Context, _ string, input json.RawMessage) (bool, error) {
+	p.input = string(input)
+	return true, nil
+}
+
+// countingTool counts how often it runs.
+type countingTool struct {
+	mockTool
+	calls int
+}
+
+func (t *countingTool) Execute(ctx context.Context, input json.RawMessage) (string, error) {
+	t.calls++
+	return t.mockTool.Execute(ctx, input)
+}
@@ -2,18 +2,20 @@ package tools
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
-	"sync"
 )
 
 // NotebookEditInput is the input schema for the NotebookEdit tool.
 type NotebookEditInput struct {
 	NotebookPath string  `json:"notebook_path"`
 	CellID       *string `json:"cell_id,omitempty"`
+	CellIndex    *int    `json:"cell_index,omitempty"` // 0-based, as shown by NotebookRead
 	NewSource    string  `json:"new_source"`
 	CellType     *string `json:"cell_type,omitempty"`
 	EditMode     *string `json:"edit_mode,omitempty"` // replace, insert, delete
@@ -37,18 +39,52 @@ type NotebookCell struct {
 	ExecutionCount *int                   `json:"execution_count,omitempty"`
 }
 
+// MarshalJSON writes outputs and execution_count (null when unset) on code
+// cells only, as nbformat requires.
+func (c NotebookCell) MarshalJSON() ([]byte, error) {
+	type plain NotebookCell // drops this method to avoid recursion
+	if c.CellType != "code" {
+		c.Outputs = nil
+		c.ExecutionCount = nil
+		return json.Marshal(plain(c))
+	}
+	outputs := c.Outputs
+	if outputs == nil {
+		outputs = []interface{}{}
+	}
+	return json.Marshal(struct {
+		plain
+		Outputs        []interface{} `json:"outputs"`
+		ExecutionCount *int          `json:"execution_count"`
+	}{plain(c), outputs, c.ExecutionCount})
+}
+
 // NotebookEditTool edits Jupyter notebook cells.
-type NotebookEditTool struct{}
+type NotebookEditTool struct {
+	undo    *UndoStore
+	tracker *ReadTracker
+}
 
 // NewNotebookEditTool creates a new NotebookEdit tool.
 func NewNotebookEditTool() *NotebookEditTool {
 	return &NotebookEditTool{}
 }
 
+// SetUndoStore records each edit in store so it can be undone.
+func (t *NotebookEditTool) SetUndoStore(store *UndoStore) {
+	t.undo = store
+}
+
+// SetReadTracker makes edits fail if the notebook changed on disk since it
+// was last read.
+func (t *NotebookEditTool) SetReadTracker(tracker *ReadTracker) {
+	t.tracker = tracker
+}
+
 func (t *NotebookEditTool) Name() string { return "NotebookEdit" }
 
 func (t *NotebookEditTool) Description() string {
-	return `Edit Jupyter notebook (.ipynb) cells. Supports replacing cell content, inserting new cells, and deleting cells. The notebook_path must be an absolute path. Use cell_id to target a specific cell. Use edit_mode to specify the operation (replace, insert, or delete).`
+	return `Edit Jupyter notebook (.ipynb) cells. Supports replacing cell content, inserting new cells, and deleting cells. The notebook_path must be an absolute path. Use cell_id or cell_index (as shown by NotebookRead) to target a specific cell. Use edit_mode to specify the operation (replace, insert, or delete). Setting cell_type when replacing changes the cell's type. Replacing a code cell clears its outputs.`
 }
 
 func (t *NotebookEditTool) InputSchema() json.RawMessage {
@@ -63,14 +99,18 @@ func (t *NotebookEditTool) InputSchema() json.RawMessage {
       "type": "string",
       "description": "The ID of the cell to edit. For insert mode, the new cell is inserted after this cell."
     },
+    "cell_index": {
+      "type": "number",
+      "description": "The 0-based index of the cell to edit, as an alternative to cell_id. For insert mode, the new cell is inserted at this index."
+    },
     "new_source": {
       "type": "string",
-      "description": "The new source for the cell"
+      "description": "The new source for the cell. Not needed for delete."
     },
     "cell_type": {
       "type": "string",
       "enum": ["code", "markdown"],
-      "description": "The type of the cell. Required for insert mode."
+      "description": "The type of the cell. Defaults to code for insert mode; changes the cell's type in replace mode."
     },
     "edit_mode": {
       "type": "string",
@@ -78,7 +118,7 @@ func (t *NotebookEditTool) InputSchema() json.RawMessage {
       "description": "The type of edit (replace, insert, delete). Defaults to replace."
     }
   },
-  "required": ["notebook_path", "new_source"],
+  "required": ["notebook_path"],
   "additionalProperties": false
 }`)
 }
@@ -106,6 +146,10 @@ func (t *NotebookEditTool) Execute(_ context.Context, input json.RawMessage) (st
 		editMode = *in.EditMode
 	}
 
+	if msg := t.tracker.CheckStale(in.NotebookPath); msg != "" {
+		return msg, nil
+	}
+
 	// Read the notebook file.
 	data, err := os.ReadFile(in.NotebookPath)
 	if err != nil {
@@ -125,18 +169,30 @@ func (t *NotebookEditTool) Execute(_ context.Context, input json.RawMessage) (st
 	var resultCellID string
 	var resultCellType string
 
+	if in.CellType != nil && *in.CellType != "code" && *in.CellType != "markdown" {
+		return fmt.Sprintf("Error: unknown cell_type: %s", *in.CellType), nil
+	}
+
 	switch editMode {
 	case "replace":
-		idx := t.findCellIndex(&notebook, in.CellID)
-		if idx < 0 {
-			return "Error: cell not found", nil
+		idx, errMsg := t.findCell(&notebook, in.CellID, in.CellIndex)
+		if errMsg != "" {
+			return "Error: " + errMsg, nil
 		}
-		notebook.Cells[idx].Source = splitSource(in.NewSource)
+		cell := &notebook.Cells[idx]
+		cell.Source = splitSource(in.NewSource)
 		if in.CellType != nil {
-			notebook.Cells[idx].CellType = *in.CellType
+			cell.CellType = *in.CellType
 		}
-		resultCellID = notebook.Cells[idx].ID
-		resultCellType = notebook.Cells[idx].CellType
+		// Outputs belong to the old source; markdown cells have none.
+		if cell.CellType == "code" {
+			cell.Outputs = []interface{}{}
+		} else {
+			cell.Outputs = nil
+		}
+		cell.ExecutionCount = nil
+		resultCellID = cell.ID
+		resultCellType = cell.CellType
 
 	case "insert":
 		cellType := "code"
@@ -154,11 +210,18 @@ func (t *NotebookEditTool) Execute(_ context.Context, input json.RawMessage) (st
 		}
 
 		insertIdx := 0
-		if in.CellID != nil {
-			idx := t.findCellIndex(&notebook, in.CellID)
-			if idx >= 0 {
-				insertIdx = idx + 1
+		switch {
+		case in.CellIndex != nil:
+			if *in.CellIndex < 0 || *in.CellIndex > len(notebook.Cells) {
+				return fmt.Sprintf("Error: cell_index %d out of range (notebook has %d cells)", *in.CellIndex, len(notebook.Cells)), nil
+			}
+			insertIdx = *in.CellIndex
+		case in.CellID != nil && *in.CellID != "":
+			idx, errMsg := t.findCell(&notebook, in.CellID, nil)
+			if errMsg != "" {
+				return "Error: " + errMsg, nil
 			}
+			insertIdx = idx + 1
 		}
 
 		// Insert cell at position.
@@ -170,9 +233,9 @@ func (t *NotebookEditTool) Execute(_ context.Context, input json.RawMessage) (st
 		resultCellType = newCell.CellType
 
 	case "delete":
-		idx := t.findCellIndex(&notebook, in.CellID)
-		if idx < 0 {
-			return "Error: cell not found", nil
+		idx, errMsg := t.findCell(&notebook, in.CellID, in.CellIndex)
+		if errMsg != "" {
+			return "Error: " + errMsg, nil
 		}
 		resultCellID = notebook.Cells[idx].ID
 		resultCellType = notebook.Cells[idx].CellType
@@ -189,25 +252,23 @@ func (t *NotebookEditTool) Execute(_ context.Context, input json.RawMessage) (st
 	}
 	output = append(output, '\n')
 
-	if err := os.WriteFile(in.NotebookPath, output, 0644); err != nil {
-		return fmt.Sprintf("Error writing notebook: %v", err), nil
+	perm := os.FileMode(0644)
+	if info, err := os.Stat(in.NotebookPath); err == nil {
+		perm = info.Mode().Perm()
 	}
-
-	// Detect language from notebook metadata.
-	language := "python"
-	if notebook.Metadata != nil {
-		if kernelspec, ok := notebook.Metadata["kernelspec"].(map[string]interface{}); ok {
-			if lang, ok := kernelspec["language"].(string); ok {
-				language = lang
-			}
-		}
+	if err := t.undo.Snapshot(in.NotebookPath, t.Name()); err != nil {
+		return fmt.Sprintf("Error saving undo state: %v", err), nil
+	}
+	if err := writeFileAtomic(in.NotebookPath, output, perm); err != nil {
+		return fmt.Sprintf("Error writing notebook: %v", err), nil
 	}
+	t.tracker.RecordCurrent(in.NotebookPath)
 
 	result := map[string]interface{}{
 		"new_source":    in.NewSource,
 		"cell_id":       resultCellID,
 		"cell_type":     resultCellType,
-		"language":      language,
+		"language":      notebookLanguage(&notebook),
 		"edit_mode":     editMode,
 		"notebook_path": in.NotebookPath,
 		"original_file": originalFile,
@@ -217,21 +278,29 @@ func (t *NotebookEditTool) Execute(_ context.Context, input json.RawMessage) (st
 	return string(out), nil
 }
 
-// findCellIndex finds a cell by ID or returns 0 if no ID is specified.
-func (t *NotebookEditTool) findCellIndex(notebook *Notebook, cellID *string) int {
+// findCell finds a cell by index or ID, defaulting to the first cell if
+// neither is specified. It returns a message if there is no such cell.
+func (t *NotebookEditTool) findCell(notebook *Notebook, cellID *string, cellIndex *int) (int, string) {
+	if cellIndex != nil {
+		if *cellIndex < 0 || *cellIndex >= len(notebook.Cells) {
+			return -1, fmt.Sprintf("cell_index %d out of range (notebook has %d cells)", *cellIndex, len(notebook.Cells))
+		}
+		return *cellIndex, ""
+	}
+
 	if cellID == nil || *cellID == "" {
 		if len(notebook.Cells) > 0 {
-			return 0
+			return 0, ""
 		}
-		return -1
+		return -1, "notebook has no cells"
 	}
 
 	for i, cell := range notebook.Cells {
 		if cell.ID == *cellID {
-			return i
+			return i, ""
 		}
 	}
-	return -1
+	return -1, fmt.Sprintf("cell %s not found", *cellID)
 }
 
 // splitSource converts a string into an array of lines for notebook source format.
@@ -252,22 +321,10 @@ func splitSource(source string) []string {
 	return result
 }
 
-// generateCellID creates a simple unique cell ID.
+// generateCellID creates a random cell ID in the style Jupyter uses, so
+// IDs stay unique across edits made by separate runs.
 func generateCellID() string {
-	return fmt.Sprintf("cell-%d", cellIDCounter.Add(1))
-}
-
-// cellIDCounter is an atomic counter for generating cell IDs.
-var cellIDCounter atomicCounter
-
-type atomicCounter struct {
-	mu  sync.Mutex
-	val int64
-}
-
-func (c *atomicCounter) Add(delta int64) int64 {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.val += delta
-	return c.val
+	var b [4]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
 }
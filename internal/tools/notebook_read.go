@@ -0,0 +1,235 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// notebookMaxOutput caps the text rendered for a single cell output.
+const notebookMaxOutput = 10_000
+
+// NotebookReadInput is the input schema for the NotebookRead tool.
+type NotebookReadInput struct {
+	NotebookPath string  `json:"notebook_path"`
+	CellID       *string `json:"cell_id,omitempty"`
+	CellIndex    *int    `json:"cell_index,omitempty"`
+}
+
+// NotebookReadTool renders a Jupyter notebook's cells and outputs.
+type NotebookReadTool struct {
+	tracker *ReadTracker
+}
+
+// NewNotebookReadTool creates a new NotebookRead tool.
+func NewNotebookReadTool() *NotebookReadTool {
+	return &NotebookReadTool{}
+}
+
+// SetReadTracker records each notebook read in tracker.
+func (t *NotebookReadTool) SetReadTracker(tracker *ReadTracker) {
+	t.tracker = tracker
+}
+
+func (t *NotebookReadTool) Name() string { return "NotebookRead" }
+
+func (t *NotebookReadTool) Description() string {
+	return `Reads a Jupyter notebook (.ipynb) and returns its cells with their index, ID, type, source, and outputs. The notebook_path must be an absolute path. Use cell_id or cell_index to read a single cell. The indices and IDs shown can be passed to NotebookEdit.`
+}
+
+func (t *NotebookReadTool) InputSchema() json.RawMessage {
+	return json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "notebook_path": {
+      "type": "string",
+      "description": "The absolute path to the Jupyter notebook file"
+    },
+    "cell_id": {
+      "type": "string",
+      "description": "Only return the cell with this ID"
+    },
+    "cell_index": {
+      "type": "number",
+      "description": "Only return the cell at this 0-based index"
+    }
+  },
+  "required": ["notebook_path"],
+  "additionalProperties": false
+}`)
+}
+
+func (t *NotebookReadTool) RequiresPermission(_ json.RawMessage) bool {
+	return false // Read-only.
+}
+
+func (t *NotebookReadTool) Execute(_ context.Context, input json.RawMessage) (string, error) {
+	var in NotebookReadInput
+	if err := json.Unmarshal(input, &in); err != nil {
+		return "", fmt.Errorf("parsing NotebookRead input: %w", err)
+	}
+
+	if in.NotebookPath == "" {
+		return "Error: notebook_path is required", nil
+	}
+	if !filepath.IsAbs(in.NotebookPath) {
+		return "Error: notebook_path must be an absolute path", nil
+	}
+
+	info, _ := os.Stat(in.NotebookPath)
+	notebook, errMsg := loadNotebook(in.NotebookPath)
+	if errMsg != "" {
+		return errMsg, nil
+	}
+	t.tracker.Record(in.NotebookPath, info)
+
+	if in.CellID == nil && in.CellIndex == nil {
+		return renderNotebook(notebook, in.NotebookPath), nil
+	}
+
+	idx, errMsg := (&NotebookEditTool{}).findCell(notebook, in.CellID, in.CellIndex)
+	if errMsg != "" {
+		return "Error: " + errMsg, nil
+	}
+	var b strings.Builder
+	renderNotebookCell(&b, idx, notebook.Cells[idx])
+	return strings.TrimRight(b.String(), "\n"), nil
+}
+
+// loadNotebook reads and parses a notebook file. On failure it returns a
+// user-facing error message.
+func loadNotebook(path string) (*Notebook, string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Sprintf("Error: notebook not found: %s", path)
+		}
+		return nil, fmt.Sprintf("Error reading notebook: %v", err)
+	}
+	var notebook Notebook
+	if err := json.Unmarshal(data, &notebook); err != nil {
+		return nil, fmt.Sprintf("Error parsing notebook: %v", err)
+	}
+	return &notebook, ""
+}
+
+// notebookLanguage returns the kernel language from notebook metadata.
+func notebookLanguage(notebook *Notebook) string {
+	if kernelspec, ok := notebook.Metadata["kernelspec"].(map[string]interface{}); ok {
+		if lang, ok := kernelspec["language"].(string); ok {
+			return lang
+		}
+	}
+	if info, ok := notebook.Metadata["language_info"].(map[string]interface{}); ok {
+		if lang, ok := info["name"].(string); ok {
+			return lang
+		}
+	}
+	return "python"
+}
+
+// renderNotebook renders every cell of a notebook with a short header.
+func renderNotebook(notebook *Notebook, path string) string {
+	if len(notebook.Cells) == 0 {
+		return "(empty notebook)"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Notebook %s (%s, %d cells)\n\n", path, notebookLanguage(notebook), len(notebook.Cells))
+	for i, cell := range notebook.Cells {
+		renderNotebookCell(&b, i, cell)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// renderNotebookCell renders one cell: a header line with its index, ID,
+// type, and execution count, then its source and outputs.
+func renderNotebookCell(b *strings.Builder, index int, cell NotebookCell) {
+	fmt.Fprintf(b, "--- Cell %d [%s]", index, cell.CellType)
+	if cell.ID != "" {
+		fmt.Fprintf(b, " id=%s", cell.ID)
+	}
+	if cell.ExecutionCount != nil {
+		fmt.Fprintf(b, " execution_count=%d", *cell.ExecutionCount)
+	}
+	b.WriteString(" ---\n")
+
+	writeLine(b, flattenNotebookSource(cell.Source))
+	for _, out := range cell.Outputs {
+		o, ok := out.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		outputType, _ := o["output_type"].(string)
+		text := notebookOutputText(o)
+		if text == "" {
+			continue
+		}
+		if len(text) > notebookMaxOutput {
+			text = text[:notebookMaxOutput] + "\n... (output truncated)"
+		}
+		fmt.Fprintf(b, "[Output: %s]\n", outputType)
+		writeLine(b, text)
+	}
+	b.WriteString("\n")
+}
+
+// notebookOutputText extracts the readable text of a cell output. Rich
+// outputs without a text/plain form are summarized by MIME type.
+func notebookOutputText(o map[string]interface{}) string {
+	switch o["output_type"] {
+	case "stream":
+		return flattenNotebookSource(o["text"])
+	case "error":
+		ename, _ := o["ename"].(string)
+		evalue, _ := o["evalue"].(string)
+		text := ename + ": " + evalue
+		if tb := flattenNotebookTraceback(o["traceback"]); tb != "" {
+			text += "\n" + tb
+		}
+		return text
+	}
+
+	data, _ := o["data"].(map[string]interface{})
+	var parts []string
+	if plain := flattenNotebookSource(data["text/plain"]); plain != "" {
+		parts = append(parts, plain)
+	}
+	var mimeTypes []string
+	for mime := range data {
+		if mime != "text/plain" {
+			mimeTypes = append(mimeTypes, mime)
+		}
+	}
+	sort.Strings(mimeTypes)
+	for _, mime := range mimeTypes {
+		parts = append(parts, "["+mime+" output]")
+	}
+	return strings.Join(parts, "\n")
+}
+
+// flattenNotebookTraceback joins traceback lines, removing the terminal
+// color codes Jupyter kernels emit.
+func flattenNotebookTraceback(tb interface{}) string {
+	lines, _ := tb.([]interface{})
+	var out []string
+	for _, l := range lines {
+		if s, ok := l.(string); ok {
+			out = append(out, ansi.Strip(s))
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// writeLine writes s followed by a newline if it doesn't already end in one.
+func writeLine(b *strings.Builder, s string) {
+	b.WriteString(s)
+	if !strings.HasSuffix(s, "\n") {
+		b.WriteString("\n")
+	}
+}
@@ -0,0 +1,173 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testNotebook = `{
+ "cells": [
+  {"cell_type": "markdown", "id": "intro", "metadata": {}, "source": ["# Title\n", "Some text"]},
+  {"cell_type": "code", "id": "calc", "metadata": {}, "execution_count": 3,
+   "source": "x = 1 + 1\nx",
+   "outputs": [
+    {"output_type": "execute_result", "execution_count": 3, "metadata": {},
+     "data": {"text/plain": ["2"], "image/png": "iVBOR"}}
+   ]},
+  {"cell_type": "code", "id": "boom", "metadata": {}, "execution_count": 4,
+   "source": "1/0",
+   "outputs": [
+    {"output_type": "error", "ename": "ZeroDivisionError", "evalue": "division by zero",
+     "traceback": ["\u001b[0;31mZeroDivisionError\u001b[0m: division by zero"]}
+   ]}
+ ],
+ "metadata": {"kernelspec": {"language": "python", "name": "python3"}},
+ "nbformat": 4,
+ "nbformat_minor": 5
+}`
+
+func writeTestNotebook(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.ipynb")
+	if err := os.WriteFile(path, []byte(testNotebook), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func execJSON(t *testing.T, tool Tool, in any) string {
+	t.Helper()
+	input, _ := json.Marshal(in)
+	result, err := tool.Execute(context.Background(), input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return result
+}
+
+func TestNotebookRead_AllCells(t *testing.T) {
+	path := writeTestNotebook(t)
+	got := execJSON(t, NewNotebookReadTool(), NotebookReadInput{NotebookPath: path})
+
+	for _, want := range []string{
+		"(python, 3 cells)",
+		"--- Cell 0 [markdown] id=intro ---\n# Title\nSome text",
+		"--- Cell 1 [code] id=calc execution_count=3 ---\nx = 1 + 1\nx",
+		"[Output: execute_result]\n2\n[image/png output]",
+		"ZeroDivisionError: division by zero",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "\x1b[") {
+		t.Errorf("traceback color codes not stripped:\n%s", got)
+	}
+}
+
+func TestNotebookRead_SingleCell(t *testing.T) {
+	path := writeTestNotebook(t)
+	tool := NewNotebookReadTool()
+
+	idx := 2
+	if got := execJSON(t, tool, NotebookReadInput{NotebookPath: path, CellIndex: &idx}); !strings.HasPrefix(got, "--- Cell 2 [code] id=boom") || strings.Contains(got, "calc") {
+		t.Errorf("unexpected cell output:\n%s", got)
+	}
+	id := "calc"
+	if got := execJSON(t, tool, NotebookReadInput{NotebookPath: path, CellID: &id}); !strings.HasPrefix(got, "--- Cell 1 [code] id=calc") {
+		t.Errorf("unexpected cell output:\n%s", got)
+	}
+	idx = 9
+	if got := execJSON(t, tool, NotebookReadInput{NotebookPath: path, CellIndex: &idx}); !strings.Contains(got, "out of range") {
+		t.Errorf("expected range error, got %q", got)
+	}
+}
+
+func readNotebookFile(t *testing.T, path string) Notebook {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var nb Notebook
+	if err := json.Unmarshal(data, &nb); err != nil {
+		t.Fatal(err)
+	}
+	return nb
+}
+
+func TestNotebookEdit_ReplaceClearsOutputs(t *testing.T) {
+	path := writeTestNotebook(t)
+	idx := 1
+	execJSON(t, NewNotebookEditTool(), NotebookEditInput{NotebookPath: path, CellIndex: &idx, NewSource: "x = 3"})
+
+	nb := readNotebookFile(t, path)
+	cell := nb.Cells[1]
+	if flattenNotebookSource(cell.Source) != "x = 3" {
+		t.Errorf("source not replaced: %v", cell.Source)
+	}
+	if len(cell.Outputs) != 0 || cell.ExecutionCount != nil {
+		t.Errorf("expected outputs cleared, got %v / %v", cell.Outputs, cell.ExecutionCount)
+	}
+
+	// Code cells must keep execution_count (as null) per nbformat.
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), `"execution_count": null`) {
+		t.Errorf("expected null execution_count in file:\n%s", data)
+	}
+}
+
+func TestNotebookEdit_ChangeCellType(t *testing.T) {
+	path := writeTestNotebook(t)
+	id := "calc"
+	markdown := "markdown"
+	execJSON(t, NewNotebookEditTool(), NotebookEditInput{NotebookPath: path, CellID: &id, NewSource: "Now prose", CellType: &markdown})
+
+	data, _ := os.ReadFile(path)
+	var raw struct {
+		Cells []map[string]json.RawMessage `json:"cells"`
+	}
+	json.Unmarshal(data, &raw)
+	cell := raw.Cells[1]
+	if string(cell["cell_type"]) != `"markdown"` {
+		t.Errorf("cell type not changed: %s", cell["cell_type"])
+	}
+	if _, ok := cell["outputs"]; ok {
+		t.Error("markdown cell should not have outputs")
+	}
+	if _, ok := cell["execution_count"]; ok {
+		t.Error("markdown cell should not have execution_count")
+	}
+}
+
+func TestNotebookEdit_InsertAndDelete(t *testing.T) {
+	path := writeTestNotebook(t)
+	tool := NewNotebookEditTool()
+	insert, del := "insert", "delete"
+
+	idx := 3
+	execJSON(t, tool, NotebookEditInput{NotebookPath: path, CellIndex: &idx, NewSource: "print('end')", EditMode: &insert})
+	nb := readNotebookFile(t, path)
+	if len(nb.Cells) != 4 || flattenNotebookSource(nb.Cells[3].Source) != "print('end')" {
+		t.Fatalf("expected cell appended at index 3, got %d cells", len(nb.Cells))
+	}
+	if nb.Cells[3].ID == "" || nb.Cells[3].CellType != "code" {
+		t.Errorf("expected new code cell with ID, got %+v", nb.Cells[3])
+	}
+
+	id := "intro"
+	execJSON(t, tool, NotebookEditInput{NotebookPath: path, CellID: &id, EditMode: &del})
+	nb = readNotebookFile(t, path)
+	if len(nb.Cells) != 3 || nb.Cells[0].ID != "calc" {
+		t.Errorf("expected intro deleted, got first cell %q", nb.Cells[0].ID)
+	}
+
+	missing := "nope"
+	if got := execJSON(t, tool, NotebookEditInput{NotebookPath: path, CellID: &missing, NewSource: "x", EditMode: &insert}); !strings.Contains(got, "not found") {
+		t.Errorf("expected error inserting after unknown cell, got %q", got)
+	}
+}
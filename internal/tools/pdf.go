@@ -0,0 +1,217 @@
+package tools
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/anthropics/claude-code-go/internal/api"
+	"github.com/anthropics/claude-code-go/internal/conversation"
+)
+
+const (
+	// pdfMaxPagesPerRead caps how many pages of text one read returns when
+	// no page range is given.
+	pdfMaxPagesPerRead = 20
+
+	// pdfMaxImagePages caps how many pages one read renders as images.
+	pdfMaxImagePages = 10
+
+	// pdfImageDPI is the resolution pages are rendered at in image mode.
+	pdfImageDPI = 100
+
+	pdfMaxOutput = 200_000
+)
+
+var pdfInfoPages = regexp.MustCompile(`(?m)^Pages:\s+(\d+)`)
+
+// readPDF returns the text of a range of PDF pages, one section per page,
+// extracted with pdftotext. With asImages it instead renders the pages with
+// pdftoppm and attaches them as image blocks.
+func (t *FileReadTool) readPDF(ctx context.Context, filePath, pages string, asImages bool) (string, error) {
+	total := pdfPageCount(ctx, filePath)
+
+	limit := pdfMaxPagesPerRead
+	if asImages {
+		limit = pdfMaxImagePages
+	}
+	first, last, err := parsePageRange(pages, total)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err), nil
+	}
+	clamped := false
+	if (pages == "" || strings.HasSuffix(pages, "-")) && last > first+limit-1 {
+		last, clamped = first+limit-1, true
+	}
+	if asImages && last-first+1 > pdfMaxImagePages {
+		return fmt.Sprintf("Error: at most %d pages can be read as images at once", pdfMaxImagePages), nil
+	}
+
+	header := fmt.Sprintf("PDF: %s", filePath)
+	if total > 0 {
+		header += fmt.Sprintf(" (%d pages)", total)
+	}
+	footer := ""
+	switch {
+	case total > last:
+		footer = fmt.Sprintf("\n\n(pages %d-%d not shown; use pages to read more)", last+1, total)
+	case total == 0 && clamped:
+		footer = fmt.Sprintf("\n\n(read up to page %d; use pages to read more)", last)
+	}
+
+	if asImages {
+		n, errMsg := t.attachPDFPages(ctx, filePath, first, last)
+		if errMsg != "" {
+			return errMsg, nil
+		}
+		return fmt.Sprintf("%s\nPages %d-%d attached as %d images.%s", header, first, first+n-1, n, footer), nil
+	}
+
+	args := []string{"-layout", "-f", strconv.Itoa(first), "-l", strconv.Itoa(last), filePath, "-"}
+	out, err := exec.CommandContext(ctx, "pdftotext", args...).Output()
+	if err != nil {
+		return pdfToolError("pdftotext", err), nil
+	}
+
+	// pdftotext ends every page with a form feed.
+	pageTexts := strings.Split(strings.TrimSuffix(string(out), "\f"), "\f")
+	var b strings.Builder
+	b.WriteString(header + "\n")
+	hasText := false
+	for i, text := range pageTexts {
+		text = strings.TrimRight(text, " \n")
+		if strings.TrimSpace(text) != "" {
+			hasText = true
+		}
+		fmt.Fprintf(&b, "\n--- Page %d ---\n%s\n", first+i, text)
+	}
+	if !hasText {
+		return fmt.Sprintf("%s\n(no extractable text on pages %d-%d; the PDF may be scanned. Use page_images to view the pages as images.)", header, first, last), nil
+	}
+
+	text := strings.TrimRight(b.String(), "\n")
+	if len(text) > pdfMaxOutput {
+		text = text[:pdfMaxOutput] + "\n... (PDF content truncated; use pages to read a smaller range)"
+	}
+	return text + footer, nil
+}
+
+// attachPDFPages renders pages first..last to PNG and attaches them to the
+// tool result. It returns the number of pages attached, or a user-facing
+// error message.
+func (t *FileReadTool) attachPDFPages(ctx context.Context, filePath string, first, last int) (int, string) {
+	dir, err := os.MkdirTemp("", "pdf-pages-")
+	if err != nil {
+		return 0, fmt.Sprintf("Error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	args := []string{"-png", "-r", strconv.Itoa(pdfImageDPI),
+		"-f", strconv.Itoa(first), "-l", strconv.Itoa(last), filePath, filepath.Join(dir, "page")}
+	if err := exec.CommandContext(ctx, "pdftoppm", args...).Run(); err != nil {
+		return 0, pdfToolError("pdftoppm", err)
+	}
+
+	// pdftoppm zero-pads page numbers, so names sort in page order.
+	files, _ := filepath.Glob(filepath.Join(dir, "page*.png"))
+	sort.Strings(files)
+	if len(files) == 0 {
+		return 0, fmt.Sprintf("Error: no pages rendered from %s", filePath)
+	}
+
+	for i, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return 0, fmt.Sprintf("Error reading rendered page: %v", err)
+		}
+		img, err := processImage(data)
+		if err != nil {
+			return 0, fmt.Sprintf("Error: page %d: %v", first+i, err)
+		}
+		block := api.ContentBlock{
+			Type: api.ContentTypeImage,
+			Source: &api.ImageSource{
+				Type:      "base64",
+				MediaType: img.mediaType,
+				Data:      base64.StdEncoding.EncodeToString(img.data),
+			},
+		}
+		if !conversation.AttachToolResultBlock(ctx, block) {
+			return 0, "Error: page images are not available in this context; read the PDF as text instead"
+		}
+	}
+	return len(files), ""
+}
+
+// pdfPageCount returns the number of pages in a PDF according to pdfinfo,
+// or 0 if it can't be determined.
+func pdfPageCount(ctx context.Context, filePath string) int {
+	out, err := exec.CommandContext(ctx, "pdfinfo", filePath).Output()
+	if err != nil {
+		return 0
+	}
+	m := pdfInfoPages.FindSubmatch(out)
+	if m == nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(string(m[1]))
+	return n
+}
+
+// parsePageRange parses a 1-based page range: "3", "1-5", or "10-" (to the
+// end). An empty range means every page. total is the page count, or 0 if
+// unknown, in which case open-ended ranges extend to pdfMaxPagesPerRead pages.
+func parsePageRange(s string, total int) (first, last int, err error) {
+	s = strings.ReplaceAll(s, " ", "")
+	end := total
+	if end == 0 {
+		end = 1<<31 - 1
+	}
+	if s == "" {
+		return 1, end, nil
+	}
+
+	lo, hi, isRange := strings.Cut(s, "-")
+	first, err = strconv.Atoi(lo)
+	if err != nil || first < 1 {
+		return 0, 0, fmt.Errorf("invalid page range %q; use e.g. \"3\", \"1-5\", or \"10-\"", s)
+	}
+	switch {
+	case !isRange:
+		last = first
+	case hi == "":
+		last = end
+	default:
+		last, err = strconv.Atoi(hi)
+		if err != nil || last < first {
+			return 0, 0, fmt.Errorf("invalid page range %q; use e.g. \"3\", \"1-5\", or \"10-\"", s)
+		}
+	}
+	if total > 0 {
+		if first > total {
+			return 0, 0, fmt.Errorf("page %d is out of range; the PDF has %d pages", first, total)
+		}
+		last = min(last, total)
+	}
+	return first, last, nil
+}
+
+// pdfToolError describes a failure to run a poppler utility.
+func pdfToolError(name string, err error) string {
+	if errors.Is(err, exec.ErrNotFound) {
+		return fmt.Sprintf("Error: %s not available to read PDF. Install poppler-utils.", name)
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+		return fmt.Sprintf("Error: %s failed: %s", name, strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return fmt.Sprintf("Error: %s failed: %v", name, err)
+}
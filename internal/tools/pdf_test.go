@@ -0,0 +1,73 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParsePageRange(t *testing.T) {
+	tests := []struct {
+		in          string
+		total       int
+		first, last int
+		wantErr     bool
+	}{
+		{"", 7, 1, 7, false},
+		{"3", 7, 3, 3, false},
+		{"2-5", 7, 2, 5, false},
+		{" 2 - 5 ", 7, 2, 5, false},
+		{"5-", 7, 5, 7, false},
+		{"5-99", 7, 5, 7, false},
+		{"5-", 0, 5, 1<<31 - 1, false},
+		{"8", 7, 0, 0, true},
+		{"0", 7, 0, 0, true},
+		{"5-2", 7, 0, 0, true},
+		{"a-b", 7, 0, 0, true},
+	}
+	for _, tt := range tests {
+		first, last, err := parsePageRange(tt.in, tt.total)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parsePageRange(%q, %d) error = %v, wantErr %v", tt.in, tt.total, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && (first != tt.first || last != tt.last) {
+			t.Errorf("parsePageRange(%q, %d) = %d, %d; want %d, %d", tt.in, tt.total, first, last, tt.first, tt.last)
+		}
+	}
+}
+
+func TestFileReadTool_PDFInvalidPages(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "doc.pdf")
+	os.WriteFile(path, []byte("%PDF-1.4\n"), 0644)
+
+	input, _ := json.Marshal(FileReadInput{FilePath: path, Pages: "x"})
+	result, _ := NewFileReadTool().Execute(context.Background(), input)
+	if !strings.Contains(result, "invalid page range") {
+		t.Errorf("result = %q, want page range error", result)
+	}
+
+	input, _ = json.Marshal(FileReadInput{FilePath: path, Pages: "1-15", PageImages: true})
+	result, _ = NewFileReadTool().Execute(context.Background(), input)
+	if !strings.Contains(result, "at most 10 pages") {
+		t.Errorf("result = %q, want image page limit error", result)
+	}
+}
+
+func TestFileReadTool_PDFMissingPoppler(t *testing.T) {
+	if _, err := exec.LookPath("pdftotext"); err == nil {
+		t.Skip("pdftotext is installed")
+	}
+	path := filepath.Join(t.TempDir(), "doc.pdf")
+	os.WriteFile(path, []byte("%PDF-1.4\n"), 0644)
+
+	input, _ := json.Marshal(FileReadInput{FilePath: path})
+	result, _ := NewFileReadTool().Execute(context.Background(), input)
+	if !strings.Contains(result, "Install poppler-utils") {
+		t.Errorf("result = %q, want install hint", result)
+	}
+}
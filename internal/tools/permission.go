@@ -7,10 +7,12 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"sync"
 )
 
 // TerminalPermissionHandler prompts the user for permission via the terminal.
 type TerminalPermissionHandler struct {
+	mu     sync.Mutex // one prompt at a time when sub-agents run in parallel
 	reader *bufio.Reader
 }
 
@@ -23,6 +25,9 @@ func NewTerminalPermissionHandler() *TerminalPermissionHandler {
 
 // RequestPermission prompts the user to allow or deny a tool call.
 func (h *TerminalPermissionHandler) RequestPermission(ctx context.Context, toolName string, input json.RawMessage) (bool, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
 	summary := summarizeToolInput(toolName, input)
 	fmt.Printf("\n--- Permission Required ---\n")
 	fmt.Printf("Tool: %s\n", toolName)
@@ -81,6 +86,17 @@ func summarizeToolInput(toolName string, input json.RawMessage) string {
 			json.Unmarshal(u, &s)
 			return fmt.Sprintf("Fetch: %s", s)
 		}
+	case "HttpRequest":
+		if s := HttpRequestSummary(input); s != "" {
+			return s
+		}
+	case "DownloadFile":
+		if u, ok := m["url"]; ok {
+			var s, dest string
+			json.Unmarshal(u, &s)
+			json.Unmarshal(m["path"], &dest)
+			return fmt.Sprintf("Download: %s → %s", s, dest)
+		}
 	case "NotebookEdit":
 		if np, ok := m["notebook_path"]; ok {
 			var s string
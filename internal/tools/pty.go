@@ -0,0 +1,67 @@
+package tools
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+const (
+	// Size of the pseudo-terminal given to interactive commands.
+	ptyRows = 40
+	ptyCols = 120
+
+	// ptyDrainTimeout bounds how long to keep reading terminal output after
+	// bash exits, in case a background child still holds the terminal.
+	ptyDrainTimeout = 500 * time.Millisecond
+)
+
+// interactiveCommands are programs that expect a terminal: they prompt on
+// /dev/tty, page their output, or draw a full-screen UI. Without a PTY of
+// their own they either hang or take over the user's terminal.
+var interactiveCommands = map[string]bool{
+	"ssh": true, "scp": true, "sftp": true, "mosh": true,
+	"sudo": true, "su": true, "passwd": true, "login": true,
+	"top": true, "htop": true, "watch": true,
+	"vi": true, "vim": true, "nvim": true, "nano": true, "emacs": true,
+	"less": true, "more": true, "man": true,
+	"tmux": true, "screen": true,
+}
+
+// needsPTY reports whether any simple command in a shell command line runs
+// a known-interactive program.
+func needsPTY(command string) bool {
+	fields := strings.FieldsFunc(command, func(r rune) bool {
+		return r == '|' || r == '&' || r == ';' || r == '\n' || r == '(' || r == ')'
+	})
+	for _, part := range fields {
+		for _, word := range strings.Fields(part) {
+			// Skip leading VAR=value assignments.
+			if strings.Contains(word, "=") && !strings.HasPrefix(word, "=") {
+				continue
+			}
+			if interactiveCommands[filepath.Base(word)] {
+				return true
+			}
+			break
+		}
+	}
+	return false
+}
+
+// cleanPTYOutput converts terminal output into plain text: escape
+// sequences are removed, CRLF line endings normalized, and lines redrawn
+// with a bare carriage return (progress bars) reduced to their final state.
+func cleanPTYOutput(s string) string {
+	s = ansi.Strip(s)
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if j := strings.LastIndexByte(line, '\r'); j >= 0 {
+			lines[i] = line[j+1:]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
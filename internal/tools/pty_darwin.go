@@ -0,0 +1,39 @@
+package tools
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// openPTY allocates a pseudo-terminal pair from /dev/ptmx.
+func openPTY() (pty, tty *os.File, err error) {
+	pty, err = os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, req := range []uintptr{syscall.TIOCPTYGRANT, syscall.TIOCPTYUNLK} {
+		if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, pty.Fd(), req, 0); errno != 0 {
+			pty.Close()
+			return nil, nil, fmt.Errorf("unlocking pty: %w", errno)
+		}
+	}
+	name := make([]byte, 128)
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, pty.Fd(), syscall.TIOCPTYGNAME, uintptr(unsafe.Pointer(&name[0]))); errno != 0 {
+		pty.Close()
+		return nil, nil, fmt.Errorf("getting pty name: %w", errno)
+	}
+	if i := bytes.IndexByte(name, 0); i >= 0 {
+		name = name[:i]
+	}
+
+	tty, err = os.OpenFile(string(name), os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		pty.Close()
+		return nil, nil, err
+	}
+	return pty, tty, nil
+}
@@ -0,0 +1,18 @@
+//go:build !linux && !darwin
+
+package tools
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// openPTY is unsupported on this platform.
+func openPTY() (pty, tty *os.File, err error) {
+	return nil, nil, errors.New("pseudo-terminals are not supported on this platform")
+}
+
+func ptyProcAttr() *syscall.SysProcAttr { return nil }
+
+func setPTYSize(pty *os.File, rows, cols uint16) {}
@@ -0,0 +1,23 @@
+//go:build linux || darwin
+
+package tools
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// ptyProcAttr starts the child in a new session with its stdin (the PTY
+// slave) as the controlling terminal, so programs that open /dev/tty talk
+// to the PTY instead of the user's terminal.
+func ptyProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true, Setctty: true, Ctty: 0}
+}
+
+// setPTYSize sets the terminal window size. Errors are ignored: programs
+// fall back to a default size.
+func setPTYSize(pty *os.File, rows, cols uint16) {
+	ws := struct{ row, col, x, y uint16 }{rows, cols, 0, 0}
+	syscall.Syscall(syscall.SYS_IOCTL, pty.Fd(), syscall.TIOCSWINSZ, uintptr(unsafe.Pointer(&ws)))
+}
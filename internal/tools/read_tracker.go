@@ -0,0 +1,79 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// fileStamp identifies a version of a file on disk.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+func stampOf(info os.FileInfo) fileStamp {
+	return fileStamp{modTime: info.ModTime(), size: info.Size()}
+}
+
+// ReadTracker remembers which version of each file the model last read or
+// wrote during a session. The file-modifying tools use it to refuse changes
+// to files that were modified on disk since then, which would otherwise
+// silently overwrite edits made by someone else.
+type ReadTracker struct {
+	mu    sync.Mutex
+	files map[string]fileStamp
+}
+
+// NewReadTracker creates an empty read tracker.
+func NewReadTracker() *ReadTracker {
+	return &ReadTracker{files: make(map[string]fileStamp)}
+}
+
+// Record notes that the model has seen path as described by info. It does
+// nothing on a nil tracker.
+func (r *ReadTracker) Record(path string, info os.FileInfo) {
+	if r == nil || info == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.files[filepath.Clean(path)] = stampOf(info)
+}
+
+// RecordCurrent records the file's current state on disk, e.g. after a tool
+// has written it.
+func (r *ReadTracker) RecordCurrent(path string) {
+	if r == nil {
+		return
+	}
+	if info, err := os.Stat(path); err == nil {
+		r.Record(path, info)
+	}
+}
+
+// CheckStale returns a user-facing error message if path was read earlier
+// and has changed on disk since, or "" if it is safe to modify. Files that
+// were never read, or no longer exist, are not considered stale.
+func (r *ReadTracker) CheckStale(path string) string {
+	if r == nil {
+		return ""
+	}
+	r.mu.Lock()
+	seen, ok := r.files[filepath.Clean(path)]
+	r.mu.Unlock()
+	if !ok {
+		return ""
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+	if cur := stampOf(info); cur.modTime.Equal(seen.modTime) && cur.size == seen.size {
+		return ""
+	}
+	return fmt.Sprintf("Error: %s has been modified since it was last read (it changed at %s). Read it again before modifying it, so changes made by someone else aren't overwritten.",
+		path, info.ModTime().Format("15:04:05"))
+}
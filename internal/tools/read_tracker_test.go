@@ -0,0 +1,118 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTrackedTools(tracker *ReadTracker) (*FileReadTool, *FileEditTool, *FileWriteTool) {
+	read := NewFileReadTool()
+	read.SetReadTracker(tracker)
+	edit := NewFileEditTool()
+	edit.SetReadTracker(tracker)
+	write := NewFileWriteTool()
+	write.SetReadTracker(tracker)
+	return read, edit, write
+}
+
+// touchLater rewrites path with a modification time clearly after the
+// previous one, so the change is visible on coarse-grained filesystems.
+func touchLater(t *testing.T, path, content string) {
+	t.Helper()
+	info, _ := os.Stat(path)
+	os.WriteFile(path, []byte(content), 0644)
+	later := info.ModTime().Add(2 * time.Second)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadTracker_EditRefusedAfterExternalChange(t *testing.T) {
+	read, edit, _ := newTrackedTools(NewReadTracker())
+	path := filepath.Join(t.TempDir(), "f.txt")
+	os.WriteFile(path, []byte("alpha\n"), 0644)
+
+	input, _ := json.Marshal(FileReadInput{FilePath: path})
+	read.Execute(context.Background(), input)
+
+	touchLater(t, path, "alpha beta\n")
+
+	input, _ = json.Marshal(FileEditInput{FilePath: path, OldString: "alpha", NewString: "gamma"})
+	result, _ := edit.Execute(context.Background(), input)
+	if !strings.Contains(result, "modified since it was last read") {
+		t.Errorf("result = %q, want staleness error", result)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "alpha beta\n" {
+		t.Errorf("file was modified: %q", data)
+	}
+
+	// Re-reading clears the condition.
+	input, _ = json.Marshal(FileReadInput{FilePath: path})
+	read.Execute(context.Background(), input)
+	input, _ = json.Marshal(FileEditInput{FilePath: path, OldString: "alpha", NewString: "gamma"})
+	result, _ = edit.Execute(context.Background(), input)
+	if !strings.Contains(result, "Successfully edited") {
+		t.Errorf("result = %q, want success after re-read", result)
+	}
+}
+
+func TestReadTracker_OwnWritesAreNotStale(t *testing.T) {
+	read, edit, write := newTrackedTools(NewReadTracker())
+	path := filepath.Join(t.TempDir(), "f.txt")
+	os.WriteFile(path, []byte("one\n"), 0644)
+
+	input, _ := json.Marshal(FileReadInput{FilePath: path})
+	read.Execute(context.Background(), input)
+
+	for _, step := range []struct{ old, new string }{{"one", "two"}, {"two", "three"}} {
+		input, _ = json.Marshal(FileEditInput{FilePath: path, OldString: step.old, NewString: step.new})
+		if result, _ := edit.Execute(context.Background(), input); !strings.Contains(result, "Successfully") {
+			t.Fatalf("edit %s->%s: %q", step.old, step.new, result)
+		}
+	}
+	input, _ = json.Marshal(FileWriteInput{FilePath: path, Content: "four\n"})
+	if result, _ := write.Execute(context.Background(), input); !strings.Contains(result, "Successfully") {
+		t.Fatalf("write: %q", result)
+	}
+}
+
+func TestReadTracker_WriteRefusedAfterExternalChange(t *testing.T) {
+	read, _, write := newTrackedTools(NewReadTracker())
+	path := filepath.Join(t.TempDir(), "f.txt")
+	os.WriteFile(path, []byte("mine\n"), 0644)
+
+	input, _ := json.Marshal(FileReadInput{FilePath: path})
+	read.Execute(context.Background(), input)
+	touchLater(t, path, "theirs\n")
+
+	input, _ = json.Marshal(FileWriteInput{FilePath: path, Content: "overwrite\n"})
+	result, _ := write.Execute(context.Background(), input)
+	if !strings.HasPrefix(result, "Error:") {
+		t.Errorf("result = %q, want staleness error", result)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "theirs\n" {
+		t.Errorf("file was overwritten: %q", data)
+	}
+}
+
+func TestReadTracker_UnreadFilesAllowed(t *testing.T) {
+	_, edit, write := newTrackedTools(NewReadTracker())
+	dir := t.TempDir()
+
+	input, _ := json.Marshal(FileWriteInput{FilePath: filepath.Join(dir, "new.txt"), Content: "x\n"})
+	if result, _ := write.Execute(context.Background(), input); !strings.Contains(result, "Successfully") {
+		t.Errorf("write: %q", result)
+	}
+
+	path := filepath.Join(dir, "old.txt")
+	os.WriteFile(path, []byte("a\n"), 0644)
+	input, _ = json.Marshal(FileEditInput{FilePath: path, OldString: "a", NewString: "b"})
+	if result, _ := edit.Execute(context.Background(), input); !strings.Contains(result, "Successfully") {
+		t.Errorf("edit: %q", result)
+	}
+}
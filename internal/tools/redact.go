@@ -0,0 +1,191 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// secretPattern is a known credential format.
+type secretPattern struct {
+	kind string
+	re   *regexp.Regexp
+	// group is the submatch holding the secret; 0 means the whole match.
+	group int
+}
+
+var secretPatterns = []secretPattern{
+	{kind: "private-key", re: regexp.MustCompile(`-----BEGIN[A-Z ]* PRIVATE KEY-----[\s\S]*?-----END[A-Z ]* PRIVATE KEY-----`)},
+	{kind: "aws-access-key", re: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{kind: "aws-secret-key", re: regexp.MustCompile(`(?i)aws_?secret_?access_?key["']?\s*[:=]\s*["']?([A-Za-z0-9/+=]{40})`), group: 1},
+	{kind: "github-token", re: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})`)},
+	{kind: "anthropic-key", re: regexp.MustCompile(`\bsk-ant-[A-Za-z0-9_-]{20,}`)},
+	{kind: "openai-key", re: regexp.MustCompile(`\bsk-(?:proj-)?[A-Za-z0-9_-]{20,}`)},
+	{kind: "stripe-key", re: regexp.MustCompile(`\b[rs]k_live_[0-9A-Za-z]{20,}`)},
+	{kind: "slack-token", re: regexp.MustCompile(`\bxox[abposr]-[0-9A-Za-z-]{10,}`)},
+	{kind: "google-api-key", re: regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}`)},
+	{kind: "jwt", re: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`)},
+	{kind: "password", re: regexp.MustCompile(`(?i)(?:password|passwd|secret|token|api_?key)["']?\s*[:=]\s*["']([^"'\s]{8,})["']`), group: 1},
+	{kind: "url-credentials", re: regexp.MustCompile(`[a-z][a-z0-9+.-]*://[^:/\s@]+:([^@/\s]{3,})@`), group: 1},
+}
+
+// highEntropyCandidate matches long tokens that might be random secrets.
+var highEntropyCandidate = regexp.MustCompile(`[A-Za-z0-9+/_-]{32,}={0,2}`)
+
+const (
+	// A candidate counts as random if its Shannon entropy per character
+	// and the share of adjacent characters that switch between upper case,
+	// lower case, digits, and symbols are both high. Random base64 scores
+	// about 4.5+ and 0.6; camel-case identifiers and paths score about 4.2
+	// and 0.35.
+	highEntropyMinBits     = 4.3
+	highEntropyMinSwitches = 0.4
+	redactionMarker        = RedactionMarkerPrefix + "%s]"
+)
+
+// digestPrefix matches the algorithm prefix of subresource-integrity
+// hashes in lock files, which are not secrets.
+var digestPrefix = regexp.MustCompile(`^sha\d+-`)
+
+// digestLabel matches the text before a go.sum or OCI hash ("h1:...",
+// "sha256:..."), which high-entropy candidates don't include.
+var digestLabel = regexp.MustCompile(`\b(?:h1|sha\d+):$`)
+
+// RedactionMarkerPrefix starts every redaction marker. The file tools
+// refuse to write it, so that redacted text the model copies from command
+// output can't replace the real values in a file.
+const RedactionMarkerPrefix = "[REDACTED:"
+
+const redactedWriteError = "Error: the new content contains a redaction marker (" + RedactionMarkerPrefix + "...]). Secrets are redacted from command output, so write the real value, or read the file with FileRead to see it."
+
+// redactedTools are the tools whose output is scanned. FileRead is left
+// out: the model edits files by quoting what it read, and redacted text
+// would be written back over the real values.
+var redactedTools = map[string]bool{"Bash": true, "Grep": true}
+
+// SecretRedactor is middleware that replaces credentials in Bash and Grep
+// output with redaction markers before the model sees them. It looks for
+// known credential formats and for long high-entropy tokens.
+type SecretRedactor struct {
+	allow []*regexp.Regexp
+}
+
+// NewSecretRedactor creates a redactor. Values matching any of the allow
+// regular expressions are kept, for example known test fixtures.
+func NewSecretRedactor(allow []string) (*SecretRedactor, error) {
+	r := &SecretRedactor{}
+	for _, pattern := range allow {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secret redaction allow pattern %q: %w", pattern, err)
+		}
+		r.allow = append(r.allow, re)
+	}
+	return r, nil
+}
+
+func (r *SecretRedactor) BeforeTool(_ context.Context, _ string, _ json.RawMessage) (json.RawMessage, error) {
+	return nil, nil
+}
+
+func (r *SecretRedactor) AfterTool(_ context.Context, toolName string, _ json.RawMessage, output string, _ error) (string, error) {
+	if !redactedTools[toolName] {
+		return output, nil
+	}
+	return r.Redact(output), nil
+}
+
+// Redact replaces the secrets found in s with markers naming their kind.
+func (r *SecretRedactor) Redact(s string) string {
+	for _, p := range secretPatterns {
+		s = r.replace(s, p.re, p.group, p.kind)
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range highEntropyCandidate.FindAllStringIndex(s, -1) {
+		tok := s[m[0]:m[1]]
+		if digestLabel.MatchString(s[max(0, m[0]-8):m[0]]) || !looksRandom(tok) || r.allowed(tok) {
+			continue
+		}
+		b.WriteString(s[last:m[0]])
+		fmt.Fprintf(&b, redactionMarker, "high-entropy")
+		last = m[1]
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// replace redacts submatch group of each match of re.
+func (r *SecretRedactor) replace(s string, re *regexp.Regexp, group int, kind string) string {
+	matches := re.FindAllStringSubmatchIndex(s, -1)
+	if matches == nil {
+		return s
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := m[2*group], m[2*group+1]
+		if start < 0 || r.allowed(s[start:end]) {
+			continue
+		}
+		b.WriteString(s[last:start])
+		fmt.Fprintf(&b, redactionMarker, kind)
+		last = end
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+func (r *SecretRedactor) allowed(secret string) bool {
+	for _, re := range r.allow {
+		if re.MatchString(secret) {
+			return true
+		}
+	}
+	return false
+}
+
+// looksRandom reports whether tok looks like a random secret rather than
+// a digest, identifier, or path. Hex digests have no upper case and are
+// left alone.
+func looksRandom(tok string) bool {
+	if digestPrefix.MatchString(tok) {
+		return false
+	}
+	class := func(c byte) int {
+		switch {
+		case c >= 'A' && c <= 'Z':
+			return 0
+		case c >= 'a' && c <= 'z':
+			return 1
+		case c >= '0' && c <= '9':
+			return 2
+		}
+		return 3
+	}
+	var seen [4]bool
+	var counts [256]int
+	switches := 0
+	for i := 0; i < len(tok); i++ {
+		seen[class(tok[i])] = true
+		counts[tok[i]]++
+		if i > 0 && class(tok[i]) != class(tok[i-1]) {
+			switches++
+		}
+	}
+	if !seen[0] || !seen[1] || !seen[2] {
+		return false
+	}
+	var bits float64
+	n := float64(len(tok))
+	for _, c := range counts {
+		if c > 0 {
+			p := float64(c) / n
+			bits -= p * math.Log2(p)
+		}
+	}
+	return bits >= highEntropyMinBits && float64(switches)/(n-1) >= highEntropyMinSwitches
+}
@@ -5,10 +5,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"sync"
 
 	"github.com/anthropics/claude-code-go/internal/api"
 	"github.com/anthropics/claude-code-go/internal/config"
+	"github.com/anthropics/claude-code-go/internal/conversation"
 )
 
 // Tool is the interface that all built-in tools implement.
@@ -29,6 +31,14 @@ type Tool interface {
 	RequiresPermission(input json.RawMessage) bool
 }
 
+// ParallelTool is implemented by tools whose calls may run concurrently
+// when the model makes several in one turn.
+type ParallelTool interface {
+	// MaxParallel returns how many calls may run at once unless settings
+	// set a maxConcurrent limit for the tool.
+	MaxParallel() int
+}
+
 // PermissionHandler prompts the user for tool execution permission.
 type PermissionHandler interface {
 	// RequestPermission asks the user whether to allow a tool call.
@@ -58,6 +68,8 @@ type Registry struct {
 	tools      map[string]Tool
 	order      []string // preserves registration order
 	permission PermissionHandler
+	middleware []Middleware
+	limits     *toolLimits // nil = unlimited
 }
 
 // NewRegistry creates a new tool registry.
@@ -79,6 +91,33 @@ func (r *Registry) Register(t Tool) {
 	r.tools[name] = t
 }
 
+// Unregister removes a tool from the registry, for example when an MCP
+// server stops offering it.
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.tools[name]; !exists {
+		return
+	}
+	delete(r.tools, name)
+	r.order = slices.DeleteFunc(r.order, func(n string) bool { return n == name })
+}
+
+// Use appends middleware to the chain run around every tool call.
+func (r *Registry) Use(mw ...Middleware) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.middleware = append(r.middleware, mw...)
+}
+
+// SetLimits sets per-tool concurrency and rate limits, keyed by tool name;
+// the "*" entry limits all tool calls together.
+func (r *Registry) SetLimits(limits map[string]config.ToolLimit) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.limits = newToolLimits(limits)
+}
+
 // HasTool returns true if the named tool is registered.
 func (r *Registry) HasTool(name string) bool {
 	r.mu.RLock()
@@ -87,12 +126,35 @@ func (r *Registry) HasTool(name string) bool {
 	return ok
 }
 
+// MaxParallel returns how many calls to the named tool the conversation
+// loop may run at once: 1 unless the tool is a ParallelTool, whose default
+// is overridden by the tool's maxConcurrent limit in settings. It
+// implements conversation.ParallelToolExecutor.
+func (r *Registry) MaxParallel(name string) int {
+	r.mu.RLock()
+	tool, ok := r.tools[name]
+	limits := r.limits
+	r.mu.RUnlock()
+
+	pt, ok := tool.(ParallelTool)
+	if !ok {
+		return 1
+	}
+	if n := limits.maxConcurrent(name); n > 0 {
+		return n
+	}
+	return pt.MaxParallel()
+}
+
 // Execute runs the named tool with the given JSON input.
-// It checks permissions before execution if required.
+// Middleware may rewrite the input first; permissions are then checked if
+// required, and middleware may rewrite the output afterwards.
 func (r *Registry) Execute(ctx context.Context, name string, input []byte) (string, error) {
 	r.mu.RLock()
 	tool, ok := r.tools[name]
 	perm := r.permission
+	middleware := r.middleware
+	limits := r.limits
 	r.mu.RUnlock()
 
 	if !ok {
@@ -101,6 +163,16 @@ func (r *Registry) Execute(ctx context.Context, name string, input []byte) (stri
 
 	rawInput := json.RawMessage(input)
 
+	for _, mw := range middleware {
+		rewritten, err := mw.BeforeTool(ctx, name, rawInput)
+		if err != nil {
+			return fmt.Sprintf("Tool call blocked: %v", err), fmt.Errorf("blocked by middleware: %w", err)
+		}
+		if rewritten != nil {
+			rawInput = rewritten
+		}
+	}
+
 	// Check permission if needed.
 	if tool.RequiresPermission(rawInput) && perm != nil {
 		// Try rich permission check first.
@@ -117,33 +189,66 @@ func (r *Registry) Execute(ctx context.Context, name string, input []byte) (stri
 				return msg, fmt.Errorf("permission denied")
 			default:
 				// BehaviorAsk or BehaviorPassthrough — fall back to interactive prompt.
-				allowed, err := perm.RequestPermission(ctx, name, rawInput)
-				if err != nil {
-					return "", fmt.Errorf("permission check: %w", err)
-				}
-				if !allowed {
-					return "Permission denied by user.", fmt.Errorf("permission denied")
+				if msg, err := askPermission(ctx, perm, name, rawInput); err != nil {
+					return msg, err
 				}
 			}
 		} else {
 			// Simple permission handler.
-			allowed, err := perm.RequestPermission(ctx, name, rawInput)
-			if err != nil {
-				return "", fmt.Errorf("permission check: %w", err)
-			}
-			if !allowed {
-				return "Permission denied by user.", fmt.Errorf("permission denied")
+			if msg, err := askPermission(ctx, perm, name, rawInput); err != nil {
+				return msg, err
 			}
 		}
 	}
 
+	if limits != nil {
+		release, msg, err := limits.acquire(ctx, name)
+		if err != nil {
+			return "", err
+		}
+		if msg != "" {
+			return msg, fmt.Errorf("rate limited")
+		}
+		defer release()
+	}
+
 	result, err := tool.Execute(ctx, rawInput)
+	for i := len(middleware) - 1; i >= 0; i-- {
+		output, vetoErr := middleware[i].AfterTool(ctx, name, rawInput, result, err)
+		if vetoErr != nil {
+			return fmt.Sprintf("Tool result withheld: %v", vetoErr), fmt.Errorf("blocked by middleware: %w", vetoErr)
+		}
+		result = output
+	}
 	if err != nil {
 		return result, err
 	}
 	return result, nil
 }
 
+// askPermission asks the user to allow a tool call that no rule decided,
+// unless the hooks in ctx decide it first. It returns a non-nil error,
+// and the message for the tool result, when the call is denied.
+func askPermission(ctx context.Context, perm PermissionHandler, name string, input json.RawMessage) (string, error) {
+	if hook := conversation.PermissionHookFrom(ctx); hook != nil {
+		out := hook(ctx, name, input)
+		switch out.Decision {
+		case conversation.HookApprove:
+			return "", nil
+		case conversation.HookBlock:
+			return "Permission denied by hook: " + out.Reason, fmt.Errorf("permission denied")
+		}
+	}
+	allowed, err := perm.RequestPermission(ctx, name, input)
+	if err != nil {
+		return "", fmt.Errorf("permission check: %w", err)
+	}
+	if !allowed {
+		return "Permission denied by user.", fmt.Errorf("permission denied")
+	}
+	return "", nil
+}
+
 // LastPermissionResult returns the most recent rich permission result for
 // a tool execution, if the handler supports it. Returns nil otherwise.
 func (r *Registry) LastPermissionResult(name string, input json.RawMessage) *config.PermissionResult {
@@ -171,6 +276,29 @@ func (r *Registry) GetPermissionContext() *config.ToolPermissionContext {
 	return nil
 }
 
+// RequestPermission asks the current permission handler to approve an
+// operation that isn't a tool call, such as an MCP server's sampling
+// request. Rules and the permission mode are checked before prompting.
+// Without a handler, everything is allowed, as in Execute.
+func (r *Registry) RequestPermission(ctx context.Context, name string, input json.RawMessage) (bool, error) {
+	r.mu.RLock()
+	perm := r.permission
+	r.mu.RUnlock()
+
+	if perm == nil {
+		return true, nil
+	}
+	if rph, ok := perm.(RichPermissionHandler); ok {
+		switch rph.CheckPermission(name, input).Behavior {
+		case config.BehaviorAllow:
+			return true, nil
+		case config.BehaviorDeny:
+			return false, nil
+		}
+	}
+	return perm.RequestPermission(ctx, name, input)
+}
+
 // SetPermissionHandler replaces the permission handler at runtime.
 // The argument is interface{} to avoid import cycles with the tui package;
 // it must implement PermissionHandler.
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/anthropics/claude-code-go/internal/config"
+	"github.com/anthropics/claude-code-go/internal/conversation"
 )
 
 // mockTool is a simple tool for testing the registry.
@@ -144,6 +145,28 @@ func TestRegistry_Definitions(t *testing.T) {
 	}
 }
 
+func TestRegistry_Unregister(t *testing.T) {
+	r := NewRegistry(nil)
+	r.Register(&mockTool{name: "A", result: "a"})
+	r.Register(&mockTool{name: "B", result: "b"})
+	r.Unregister("A")
+	r.Unregister("missing")
+
+	if r.HasTool("A") {
+		t.Error("A still registered after Unregister")
+	}
+	defs := r.Definitions()
+	if len(defs) != 1 || defs[0].Name != "B" {
+		t.Errorf("definitions = %v, want only B", defs)
+	}
+
+	// Registering again appends to the order once.
+	r.Register(&mockTool{name: "A", result: "a"})
+	if defs := r.Definitions(); len(defs) != 2 || defs[1].Name != "A" {
+		t.Errorf("definitions after re-registering = %v, want B then A", defs)
+	}
+}
+
 // ─── Rich Permission Handler integration ───
 
 func TestRegistry_RichPermissionAllow(t *testing.T) {
@@ -246,6 +269,30 @@ func TestRegistry_SetPermissionHandler(t *testing.T) {
 	}
 }
 
+func TestRegistry_RequestPermission(t *testing.T) {
+	tests := []struct {
+		behavior config.PermissionBehavior
+		fallback bool
+		want     bool
+	}{
+		{config.BehaviorAllow, false, true},
+		{config.BehaviorDeny, true, false},
+		{config.BehaviorAsk, true, true},
+		{config.BehaviorAsk, false, false},
+	}
+	for _, tt := range tests {
+		perm := &mockRichPermission{result: config.PermissionResult{Behavior: tt.behavior}, fallback: tt.fallback}
+		r := NewRegistry(perm)
+		got, err := r.RequestPermission(context.Background(), "MCPSampling", json.RawMessage(`{}`))
+		if err != nil {
+			t.Fatalf("RequestPermission: %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("behavior %v, fallback %v: got %v, want %v", tt.behavior, tt.fallback, got, tt.want)
+		}
+	}
+}
+
 func TestRegistry_GetPermissionContext(t *testing.T) {
 	// Without a PermissionContextProvider, should return nil.
 	r := NewRegistry(&mockPermission{allow: true})
@@ -281,3 +328,39 @@ func TestRegistry_LastPermissionResult(t *testing.T) {
 		t.Error("Expected nil from non-rich handler")
 	}
 }
+
+func TestRegistry_PermissionHook(t *testing.T) {
+	for _, tt := range []struct {
+		decision string
+		wantErr  bool
+		asked    bool
+	}{
+		{conversation.HookApprove, false, false},
+		{conversation.HookBlock, true, false},
+		{"", true, true}, // left to the user, who denies
+	} {
+		perm := &mockPermission{allow: false}
+		r := NewRegistry(perm)
+		r.Register(&mockTool{name: "Bash", needsPermission: true, result: "done"})
+		ctx := conversation.WithPermissionHook(context.Background(), func(context.Context, string, json.RawMessage) conversation.HookOutput {
+			return conversation.HookOutput{Decision: tt.decision, Reason: "policy"}
+		})
+		result, err := r.Execute(ctx, "Bash", []byte(`{}`))
+		if (err != nil) != tt.wantErr || (len(perm.requests) > 0) != tt.asked {
+			t.Errorf("decision %q: result %q, err %v, prompts %v", tt.decision, result, err, perm.requests)
+		}
+		if tt.decision == conversation.HookBlock && result != "Permission denied by hook: policy" {
+			t.Errorf("blocked result = %q", result)
+		}
+	}
+
+	// A deny rule still wins over a hook's approval.
+	r := NewRegistry(&mockRichPermission{result: config.PermissionResult{Behavior: config.BehaviorDeny}})
+	r.Register(&mockTool{name: "Bash", needsPermission: true, result: "done"})
+	ctx := conversation.WithPermissionHook(context.Background(), func(context.Context, string, json.RawMessage) conversation.HookOutput {
+		return conversation.HookOutput{Decision: conversation.HookApprove}
+	})
+	if _, err := r.Execute(ctx, "Bash", []byte(`{}`)); err == nil {
+		t.Error("a hook approval overrode a deny rule")
+	}
+}
@@ -0,0 +1,362 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+const (
+	runServerDefaultWait = 60 * time.Second
+	runServerMaxWait     = 300 * time.Second
+	// runServerGrace is how long a server without readiness criteria is
+	// watched for an immediate crash before it is reported as started.
+	runServerGrace      = 2 * time.Second
+	runServerPoll       = 250 * time.Millisecond
+	runServerLogMax     = 64 * 1024 // bytes of recent output kept
+	runServerRecentShow = 4 * 1024  // bytes of recent output in results
+)
+
+// RunServerInput is the input schema for the RunServer tool.
+type RunServerInput struct {
+	Command      string `json:"command"`
+	ReadyPattern string `json:"ready_pattern,omitempty"`
+	Port         int    `json:"port,omitempty"`
+	Timeout      *int   `json:"timeout,omitempty"` // milliseconds to wait for readiness
+}
+
+// RunServerTool starts long-running processes such as dev servers as
+// background tasks, waits until they are ready, and reports their health
+// through TaskOutput. Servers are stopped with TaskStop or when the session
+// ends.
+type RunServerTool struct {
+	bash    *BashTool // supplies the working directory and environment
+	bgStore *BackgroundTaskStore
+
+	mu     sync.Mutex
+	nextID int
+}
+
+// NewRunServerTool creates a RunServer tool. Servers run in bash's current
+// directory with its environment.
+func NewRunServerTool(bash *BashTool, bgStore *BackgroundTaskStore) *RunServerTool {
+	return &RunServerTool{bash: bash, bgStore: bgStore}
+}
+
+func (t *RunServerTool) Name() string { return "RunServer" }
+
+func (t *RunServerTool) Description() string {
+	return `Starts a long-running process such as a dev server, database, or file watcher in the background and waits until it is ready. Use this instead of Bash with run_in_background for servers.
+
+- ready_pattern is a regular expression matched against each line of output (e.g. "Listening on|ready in").
+- port waits until the port accepts TCP connections on localhost, and is rechecked whenever TaskOutput is called.
+- timeout is how long to wait for readiness in milliseconds (default 60000, max 300000). Without ready_pattern or port, the server is watched for 2 seconds for an immediate crash.
+- Use TaskOutput with the returned ID to see the server's health and recent logs (block=false), and TaskStop to stop it. Servers are stopped automatically when the session ends.`
+}
+
+func (t *RunServerTool) InputSchema() json.RawMessage {
+	return json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "command": {
+      "type": "string",
+      "description": "The command that starts the server"
+    },
+    "ready_pattern": {
+      "type": "string",
+      "description": "Regular expression matched against output lines that indicates the server is ready"
+    },
+    "port": {
+      "type": "number",
+      "description": "Port on localhost that accepts connections once the server is ready"
+    },
+    "timeout": {
+      "type": "number",
+      "description": "Max time to wait for readiness in milliseconds (default 60000, max 300000)"
+    }
+  },
+  "required": ["command"],
+  "additionalProperties": false
+}`)
+}
+
+func (t *RunServerTool) RequiresPermission(_ json.RawMessage) bool {
+	return true
+}
+
+func (t *RunServerTool) Execute(ctx context.Context, input json.RawMessage) (string, error) {
+	var in RunServerInput
+	if err := json.Unmarshal(input, &in); err != nil {
+		return "", fmt.Errorf("parsing RunServer input: %w", err)
+	}
+	if in.Command == "" {
+		return "Error: command is required", nil
+	}
+	if t.bgStore == nil {
+		return "Error: background tasks are not available", nil
+	}
+	var pattern *regexp.Regexp
+	if in.ReadyPattern != "" {
+		var err error
+		if pattern, err = regexp.Compile(in.ReadyPattern); err != nil {
+			return fmt.Sprintf("Error: invalid ready_pattern: %v", err), nil
+		}
+	}
+	if in.Port < 0 || in.Port > 65535 {
+		return fmt.Sprintf("Error: invalid port %d", in.Port), nil
+	}
+	wait := runServerDefaultWait
+	if in.Timeout != nil && *in.Timeout > 0 {
+		wait = min(time.Duration(*in.Timeout)*time.Millisecond, runServerMaxWait)
+	}
+
+	srv := &devServer{port: in.Port, log: &serverLog{pattern: pattern, ready: make(chan struct{})}}
+	taskCtx, cancel := context.WithCancel(context.Background())
+	cmd := exec.CommandContext(taskCtx, "bash", "-c", in.Command)
+	cmd.Dir = t.bash.Cwd()
+	cmd.Env = os.Environ()
+	for k, v := range t.bash.env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	cmd.Stdout, cmd.Stderr = srv.log, srv.log
+	cmd.WaitDelay = bashWaitDelay
+	setProcessGroup(cmd)
+	if err := cmd.Start(); err != nil {
+		cancel()
+		return fmt.Sprintf("Error: starting server: %v", err), nil
+	}
+
+	task := &BackgroundTask{
+		ID:          t.generateID(),
+		Kind:        "server",
+		Description: in.Command,
+		Ctx:         taskCtx,
+		Cancel:      cancel,
+		Done:        make(chan struct{}),
+		Output:      srv.log.String,
+		Health:      srv.health,
+	}
+	t.bgStore.Add(task)
+	go func() {
+		defer close(task.Done)
+		defer cancel()
+		err := cmd.Wait()
+		srv.exited(err)
+		task.Result = srv.log.String() + "\n" + srv.health()
+	}()
+
+	start := time.Now()
+	reason, ok := srv.waitReady(ctx, task.Done, wait)
+	elapsed := time.Since(start).Round(100 * time.Millisecond)
+	recent := srv.log.recent(runServerRecentShow)
+	switch {
+	case ok:
+		return fmt.Sprintf("Server running in background with ID: %s. Ready after %s (%s). Use TaskOutput to check its health and logs and TaskStop to stop it; it is stopped automatically when the session ends.\n\nRecent output:\n%s",
+			task.ID, elapsed, reason, recent), nil
+	case srv.hasExited():
+		return fmt.Sprintf("Error: server %s failed to start: %s.\n\nOutput:\n%s", task.ID, srv.health(), recent), nil
+	default:
+		return fmt.Sprintf("Server running in background with ID: %s, but it is not ready after %s (%s). It is still running; use TaskOutput to check its logs and TaskStop to stop it.\n\nRecent output:\n%s",
+			task.ID, elapsed, reason, recent), nil
+	}
+}
+
+// generateID returns an ID for a server task.
+func (t *RunServerTool) generateID() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.nextID++
+	return fmt.Sprintf("server_%d", t.nextID)
+}
+
+// devServer tracks the state of one server process.
+type devServer struct {
+	port int
+	log  *serverLog
+
+	mu      sync.Mutex
+	done    bool
+	exitErr error
+}
+
+func (s *devServer) exited(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.done, s.exitErr = true, err
+}
+
+func (s *devServer) hasExited() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.done
+}
+
+// waitReady waits until the server matches its ready pattern or its port
+// accepts connections. Without either, it waits runServerGrace and reports
+// the server ready if it is still running. It returns a description of
+// what happened and whether the server is ready.
+func (s *devServer) waitReady(ctx context.Context, done <-chan struct{}, wait time.Duration) (string, bool) {
+	criteria := s.log.pattern != nil || s.port > 0
+	if !criteria {
+		wait = runServerGrace
+	}
+	deadline := time.NewTimer(wait)
+	defer deadline.Stop()
+	poll := time.NewTicker(runServerPoll)
+	defer poll.Stop()
+
+	for {
+		if s.port > 0 && portOpen(s.port) {
+			return fmt.Sprintf("port %d is accepting connections", s.port), true
+		}
+		select {
+		case <-s.log.ready:
+			return fmt.Sprintf("output matched %q", s.log.pattern), true
+		case <-done:
+			return "", false
+		case <-deadline.C:
+			if !criteria {
+				return "still running", true
+			}
+			return "waiting for " + s.criteria(), false
+		case <-ctx.Done():
+			return "stopped waiting for " + s.criteria(), false
+		case <-poll.C:
+		}
+	}
+}
+
+func (s *devServer) criteria() string {
+	switch {
+	case s.log.pattern != nil && s.port > 0:
+		return fmt.Sprintf("output matching %q or port %d", s.log.pattern, s.port)
+	case s.log.pattern != nil:
+		return fmt.Sprintf("output matching %q", s.log.pattern)
+	}
+	return fmt.Sprintf("port %d", s.port)
+}
+
+// health describes the server's current state.
+func (s *devServer) health() string {
+	s.mu.Lock()
+	done, exitErr := s.done, s.exitErr
+	s.mu.Unlock()
+	if done {
+		var ee *exec.ExitError
+		switch {
+		case exitErr == nil:
+			return "exited (exit code 0)"
+		case errors.As(exitErr, &ee) && ee.ExitCode() >= 0:
+			return fmt.Sprintf("exited (exit code %d)", ee.ExitCode())
+		}
+		return fmt.Sprintf("exited (%v)", exitErr)
+	}
+	if s.port > 0 {
+		if portOpen(s.port) {
+			return fmt.Sprintf("healthy (port %d accepting connections)", s.port)
+		}
+		return fmt.Sprintf("unhealthy (port %d not accepting connections)", s.port)
+	}
+	if s.log.isReady() {
+		return "ready"
+	}
+	if s.log.pattern != nil {
+		return "starting"
+	}
+	return "running"
+}
+
+// portOpen reports whether a TCP port on localhost accepts connections.
+func portOpen(port int) bool {
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort("localhost", strconv.Itoa(port)), time.Second)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// serverLog keeps the most recent output of a server and watches it for
+// the ready pattern.
+type serverLog struct {
+	pattern *regexp.Regexp
+	ready   chan struct{} // closed once a line matches pattern
+
+	mu      sync.Mutex
+	buf     []byte
+	partial []byte // incomplete last line, not yet matched
+	matched bool
+}
+
+func (l *serverLog) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.buf = append(l.buf, p...)
+	if len(l.buf) > runServerLogMax {
+		l.buf = append(l.buf[:0], l.buf[len(l.buf)-runServerLogMax:]...)
+	}
+
+	if l.pattern != nil && !l.matched {
+		l.partial = append(l.partial, p...)
+		for {
+			i := bytes.IndexByte(l.partial, '\n')
+			if i < 0 {
+				break
+			}
+			line := ansi.Strip(string(l.partial[:i]))
+			l.partial = l.partial[i+1:]
+			if l.pattern.MatchString(line) {
+				l.matched = true
+				l.partial = nil
+				close(l.ready)
+				break
+			}
+		}
+		if len(l.partial) > runServerLogMax {
+			l.partial = nil
+		}
+	}
+	return len(p), nil
+}
+
+func (l *serverLog) isReady() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.matched
+}
+
+// String returns the kept output with terminal escape sequences removed.
+func (l *serverLog) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return ansi.Strip(string(l.buf))
+}
+
+// recent returns about the last n bytes of output, starting at a line
+// boundary.
+func (l *serverLog) recent(n int) string {
+	s := l.String()
+	if len(s) > n {
+		s = s[len(s)-n:]
+		if i := strings.IndexByte(s, '\n'); i >= 0 {
+			s = s[i+1:]
+		}
+		s = "...\n" + s
+	}
+	if s == "" {
+		return "(no output)"
+	}
+	return s
+}
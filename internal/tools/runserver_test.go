@@ -0,0 +1,100 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func runServer(t *testing.T, tool *RunServerTool, in RunServerInput) string {
+	t.Helper()
+	input, _ := json.Marshal(in)
+	result, err := tool.Execute(context.Background(), input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return result
+}
+
+func TestRunServer_ReadyPattern(t *testing.T) {
+	store := NewBackgroundTaskStore()
+	tool := NewRunServerTool(NewBashTool(t.TempDir()), store)
+	defer store.StopAll()
+
+	result := runServer(t, tool, RunServerInput{
+		Command:      `echo compiling; sleep 0.1; printf '\033[32mListening on :8080\033[0m\n'; sleep 30`,
+		ReadyPattern: `Listening on :\d+`,
+	})
+	if !strings.Contains(result, "server_1") || !strings.Contains(result, "Ready after") || !strings.Contains(result, "Listening on :8080") {
+		t.Fatalf("expected ready server, got:\n%s", result)
+	}
+
+	in, _ := json.Marshal(TaskOutputInput{TaskID: "server_1"})
+	got, _ := NewTaskOutputTool(store).Execute(context.Background(), in)
+	var out map[string]interface{}
+	json.Unmarshal([]byte(got), &out)
+	if out["status"] != "running" || out["health"] != "ready" || !strings.Contains(out["output"].(string), "compiling") {
+		t.Errorf("unexpected TaskOutput result: %s", got)
+	}
+
+	task, _ := store.Get("server_1")
+	store.StopAll()
+	select {
+	case <-task.Done:
+	default:
+		t.Fatal("expected StopAll to stop the server")
+	}
+}
+
+func TestRunServer_Port(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen: %v", err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	store := NewBackgroundTaskStore()
+	tool := NewRunServerTool(NewBashTool(t.TempDir()), store)
+	defer store.StopAll()
+
+	result := runServer(t, tool, RunServerInput{Command: "sleep 30", Port: port})
+	if !strings.Contains(result, "accepting connections") {
+		t.Fatalf("expected port readiness, got:\n%s", result)
+	}
+	task, _ := store.Get("server_1")
+	if h := task.Health(); !strings.HasPrefix(h, "healthy") {
+		t.Errorf("health = %q, want healthy", h)
+	}
+	ln.Close()
+	if h := task.Health(); !strings.HasPrefix(h, "unhealthy") {
+		t.Errorf("health after close = %q, want unhealthy", h)
+	}
+}
+
+func TestRunServer_ExitsBeforeReady(t *testing.T) {
+	store := NewBackgroundTaskStore()
+	tool := NewRunServerTool(NewBashTool(t.TempDir()), store)
+
+	result := runServer(t, tool, RunServerInput{Command: "echo 'address in use'; exit 3", ReadyPattern: "ready"})
+	if !strings.HasPrefix(result, "Error: server server_1 failed to start: exited (exit code 3)") || !strings.Contains(result, "address in use") {
+		t.Errorf("expected startup failure, got:\n%s", result)
+	}
+}
+
+func TestRunServer_NotReady(t *testing.T) {
+	store := NewBackgroundTaskStore()
+	tool := NewRunServerTool(NewBashTool(t.TempDir()), store)
+	defer store.StopAll()
+
+	start := time.Now()
+	result := runServer(t, tool, RunServerInput{Command: "echo booting; sleep 30", ReadyPattern: "ready", Timeout: intPtr(300)})
+	if !strings.Contains(result, "not ready after") || !strings.Contains(result, `waiting for output matching "ready"`) {
+		t.Errorf("expected not-ready result, got:\n%s", result)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("waited too long: %s", time.Since(start))
+	}
+}
@@ -0,0 +1,109 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// shellSession carries shell state between Bash tool calls. Each command
+// still runs in a fresh bash process, but the working directory, exported
+// variables, and shell functions left behind by one command are restored
+// before the next, so `cd`, `export`, and `source venv/bin/activate`
+// behave as they would in an interactive terminal.
+type shellSession struct {
+	mu       sync.Mutex
+	workDir  string // initial working directory, restored on reset
+	cwd      string // current working directory
+	stateDir string // holds the env snapshot and cwd files; created lazily
+}
+
+func newShellSession(workDir string) *shellSession {
+	return &shellSession{workDir: workDir, cwd: workDir}
+}
+
+// Cwd returns the session's current working directory.
+func (s *shellSession) Cwd() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cwd
+}
+
+// Reset discards saved shell state and returns to the initial directory.
+func (s *shellSession) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cwd = s.workDir
+	if s.stateDir != "" {
+		os.RemoveAll(s.stateDir)
+		s.stateDir = ""
+	}
+}
+
+// prepare returns the script to run for command, plus the directory it
+// should start in. The script restores the saved environment, runs the
+// command, and, if save is set, (via an EXIT trap, so `exit` in the command
+// is honored) saves the resulting environment and working directory.
+// Background commands pass save=false so they can't race foreground ones.
+func (s *shellSession) prepare(command string, save bool) (script, dir string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stateDir == "" {
+		d, err := os.MkdirTemp("", "claude-shell-")
+		if err != nil {
+			return "", "", fmt.Errorf("creating shell state directory: %w", err)
+		}
+		s.stateDir = d
+	}
+
+	if info, err := os.Stat(s.cwd); err != nil || !info.IsDir() {
+		s.cwd = s.workDir
+	}
+
+	envFile := shellQuote(filepath.Join(s.stateDir, "env.sh"))
+	cwdFile := shellQuote(filepath.Join(s.stateDir, "cwd"))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "[ -f %s ] && source %s >/dev/null 2>&1\n", envFile, envFile)
+	if save {
+		fmt.Fprintf(&b, "__claude_save_state() { local ec=$?; { export -p; declare -f; } > %s.tmp 2>/dev/null && mv -f %s.tmp %s; pwd > %s 2>/dev/null; return $ec; }\n",
+			envFile, envFile, envFile, cwdFile)
+		b.WriteString("trap __claude_save_state EXIT\n")
+	}
+	b.WriteString(command)
+	b.WriteString("\n")
+	return b.String(), s.cwd, nil
+}
+
+// update records the working directory left behind by the last command.
+// It returns a note for the tool result when the directory had to be reset.
+func (s *shellSession) update() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stateDir == "" {
+		return ""
+	}
+
+	data, err := os.ReadFile(filepath.Join(s.stateDir, "cwd"))
+	if err != nil {
+		return ""
+	}
+	cwd := strings.TrimSpace(string(data))
+	if cwd == "" {
+		return ""
+	}
+	if info, err := os.Stat(cwd); err != nil || !info.IsDir() {
+		s.cwd = s.workDir
+		return fmt.Sprintf("Shell cwd %s no longer exists; reset to %s", cwd, s.workDir)
+	}
+	s.cwd = cwd
+	return ""
+}
+
+// shellQuote quotes a string for safe use as a single bash word.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
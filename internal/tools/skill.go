@@ -0,0 +1,103 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/anthropics/claude-code-go/internal/skills"
+)
+
+// SkillInput is the input schema for the Skill tool.
+type SkillInput struct {
+	Skill string `json:"skill"`          // skill name, or its trigger such as "/commit"
+	Args  string `json:"args,omitempty"` // optional arguments
+}
+
+// SkillTool expands a skill's instructions into the conversation when the
+// model decides to use it, for example when the user types "/commit" in a
+// prompt that isn't itself a slash command.
+type SkillTool struct {
+	skills []skills.Skill // in load order, excluding disable-model-invocation
+}
+
+// NewSkillTool creates a Skill tool for the loaded skills. A skill with a
+// trigger is a slash command, so, as for the SlashCommand tool, it is only
+// included if allow (the modelSlashCommands setting) lists it.
+func NewSkillTool(loaded []skills.Skill, allow []string) *SkillTool {
+	t := &SkillTool{}
+	allowed := slashCommandAllowlist(allow)
+	for _, s := range loaded {
+		if s.DisableModelInvocation || (s.Trigger != "" && !allowed(strings.TrimPrefix(s.Trigger, "/"))) {
+			continue
+		}
+		t.skills = append(t.skills, s)
+	}
+	return t
+}
+
+// Len returns the number of skills the model may use.
+func (t *SkillTool) Len() int { return len(t.skills) }
+
+func (t *SkillTool) Name() string { return "Skill" }
+
+func (t *SkillTool) Description() string {
+	var b strings.Builder
+	b.WriteString(`Executes a skill, returning its full instructions for you to follow. When the user refers to "/<skill-name>" or asks for a task a skill covers, call this tool with the skill's name before doing the task. Only use skills listed below; do not guess names or use built-in CLI commands.
+
+User-invocable skills:`)
+	for _, s := range t.skills {
+		b.WriteString("\n- " + s.Name)
+		if s.Trigger != "" {
+			b.WriteString(" (" + s.Trigger + ")")
+		}
+		if s.Description != "" {
+			b.WriteString(": " + s.Description)
+		}
+	}
+	return b.String()
+}
+
+func (t *SkillTool) InputSchema() json.RawMessage {
+	return json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "skill": {
+      "type": "string",
+      "description": "The skill name, e.g. \"commit\""
+    },
+    "args": {
+      "type": "string",
+      "description": "Optional arguments for the skill"
+    }
+  },
+  "required": ["skill"],
+  "additionalProperties": false
+}`)
+}
+
+func (t *SkillTool) RequiresPermission(_ json.RawMessage) bool {
+	return false // only returns instructions; the tools used to follow them are checked
+}
+
+func (t *SkillTool) Execute(_ context.Context, input json.RawMessage) (string, error) {
+	var in SkillInput
+	if err := json.Unmarshal(input, &in); err != nil {
+		return "", fmt.Errorf("parsing Skill input: %w", err)
+	}
+	name := strings.TrimSpace(in.Skill)
+	if name == "" {
+		return "Error: skill is required", nil
+	}
+	for _, s := range t.skills {
+		if s.Name == name || (s.Trigger != "" && strings.TrimPrefix(s.Trigger, "/") == strings.TrimPrefix(name, "/")) {
+			return expandSkill("skill "+s.Name, s.Content, in.Args), nil
+		}
+	}
+	names := make([]string, len(t.skills))
+	for i, s := range t.skills {
+		names[i] = s.Name
+	}
+	return fmt.Sprintf("Error: unknown skill %q (available: %s)", name, strings.Join(names, ", ")), nil
+}
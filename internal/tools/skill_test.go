@@ -0,0 +1,43 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/anthropics/claude-code-go/internal/skills"
+)
+
+func TestSkillTool(t *testing.T) {
+	tool := NewSkillTool([]skills.Skill{
+		{Name: "commit", Description: "Create a git commit", Trigger: "/commit", Content: "Stage and commit."},
+		{Name: "style", Content: "Use tabs."},
+		{Name: "release", Content: "Cut a release.", DisableModelInvocation: true},
+		{Name: "deploy", Trigger: "/deploy", Content: "Deploy to production."},
+	}, []string{"commit"})
+	if tool.Len() != 2 {
+		t.Fatalf("Len = %d, want 2", tool.Len())
+	}
+	desc := tool.Description()
+	if !strings.Contains(desc, "- commit (/commit): Create a git commit") || !strings.Contains(desc, "- style") || strings.Contains(desc, "release") {
+		t.Errorf("unexpected description:\n%s", desc)
+	}
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`{"skill":"commit","args":"-m fix"}`, "Running skill commit. Follow these instructions:\n\nStage and commit.\n\nArguments: -m fix"},
+		{`{"skill":"/commit"}`, "Running skill commit. Follow these instructions:\n\nStage and commit."},
+		{`{"skill":"style"}`, "Running skill style. Follow these instructions:\n\nUse tabs."},
+		{`{"skill":"release"}`, `Error: unknown skill "release" (available: commit, style)`},
+		{`{"skill":"/deploy"}`, `Error: unknown skill "/deploy" (available: commit, style)`},
+	}
+	for _, tt := range tests {
+		out, err := tool.Execute(context.Background(), json.RawMessage(tt.input))
+		if err != nil || out != tt.want {
+			t.Errorf("%s: got %q, %v; want %q", tt.input, out, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/anthropics/claude-code-go/internal/skills"
+)
+
+// SlashCommandInput is the input schema for the SlashCommand tool.
+type SlashCommandInput struct {
+	Command string `json:"command"` // e.g. "/deploy-checklist staging"
+}
+
+// SlashCommandTool lets the model run user-defined slash commands (skills
+// with a trigger) itself, when instructions reference those workflows.
+// Only commands on the allowlist from settings are available.
+type SlashCommandTool struct {
+	commands map[string]skills.Skill // by name, without the slash
+	names    []string                // sorted
+}
+
+// NewSlashCommandTool creates a SlashCommand tool for the skills whose
+// triggers are on the allowlist. Allowlist entries may omit the leading
+// slash; "*" allows every skill with a trigger.
+func NewSlashCommandTool(loaded []skills.Skill, allow []string) *SlashCommandTool {
+	t := &SlashCommandTool{commands: make(map[string]skills.Skill)}
+	allowed := slashCommandAllowlist(allow)
+	for _, s := range loaded {
+		name := strings.TrimPrefix(s.Trigger, "/")
+		if name == "" || !allowed(name) {
+			continue
+		}
+		if _, dup := t.commands[name]; !dup {
+			t.names = append(t.names, name)
+		}
+		t.commands[name] = s
+	}
+	slices.Sort(t.names)
+	return t
+}
+
+// slashCommandAllowlist returns whether the modelSlashCommands allowlist
+// allows a command, named without its slash.
+func slashCommandAllowlist(allow []string) func(name string) bool {
+	allowed := make(map[string]bool)
+	for _, name := range allow {
+		allowed[strings.TrimPrefix(name, "/")] = true
+	}
+	return func(name string) bool { return allowed["*"] || allowed[name] }
+}
+
+// Len returns the number of commands the model may run.
+func (t *SlashCommandTool) Len() int { return len(t.names) }
+
+func (t *SlashCommandTool) Name() string { return "SlashCommand" }
+
+func (t *SlashCommandTool) Description() string {
+	var b strings.Builder
+	b.WriteString(`Runs one of the user's custom slash commands and returns its instructions, which you should then follow. Use this when the user or project instructions refer to one of these workflows, e.g. "run /deploy-checklist before releasing".
+
+Text after the command name is passed to it as arguments.
+
+Available commands:`)
+	for _, name := range t.names {
+		b.WriteString("\n- /" + name)
+		if d := t.commands[name].Description; d != "" {
+			b.WriteString(": " + d)
+		}
+	}
+	return b.String()
+}
+
+func (t *SlashCommandTool) InputSchema() json.RawMessage {
+	return json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "command": {
+      "type": "string",
+      "description": "The slash command to run, with any arguments, e.g. \"/deploy-checklist staging\""
+    }
+  },
+  "required": ["command"],
+  "additionalProperties": false
+}`)
+}
+
+// RequiresPermission returns false: the user opted in to each command by
+// listing it in settings, and the tools the model uses to carry out its
+// instructions are permission-checked as usual.
+func (t *SlashCommandTool) RequiresPermission(_ json.RawMessage) bool {
+	return false
+}
+
+func (t *SlashCommandTool) Execute(_ context.Context, input json.RawMessage) (string, error) {
+	var in SlashCommandInput
+	if err := json.Unmarshal(input, &in); err != nil {
+		return "", fmt.Errorf("parsing SlashCommand input: %w", err)
+	}
+	name, args, _ := strings.Cut(strings.TrimSpace(in.Command), " ")
+	name = strings.TrimPrefix(name, "/")
+	cmd, ok := t.commands[name]
+	if !ok {
+		if name == "" {
+			return "Error: command is required", nil
+		}
+		return fmt.Sprintf("Error: /%s is not available to the SlashCommand tool (available: /%s)", name, strings.Join(t.names, ", /")), nil
+	}
+
+	return expandSkill("/"+name, cmd.Content, args), nil
+}
+
+// expandSkill returns a skill's instructions as a tool result, followed by
+// any arguments the model passed.
+func expandSkill(label, content, args string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Running %s. Follow these instructions:\n\n%s", label, content)
+	if args = strings.TrimSpace(args); args != "" {
+		fmt.Fprintf(&b, "\n\nArguments: %s", args)
+	}
+	return b.String()
+}
@@ -0,0 +1,48 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/anthropics/claude-code-go/internal/skills"
+)
+
+var testSkills = []skills.Skill{
+	{Name: "deploy", Description: "Pre-deploy checks", Trigger: "/deploy-checklist", Content: "1. Run the tests."},
+	{Name: "review", Trigger: "/review", Content: "Review the diff."},
+	{Name: "notes", Content: "No trigger, not a command."},
+}
+
+func TestSlashCommandToolAllowlist(t *testing.T) {
+	tool := NewSlashCommandTool(testSkills, []string{"deploy-checklist"})
+	if tool.Len() != 1 {
+		t.Fatalf("Len = %d, want 1", tool.Len())
+	}
+	if desc := tool.Description(); !strings.Contains(desc, "- /deploy-checklist: Pre-deploy checks") || strings.Contains(desc, "/review") {
+		t.Errorf("description lists the wrong commands:\n%s", desc)
+	}
+
+	out, err := tool.Execute(context.Background(), json.RawMessage(`{"command":"/review"}`))
+	if err != nil || !strings.HasPrefix(out, "Error: /review is not available") {
+		t.Errorf("got %q, %v", out, err)
+	}
+
+	if all := NewSlashCommandTool(testSkills, []string{"*"}); all.Len() != 2 {
+		t.Errorf("* allowed %d commands, want 2", all.Len())
+	}
+}
+
+func TestSlashCommandToolExecute(t *testing.T) {
+	tool := NewSlashCommandTool(testSkills, []string{"/deploy-checklist"})
+	input, _ := json.Marshal(SlashCommandInput{Command: "/deploy-checklist staging"})
+	out, err := tool.Execute(context.Background(), input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Running /deploy-checklist. Follow these instructions:\n\n1. Run the tests.\n\nArguments: staging"
+	if out != want {
+		t.Errorf("got %q, want %q", out, want)
+	}
+}
@@ -0,0 +1,425 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/anthropics/claude-code-go/internal/config"
+)
+
+const (
+	sqlDefaultMaxRows = 100
+	sqlMaxRows        = 1000
+	sqlMaxCellLen     = 200
+	sqlMaxOutput      = 50_000
+	sqlTimeout        = 60 * time.Second
+)
+
+// SQLInput is the input schema for the SQL tool.
+type SQLInput struct {
+	Database string `json:"database"`
+	Query    string `json:"query"`
+	MaxRows  *int   `json:"max_rows,omitempty"`
+}
+
+// SQLTool runs queries against the databases configured in settings, using
+// the sqlite3, psql, and mysql command-line clients. Queries are read-only
+// unless a database is configured with readWrite.
+type SQLTool struct {
+	workDir   string
+	databases map[string]config.DatabaseConfig
+}
+
+// NewSQLTool creates a new SQL tool for the given databases. Relative
+// SQLite paths are resolved against workDir.
+func NewSQLTool(workDir string, databases map[string]config.DatabaseConfig) *SQLTool {
+	return &SQLTool{workDir: workDir, databases: databases}
+}
+
+func (t *SQLTool) Name() string { return "SQL" }
+
+func (t *SQLTool) Description() string {
+	names := make([]string, 0, len(t.databases))
+	for name, db := range t.databases {
+		mode := "read-only"
+		if db.ReadWrite {
+			mode = "read-write"
+		}
+		names = append(names, fmt.Sprintf("%s (%s, %s)", name, sqlEngine(db.URL), mode))
+	}
+	sort.Strings(names)
+	return `Runs a SQL query against a configured database and returns the results as a table. Use it to explore schemas and debug data.
+
+Configured databases: ` + strings.Join(names, ", ") + `
+
+- Only SQL is run: client backslash commands such as \! and \o are rejected, and SQLite's file functions (readfile, writefile) are disabled.
+- Read-only databases only accept queries that read data (SELECT, WITH, EXPLAIN, SHOW, DESCRIBE, PRAGMA); the session is also opened read-only.
+- Results are limited to max_rows rows (default 100, max 1000) and long values are truncated. Add a LIMIT or narrow the query rather than raising max_rows.
+- To explore a schema: in SQLite use "SELECT name, sql FROM sqlite_master"; in Postgres and MySQL query information_schema.`
+}
+
+func (t *SQLTool) InputSchema() json.RawMessage {
+	return json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "database": {
+      "type": "string",
+      "description": "Name of the configured database to query"
+    },
+    "query": {
+      "type": "string",
+      "description": "The SQL to run"
+    },
+    "max_rows": {
+      "type": "integer",
+      "description": "Maximum number of rows to return (default 100, max 1000)"
+    }
+  },
+  "required": ["database", "query"],
+  "additionalProperties": false
+}`)
+}
+
+func (t *SQLTool) RequiresPermission(_ json.RawMessage) bool {
+	return true // reads data outside the workspace
+}
+
+func (t *SQLTool) Execute(ctx context.Context, input json.RawMessage) (string, error) {
+	var in SQLInput
+	if err := json.Unmarshal(input, &in); err != nil {
+		return "", fmt.Errorf("parsing SQL input: %w", err)
+	}
+	if strings.TrimSpace(in.Query) == "" {
+		return "Error: query is required", nil
+	}
+	db, ok := t.databases[in.Database]
+	if !ok {
+		names := make([]string, 0, len(t.databases))
+		for name := range t.databases {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return fmt.Sprintf("Error: unknown database %q (configured: %s)", in.Database, strings.Join(names, ", ")), nil
+	}
+	// psql and mysql act on backslash commands in -c and -e, and some of
+	// them run shell commands or write files, which readWrite doesn't
+	// allow either.
+	if cmd := clientCommand(in.Query); cmd != "" {
+		return fmt.Sprintf("Error: %q is a client command, not SQL; only SQL statements can be run", cmd), nil
+	}
+	if !db.ReadWrite {
+		if stmt := firstWriteStatement(in.Query); stmt != "" {
+			return fmt.Sprintf("Error: database %s is read-only; %q modifies data. Set readWrite in its settings to allow this.", in.Database, stmt), nil
+		}
+	}
+	maxRows := sqlDefaultMaxRows
+	if in.MaxRows != nil && *in.MaxRows > 0 {
+		maxRows = min(*in.MaxRows, sqlMaxRows)
+	}
+
+	cmd, tsv, err := t.command(db, in.Query)
+	if err != nil {
+		return "Error: " + err.Error(), nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, sqlTimeout)
+	defer cancel()
+	c := exec.CommandContext(ctx, cmd.path, cmd.args...)
+	c.Env = append(os.Environ(), cmd.env...)
+	var stdout, stderr bytes.Buffer
+	c.Stdout = &stdout
+	c.Stderr = &stderr
+	if err := c.Run(); err != nil {
+		switch {
+		case errors.Is(err, exec.ErrNotFound):
+			return fmt.Sprintf("Error: %s is not installed; it is needed to query %s databases", cmd.path, sqlEngine(db.URL)), nil
+		case ctx.Err() != nil:
+			return fmt.Sprintf("Error: query timed out after %s", sqlTimeout), nil
+		case stderr.Len() > 0:
+			return "Error: " + strings.TrimSpace(stderr.String()), nil
+		}
+		return fmt.Sprintf("Error: %s failed: %v", cmd.path, err), nil
+	}
+
+	rows, err := parseSQLOutput(stdout.String(), tsv)
+	if err != nil {
+		return fmt.Sprintf("Error parsing query output: %v", err), nil
+	}
+	if len(rows) == 0 {
+		return "Query OK (no rows returned).", nil
+	}
+	return formatSQLRows(rows, maxRows), nil
+}
+
+// sqlCommand is a client invocation for one query.
+type sqlCommand struct {
+	path string
+	args []string
+	env  []string
+}
+
+// sqlEngine returns the engine named by a database URL.
+func sqlEngine(rawURL string) string {
+	switch {
+	case strings.HasPrefix(rawURL, "postgres://"), strings.HasPrefix(rawURL, "postgresql://"):
+		return "postgres"
+	case strings.HasPrefix(rawURL, "mysql://"):
+		return "mysql"
+	}
+	return "sqlite"
+}
+
+// command builds the client invocation for query. It reports whether the
+// client writes tab-separated output rather than CSV.
+func (t *SQLTool) command(db config.DatabaseConfig, query string) (*sqlCommand, bool, error) {
+	rawURL := db.URL
+	if db.ExpandEnv {
+		rawURL = os.ExpandEnv(rawURL)
+	} else if refersToEnv(rawURL) {
+		return nil, false, errors.New("database URLs in project settings can't use $VAR references; define this database in ~/.claude/settings.json instead")
+	}
+	switch sqlEngine(rawURL) {
+	case "postgres":
+		cmd := &sqlCommand{path: "psql", args: []string{"-X", "-q", "--csv", "-v", "ON_ERROR_STOP=1", "-d", rawURL, "-c", query}}
+		if !db.ReadWrite {
+			// The query could turn this off again with SET or BEGIN READ
+			// WRITE; firstWriteStatement rejects both, as neither is a read.
+			cmd.env = []string{"PGOPTIONS=-c default_transaction_read_only=on"}
+		}
+		return cmd, false, nil
+
+	case "mysql":
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid database URL: %v", err)
+		}
+		args := []string{"--batch"}
+		if !db.ReadWrite {
+			// Set on connecting rather than prefixed to the query. The
+			// query could still run SET SESSION TRANSACTION READ WRITE;
+			// firstWriteStatement rejects it, as SET isn't a read.
+			args = append(args, "--init-command=SET SESSION TRANSACTION READ ONLY")
+		}
+		args = append(args, "-h", u.Hostname())
+		if port := u.Port(); port != "" {
+			args = append(args, "-P", port)
+		}
+		cmd := &sqlCommand{path: "mysql"}
+		if u.User != nil {
+			args = append(args, "-u", u.User.Username())
+			if pw, ok := u.User.Password(); ok {
+				cmd.env = []string{"MYSQL_PWD=" + pw} // keeps it out of the process list
+			}
+		}
+		if name := strings.TrimPrefix(u.Path, "/"); name != "" {
+			args = append(args, name)
+		}
+		cmd.args = append(args, "-e", query)
+		return cmd, true, nil
+	}
+
+	path := strings.TrimPrefix(strings.TrimPrefix(rawURL, "sqlite3://"), "sqlite://")
+	if path == "" {
+		return nil, false, errors.New("database URL has no path")
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(t.workDir, path)
+	}
+	// -readonly doesn't stop readfile() and writefile() from reaching any
+	// file, nor .shell; -safe does, whether or not writes are allowed.
+	args := []string{"-bail", "-safe", "-csv", "-header"}
+	if !db.ReadWrite {
+		args = append(args, "-readonly")
+	}
+	return &sqlCommand{path: "sqlite3", args: append(args, path, query)}, false, nil
+}
+
+// refersToEnv reports whether s has a $VAR or ${VAR} reference.
+func refersToEnv(s string) bool {
+	found := false
+	os.Expand(s, func(string) string {
+		found = true
+		return ""
+	})
+	return found
+}
+
+// clientCommand returns the first backslash command in query, such as
+// psql's \! or \o and mysql's \! or \., or "" if there is none. Quoted
+// strings are skipped both with and without backslash escapes, since the
+// clients disagree; a command in either reading is reported. Comments
+// aren't skipped, so a backslash command in one is reported too.
+func clientCommand(query string) string {
+	for _, escapes := range []bool{false, true} {
+		var quote byte
+		for i := 0; i < len(query); i++ {
+			c := query[i]
+			switch {
+			case quote != 0:
+				if escapes && c == '\\' {
+					i++
+				} else if c == quote {
+					quote = 0
+				}
+			case c == '\'' || c == '"' || c == '`':
+				quote = c
+			case c == '\\' && i+1 < len(query) && isClientCommandChar(query[i+1]):
+				return strings.Fields(query[i:])[0]
+			}
+		}
+	}
+	return ""
+}
+
+// isClientCommandChar reports whether a backslash followed by c starts a
+// client command.
+func isClientCommandChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '!' || c == '.'
+}
+
+// sqlReadKeywords are the statements allowed on read-only databases.
+var sqlReadKeywords = map[string]bool{
+	"select": true, "with": true, "explain": true, "show": true,
+	"describe": true, "desc": true, "pragma": true, "values": true, "table": true,
+}
+
+// firstWriteStatement returns the first statement in query that isn't a
+// read, or "" if every statement only reads. The session is opened
+// read-only as well; this check gives a clearer error up front.
+func firstWriteStatement(query string) string {
+	for _, stmt := range splitSQLStatements(query) {
+		fields := strings.Fields(stmt)
+		if len(fields) == 0 {
+			continue
+		}
+		kw := strings.ToLower(strings.TrimLeft(fields[0], "("))
+		// PRAGMA name = value changes settings in SQLite.
+		if !sqlReadKeywords[kw] || (kw == "pragma" && strings.Contains(stmt, "=")) {
+			return stmt
+		}
+	}
+	return ""
+}
+
+// splitSQLStatements splits query on semicolons outside quotes, dropping
+// comments.
+func splitSQLStatements(query string) []string {
+	var stmts []string
+	var cur strings.Builder
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			j := i + 1
+			for j < len(query) && query[j] != c {
+				j++
+			}
+			j = min(j, len(query)-1)
+			cur.WriteString(query[i : j+1])
+			i = j
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			if end := strings.IndexByte(query[i:], '\n'); end >= 0 {
+				i += end
+			} else {
+				i = len(query)
+			}
+			cur.WriteByte(' ')
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			if end := strings.Index(query[i+2:], "*/"); end >= 0 {
+				i += end + 3
+			} else {
+				i = len(query)
+			}
+			cur.WriteByte(' ')
+		case c == ';':
+			stmts = append(stmts, strings.TrimSpace(cur.String()))
+			cur.Reset()
+		default:
+			cur.WriteByte(c)
+		}
+	}
+	if s := strings.TrimSpace(cur.String()); s != "" {
+		stmts = append(stmts, s)
+	}
+	return stmts
+}
+
+// parseSQLOutput parses client output into rows, the first being the
+// column names. mysql --batch output is tab-separated with backslash
+// escapes; the other clients write CSV.
+func parseSQLOutput(out string, tsv bool) ([][]string, error) {
+	if strings.TrimSpace(out) == "" {
+		return nil, nil
+	}
+	if !tsv {
+		r := csv.NewReader(strings.NewReader(out))
+		r.FieldsPerRecord = -1
+		return r.ReadAll()
+	}
+	var rows [][]string
+	unescape := strings.NewReplacer(`\t`, "\t", `\n`, "\n", `\0`, "\x00", `\\`, `\`)
+	for _, line := range strings.Split(strings.TrimRight(out, "\n"), "\n") {
+		fields := strings.Split(line, "\t")
+		for i, f := range fields {
+			fields[i] = unescape.Replace(f)
+		}
+		rows = append(rows, fields)
+	}
+	return rows, nil
+}
+
+// formatSQLRows renders rows as a Markdown table, keeping at most maxRows
+// data rows and shortening long values.
+func formatSQLRows(rows [][]string, maxRows int) string {
+	header, data := rows[0], rows[1:]
+	cell := func(s string) string {
+		s = strings.ReplaceAll(strings.ReplaceAll(s, "\n", `\n`), "|", `\|`)
+		if len(s) > sqlMaxCellLen {
+			n := sqlMaxCellLen
+			for n > 0 && !utf8.RuneStart(s[n]) {
+				n--
+			}
+			s = s[:n] + "…"
+		}
+		return s
+	}
+
+	var b strings.Builder
+	writeRow := func(r []string) {
+		cells := make([]string, len(header))
+		for i := range cells {
+			if i < len(r) {
+				cells[i] = cell(r[i])
+			}
+		}
+		b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+	writeRow(header)
+	b.WriteString("|" + strings.Repeat(" --- |", len(header)) + "\n")
+	shown := 0
+	for _, r := range data {
+		if shown == maxRows || b.Len() > sqlMaxOutput {
+			break
+		}
+		writeRow(r)
+		shown++
+	}
+
+	if shown < len(data) {
+		fmt.Fprintf(&b, "\n(showing %d of %d rows; add a LIMIT or narrow the query to see the rest)", shown, len(data))
+	} else {
+		fmt.Fprintf(&b, "\n(%d rows)", len(data))
+	}
+	return b.String()
+}
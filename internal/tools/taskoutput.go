@@ -27,7 +27,7 @@ func NewTaskOutputTool(bgStore *BackgroundTaskStore) *TaskOutputTool {
 func (t *TaskOutputTool) Name() string { return "TaskOutput" }
 
 func (t *TaskOutputTool) Description() string {
-	return `Read output from a background task. Use task_id to identify the task. Set block=true to wait for completion. Use timeout (ms) to limit how long to wait.`
+	return `Read output from a background task. Use task_id to identify the task. Set block=true to wait for completion. Use timeout (ms) to limit how long to wait. For servers started with RunServer, use block=false to get their health and recent logs without waiting. Background agents from an earlier run of a resumed session can still be read; an agent reported as interrupted can be continued with the Agent tool's resume parameter.`
 }
 
 func (t *TaskOutputTool) InputSchema() json.RawMessage {
@@ -103,10 +103,7 @@ func (t *TaskOutputTool) Execute(ctx context.Context, input json.RawMessage) (st
 	// Check if task is done.
 	select {
 	case <-task.Done:
-		status := "completed"
-		if task.Err != nil {
-			status = "error"
-		}
+		status := task.Status()
 		result := map[string]interface{}{
 			"status": status,
 			"taskId": in.TaskID,
@@ -115,6 +112,12 @@ func (t *TaskOutputTool) Execute(ctx context.Context, input json.RawMessage) (st
 		if task.Err != nil {
 			result["error"] = task.Err.Error()
 		}
+		if task.OutputFile != "" {
+			result["outputFile"] = task.OutputFile
+		}
+		if status == TaskInterrupted && task.Kind == "agent" {
+			result["message"] = fmt.Sprintf("Agent was interrupted before finishing. Continue it with the Agent tool: resume=%q.", in.TaskID)
+		}
 		out, _ := json.Marshal(result)
 		return string(out), nil
 	default:
@@ -123,6 +126,12 @@ func (t *TaskOutputTool) Execute(ctx context.Context, input json.RawMessage) (st
 			"taskId":  in.TaskID,
 			"message": "Task is still running",
 		}
+		if task.Output != nil {
+			result["output"] = task.Output()
+		}
+		if task.Health != nil {
+			result["health"] = task.Health()
+		}
 		out, _ := json.Marshal(result)
 		return string(out), nil
 	}
@@ -0,0 +1,95 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// TaskSteerInput is the input schema for the TaskSteer tool.
+type TaskSteerInput struct {
+	TaskID  string `json:"task_id"`
+	Message string `json:"message"`
+}
+
+// TaskSteerTool sends follow-up instructions to a running background agent.
+type TaskSteerTool struct {
+	bgStore *BackgroundTaskStore
+}
+
+// NewTaskSteerTool creates a new TaskSteer tool.
+func NewTaskSteerTool(bgStore *BackgroundTaskStore) *TaskSteerTool {
+	return &TaskSteerTool{bgStore: bgStore}
+}
+
+func (t *TaskSteerTool) Name() string { return "TaskSteer" }
+
+func (t *TaskSteerTool) Description() string {
+	return `Send follow-up instructions to a background agent while it runs, for example to narrow its search, change its focus, or ask it to wrap up. The agent sees the message after its current step and carries on with its conversation, so nothing it has done is lost. Use task_id to identify the agent. Only running agents started with run_in_background can be steered; to continue a finished or interrupted agent, use the Agent tool's resume parameter instead.`
+}
+
+func (t *TaskSteerTool) InputSchema() json.RawMessage {
+	return json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "task_id": {
+      "type": "string",
+      "description": "The ID of the background agent"
+    },
+    "message": {
+      "type": "string",
+      "description": "The instructions to send to the agent"
+    }
+  },
+  "required": ["task_id", "message"],
+  "additionalProperties": false
+}`)
+}
+
+func (t *TaskSteerTool) RequiresPermission(_ json.RawMessage) bool {
+	return false
+}
+
+func (t *TaskSteerTool) Execute(_ context.Context, input json.RawMessage) (string, error) {
+	var in TaskSteerInput
+	if err := json.Unmarshal(input, &in); err != nil {
+		return "", fmt.Errorf("parsing TaskSteer input: %w", err)
+	}
+	if in.TaskID == "" {
+		return "Error: task_id is required", nil
+	}
+	if strings.TrimSpace(in.Message) == "" {
+		return "Error: message is required", nil
+	}
+	if err := SteerTask(t.bgStore, in.TaskID, in.Message); err != nil {
+		return "Error: " + err.Error(), nil
+	}
+
+	result := map[string]interface{}{
+		"status":  "delivered",
+		"taskId":  in.TaskID,
+		"message": "The agent will see the message after its current step. Read its result with TaskOutput.",
+	}
+	out, _ := json.Marshal(result)
+	return string(out), nil
+}
+
+// SteerTask sends message to the running background agent with the given
+// ID. It is shared by the TaskSteer tool and the /steer command.
+func SteerTask(store *BackgroundTaskStore, id, message string) error {
+	task, ok := store.Get(id)
+	if !ok {
+		return fmt.Errorf("task %s not found", id)
+	}
+	if task.Steer == nil {
+		if task.Kind == "agent" {
+			return fmt.Errorf("agent %s is not running; continue it with the Agent tool's resume parameter", id)
+		}
+		return fmt.Errorf("task %s is not an agent and can't take messages", id)
+	}
+	if task.Status() != TaskRunning || !task.Steer(message) {
+		return fmt.Errorf("agent %s is not running; continue it with the Agent tool's resume parameter", id)
+	}
+	return nil
+}
@@ -51,6 +51,25 @@ func (t *TodoWriteTool) SetProgram(p *tea.Program) {
 	t.program = p
 }
 
+// Todos returns a copy of the current todo list.
+func (t *TodoWriteTool) Todos() []TodoItem {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.todos) == 0 {
+		return nil
+	}
+	return append([]TodoItem(nil), t.todos...)
+}
+
+// SetTodos replaces the todo list without notifying the BT program. It is
+// used to restore a resumed session's list and for edits made with /todos,
+// where the caller updates the UI itself.
+func (t *TodoWriteTool) SetTodos(todos []TodoItem) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.todos = append([]TodoItem(nil), todos...)
+}
+
 func (t *TodoWriteTool) Name() string { return "TodoWrite" }
 
 func (t *TodoWriteTool) Description() string {
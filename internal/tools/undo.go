@@ -0,0 +1,139 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// undoMaxEntries bounds how many file modifications an UndoStore remembers.
+const undoMaxEntries = 100
+
+// UndoEntry is the state of a file before one modification.
+type UndoEntry struct {
+	Path    string
+	Tool    string // tool that made the modification
+	Time    time.Time
+	Existed bool // false if the modification created the file
+	Content []byte
+	Mode    os.FileMode
+}
+
+// UndoStore remembers the previous contents of files modified by the file
+// tools during a session, so the modifications can be reverted.
+type UndoStore struct {
+	mu         sync.Mutex
+	entries    []UndoEntry
+	onSnapshot func(UndoEntry)
+}
+
+// NewUndoStore creates an empty undo store.
+func NewUndoStore() *UndoStore {
+	return &UndoStore{}
+}
+
+// OnSnapshot registers fn to be called with each entry Snapshot records,
+// e.g. to keep a session's change ledger. It replaces any earlier hook.
+func (s *UndoStore) OnSnapshot(fn func(UndoEntry)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onSnapshot = fn
+}
+
+// Snapshot records the current state of path before tool modifies it. It
+// does nothing on a nil store.
+func (s *UndoStore) Snapshot(path, tool string) error {
+	if s == nil {
+		return nil
+	}
+	e := UndoEntry{Path: path, Tool: tool, Time: time.Now()}
+	info, err := os.Stat(path)
+	switch {
+	case err == nil:
+		e.Existed = true
+		e.Mode = info.Mode().Perm()
+		if e.Content, err = os.ReadFile(path); err != nil {
+			return err
+		}
+	case !os.IsNotExist(err):
+		return err
+	}
+
+	s.mu.Lock()
+	s.entries = append(s.entries, e)
+	if len(s.entries) > undoMaxEntries {
+		s.entries = s.entries[len(s.entries)-undoMaxEntries:]
+	}
+	fn := s.onSnapshot
+	s.mu.Unlock()
+	if fn != nil {
+		fn(e)
+	}
+	return nil
+}
+
+// Len returns the number of modifications that can be undone.
+func (s *UndoStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
+
+// Undo reverts the last n modifications, most recent first, and returns the
+// entries that were restored. Files that didn't exist before are removed.
+// It stops at the first failure; entries already restored are returned
+// along with the error.
+func (s *UndoStore) Undo(n int) ([]UndoEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var undone []UndoEntry
+	for ; n > 0 && len(s.entries) > 0; n-- {
+		e := s.entries[len(s.entries)-1]
+		var err error
+		if e.Existed {
+			err = writeFileAtomic(e.Path, e.Content, e.Mode)
+		} else if err = os.Remove(e.Path); os.IsNotExist(err) {
+			err = nil
+		}
+		if err != nil {
+			return undone, fmt.Errorf("restoring %s: %w", e.Path, err)
+		}
+		s.entries = s.entries[:len(s.entries)-1]
+		undone = append(undone, e)
+	}
+	return undone, nil
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames
+// it into place, so readers never see a partially written file. If path is
+// a symlink, its target is replaced.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	if target, err := filepath.EvalSymlinks(path); err == nil {
+		path = target
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp) // no-op after a successful rename
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp, perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
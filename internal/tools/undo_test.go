@@ -0,0 +1,83 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUndoStore_EditAndWrite(t *testing.T) {
+	store := NewUndoStore()
+	path := filepath.Join(t.TempDir(), "f.txt")
+	os.WriteFile(path, []byte("hello world\n"), 0600)
+
+	edit := NewFileEditTool()
+	edit.SetUndoStore(store)
+	input, _ := json.Marshal(FileEditInput{FilePath: path, OldString: "world", NewString: "there"})
+	edit.Execute(context.Background(), input)
+
+	write := NewFileWriteTool()
+	write.SetUndoStore(store)
+	input, _ = json.Marshal(FileWriteInput{FilePath: path, Content: "replaced\n"})
+	write.Execute(context.Background(), input)
+
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v, want 0600 preserved", info.Mode().Perm())
+	}
+	if store.Len() != 2 {
+		t.Fatalf("Len = %d, want 2", store.Len())
+	}
+
+	undone, err := store.Undo(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(undone) != 2 || undone[0].Tool != "FileWrite" || undone[1].Tool != "FileEdit" {
+		t.Errorf("undone = %+v, want FileWrite then FileEdit", undone)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "hello world\n" {
+		t.Errorf("file = %q, want original", data)
+	}
+	if store.Len() != 0 {
+		t.Errorf("Len = %d, want 0", store.Len())
+	}
+}
+
+func TestUndoStore_Limit(t *testing.T) {
+	store := NewUndoStore()
+	path := filepath.Join(t.TempDir(), "f.txt")
+	for i := 0; i < undoMaxEntries+10; i++ {
+		if err := store.Snapshot(path, "FileWrite"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if store.Len() != undoMaxEntries {
+		t.Errorf("Len = %d, want %d", store.Len(), undoMaxEntries)
+	}
+}
+
+func TestWriteFileAtomic_Symlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "target.txt")
+	link := filepath.Join(dir, "link.txt")
+	os.WriteFile(target, []byte("old"), 0644)
+	if err := os.Symlink(target, link); err != nil {
+		t.Skip("symlinks not supported")
+	}
+
+	if err := writeFileAtomic(link, []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if fi, _ := os.Lstat(link); fi.Mode()&os.ModeSymlink == 0 {
+		t.Error("symlink was replaced by a regular file")
+	}
+	if data, _ := os.ReadFile(target); string(data) != "new" {
+		t.Errorf("target = %q, want new", data)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Errorf("temp files left behind: %v", entries)
+	}
+}
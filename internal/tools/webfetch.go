@@ -5,11 +5,21 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
-	"regexp"
+	"net/url"
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
+)
+
+const (
+	webFetchCacheTTL        = 15 * time.Minute
+	webFetchCacheMaxEntries = 50
+	webFetchMaxRedirects    = 10
+	webFetchMaxBodySize     = 10 * 1024 * 1024
+	webFetchMaxContent      = 100_000 // characters of converted content
 )
 
 // WebFetchInput is the input schema for the WebFetch tool.
@@ -21,6 +31,9 @@ type WebFetchInput struct {
 // webFetchCacheEntry stores a cached fetch result.
 type webFetchCacheEntry struct {
 	content   string
+	code      int
+	codeText  string
+	bytes     int
 	fetchedAt time.Time
 }
 
@@ -61,7 +74,12 @@ func NewWebFetchTool(httpClient *http.Client) *WebFetchTool {
 func (t *WebFetchTool) Name() string { return "WebFetch" }
 
 func (t *WebFetchTool) Description() string {
-	return `Fetches content from a URL and returns it. The URL must be a fully-formed valid URL. HTTP URLs will be automatically upgraded to HTTPS. Includes a 15-minute cache for repeated access to the same URL.`
+	return `Fetches content from a URL and returns it. HTML pages are converted to Markdown.
+
+- The URL must be a fully-formed valid URL. HTTP URLs will be automatically upgraded to HTTPS.
+- Redirects within the same host are followed. When a URL redirects to a different host, the tool reports the redirect URL instead of following it; make a new WebFetch request with that URL to fetch it.
+- Large pages are truncated, and binary content such as images is not supported.
+- Includes a 15-minute cache for repeated access to the same URL.`
 }
 
 func (t *WebFetchTool) InputSchema() json.RawMessage {
@@ -100,67 +118,144 @@ func (t *WebFetchTool) Execute(ctx context.Context, input json.RawMessage) (stri
 		return "Error: prompt is required", nil
 	}
 
-	// Upgrade HTTP to HTTPS.
-	url := in.URL
-	if strings.HasPrefix(url, "http://") {
-		url = "https://" + url[7:]
+	target, err := url.Parse(in.URL)
+	if err != nil || target.Host == "" || (target.Scheme != "http" && target.Scheme != "https") {
+		return fmt.Sprintf("Error: invalid URL %q: must be an absolute http or https URL", in.URL), nil
+	}
+	if target.User != nil {
+		return "Error: URLs with credentials are not supported", nil
 	}
+	// Upgrade HTTP to HTTPS.
+	target.Scheme = "https"
+	target.Fragment = ""
+	fetchURL := target.String()
 
 	startTime := time.Now()
 
 	// Check cache.
-	t.mu.Lock()
-	if entry, ok := t.cache[url]; ok && time.Since(entry.fetchedAt) < 15*time.Minute {
-		t.mu.Unlock()
+	if entry := t.cached(fetchURL); entry != nil {
 		durationMs := time.Since(startTime).Milliseconds()
-		return t.buildResult(url, entry.content, 200, "OK", len(entry.content), durationMs), nil
+		return t.buildResult(fetchURL, entry.content, entry.code, entry.codeText, entry.bytes, durationMs), nil
 	}
-	t.mu.Unlock()
 
 	// Fetch the URL.
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", fetchURL, nil)
 	if err != nil {
 		return fmt.Sprintf("Error creating request: %v", err), nil
 	}
 	req.Header.Set("User-Agent", "ClaudeCode/1.0")
-	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	req.Header.Set("Accept", "text/markdown,text/html,application/xhtml+xml,text/plain;q=0.9,*/*;q=0.8")
+
+	// Redirects within the same site are followed; a redirect to another
+	// host is reported back so the model can decide whether to follow it.
+	client := *t.httpClient
+	var crossHost *url.URL
+	client.CheckRedirect = func(r *http.Request, via []*http.Request) error {
+		if len(via) >= webFetchMaxRedirects {
+			return fmt.Errorf("stopped after %d redirects", webFetchMaxRedirects)
+		}
+		if !sameSite(via[0].URL, r.URL) {
+			crossHost = r.URL
+			return http.ErrUseLastResponse
+		}
+		return nil
+	}
 
-	resp, err := t.httpClient.Do(req)
+	resp, err := client.Do(req)
 	if err != nil {
 		return fmt.Sprintf("Error fetching URL: %v", err), nil
 	}
 	defer resp.Body.Close()
 
-	// Read body with size limit (10MB).
-	const maxBodySize = 10 * 1024 * 1024
-	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if crossHost != nil {
+		msg := fmt.Sprintf("REDIRECT DETECTED: The URL redirects to a different host.\n\nOriginal URL: %s\nRedirect URL: %s\nStatus: %d %s\n\nTo fetch the content, make a new WebFetch request with the redirect URL.",
+			fetchURL, crossHost, resp.StatusCode, http.StatusText(resp.StatusCode))
+		return t.buildResult(fetchURL, msg, resp.StatusCode, http.StatusText(resp.StatusCode), 0, time.Since(startTime).Milliseconds()), nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "" && !isTextMediaType(mediaType) {
+		return fmt.Sprintf("Error: %s returned unsupported content type %s", fetchURL, mediaType), nil
+	}
+
+	// Read one byte past the limit to tell whether the body was cut off.
+	body, err := io.ReadAll(io.LimitReader(resp.Body, webFetchMaxBodySize+1))
 	if err != nil {
 		return fmt.Sprintf("Error reading response: %v", err), nil
 	}
+	bodyTruncated := len(body) > webFetchMaxBodySize
+	if bodyTruncated {
+		body = body[:webFetchMaxBodySize]
+	}
 
 	content := string(body)
-
-	// Basic HTML to text conversion.
-	if strings.Contains(resp.Header.Get("Content-Type"), "text/html") {
-		content = htmlToText(content)
+	if mediaType == "text/html" || mediaType == "application/xhtml+xml" ||
+		(mediaType == "" && strings.Contains(strings.ToLower(content[:min(len(content), 512)]), "<html")) {
+		content = htmlToMarkdown(content, resp.Request.URL)
 	}
 
-	// Truncate if very large.
-	const maxContent = 100_000
-	if len(content) > maxContent {
-		content = content[:maxContent] + "\n... (content truncated)"
+	if n := utf8.RuneCountInString(content); n > webFetchMaxContent {
+		cut, runes := len(content), 0
+		for i := range content {
+			if runes == webFetchMaxContent {
+				cut = i
+				break
+			}
+			runes++
+		}
+		content = content[:cut] + fmt.Sprintf("\n\n... (content truncated to %d of %d characters)", webFetchMaxContent, n)
+	} else if bodyTruncated {
+		content += fmt.Sprintf("\n\n... (response truncated at %d MB)", webFetchMaxBodySize>>20)
 	}
 
-	// Cache the result.
-	t.mu.Lock()
-	t.cache[url] = &webFetchCacheEntry{
+	// Cache successful results.
+	entry := &webFetchCacheEntry{
 		content:   content,
+		code:      resp.StatusCode,
+		codeText:  http.StatusText(resp.StatusCode),
+		bytes:     len(body),
 		fetchedAt: time.Now(),
 	}
-	t.mu.Unlock()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		t.store(fetchURL, entry)
+	}
 
 	durationMs := time.Since(startTime).Milliseconds()
-	return t.buildResult(url, content, resp.StatusCode, http.StatusText(resp.StatusCode), len(body), durationMs), nil
+	return t.buildResult(fetchURL, content, entry.code, entry.codeText, entry.bytes, durationMs), nil
+}
+
+// cached returns the unexpired cache entry for url, or nil.
+func (t *WebFetchTool) cached(url string) *webFetchCacheEntry {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	entry, ok := t.cache[url]
+	if !ok {
+		return nil
+	}
+	if time.Since(entry.fetchedAt) >= webFetchCacheTTL {
+		delete(t.cache, url)
+		return nil
+	}
+	return entry
+}
+
+// store caches entry for url, evicting expired entries and, if the cache is
+// still full, the oldest one.
+func (t *WebFetchTool) store(url string, entry *webFetchCacheEntry) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var oldest string
+	for u, e := range t.cache {
+		if time.Since(e.fetchedAt) >= webFetchCacheTTL {
+			delete(t.cache, u)
+		} else if oldest == "" || e.fetchedAt.Before(t.cache[oldest].fetchedAt) {
+			oldest = u
+		}
+	}
+	if len(t.cache) >= webFetchCacheMaxEntries && oldest != "" {
+		delete(t.cache, oldest)
+	}
+	t.cache[url] = entry
 }
 
 // buildResult creates the JSON output for the tool.
@@ -177,33 +272,22 @@ func (t *WebFetchTool) buildResult(url, content string, code int, codeText strin
 	return string(out)
 }
 
-// htmlToText strips HTML tags and extracts text content.
-func htmlToText(html string) string {
-	// Remove script and style blocks.
-	reScript := regexp.MustCompile(`(?is)<script[^>]*>.*?</script>`)
-	html = reScript.ReplaceAllString(html, "")
-	reStyle := regexp.MustCompile(`(?is)<style[^>]*>.*?</style>`)
-	html = reStyle.ReplaceAllString(html, "")
-
-	// Replace common block elements with newlines.
-	reBlock := regexp.MustCompile(`(?i)<(?:br|p|div|h[1-6]|li|tr)[^>]*>`)
-	html = reBlock.ReplaceAllString(html, "\n")
-
-	// Strip all remaining tags.
-	reTags := regexp.MustCompile(`<[^>]+>`)
-	html = reTags.ReplaceAllString(html, "")
-
-	// Decode common HTML entities.
-	html = strings.ReplaceAll(html, "&amp;", "&")
-	html = strings.ReplaceAll(html, "&lt;", "<")
-	html = strings.ReplaceAll(html, "&gt;", ">")
-	html = strings.ReplaceAll(html, "&quot;", "\"")
-	html = strings.ReplaceAll(html, "&#39;", "'")
-	html = strings.ReplaceAll(html, "&nbsp;", " ")
-
-	// Collapse multiple blank lines.
-	reBlank := regexp.MustCompile(`\n{3,}`)
-	html = reBlank.ReplaceAllString(html, "\n\n")
-
-	return strings.TrimSpace(html)
+// sameSite reports whether a redirect from a to b stays on the same host,
+// ignoring a "www." prefix on either side.
+func sameSite(a, b *url.URL) bool {
+	return strings.TrimPrefix(strings.ToLower(a.Host), "www.") == strings.TrimPrefix(strings.ToLower(b.Host), "www.")
+}
+
+// isTextMediaType reports whether content of the given media type can be
+// returned as text.
+func isTextMediaType(mediaType string) bool {
+	if strings.HasPrefix(mediaType, "text/") {
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/xml", "application/xhtml+xml",
+		"application/javascript", "application/rss+xml", "application/atom+xml":
+		return true
+	}
+	return strings.HasSuffix(mediaType, "+json") || strings.HasSuffix(mediaType, "+xml")
 }
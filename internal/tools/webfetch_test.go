@@ -0,0 +1,176 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// fetch runs the WebFetch tool against rawURL and decodes its result.
+func fetch(t *testing.T, tool *WebFetchTool, rawURL string) map[string]interface{} {
+	t.Helper()
+	input, _ := json.Marshal(WebFetchInput{URL: rawURL, Prompt: "summarize"})
+	result, err := tool.Execute(context.Background(), input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal([]byte(result), &out); err != nil {
+		t.Fatalf("expected JSON result, got %q", result)
+	}
+	return out
+}
+
+func TestWebFetch_HTMLToMarkdown(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, `<html><head><title>T</title><script>var x = 1;</script></head><body>
+<nav><a href="/home">Home</a></nav>
+<h1>Guide</h1>
+<p>Read the <a href="/docs">docs</a> &amp; <strong>enjoy</strong>.</p>
+<ul><li>one</li><li>two</li></ul>
+<pre><code class="language-go">func main() {}</code></pre>
+</body></html>`)
+	}))
+	defer srv.Close()
+
+	out := fetch(t, NewWebFetchTool(srv.Client()), srv.URL+"/page")
+	got := out["result"].(string)
+	for _, want := range []string{
+		"# Guide",
+		"Read the [docs](" + srv.URL + "/docs) & **enjoy**.",
+		"- one\n- two",
+		"```go\nfunc main() {}\n```",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "var x") || strings.Contains(got, "Home") {
+		t.Errorf("expected script and nav to be dropped:\n%s", got)
+	}
+}
+
+func TestWebFetch_UpgradesToHTTPSAndCaches(t *testing.T) {
+	hits := 0
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprint(w, "hello")
+	}))
+	defer srv.Close()
+
+	tool := NewWebFetchTool(srv.Client())
+	plain := "http://" + strings.TrimPrefix(srv.URL, "https://")
+	for i := 0; i < 2; i++ {
+		out := fetch(t, tool, plain)
+		if out["result"] != "hello" || out["url"] != srv.URL {
+			t.Fatalf("unexpected result: %v", out)
+		}
+	}
+	if hits != 1 {
+		t.Errorf("expected second fetch to be served from cache, got %d requests", hits)
+	}
+}
+
+func TestWebFetch_CrossHostRedirect(t *testing.T) {
+	other := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("cross-host redirect should not be followed")
+	}))
+	defer other.Close()
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/old":
+			http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+		case "/new":
+			fmt.Fprint(w, "moved here")
+		case "/away":
+			http.Redirect(w, r, other.URL+"/elsewhere", http.StatusFound)
+		}
+	}))
+	defer srv.Close()
+
+	tool := NewWebFetchTool(srv.Client())
+	if out := fetch(t, tool, srv.URL+"/old"); out["result"] != "moved here" {
+		t.Errorf("expected same-host redirect to be followed, got %v", out)
+	}
+
+	out := fetch(t, tool, srv.URL+"/away")
+	got := out["result"].(string)
+	if !strings.Contains(got, "REDIRECT DETECTED") || !strings.Contains(got, other.URL+"/elsewhere") {
+		t.Errorf("expected redirect notice, got:\n%s", got)
+	}
+	if out["code"] != float64(http.StatusFound) {
+		t.Errorf("expected status 302, got %v", out["code"])
+	}
+}
+
+func TestWebFetch_RejectsBinary(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte{0x89, 'P', 'N', 'G'})
+	}))
+	defer srv.Close()
+
+	input, _ := json.Marshal(WebFetchInput{URL: srv.URL, Prompt: "describe"})
+	result, _ := NewWebFetchTool(srv.Client()).Execute(context.Background(), input)
+	if !strings.Contains(result, "unsupported content type image/png") {
+		t.Errorf("expected content type error, got %q", result)
+	}
+}
+
+func TestWebFetch_TruncatesLargeContent(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprint(w, strings.Repeat("x", webFetchMaxContent+10))
+	}))
+	defer srv.Close()
+
+	got := fetch(t, NewWebFetchTool(srv.Client()), srv.URL)["result"].(string)
+	if !strings.HasSuffix(got, fmt.Sprintf("(content truncated to %d of %d characters)", webFetchMaxContent, webFetchMaxContent+10)) {
+		t.Errorf("expected truncation note, got suffix %q", got[len(got)-80:])
+	}
+}
+
+func TestWebFetch_TruncatesByCharacters(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprint(w, strings.Repeat("é", webFetchMaxContent+10))
+	}))
+	defer srv.Close()
+
+	got := fetch(t, NewWebFetchTool(srv.Client()), srv.URL)["result"].(string)
+	note := fmt.Sprintf("\n\n... (content truncated to %d of %d characters)", webFetchMaxContent, webFetchMaxContent+10)
+	if !strings.HasSuffix(got, note) {
+		t.Fatalf("expected truncation note, got suffix %q", got[len(got)-80:])
+	}
+	if kept := strings.TrimSuffix(got, note); kept != strings.Repeat("é", webFetchMaxContent) {
+		t.Errorf("kept %d characters (valid UTF-8: %v), want %d", utf8.RuneCountInString(kept), utf8.ValidString(kept), webFetchMaxContent)
+	}
+}
+
+func TestHTMLToMarkdown(t *testing.T) {
+	base, _ := url.Parse("https://example.com/a/b.html")
+	src := `<html><head><title>Page Title</title></head><body>
+<p>Line one<br>line <em>two</em></p>
+<blockquote><p>quoted</p></blockquote>
+<ol><li>first<ul><li>nested</li></ul></li><li>second</li></ol>
+<table><tr><th>Name</th><th>Value</th></tr><tr><td>a|b</td><td><code>1</code></td></tr></table>
+<img src="pic.png" alt="A picture">
+</body></html>`
+	want := "# Page Title\n\n" +
+		"Line one\nline *two*\n\n" +
+		"> quoted\n\n" +
+		"1. first\n   - nested\n2. second\n\n" +
+		"| Name | Value |\n| --- | --- |\n| a\\|b | `1` |\n\n" +
+		"![A picture](https://example.com/a/pic.png)"
+	if got := htmlToMarkdown(src, base); got != want {
+		t.Errorf("got:\n%s\n\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,100 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/anthropics/claude-code-go/internal/conversation"
+)
+
+// agentProgressTextLines is how many lines of each sub-agent's latest text
+// are shown when detail is expanded.
+const agentProgressTextLines = 3
+
+// agentProgressEntry is the latest progress of one running Agent call.
+type agentProgressEntry struct {
+	id string // tool_use ID of the Agent call
+	p  conversation.AgentProgress
+}
+
+// updateAgentProgress records a progress report, dropping the entry once
+// its sub-agent is done. Entries keep the order the agents started in.
+func (m *model) updateAgentProgress(id string, p conversation.AgentProgress) {
+	for i, e := range m.agentProgress {
+		if e.id != id {
+			continue
+		}
+		if p.Done {
+			m.agentProgress = append(m.agentProgress[:i:i], m.agentProgress[i+1:]...)
+		} else {
+			m.agentProgress[i].p = p
+		}
+		return
+	}
+	if !p.Done {
+		m.agentProgress = append(m.agentProgress, agentProgressEntry{id: id, p: p})
+	}
+}
+
+// agentDoneLine summarizes a finished sub-agent run: its tool calls,
+// tokens, cost, and duration.
+func agentDoneLine(p conversation.AgentProgress) string {
+	name := p.Description
+	if name == "" {
+		name = p.AgentID
+	}
+	uses := "tool uses"
+	if p.ToolUses == 1 {
+		uses = "tool use"
+	}
+	stats := fmt.Sprintf("%d %s · %s tokens", p.ToolUses, uses, formatTokenCount(p.Tokens))
+	if p.CostUSD > 0 {
+		stats += fmt.Sprintf(" · $%.4f", p.CostUSD)
+	}
+	stats += fmt.Sprintf(" · %.1fs", float64(p.DurationMs)/1000)
+	return fmt.Sprintf("  ⎿ %s · Done (%s)", name, stats)
+}
+
+// renderAgentProgress renders running sub-agents as nested lines under an
+// Agent spinner: one line each with the turn count and current tool, plus
+// the tail of their text when detail is expanded with ctrl+o.
+func (m model) renderAgentProgress() string {
+	var b strings.Builder
+	b.WriteString(m.spinner.View() + " " + toolNameStyle.Render("Agent"))
+	label := "Running 1 agent"
+	if n := len(m.agentProgress); n > 1 {
+		label = fmt.Sprintf("Running %d agents", n)
+	}
+	hint := "ctrl+o to expand"
+	if m.agentDetail {
+		hint = "ctrl+o to collapse"
+	}
+	b.WriteString("  " + toolSummaryStyle.Render(label) + "  " + permHintStyle.Render("("+hint+")") + "\n")
+
+	for _, e := range m.agentProgress {
+		line := e.p.Description
+		if line == "" {
+			line = e.p.AgentID
+		}
+		line += fmt.Sprintf(" · turn %d", e.p.Turns)
+		if e.p.Tool != "" {
+			line += " · " + e.p.Tool
+			if summary := extractToolSummary(e.p.Tool, e.p.ToolInput); summary != "" {
+				line += "  " + summary
+			}
+		}
+		line = "  ⎿ " + line
+		if m.width > 4 {
+			line = ansi.Truncate(line, m.width-1, "…")
+		}
+		b.WriteString(toolSummaryStyle.Render(line) + "\n")
+		if m.agentDetail {
+			if out := renderToolOutput(e.p.Text, agentProgressTextLines, m.width); out != "" {
+				b.WriteString(out + "\n")
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,66 @@
+package tui
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/anthropics/claude-code-go/internal/conversation"
+)
+
+func TestAgentProgress(t *testing.T) {
+	m, _ := testModel(t)
+	m.mode = modeStreaming
+
+	send := func(id string, p conversation.AgentProgress) {
+		result, _ := m.Update(AgentProgressMsg{ID: id, Progress: p})
+		m = result.(model)
+	}
+	send("toolu_1", conversation.AgentProgress{AgentID: "agent-1", Description: "Find TODOs", Turns: 2,
+		Tool: "Grep", ToolInput: json.RawMessage(`{"pattern":"TODO"}`), Text: "Looking\nin internal/"})
+	send("toolu_2", conversation.AgentProgress{AgentID: "agent-2", Description: "Review tests", Turns: 1})
+
+	view := m.View()
+	for _, want := range []string{"Running 2 agents", "Find TODOs · turn 2 · Grep", "Review tests · turn 1", "ctrl+o to expand"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q:\n%s", want, view)
+		}
+	}
+	if strings.Contains(view, "in internal/") {
+		t.Errorf("collapsed view should not show agent text:\n%s", view)
+	}
+
+	result, _ := m.handleKey(tea.KeyMsg{Type: tea.KeyCtrlO})
+	m = result.(model)
+	if view := m.View(); !strings.Contains(view, "in internal/") || !strings.Contains(view, "ctrl+o to collapse") {
+		t.Errorf("expanded view should show agent text:\n%s", view)
+	}
+
+	send("toolu_1", conversation.AgentProgress{AgentID: "agent-1", Description: "Find TODOs", Done: true,
+		ToolUses: 3, Tokens: 12_300, CostUSD: 0.0123, DurationMs: 4200})
+	if len(m.agentProgress) != 1 || m.agentProgress[0].id != "toolu_2" {
+		t.Fatalf("progress after done = %+v", m.agentProgress)
+	}
+	if view := m.View(); !strings.Contains(view, "Running 1 agent") || strings.Contains(view, "Find TODOs") {
+		t.Errorf("view after done:\n%s", view)
+	}
+
+	result, _ = m.Update(MessageStartMsg{})
+	if m = result.(model); m.agentProgress != nil {
+		t.Errorf("progress should clear when the next response starts, got %+v", m.agentProgress)
+	}
+}
+
+func TestAgentDoneLine(t *testing.T) {
+	got := agentDoneLine(conversation.AgentProgress{AgentID: "agent-1", Description: "Find TODOs", Done: true,
+		ToolUses: 3, Tokens: 12_300, CostUSD: 0.0123, DurationMs: 4200})
+	if want := "  ⎿ Find TODOs · Done (3 tool uses · 12.3k tokens · $0.0123 · 4.2s)"; got != want {
+		t.Errorf("agentDoneLine = %q, want %q", got, want)
+	}
+	got = agentDoneLine(conversation.AgentProgress{AgentID: "agent-2", Done: true, ToolUses: 1, Tokens: 40})
+	if want := "  ⎿ agent-2 · Done (1 tool use · 40 tokens · 0.0s)"; got != want {
+		t.Errorf("agentDoneLine = %q, want %q", got, want)
+	}
+}
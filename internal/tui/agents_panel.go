@@ -0,0 +1,370 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/anthropics/claude-code-go/internal/agents"
+)
+
+// agentsStep is a screen of the /agents manager.
+type agentsStep int
+
+const (
+	agentsStepList          agentsStep = iota // existing agents plus "Create new agent"
+	agentsStepActions                         // edit or delete the selected agent
+	agentsStepConfirmDelete                   // y/n before deleting
+	agentsStepLocation                        // project or user directory
+	agentsStepName                            // typing the name
+	agentsStepDescription                     // typing the description
+	agentsStepTools                           // checking tools
+	agentsStepModel                           // choosing a model
+)
+
+var (
+	agentsActions   = []string{"Edit in editor", "Delete", "Back"}
+	agentsLocations = []string{"Project (.claude/agents/)", "User (~/.claude/agents/)"}
+)
+
+// agentsPanel holds the state of the /agents manager.
+type agentsPanel struct {
+	cwd        string
+	knownTools []string // tools offered when creating an agent
+	agents     []agents.Agent
+
+	step     agentsStep
+	cursor   int
+	selected int // index into agents for the actions screen
+
+	// Create flow.
+	draft  agents.Agent
+	user   bool
+	input  string          // text typed on the name and description steps
+	picked map[string]bool // checked tools; "" means all tools
+	errMsg string
+}
+
+// newAgentsPanel creates the manager listing the agents defined for cwd.
+func newAgentsPanel(cwd string, knownTools []string) *agentsPanel {
+	return &agentsPanel{cwd: cwd, knownTools: knownTools, agents: agents.LoadAgents(cwd)}
+}
+
+// AgentEditDoneMsg is sent when the editor opened from /agents exits.
+type AgentEditDoneMsg struct {
+	Path string
+	Err  error
+}
+
+// handleAgentsKey processes key events in the /agents manager.
+func (m model) handleAgentsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := m.agentsPanel
+	if p == nil {
+		m.mode = modeInput
+		return m, nil
+	}
+	if msg.Type == tea.KeyCtrlC {
+		return m.closeAgentsPanel("Agents dialog dismissed")
+	}
+
+	switch p.step {
+	case agentsStepName, agentsStepDescription:
+		return m.handleAgentsTextKey(msg)
+	case agentsStepConfirmDelete:
+		switch msg.String() {
+		case "y", "Y":
+			a := p.agents[p.selected]
+			if err := os.Remove(a.FilePath); err != nil {
+				return m.closeAgentsPanel("Error deleting agent: " + err.Error())
+			}
+			return m.closeAgentsPanel(fmt.Sprintf("Deleted agent %s (%s)", a.Name, shortenPath(a.FilePath)))
+		case "n", "N", "esc":
+			p.step = agentsStepActions
+		}
+		return m, nil
+	}
+
+	n := p.optionCount()
+	switch msg.Type {
+	case tea.KeyUp:
+		if p.cursor > 0 {
+			p.cursor--
+		}
+	case tea.KeyDown:
+		if p.cursor < n-1 {
+			p.cursor++
+		}
+	case tea.KeySpace:
+		if p.step == agentsStepTools {
+			p.toggleTool()
+		}
+	case tea.KeyEsc:
+		return m.agentsBack()
+	case tea.KeyEnter:
+		return m.agentsSelect()
+	}
+	return m, nil
+}
+
+// handleAgentsTextKey edits the name or description being typed.
+func (m model) handleAgentsTextKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := m.agentsPanel
+	switch msg.Type {
+	case tea.KeyEsc:
+		return m.agentsBack()
+	case tea.KeyBackspace:
+		if r := []rune(p.input); len(r) > 0 {
+			p.input = string(r[:len(r)-1])
+		}
+	case tea.KeySpace:
+		p.input += " "
+	case tea.KeyRunes:
+		p.input += string(msg.Runes)
+	case tea.KeyEnter:
+		value := strings.TrimSpace(p.input)
+		if p.step == agentsStepName {
+			p.draft.Name = value
+			if err := agents.Validate(agents.Agent{Name: value, Description: "-", SystemPrompt: "-"}, nil); err != nil {
+				p.errMsg = err.Error()
+				return m, nil
+			}
+			for _, a := range p.agents {
+				if a.Name == value {
+					p.errMsg = fmt.Sprintf("An agent named %s already exists (%s)", value, shortenPath(a.FilePath))
+					return m, nil
+				}
+			}
+			p.step, p.input, p.errMsg = agentsStepDescription, p.draft.Description, ""
+			return m, nil
+		}
+		if value == "" {
+			p.errMsg = "Describe when the agent should be used"
+			return m, nil
+		}
+		p.draft.Description = value
+		p.step, p.cursor, p.errMsg = agentsStepTools, 0, ""
+		if p.picked == nil {
+			p.picked = map[string]bool{"": true}
+		}
+	}
+	return m, nil
+}
+
+// optionCount returns the number of choices on the current list screen.
+func (p *agentsPanel) optionCount() int {
+	switch p.step {
+	case agentsStepList:
+		return len(p.agents) + 1
+	case agentsStepActions:
+		return len(agentsActions)
+	case agentsStepLocation:
+		return len(agentsLocations)
+	case agentsStepTools:
+		return len(p.knownTools) + 1
+	case agentsStepModel:
+		return len(agents.Models)
+	}
+	return 0
+}
+
+// toggleTool checks or unchecks the tool under the cursor. Row 0 is
+// "All tools", which is exclusive with individual tools.
+func (p *agentsPanel) toggleTool() {
+	if p.cursor == 0 {
+		p.picked = map[string]bool{"": !p.picked[""]}
+		return
+	}
+	name := p.knownTools[p.cursor-1]
+	p.picked[name] = !p.picked[name]
+	delete(p.picked, "")
+}
+
+// agentsSelect handles Enter on a list screen.
+func (m model) agentsSelect() (tea.Model, tea.Cmd) {
+	p := m.agentsPanel
+	switch p.step {
+	case agentsStepList:
+		if p.cursor == len(p.agents) {
+			p.step, p.cursor = agentsStepLocation, 0
+			p.draft, p.input, p.picked = agents.Agent{}, "", nil
+			return m, nil
+		}
+		p.selected = p.cursor
+		p.step, p.cursor = agentsStepActions, 0
+
+	case agentsStepActions:
+		switch agentsActions[p.cursor] {
+		case "Edit in editor":
+			path := p.agents[p.selected].FilePath
+			editorCmd, err := editorCommand(path)
+			if err != nil {
+				return m.closeAgentsPanel("Error: " + err.Error())
+			}
+			m.agentsPanel = nil
+			m.mode = modeInput
+			m.textInput.Focus()
+			return m, tea.Batch(tea.ExecProcess(editorCmd, func(err error) tea.Msg {
+				return AgentEditDoneMsg{Path: path, Err: err}
+			}), textarea.Blink)
+		case "Delete":
+			p.step = agentsStepConfirmDelete
+		default:
+			p.step, p.cursor = agentsStepList, p.selected
+		}
+
+	case agentsStepLocation:
+		p.user = p.cursor == 1
+		p.step, p.input, p.errMsg = agentsStepName, p.draft.Name, ""
+
+	case agentsStepTools:
+		p.draft.Tools = nil
+		if !p.picked[""] {
+			for _, name := range p.knownTools {
+				if p.picked[name] {
+					p.draft.Tools = append(p.draft.Tools, name)
+				}
+			}
+			if len(p.draft.Tools) == 0 {
+				p.errMsg = "Select at least one tool, or All tools"
+				return m, nil
+			}
+		}
+		p.step, p.cursor, p.errMsg = agentsStepModel, 0, ""
+
+	case agentsStepModel:
+		p.draft.Model = agents.Models[p.cursor]
+		dir, err := agents.Dir(p.cwd, p.user)
+		if err == nil {
+			var path string
+			if path, err = agents.Create(dir, p.draft, p.knownTools); err == nil {
+				return m.closeAgentsPanel(fmt.Sprintf("Created agent %s at %s\nEdit its system prompt with /agents. New agents are available in new sessions.", p.draft.Name, shortenPath(path)))
+			}
+		}
+		p.errMsg = err.Error()
+	}
+	return m, nil
+}
+
+// agentsBack returns to the previous screen, closing the manager from the
+// first one.
+func (m model) agentsBack() (tea.Model, tea.Cmd) {
+	p := m.agentsPanel
+	p.errMsg = ""
+	switch p.step {
+	case agentsStepList:
+		return m.closeAgentsPanel("Agents dialog dismissed")
+	case agentsStepActions, agentsStepLocation:
+		p.step, p.cursor = agentsStepList, 0
+	case agentsStepName:
+		p.step, p.cursor = agentsStepLocation, 0
+	case agentsStepDescription:
+		p.step, p.input = agentsStepName, p.draft.Name
+	case agentsStepTools:
+		p.step, p.input = agentsStepDescription, p.draft.Description
+	case agentsStepModel:
+		p.step, p.cursor = agentsStepTools, 0
+	}
+	return m, nil
+}
+
+// closeAgentsPanel leaves the manager and prints msg.
+func (m model) closeAgentsPanel(msg string) (tea.Model, tea.Cmd) {
+	m.agentsPanel = nil
+	m.mode = modeInput
+	m.textInput.Focus()
+	return m, tea.Batch(tea.Println(msg), textarea.Blink)
+}
+
+// agentEditResult validates an agent file after it was edited.
+func agentEditResult(msg AgentEditDoneMsg, knownTools []string) string {
+	if msg.Err != nil {
+		return "Editor exited with error: " + msg.Err.Error()
+	}
+	a, err := agents.ValidateFile(msg.Path, knownTools)
+	if err != nil {
+		return fmt.Sprintf("Agent file %s has problems:\n  %s", shortenPath(msg.Path), strings.ReplaceAll(err.Error(), "\n", "\n  "))
+	}
+	return fmt.Sprintf("Saved agent %s. Changes apply in new sessions.", a.Name)
+}
+
+// renderAgentsPanel renders the /agents manager for the live region.
+func (m model) renderAgentsPanel() string {
+	p := m.agentsPanel
+	if p == nil {
+		return ""
+	}
+	var b strings.Builder
+	option := func(i int, label, detail string) {
+		if i == p.cursor {
+			b.WriteString(askSelectedStyle.Render("  > "+label) + " " + askOptionStyle.Render(detail) + "\n")
+		} else {
+			b.WriteString(askOptionStyle.Render(strings.TrimRight("    "+label+" "+detail, " ")) + "\n")
+		}
+	}
+	header := func(question string) {
+		b.WriteString(askHeaderStyle.Render("[Agents]") + " " + askQuestionStyle.Render(question) + "\n")
+	}
+	hint := "  Use arrow keys to navigate, Enter to select, Esc to go back"
+
+	switch p.step {
+	case agentsStepList:
+		header("Custom agents")
+		for i, a := range p.agents {
+			option(i, a.Name, fmt.Sprintf("(%s) %s", a.Source, a.Description))
+		}
+		option(len(p.agents), "Create new agent", "")
+	case agentsStepActions:
+		a := p.agents[p.selected]
+		header(fmt.Sprintf("%s (%s)", a.Name, shortenPath(a.FilePath)))
+		for i, action := range agentsActions {
+			option(i, action, "")
+		}
+	case agentsStepConfirmDelete:
+		header(fmt.Sprintf("Delete agent %s? (y/n)", p.agents[p.selected].Name))
+		hint = ""
+	case agentsStepLocation:
+		header("Where should the agent be saved?")
+		for i, loc := range agentsLocations {
+			option(i, loc, "")
+		}
+	case agentsStepName, agentsStepDescription:
+		if p.step == agentsStepName {
+			header("Agent name (lowercase letters, digits, and hyphens):")
+		} else {
+			header("When should the agent be used?")
+		}
+		b.WriteString("  " + p.input + "_\n")
+		hint = "  Enter to continue, Esc to go back"
+	case agentsStepTools:
+		header("Which tools may the agent use?")
+		check := func(on bool) string {
+			if on {
+				return "[x] "
+			}
+			return "[ ] "
+		}
+		option(0, check(p.picked[""])+"All tools", "")
+		for i, name := range p.knownTools {
+			option(i+1, check(p.picked[name])+name, "")
+		}
+		hint = "  Space to toggle, Enter to continue, Esc to go back"
+	case agentsStepModel:
+		header("Which model should the agent use?")
+		for i, model := range agents.Models {
+			detail := ""
+			if model == "inherit" {
+				detail = "same as the main conversation"
+			}
+			option(i, model, detail)
+		}
+	}
+
+	if p.errMsg != "" {
+		b.WriteString(errorStyle.Render("  "+p.errMsg) + "\n")
+	}
+	b.WriteString(permHintStyle.Render(hint))
+	return b.String()
+}
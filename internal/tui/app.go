@@ -12,6 +12,7 @@ import (
 	"github.com/anthropics/claude-code-go/internal/conversation"
 	"github.com/anthropics/claude-code-go/internal/session"
 	"github.com/anthropics/claude-code-go/internal/skills"
+	"github.com/anthropics/claude-code-go/internal/tools"
 )
 
 // MCPStatus provides MCP server information to the TUI without importing
@@ -19,14 +20,17 @@ import (
 type MCPStatus interface {
 	Servers() []string
 	ServerStatus(name string) string
+	Logs(name string, n int) (lines []string, path string)
 }
 
 // ExitAction indicates what the caller should do after the TUI exits.
 type ExitAction int
 
 const (
-	ExitNone  ExitAction = iota
-	ExitLogin            // The user requested /login; caller should run the login flow.
+	ExitNone   ExitAction = iota
+	ExitLogin             // The user requested /login; caller should run the login flow.
+	ExitResume            // The user picked another project's session in /resume; see ResumeSession.
+	ExitLogout            // The user ran /logout.
 )
 
 // AppConfig bundles everything the TUI needs from main.go.
@@ -42,12 +46,29 @@ type AppConfig struct {
 	MCPManager    MCPStatus                          // *mcp.Manager; nil if no MCP servers configured
 	Skills        []skills.Skill                     // Phase 7: loaded skills for slash command registration
 	Hooks         conversation.HookRunner            // Phase 7: hook runner for SessionStart, etc.
+	NoHooks       bool                               // --no-hooks: /hooks saves changes without applying them
 	Settings      *config.Settings                   // live settings for config panel
 	RuleHandler   *config.RuleBasedPermissionHandler // Rule-based permission handler from main; may be nil
 	OnModelSwitch func(newModel string)              // called when user switches model via /model
 	LogoutFunc    func() error                       // Called when the user types /logout to clear credentials.
 	FastMode      bool                               // initial fast mode state from settings
 	Client        *api.Client                        // API client for model switching
+	ShellCwd      func() string                      // current Bash tool directory; may be nil
+	UndoStore     *tools.UndoStore                   // file modifications for /undo; may be nil
+	TodoTool      *tools.TodoWriteTool               // todo list shown in the live region and by /todos; may be nil
+	AskUser       *tools.AskUserTool                 // prompts in the TUI once wired to the program; may be nil
+	AgentTools    []string                           // tool names offered by the /agents manager
+	Tasks         *tools.BackgroundTaskStore         // background tasks listed by /tasks; may be nil
+	AgentTool     *tools.AgentTool                   // sub-agent costs shown by /cost; may be nil
+	MCPPrompts    []MCPPrompt                        // MCP prompts registered as slash commands
+	GetMCPPrompt  MCPPromptFunc                      // resolves an MCP prompt; nil if no MCP servers
+	WatchPrompts  func(update func([]MCPPrompt))     // registers for MCP prompt list changes; may be nil
+	ListResources MCPResourcesFunc                   // lists MCP resources for @-mention completion; may be nil
+	ReadResource  MCPReadResourceFunc                // reads @server:uri mentions; nil if no MCP servers
+	WatchUpdates  func(notify func(target string))   // registers for MCP subscription changes; may be nil
+	WatchHooks    func(notify func(line string))     // registers for hook runs, with --verbose; may be nil
+	OnAddDir      func(dir string)                   // called after /add-dir adds a directory; may be nil
+	StartMCP      func()                             // starts MCP servers in the background once the TUI is wired; may be nil
 }
 
 // App is the top-level TUI application. main.go creates it and calls Run.
@@ -55,6 +76,7 @@ type App struct {
 	cfg           AppConfig
 	initialPrompt string
 	exitAction    ExitAction
+	resumeSession *session.Session
 }
 
 // ExitAction returns the action the caller should take after Run() returns.
@@ -62,6 +84,12 @@ func (a *App) ExitAction() ExitAction {
 	return a.exitAction
 }
 
+// ResumeSession returns the session to resume in its own directory when
+// ExitAction is ExitResume.
+func (a *App) ResumeSession() *session.Session {
+	return a.resumeSession
+}
+
 // New creates a new TUI application.
 func New(cfg AppConfig) *App {
 	return &App{cfg: cfg}
@@ -87,7 +115,11 @@ func (a *App) Run(ctx context.Context) error {
 
 	// Phase 7: Fire SessionStart hook before UI starts.
 	if a.cfg.Hooks != nil {
-		_ = a.cfg.Hooks.RunSessionStart(loopCtx)
+		source := "startup"
+		if a.cfg.Session != nil && len(a.cfg.Session.Messages) > 0 {
+			source = "resume"
+		}
+		_ = a.cfg.Hooks.RunSessionStart(loopCtx, source)
 	}
 
 	// Create the Bubble Tea model.
@@ -107,12 +139,47 @@ func (a *App) Run(ctx context.Context) error {
 		OnModelSwitch: a.cfg.OnModelSwitch,
 		LogoutFunc:    a.cfg.LogoutFunc,
 		FastMode:      a.cfg.FastMode,
+		Cwd:           a.cfg.Cwd,
+		ShellCwd:      a.cfg.ShellCwd,
+		UndoStore:     a.cfg.UndoStore,
+		TodoTool:      a.cfg.TodoTool,
+		AgentTools:    a.cfg.AgentTools,
+		Tasks:         a.cfg.Tasks,
+		AgentTool:     a.cfg.AgentTool,
+		MCPPrompts:    a.cfg.MCPPrompts,
+		GetMCPPrompt:  a.cfg.GetMCPPrompt,
+		ListResources: a.cfg.ListResources,
+		ReadResource:  a.cfg.ReadResource,
+		OnAddDir:      a.cfg.OnAddDir,
+		Hooks:         a.cfg.Hooks,
+		NoHooks:       a.cfg.NoHooks,
 	})
 	m.apiClient = a.cfg.Client
 
 	// Create the BT program (inline mode, no alt screen).
 	// Bracketed paste is enabled by default in bubbletea v1.x.
 	p := tea.NewProgram(m)
+	if a.cfg.TodoTool != nil {
+		a.cfg.TodoTool.SetProgram(p)
+	}
+	if a.cfg.AskUser != nil {
+		a.cfg.AskUser.SetProgram(p)
+	}
+	if a.cfg.WatchPrompts != nil {
+		a.cfg.WatchPrompts(func(prompts []MCPPrompt) {
+			p.Send(mcpPromptsMsg{prompts: prompts})
+		})
+	}
+	if a.cfg.WatchUpdates != nil {
+		a.cfg.WatchUpdates(func(target string) {
+			p.Send(mcpUpdateMsg{target: target})
+		})
+	}
+	if a.cfg.WatchHooks != nil {
+		a.cfg.WatchHooks(func(line string) {
+			p.Send(HookTraceMsg{Line: line})
+		})
+	}
 
 	// Wire the TUI stream handler into the loop.
 	handler := NewTUIStreamHandler(p)
@@ -154,6 +221,12 @@ func (a *App) Run(ctx context.Context) error {
 	fmt.Printf("%s  ▘▘ ▝▝%s    %s\n", oFg, rst, line3)
 	fmt.Println()
 
+	// Start MCP servers now that their tools and prompts have somewhere
+	// to go, rather than holding up the prompt until they're all up.
+	if a.cfg.StartMCP != nil {
+		a.cfg.StartMCP()
+	}
+
 	// Run the BT event loop (blocks until quit).
 	finalModel, err := p.Run()
 
@@ -162,6 +235,18 @@ func (a *App) Run(ctx context.Context) error {
 	// Check if the user requested a special exit action (e.g., /login).
 	if fm, ok := finalModel.(model); ok {
 		a.exitAction = fm.exitAction
+		a.resumeSession = fm.resumeTarget
+	}
+
+	if a.cfg.Hooks != nil {
+		reason := "other"
+		switch {
+		case a.exitAction == ExitLogout:
+			reason = "logout"
+		case a.exitAction == ExitNone && err == nil:
+			reason = "prompt_input_exit"
+		}
+		_ = a.cfg.Hooks.RunSessionEnd(context.Background(), reason)
 	}
 
 	return err
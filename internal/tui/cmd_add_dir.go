@@ -0,0 +1,60 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// registerAddDirCommand registers /add-dir.
+func registerAddDirCommand(r *slashRegistry) {
+	r.register(SlashCommand{
+		Name:        "add-dir",
+		Description: "Add a working directory for this session",
+		Execute:     executeAddDir,
+	})
+}
+
+func executeAddDir(m *model, args string) (tea.Model, tea.Cmd) {
+	dir := strings.TrimSpace(args)
+	if dir == "" {
+		return *m, tea.Println("Usage: /add-dir <path>")
+	}
+	if rest, ok := strings.CutPrefix(dir, "~"); ok && (rest == "" || rest[0] == '/') {
+		if home, err := os.UserHomeDir(); err == nil {
+			dir = home + rest
+		}
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(m.cwd, dir)
+	}
+	dir = filepath.Clean(dir)
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		return *m, tea.Println(fmt.Sprintf("Cannot add %s: %v", dir, err))
+	}
+	if !info.IsDir() {
+		return *m, tea.Println(fmt.Sprintf("Cannot add %s: not a directory", dir))
+	}
+
+	if m.loop != nil {
+		if permCtx := m.loop.GetPermissionContext(); permCtx != nil {
+			permCtx.AddWorkingDirectory(dir, "session")
+		}
+	}
+	out := tea.Println(fmt.Sprintf("Added %s as a working directory.", dir))
+	if m.onAddDir == nil {
+		return *m, out
+	}
+	// Tell MCP servers about the new root in the background; the
+	// notifications are best-effort.
+	onAddDir := m.onAddDir
+	return *m, tea.Batch(out, func() tea.Msg {
+		onAddDir(dir)
+		return nil
+	})
+}
@@ -0,0 +1,27 @@
+package tui
+
+import (
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// registerAgentsCommand registers /agents.
+func registerAgentsCommand(r *slashRegistry) {
+	r.register(SlashCommand{
+		Name:        "agents",
+		Description: "Manage custom agents",
+		Execute:     executeAgents,
+	})
+}
+
+func executeAgents(m *model, args string) (tea.Model, tea.Cmd) {
+	cwd := m.cwd
+	if cwd == "" {
+		cwd, _ = os.Getwd()
+	}
+	m.agentsPanel = newAgentsPanel(cwd, m.agentTools)
+	m.mode = modeAgents
+	m.textInput.Blur()
+	return *m, nil
+}
@@ -0,0 +1,54 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/anthropics/claude-code-go/internal/session"
+)
+
+// registerChangesCommand registers /changes.
+func registerChangesCommand(r *slashRegistry) {
+	r.register(SlashCommand{
+		Name:        "changes",
+		Description: "Show the cumulative diff of files changed in this session",
+		Execute:     textCommand(changesText),
+	})
+}
+
+func changesText(m *model) string {
+	if m.sessStore == nil || m.session == nil {
+		return "Changes are not available in this session."
+	}
+	records, err := m.sessStore.Changes(m.session.ID)
+	if err != nil {
+		return errorStyle.Render("Cannot read changes: " + err.Error())
+	}
+	if len(records) == 0 {
+		return "No files changed in this session."
+	}
+
+	diffs := session.CumulativeDiff(records, m.session.CWD)
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Files changed in this session (%s):\n", pluralize(len(diffs), "file", "files")))
+	for _, d := range diffs {
+		b.WriteString("\n" + diffFileHeaderStyle.Render(shortenPath(d.Path)) + " " +
+			diffDimStyle.Render("("+d.Summary()+")") + "\n")
+		hunks := false // past the ---/+++ header, which the file line replaces
+		for _, line := range strings.Split(strings.TrimSuffix(d.Diff, "\n"), "\n") {
+			switch {
+			case strings.HasPrefix(line, "@@"):
+				hunks = true
+				b.WriteString(diffHunkHeaderStyle.Render(line) + "\n")
+			case !hunks:
+			case strings.HasPrefix(line, "+"):
+				b.WriteString(diffAddStyle.Render(line) + "\n")
+			case strings.HasPrefix(line, "-"):
+				b.WriteString(diffRemoveStyle.Render(line) + "\n")
+			default:
+				b.WriteString(line + "\n")
+			}
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
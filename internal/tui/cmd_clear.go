@@ -3,8 +3,10 @@ package tui
 import (
 	tea "github.com/charmbracelet/bubbletea"
 
+	"github.com/anthropics/claude-code-go/internal/api"
 	"github.com/anthropics/claude-code-go/internal/conversation"
 	"github.com/anthropics/claude-code-go/internal/session"
+	"github.com/anthropics/claude-code-go/internal/tools"
 )
 
 // registerClearCommand registers /clear and its aliases /reset, /new.
@@ -33,6 +35,11 @@ func registerClearCommand(r *slashRegistry) {
 func executeClear(m *model, args string) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 
+	// The old session ends here, and the new one starts below.
+	if m.hooks != nil {
+		_ = m.hooks.RunSessionEnd(m.ctx, "clear")
+	}
+
 	// Clear conversation history.
 	m.loop.Clear()
 
@@ -41,27 +48,54 @@ func executeClear(m *model, args string) (tea.Model, tea.Cmd) {
 
 	// Clear todo list.
 	m.todos = nil
+	if m.todoTool != nil {
+		m.todoTool.SetTodos(nil)
+	}
+	setAgentCosts(m, nil)
 
 	// Clear any queued messages.
 	m.queue.Clear()
 
 	// Create a new session, preserving the model and CWD.
 	if m.session != nil {
+		newID := session.GenerateID()
+		claimSession(m, newID) // a new ID is never held elsewhere
 		m.session = &session.Session{
-			ID:    session.GenerateID(),
+			ID:    newID,
 			Model: m.session.Model,
 			CWD:   m.session.CWD,
 		}
 
-		// Update the turn-complete callback to reference the new session.
+		// Update the save callbacks to reference the new session.
 		newSess := m.session
 		store := m.sessStore
-		m.loop.SetOnTurnComplete(func(h *conversation.History) {
+		todoTool := m.todoTool
+		agentTool := m.agentTool
+		save := func(h *conversation.History) {
 			if store != nil && newSess != nil {
 				newSess.Messages = h.Messages()
+				newSess.Turns = h.Turns()
+				if todoTool != nil {
+					newSess.Todos = todoTool.Todos()
+				}
+				if agentTool != nil {
+					newSess.AgentCosts = agentTool.Costs()
+				}
 				_ = store.Save(newSess)
 			}
+		}
+		m.loop.SetOnTurnComplete(save)
+		m.loop.SetOnMessage(save)
+		m.loop.SetOnToolResult(func(result api.ContentBlock) {
+			if store != nil {
+				_ = store.AppendToolResult(newSess.ID, result)
+			}
 		})
+		if store != nil && m.undoStore != nil {
+			m.undoStore.OnSnapshot(func(e tools.UndoEntry) {
+				_ = store.RecordChange(newSess.ID, e)
+			})
+		}
 
 		if m.sessStore != nil {
 			if err := m.sessStore.Save(m.session); err != nil {
@@ -69,6 +103,10 @@ func executeClear(m *model, args string) (tea.Model, tea.Cmd) {
 				cmds = append(cmds, tea.Println(errLine))
 			}
 		}
+		openSessionTasks(m)
+	}
+	if m.hooks != nil {
+		_ = m.hooks.RunSessionStart(m.ctx, "clear")
 	}
 
 	cmds = append(cmds, tea.Println("Conversation cleared. Starting fresh."))
@@ -22,14 +22,24 @@ func executeContinue(m *model, args string) (tea.Model, tea.Cmd) {
 	if err != nil {
 		return *m, tea.Println(errorStyle.Render("No previous session found."))
 	}
+	if err := claimSession(m, sess.ID); err != nil {
+		return *m, tea.Println(errorStyle.Render("Cannot continue: " + err.Error() + "."))
+	}
 	// Switch to the most recent session directly.
 	m.session.ID = sess.ID
 	m.session.Model = sess.Model
 	m.session.CWD = sess.CWD
 	m.session.Messages = sess.Messages
+	m.session.Turns = sess.Turns
 	m.session.CreatedAt = sess.CreatedAt
 	m.session.UpdatedAt = sess.UpdatedAt
+	m.session.Title = sess.Title
+	m.session.Name = sess.Name
+	setTodos(m, sess.Todos)
+	setAgentCosts(m, sess.AgentCosts)
+	openSessionTasks(m)
 	m.loop.History().SetMessages(sess.Messages)
+	m.loop.History().SetTurns(sess.Turns)
 
 	summary := sessionSummary(sess)
 	line := resumeHeaderStyle.Render("Resumed session ") +
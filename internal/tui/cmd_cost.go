@@ -1,5 +1,14 @@
 package tui
 
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/anthropics/claude-code-go/internal/tools"
+)
+
 // registerCostCommand registers /cost.
 func registerCostCommand(r *slashRegistry) {
 	r.register(SlashCommand{
@@ -10,5 +19,79 @@ func registerCostCommand(r *slashRegistry) {
 }
 
 func costText(m *model) string {
-	return renderCostSummary(&m.tokens)
+	summary := renderCostSummary(&m.tokens)
+	if m.agentTool == nil {
+		return summary
+	}
+	return summary + renderAgentCosts(m.agentTool.Costs(), m.tokens.TotalCostUSD)
+}
+
+// agentCostGroup totals the runs of one kind of sub-agent.
+type agentCostGroup struct {
+	name   string
+	runs   int
+	tokens int
+	cost   float64
+}
+
+// renderAgentCosts rolls up sub-agent runs by agent type, most expensive
+// first, and adds them to the main conversation's cost.
+func renderAgentCosts(costs []tools.AgentCost, mainCost float64) string {
+	if len(costs) == 0 {
+		return ""
+	}
+	byName := make(map[string]*agentCostGroup)
+	var groups []*agentCostGroup
+	var total agentCostGroup
+	for _, c := range costs {
+		name := c.SubagentType
+		if name == "" {
+			name = "general-purpose"
+		}
+		g := byName[name]
+		if g == nil {
+			g = &agentCostGroup{name: name}
+			byName[name] = g
+			groups = append(groups, g)
+		}
+		g.runs++
+		g.tokens += c.Tokens()
+		g.cost += c.CostUSD
+		total.runs++
+		total.tokens += c.Tokens()
+		total.cost += c.CostUSD
+	}
+	slices.SortStableFunc(groups, func(a, b *agentCostGroup) int {
+		return cmp.Or(cmp.Compare(b.cost, a.cost), cmp.Compare(b.tokens, a.tokens))
+	})
+
+	width := 0
+	for _, g := range groups {
+		width = max(width, len(g.name))
+	}
+	var b strings.Builder
+	b.WriteString("\n\nSub-agents:\n")
+	for _, g := range groups {
+		runs := "runs"
+		if g.runs == 1 {
+			runs = "run"
+		}
+		fmt.Fprintf(&b, "  %-*s  %3d %-4s  %8s tokens  $%.4f\n", width, g.name, g.runs, runs, formatTokenCount(g.tokens), g.cost)
+	}
+	fmt.Fprintf(&b, "  Sub-agent total: %s tokens, $%.4f\n", formatTokenCount(total.tokens), total.cost)
+	fmt.Fprintf(&b, "Total cost with sub-agents: $%.4f", mainCost+total.cost)
+	return b.String()
+}
+
+// setAgentCosts replaces the session's sub-agent costs, as when switching
+// sessions. Cached agent results belong to the old session and are
+// dropped.
+func setAgentCosts(m *model, costs []tools.AgentCost) {
+	if m.agentTool != nil {
+		m.agentTool.SetCosts(costs)
+		m.agentTool.ClearResultCache()
+	}
+	if m.session != nil {
+		m.session.AgentCosts = costs
+	}
 }
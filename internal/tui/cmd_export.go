@@ -0,0 +1,60 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/anthropics/claude-code-go/internal/session"
+)
+
+// registerExportCommand registers /export.
+func registerExportCommand(r *slashRegistry) {
+	r.register(SlashCommand{
+		Name:        "export",
+		Description: "Export the conversation to a Markdown or HTML file",
+		Execute:     executeExport,
+	})
+}
+
+// executeExport writes the current conversation to a file. The format is
+// taken from the file extension (.html/.htm for HTML, Markdown otherwise);
+// with no argument it writes conversation-<session-id>.md in the current
+// directory.
+func executeExport(m *model, args string) (tea.Model, tea.Cmd) {
+	sess := &session.Session{}
+	if m.session != nil {
+		*sess = *m.session
+	}
+	if sess.ID == "" {
+		sess.ID = session.GenerateID()
+	}
+	sess.Messages = m.loop.History().Messages()
+	if len(sess.Messages) == 0 {
+		return *m, tea.Println("No conversation to export.")
+	}
+
+	path := strings.TrimSpace(args)
+	if path == "" {
+		path = "conversation-" + sess.ID + ".md"
+	}
+	format := session.FormatMarkdown
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".html", ".htm":
+		format = session.FormatHTML
+	}
+
+	doc, err := session.Export(sess, format, session.ExportOptions{
+		CostSummary: costText(m),
+	})
+	if err != nil {
+		return *m, tea.Println(errorStyle.Render("Export failed: " + err.Error()))
+	}
+	if err := os.WriteFile(path, []byte(doc), 0644); err != nil {
+		return *m, tea.Println(errorStyle.Render("Export failed: " + err.Error()))
+	}
+	return *m, tea.Println(fmt.Sprintf("Conversation exported to %s", path))
+}
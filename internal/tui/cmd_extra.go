@@ -1,7 +1,6 @@
 package tui
 
 import (
-	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -150,54 +149,6 @@ func permissionsText(m *model) string {
 	return b.String()
 }
 
-// registerHooksCommand registers /hooks.
-func registerHooksCommand(r *slashRegistry) {
-	r.register(SlashCommand{
-		Name:        "hooks",
-		Description: "View configured hooks",
-		Execute:     textCommand(hooksText),
-	})
-}
-
-func hooksText(m *model) string {
-	if m.settings == nil || m.settings.Hooks == nil {
-		return "No hooks configured.\n\nAdd hooks in .claude/settings.json or ~/.claude/settings.json"
-	}
-
-	var hookConfig map[string]json.RawMessage
-	if err := json.Unmarshal(m.settings.Hooks, &hookConfig); err != nil {
-		return "Error parsing hooks configuration."
-	}
-
-	if len(hookConfig) == 0 {
-		return "No hooks configured."
-	}
-
-	var b strings.Builder
-	b.WriteString("Configured Hooks\n")
-	b.WriteString("================\n\n")
-	for event, defs := range hookConfig {
-		var hooks []map[string]string
-		if err := json.Unmarshal(defs, &hooks); err != nil {
-			b.WriteString(fmt.Sprintf("  %s: (parse error)\n", event))
-			continue
-		}
-		b.WriteString(fmt.Sprintf("  %s: %d hook(s)\n", event, len(hooks)))
-		for _, h := range hooks {
-			if cmd, ok := h["command"]; ok {
-				b.WriteString(fmt.Sprintf("    - command: %s\n", cmd))
-			} else if prompt, ok := h["prompt"]; ok {
-				truncated := prompt
-				if len(truncated) > 60 {
-					truncated = truncated[:57] + "..."
-				}
-				b.WriteString(fmt.Sprintf("    - prompt: %s\n", truncated))
-			}
-		}
-	}
-	return b.String()
-}
-
 // registerStatusCommand registers /status.
 func registerStatusCommand(r *slashRegistry) {
 	r.register(SlashCommand{
@@ -0,0 +1,32 @@
+package tui
+
+import (
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// registerHooksCommand registers /hooks.
+func registerHooksCommand(r *slashRegistry) {
+	r.register(SlashCommand{
+		Name:        "hooks",
+		Description: "Manage hooks",
+		Execute:     executeHooks,
+	})
+}
+
+func executeHooks(m *model, args string) (tea.Model, tea.Cmd) {
+	cwd := m.cwd
+	if cwd == "" {
+		cwd, _ = os.Getwd()
+	}
+	p, err := newHooksPanel(cwd)
+	if err != nil {
+		return *m, tea.Println(errorStyle.Render("Error: " + err.Error()))
+	}
+	p.noHooks = m.noHooks
+	m.hooksPanel = p
+	m.mode = modeHooks
+	m.textInput.Blur()
+	return *m, nil
+}
@@ -18,6 +18,7 @@ func executeLogout(m *model, args string) (tea.Model, tea.Cmd) {
 		}
 	}
 	m.quitting = true
+	m.exitAction = ExitLogout
 	return *m, tea.Batch(
 		tea.Println("Successfully logged out from your Anthropic account."),
 		tea.Quit,
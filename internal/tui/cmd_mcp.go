@@ -3,14 +3,25 @@ package tui
 import (
 	"fmt"
 	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
 )
 
+// mcpLogLines is how many lines of a server's log /mcp logs shows.
+const mcpLogLines = 50
+
 // registerMCPCommand registers /mcp.
 func registerMCPCommand(r *slashRegistry) {
 	r.register(SlashCommand{
 		Name:        "mcp",
-		Description: "Show MCP server status",
-		Execute:     textCommand(mcpText),
+		Description: "Show MCP server status, or a server's logs with /mcp logs <server>",
+		Execute: func(m *model, args string) (tea.Model, tea.Cmd) {
+			fields := strings.Fields(args)
+			if len(fields) > 0 && fields[0] == "logs" {
+				return *m, tea.Println(mcpLogsText(m, fields[1:]))
+			}
+			return *m, tea.Println(mcpText(m))
+		},
 	})
 }
 
@@ -27,5 +38,34 @@ func mcpText(m *model) string {
 	for _, name := range servers {
 		b.WriteString("  " + m.mcpStatus.ServerStatus(name) + "\n")
 	}
+	b.WriteString("\nUse /mcp logs <server> to see what a server wrote to stderr.")
+	return b.String()
+}
+
+// mcpLogsText shows the end of a server's stderr log.
+func mcpLogsText(m *model, args []string) string {
+	if m.mcpStatus == nil {
+		return "No MCP servers configured."
+	}
+	if len(args) != 1 {
+		return "Usage: /mcp logs <server>"
+	}
+	name := args[0]
+	lines, path := m.mcpStatus.Logs(name, mcpLogLines)
+	if len(lines) == 0 && path == "" {
+		return fmt.Sprintf("No logs for MCP server %q. Only stdio servers write logs.", name)
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Logs for MCP server %s (last %d lines):\n", name, mcpLogLines))
+	if len(lines) == 0 {
+		b.WriteString("  (no output yet)\n")
+	}
+	for _, line := range lines {
+		b.WriteString("  " + line + "\n")
+	}
+	if path != "" {
+		b.WriteString("\nFull log: " + path)
+	}
 	return strings.TrimRight(b.String(), "\n")
 }
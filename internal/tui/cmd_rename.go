@@ -0,0 +1,40 @@
+package tui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// registerRenameCommand registers /rename.
+func registerRenameCommand(r *slashRegistry) {
+	r.register(SlashCommand{
+		Name:        "rename",
+		Description: "Name this session, to resume it with claude -r <name>",
+		Execute:     executeRename,
+	})
+}
+
+func executeRename(m *model, args string) (tea.Model, tea.Cmd) {
+	if m.sessStore == nil || m.session == nil {
+		return *m, tea.Println(errorStyle.Render("Session store not available."))
+	}
+	name := strings.TrimSpace(args)
+	if name == "" {
+		if m.session.Name != "" {
+			return *m, tea.Println("This session is named " + m.session.Name + ". Usage: /rename <name>")
+		}
+		return *m, tea.Println(errorStyle.Render("Usage: /rename <name>"))
+	}
+	if err := m.sessStore.CheckName(m.session.ID, name); err != nil {
+		return *m, tea.Println(errorStyle.Render("Cannot rename: " + err.Error() + "."))
+	}
+	// A session not saved yet gets its name with its first save.
+	if m.sessStore.Exists(m.session.ID) {
+		if err := m.sessStore.Rename(m.session.ID, name); err != nil {
+			return *m, tea.Println(errorStyle.Render("Cannot rename: " + err.Error() + "."))
+		}
+	}
+	m.session.Name = name
+	return *m, tea.Println("Session named " + name + ". Resume it with: claude -r " + name)
+}
@@ -1,12 +1,20 @@
 package tui
 
-import tea "github.com/charmbracelet/bubbletea"
+import (
+	"errors"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/anthropics/claude-code-go/internal/conversation"
+	"github.com/anthropics/claude-code-go/internal/session"
+)
 
 // registerResumeCommand registers /resume.
 func registerResumeCommand(r *slashRegistry) {
 	r.register(SlashCommand{
 		Name:        "resume",
-		Description: "Resume a previous session",
+		Description: "Resume a previous session (all: from any project; fork: continue in a copy)",
 		Execute:     executeResume,
 	})
 }
@@ -15,13 +23,80 @@ func executeResume(m *model, args string) (tea.Model, tea.Cmd) {
 	if m.sessStore == nil {
 		return *m, tea.Println(errorStyle.Render("Session store not available."))
 	}
-	sessions, err := m.sessStore.List()
+	var all, fork bool
+	for _, arg := range strings.Fields(args) {
+		switch arg {
+		case "all":
+			all = true
+		case "fork":
+			fork = true
+		default:
+			return *m, tea.Println(errorStyle.Render("Usage: /resume [all] [fork]"))
+		}
+	}
+	sessions, err := loadResumeSessions(m, all)
 	if err != nil || len(sessions) == 0 {
 		return *m, tea.Println(errorStyle.Render("No sessions found."))
 	}
 	m.resumeSessions = sessions
 	m.resumeCursor = 0
+	m.resumeAll = all
+	m.resumeFork = fork
 	m.mode = modeResume
 	m.textInput.Blur()
 	return *m, nil
 }
+
+// loadResumeSessions lists the sessions the picker offers: the current
+// project's, or with all every project's, grouped by directory.
+func loadResumeSessions(m *model, all bool) ([]*session.Session, error) {
+	if !all {
+		return m.sessStore.List()
+	}
+	sessions, err := session.ListAll()
+	if err != nil {
+		return nil, err
+	}
+	return groupByCWD(sessions, m.cwd), nil
+}
+
+// groupByCWD orders sessions by project directory: cwd's first, then the
+// others by their most recent session. Within a project the given order
+// (newest first) is kept.
+func groupByCWD(sessions []*session.Session, cwd string) []*session.Session {
+	order := []string{cwd}
+	groups := make(map[string][]*session.Session)
+	for _, sess := range sessions {
+		if _, ok := groups[sess.CWD]; !ok && sess.CWD != cwd {
+			order = append(order, sess.CWD)
+		}
+		groups[sess.CWD] = append(groups[sess.CWD], sess)
+	}
+	out := make([]*session.Session, 0, len(sessions))
+	for _, dir := range order {
+		out = append(out, groups[dir]...)
+	}
+	return out
+}
+
+// claimSession locks the session m is switching to and releases the one
+// it leaves, so two processes never save the same session, and tells
+// hooks about the switch. It fails, changing nothing, when another
+// process has the session open.
+func claimSession(m *model, id string) error {
+	transcript := ""
+	if m.sessStore != nil {
+		var locked *session.LockedError
+		if err := m.sessStore.Lock(id); errors.As(err, &locked) {
+			return err
+		}
+		if m.session != nil && m.session.ID != id {
+			m.sessStore.Unlock(m.session.ID)
+		}
+		transcript = m.sessStore.TranscriptPath(id)
+	}
+	if h, ok := m.hooks.(conversation.SessionHookRunner); ok {
+		h.SetSession(id, transcript)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/anthropics/claude-code-go/internal/conversation"
+)
+
+// registerStatsCommand registers /stats.
+func registerStatsCommand(r *slashRegistry) {
+	r.register(SlashCommand{
+		Name:        "stats",
+		Description: "Show per-turn statistics for this session",
+		Execute:     textCommand(statsText),
+	})
+}
+
+func statsText(m *model) string {
+	turns := m.loop.History().Turns()
+	if len(turns) == 0 {
+		return "No turns recorded yet."
+	}
+	st := conversation.SummarizeTurns(turns)
+
+	var sb strings.Builder
+	sb.WriteString("Session Statistics:\n")
+	fmt.Fprintf(&sb, "  Turns:          %d\n", st.Turns)
+	fmt.Fprintf(&sb, "  Total time:     %s\n", st.TotalDuration.Round(time.Millisecond))
+	fmt.Fprintf(&sb, "  Avg turn time:  %s\n", (st.TotalDuration / time.Duration(st.Turns)).Round(time.Millisecond))
+	fmt.Fprintf(&sb, "  Tool time:      %s\n", st.ToolDuration.Round(time.Millisecond))
+	fmt.Fprintf(&sb, "  Input tokens:   %d\n", st.InputTokens)
+	fmt.Fprintf(&sb, "  Output tokens:  %d\n", st.OutputTokens)
+	fmt.Fprintf(&sb, "  Cache read:     %d\n", st.CacheRead)
+	fmt.Fprintf(&sb, "  Cache write:    %d\n", st.CacheWrite)
+	fmt.Fprintf(&sb, "  Tool calls:     %d\n", st.ToolCalls)
+
+	writeCounts(&sb, "Models", st.ModelCounts)
+	writeCounts(&sb, "Stop reasons", st.StopReasons)
+	writeCounts(&sb, "Tools", st.ToolCounts)
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// writeCounts appends a "label:" section listing counts, most frequent first.
+func writeCounts(sb *strings.Builder, label string, counts map[string]int) {
+	if len(counts) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	fmt.Fprintf(sb, "  %s:\n", label)
+	for _, k := range keys {
+		fmt.Fprintf(sb, "    %-20s %d\n", k, counts[k])
+	}
+}
@@ -0,0 +1,61 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/anthropics/claude-code-go/internal/tools"
+)
+
+const steerUsage = "Usage: /steer [task-id] <message>"
+
+// registerSteerCommand registers /steer.
+func registerSteerCommand(r *slashRegistry) {
+	r.register(SlashCommand{
+		Name:        "steer",
+		Description: "Send follow-up instructions to a running background agent",
+		Execute:     executeSteer,
+	})
+}
+
+func executeSteer(m *model, args string) (tea.Model, tea.Cmd) {
+	return *m, tea.Println(steerText(m, strings.TrimSpace(args)))
+}
+
+// steerText sends a /steer message and returns the line to print. The task
+// ID may be left out when exactly one agent is running in the background.
+func steerText(m *model, args string) string {
+	if args == "" {
+		return steerUsage
+	}
+	if m.bgTasks == nil {
+		return "No background agents are running."
+	}
+	id, message, _ := strings.Cut(args, " ")
+	if _, ok := m.bgTasks.Get(id); !ok {
+		var running []string
+		for _, task := range m.bgTasks.List() {
+			if task.Steer != nil && task.Status() == tools.TaskRunning {
+				running = append(running, task.ID)
+			}
+		}
+		switch len(running) {
+		case 0:
+			return "No background agents are running."
+		case 1:
+			id, message = running[0], args
+		default:
+			return fmt.Sprintf("Several agents are running; name one: %s\n%s", strings.Join(running, ", "), steerUsage)
+		}
+	}
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return steerUsage
+	}
+	if err := tools.SteerTask(m.bgTasks, id, message); err != nil {
+		return errorStyle.Render("Error: " + err.Error())
+	}
+	return fmt.Sprintf("Sent to %s. The agent will see it after its current step.", id)
+}
@@ -0,0 +1,37 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// registerTasksCommand registers /tasks.
+func registerTasksCommand(r *slashRegistry) {
+	r.register(SlashCommand{
+		Name:        "tasks",
+		Description: "List background agents and commands",
+		Execute:     executeTasks,
+	})
+}
+
+func executeTasks(m *model, args string) (tea.Model, tea.Cmd) {
+	if m.bgTasks == nil {
+		return *m, tea.Println("No background tasks.")
+	}
+	tasks := m.bgTasks.List()
+	if len(tasks) == 0 {
+		return *m, tea.Println("No background tasks.")
+	}
+	m.tasksPanel = &tasksPanel{tasks: tasks, cursor: len(tasks) - 1}
+	m.mode = modeTasks
+	m.textInput.Blur()
+	return *m, nil
+}
+
+// openSessionTasks restores the background tasks saved with the current
+// session, after the session changes.
+func openSessionTasks(m *model) {
+	if m.bgTasks == nil || m.sessStore == nil || m.session == nil {
+		return
+	}
+	_ = m.bgTasks.Open(m.sessStore.TasksDir(m.session.ID))
+}
@@ -0,0 +1,110 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/anthropics/claude-code-go/internal/tools"
+)
+
+const todosUsage = "Usage: /todos [done N | undo N | clear]"
+
+// registerTodosCommand registers /todos.
+func registerTodosCommand(r *slashRegistry) {
+	r.register(SlashCommand{
+		Name:        "todos",
+		Description: "Show the todo list, or mark items done/undone (done N, undo N, clear)",
+		Execute:     executeTodos,
+	})
+}
+
+func executeTodos(m *model, args string) (tea.Model, tea.Cmd) {
+	return *m, tea.Println(todosText(m, strings.TrimSpace(args)))
+}
+
+func todosText(m *model, args string) string {
+	if args == "" {
+		return formatTodos(m.todos)
+	}
+
+	fields := strings.Fields(args)
+	if fields[0] == "clear" && len(fields) == 1 {
+		setTodos(m, nil)
+		if err := saveTodos(m); err != nil {
+			return errorStyle.Render("Warning: failed to save session: " + err.Error())
+		}
+		return "Todo list cleared."
+	}
+	if len(fields) != 2 || (fields[0] != "done" && fields[0] != "undo") {
+		return todosUsage
+	}
+	n, err := strconv.Atoi(fields[1])
+	if err != nil || n < 1 {
+		return todosUsage
+	}
+	if n > len(m.todos) {
+		return fmt.Sprintf("No todo item %d (the list has %d).", n, len(m.todos))
+	}
+
+	todos := append([]tools.TodoItem(nil), m.todos...)
+	status, verb := "completed", "Marked done"
+	if fields[0] == "undo" {
+		status, verb = "pending", "Marked not done"
+	}
+	todos[n-1].Status = status
+	setTodos(m, todos)
+	if err := saveTodos(m); err != nil {
+		return errorStyle.Render("Warning: failed to save session: " + err.Error())
+	}
+	return fmt.Sprintf("%s: %s\n%s", verb, todos[n-1].Content, formatTodos(todos))
+}
+
+// formatTodos renders a numbered todo list for /todos.
+func formatTodos(todos []tools.TodoItem) string {
+	if len(todos) == 0 {
+		return "No todos."
+	}
+	done := 0
+	for _, item := range todos {
+		if item.Status == "completed" {
+			done++
+		}
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Todos (%d of %d done):", done, len(todos))
+	for i, item := range todos {
+		icon := "[ ]"
+		switch item.Status {
+		case "in_progress":
+			icon = "[~]"
+		case "completed":
+			icon = "[x]"
+		}
+		fmt.Fprintf(&b, "\n  %d. %s %s", i+1, icon, item.Content)
+	}
+	return b.String()
+}
+
+// setTodos replaces the todo list shown in the live region, kept by the
+// TodoWrite tool, and recorded in the session.
+func setTodos(m *model, todos []tools.TodoItem) {
+	m.todos = todos
+	if m.todoTool != nil {
+		m.todoTool.SetTodos(todos)
+	}
+	if m.session != nil {
+		m.session.Todos = todos
+	}
+}
+
+// saveTodos saves the session so edits made with /todos survive a restart
+// even if no further turn completes.
+func saveTodos(m *model) error {
+	if m.sessStore == nil || m.session == nil {
+		return nil
+	}
+	return m.sessStore.Save(m.session)
+}
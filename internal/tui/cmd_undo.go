@@ -0,0 +1,57 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// registerUndoCommand registers /undo.
+func registerUndoCommand(r *slashRegistry) {
+	r.register(SlashCommand{
+		Name:        "undo",
+		Description: "Revert the last N file changes made by tools (default 1)",
+		Execute:     executeUndo,
+	})
+}
+
+func executeUndo(m *model, args string) (tea.Model, tea.Cmd) {
+	return *m, tea.Println(undoText(m, strings.TrimSpace(args)))
+}
+
+func undoText(m *model, args string) string {
+	if m.undoStore == nil {
+		return "Undo is not available in this session."
+	}
+	n := 1
+	if args != "" {
+		v, err := strconv.Atoi(args)
+		if err != nil || v < 1 {
+			return "Usage: /undo [N]"
+		}
+		n = v
+	}
+	if m.undoStore.Len() == 0 {
+		return "No file changes to undo."
+	}
+
+	undone, err := m.undoStore.Undo(n)
+	var sb strings.Builder
+	for _, e := range undone {
+		if e.Existed {
+			fmt.Fprintf(&sb, "  Restored %s (before %s)\n", e.Path, e.Tool)
+		} else {
+			fmt.Fprintf(&sb, "  Removed %s (created by %s)\n", e.Path, e.Tool)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(&sb, "Undo failed: %v\n", err)
+	}
+	if left := m.undoStore.Len(); left > 0 {
+		fmt.Fprintf(&sb, "%d more change(s) can be undone.\n", left)
+	}
+	header := fmt.Sprintf("Undid %d file change(s):\n", len(undone))
+	return header + strings.TrimSuffix(sb.String(), "\n")
+}
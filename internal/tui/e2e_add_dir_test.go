@@ -0,0 +1,33 @@
+package tui
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestE2E_AddDirCommand(t *testing.T) {
+	var added []string
+	m, _ := testModel(t, withOnAddDir(func(dir string) { added = append(added, dir) }))
+	dir := t.TempDir()
+
+	_, cmd := submitCommand(m, "/add-dir "+dir)
+	runBatch(cmd)
+
+	if len(added) != 1 || added[0] != dir {
+		t.Errorf("onAddDir called with %v, want [%s]", added, dir)
+	}
+}
+
+func TestE2E_AddDirCommand_Missing(t *testing.T) {
+	var added []string
+	m, _ := testModel(t, withOnAddDir(func(dir string) { added = append(added, dir) }))
+
+	for _, args := range []string{"", filepath.Join(t.TempDir(), "missing")} {
+		_, cmd := submitCommand(m, "/add-dir "+args)
+		runBatch(cmd)
+	}
+
+	if len(added) != 0 {
+		t.Errorf("onAddDir called with %v, want no calls", added)
+	}
+}
@@ -0,0 +1,127 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// agentsKeys sends keys to the /agents manager and returns the model.
+func agentsKeys(t *testing.T, m model, keys ...tea.KeyMsg) model {
+	t.Helper()
+	for _, k := range keys {
+		result, _ := m.handleAgentsKey(k)
+		m = result.(model)
+	}
+	return m
+}
+
+func typed(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+
+func TestE2E_AgentsCreate(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	m, _ := testModel(t)
+	m.cwd = t.TempDir()
+	m.agentTools = []string{"Bash", "FileRead", "Grep"}
+
+	m, _ = submitCommand(m, "/agents")
+	if m.mode != modeAgents || m.agentsPanel == nil {
+		t.Fatalf("mode = %v, want modeAgents", m.mode)
+	}
+	if view := m.renderAgentsPanel(); !strings.Contains(view, "Create new agent") {
+		t.Errorf("list view:\n%s", view)
+	}
+
+	enter := tea.KeyMsg{Type: tea.KeyEnter}
+	down := tea.KeyMsg{Type: tea.KeyDown}
+	space := tea.KeyMsg{Type: tea.KeySpace}
+
+	// Create new agent, project location.
+	m = agentsKeys(t, m, enter, enter)
+	if m.agentsPanel.step != agentsStepName {
+		t.Fatalf("step = %v, want name", m.agentsPanel.step)
+	}
+
+	// An invalid name is rejected in place.
+	m = agentsKeys(t, m, typed("Bad Name"), enter)
+	if m.agentsPanel.step != agentsStepName || m.agentsPanel.errMsg == "" {
+		t.Fatalf("invalid name accepted: %+v", m.agentsPanel)
+	}
+	m.agentsPanel.input = ""
+	m = agentsKeys(t, m, typed("reviewer"), enter, typed("Reviews"), space, typed("diffs"), enter)
+	if m.agentsPanel.step != agentsStepTools {
+		t.Fatalf("step = %v, want tools", m.agentsPanel.step)
+	}
+
+	// Pick Grep and FileRead, which unchecks All tools.
+	m = agentsKeys(t, m, down, down, space, down, space, enter)
+	if got := m.agentsPanel.draft.Tools; strings.Join(got, ",") != "FileRead,Grep" {
+		t.Fatalf("tools = %v", got)
+	}
+
+	// Model: sonnet.
+	m = agentsKeys(t, m, down, enter)
+	if m.mode != modeInput || m.agentsPanel != nil {
+		t.Fatalf("panel should close after creating, mode = %v", m.mode)
+	}
+
+	data, err := os.ReadFile(filepath.Join(m.cwd, ".claude", "agents", "reviewer.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"name: reviewer", "description: Reviews diffs", "tools: FileRead, Grep", "model: sonnet", "You are reviewer"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("agent file missing %q:\n%s", want, data)
+		}
+	}
+}
+
+func TestE2E_AgentsDelete(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	m, _ := testModel(t)
+	m.cwd = t.TempDir()
+	dir := filepath.Join(m.cwd, ".claude", "agents")
+	os.MkdirAll(dir, 0o755)
+	path := filepath.Join(dir, "helper.md")
+	os.WriteFile(path, []byte("---\nname: helper\ndescription: Helps\n---\nHelp."), 0o644)
+
+	m, _ = submitCommand(m, "/agents")
+	if view := m.renderAgentsPanel(); !strings.Contains(view, "helper") {
+		t.Fatalf("list view:\n%s", view)
+	}
+	enter := tea.KeyMsg{Type: tea.KeyEnter}
+	down := tea.KeyMsg{Type: tea.KeyDown}
+
+	m = agentsKeys(t, m, enter, down, enter, typed("n"))
+	if m.agentsPanel.step != agentsStepActions {
+		t.Fatalf("step after declining = %v", m.agentsPanel.step)
+	}
+	m = agentsKeys(t, m, enter, typed("y"))
+	if m.mode != modeInput {
+		t.Errorf("mode = %v, want modeInput", m.mode)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("agent file should be deleted, stat err = %v", err)
+	}
+}
+
+func TestAgentEditResult(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.md")
+	os.WriteFile(good, []byte("---\nname: good\ndescription: Fine\n---\nPrompt."), 0o644)
+	if got := agentEditResult(AgentEditDoneMsg{Path: good}, nil); !strings.Contains(got, "Saved agent good") {
+		t.Errorf("got %q", got)
+	}
+
+	bad := filepath.Join(dir, "bad.md")
+	os.WriteFile(bad, []byte("---\nname: bad\ntools: Nope\n---\n"), 0o644)
+	got := agentEditResult(AgentEditDoneMsg{Path: bad}, []string{"Bash"})
+	for _, want := range []string{"has problems", "description is required", `unknown tool "Nope"`, "system prompt"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("result missing %q:\n%s", want, got)
+		}
+	}
+}
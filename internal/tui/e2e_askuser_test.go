@@ -0,0 +1,37 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/anthropics/claude-code-go/internal/tools"
+)
+
+func TestE2E_AskUser_FreeTextQuestion(t *testing.T) {
+	m, _ := testModel(t)
+	ch := make(chan map[string]string, 1)
+	questions := []tools.AskUserQuestionItem{
+		{Question: "Deploy now?", Header: "deploy", Options: []tools.AskUserOption{{Label: "yes"}, {Label: "no"}}},
+		{Question: "API token", Header: "token"},
+	}
+
+	result, _ := m.Update(tools.AskUserRequestMsg{Questions: questions, ResponseCh: ch})
+	m = result.(model)
+	if m.askCustomInput {
+		t.Fatal("question with options should start on the option list")
+	}
+	result, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = result.(model)
+	if !m.askCustomInput {
+		t.Fatal("question without options should take typed text directly")
+	}
+	result, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("abc123")})
+	m = result.(model)
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	answers := <-ch
+	if answers["Deploy now?"] != "yes" || answers["API token"] != "abc123" {
+		t.Errorf("answers = %v, want yes and abc123", answers)
+	}
+}
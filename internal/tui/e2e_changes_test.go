@@ -0,0 +1,50 @@
+package tui
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/anthropics/claude-code-go/internal/session"
+	"github.com/anthropics/claude-code-go/internal/tools"
+)
+
+func TestE2E_ChangesCommand(t *testing.T) {
+	store := session.NewStoreWithDir(t.TempDir())
+	cwd := t.TempDir()
+	sess := &session.Session{ID: "changes", CWD: cwd}
+	m, _ := testModel(t, withSessionStore(store), withSession(sess))
+
+	if got := changesText(&m); got != "No files changed in this session." {
+		t.Errorf("before any change = %q", got)
+	}
+
+	undo := tools.NewUndoStore()
+	undo.OnSnapshot(func(e tools.UndoEntry) { store.RecordChange(sess.ID, e) })
+	write := tools.NewFileWriteTool()
+	write.SetUndoStore(undo)
+	path := filepath.Join(cwd, "notes.txt")
+	os.WriteFile(path, []byte("one\n"), 0600)
+	input, _ := json.Marshal(tools.FileWriteInput{FilePath: path, Content: "two\n"})
+	write.Execute(context.Background(), input)
+
+	out := ansi.Strip(changesText(&m))
+	for _, want := range []string{"1 file", "notes.txt (modified, +1 -1, FileWrite)", "-one", "+two"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "--- a/") {
+		t.Errorf("diff headers should be replaced by the file line:\n%s", out)
+	}
+
+	m, _ = testModel(t)
+	if got := changesText(&m); !strings.Contains(got, "not available") {
+		t.Errorf("without a store = %q", got)
+	}
+}
@@ -3,6 +3,10 @@ package tui
 import (
 	"strings"
 	"testing"
+
+	"github.com/anthropics/claude-code-go/internal/api"
+	"github.com/anthropics/claude-code-go/internal/session"
+	"github.com/anthropics/claude-code-go/internal/tools"
 )
 
 func TestE2E_CostCommand_ZeroTokens(t *testing.T) {
@@ -66,3 +70,32 @@ func TestE2E_CostCommand_TracksTurns(t *testing.T) {
 		t.Errorf("cost output should reflect 3 turns, got %q", output)
 	}
 }
+
+func TestE2E_CostCommand_SubAgents(t *testing.T) {
+	m, _ := testModel(t, withSession(&session.Session{ID: "s1"}))
+	if strings.Contains(costText(&m), "Sub-agents") {
+		t.Error("cost output should not list sub-agents when none ran")
+	}
+
+	m.agentTool = tools.NewAgentTool(nil, nil, nil, nil, nil, nil)
+	setAgentCosts(&m, []tools.AgentCost{
+		{AgentID: "agent-1", SubagentType: "Explore", Usage: api.Usage{InputTokens: 1000}, CostUSD: 0.01},
+		{AgentID: "agent-2", SubagentType: "code-reviewer", Usage: api.Usage{InputTokens: 4000, OutputTokens: 1000}, CostUSD: 0.5},
+		{AgentID: "agent-3", SubagentType: "Explore", Usage: api.Usage{InputTokens: 2000}, CostUSD: 0.02},
+	})
+	if len(m.session.AgentCosts) != 3 {
+		t.Errorf("session costs = %+v", m.session.AgentCosts)
+	}
+
+	output := costText(&m)
+	reviewer := strings.Index(output, "code-reviewer    1 run       5.0k tokens  $0.5000")
+	explore := strings.Index(output, "Explore          2 runs      3.0k tokens  $0.0300")
+	if reviewer < 0 || explore < 0 || explore < reviewer {
+		t.Errorf("sub-agents should be listed by cost, most expensive first:\n%s", output)
+	}
+	for _, want := range []string{"Sub-agent total: 8.0k tokens, $0.5300", "Total cost with sub-agents: $0.5300"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("cost output missing %q:\n%s", want, output)
+		}
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/anthropics/claude-code-go/internal/mock"
 	"github.com/anthropics/claude-code-go/internal/session"
 	"github.com/anthropics/claude-code-go/internal/skills"
+	"github.com/anthropics/claude-code-go/internal/tools"
 )
 
 // testModel creates a model wired to a mock backend for e2e testing.
@@ -70,6 +71,14 @@ func testModel(t *testing.T, opts ...testModelOption) (model, *mock.Backend) {
 		OnModelSwitch: cfg.onModelSwitch,
 		LogoutFunc:    cfg.logoutFunc,
 		FastMode:      cfg.fastMode,
+		UndoStore:     cfg.undoStore,
+		TodoTool:      cfg.todoTool,
+		Tasks:         cfg.tasks,
+		MCPPrompts:    cfg.mcpPrompts,
+		GetMCPPrompt:  cfg.getMCPPrompt,
+		OnAddDir:      cfg.onAddDir,
+		ListResources: cfg.listResources,
+		ReadResource:  cfg.readResource,
 	})
 	m.apiClient = client
 
@@ -90,6 +99,14 @@ type testModelConfig struct {
 	logoutFunc    func() error
 	fastMode      bool
 	compactor     *conversation.Compactor
+	undoStore     *tools.UndoStore
+	todoTool      *tools.TodoWriteTool
+	tasks         *tools.BackgroundTaskStore
+	mcpPrompts    []MCPPrompt
+	getMCPPrompt  MCPPromptFunc
+	onAddDir      func(string)
+	listResources MCPResourcesFunc
+	readResource  MCPReadResourceFunc
 }
 
 // testModelOption is a functional option for testModel.
@@ -143,6 +160,26 @@ func withCompactor(c *conversation.Compactor) testModelOption {
 	return func(cfg *testModelConfig) { cfg.compactor = c }
 }
 
+func withUndoStore(s *tools.UndoStore) testModelOption {
+	return func(cfg *testModelConfig) { cfg.undoStore = s }
+}
+
+func withTodoTool(tool *tools.TodoWriteTool) testModelOption {
+	return func(cfg *testModelConfig) { cfg.todoTool = tool }
+}
+
+func withTasks(store *tools.BackgroundTaskStore) testModelOption {
+	return func(cfg *testModelConfig) { cfg.tasks = store }
+}
+
+func withMCPResources(list MCPResourcesFunc, read MCPReadResourceFunc) testModelOption {
+	return func(cfg *testModelConfig) { cfg.listResources, cfg.readResource = list, read }
+}
+
+func withMCPPrompts(prompts []MCPPrompt, get MCPPromptFunc) testModelOption {
+	return func(cfg *testModelConfig) { cfg.mcpPrompts, cfg.getMCPPrompt = prompts, get }
+}
+
 // collectingStreamHandler collects all streamed text for assertions.
 type collectingStreamHandler struct {
 	texts []string
@@ -159,6 +196,10 @@ func (h *collectingStreamHandler) OnMessageDelta(_ api.MessageDeltaBody, _ *api.
 func (h *collectingStreamHandler) OnMessageStop()                                        {}
 func (h *collectingStreamHandler) OnError(_ error)                                       {}
 
+func withOnAddDir(fn func(string)) testModelOption {
+	return func(cfg *testModelConfig) { cfg.onAddDir = fn }
+}
+
 // extractPrintlnTexts collects all tea.Println output text from the commands
 // returned by handleSubmit or Update. This is used to verify what text a slash
 // command writes to the scrollback.
@@ -185,6 +226,7 @@ func submitCommand(m model, text string) (model, tea.Cmd) {
 type mockMCPStatus struct {
 	servers  []string
 	statuses map[string]string
+	logs     map[string][]string
 }
 
 func (m *mockMCPStatus) Servers() []string { return m.servers }
@@ -195,6 +237,17 @@ func (m *mockMCPStatus) ServerStatus(name string) string {
 	return name + ": unknown"
 }
 
+func (m *mockMCPStatus) Logs(name string, n int) ([]string, string) {
+	lines, ok := m.logs[name]
+	if !ok {
+		return nil, ""
+	}
+	if n > 0 && len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines, "/logs/" + name + ".log"
+}
+
 // makeTestSession creates a test session with messages for testing.
 func makeTestSession(id string, msgs ...api.Message) *session.Session {
 	return &session.Session{
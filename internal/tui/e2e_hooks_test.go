@@ -0,0 +1,169 @@
+package tui
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/anthropics/claude-code-go/internal/conversation"
+	"github.com/anthropics/claude-code-go/internal/hooks"
+)
+
+func TestPromptBlockedByHook(t *testing.T) {
+	m, _ := testModel(t)
+	m, _ = submitCommand(m, "fix the login bug")
+
+	result, _ := m.Update(LoopDoneMsg{Err: &conversation.PromptBlockedError{
+		Prompt: "fix the login bug",
+		Reason: "Reference a ticket.",
+	}})
+	m = result.(model)
+	if m.mode != modeInput {
+		t.Fatalf("mode = %v, want input", m.mode)
+	}
+	if got := m.textInput.Value(); got != "fix the login bug" {
+		t.Errorf("input = %q, want the blocked prompt back", got)
+	}
+}
+
+// hooksKeys sends keys to the /hooks manager and returns the model.
+func hooksKeys(t *testing.T, m model, keys ...tea.KeyMsg) model {
+	t.Helper()
+	for _, k := range keys {
+		result, _ := m.handleHooksKey(k)
+		m = result.(model)
+	}
+	return m
+}
+
+func TestE2E_HooksManager(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	m, _ := testModel(t)
+	m.cwd = t.TempDir()
+	runner := hooks.NewRunner(hooks.HookConfig{})
+	m.hooks = runner
+	ran := filepath.Join(t.TempDir(), "ran")
+
+	enter := tea.KeyMsg{Type: tea.KeyEnter}
+	down := tea.KeyMsg{Type: tea.KeyDown}
+
+	m, _ = submitCommand(m, "/hooks")
+	if m.mode != modeHooks || m.hooksPanel == nil {
+		t.Fatalf("mode = %v, want modeHooks", m.mode)
+	}
+
+	// Add new hook, on Stop, which has no matcher step.
+	m = hooksKeys(t, m, enter, down, down, down, down, down, enter)
+	if m.hooksPanel.step != hooksStepCommand || m.hooksPanel.event != hooks.EventStop {
+		t.Fatalf("step = %v, event %s; want the Stop command", m.hooksPanel.step, m.hooksPanel.event)
+	}
+
+	// A command that can't run is rejected in place.
+	m = hooksKeys(t, m, typed("no-such-program-here"), enter)
+	if m.hooksPanel.step != hooksStepCommand || !strings.Contains(m.hooksPanel.errMsg, "command not found") {
+		t.Fatalf("bad command accepted: %+v", m.hooksPanel)
+	}
+	m.hooksPanel.input = ""
+	m = hooksKeys(t, m, typed("touch "+ran), enter, enter)
+	if m.mode != modeInput || m.hooksPanel != nil {
+		t.Fatalf("panel should close after adding, mode = %v", m.mode)
+	}
+
+	data, err := os.ReadFile(filepath.Join(m.cwd, ".claude", "settings.json"))
+	if err != nil || !strings.Contains(string(data), `"command": "touch `+ran) {
+		t.Fatalf("project settings = %s, %v", data, err)
+	}
+	// The running hooks have it already.
+	runner.RunStop(context.Background(), false)
+	if _, err := os.Stat(ran); err != nil {
+		t.Fatalf("added hook didn't run: %v", err)
+	}
+
+	// Disable it.
+	m, _ = submitCommand(m, "/hooks")
+	if view := m.renderHooksPanel(); !strings.Contains(view, "touch "+ran+" (project)") {
+		t.Errorf("list view:\n%s", view)
+	}
+	m = hooksKeys(t, m, enter, enter)
+	if m.hooksPanel.step != hooksStepList || !m.hooksPanel.entries[0].def.Disabled {
+		t.Fatalf("step = %v, entries %+v; want the hook disabled", m.hooksPanel.step, m.hooksPanel.entries)
+	}
+	data, _ = os.ReadFile(filepath.Join(m.cwd, ".claude", "settings.json"))
+	if !strings.Contains(string(data), `"disabled": true`) {
+		t.Errorf("project settings = %s", data)
+	}
+	os.Remove(ran)
+	runner.RunStop(context.Background(), false)
+	if _, err := os.Stat(ran); err == nil {
+		t.Error("disabled hook ran")
+	}
+
+	// Delete it.
+	m = hooksKeys(t, m, enter, down, enter, typed("y"))
+	if m.mode != modeInput {
+		t.Fatalf("mode = %v, want modeInput", m.mode)
+	}
+	data, _ = os.ReadFile(filepath.Join(m.cwd, ".claude", "settings.json"))
+	if strings.Contains(string(data), "touch") {
+		t.Errorf("project settings after delete = %s", data)
+	}
+}
+
+func TestE2E_HooksManagerMatcher(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	m, _ := testModel(t)
+	m.cwd = t.TempDir()
+	enter := tea.KeyMsg{Type: tea.KeyEnter}
+
+	// Add new hook on PreToolUse, the first event, with a bad matcher.
+	m, _ = submitCommand(m, "/hooks")
+	m = hooksKeys(t, m, enter, enter, typed("Edit("), enter)
+	if m.hooksPanel.step != hooksStepMatcher || !strings.Contains(m.hooksPanel.errMsg, "Invalid matcher") {
+		t.Fatalf("bad matcher accepted: %+v", m.hooksPanel)
+	}
+	m.hooksPanel.input = ""
+	m = hooksKeys(t, m, typed("Edit|Write"), enter, typed("echo edited"), enter)
+	if m.hooksPanel.step != hooksStepLocation {
+		t.Fatalf("step = %v, want location", m.hooksPanel.step)
+	}
+
+	// Local settings, the second location.
+	m = hooksKeys(t, m, tea.KeyMsg{Type: tea.KeyDown}, enter)
+	data, err := os.ReadFile(filepath.Join(m.cwd, ".claude", "settings.local.json"))
+	if err != nil || !strings.Contains(string(data), `"matcher": "Edit|Write"`) {
+		t.Errorf("local settings = %s, %v", data, err)
+	}
+}
+
+func TestE2E_HooksManagerOverrides(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	m, _ := testModel(t)
+	m.cwd = t.TempDir()
+	m.noHooks = true
+	write := func(path, data string) {
+		os.MkdirAll(filepath.Dir(path), 0o755)
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(home, ".claude", "settings.json"), `{"hooks": {"Stop": [{"type": "command", "command": "audit"}, {"type": "command", "command": "notify"}]}}`)
+	write(filepath.Join(m.cwd, ".claude", "settings.local.json"), `{"hooks": {"Stop": [{"type": "command", "command": "audit", "disabled": true}]}}`)
+
+	m, _ = submitCommand(m, "/hooks")
+	out := m.renderHooksPanel()
+	for _, want := range []string{
+		"Hooks are off for this session",
+		"audit (user) (overridden)",
+		"notify (user)\n",
+		"audit (local) (disabled)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("panel missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -1,8 +1,13 @@
 package tui
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"strings"
 	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
 )
 
 func TestE2E_MCPCommand_NoServers(t *testing.T) {
@@ -51,3 +56,308 @@ func TestE2E_MCPCommand_WithServers(t *testing.T) {
 		t.Errorf("mcp output should show slack status, got %q", output)
 	}
 }
+
+func TestE2E_MCPLogs(t *testing.T) {
+	mcp := &mockMCPStatus{
+		servers: []string{"github", "remote"},
+		logs:    map[string][]string{"github": {"starting", "error: bad token"}},
+	}
+	m, _ := testModel(t, withMCPStatus(mcp))
+
+	output := mcpLogsText(&m, []string{"github"})
+	if !strings.Contains(output, "  error: bad token") || !strings.Contains(output, "Full log: /logs/github.log") {
+		t.Errorf("logs output = %q, want the lines and the log path", output)
+	}
+	if output := mcpLogsText(&m, []string{"remote"}); !strings.Contains(output, "Only stdio servers write logs") {
+		t.Errorf("logs output for a server without logs = %q", output)
+	}
+	if output := mcpLogsText(&m, nil); !strings.Contains(output, "Usage: /mcp logs <server>") {
+		t.Errorf("logs output without a server = %q, want usage", output)
+	}
+
+	cmd, _ := m.slashReg.lookup("mcp")
+	_, printCmd := cmd.Execute(&m, "logs github")
+	if printCmd == nil {
+		t.Fatal("/mcp logs github returned no command")
+	}
+}
+
+// runBatch executes cmd and any batched commands it returns, collecting
+// the resulting messages.
+func runBatch(cmd tea.Cmd) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	msg := cmd()
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		var msgs []tea.Msg
+		for _, c := range batch {
+			msgs = append(msgs, runBatch(c)...)
+		}
+		return msgs
+	}
+	return []tea.Msg{msg}
+}
+
+func TestE2E_MCPPromptCommand_AsksForMissingArgs(t *testing.T) {
+	prompts := []MCPPrompt{{
+		Command:     "mcp__github__review_pr",
+		Server:      "github",
+		Name:        "review_pr",
+		Description: "Review a pull request",
+		Args: []MCPPromptArg{
+			{Name: "number", Description: "PR number", Required: true},
+			{Name: "focus"},
+		},
+	}}
+	var gotServer, gotName string
+	var gotArgs map[string]string
+	get := func(_ context.Context, server, name string, args map[string]string) (string, error) {
+		gotServer, gotName, gotArgs = server, name, args
+		return "", errors.New("stop before sending")
+	}
+	m, _ := testModel(t, withMCPPrompts(prompts, get))
+
+	cmd, ok := m.slashReg.lookup("mcp__github__review_pr")
+	if !ok {
+		t.Fatal("/mcp__github__review_pr not registered")
+	}
+	if !cmd.IsSkill || !strings.Contains(cmd.Description, "Review a pull request") {
+		t.Errorf("command = %+v, want a custom command with the prompt description", cmd)
+	}
+
+	m, _ = submitCommand(m, "/mcp__github__review_pr")
+	if m.mode != modeMCPPrompt || m.mcpPromptPanel == nil {
+		t.Fatalf("mode = %d, want modeMCPPrompt", m.mode)
+	}
+	if view := m.renderMCPPromptPanel(); !strings.Contains(view, "number (PR number)") {
+		t.Errorf("panel should ask for number, got %q", view)
+	}
+
+	// Enter with no value is rejected.
+	result, _ := m.handleMCPPromptKey(tea.KeyMsg{Type: tea.KeyEnter})
+	m = result.(model)
+	if m.mcpPromptPanel == nil || m.mcpPromptPanel.errMsg == "" {
+		t.Fatal("empty required argument should be rejected")
+	}
+
+	result, _ = m.handleMCPPromptKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("42")})
+	m = result.(model)
+	result, batch := m.handleMCPPromptKey(tea.KeyMsg{Type: tea.KeyEnter})
+	m = result.(model)
+	if m.mode != modeStreaming || m.mcpPromptPanel != nil {
+		t.Fatalf("mode = %d, want modeStreaming after the last argument", m.mode)
+	}
+
+	var done *LoopDoneMsg
+	for _, msg := range runBatch(batch) {
+		if d, ok := msg.(LoopDoneMsg); ok {
+			done = &d
+		}
+	}
+	if done == nil || done.Err == nil || !strings.Contains(done.Err.Error(), "stop before sending") {
+		t.Fatalf("LoopDoneMsg = %+v, want the prompt error", done)
+	}
+	if gotServer != "github" || gotName != "review_pr" || gotArgs["number"] != "42" {
+		t.Errorf("GetPrompt(%q, %q, %v), want github review_pr number=42", gotServer, gotName, gotArgs)
+	}
+}
+
+func TestE2E_MCPPromptsRefreshed(t *testing.T) {
+	prompts := []MCPPrompt{
+		{Command: "mcp__docs__old", Server: "docs", Name: "old"},
+		{Command: "mcp__docs__kept", Server: "docs", Name: "kept"},
+	}
+	m, _ := testModel(t, withMCPPrompts(prompts, nil))
+
+	result, _ := m.Update(mcpPromptsMsg{prompts: []MCPPrompt{
+		{Command: "mcp__docs__kept", Server: "docs", Name: "kept"},
+		{Command: "mcp__docs__added", Server: "docs", Name: "added", Description: "New prompt"},
+	}})
+	m = result.(model)
+
+	if _, ok := m.slashReg.lookup("mcp__docs__old"); ok {
+		t.Error("/mcp__docs__old still registered after the server dropped it")
+	}
+	if cmd, ok := m.slashReg.lookup("mcp__docs__added"); !ok || !strings.Contains(cmd.Description, "New prompt") {
+		t.Errorf("/mcp__docs__added = %+v, %v; want it registered", cmd, ok)
+	}
+	if got := m.slashReg.complete("mcp__docs__"); len(got) != 2 {
+		t.Errorf("completions = %v, want kept and added once each", got)
+	}
+	if _, ok := m.slashReg.lookup("help"); !ok {
+		t.Error("built-in commands were removed")
+	}
+}
+
+func TestParseMCPPromptArgs(t *testing.T) {
+	args := []MCPPromptArg{{Name: "repo"}, {Name: "number"}, {Name: "focus"}}
+	tests := []struct {
+		input string
+		want  map[string]string
+	}{
+		{"", map[string]string{}},
+		{"acme/app 42", map[string]string{"repo": "acme/app", "number": "42"}},
+		{"acme/app 42 error handling", map[string]string{"repo": "acme/app", "number": "42", "focus": "error handling"}},
+		{"number=7 acme/app tests", map[string]string{"repo": "acme/app", "number": "7", "focus": "tests"}},
+	}
+	for _, tt := range tests {
+		got := parseMCPPromptArgs(args, tt.input)
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("parseMCPPromptArgs(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+
+	single := parseMCPPromptArgs([]MCPPromptArg{{Name: "topic"}}, "  the whole text  ")
+	if single["topic"] != "the whole text" {
+		t.Errorf("single argument = %q, want the whole text", single["topic"])
+	}
+}
+
+func TestE2E_MCPResourceMention(t *testing.T) {
+	var reads []string
+	read := func(_ context.Context, server, uri string) (string, error) {
+		reads = append(reads, server+" "+uri)
+		if uri == "issue://999" {
+			return "", errors.New("not found")
+		}
+		return "Crash on startup when the config is empty", nil
+	}
+	m, _ := testModel(t,
+		withMCPStatus(&mockMCPStatus{servers: []string{"github"}}),
+		withMCPResources(nil, read))
+
+	m, cmd := submitCommand(m, "Fix @github:issue://123, see @github:issue://999 or mail me@example.com")
+	if m.mode != modeStreaming {
+		t.Fatalf("mode = %d, want modeStreaming while resources are read", m.mode)
+	}
+	var mentions *mcpMentionsMsg
+	for _, msg := range runBatch(cmd) {
+		if mm, ok := msg.(mcpMentionsMsg); ok {
+			mentions = &mm
+		}
+	}
+	if mentions == nil {
+		t.Fatal("expected the mentions to be resolved")
+	}
+	if fmt.Sprint(reads) != "[github issue://123 github issue://999]" {
+		t.Errorf("reads = %v", reads)
+	}
+	want := "<mcp-resource server=\"github\" uri=\"issue://123\">\nCrash on startup when the config is empty\n</mcp-resource>"
+	if !strings.HasPrefix(mentions.text, "Fix @github:issue://123,") || !strings.Contains(mentions.text, want) {
+		t.Errorf("message = %q, want the prompt with the resource attached", mentions.text)
+	}
+	if strings.Contains(mentions.text, `uri="issue://999"`) {
+		t.Errorf("unreadable resource should not be attached: %q", mentions.text)
+	}
+	notes := strings.Join(mentions.notes, "\n")
+	if !strings.Contains(notes, "Attached @github:issue://123 (41 B)") || !strings.Contains(notes, "Could not attach @github:issue://999: not found") {
+		t.Errorf("notes = %q", notes)
+	}
+
+	result, cmd := m.Update(*mentions)
+	m = result.(model)
+	for _, msg := range runBatch(cmd) {
+		if done, ok := msg.(LoopDoneMsg); ok && done.Err != nil {
+			t.Fatalf("loop: %v", done.Err)
+		}
+	}
+	msgs := m.loop.History().Messages()
+	if len(msgs) == 0 || !strings.Contains(fmt.Sprint(msgs[0].Content), "Crash on startup") {
+		t.Errorf("sent message should include the resource, history = %+v", msgs)
+	}
+}
+
+func TestMCPResourceMention_Truncated(t *testing.T) {
+	big := strings.Repeat("é", mcpMentionMaxBytes) // two bytes each
+	read := func(context.Context, string, string) (string, error) { return big, nil }
+	msg := resolveMCPMentions(context.Background(), read, "look at @logs:file:///var/log/app.log",
+		[]mcpMention{{Server: "logs", URI: "file:///var/log/app.log"}})().(mcpMentionsMsg)
+
+	if len(msg.text) > mcpMentionMaxBytes+500 {
+		t.Errorf("message is %d bytes, want the resource cut to %d", len(msg.text), mcpMentionMaxBytes)
+	}
+	if !strings.Contains(msg.text, "[truncated: showing the first 100.0 KB of 200.0 KB]") {
+		t.Errorf("missing truncation note: %q", msg.text[len(msg.text)-200:])
+	}
+	if !strings.Contains(msg.text, "é\n[truncated") {
+		t.Error("truncation split a character")
+	}
+}
+
+func TestFindMCPMentions(t *testing.T) {
+	servers := []string{"github", "docs.internal"}
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"see @github:issue://123", "[@github:issue://123]"},
+		{"@docs.internal:page/intro. Thanks", "[@docs.internal:page/intro]"},
+		{"(@github:pr://7) and @github:pr://7", "[@github:pr://7]"},
+		{"mail bob@github:issue://1 or @gitlab:issue://2", "[]"},
+		{"@github: nothing", "[]"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprint(findMCPMentions(tt.text, servers)); got != tt.want {
+			t.Errorf("findMCPMentions(%q) = %s, want %s", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestE2E_MCPResourceCompletion(t *testing.T) {
+	resources := []MCPResource{
+		{Server: "github", URI: "issue://123", Name: "Crash on startup"},
+		{Server: "github", URI: "issue://456", Name: "Slow search"},
+		{Server: "docs", URI: "page/intro", Name: "Introduction"},
+	}
+	list := func(context.Context) []MCPResource { return resources }
+	m, _ := testModel(t, withMCPResources(list, nil))
+
+	// The first Tab lists resources in the background.
+	m.textInput.SetValue("fix @iss")
+	result, cmd := m.handleTabComplete()
+	m = result.(model)
+	if len(m.completions) != 0 {
+		t.Fatalf("completions before resources are listed = %v", m.completions)
+	}
+	result, _ = m.Update(cmd())
+	m = result.(model)
+
+	result, _ = m.handleTabComplete()
+	m = result.(model)
+	if got := m.textInput.Value(); got != "fix @github:issue://123" {
+		t.Errorf("after Tab input = %q", got)
+	}
+	if view := m.renderCompletions(); !strings.Contains(view, "@github:issue://456") || !strings.Contains(view, "Slow search") {
+		t.Errorf("completions = %q", view)
+	}
+
+	result, _ = m.handleTabComplete()
+	m = result.(model)
+	if got := m.textInput.Value(); got != "fix @github:issue://456" {
+		t.Errorf("after second Tab input = %q", got)
+	}
+
+	// Matching is by resource name too.
+	m.clearCompletions()
+	m.textInput.SetValue("@intro")
+	result, _ = m.handleTabComplete()
+	m = result.(model)
+	if got := m.textInput.Value(); got != "@docs:page/intro" {
+		t.Errorf("name match input = %q", got)
+	}
+}
+
+func TestMCPUpdate_PrintsNotice(t *testing.T) {
+	m, _ := testModel(t, withMCPStatus(&mockMCPStatus{servers: []string{"docs"}}))
+	result, cmd := m.Update(mcpUpdateMsg{target: "@docs:file:///readme.md"})
+	if cmd == nil {
+		t.Fatal("expected a notice to be printed")
+	}
+	if result.(model).mode != modeInput {
+		t.Error("a notice should not change the mode")
+	}
+	if got := mcpUpdateNotice("@docs:file:///readme.md"); got != "  ⎿ MCP update: @docs:file:///readme.md changed; Claude will see it next turn" {
+		t.Errorf("notice = %q", got)
+	}
+}
@@ -1,10 +1,16 @@
 package tui
 
 import (
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+
 	"github.com/anthropics/claude-code-go/internal/api"
+	"github.com/anthropics/claude-code-go/internal/conversation"
 	"github.com/anthropics/claude-code-go/internal/session"
 )
 
@@ -166,3 +172,157 @@ func TestE2E_ContinueCommand_PreservesSessionFields(t *testing.T) {
 		t.Errorf("session CWD = %q, want /home/user/project", result.session.CWD)
 	}
 }
+
+func TestGroupByCWD(t *testing.T) {
+	sessions := []*session.Session{
+		{ID: "1", CWD: "/b"},
+		{ID: "2", CWD: "/a"},
+		{ID: "3", CWD: "/c"},
+		{ID: "4", CWD: "/b"},
+		{ID: "5", CWD: "/a"},
+	}
+	var ids string
+	for _, sess := range groupByCWD(sessions, "/a") {
+		ids += sess.ID
+	}
+	if ids != "25143" {
+		t.Errorf("order = %q, want 25143 (current project first, then by recency)", ids)
+	}
+}
+
+func TestE2E_ResumeCommand_AllProjects(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	here := session.NewStoreWithDir(filepath.Join(home, ".claude", "projects", "here", "sessions"))
+	there := session.NewStoreWithDir(filepath.Join(home, ".claude", "projects", "there", "sessions"))
+	there.Save(&session.Session{ID: "elsewhere", CWD: "/src/other", Messages: []api.Message{makeTextMsg(api.RoleUser, "Hi")}})
+	here.Save(&session.Session{ID: "local", CWD: "/src/here", Messages: []api.Message{makeTextMsg(api.RoleUser, "Hello")}})
+
+	m, _ := testModel(t, withSessionStore(here))
+	m.cwd = "/src/here"
+
+	result, _ := submitCommand(m, "/resume")
+	if len(result.resumeSessions) != 1 {
+		t.Fatalf("resumeSessions = %d, want 1 (this project)", len(result.resumeSessions))
+	}
+	updated, _ := result.Update(tea.KeyMsg{Type: tea.KeyTab})
+	result = updated.(model)
+	if !result.resumeAll || len(result.resumeSessions) != 2 || result.resumeSessions[0].ID != "local" {
+		t.Fatalf("after Tab: all=%v sessions=%d, want both with this project first", result.resumeAll, len(result.resumeSessions))
+	}
+	if view := result.renderResumePicker(); !strings.Contains(view, "/src/other") {
+		t.Errorf("picker should show the other project's directory:\n%s", view)
+	}
+
+	updated, _ = result.Update(tea.KeyMsg{Type: tea.KeyDown})
+	updated, cmd := updated.(model).Update(tea.KeyMsg{Type: tea.KeyEnter})
+	result = updated.(model)
+	if result.exitAction != ExitResume || result.resumeTarget == nil || result.resumeTarget.ID != "elsewhere" {
+		t.Errorf("exitAction = %v, target = %v; want ExitResume with the other project's session", result.exitAction, result.resumeTarget)
+	}
+	if cmd == nil || !result.quitting {
+		t.Error("picking another project's session should quit")
+	}
+	if result.session != nil && result.session.ID == "elsewhere" {
+		t.Error("another project's session must not be resumed in this process")
+	}
+}
+
+func TestE2E_ResumeCommand_Fork(t *testing.T) {
+	store := session.NewStoreWithDir(t.TempDir())
+	store.Save(&session.Session{ID: "orig", CWD: "/tmp", Messages: []api.Message{makeTextMsg(api.RoleUser, "Hello")}})
+
+	m, _ := testModel(t, withSessionStore(store), withSession(&session.Session{ID: "current", CWD: "/tmp"}))
+	m.cwd = "/tmp"
+	result, _ := submitCommand(m, "/resume fork")
+	if result.mode != modeResume || !result.resumeFork {
+		t.Fatalf("mode = %d, fork = %v; want the picker in fork mode", result.mode, result.resumeFork)
+	}
+
+	updated, _ := result.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	result = updated.(model)
+	if result.session.ID == "orig" || result.session.ForkedFrom != "orig" {
+		t.Errorf("session = %q forked from %q, want a copy of orig", result.session.ID, result.session.ForkedFrom)
+	}
+	if result.loop.History().Len() != 1 {
+		t.Errorf("history len = %d, want 1", result.loop.History().Len())
+	}
+	if sessions, _ := store.List(); len(sessions) != 2 {
+		t.Errorf("store has %d sessions, want the original and the fork", len(sessions))
+	}
+
+	bad, _ := submitCommand(m, "/resume sideways")
+	if bad.mode != modeInput {
+		t.Error("an unknown /resume option should not open the picker")
+	}
+}
+
+func TestE2E_ResumeCommand_Preview(t *testing.T) {
+	store := session.NewStoreWithDir(t.TempDir())
+	edit := api.Message{Role: api.RoleAssistant, Content: []byte(`[
+		{"type":"text","text":"Fixing the parser."},
+		{"type":"tool_use","id":"toolu_1","name":"Edit","input":{"file_path":"/tmp/proj/parser.go","old_string":"a","new_string":"b"}}
+	]`)}
+	store.Save(&session.Session{
+		ID:    "preview",
+		Title: "Fix the parser",
+		CWD:   "/tmp/proj",
+		Messages: []api.Message{
+			makeTextMsg(api.RoleUser, "The parser rejects trailing commas"),
+			edit,
+			{Role: api.RoleUser, Content: []byte(`[{"type":"tool_result","tool_use_id":"toolu_1","content":"ok"}]`)},
+			makeTextMsg(api.RoleAssistant, "Trailing commas are accepted now."),
+		},
+		Turns:     []conversation.TurnMetadata{{Model: "claude-sonnet-4-6", Usage: api.Usage{InputTokens: 1000, OutputTokens: 500}}},
+		UpdatedAt: time.Now(),
+	})
+
+	m, _ := testModel(t, withSessionStore(store))
+	result, _ := submitCommand(m, "/resume")
+	for _, width := range []int{80, 160} {
+		result.width = width
+		view := ansi.Strip(result.renderResumePicker())
+		for _, want := range []string{"Fix the parser", "$0.0105", "parser.go", "> The parser rejects trailing commas", "Trailing commas are accepted now."} {
+			if !strings.Contains(view, want) {
+				t.Errorf("width %d: preview missing %q:\n%s", width, want, view)
+			}
+		}
+		if strings.Contains(view, "/tmp/proj/parser.go") {
+			t.Errorf("width %d: file path should be relative to the session's directory", width)
+		}
+	}
+}
+
+func TestE2E_ResumeCommand_LockedSession(t *testing.T) {
+	dir := t.TempDir()
+	store := session.NewStoreWithDir(dir)
+	store.Save(&session.Session{ID: "busy", CWD: "/tmp", Messages: []api.Message{makeTextMsg(api.RoleUser, "Hello")}})
+
+	// Another process has the session open.
+	other := session.NewStoreWithDir(dir)
+	if err := other.Lock("busy"); err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close()
+	if err := store.Lock("busy"); err == nil {
+		store.Unlock("busy")
+		t.Skip("sessions are not locked on this platform")
+	}
+
+	m, _ := testModel(t, withSessionStore(store), withSession(&session.Session{ID: "current", CWD: "/tmp"}))
+	m.cwd = "/tmp"
+	result, _ := submitCommand(m, "/resume")
+	updated, _ := result.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	result = updated.(model)
+	if result.session.ID != "current" || result.mode != modeResume {
+		t.Errorf("session = %q, mode = %d; want the locked session refused and the picker kept open", result.session.ID, result.mode)
+	}
+
+	// Forking it is fine.
+	result, _ = submitCommand(m, "/resume fork")
+	updated, _ = result.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	result = updated.(model)
+	if result.session.ForkedFrom != "busy" {
+		t.Errorf("session = %+v, want a fork of the locked session", result.session)
+	}
+}
@@ -0,0 +1,102 @@
+package tui
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/anthropics/claude-code-go/internal/session"
+	"github.com/anthropics/claude-code-go/internal/tools"
+)
+
+func TestE2E_TasksCommand(t *testing.T) {
+	m, _ := testModel(t, withTasks(tools.NewBackgroundTaskStore()))
+	m, _ = submitCommand(m, "/tasks")
+	if m.mode != modeInput || m.tasksPanel != nil {
+		t.Fatalf("/tasks with no tasks should not open the list, mode = %v", m.mode)
+	}
+
+	store := tools.NewBackgroundTaskStore()
+	done := &tools.BackgroundTask{ID: "agent-1", Kind: "agent", Description: "Find auth", Done: make(chan struct{})}
+	ctx, cancel := context.WithCancel(context.Background())
+	running := &tools.BackgroundTask{ID: "bash-1", Kind: "bash", Description: "npm test", Done: make(chan struct{}), Cancel: cancel}
+	store.Add(done)
+	store.Add(running)
+	store.Finish(done, "Auth lives in auth.go.", context.Canceled)
+
+	m, _ = testModel(t, withTasks(store))
+	m, _ = submitCommand(m, "/tasks")
+	if m.mode != modeTasks || m.tasksPanel == nil {
+		t.Fatalf("mode = %v, want modeTasks", m.mode)
+	}
+	view := m.renderTasksPanel()
+	if !strings.Contains(view, "interrupted agent  Find auth") || !strings.Contains(view, "running     bash   npm test") {
+		t.Errorf("list view:\n%s", view)
+	}
+
+	report := formatTaskReport(done)
+	if !strings.Contains(report, "agent-1 (agent, interrupted)") || !strings.Contains(report, "Auth lives in auth.go.") || !strings.Contains(report, "ask to resume agent agent-1") {
+		t.Errorf("report:\n%s", report)
+	}
+
+	// x stops the running task under the cursor, which starts on the newest.
+	result, _ := m.handleTasksKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	m = result.(model)
+	if m.mode != modeInput || m.tasksPanel != nil {
+		t.Errorf("x should close the list, mode = %v", m.mode)
+	}
+	if ctx.Err() == nil {
+		t.Error("x did not stop the running task")
+	}
+}
+
+func TestE2E_TasksRestoredOnResume(t *testing.T) {
+	store := session.NewStoreWithDir(t.TempDir())
+	tasks := tools.NewBackgroundTaskStore()
+	tasks.Open(store.TasksDir("old"))
+	task := &tools.BackgroundTask{ID: "agent-1", Kind: "agent", Done: make(chan struct{}), Snapshot: func(*tools.TaskRecord) {}}
+	tasks.Add(task)
+	tasks.Finish(task, "done", nil)
+	if err := store.Save(&session.Session{ID: "old"}); err != nil {
+		t.Fatal(err)
+	}
+
+	fresh := tools.NewBackgroundTaskStore()
+	m, _ := testModel(t, withSessionStore(store), withSession(&session.Session{ID: "new"}), withTasks(fresh))
+	m, _ = submitCommand(m, "/continue")
+	if _, ok := fresh.Get("agent-1"); !ok {
+		t.Errorf("tasks of the continued session not restored: %v", fresh.List())
+	}
+	if got := filepath.Base(store.TasksDir("old")); got != "old.tasks" {
+		t.Errorf("TasksDir = %q", got)
+	}
+}
+
+func TestE2E_SteerCommand(t *testing.T) {
+	store := tools.NewBackgroundTaskStore()
+	m, _ := testModel(t, withTasks(store))
+	if got := steerText(&m, ""); got != steerUsage {
+		t.Errorf("no args: %q", got)
+	}
+	if got := steerText(&m, "focus on auth"); got != "No background agents are running." {
+		t.Errorf("no agents: %q", got)
+	}
+
+	var got []string
+	steer := func(msg string) bool { got = append(got, msg); return true }
+	store.Add(&tools.BackgroundTask{ID: "agent-1", Kind: "agent", Done: make(chan struct{}), Steer: steer})
+	if out := steerText(&m, "focus on auth"); !strings.Contains(out, "Sent to agent-1") || got[0] != "focus on auth" {
+		t.Errorf("output = %q, delivered = %v", out, got)
+	}
+
+	store.Add(&tools.BackgroundTask{ID: "agent-2", Kind: "agent", Done: make(chan struct{}), Steer: steer})
+	if out := steerText(&m, "stop"); !strings.Contains(out, "Several agents are running") {
+		t.Errorf("ambiguous: %q", out)
+	}
+	if out := steerText(&m, "agent-2 stop"); !strings.Contains(out, "Sent to agent-2") || got[1] != "stop" {
+		t.Errorf("output = %q, delivered = %v", out, got)
+	}
+}
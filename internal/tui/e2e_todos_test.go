@@ -0,0 +1,67 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/anthropics/claude-code-go/internal/session"
+	"github.com/anthropics/claude-code-go/internal/tools"
+)
+
+func TestE2E_TodosCommand(t *testing.T) {
+	tool := tools.NewTodoWriteTool()
+	tool.SetTodos([]tools.TodoItem{
+		{Content: "Write tests", Status: "in_progress", ActiveForm: "Writing tests"},
+		{Content: "Fix bug", Status: "pending", ActiveForm: "Fixing bug"},
+	})
+	store := session.NewStoreWithDir(t.TempDir())
+	sess := &session.Session{ID: "todos"}
+
+	m, _ := testModel(t, withTodoTool(tool), withSessionStore(store), withSession(sess))
+	if len(m.todos) != 2 {
+		t.Fatalf("model should start with the tool's todos, got %+v", m.todos)
+	}
+
+	if output := todosText(&m, ""); !strings.Contains(output, "Todos (0 of 2 done)") || !strings.Contains(output, "2. [ ] Fix bug") {
+		t.Errorf("unexpected output:\n%s", output)
+	}
+
+	output := todosText(&m, "done 2")
+	if !strings.Contains(output, "Marked done: Fix bug") || !strings.Contains(output, "2. [x] Fix bug") {
+		t.Errorf("unexpected output:\n%s", output)
+	}
+	if got := tool.Todos()[1].Status; got != "completed" {
+		t.Errorf("tool status = %q, want completed", got)
+	}
+	loaded, err := store.Load("todos")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(loaded.Todos) != 2 || loaded.Todos[1].Status != "completed" {
+		t.Errorf("saved todos = %+v", loaded.Todos)
+	}
+
+	todosText(&m, "undo 2")
+	if got := m.todos[1].Status; got != "pending" {
+		t.Errorf("status after undo = %q, want pending", got)
+	}
+
+	if output := todosText(&m, "clear"); output != "Todo list cleared." || tool.Todos() != nil {
+		t.Errorf("output = %q, tool todos = %+v", output, tool.Todos())
+	}
+	if output := todosText(&m, ""); output != "No todos." {
+		t.Errorf("output = %q", output)
+	}
+}
+
+func TestE2E_TodosCommand_Usage(t *testing.T) {
+	m, _ := testModel(t)
+	for _, args := range []string{"done", "done x", "finish 1", "clear all"} {
+		if output := todosText(&m, args); output != todosUsage {
+			t.Errorf("/todos %s: output = %q", args, output)
+		}
+	}
+	if output := todosText(&m, "done 1"); !strings.Contains(output, "No todo item 1") {
+		t.Errorf("output = %q", output)
+	}
+}
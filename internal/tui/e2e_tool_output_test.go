@@ -0,0 +1,104 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/anthropics/claude-code-go/internal/conversation"
+)
+
+func TestToolOutput_ShownWhileRunning(t *testing.T) {
+	m, _ := testModel(t)
+	m, _ = submitCommand(m, "run the build")
+
+	result, _ := m.Update(ToolOutputMsg{ID: "toolu_1", Name: "Bash", Chunk: "compiling a\n"})
+	m = result.(model)
+	result, _ = m.Update(ToolOutputMsg{ID: "toolu_1", Name: "Bash", Chunk: "compiling b\n50%\r100%\n"})
+	m = result.(model)
+
+	view := m.View()
+	for _, want := range []string{"Bash", "compiling a", "compiling b", "100%"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q:\n%s", want, view)
+		}
+	}
+	if strings.Contains(view, "50%") {
+		t.Errorf("expected carriage return to overwrite progress, got:\n%s", view)
+	}
+	if strings.Contains(view, "Thinking...") {
+		t.Errorf("expected tool output instead of thinking spinner:\n%s", view)
+	}
+
+	// A new tool call replaces the previous output.
+	result, _ = m.Update(ToolOutputMsg{ID: "toolu_2", Name: "Bash", Chunk: "testing\n"})
+	m = result.(model)
+	if view := m.View(); strings.Contains(view, "compiling") || !strings.Contains(view, "testing") {
+		t.Errorf("expected only the second tool's output:\n%s", view)
+	}
+
+	// The next API response clears it.
+	result, _ = m.Update(MessageStartMsg{})
+	m = result.(model)
+	if view := m.View(); strings.Contains(view, "testing") {
+		t.Errorf("expected tool output cleared after next response:\n%s", view)
+	}
+}
+
+func TestToolOutput_TailOnly(t *testing.T) {
+	var sb strings.Builder
+	for i := 0; i < 50; i++ {
+		sb.WriteString("line\n")
+	}
+	sb.WriteString("last\n")
+	out := renderToolOutput(sb.String(), toolOutputLines, 80)
+	if n := strings.Count(out, "\n") + 1; n != toolOutputLines {
+		t.Errorf("expected %d lines, got %d", toolOutputLines, n)
+	}
+	if !strings.Contains(out, "last") {
+		t.Errorf("expected the most recent line, got %q", out)
+	}
+
+	buf := appendToolOutput(strings.Repeat("x", toolOutputMaxBytes), "\nnew\n")
+	if len(buf) > toolOutputMaxBytes || !strings.HasSuffix(buf, "new\n") {
+		t.Errorf("expected buffer capped with newest output kept, got %d bytes", len(buf))
+	}
+}
+
+func TestToolProgress_ShownOnSpinnerLine(t *testing.T) {
+	m, _ := testModel(t)
+	m, _ = submitCommand(m, "index the repo")
+
+	result, _ := m.Update(ToolProgressMsg{ID: "toolu_1", Name: "mcp__search__index", Progress: conversation.ToolProgress{Progress: 30, Total: 120, Message: "Indexing files"}})
+	m = result.(model)
+	view := m.View()
+	if !strings.Contains(view, "mcp__search__index") || !strings.Contains(view, "25% · Indexing files") {
+		t.Errorf("view missing tool progress:\n%s", view)
+	}
+	if strings.Contains(view, "Thinking...") {
+		t.Errorf("expected tool progress instead of thinking spinner:\n%s", view)
+	}
+
+	result, _ = m.Update(MessageStartMsg{})
+	m = result.(model)
+	if view := m.View(); strings.Contains(view, "Indexing files") {
+		t.Errorf("expected progress cleared after next response:\n%s", view)
+	}
+}
+
+func TestFormatToolProgress(t *testing.T) {
+	tests := []struct {
+		p    conversation.ToolProgress
+		want string
+	}{
+		{conversation.ToolProgress{Progress: 1, Total: 4}, "25%"},
+		{conversation.ToolProgress{Progress: 9, Total: 4}, "100%"},
+		{conversation.ToolProgress{Progress: 17}, "17"},
+		{conversation.ToolProgress{Message: "Waiting for lock"}, "Waiting for lock"},
+		{conversation.ToolProgress{Progress: 2.5, Message: "MB uploaded"}, "2.5 · MB uploaded"},
+	}
+	for _, tt := range tests {
+		if got := formatToolProgress(tt.p); got != tt.want {
+			t.Errorf("formatToolProgress(%+v) = %q, want %q", tt.p, got, tt.want)
+		}
+	}
+}
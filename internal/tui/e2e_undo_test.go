@@ -0,0 +1,66 @@
+package tui
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/anthropics/claude-code-go/internal/tools"
+)
+
+func TestE2E_UndoCommand(t *testing.T) {
+	store := tools.NewUndoStore()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.txt")
+
+	write := tools.NewFileWriteTool()
+	write.SetUndoStore(store)
+	for _, content := range []string{"one\n", "two\n"} {
+		input, _ := json.Marshal(tools.FileWriteInput{FilePath: path, Content: content})
+		if _, err := write.Execute(context.Background(), input); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m, _ := testModel(t, withUndoStore(store))
+
+	output := undoText(&m, "")
+	if !strings.Contains(output, "Restored "+path) || !strings.Contains(output, "1 more change(s)") {
+		t.Errorf("unexpected output:\n%s", output)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "one\n" {
+		t.Errorf("after first undo file = %q, want %q", data, "one\n")
+	}
+
+	output = undoText(&m, "5")
+	if !strings.Contains(output, "Removed "+path) {
+		t.Errorf("unexpected output:\n%s", output)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("file created by the first write should be removed, stat err = %v", err)
+	}
+
+	if output := undoText(&m, ""); output != "No file changes to undo." {
+		t.Errorf("output = %q", output)
+	}
+}
+
+func TestE2E_UndoCommand_Usage(t *testing.T) {
+	m, _ := testModel(t, withUndoStore(tools.NewUndoStore()))
+	if output := undoText(&m, "abc"); output != "Usage: /undo [N]" {
+		t.Errorf("output = %q", output)
+	}
+
+	m, _ = testModel(t)
+	if output := undoText(&m, ""); !strings.Contains(output, "not available") {
+		t.Errorf("output = %q", output)
+	}
+
+	result, _ := submitCommand(m, "/undo")
+	if result.mode != modeInput {
+		t.Errorf("mode = %d, want modeInput", result.mode)
+	}
+}
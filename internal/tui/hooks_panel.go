@@ -0,0 +1,523 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/anthropics/claude-code-go/internal/config"
+	"github.com/anthropics/claude-code-go/internal/hooks"
+)
+
+// hooksStep is a screen of the /hooks manager.
+type hooksStep int
+
+const (
+	hooksStepList          hooksStep = iota // configured hooks plus "Add new hook"
+	hooksStepActions                        // enable, disable, or delete the selected hook
+	hooksStepConfirmDelete                  // y/n before deleting
+	hooksStepEvent                          // choosing the new hook's event
+	hooksStepMatcher                        // typing its matcher
+	hooksStepCommand                        // typing its command
+	hooksStepLocation                       // choosing the settings file
+)
+
+// hooksLocations are the scopes of the settings files a new hook can be
+// saved in, with their labels.
+var hooksLocations = []struct{ scope, label string }{
+	{"project", "Project (.claude/settings.json)"},
+	{"local", "Local (.claude/settings.local.json, not committed)"},
+	{"user", "User (~/.claude/settings.json)"},
+}
+
+// hookEventHelp says when each event fires, for choosing one.
+var hookEventHelp = map[string]string{
+	hooks.EventPreToolUse:        "before a tool runs; can block it",
+	hooks.EventPostToolUse:       "after a tool runs",
+	hooks.EventPermissionRequest: "when a tool call needs permission",
+	hooks.EventUserPromptSubmit:  "when you send a message; can block it",
+	hooks.EventNotification:      "when Claude needs your attention",
+	hooks.EventStop:              "when Claude finishes responding",
+	hooks.EventSubagentStart:     "before a sub-agent runs",
+	hooks.EventSubagentStop:      "after a sub-agent finishes",
+	hooks.EventPreCompact:        "before the conversation is compacted",
+	hooks.EventSessionStart:      "when a session starts or resumes",
+	hooks.EventSessionEnd:        "when a session ends",
+}
+
+// hookEntry is a hook in the /hooks list, with the file it's set in.
+type hookEntry struct {
+	event      string
+	index      int // position in the event's list in its file
+	def        hooks.HookDef
+	file       config.SettingsFile
+	overridden bool // a higher-priority file sets the same hook
+}
+
+// hooksPanel holds the state of the /hooks manager.
+type hooksPanel struct {
+	cwd      string
+	files    []config.SettingsFile
+	entries  []hookEntry
+	loadErrs []string // files whose hooks couldn't be read
+	noHooks  bool     // --no-hooks: changes are saved, not applied
+
+	step     hooksStep
+	cursor   int
+	selected int // index into entries for the actions screen
+
+	// Add flow.
+	event  string
+	draft  hooks.HookDef
+	input  string // text typed on the matcher and command steps
+	errMsg string
+}
+
+// newHooksPanel creates the manager listing the hooks in cwd's settings
+// files.
+func newHooksPanel(cwd string) (*hooksPanel, error) {
+	files, err := config.SettingsFiles(cwd)
+	if err != nil {
+		return nil, err
+	}
+	p := &hooksPanel{cwd: cwd, files: files}
+	p.load()
+	return p, nil
+}
+
+// load reads the hooks from each settings file. All of them run, but a
+// hook a higher-priority file sets too runs as that file sets it (see
+// hooks.Merge).
+func (p *hooksPanel) load() {
+	p.entries, p.loadErrs = nil, nil
+	lists := make([]map[string]hooks.HookList, len(p.files))
+	for i, f := range p.files {
+		raw, err := config.ReadSetting(f.Path, "hooks")
+		if err == nil && raw != nil {
+			err = json.Unmarshal(raw, &lists[i])
+		}
+		if err != nil {
+			p.loadErrs = append(p.loadErrs, fmt.Sprintf("%s: %v", shortenPath(f.Path), err))
+		}
+	}
+	for _, event := range hooks.Events {
+		for i, f := range p.files {
+			for j, def := range lists[i][event] {
+				overridden := slices.ContainsFunc(lists[i+1:], func(higher map[string]hooks.HookList) bool {
+					return slices.ContainsFunc(higher[event], func(d hooks.HookDef) bool { return hooks.SameHook(d, def) })
+				})
+				p.entries = append(p.entries, hookEntry{event: event, index: j, def: def, file: f, overridden: overridden})
+			}
+		}
+	}
+}
+
+// save changes the hooks in a settings file and writes them back.
+func (p *hooksPanel) save(file config.SettingsFile, change func(map[string]hooks.HookList) error) error {
+	raw, err := config.ReadSetting(file.Path, "hooks")
+	if err != nil {
+		return err
+	}
+	var lists map[string]hooks.HookList
+	if raw != nil {
+		if err := json.Unmarshal(raw, &lists); err != nil {
+			return fmt.Errorf("hooks in %s: %w", shortenPath(file.Path), err)
+		}
+	}
+	if lists == nil {
+		lists = map[string]hooks.HookList{}
+	}
+	if err := change(lists); err != nil {
+		return err
+	}
+	maps.DeleteFunc(lists, func(_ string, list hooks.HookList) bool { return len(list) == 0 })
+	return config.SaveSetting(file.Path, "hooks", lists)
+}
+
+// changeEntry applies change to the selected hook in its file.
+func (p *hooksPanel) changeEntry(change func(list hooks.HookList, i int) hooks.HookList) error {
+	e := p.entries[p.selected]
+	return p.save(e.file, func(lists map[string]hooks.HookList) error {
+		list := lists[e.event]
+		if e.index >= len(list) || list[e.index].Command != e.def.Command {
+			return fmt.Errorf("%s changed since /hooks opened it", shortenPath(e.file.Path))
+		}
+		lists[e.event] = change(list, e.index)
+		return nil
+	})
+}
+
+// actions returns the choices for the selected hook. Managed settings
+// are only shown.
+func (p *hooksPanel) actions() []string {
+	e := p.entries[p.selected]
+	if e.file.Scope == "managed" {
+		return []string{"Back"}
+	}
+	if e.def.Disabled {
+		return []string{"Enable", "Delete", "Back"}
+	}
+	return []string{"Disable", "Delete", "Back"}
+}
+
+// optionCount returns the number of choices on the current list screen.
+func (p *hooksPanel) optionCount() int {
+	switch p.step {
+	case hooksStepList:
+		return len(p.entries) + 1
+	case hooksStepActions:
+		return len(p.actions())
+	case hooksStepEvent:
+		return len(hooks.Events)
+	case hooksStepLocation:
+		return len(hooksLocations)
+	}
+	return 0
+}
+
+// fileIndex returns the priority of a settings file: its index in files.
+func (p *hooksPanel) fileIndex(file config.SettingsFile) int {
+	return slices.Index(p.files, file)
+}
+
+// file returns the settings file with the given scope.
+func (p *hooksPanel) file(scope string) (int, config.SettingsFile) {
+	for i, f := range p.files {
+		if f.Scope == scope {
+			return i, f
+		}
+	}
+	return -1, config.SettingsFile{}
+}
+
+// hookConfigSetter is implemented by hook runners that take new hooks
+// while running, as *hooks.Runner does, so /hooks changes apply at once.
+type hookConfigSetter interface {
+	SetConfig(hooks.HookConfig)
+}
+
+// reloadHooks applies the hooks settings after /hooks changed them,
+// unless hooks are off for the session.
+func (m *model) reloadHooks(cwd string) error {
+	hookConfig, _, err := hooks.Load(cwd)
+	if err != nil {
+		return err
+	}
+	if r, ok := m.hooks.(hookConfigSetter); ok && !m.noHooks {
+		r.SetConfig(hookConfig)
+	}
+	return nil
+}
+
+// handleHooksKey processes key events in the /hooks manager.
+func (m model) handleHooksKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := m.hooksPanel
+	if p == nil {
+		m.mode = modeInput
+		return m, nil
+	}
+	if msg.Type == tea.KeyCtrlC {
+		return m.closeHooksPanel("Hooks dialog dismissed")
+	}
+
+	switch p.step {
+	case hooksStepMatcher, hooksStepCommand:
+		return m.handleHooksTextKey(msg)
+	case hooksStepConfirmDelete:
+		switch msg.String() {
+		case "y", "Y":
+			e := p.entries[p.selected]
+			err := p.changeEntry(func(list hooks.HookList, i int) hooks.HookList {
+				return slices.Delete(list, i, i+1)
+			})
+			if err == nil {
+				err = m.reloadHooks(p.cwd)
+			}
+			if err != nil {
+				return m.closeHooksPanel("Error deleting hook: " + err.Error())
+			}
+			return m.closeHooksPanel(fmt.Sprintf("Deleted %s hook from %s", e.event, shortenPath(e.file.Path)))
+		case "n", "N", "esc":
+			p.step = hooksStepActions
+		}
+		return m, nil
+	}
+
+	n := p.optionCount()
+	switch msg.Type {
+	case tea.KeyUp:
+		if p.cursor > 0 {
+			p.cursor--
+		}
+	case tea.KeyDown:
+		if p.cursor < n-1 {
+			p.cursor++
+		}
+	case tea.KeyEsc:
+		return m.hooksBack()
+	case tea.KeyEnter:
+		return m.hooksSelect()
+	}
+	return m, nil
+}
+
+// handleHooksTextKey edits the matcher or command being typed.
+func (m model) handleHooksTextKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := m.hooksPanel
+	switch msg.Type {
+	case tea.KeyEsc:
+		return m.hooksBack()
+	case tea.KeyBackspace:
+		if r := []rune(p.input); len(r) > 0 {
+			p.input = string(r[:len(r)-1])
+		}
+	case tea.KeySpace:
+		p.input += " "
+	case tea.KeyRunes:
+		p.input += string(msg.Runes)
+	case tea.KeyEnter:
+		value := strings.TrimSpace(p.input)
+		if p.step == hooksStepMatcher {
+			if err := hooks.ValidateMatcher(value); err != nil {
+				p.errMsg = "Invalid matcher: " + err.Error()
+				return m, nil
+			}
+			p.draft.Matcher = value
+			p.step, p.input, p.errMsg = hooksStepCommand, p.draft.Command, ""
+			return m, nil
+		}
+		if err := hooks.ValidateCommand(value); err != nil {
+			p.errMsg = err.Error()
+			return m, nil
+		}
+		p.draft.Command = value
+		p.step, p.cursor, p.errMsg = hooksStepLocation, 0, ""
+	}
+	return m, nil
+}
+
+// hooksSelect handles Enter on a list screen.
+func (m model) hooksSelect() (tea.Model, tea.Cmd) {
+	p := m.hooksPanel
+	switch p.step {
+	case hooksStepList:
+		if p.cursor == len(p.entries) {
+			p.step, p.cursor = hooksStepEvent, 0
+			p.draft = hooks.HookDef{Type: "command"}
+			return m, nil
+		}
+		p.selected = p.cursor
+		p.step, p.cursor = hooksStepActions, 0
+
+	case hooksStepActions:
+		switch p.actions()[p.cursor] {
+		case "Enable", "Disable":
+			err := p.changeEntry(func(list hooks.HookList, i int) hooks.HookList {
+				list[i].Disabled = !list[i].Disabled
+				return list
+			})
+			if err == nil {
+				err = m.reloadHooks(p.cwd)
+			}
+			if err != nil {
+				p.errMsg = err.Error()
+				return m, nil
+			}
+			p.load()
+			p.step, p.cursor = hooksStepList, p.selected
+		case "Delete":
+			p.step = hooksStepConfirmDelete
+		default:
+			p.step, p.cursor = hooksStepList, p.selected
+		}
+
+	case hooksStepEvent:
+		p.event = hooks.Events[p.cursor]
+		if hooks.MatcherTarget(p.event) == "" {
+			p.draft.Matcher = ""
+			p.step, p.input = hooksStepCommand, p.draft.Command
+		} else {
+			p.step, p.input = hooksStepMatcher, p.draft.Matcher
+		}
+
+	case hooksStepLocation:
+		index, file := p.file(hooksLocations[p.cursor].scope)
+		err := p.save(file, func(lists map[string]hooks.HookList) error {
+			lists[p.event] = append(lists[p.event], p.draft)
+			return nil
+		})
+		if err == nil {
+			err = m.reloadHooks(p.cwd)
+		}
+		if err != nil {
+			p.errMsg = err.Error()
+			return m, nil
+		}
+		msg := fmt.Sprintf("Added %s hook to %s", p.event, shortenPath(file.Path))
+		for _, e := range p.entries {
+			if e.event == p.event && hooks.SameHook(e.def, p.draft) && p.fileIndex(e.file) > index {
+				msg += fmt.Sprintf("\n%s sets the same hook, which overrides this one.", shortenPath(e.file.Path))
+				break
+			}
+		}
+		if p.noHooks {
+			msg += "\nHooks are off for this session (--no-hooks)."
+		}
+		return m.closeHooksPanel(msg)
+	}
+	return m, nil
+}
+
+// hooksBack returns to the previous screen, closing the manager from the
+// first one.
+func (m model) hooksBack() (tea.Model, tea.Cmd) {
+	p := m.hooksPanel
+	p.errMsg = ""
+	switch p.step {
+	case hooksStepList:
+		return m.closeHooksPanel("Hooks dialog dismissed")
+	case hooksStepActions:
+		p.step, p.cursor = hooksStepList, p.selected
+	case hooksStepEvent:
+		p.step, p.cursor = hooksStepList, len(p.entries)
+	case hooksStepMatcher:
+		p.step, p.cursor = hooksStepEvent, slices.Index(hooks.Events, p.event)
+	case hooksStepCommand:
+		p.draft.Command = strings.TrimSpace(p.input)
+		if hooks.MatcherTarget(p.event) == "" {
+			p.step, p.cursor = hooksStepEvent, slices.Index(hooks.Events, p.event)
+		} else {
+			p.step, p.input = hooksStepMatcher, p.draft.Matcher
+		}
+	case hooksStepLocation:
+		p.step, p.input = hooksStepCommand, p.draft.Command
+	}
+	return m, nil
+}
+
+// closeHooksPanel leaves the manager and prints msg.
+func (m model) closeHooksPanel(msg string) (tea.Model, tea.Cmd) {
+	m.hooksPanel = nil
+	m.mode = modeInput
+	m.textInput.Focus()
+	return m, tea.Batch(tea.Println(msg), textarea.Blink)
+}
+
+// describeHook returns a one-line summary of a hook: its matcher and
+// input conditions, and what it runs.
+func describeHook(def hooks.HookDef) string {
+	var line string
+	switch {
+	case def.Type == "mcp":
+		line = fmt.Sprintf("mcp: %s/%s", def.Server, def.Tool)
+	case def.Command != "":
+		line = def.Command
+	case def.Prompt != "":
+		line = "prompt: " + def.Prompt
+		if len(line) > 60 {
+			line = line[:57] + "..."
+		}
+	default:
+		line = "(" + def.Type + ")"
+	}
+	if def.Matcher != "" {
+		line = def.Matcher + ": " + line
+	}
+	for _, field := range slices.Sorted(maps.Keys(def.Input)) {
+		line += fmt.Sprintf(" (%s ~ %s)", field, def.Input[field])
+	}
+	if def.Async {
+		line += " (async)"
+	}
+	if def.OncePerSession {
+		line += " (once per session)"
+	}
+	if def.Debounce > 0 {
+		line += fmt.Sprintf(" (debounce %ds)", def.Debounce)
+	}
+	return line
+}
+
+// renderHooksPanel renders the /hooks manager for the live region.
+func (m model) renderHooksPanel() string {
+	p := m.hooksPanel
+	if p == nil {
+		return ""
+	}
+	var b strings.Builder
+	option := func(i int, label, detail string) {
+		if i == p.cursor {
+			b.WriteString(askSelectedStyle.Render("  > "+label) + " " + askOptionStyle.Render(detail) + "\n")
+		} else {
+			b.WriteString(askOptionStyle.Render(strings.TrimRight("    "+label+" "+detail, " ")) + "\n")
+		}
+	}
+	header := func(question string) {
+		b.WriteString(askHeaderStyle.Render("[Hooks]") + " " + askQuestionStyle.Render(question) + "\n")
+	}
+	hint := "  Use arrow keys to navigate, Enter to select, Esc to go back"
+
+	switch p.step {
+	case hooksStepList:
+		header("Configured hooks")
+		if p.noHooks {
+			b.WriteString(askOptionStyle.Render("  Hooks are off for this session (--no-hooks); changes apply to the next one.") + "\n")
+		}
+		for _, msg := range p.loadErrs {
+			b.WriteString(errorStyle.Render("  Can't read hooks in "+msg) + "\n")
+		}
+		for i, e := range p.entries {
+			detail := describeHook(e.def) + " (" + e.file.Scope + ")"
+			if e.def.Disabled {
+				detail += " (disabled)"
+			}
+			if e.overridden {
+				detail += " (overridden)"
+			}
+			option(i, e.event, detail)
+		}
+		option(len(p.entries), "Add new hook", "")
+	case hooksStepActions:
+		e := p.entries[p.selected]
+		header(fmt.Sprintf("%s hook in %s", e.event, shortenPath(e.file.Path)))
+		b.WriteString(askOptionStyle.Render("    "+describeHook(e.def)) + "\n")
+		if e.file.Scope == "managed" {
+			b.WriteString(askOptionStyle.Render("    Managed settings can't be changed here.") + "\n")
+		}
+		for i, action := range p.actions() {
+			option(i, action, "")
+		}
+	case hooksStepConfirmDelete:
+		header(fmt.Sprintf("Delete this %s hook? (y/n)", p.entries[p.selected].event))
+		hint = ""
+	case hooksStepEvent:
+		header("Which event should the hook run on?")
+		for i, event := range hooks.Events {
+			option(i, event, hookEventHelp[event])
+		}
+	case hooksStepMatcher, hooksStepCommand:
+		if p.step == hooksStepMatcher {
+			header(fmt.Sprintf("Run it for which %s? (a regular expression such as Edit|Write; empty for all)", hooks.MatcherTarget(p.event)))
+		} else {
+			header("Command to run (it gets the event as JSON on stdin):")
+		}
+		b.WriteString("  " + p.input + "_\n")
+		hint = "  Enter to continue, Esc to go back"
+	case hooksStepLocation:
+		header("Where should the hook be saved?")
+		for i, loc := range hooksLocations {
+			option(i, loc.label, "")
+		}
+	}
+
+	if p.errMsg != "" {
+		b.WriteString(errorStyle.Render("  "+p.errMsg) + "\n")
+	}
+	b.WriteString(permHintStyle.Render(hint))
+	return b.String()
+}
@@ -0,0 +1,217 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// MCPPrompt is a prompt template from an MCP server, offered as the slash
+// command /mcp__<server>__<prompt>.
+type MCPPrompt struct {
+	Command     string // slash command name without the leading "/"
+	Server      string
+	Name        string
+	Description string
+	Args        []MCPPromptArg
+}
+
+// MCPPromptArg is an argument an MCP prompt accepts.
+type MCPPromptArg struct {
+	Name        string
+	Description string
+	Required    bool
+}
+
+// MCPPromptFunc resolves an MCP prompt with arguments into the text sent
+// to the conversation.
+type MCPPromptFunc func(ctx context.Context, server, name string, args map[string]string) (string, error)
+
+// mcpPromptPanel asks for the required arguments missing from an MCP
+// prompt command, one at a time.
+type mcpPromptPanel struct {
+	prompt  MCPPrompt
+	values  map[string]string
+	missing []MCPPromptArg
+	idx     int
+	input   string
+	errMsg  string
+}
+
+// registerMCPPrompts adds a slash command for each MCP prompt, replacing
+// the commands of any earlier call. They are listed with the custom
+// commands in /help.
+func (r *slashRegistry) registerMCPPrompts(prompts []MCPPrompt) {
+	for _, name := range r.mcpPrompts {
+		r.unregister(name)
+	}
+	r.mcpPrompts = nil
+	for _, p := range prompts {
+		prompt := p // capture for closure
+		desc := prompt.Description
+		if desc == "" {
+			desc = "MCP prompt " + prompt.Name
+		}
+		r.register(SlashCommand{
+			Name:        prompt.Command,
+			Description: desc + " (MCP: " + prompt.Server + ")",
+			IsSkill:     true,
+			Execute: func(m *model, args string) (tea.Model, tea.Cmd) {
+				return executeMCPPrompt(m, prompt, args)
+			},
+		})
+		r.mcpPrompts = append(r.mcpPrompts, prompt.Command)
+	}
+}
+
+// mcpPromptsMsg carries the MCP prompts after a server's prompt list
+// changes.
+type mcpPromptsMsg struct {
+	prompts []MCPPrompt
+}
+
+// executeMCPPrompt runs an MCP prompt command, first asking for any
+// required arguments not given on the command line.
+func executeMCPPrompt(m *model, prompt MCPPrompt, args string) (tea.Model, tea.Cmd) {
+	values := parseMCPPromptArgs(prompt.Args, args)
+	var missing []MCPPromptArg
+	for _, a := range prompt.Args {
+		if a.Required && values[a.Name] == "" {
+			missing = append(missing, a)
+		}
+	}
+	if len(missing) == 0 {
+		return sendMCPPrompt(m, prompt, values)
+	}
+	m.mcpPromptPanel = &mcpPromptPanel{prompt: prompt, values: values, missing: missing}
+	m.mode = modeMCPPrompt
+	m.textInput.Blur()
+	return *m, nil
+}
+
+// sendMCPPrompt resolves the prompt on its server and sends the result to
+// the agentic loop.
+func sendMCPPrompt(m *model, prompt MCPPrompt, values map[string]string) (tea.Model, tea.Cmd) {
+	if m.getMCPPrompt == nil {
+		return *m, tea.Println("MCP prompts are not available.")
+	}
+	get := m.getMCPPrompt
+	m.mode = modeStreaming
+	m.textInput.Blur()
+	loopCmd := func() tea.Msg {
+		text, err := get(m.ctx, prompt.Server, prompt.Name, values)
+		if err != nil {
+			return LoopDoneMsg{Err: fmt.Errorf("MCP prompt /%s: %w", prompt.Command, err)}
+		}
+		return LoopDoneMsg{Err: m.loop.SendMessage(m.ctx, text)}
+	}
+	return *m, tea.Batch(loopCmd, m.spinner.Tick)
+}
+
+// parseMCPPromptArgs maps command-line text to prompt arguments. Words of
+// the form name=value set that argument; other words fill the remaining
+// arguments in order, with the last one taking the rest of the text.
+func parseMCPPromptArgs(args []MCPPromptArg, input string) map[string]string {
+	values := make(map[string]string)
+	input = strings.TrimSpace(input)
+	if input == "" || len(args) == 0 {
+		return values
+	}
+	if len(args) == 1 {
+		values[args[0].Name] = input
+		return values
+	}
+
+	known := make(map[string]bool, len(args))
+	for _, a := range args {
+		known[a.Name] = true
+	}
+	var positional []string
+	for _, word := range strings.Fields(input) {
+		if name, value, ok := strings.Cut(word, "="); ok && known[name] {
+			values[name] = value
+			continue
+		}
+		positional = append(positional, word)
+	}
+
+	var open []string
+	for _, a := range args {
+		if _, ok := values[a.Name]; !ok {
+			open = append(open, a.Name)
+		}
+	}
+	for i, name := range open {
+		if len(positional) == 0 {
+			break
+		}
+		if i == len(open)-1 {
+			values[name] = strings.Join(positional, " ")
+			break
+		}
+		values[name], positional = positional[0], positional[1:]
+	}
+	return values
+}
+
+// handleMCPPromptKey edits the argument being typed.
+func (m model) handleMCPPromptKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := m.mcpPromptPanel
+	if p == nil {
+		m.mode = modeInput
+		return m, nil
+	}
+	switch msg.Type {
+	case tea.KeyCtrlC, tea.KeyEsc:
+		m.mcpPromptPanel = nil
+		m.mode = modeInput
+		m.textInput.Focus()
+		return m, tea.Batch(tea.Println("Prompt /"+p.prompt.Command+" cancelled"), textarea.Blink)
+	case tea.KeyBackspace:
+		if r := []rune(p.input); len(r) > 0 {
+			p.input = string(r[:len(r)-1])
+		}
+	case tea.KeySpace:
+		p.input += " "
+	case tea.KeyRunes:
+		p.input += string(msg.Runes)
+	case tea.KeyEnter:
+		value := strings.TrimSpace(p.input)
+		if value == "" {
+			p.errMsg = p.missing[p.idx].Name + " is required"
+			return m, nil
+		}
+		p.values[p.missing[p.idx].Name] = value
+		p.idx++
+		p.input, p.errMsg = "", ""
+		if p.idx == len(p.missing) {
+			m.mcpPromptPanel = nil
+			return sendMCPPrompt(&m, p.prompt, p.values)
+		}
+	}
+	return m, nil
+}
+
+// renderMCPPromptPanel renders the argument prompt for the live region.
+func (m model) renderMCPPromptPanel() string {
+	p := m.mcpPromptPanel
+	if p == nil {
+		return ""
+	}
+	arg := p.missing[p.idx]
+	question := arg.Name
+	if arg.Description != "" {
+		question += " (" + arg.Description + ")"
+	}
+	var b strings.Builder
+	b.WriteString(askHeaderStyle.Render("[/"+p.prompt.Command+"]") + " " + askQuestionStyle.Render(question+":") + "\n")
+	b.WriteString("  " + p.input + "_\n")
+	if p.errMsg != "" {
+		b.WriteString(errorStyle.Render("  "+p.errMsg) + "\n")
+	}
+	b.WriteString(permHintStyle.Render(fmt.Sprintf("  Argument %d of %d · Enter to continue, Esc to cancel", p.idx+1, len(p.missing))))
+	return b.String()
+}
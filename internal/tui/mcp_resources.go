@@ -0,0 +1,187 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// mcpMentionMaxBytes caps the text attached for one @server:uri mention;
+// longer resources are truncated with a note.
+const mcpMentionMaxBytes = 100 * 1024
+
+// MCPResource is a resource offered by an MCP server, which a prompt can
+// attach by mentioning @<server>:<uri>.
+type MCPResource struct {
+	Server      string
+	URI         string
+	Name        string
+	Description string
+}
+
+// MCPResourcesFunc lists the resources of the connected MCP servers.
+type MCPResourcesFunc func(ctx context.Context) []MCPResource
+
+// MCPReadResourceFunc reads an MCP resource and returns its text.
+type MCPReadResourceFunc func(ctx context.Context, server, uri string) (string, error)
+
+// mcpMention is an @server:uri reference in a prompt.
+type mcpMention struct {
+	Server string
+	URI    string
+}
+
+func (r mcpMention) String() string { return "@" + r.Server + ":" + r.URI }
+
+// mcpMentionRe matches @server:uri at the start of a word.
+var mcpMentionRe = regexp.MustCompile(`(?:^|\s)@([\w.-]+):(\S+)`)
+
+// findMCPMentions returns the distinct @server:uri mentions in text whose
+// server is one of servers, so email addresses and the like are left
+// alone. Trailing punctuation is not part of the URI.
+func findMCPMentions(text string, servers []string) []mcpMention {
+	known := make(map[string]bool, len(servers))
+	for _, s := range servers {
+		known[s] = true
+	}
+	var out []mcpMention
+	seen := make(map[mcpMention]bool)
+	for _, match := range mcpMentionRe.FindAllStringSubmatch(text, -1) {
+		ref := mcpMention{Server: match[1], URI: strings.TrimRight(match[2], ".,;:!?)\"'")}
+		if !known[ref.Server] || ref.URI == "" || seen[ref] {
+			continue
+		}
+		seen[ref] = true
+		out = append(out, ref)
+	}
+	return out
+}
+
+// mcpMentionsMsg carries a prompt with its mentioned resources attached,
+// ready to send, and a line for each mention to print.
+type mcpMentionsMsg struct {
+	text  string
+	notes []string
+}
+
+// resolveMCPMentions reads the mentioned resources and appends each to
+// the prompt in an <mcp-resource> block. Resources that can't be read are
+// reported and left out.
+func resolveMCPMentions(ctx context.Context, read MCPReadResourceFunc, text string, mentions []mcpMention) tea.Cmd {
+	return func() tea.Msg {
+		var b strings.Builder
+		b.WriteString(text)
+		var notes []string
+		for _, ref := range mentions {
+			content, err := read(ctx, ref.Server, ref.URI)
+			if err != nil {
+				notes = append(notes, fmt.Sprintf("  ⎿ Could not attach %s: %v", ref, err))
+				continue
+			}
+			size := len(content)
+			if size > mcpMentionMaxBytes {
+				content = truncateUTF8(content, mcpMentionMaxBytes) +
+					fmt.Sprintf("\n[truncated: showing the first %s of %s]", formatBytes(mcpMentionMaxBytes), formatBytes(size))
+			}
+			fmt.Fprintf(&b, "\n\n<mcp-resource server=%q uri=%q>\n%s\n</mcp-resource>", ref.Server, ref.URI, content)
+			notes = append(notes, fmt.Sprintf("  ⎿ Attached %s (%s)", ref, formatBytes(size)))
+		}
+		return mcpMentionsMsg{text: b.String(), notes: notes}
+	}
+}
+
+// truncateUTF8 cuts s to at most n bytes without splitting a character.
+func truncateUTF8(s string, n int) string {
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// formatBytes formats a size as B, KB, or MB.
+func formatBytes(n int) string {
+	switch {
+	case n >= 1024*1024:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1024*1024))
+	case n >= 1024:
+		return fmt.Sprintf("%.1f KB", float64(n)/1024)
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+// mcpResourcesMsg carries the MCP resources offered for completion.
+type mcpResourcesMsg struct {
+	resources []MCPResource
+}
+
+// loadMCPResources lists the MCP servers' resources in the background.
+func (m model) loadMCPResources() tea.Cmd {
+	if m.listMCPResources == nil {
+		return nil
+	}
+	list, ctx := m.listMCPResources, m.ctx
+	return func() tea.Msg {
+		return mcpResourcesMsg{resources: list(ctx)}
+	}
+}
+
+// mcpUpdateMsg reports that a subscribed or polled MCP resource or tool
+// changed. The loop already has a reminder queued; this shows the user.
+type mcpUpdateMsg struct {
+	target string
+}
+
+// mcpUpdateNotice is the line printed for an mcpUpdateMsg.
+func mcpUpdateNotice(target string) string {
+	return "  ⎿ MCP update: " + target + " changed; Claude will see it next turn"
+}
+
+// mentionWord returns the word being typed at the end of text if it
+// starts an @-mention.
+func mentionWord(text string) (string, bool) {
+	if text == "" || strings.HasSuffix(text, " ") || strings.HasSuffix(text, "\n") {
+		return "", false
+	}
+	word := text[strings.LastIndexAny(text, " \n")+1:]
+	return word, strings.HasPrefix(word, "@")
+}
+
+// completeMention offers the known resources matching the @-word being
+// typed, as @server:uri. The list is refreshed in the background each
+// time completion starts, so new resources show up on the next Tab.
+func (m model) completeMention(text, word string) (tea.Model, tea.Cmd) {
+	typed := strings.ToLower(strings.TrimPrefix(word, "@"))
+	var matches []string
+	for _, r := range m.mcpResources {
+		ref := mcpMention{Server: r.Server, URI: r.URI}.String()
+		if strings.Contains(strings.ToLower(ref), typed) || strings.Contains(strings.ToLower(r.Name), typed) {
+			matches = append(matches, ref)
+		}
+	}
+	refresh := m.loadMCPResources()
+	if len(matches) == 0 {
+		return m, refresh
+	}
+	m.completions = matches
+	m.completionIdx = 0
+	m.completionBase = word
+	m.completionPrefix = strings.TrimSuffix(text, word)
+	m.completingMention = true
+	m.applyCompletion()
+	return m, refresh
+}
+
+// mentionDescription returns the name of the resource a completion
+// refers to, shown next to it.
+func (m model) mentionDescription(ref string) string {
+	for _, r := range m.mcpResources {
+		if (mcpMention{Server: r.Server, URI: r.URI}).String() == ref {
+			return r.Name
+		}
+	}
+	return ""
+}
@@ -18,15 +18,19 @@ import (
 type uiMode int
 
 const (
-	modeInput      uiMode = iota // waiting for user text
+	modeInput       uiMode = iota // waiting for user text
 	modeStreaming                 // receiving API response
-	modePermission               // waiting for permission y/n
-	modeAskUser                  // waiting for ask-user response
-	modeResume                   // session picker for /resume
-	modeModelPicker              // choosing a model via /model
-	modeDiff                     // viewing diff dialog
-	modeConfig                   // config panel open
-	modeHelp                     // viewing help screen
+	modePermission                // waiting for permission y/n
+	modeAskUser                   // waiting for ask-user response
+	modeResume                    // session picker for /resume
+	modeModelPicker               // choosing a model via /model
+	modeDiff                      // viewing diff dialog
+	modeConfig                    // config panel open
+	modeHelp                      // viewing help screen
+	modeAgents                    // /agents manager open
+	modeHooks                     // /hooks manager open
+	modeTasks                     // /tasks list open
+	modeMCPPrompt                 // typing arguments for an MCP prompt command
 )
 
 // model is the Bubble Tea model for the TUI.
@@ -41,6 +45,12 @@ type model struct {
 	mcpStatus MCPStatus   // MCP manager for /mcp command; may be nil
 	apiClient *api.Client // API client for model switching
 
+	// Lifecycle hooks the TUI fires itself: Notification, and the
+	// SessionEnd and SessionStart around /clear. nil when not configured.
+	hooks   conversation.HookRunner
+	noHooks bool // --no-hooks: /hooks changes aren't applied
+	idleSeq int  // bumped by key presses; see startIdleTimer
+
 	// UI state.
 	mode          uiMode
 	width, height int
@@ -53,6 +63,14 @@ type model struct {
 	streamingText string // accumulated markdown text during streaming
 	activeTool    string // name of tool currently executing (shown with spinner)
 	toolSummary   string // short description of the active tool call
+	toolOutputID  string // tool_use ID whose output is shown in toolOutput
+	toolOutputFor string // name of the tool producing toolOutput
+	toolProgress  string // latest progress report of that tool, formatted
+	toolOutput    string // tail of the running tool's output
+
+	// Running sub-agents, shown under an Agent spinner.
+	agentProgress []agentProgressEntry
+	agentDetail   bool // show each sub-agent's latest text (ctrl+o)
 
 	// Token tracking.
 	tokens tokenTracker
@@ -84,6 +102,9 @@ type model struct {
 	// Resume session picker state.
 	resumeSessions []*session.Session // loaded session list for picker
 	resumeCursor   int                // selected index in session list
+	resumeAll      bool               // picker shows every project's sessions, grouped by directory
+	resumeFork     bool               // picked session is continued in a copy (Store.Fork)
+	resumeTarget   *session.Session   // another project's session to resume after exiting
 
 	// Auth callbacks.
 	logoutFunc func() error // Clears credentials; nil if not available.
@@ -93,6 +114,30 @@ type model struct {
 	diffSelected int    // selected file index
 	diffViewMode string // "list" or "detail"
 
+	// /agents manager state.
+	agentsPanel *agentsPanel
+	agentTools  []string // tool names offered when creating an agent
+
+	// /hooks manager state.
+	hooksPanel *hooksPanel
+
+	// MCP prompt commands.
+	mcpPromptPanel *mcpPromptPanel
+	getMCPPrompt   MCPPromptFunc // nil if MCP prompts are unavailable
+
+	// MCP resources for @server:uri mentions; the funcs are nil without
+	// MCP servers.
+	listMCPResources MCPResourcesFunc
+	readMCPResource  MCPReadResourceFunc
+	mcpResources     []MCPResource // last listed, for completion
+
+	// Called with each directory added by /add-dir; may be nil.
+	onAddDir func(dir string)
+
+	// /tasks list state.
+	tasksPanel *tasksPanel
+	bgTasks    *tools.BackgroundTaskStore // nil if background tasks are unavailable
+
 	// Config panel state.
 	configPanel *configPanel
 	settings    *config.Settings // reference to live settings
@@ -102,6 +147,10 @@ type model struct {
 	completionIdx  int      // selected index in completions (-1 = none)
 	completionBase string   // the original typed text (without leading /)
 
+	// @-mention completion of MCP resources reuses the fields above.
+	completingMention bool   // completions are @server:uri mentions
+	completionPrefix  string // input before the @-word being completed
+
 	// Help screen state.
 	helpTab       int // 0=general, 1=commands, 2=custom-commands
 	helpScrollOff int // scroll offset (first visible line of tab content)
@@ -109,6 +158,20 @@ type model struct {
 	// Fast mode toggle.
 	fastMode bool
 
+	// Shell working directory, shown on Bash tool lines when it differs
+	// from the startup directory.
+	cwd      string
+	shellCwd func() string // nil if the Bash tool has no persistent shell
+
+	// File modifications made by tools this session, for /undo.
+	undoStore *tools.UndoStore
+
+	// TodoWrite tool, whose list /todos edits and sessions persist.
+	todoTool *tools.TodoWriteTool
+
+	// Agent tool, whose per-run costs /cost rolls up and sessions persist.
+	agentTool *tools.AgentTool
+
 	// Command queueing: users can type and submit messages while the agent
 	// is busy. These are stored here and automatically sent when the current
 	// turn completes.
@@ -158,6 +221,20 @@ type ModelConfig struct {
 	OnModelSwitch func(newModel string)
 	LogoutFunc    func() error
 	FastMode      bool
+	Cwd           string
+	ShellCwd      func() string
+	UndoStore     *tools.UndoStore
+	TodoTool      *tools.TodoWriteTool
+	AgentTools    []string
+	Tasks         *tools.BackgroundTaskStore
+	AgentTool     *tools.AgentTool
+	MCPPrompts    []MCPPrompt
+	GetMCPPrompt  MCPPromptFunc
+	OnAddDir      func(dir string)
+	ListResources MCPResourcesFunc
+	ReadResource  MCPReadResourceFunc
+	Hooks         conversation.HookRunner
+	NoHooks       bool
 }
 
 // newModel creates the initial Bubble Tea model.
@@ -171,6 +248,7 @@ func newModel(cfg ModelConfig) model {
 	if len(cfg.Skills) > 0 {
 		slash.registerSkills(cfg.Skills)
 	}
+	slash.registerMCPPrompts(cfg.MCPPrompts)
 
 	m := model{
 		loop:             cfg.Loop,
@@ -189,12 +267,28 @@ func newModel(cfg ModelConfig) model {
 		mdRenderer:       md,
 		slashReg:         slash,
 		logoutFunc:       cfg.LogoutFunc,
+		hooks:            cfg.Hooks,
+		noHooks:          cfg.NoHooks,
 		initialPrompt:    cfg.InitialPrompt,
 		sessStore:        cfg.SessStore,
 		session:          cfg.Session,
 		fastMode:         cfg.FastMode,
+		cwd:              cfg.Cwd,
+		shellCwd:         cfg.ShellCwd,
+		undoStore:        cfg.UndoStore,
+		todoTool:         cfg.TodoTool,
+		agentTools:       cfg.AgentTools,
+		bgTasks:          cfg.Tasks,
+		agentTool:        cfg.AgentTool,
+		getMCPPrompt:     cfg.GetMCPPrompt,
+		listMCPResources: cfg.ListResources,
+		readMCPResource:  cfg.ReadResource,
+		onAddDir:         cfg.OnAddDir,
 		promptSuggestion: generatePromptSuggestion(),
 	}
+	if cfg.TodoTool != nil {
+		m.todos = cfg.TodoTool.Todos()
+	}
 	m.tokens.setModel(cfg.ModelName)
 	return m
 }
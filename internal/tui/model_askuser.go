@@ -4,6 +4,8 @@ import (
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/anthropics/claude-code-go/internal/tools"
 )
 
 // handleAskUserKey processes key events during an ask-user prompt.
@@ -83,6 +85,7 @@ func (m model) handleAskUserKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 func (m model) advanceAskUser() (tea.Model, tea.Cmd) {
 	m.askQuestionIdx++
 	m.askCursor = 0
+	m.askCustomInput = askTakesText(m.askUserPending.Questions, m.askQuestionIdx)
 
 	if m.askQuestionIdx >= len(m.askUserPending.Questions) {
 		// All questions answered. Print summary to scrollback.
@@ -105,6 +108,12 @@ func (m model) advanceAskUser() (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// askTakesText reports whether question i has no options, so the answer
+// is typed straight away rather than via "Other".
+func askTakesText(questions []tools.AskUserQuestionItem, i int) bool {
+	return i < len(questions) && len(questions[i].Options) == 0
+}
+
 // renderAskUserPrompt renders the current ask-user question.
 func (m model) renderAskUserPrompt() string {
 	if m.askUserPending == nil || m.askQuestionIdx >= len(m.askUserPending.Questions) {
@@ -116,6 +125,12 @@ func (m model) renderAskUserPrompt() string {
 
 	b.WriteString(askHeaderStyle.Render("["+q.Header+"]") + " " + askQuestionStyle.Render(q.Question) + "\n")
 
+	if len(q.Options) == 0 {
+		b.WriteString(askSelectedStyle.Render("  > "+m.askCustomText+"_") + "\n")
+		b.WriteString(permHintStyle.Render("  Type your answer, Enter to submit"))
+		return b.String()
+	}
+
 	for i, opt := range q.Options {
 		prefix := "  "
 		if i == m.askCursor && !m.askCustomInput {
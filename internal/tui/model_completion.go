@@ -7,10 +7,20 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-// handleTabComplete triggers or cycles forward through fuzzy slash command completions.
+// handleTabComplete triggers or cycles forward through fuzzy slash command
+// completions, or MCP resource completions for an @-mention.
 func (m model) handleTabComplete() (tea.Model, tea.Cmd) {
 	text := m.textInput.Value()
 
+	if m.completingMention && len(m.completions) > 0 {
+		m.completionIdx = (m.completionIdx + 1) % len(m.completions)
+		m.applyCompletion()
+		return m, nil
+	}
+	if word, ok := mentionWord(text); ok {
+		return m.completeMention(text, word)
+	}
+
 	// Only complete when the input starts with "/".
 	if !strings.HasPrefix(text, "/") {
 		return m, nil
@@ -61,6 +71,9 @@ func (m *model) applyCompletion() {
 		return
 	}
 	completed := "/" + m.completions[m.completionIdx]
+	if m.completingMention {
+		completed = m.completionPrefix + m.completions[m.completionIdx]
+	}
 	m.textInput.Reset()
 	m.textInput.SetValue(completed)
 	// Move cursor to end.
@@ -72,6 +85,8 @@ func (m *model) clearCompletions() {
 	m.completions = nil
 	m.completionIdx = -1
 	m.completionBase = ""
+	m.completionPrefix = ""
+	m.completingMention = false
 }
 
 // renderCompletions renders the inline completion suggestions.
@@ -83,15 +98,16 @@ func (m model) renderCompletions() string {
 	}
 
 	for i := 0; i < maxShow; i++ {
-		name := m.completions[i]
-		desc := ""
-		if cmd, ok := m.slashReg.lookup(name); ok {
+		name, desc := "/"+m.completions[i], ""
+		if m.completingMention {
+			name, desc = m.completions[i], m.mentionDescription(m.completions[i])
+		} else if cmd, ok := m.slashReg.lookup(m.completions[i]); ok {
 			desc = cmd.Description
 		}
 		if i == m.completionIdx {
-			b.WriteString(askSelectedStyle.Render("  > /"+name) + " " + permHintStyle.Render(desc) + "\n")
+			b.WriteString(askSelectedStyle.Render("  > "+name) + " " + permHintStyle.Render(desc) + "\n")
 		} else {
-			b.WriteString(permHintStyle.Render("    /"+name+" "+desc) + "\n")
+			b.WriteString(permHintStyle.Render("    "+name+" "+desc) + "\n")
 		}
 	}
 
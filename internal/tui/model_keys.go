@@ -21,6 +21,18 @@ func (m model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case modeConfig:
 		return m.handleConfigKey(msg)
 
+	case modeAgents:
+		return m.handleAgentsKey(msg)
+
+	case modeHooks:
+		return m.handleHooksKey(msg)
+
+	case modeTasks:
+		return m.handleTasksKey(msg)
+
+	case modeMCPPrompt:
+		return m.handleMCPPromptKey(msg)
+
 	case modePermission:
 		return m.handlePermissionKey(msg)
 
@@ -154,6 +166,11 @@ func (m model) handleStreamingKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.cancelFn()
 		return m, nil
 
+	case tea.KeyCtrlO:
+		// Ctrl+O expands or collapses running sub-agents' output.
+		m.agentDetail = !m.agentDetail
+		return m, nil
+
 	case tea.KeyEnter:
 		text := strings.TrimSpace(m.textInput.Value())
 		if text == "" {
@@ -1,18 +1,32 @@
 package tui
 
 import (
-	"encoding/json"
 	"fmt"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/textarea"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 
 	"github.com/anthropics/claude-code-go/internal/api"
+	"github.com/anthropics/claude-code-go/internal/conversation"
 	"github.com/anthropics/claude-code-go/internal/session"
 )
 
+// resumePreviewMinWidth is the narrowest terminal that shows the selected
+// session's preview beside the picker list; narrower ones show it below.
+const resumePreviewMinWidth = 100
+
+// Limits on what the preview shows of the selected session.
+const (
+	resumePreviewMessages = 4 // most recent messages with text
+	resumePreviewFiles    = 5 // files touched, in the order first edited
+	resumePreviewLines    = 2 // lines of each message
+)
+
 // handleResumeKey processes key events during the session picker.
 func (m model) handleResumeKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	if len(m.resumeSessions) == 0 {
@@ -34,26 +48,82 @@ func (m model) handleResumeKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case tea.KeyTab:
+		// Switch between this project's sessions and every project's.
+		sessions, err := loadResumeSessions(&m, !m.resumeAll)
+		if err != nil || len(sessions) == 0 {
+			return m, nil
+		}
+		m.resumeSessions = sessions
+		m.resumeCursor = 0
+		m.resumeAll = !m.resumeAll
+		return m, nil
+
 	case tea.KeyEnter:
 		sess := m.resumeSessions[m.resumeCursor]
+		elsewhere := sess.CWD != "" && sess.CWD != m.cwd
+		if m.resumeFork {
+			store := m.sessStore
+			if elsewhere {
+				other, err := session.NewStore(sess.CWD)
+				if err != nil {
+					return m, tea.Println(errorStyle.Render("Cannot fork session: " + err.Error()))
+				}
+				defer other.Close()
+				store = other
+			}
+			fork, err := store.Fork(sess.ID)
+			if err != nil {
+				return m, tea.Println(errorStyle.Render("Cannot fork session: " + err.Error()))
+			}
+			sess = fork
+		}
+		if elsewhere {
+			// Tools, settings, and CLAUDE.md are bound to this directory,
+			// so another project's session is resumed by a fresh process
+			// in its own directory once this one exits.
+			m.resumeTarget = sess
+			m.exitAction = ExitResume
+			m.quitting = true
+			return m, tea.Batch(tea.Println(resumeHeaderStyle.Render("Resuming session ")+
+				resumeIDStyle.Render(sess.ID)+resumeHeaderStyle.Render(" in "+shortenPath(sess.CWD)+"...")), tea.Quit)
+		}
+
+		if err := claimSession(&m, sess.ID); err != nil {
+			return m, tea.Println(errorStyle.Render("Cannot resume: " + err.Error() + ". Use /resume fork to continue in a copy."))
+		}
+
 		// Switch the current session to the selected one.
 		m.session.ID = sess.ID
 		m.session.Model = sess.Model
 		m.session.CWD = sess.CWD
 		m.session.Messages = sess.Messages
+		m.session.Turns = sess.Turns
 		m.session.CreatedAt = sess.CreatedAt
 		m.session.UpdatedAt = sess.UpdatedAt
+		m.session.Title = sess.Title
+		m.session.Name = sess.Name
+		m.session.ForkedFrom = sess.ForkedFrom
+		setTodos(&m, sess.Todos)
+		setAgentCosts(&m, sess.AgentCosts)
+		openSessionTasks(&m)
 
 		// Replace the loop's history with the resumed session's messages.
 		m.loop.History().SetMessages(sess.Messages)
+		m.loop.History().SetTurns(sess.Turns)
 
 		// Clear picker state.
 		m.resumeSessions = nil
 		m.resumeCursor = 0
+		m.resumeAll = false
+		m.resumeFork = false
 		m.mode = modeInput
 		m.textInput.Focus()
 
 		summary := sessionSummary(sess)
+		if sess.ForkedFrom != "" {
+			summary += ", forked from " + sess.ForkedFrom
+		}
 		line := resumeHeaderStyle.Render("Resumed session ") +
 			resumeIDStyle.Render(sess.ID) +
 			resumeHeaderStyle.Render(" ("+summary+")")
@@ -62,6 +132,8 @@ func (m model) handleResumeKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case tea.KeyEsc, tea.KeyCtrlC:
 		m.resumeSessions = nil
 		m.resumeCursor = 0
+		m.resumeAll = false
+		m.resumeFork = false
 		m.mode = modeInput
 		m.textInput.Focus()
 		return m, textarea.Blink
@@ -70,10 +142,18 @@ func (m model) handleResumeKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-// renderResumePicker renders the session selection list.
+// renderResumePicker renders the session selection list, with a preview
+// of the selected session beside it, or below it on narrow terminals.
 func (m model) renderResumePicker() string {
 	var b strings.Builder
-	b.WriteString(resumeHeaderStyle.Render("Select a session to resume:") + "\n")
+	header := "Select a session to resume"
+	if m.resumeFork {
+		header = "Select a session to fork"
+	}
+	if m.resumeAll {
+		header += " (all projects)"
+	}
+	b.WriteString(resumeHeaderStyle.Render(header+":") + "\n")
 
 	// Show at most 10 sessions.
 	maxVisible := 10
@@ -95,18 +175,34 @@ func (m model) renderResumePicker() string {
 		}
 	}
 
+	side := m.width >= resumePreviewMinWidth
+	listWidth := m.width
+	if side {
+		listWidth = m.width / 2
+	}
+
 	for i := start; i < end; i++ {
 		sess := m.resumeSessions[i]
+		if m.resumeAll && (i == start || sess.CWD != m.resumeSessions[i-1].CWD) {
+			dir := shortenPath(sess.CWD)
+			if sess.CWD == m.cwd {
+				dir += " (current)"
+			}
+			b.WriteString(resumeHeaderStyle.Render("  "+dir) + "\n")
+		}
 		timeStr := relativeTime(sess.UpdatedAt)
 		msgCount := len(sess.Messages)
-		firstMsg := firstUserMessage(sess)
-		if len(firstMsg) > 60 {
-			firstMsg = firstMsg[:57] + "..."
-		}
+		title := sess.DisplayTitle()
 
 		desc := timeStr + " | " + pluralize(msgCount, "message", "messages")
-		if firstMsg != "" {
-			desc += " | " + firstMsg
+		if sess.Name != "" {
+			desc += " | [" + sess.Name + "]"
+		}
+		if title != "" {
+			desc += " | " + title
+		}
+		if listWidth > 0 {
+			desc = ansi.Truncate(desc, listWidth-5, "…")
 		}
 
 		if i == m.resumeCursor {
@@ -121,8 +217,114 @@ func (m model) renderResumePicker() string {
 			" of " + pluralize(len(m.resumeSessions), "", "") + ")") + "\n")
 	}
 
-	b.WriteString(permHintStyle.Render("  Use arrow keys to navigate, Enter to select, Esc to cancel"))
-	return b.String()
+	list := strings.TrimSuffix(b.String(), "\n")
+	selected := m.resumeSessions[min(m.resumeCursor, len(m.resumeSessions)-1)]
+	var out string
+	if side {
+		preview := renderResumePreview(selected, m.width-listWidth-3, m.resumeAll)
+		border := lipgloss.NewStyle().
+			BorderStyle(lipgloss.NormalBorder()).
+			BorderLeft(true).
+			BorderForeground(colorDim).
+			PaddingLeft(1)
+		out = lipgloss.JoinHorizontal(lipgloss.Top,
+			lipgloss.NewStyle().Width(listWidth).Render(list),
+			border.Render(preview))
+	} else {
+		width := m.width - 4
+		if width <= 0 {
+			width = resumePreviewMinWidth
+		}
+		preview := renderResumePreview(selected, width, m.resumeAll)
+		out = list + "\n\n" + lipgloss.NewStyle().PaddingLeft(4).Render(preview)
+	}
+
+	scope := "Tab for all projects"
+	if m.resumeAll {
+		scope = "Tab for this project"
+	}
+	return out + "\n" + permHintStyle.Render("  Use arrow keys to navigate, Enter to select, "+scope+", Esc to cancel")
+}
+
+// renderResumePreview describes a session in the picker so it can be told
+// apart from others without resuming it: its title, cost, the files it
+// edited, and its last few messages. Lines are cut to width. all adds the
+// session's directory, for the all-projects picker.
+func renderResumePreview(sess *session.Session, width int, all bool) string {
+	var lines []string
+	add := func(line string) {
+		lines = append(lines, ansi.Truncate(line, width, "…"))
+	}
+
+	add(resumeHeaderStyle.Render(sess.DisplayTitle()))
+	details := []string{sess.ID}
+	if all && sess.CWD != "" {
+		details = append(details, shortenPath(sess.CWD))
+	}
+	if cost := sess.CostUSD(); cost > 0 {
+		details = append(details, fmt.Sprintf("$%.4f", cost))
+	}
+	add(permHintStyle.Render(strings.Join(details, " · ")))
+	if sess.ForkedFrom != "" {
+		add(permHintStyle.Render("Forked from " + sess.ForkedFrom))
+	}
+
+	if files := touchedFiles(sess); len(files) > 0 {
+		lines = append(lines, "")
+		add(askQuestionStyle.Render("Files touched:"))
+		for i, f := range files {
+			if i == resumePreviewFiles {
+				add(permHintStyle.Render(fmt.Sprintf("  +%d more", len(files)-i)))
+				break
+			}
+			add("  " + f)
+		}
+	}
+
+	var recent []api.Message
+	for i := len(sess.Messages) - 1; i >= 0 && len(recent) < resumePreviewMessages; i-- {
+		if session.MessageText(sess.Messages[i]) != "" {
+			recent = append([]api.Message{sess.Messages[i]}, recent...)
+		}
+	}
+	if len(recent) > 0 {
+		lines = append(lines, "")
+		add(askQuestionStyle.Render("Recent messages:"))
+		for _, msg := range recent {
+			prefix := "  "
+			if msg.Role == api.RoleUser {
+				prefix = userLabelStyle.Render("> ")
+			}
+			text := ansi.Wordwrap(session.MessageText(msg), max(width-2, 10), "")
+			for j, line := range strings.Split(text, "\n") {
+				if j == resumePreviewLines {
+					break
+				}
+				add(prefix + line)
+				prefix = "  "
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// touchedFiles lists the files a session edited successfully, each once,
+// relative to the session's directory where they are under it.
+func touchedFiles(sess *session.Session) []string {
+	var files []string
+	seen := make(map[string]bool)
+	for _, c := range session.FileChanges(sess.Messages) {
+		if c.Failed || seen[c.Path] {
+			continue
+		}
+		seen[c.Path] = true
+		path := c.Path
+		if rel, err := filepath.Rel(sess.CWD, path); err == nil && sess.CWD != "" && !strings.HasPrefix(rel, "..") {
+			path = rel
+		}
+		files = append(files, path)
+	}
+	return files
 }
 
 // relativeTime formats a time as a human-readable relative string.
@@ -145,36 +347,22 @@ func relativeTime(t time.Time) string {
 	}
 }
 
-// firstUserMessage extracts the text of the first user message in a session.
-func firstUserMessage(sess *session.Session) string {
-	for _, msg := range sess.Messages {
-		if msg.Role != api.RoleUser {
-			continue
-		}
-		// Content can be a JSON string or []ContentBlock.
-		var text string
-		if err := json.Unmarshal(msg.Content, &text); err == nil {
-			return strings.TrimSpace(text)
-		}
-		var blocks []api.ContentBlock
-		if err := json.Unmarshal(msg.Content, &blocks); err == nil {
-			for _, b := range blocks {
-				if b.Type == api.ContentTypeText && b.Text != "" {
-					return strings.TrimSpace(b.Text)
-				}
-			}
-		}
-		break
-	}
-	return ""
-}
-
 // sessionSummary returns a short summary string for a session.
 func sessionSummary(sess *session.Session) string {
 	parts := []string{
 		relativeTime(sess.UpdatedAt),
 		pluralize(len(sess.Messages), "message", "messages"),
 	}
+	if len(sess.Turns) > 0 {
+		st := conversation.SummarizeTurns(sess.Turns)
+		parts = append(parts, pluralize(st.Turns, "turn", "turns"))
+		if st.ToolCalls > 0 {
+			parts = append(parts, pluralize(st.ToolCalls, "tool call", "tool calls"))
+		}
+	}
+	if usage := sess.TotalUsage().String(); usage != "" {
+		parts = append(parts, usage)
+	}
 	return strings.Join(parts, ", ")
 }
 
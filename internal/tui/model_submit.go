@@ -66,9 +66,17 @@ func (m model) handleSubmit(text string) (tea.Model, tea.Cmd) {
 		return m, tea.Batch(cmds...)
 	}
 
-	// Regular message: send to the agentic loop.
+	// Regular message: send to the agentic loop, after attaching any MCP
+	// resources it mentions.
 	m.mode = modeStreaming
 
+	if m.readMCPResource != nil && m.mcpStatus != nil {
+		if mentions := findMCPMentions(text, m.mcpStatus.Servers()); len(mentions) > 0 {
+			cmds = append(cmds, resolveMCPMentions(m.ctx, m.readMCPResource, text, mentions), m.spinner.Tick)
+			return m, tea.Batch(cmds...)
+		}
+	}
+
 	loopCmd := func() tea.Msg {
 		err := m.loop.SendMessage(m.ctx, text)
 		return LoopDoneMsg{Err: err}
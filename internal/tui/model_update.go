@@ -1,11 +1,14 @@
 package tui
 
 import (
+	"errors"
+
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textarea"
 	tea "github.com/charmbracelet/bubbletea"
 
 	"github.com/anthropics/claude-code-go/internal/api"
+	"github.com/anthropics/claude-code-go/internal/conversation"
 	"github.com/anthropics/claude-code-go/internal/tools"
 )
 
@@ -23,6 +26,10 @@ func (m model) Init() tea.Cmd {
 	if cmd := m.refreshStatusLine(); cmd != nil {
 		cmds = append(cmds, cmd)
 	}
+	// List MCP resources for @-mention completion.
+	if cmd := m.loadMCPResources(); cmd != nil {
+		cmds = append(cmds, cmd)
+	}
 	return tea.Batch(cmds...)
 }
 
@@ -47,6 +54,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	// ── Key events ──
 	case tea.KeyMsg:
+		m.idleSeq++ // the user is here
 		return m.handleKey(msg)
 
 	// ── User submits input ──
@@ -60,6 +68,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if msg.Model != "" {
 			m.resolvedModelID = msg.Model
 		}
+		m.clearToolOutput()
 		return m, nil
 
 	case ContentBlockStartMsg:
@@ -82,6 +91,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if msg.Name != "" {
 			// Tool call block completed. Print the tool line to scrollback.
 			toolLine := renderToolComplete(msg.Name, msg.Input)
+			if msg.Name == "Bash" {
+				toolLine += m.shellCwdNote()
+			}
 			cmds = append(cmds, tea.Println(toolLine))
 			m.activeTool = ""
 			m.toolSummary = ""
@@ -109,6 +121,38 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmds = append(cmds, tea.Println(errLine))
 		return m, tea.Batch(cmds...)
 
+	case ToolOutputMsg:
+		if msg.ID != m.toolOutputID {
+			m.toolOutputID = msg.ID
+			m.toolOutputFor = msg.Name
+			m.toolOutput = ""
+			m.toolProgress = ""
+		}
+		m.toolOutput = appendToolOutput(m.toolOutput, msg.Chunk)
+		return m, nil
+
+	case ToolProgressMsg:
+		if msg.ID != m.toolOutputID {
+			m.toolOutputID = msg.ID
+			m.toolOutputFor = msg.Name
+			m.toolOutput = ""
+		}
+		m.toolProgress = formatToolProgress(msg.Progress)
+		return m, nil
+
+	case HookMessageMsg:
+		return m, tea.Println(toolSummaryStyle.Render(msg.Event + " hook: " + msg.Message))
+
+	case HookTraceMsg:
+		return m, tea.Println(toolSummaryStyle.Render(msg.Line))
+
+	case AgentProgressMsg:
+		m.updateAgentProgress(msg.ID, msg.Progress)
+		if msg.Progress.Done {
+			return m, tea.Println(toolSummaryStyle.Render(agentDoneLine(msg.Progress)))
+		}
+		return m, nil
+
 	case LoopDoneMsg:
 		return m.handleLoopDone(msg)
 
@@ -129,10 +173,19 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.textInput.Focus()
 		return m, tea.Batch(tea.Println(output), textarea.Blink)
 
+	case AgentEditDoneMsg:
+		return m, tea.Println(agentEditResult(msg, m.agentTools))
+
 	// ── Permission prompt ──
 	case PermissionRequestMsg:
 		m.permissionPending = &msg
 		m.mode = modePermission
+		return m, m.notifyHooks("Claude needs your permission to use " + msg.ToolName)
+
+	case idleNotifyMsg:
+		if msg.seq == m.idleSeq && m.mode == modeInput {
+			return m, m.notifyHooks("Claude is waiting for your input")
+		}
 		return m, nil
 
 	// ── Status line update ──
@@ -140,6 +193,27 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.statusLineText = msg.Text
 		return m, nil
 
+	case mcpPromptsMsg:
+		m.slashReg.registerMCPPrompts(msg.prompts)
+		return m, nil
+
+	case mcpResourcesMsg:
+		m.mcpResources = msg.resources
+		return m, nil
+
+	case mcpUpdateMsg:
+		return m, tea.Println(permHintStyle.Render(mcpUpdateNotice(msg.target)))
+
+	case mcpMentionsMsg:
+		for _, note := range msg.notes {
+			cmds = append(cmds, tea.Println(permHintStyle.Render(note)))
+		}
+		text := msg.text
+		cmds = append(cmds, func() tea.Msg {
+			return LoopDoneMsg{Err: m.loop.SendMessage(m.ctx, text)}
+		})
+		return m, tea.Batch(cmds...)
+
 	// ── Todo list update ──
 	case tools.TodoUpdateMsg:
 		m.todos = msg.Todos
@@ -151,7 +225,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.askCursor = 0
 		m.askAnswers = make(map[string]string)
 		m.askQuestionIdx = 0
-		m.askCustomInput = false
+		m.askCustomInput = askTakesText(msg.Questions, 0)
 		m.askCustomText = ""
 		m.mode = modeAskUser
 		return m, nil
@@ -199,11 +273,19 @@ func (m model) handleLoopDone(msg LoopDoneMsg) (tea.Model, tea.Cmd) {
 		cmds = append(cmds, tea.Println(rendered))
 		m.streamingText = ""
 	}
-	if msg.Err != nil && m.ctx.Err() == nil {
+	var blocked *conversation.PromptBlockedError
+	if errors.As(msg.Err, &blocked) {
+		cmds = append(cmds, tea.Println(errorStyle.Render("Prompt blocked by hook: "+blocked.Reason)))
+		// Give the message back to fix and send again.
+		if m.textInput.Value() == "" {
+			m.textInput.SetValue(blocked.Prompt)
+		}
+	} else if msg.Err != nil && m.ctx.Err() == nil {
 		errLine := errorStyle.Render("Error: " + msg.Err.Error())
 		cmds = append(cmds, tea.Println(errLine))
 	}
 	m.activeTool = ""
+	m.clearToolOutput()
 	// Clear any previous dynamic suggestion.
 	m.dynSuggestion = ""
 	// Refresh the custom status line after each assistant turn.
@@ -220,6 +302,9 @@ func (m model) handleLoopDone(msg LoopDoneMsg) (tea.Model, tea.Cmd) {
 
 	m.mode = modeInput
 	m.textInput.Focus()
+	if cmd := m.startIdleTimer(); cmd != nil {
+		cmds = append(cmds, cmd)
+	}
 	// Generate a dynamic prompt suggestion for the next turn.
 	if m.apiClient != nil && msg.Err == nil && m.ctx.Err() == nil {
 		m.dynSuggestionGenerating = true
@@ -48,6 +48,22 @@ func (m model) View() string {
 			b.WriteString("  " + toolSummaryStyle.Render(m.toolSummary))
 		}
 		b.WriteString("\n")
+	} else if m.toolOutputFor != "" {
+		// A tool is running and streaming output.
+		b.WriteString(m.spinner.View())
+		b.WriteString(" ")
+		b.WriteString(toolNameStyle.Render(m.toolOutputFor))
+		if m.toolProgress != "" {
+			b.WriteString("  " + toolSummaryStyle.Render(m.toolProgress))
+		}
+		b.WriteString("\n")
+		if out := renderToolOutput(m.toolOutput, toolOutputLines, m.width); out != "" {
+			b.WriteString(out)
+			b.WriteString("\n")
+		}
+	} else if len(m.agentProgress) > 0 {
+		// Sub-agents are running.
+		b.WriteString(m.renderAgentProgress())
 	} else if m.mode == modeStreaming && m.streamingText == "" {
 		// Show a general "thinking" spinner when waiting for the API.
 		b.WriteString(m.spinner.View())
@@ -80,6 +96,30 @@ func (m model) View() string {
 		b.WriteString(m.renderResumePicker())
 	}
 
+	// Agents manager.
+	if m.mode == modeAgents {
+		b.WriteString(m.renderAgentsPanel())
+		b.WriteString("\n")
+	}
+
+	// Hooks manager.
+	if m.mode == modeHooks {
+		b.WriteString(m.renderHooksPanel())
+		b.WriteString("\n")
+	}
+
+	// MCP prompt arguments.
+	if m.mode == modeMCPPrompt {
+		b.WriteString(m.renderMCPPromptPanel())
+		b.WriteString("\n")
+	}
+
+	// Background tasks list.
+	if m.mode == modeTasks {
+		b.WriteString(m.renderTasksPanel())
+		b.WriteString("\n")
+	}
+
 	// Model picker.
 	if m.mode == modeModelPicker {
 		b.WriteString(m.renderModelPicker())
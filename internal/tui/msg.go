@@ -6,6 +6,7 @@ import (
 
 	"github.com/anthropics/claude-code-go/internal/api"
 	"github.com/anthropics/claude-code-go/internal/config"
+	"github.com/anthropics/claude-code-go/internal/conversation"
 )
 
 // MessageStartMsg carries token usage and model info from the start of an API response.
@@ -54,6 +55,37 @@ type StreamErrorMsg struct {
 	Err error
 }
 
+// ToolOutputMsg carries incremental output from a running tool.
+type ToolOutputMsg struct {
+	ID    string // tool_use ID
+	Name  string
+	Chunk string
+}
+
+// ToolProgressMsg carries a progress report from a running tool.
+type ToolProgressMsg struct {
+	ID       string // tool_use ID
+	Name     string
+	Progress conversation.ToolProgress
+}
+
+// HookMessageMsg carries output from the hooks for an event, for the user.
+type HookMessageMsg struct {
+	Event   string
+	Message string
+}
+
+// HookTraceMsg describes a hook run, with --verbose.
+type HookTraceMsg struct {
+	Line string
+}
+
+// AgentProgressMsg carries a progress report from a running sub-agent.
+type AgentProgressMsg struct {
+	ID       string // tool_use ID of the Agent call
+	Progress conversation.AgentProgress
+}
+
 // LoopDoneMsg signals the agentic loop has finished.
 type LoopDoneMsg struct {
 	Err error
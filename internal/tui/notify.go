@@ -0,0 +1,43 @@
+package tui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// idleNotifyAfter is how long the prompt waits for the user after a turn
+// before the Notification hooks fire, as in the official CLI.
+const idleNotifyAfter = 60 * time.Second
+
+// idleNotifyMsg is sent idleNotifyAfter after a turn ends. A key press
+// since then bumps model.idleSeq, which makes it stale.
+type idleNotifyMsg struct {
+	seq int
+}
+
+// startIdleTimer returns a command that sends idleNotifyMsg once the
+// prompt has waited idleNotifyAfter, or nil without hooks.
+func (m *model) startIdleTimer() tea.Cmd {
+	if m.hooks == nil {
+		return nil
+	}
+	m.idleSeq++
+	seq := m.idleSeq
+	return tea.Tick(idleNotifyAfter, func(time.Time) tea.Msg {
+		return idleNotifyMsg{seq: seq}
+	})
+}
+
+// notifyHooks returns a command that fires the Notification hooks in the
+// background, so a slow hook doesn't hold up the UI.
+func (m model) notifyHooks(message string) tea.Cmd {
+	if m.hooks == nil {
+		return nil
+	}
+	hooks, ctx := m.hooks, m.ctx
+	return func() tea.Msg {
+		_ = hooks.RunNotification(ctx, message)
+		return nil
+	}
+}
@@ -102,6 +102,19 @@ func renderToolComplete(name string, input json.RawMessage) string {
 	return b.String()
 }
 
+// shellCwdNote returns a suffix for Bash tool lines naming the directory the
+// command runs in, or "" when the shell is still in the startup directory.
+func (m model) shellCwdNote() string {
+	if m.shellCwd == nil {
+		return ""
+	}
+	dir := m.shellCwd()
+	if dir == "" || dir == m.cwd {
+		return ""
+	}
+	return "  " + toolSummaryStyle.Render("(in "+shortenPath(dir)+")")
+}
+
 // extractToolSummary returns a short description for a tool call.
 func extractToolSummary(name string, input json.RawMessage) string {
 	if len(input) == 0 {
@@ -141,6 +154,10 @@ func extractToolSummary(name string, input json.RawMessage) string {
 		if s := getString("pattern"); s != "" {
 			return fmt.Sprintf("/%s/", s)
 		}
+	case "LS":
+		return getString("path")
+	case "Git":
+		return strings.TrimSpace(getString("operation") + " " + getString("action"))
 	case "Agent":
 		return getString("description")
 	case "TodoWrite":
@@ -149,11 +166,33 @@ func extractToolSummary(name string, input json.RawMessage) string {
 		return "asking user"
 	case "WebFetch":
 		return getString("url")
+	case "HttpRequest":
+		if s := getString("url"); s != "" {
+			return strings.TrimSpace(strings.ToUpper(getString("method")) + " " + s)
+		}
+	case "DownloadFile":
+		return getString("url")
 	case "WebSearch":
 		if s := getString("query"); s != "" {
 			return "searching: " + s
 		}
-	case "NotebookEdit":
+	case "RunServer":
+		return getString("command")
+	case "Container":
+		target := getString("container")
+		if target == "" {
+			target = getString("service")
+		}
+		return strings.TrimSpace(getString("operation") + " " + target)
+	case "SQL":
+		return getString("database")
+	case "CodeRun":
+		return getString("language")
+	case "SlashCommand":
+		return getString("command")
+	case "Skill":
+		return getString("skill")
+	case "NotebookEdit", "NotebookRead":
 		return getString("notebook_path")
 	case "ExitPlanMode":
 		return "plan ready"
@@ -167,6 +206,10 @@ func extractToolSummary(name string, input json.RawMessage) string {
 		}
 	case "TaskStop":
 		return "stopping task"
+	case "TaskSteer":
+		if s := getString("task_id"); s != "" {
+			return "messaging " + s
+		}
 	}
 	return ""
 }
@@ -4,10 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
+	"sync"
 
 	tea "github.com/charmbracelet/bubbletea"
 
 	"github.com/anthropics/claude-code-go/internal/config"
+	"github.com/anthropics/claude-code-go/internal/tools"
 )
 
 // PermissionResponse represents the user's response to a permission prompt.
@@ -26,6 +29,10 @@ const (
 type TUIPermissionHandler struct {
 	program  *tea.Program
 	ruleHandler *config.RuleBasedPermissionHandler
+
+	// prompt allows one prompt on screen at a time; sub-agents running in
+	// parallel wait their turn.
+	prompt sync.Mutex
 }
 
 // NewTUIPermissionHandler creates a permission handler wired to the given
@@ -75,10 +82,17 @@ func (h *TUIPermissionHandler) RequestPermission(
 		return false, nil
 	}
 
-	// Get suggestions from the rule handler if available.
+	h.prompt.Lock()
+	defer h.prompt.Unlock()
+
+	// Get suggestions from the rule handler if available. An "always
+	// allow" answered while this call waited may already cover it.
 	var suggestions []config.PermissionSuggestion
 	if h.ruleHandler != nil {
 		result := h.ruleHandler.CheckPermission(toolName, input)
+		if result.Behavior == config.BehaviorAllow {
+			return true, nil
+		}
 		suggestions = result.Suggestions
 	}
 
@@ -170,10 +184,63 @@ func summarizeForPermission(toolName string, input json.RawMessage) string {
 		if s := getString("url"); s != "" {
 			return fmt.Sprintf("Fetch: %s", s)
 		}
+	case "HttpRequest":
+		if s := tools.HttpRequestSummary(input); s != "" {
+			return s
+		}
+	case "DownloadFile":
+		if s := getString("url"); s != "" {
+			return fmt.Sprintf("Download: %s → %s", s, getString("path"))
+		}
 	case "WebSearch":
 		if s := getString("query"); s != "" {
 			return fmt.Sprintf("Search: %s", s)
 		}
+	case "MCPSampling":
+		if server := getString("server"); server != "" {
+			s := strings.Join(strings.Fields(getString("prompt")), " ")
+			if len(s) > 120 {
+				s = s[:117] + "..."
+			}
+			return fmt.Sprintf("MCP server %q wants to query the model: %s", server, s)
+		}
+	case "RunServer":
+		if s := getString("command"); s != "" {
+			if len(s) > 120 {
+				s = s[:117] + "..."
+			}
+			return fmt.Sprintf("Start server: %s", s)
+		}
+	case "Container":
+		target := getString("container")
+		if target == "" {
+			target = getString("service")
+		}
+		switch getString("operation") {
+		case "exec":
+			s := getString("command")
+			if len(s) > 120 {
+				s = s[:117] + "..."
+			}
+			return fmt.Sprintf("%s $ %s", target, s)
+		case "cp":
+			return fmt.Sprintf("Copy %s → %s (%s)", getString("source"), getString("destination"), target)
+		}
+	case "CodeRun":
+		if s := getString("code"); s != "" {
+			s = strings.ReplaceAll(s, "\n", "; ")
+			if len(s) > 120 {
+				s = s[:117] + "..."
+			}
+			return fmt.Sprintf("Run %s: %s", getString("language"), s)
+		}
+	case "SQL":
+		if s := getString("query"); s != "" {
+			if len(s) > 120 {
+				s = s[:117] + "..."
+			}
+			return fmt.Sprintf("Query %s: %s", getString("database"), s)
+		}
 	case "EnterWorktree", "Worktree":
 		if s := getString("branch"); s != "" {
 			return fmt.Sprintf("Create worktree: %s", s)
@@ -43,61 +43,6 @@ func TestRelativeTimeOld(t *testing.T) {
 	}
 }
 
-func TestFirstUserMessage(t *testing.T) {
-	tests := []struct {
-		name string
-		sess *session.Session
-		want string
-	}{
-		{
-			name: "simple text message",
-			sess: &session.Session{
-				Messages: []api.Message{
-					api.NewTextMessage(api.RoleUser, "hello world"),
-				},
-			},
-			want: "hello world",
-		},
-		{
-			name: "assistant first then user",
-			sess: &session.Session{
-				Messages: []api.Message{
-					api.NewTextMessage(api.RoleAssistant, "I am Claude"),
-					api.NewTextMessage(api.RoleUser, "hi there"),
-				},
-			},
-			want: "hi there",
-		},
-		{
-			name: "empty session",
-			sess: &session.Session{
-				Messages: []api.Message{},
-			},
-			want: "",
-		},
-		{
-			name: "block message with text",
-			sess: &session.Session{
-				Messages: []api.Message{
-					api.NewBlockMessage(api.RoleUser, []api.ContentBlock{
-						{Type: api.ContentTypeText, Text: "block text"},
-					}),
-				},
-			},
-			want: "block text",
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got := firstUserMessage(tt.sess)
-			if got != tt.want {
-				t.Errorf("firstUserMessage() = %q, want %q", got, tt.want)
-			}
-		})
-	}
-}
-
 func TestSessionSummary(t *testing.T) {
 	sess := &session.Session{
 		ID:        "test-123",
@@ -2,6 +2,7 @@ package tui
 
 import (
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 
@@ -16,7 +17,7 @@ type SlashCommand struct {
 	Description string
 	IsHidden    bool                                  // hidden commands are not shown in the help screen
 	IsAlias     bool                                  // alias commands (e.g. exit→quit) are hidden from help
-	IsSkill     bool                                  // true for commands added via registerSkills (shown in custom-commands tab)
+	IsSkill     bool                                  // true for commands added via registerSkills or registerMCPPrompts (shown in custom-commands tab)
 	Execute     func(m *model, args string) (tea.Model, tea.Cmd) // returns updated model and command
 }
 
@@ -32,8 +33,9 @@ func textCommand(fn func(m *model) string) func(m *model, args string) (tea.Mode
 
 // slashRegistry holds all registered slash commands.
 type slashRegistry struct {
-	commands map[string]SlashCommand
-	names    []string // sorted for completion
+	commands   map[string]SlashCommand
+	names      []string // sorted for completion
+	mcpPrompts []string // commands added by registerMCPPrompts
 }
 
 // newSlashRegistry creates a registry with all built-in slash commands.
@@ -45,6 +47,7 @@ func newSlashRegistry() *slashRegistry {
 	registerClearCommand(r)
 	registerResumeCommand(r)
 	registerContinueCommand(r)
+	registerRenameCommand(r)
 	registerLoginCommand(r)
 	registerLogoutCommand(r)
 	registerVersionCommand(r)
@@ -67,14 +70,31 @@ func newSlashRegistry() *slashRegistry {
 	registerPermissionsCommand(r)
 	registerHooksCommand(r)
 	registerStatusCommand(r)
+	registerExportCommand(r)
+	registerStatsCommand(r)
+	registerUndoCommand(r)
+	registerChangesCommand(r)
+	registerTodosCommand(r)
+	registerAgentsCommand(r)
+	registerTasksCommand(r)
+	registerSteerCommand(r)
+	registerAddDirCommand(r)
 
 	return r
 }
 
 func (r *slashRegistry) register(cmd SlashCommand) {
+	if _, exists := r.commands[cmd.Name]; !exists {
+		r.names = append(r.names, cmd.Name)
+		sort.Strings(r.names)
+	}
 	r.commands[cmd.Name] = cmd
-	r.names = append(r.names, cmd.Name)
-	sort.Strings(r.names)
+}
+
+// unregister removes a command.
+func (r *slashRegistry) unregister(name string) {
+	delete(r.commands, name)
+	r.names = slices.DeleteFunc(r.names, func(n string) bool { return n == name })
 }
 
 // lookup returns a command and whether it was found.
@@ -76,7 +76,7 @@ func TestSlashRegistry_Complete(t *testing.T) {
 		{"qu", []string{"quit"}},
 		{"init", []string{"init"}},
 		{"cl", []string{"clear"}},
-		{"re", []string{"reset", "resume", "review"}},
+		{"re", []string{"rename", "reset", "resume", "review"}},
 		{"di", []string{"diff"}},
 		{"ne", []string{"new"}},
 		{"xyz", nil},
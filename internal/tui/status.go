@@ -3,17 +3,10 @@ package tui
 import (
 	"fmt"
 
+	"github.com/anthropics/claude-code-go/internal/api"
 	"github.com/anthropics/claude-code-go/internal/config"
 )
 
-// Pricing per million tokens (USD) for supported models.
-// These match the published Anthropic pricing as of 2025.
-var modelPricing = map[string]struct{ Input, Output, CacheRead, CacheWrite float64 }{
-	"claude-opus-4-6":            {Input: 15.0, Output: 75.0, CacheRead: 1.5, CacheWrite: 18.75},
-	"claude-sonnet-4-6":          {Input: 3.0, Output: 15.0, CacheRead: 0.3, CacheWrite: 3.75},
-	"claude-haiku-4-5-20251001":  {Input: 0.8, Output: 4.0, CacheRead: 0.08, CacheWrite: 1.0},
-}
-
 // tokenTracker accumulates token usage across the session.
 type tokenTracker struct {
 	TotalInputTokens  int
@@ -51,19 +44,12 @@ func (t *tokenTracker) addOutput(outputTokens int) {
 
 // updateCost recalculates cost based on the current model pricing.
 func (t *tokenTracker) updateCost(inputTokens, outputTokens int, cacheRead, cacheWrite *int) {
-	pricing, ok := modelPricing[t.modelID]
-	if !ok {
-		return
-	}
-	// Cost = tokens * price_per_million / 1_000_000
-	t.TotalCostUSD += float64(inputTokens) * pricing.Input / 1_000_000
-	t.TotalCostUSD += float64(outputTokens) * pricing.Output / 1_000_000
-	if cacheRead != nil {
-		t.TotalCostUSD += float64(*cacheRead) * pricing.CacheRead / 1_000_000
-	}
-	if cacheWrite != nil {
-		t.TotalCostUSD += float64(*cacheWrite) * pricing.CacheWrite / 1_000_000
-	}
+	t.TotalCostUSD += api.Usage{
+		InputTokens:              inputTokens,
+		OutputTokens:             outputTokens,
+		CacheReadInputTokens:     cacheRead,
+		CacheCreationInputTokens: cacheWrite,
+	}.Cost(t.modelID)
 }
 
 // renderStatusBar returns the formatted status bar string.
@@ -6,6 +6,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 
 	"github.com/anthropics/claude-code-go/internal/api"
+	"github.com/anthropics/claude-code-go/internal/conversation"
 )
 
 // TUIStreamHandler implements api.StreamHandler by forwarding all events
@@ -75,6 +76,30 @@ func (h *TUIStreamHandler) OnMessageStop() {
 	h.program.Send(MessageStopMsg{})
 }
 
+// OnAgentProgress implements conversation.AgentProgressHandler, forwarding
+// sub-agent activity to the live region.
+func (h *TUIStreamHandler) OnAgentProgress(toolUseID string, p conversation.AgentProgress) {
+	h.program.Send(AgentProgressMsg{ID: toolUseID, Progress: p})
+}
+
+// OnToolProgress implements conversation.ToolProgressHandler, forwarding
+// progress reports from running tools (e.g. MCP tools) to the spinner.
+func (h *TUIStreamHandler) OnToolProgress(toolUseID, toolName string, p conversation.ToolProgress) {
+	h.program.Send(ToolProgressMsg{ID: toolUseID, Name: toolName, Progress: p})
+}
+
+// OnToolOutput implements conversation.ToolOutputHandler, forwarding output
+// from running tools (e.g. Bash) to the live region.
+func (h *TUIStreamHandler) OnToolOutput(toolUseID, toolName, chunk string) {
+	h.program.Send(ToolOutputMsg{ID: toolUseID, Name: toolName, Chunk: chunk})
+}
+
+// OnHookMessage implements conversation.HookMessageHandler, forwarding
+// what hooks printed to the scrollback.
+func (h *TUIStreamHandler) OnHookMessage(event, message string) {
+	h.program.Send(HookMessageMsg{Event: event, Message: message})
+}
+
 func (h *TUIStreamHandler) OnError(err error) {
 	h.program.Send(StreamErrorMsg{Err: err})
 }
@@ -0,0 +1,116 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/anthropics/claude-code-go/internal/tools"
+)
+
+// tasksOutputLines is how many lines of a task's output /tasks prints.
+const tasksOutputLines = 20
+
+// tasksPanel holds the state of the /tasks list.
+type tasksPanel struct {
+	tasks  []*tools.BackgroundTask
+	cursor int
+}
+
+// handleTasksKey processes key events in the /tasks list.
+func (m model) handleTasksKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := m.tasksPanel
+	if p == nil || len(p.tasks) == 0 {
+		return m.closeTasksPanel("")
+	}
+	switch msg.Type {
+	case tea.KeyUp:
+		if p.cursor > 0 {
+			p.cursor--
+		}
+	case tea.KeyDown:
+		if p.cursor < len(p.tasks)-1 {
+			p.cursor++
+		}
+	case tea.KeyEnter:
+		return m.closeTasksPanel(formatTaskReport(p.tasks[p.cursor]))
+	case tea.KeyEsc, tea.KeyCtrlC:
+		return m.closeTasksPanel("")
+	case tea.KeyRunes:
+		if msg.String() != "x" {
+			break
+		}
+		task := p.tasks[p.cursor]
+		if task.Status() != tools.TaskRunning || task.Cancel == nil {
+			break
+		}
+		task.Cancel()
+		return m.closeTasksPanel(fmt.Sprintf("Stopping task %s", task.ID))
+	}
+	return m, nil
+}
+
+// closeTasksPanel leaves the list and prints msg, if any.
+func (m model) closeTasksPanel(msg string) (tea.Model, tea.Cmd) {
+	m.tasksPanel = nil
+	m.mode = modeInput
+	m.textInput.Focus()
+	if msg == "" {
+		return m, textarea.Blink
+	}
+	return m, tea.Batch(tea.Println(msg), textarea.Blink)
+}
+
+// formatTaskReport describes a task and the end of its output.
+func formatTaskReport(task *tools.BackgroundTask) string {
+	status := task.Status()
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (%s, %s)", task.ID, task.Kind, status)
+	if task.Description != "" {
+		b.WriteString("\n  " + task.Description)
+	}
+	output := task.Result
+	if status == tools.TaskRunning && task.Output != nil {
+		output = task.Output()
+	}
+	if lines := strings.Split(strings.TrimRight(output, "\n"), "\n"); strings.TrimSpace(output) != "" {
+		if len(lines) > tasksOutputLines {
+			fmt.Fprintf(&b, "\n  ... (%d earlier lines)", len(lines)-tasksOutputLines)
+			lines = lines[len(lines)-tasksOutputLines:]
+		}
+		b.WriteString("\n  " + strings.Join(lines, "\n  "))
+	}
+	if task.Err != nil {
+		b.WriteString("\n" + errorStyle.Render("  Error: "+task.Err.Error()))
+	}
+	if task.OutputFile != "" {
+		b.WriteString("\n  Saved to " + shortenPath(task.OutputFile))
+	}
+	if status == tools.TaskInterrupted && task.Kind == "agent" {
+		b.WriteString("\n  Interrupted before finishing. To continue it, ask to resume agent " + task.ID + ".")
+	}
+	return b.String()
+}
+
+// renderTasksPanel renders the /tasks list for the live region.
+func (m model) renderTasksPanel() string {
+	p := m.tasksPanel
+	if p == nil {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(askHeaderStyle.Render("[Tasks]") + " " + askQuestionStyle.Render("Background tasks") + "\n")
+	for i, task := range p.tasks {
+		label := fmt.Sprintf("%-11s %-6s %s", task.Status(), task.Kind, truncateText(task.Description, 50))
+		detail := relativeTime(task.StartedAt)
+		if i == p.cursor {
+			b.WriteString(askSelectedStyle.Render("  > "+label) + " " + askOptionStyle.Render(detail) + "\n")
+		} else {
+			b.WriteString(askOptionStyle.Render("    "+label+" "+detail) + "\n")
+		}
+	}
+	b.WriteString(permHintStyle.Render("  Enter to show output, x to stop a running task, Esc to close"))
+	return b.String()
+}
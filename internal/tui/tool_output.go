@@ -0,0 +1,87 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/anthropics/claude-code-go/internal/conversation"
+)
+
+const (
+	// toolOutputLines is how many trailing lines of a running tool's
+	// output are shown in the live region.
+	toolOutputLines = 8
+
+	// toolOutputMaxBytes bounds the output kept for the live region; only
+	// the tail is ever displayed.
+	toolOutputMaxBytes = 16 * 1024
+)
+
+// appendToolOutput adds a chunk to the buffered output of a running tool,
+// dropping the oldest bytes once the buffer exceeds toolOutputMaxBytes.
+func appendToolOutput(buf, chunk string) string {
+	buf += chunk
+	if len(buf) > toolOutputMaxBytes {
+		buf = buf[len(buf)-toolOutputMaxBytes:]
+		// Resume at a line boundary so a partial escape sequence or rune
+		// isn't rendered.
+		if i := strings.IndexByte(buf, '\n'); i >= 0 {
+			buf = buf[i+1:]
+		}
+	}
+	return buf
+}
+
+// clearToolOutput forgets the streamed output of the last running tool
+// and the progress of any sub-agents.
+func (m *model) clearToolOutput() {
+	m.toolOutputID = ""
+	m.toolOutputFor = ""
+	m.toolOutput = ""
+	m.toolProgress = ""
+	m.agentProgress = nil
+}
+
+// formatToolProgress describes a tool's progress report for the spinner
+// line: a percentage or count, then the tool's message.
+func formatToolProgress(p conversation.ToolProgress) string {
+	var parts []string
+	switch {
+	case p.Total > 0:
+		parts = append(parts, fmt.Sprintf("%d%%", int(100*min(p.Progress/p.Total, 1))))
+	case p.Progress > 0:
+		parts = append(parts, strconv.FormatFloat(p.Progress, 'f', -1, 64))
+	}
+	if p.Message != "" {
+		parts = append(parts, p.Message)
+	}
+	return strings.Join(parts, " · ")
+}
+
+// renderToolOutput renders the last maxLines lines of tool output, dimmed
+// and indented. Escape sequences are stripped and carriage returns are
+// honored so progress bars show their latest state.
+func renderToolOutput(output string, maxLines, width int) string {
+	output = strings.TrimRight(ansi.Strip(output), "\n")
+	if output == "" {
+		return ""
+	}
+	lines := strings.Split(output, "\n")
+	if len(lines) > maxLines {
+		lines = lines[len(lines)-maxLines:]
+	}
+	for i, line := range lines {
+		line = strings.TrimRight(line, "\r")
+		if j := strings.LastIndexByte(line, '\r'); j >= 0 {
+			line = line[j+1:]
+		}
+		if width > 6 {
+			line = ansi.Truncate(line, width-4, "…")
+		}
+		lines[i] = diffDimStyle.Render("    " + line)
+	}
+	return strings.Join(lines, "\n")
+}